@@ -14,6 +14,7 @@ import (
 	"io"
 	"net"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -74,6 +75,13 @@ func main() {
 
 	// Send downstream header
 	projectID := os.Getenv("OUBLIETTE_PROJECT_ID")
+	if projectID == "" {
+		// Warm pool containers are bound to a project after the agent starts;
+		// the relay records the project it serves
+		if data, err := os.ReadFile("/mcp/project"); err == nil {
+			projectID = strings.TrimSpace(string(data))
+		}
+	}
 	if projectID == "" {
 		projectID = "unknown"
 	}
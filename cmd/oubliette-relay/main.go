@@ -3,10 +3,16 @@
 //
 // Protocol:
 // - Downstream (agent): "OUBLIETTE-DOWNSTREAM {project_id}\n"
-// - Upstream (Oubliette): "OUBLIETTE-UPSTREAM {session_id} {project_id} {depth}\n"
+// - Upstream (Oubliette): "OUBLIETTE-UPSTREAM {session_id} {project_id} {depth} [{traceparent}]\n"
+//
+// The optional traceparent is the W3C trace context of the Oubliette span that
+// opened the connection. It is logged so relay activity can be tied to a trace.
 //
 // Relay pairs connections FIFO (first downstream with first upstream).
 // No JSON parsing - just header validation and byte copying.
+//
+// The relay writes its project ID to /mcp/project so agents started before the
+// container was bound to a project (warm pool containers) can still identify it.
 package main
 
 import (
@@ -22,6 +28,7 @@ import (
 
 const (
 	socketPath     = "/mcp/relay.sock"
+	projectFile    = "/mcp/project"
 	pairingTimeout = 60 * time.Second
 )
 
@@ -77,6 +84,10 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := os.WriteFile(projectFile, []byte(projectID+"\n"), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", projectFile, err)
+	}
+
 	fmt.Fprintf(os.Stderr, "oubliette-relay: listening on %s for project %s\n", socketPath, projectID)
 
 	relay := &Relay{
@@ -122,7 +133,11 @@ func (r *Relay) handleConnection(conn net.Conn) {
 		return
 	}
 
-	fmt.Fprintf(os.Stderr, "relay: %s connection received\n", connType)
+	if tp := traceParent(headerLine); tp != "" {
+		fmt.Fprintf(os.Stderr, "relay: %s connection received (traceparent %s)\n", connType, tp)
+	} else {
+		fmt.Fprintf(os.Stderr, "relay: %s connection received\n", connType)
+	}
 
 	// Wrap conn with the buffered reader (may have buffered data after header)
 	wrappedConn := &bufferedConn{reader: reader, Conn: conn}
@@ -194,7 +209,7 @@ func parseHeader(line string) (connectionType, string, error) {
 	switch parts[0] {
 	case "OUBLIETTE-UPSTREAM":
 		connType = connTypeUpstream
-		// Format: OUBLIETTE-UPSTREAM session_id project_id depth
+		// Format: OUBLIETTE-UPSTREAM session_id project_id depth [traceparent]
 		if len(parts) >= 3 {
 			projectID = parts[2]
 		} else {
@@ -211,6 +226,15 @@ func parseHeader(line string) (connectionType, string, error) {
 	return connType, projectID, nil
 }
 
+// traceParent returns the optional traceparent field of an upstream header
+func traceParent(line string) string {
+	parts := strings.Fields(line)
+	if len(parts) < 5 || parts[0] != "OUBLIETTE-UPSTREAM" {
+		return ""
+	}
+	return parts[4]
+}
+
 // pipe copies data bidirectionally between two connections
 func pipe(upstream, downstream net.Conn) {
 	done := make(chan struct{}, 2)
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"crypto/sha256"
 	"encoding/json"
@@ -22,6 +23,7 @@ import (
 	"time"
 
 	agentopencode "github.com/HyphaGroup/oubliette/internal/agent/opencode"
+	"github.com/HyphaGroup/oubliette/internal/audit"
 	"github.com/HyphaGroup/oubliette/internal/auth"
 	"github.com/HyphaGroup/oubliette/internal/backup"
 	"github.com/HyphaGroup/oubliette/internal/cleanup"
@@ -29,11 +31,17 @@ import (
 	"github.com/HyphaGroup/oubliette/internal/container"
 	"github.com/HyphaGroup/oubliette/internal/container/applecontainer"
 	"github.com/HyphaGroup/oubliette/internal/container/docker"
+	"github.com/HyphaGroup/oubliette/internal/container/podman"
+	"github.com/HyphaGroup/oubliette/internal/doctor"
 	"github.com/HyphaGroup/oubliette/internal/logger"
 	"github.com/HyphaGroup/oubliette/internal/mcp"
 	"github.com/HyphaGroup/oubliette/internal/project"
 	"github.com/HyphaGroup/oubliette/internal/schedule"
 	"github.com/HyphaGroup/oubliette/internal/session"
+	"github.com/HyphaGroup/oubliette/internal/terminal"
+	"github.com/HyphaGroup/oubliette/internal/tracing"
+	"github.com/HyphaGroup/oubliette/internal/transcript"
+	"github.com/moby/term"
 )
 
 // Version is set at build time via -ldflags "-X main.Version=v1.0.0"
@@ -58,6 +66,18 @@ func main() {
 		case "container":
 			cmdContainer(os.Args[2:])
 			return
+		case "attach":
+			cmdAttach(os.Args[2:])
+			return
+		case "session":
+			cmdSession(os.Args[2:])
+			return
+		case "secrets":
+			cmdSecrets(os.Args[2:])
+			return
+		case "doctor":
+			cmdDoctor(os.Args[2:])
+			return
 		case "--version", "-v":
 			fmt.Printf("oubliette %s\n", Version)
 			return
@@ -83,6 +103,10 @@ Commands:
   mcp          Configure MCP integration with AI tools
   token        Manage authentication tokens
   container    Manage containers (list, refresh, stop)
+  attach       Open a shell in a project's container (or watch one)
+  session      Export session transcripts
+  secrets      Manage the encrypted secrets file
+  doctor       Diagnose setup problems
 
 Server Options:
   --dir <path>       Oubliette home directory
@@ -100,6 +124,7 @@ Examples:
   oubliette --daemon                     Start in background
   oubliette init                         Set up ~/.oubliette
   oubliette init --dir .                 Set up in current directory
+  oubliette doctor                       Check the setup and suggest fixes
   oubliette mcp --setup claude            Configure MCP for Claude Desktop
   oubliette mcp --setup claude-code      Configure MCP for Claude Code extension
 `, Version)
@@ -164,6 +189,11 @@ func runServer() {
 	logger.Println("")
 
 	// Credentials and models are already loaded as part of cfg
+	if len(cfg.PlaintextSecrets) > 0 {
+		logger.Printf("⚠️  %d credential(s) stored in plaintext in oubliette.jsonc: %s",
+			len(cfg.PlaintextSecrets), strings.Join(cfg.PlaintextSecrets, ", "))
+		logger.Println("   Use env:, file:, exec: or secret: references instead (see docs/CONFIGURATION.md)")
+	}
 
 	// Log model info
 	if cfg.Models != nil && len(cfg.Models.Models) > 0 {
@@ -183,10 +213,8 @@ func runServer() {
 		cfg.ProjectDefaults.MaxCostUSD,
 	)
 
-	// Set model registry on project manager if available
-	if cfg.Models != nil {
-		projectMgr.SetModelRegistry(cfg.Models)
-	}
+	// Models, project defaults and container images come from config (and change on reload)
+	projectMgr.ApplyConfig(cfg.Models, cfg.ConfigDefaults, cfg.Containers)
 
 	addr := cfg.Server.Address
 
@@ -208,14 +236,23 @@ func runServer() {
 			logger.Fatalf("Failed to initialize Apple Container runtime: %v", err)
 		}
 		baseRuntime = r
+	case "podman":
+		r, err := podman.NewRuntime()
+		if err != nil {
+			logger.Fatalf("Failed to initialize Podman runtime: %v", err)
+		}
+		baseRuntime = r
 	default: // "auto"
-		// Try Apple Container first on macOS ARM64, then Docker
+		// Try Apple Container first on macOS ARM64, then Docker, then Podman
 		if r, err := applecontainer.NewRuntime(); err == nil && r.IsAvailable() {
 			baseRuntime = r
 			logger.Println("🍎 Using Apple Container runtime")
 		} else if r, err := docker.NewRuntime(); err == nil && r.IsAvailable() {
 			baseRuntime = r
 			logger.Println("🐳 Using Docker runtime")
+		} else if r, err := podman.NewRuntime(); err == nil && r.IsAvailable() {
+			baseRuntime = r
+			logger.Println("🦭 Using Podman runtime")
 		} else {
 			logger.Fatalf("No container runtime available")
 		}
@@ -229,9 +266,6 @@ func runServer() {
 	// Initialize ImageManager with container mappings from config
 	imageManager := container.NewImageManager(cfg.Containers, containerRuntime)
 
-	// Set containers on project manager for image name resolution
-	projectMgr.SetContainers(cfg.Containers)
-
 	// Initialize agent runtime (OpenCode)
 	agentRuntime := agentopencode.NewRuntime(containerRuntime)
 	logger.Println("🤖 Agent runtime: OpenCode")
@@ -243,13 +277,32 @@ func runServer() {
 	// Determine Oubliette MCP URL for session-specific configs
 	oublietteMCPURL := fmt.Sprintf("http://localhost%s/mcp", addr)
 
-	sessionMgr := session.NewManager(projectsDir, agentRuntime, oublietteMCPURL)
+	// Initialize session store
+	sessionStore, err := session.NewStore(dataDir)
+	if err != nil {
+		logger.Fatalf("Failed to initialize session store: %v", err)
+	}
+	defer func() { _ = sessionStore.Close() }()
+	logger.Printf("💬 Session database: %s/sessions.db\n", dataDir)
 
-	// Load persistent session index
-	if err := sessionMgr.LoadIndex(); err != nil {
-		logger.Printf("⚠️  Failed to load session index: %v (will rebuild from disk)", err)
+	sessionMgr := session.NewManager(projectsDir, sessionStore, agentRuntime, oublietteMCPURL)
+
+	// Move sessions saved as JSON files by earlier versions into the store
+	if imported, err := sessionMgr.ImportJSONSessions(); err != nil {
+		logger.Printf("⚠️  Failed to import JSON sessions: %v", err)
+	} else if imported > 0 {
+		logger.Printf("💬 Imported %d JSON sessions into the session database", imported)
 	}
 
+	// Index sessions saved before search existed, without holding up startup
+	go func() {
+		if indexed, err := sessionMgr.IndexUnindexedSessions(); err != nil {
+			logger.Printf("⚠️  Failed to index sessions for search: %v", err)
+		} else if indexed > 0 {
+			logger.Printf("🔎 Indexed %d sessions for search", indexed)
+		}
+	}()
+
 	// Recover stale sessions from previous crashes (mark sessions active >30min as failed)
 	if recovered, err := sessionMgr.RecoverStaleSessions(30 * time.Minute); err != nil {
 		logger.Printf("⚠️  Failed to recover stale sessions: %v", err)
@@ -276,6 +329,49 @@ func runServer() {
 	defer func() { _ = authStore.Close() }()
 	logger.Printf("🔐 Auth database: %s/auth.db\n", dataDir)
 
+	// Optional OIDC/JWT authentication alongside API tokens
+	var oidcAuth *auth.OIDCAuthenticator
+	if oidcCfg := cfg.Server.Auth.OIDC; oidcCfg != nil {
+		oidcAuth, err = newOIDCAuthenticator(oidcCfg)
+		if err != nil {
+			logger.Fatalf("Failed to configure OIDC authentication: %v", err)
+		}
+		logger.Printf("🔐 OIDC authentication enabled (issuer: %s)\n", oidcCfg.Issuer)
+	}
+
+	// Persist audit events to an append-only, hash-chained log
+	auditStore, err := audit.NewStore(dataDir)
+	if err != nil {
+		logger.Fatalf("Failed to initialize audit store: %v", err)
+	}
+	defer func() { _ = auditStore.Close() }()
+	audit.SetSink(auditStore)
+	logger.Printf("🧾 Audit database: %s/audit.db\n", dataDir)
+
+	// Export traces to an OTLP collector when enabled
+	if tracingCfg := cfg.Server.Tracing; tracingCfg.Enabled {
+		shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+			Endpoint:    tracingCfg.Endpoint,
+			Insecure:    tracingCfg.Insecure,
+			ServiceName: tracingCfg.ServiceName,
+			SampleRatio: tracingCfg.SampleRatio,
+			Version:     Version,
+		})
+		if err != nil {
+			logger.Fatalf("Failed to initialize tracing: %v", err)
+		}
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = shutdownTracing(shutdownCtx)
+		}()
+		endpoint := tracingCfg.Endpoint
+		if endpoint == "" {
+			endpoint = "default"
+		}
+		logger.Printf("🔭 Tracing enabled (OTLP endpoint: %s)\n", endpoint)
+	}
+
 	// Initialize schedule store
 	scheduleStore, err := schedule.NewStore(dataDir)
 	if err != nil {
@@ -287,6 +383,30 @@ func runServer() {
 	// Sockets directory for gogol MCP connectivity (relay creates sockets inside containers)
 	socketsDir := "/tmp/oubliette-sockets"
 
+	// Warm container pool: binding a pool container renames it, which Apple Container can't do
+	poolSizes := make(map[string]int)
+	for containerType, size := range cfg.ConfigDefaults.Container.Pool {
+		if size <= 0 {
+			continue
+		}
+		if !imageManager.IsValidType(containerType) {
+			logger.Printf("⚠️  Ignoring pool size for unknown container type %q", containerType)
+			continue
+		}
+		poolSizes[containerType] = size
+	}
+	if len(poolSizes) > 0 && containerRuntime.Name() == "apple-container" {
+		logger.Printf("⚠️  Warm container pool requires the Docker or Podman runtime; disabled on %s", containerRuntime.Name())
+		poolSizes = nil
+	}
+	for containerType, size := range poolSizes {
+		logger.Printf("🔥 Warm container pool: %d %s container(s)", size, containerType)
+	}
+
+	if idleMinutes := cfg.ConfigDefaults.Container.IdleStopMinutes; idleMinutes > 0 {
+		logger.Printf("💤 Idle containers stop after %d minute(s) without session activity", idleMinutes)
+	}
+
 	// Create MCP server with default container resource limits
 	server := mcp.NewServer(projectMgr, containerRuntime, sessionMgr, authStore, socketsDir, &mcp.ServerConfig{
 		ContainerMemory: "4G",
@@ -296,11 +416,19 @@ func runServer() {
 		ImageManager:    imageManager,
 		AgentRuntime:    agentRuntime,
 		ScheduleStore:   scheduleStore,
+		OIDC:            oidcAuth,
+		AuditStore:      auditStore,
+		PoolSizes:       poolSizes,
+		PoolDir:         filepath.Join(dataDir, "pool"),
+		IdleTimeout:     time.Duration(cfg.ConfigDefaults.Container.IdleStopMinutes) * time.Minute,
+		Config:          cfg,
+		DataDir:         dataDir,
 	})
 
 	// Start resource cleanup with defaults
 	cleaner := cleanup.New(cleanup.Config{
 		ProjectsDir:      projectsDir,
+		Sessions:         sessionMgr,
 		Interval:         5 * time.Minute,
 		SessionRetention: 60 * time.Minute,
 		DiskWarnPercent:  80,
@@ -335,6 +463,24 @@ func runServer() {
 	logger.Println("   Use project_*, container_*, session_* tools to manage resources")
 	logger.Println("")
 
+	// Reload oubliette.jsonc on SIGHUP; a rejected config is logged and the current one kept
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
+	go func() {
+		for range reloadChan {
+			logger.Println("🔄 Received SIGHUP, reloading configuration...")
+			result, err := server.ReloadConfig(true)
+			switch {
+			case err != nil:
+				// Already logged by ReloadConfig
+			case !result.Applied:
+				logger.Println("   Configuration unchanged")
+			case result.RestartRequired:
+				logger.Println("⚠️  Some changes take effect after a restart")
+			}
+		}
+	}()
+
 	// Setup graceful shutdown
 	shutdownChan := make(chan os.Signal, 1)
 	signal.Notify(shutdownChan, syscall.SIGINT, syscall.SIGTERM)
@@ -461,6 +607,7 @@ func cmdInit() {
     "github": {
       "credentials": {
         "default": {
+          // Plaintext, or a reference: env:VAR, file:/path, exec:cmd, secret:NAME
           "token": "",
           "description": "GitHub token"
         }
@@ -1028,6 +1175,10 @@ func cmdToken(args []string) {
 		tokenRevoke(store, cmdArgs)
 	case "info":
 		tokenInfo(store, cmdArgs)
+	case "quota":
+		tokenQuota(store, cmdArgs)
+	case "usage":
+		tokenUsage(store, cmdArgs)
 	case "help", "-h", "--help":
 		_ = store.Close()
 		printTokenUsage()
@@ -1051,6 +1202,8 @@ Commands:
   list      List all tokens
   revoke    Revoke a token
   info      Get token details
+  quota     Set or clear a token's quota
+  usage     Show a token's usage for today
   help      Show this help
 
 Scope Formats:
@@ -1059,18 +1212,34 @@ Scope Formats:
   project:<uuid>     Full access to one project
   project:<uuid>:ro  Read-only access to one project
 
+Entries can be combined with spaces or commas. Tool rules narrow access:
+  tool:<name>[:<action>]   Allow only the listed tools/actions
+  tool:<name>:ro           Allow only the read actions of a tool
+  deny:<name>[:<action>]   Deny a tool or one of its actions
+
+Quota Flags (create, quota; 0 = unlimited):
+  --rps <n>                  Requests per second (default: server limit)
+  --burst <n>                Request burst size
+  --max-concurrent <n>       Concurrent active sessions
+  --max-sessions-per-day <n> Sessions started per UTC day
+  --max-tokens-per-day <n>   Model tokens (input + output) per UTC day
+
 Examples:
   oubliette token create --name "Local Dev" --scope admin
   oubliette token create --name "Project Alpha" --scope project:abc-123-def
+  oubliette token create --name "Bot" --scope "project:abc,project:def:ro,tool:session:message"
   oubliette token list
   oubliette token revoke oub_xxxx...
-  oubliette token info oub_xxxx...`)
+  oubliette token info oub_xxxx...
+  oubliette token quota oub_xxxx... --max-concurrent 2 --max-tokens-per-day 500000
+  oubliette token usage oub_xxxx...`)
 }
 
 func tokenCreate(store *auth.Store, args []string) {
 	fs := flag.NewFlagSet("token create", flag.ExitOnError)
 	name := fs.String("name", "", "Human-readable token name (required)")
-	scope := fs.String("scope", "", "Token scope: admin, admin:ro, project:<uuid>, or project:<uuid>:ro (required)")
+	scope := fs.String("scope", "", "Token scope, e.g. admin, admin:ro, project:<uuid>[:ro], plus optional tool:/deny: rules (required)")
+	quota := quotaFlags(fs)
 	_ = fs.Parse(args)
 
 	if *name == "" || *scope == "" {
@@ -1080,9 +1249,9 @@ func tokenCreate(store *auth.Store, args []string) {
 	}
 
 	// Validate scope
-	if !isValidTokenScope(*scope) {
-		fmt.Fprintf(os.Stderr, "Error: invalid scope '%s'\n", *scope)
-		fmt.Fprintln(os.Stderr, "Valid scopes: admin, admin:ro, project:<uuid>, project:<uuid>:ro")
+	if err := auth.ValidateScope(*scope); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid scope '%s': %v\n", *scope, err)
+		fmt.Fprintln(os.Stderr, "Run 'oubliette token help' for scope formats")
 		os.Exit(1)
 	}
 
@@ -1091,12 +1260,27 @@ func tokenCreate(store *auth.Store, args []string) {
 		fmt.Fprintf(os.Stderr, "Error creating token: %v\n", err)
 		os.Exit(1)
 	}
+	if !quota.IsZero() {
+		if err := store.SetQuota(tokenID, quota); err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting quota: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if !quota.IsZero() {
+		if err := store.SetQuota(tokenID, quota); err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting quota: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	fmt.Println("Token created successfully!")
 	fmt.Println()
 	fmt.Printf("Token ID: %s\n", tokenID)
 	fmt.Printf("Name:     %s\n", token.Name)
 	fmt.Printf("Scope:    %s\n", token.Scope)
+	if !quota.IsZero() {
+		fmt.Printf("Quota:    %s\n", quota.String())
+	}
 	fmt.Println()
 	fmt.Println("IMPORTANT: Save this token now. It cannot be retrieved later.")
 }
@@ -1181,25 +1365,83 @@ func tokenInfo(store *auth.Store, args []string) {
 	} else {
 		fmt.Printf("Expires:     never\n")
 	}
+	quota, err := store.GetQuota(tokenID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting quota: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Quota:       %s\n", quota.String())
 }
 
-func isValidTokenScope(scope string) bool {
-	// Admin scopes
-	if scope == auth.ScopeAdmin || scope == auth.ScopeAdminRO {
-		return true
+// quotaFlags registers the quota flags on fs and returns the quota they fill in
+func quotaFlags(fs *flag.FlagSet) *auth.Quota {
+	q := &auth.Quota{}
+	fs.Float64Var(&q.RequestsPerSecond, "rps", 0, "Requests per second (0 = server default)")
+	fs.IntVar(&q.Burst, "burst", 0, "Request burst size (0 = twice the rate)")
+	fs.IntVar(&q.MaxConcurrentSessions, "max-concurrent", 0, "Max concurrent active sessions (0 = unlimited)")
+	fs.IntVar(&q.MaxSessionsPerDay, "max-sessions-per-day", 0, "Max sessions started per UTC day (0 = unlimited)")
+	fs.Int64Var(&q.MaxTokensPerDay, "max-tokens-per-day", 0, "Max model tokens per UTC day (0 = unlimited)")
+	return q
+}
+
+func tokenQuota(store *auth.Store, args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Error: token ID required")
+		fmt.Fprintln(os.Stderr, "Usage: oubliette token quota <token_id> [quota flags]")
+		os.Exit(1)
 	}
-	// Project scopes: project:<uuid> or project:<uuid>:ro
-	if strings.HasPrefix(scope, "project:") {
-		rest := scope[8:]
-		if rest == "" {
-			return false
-		}
-		if strings.HasSuffix(rest, ":ro") {
-			return len(rest) > 3
-		}
-		return true
+
+	tokenID := args[0]
+	fs := flag.NewFlagSet("token quota", flag.ExitOnError)
+	quota := quotaFlags(fs)
+	_ = fs.Parse(args[1:])
+
+	if err := store.SetQuota(tokenID, quota); err != nil {
+		fmt.Fprintf(os.Stderr, "Error setting quota: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Quota for %s set: %s\n", maskTokenID(tokenID), quota.String())
+}
+
+func tokenUsage(store *auth.Store, args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Error: token ID required")
+		fmt.Fprintln(os.Stderr, "Usage: oubliette token usage <token_id>")
+		os.Exit(1)
+	}
+
+	tokenID := args[0]
+	usage, err := store.GetUsage(tokenID, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting usage: %v\n", err)
+		os.Exit(1)
+	}
+	quota, err := store.GetQuota(tokenID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting quota: %v\n", err)
+		os.Exit(1)
 	}
-	return false
+
+	fmt.Printf("Token ID:     %s\n", maskTokenID(tokenID))
+	fmt.Printf("Day (UTC):    %s\n", usage.Day)
+	fmt.Printf("Requests:     %d\n", usage.Requests)
+	fmt.Printf("Sessions:     %d\n", usage.Sessions)
+	fmt.Printf("Model tokens: %d (%d input, %d output)\n", usage.ModelTokens(), usage.InputTokens, usage.OutputTokens)
+	fmt.Printf("Quota:        %s\n", quota.String())
+}
+
+// newOIDCAuthenticator builds the OIDC authenticator from the server.auth.oidc config section
+func newOIDCAuthenticator(section *config.OIDCSection) (*auth.OIDCAuthenticator, error) {
+	return auth.NewOIDCAuthenticator(auth.OIDCConfig{
+		Issuer:       section.Issuer,
+		Audience:     section.Audience,
+		JWKSURL:      section.JWKSURL,
+		ScopeClaim:   section.ScopeClaim,
+		GroupsClaim:  section.GroupsClaim,
+		GroupScopes:  section.GroupScopes,
+		DefaultScope: section.DefaultScope,
+		JWKSCacheTTL: time.Duration(section.JWKSCacheTTLSeconds) * time.Second,
+	})
 }
 
 func maskTokenID(tokenID string) string {
@@ -1287,7 +1529,10 @@ func containerList() {
 		}
 
 		projectID := entry.Name()
-		containerName := fmt.Sprintf("oubliette-%s", projectID[:min(8, len(projectID))])
+		containerName, err := container.ProjectContainerName(projectID)
+		if err != nil {
+			continue
+		}
 
 		status, err := containerRT.Status(ctx, containerName)
 		if err != nil {
@@ -1363,7 +1608,12 @@ func containerRefresh(args []string) {
 		imageName = cfg.Containers["dev"]
 	}
 
-	containerName := fmt.Sprintf("oubliette-%s", projectID[:min(8, len(projectID))])
+	containerName, err := container.ProjectContainerName(projectID)
+	if err != nil {
+		_ = containerRT.Close()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	fmt.Printf("Refreshing container for project %s...\n", projectID[:min(12, len(projectID))])
 	fmt.Printf("Pulling image %s...\n", imageName)
@@ -1416,7 +1666,10 @@ func containerStop(args []string) {
 			}
 
 			projectID := entry.Name()
-			containerName := fmt.Sprintf("oubliette-%s", projectID[:min(8, len(projectID))])
+			containerName, err := container.ProjectContainerName(projectID)
+			if err != nil {
+				continue
+			}
 
 			status, err := containerRT.Status(ctx, containerName)
 			if err != nil {
@@ -1444,7 +1697,12 @@ func containerStop(args []string) {
 
 	// Stop specific project
 	projectID := args[0]
-	containerName := fmt.Sprintf("oubliette-%s", projectID[:min(8, len(projectID))])
+	containerName, err := container.ProjectContainerName(projectID)
+	if err != nil {
+		_ = containerRT.Close()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	fmt.Printf("Stopping container %s...\n", containerName)
 	if err := containerRT.Stop(ctx, containerName); err != nil {
@@ -1457,6 +1715,270 @@ func containerStop(args []string) {
 	fmt.Println("✅ Container stopped")
 }
 
+func cmdSession(args []string) {
+	if len(args) < 1 {
+		printSessionUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "export":
+		sessionExport(args[1:])
+	case "help", "-h", "--help":
+		printSessionUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown session command: %s\n", args[0])
+		printSessionUsage()
+		os.Exit(1)
+	}
+}
+
+func printSessionUsage() {
+	fmt.Println(`Session Transcripts
+
+Usage: oubliette session <command> [options]
+
+Commands:
+  export <session_id>   Render the full transcript of a session
+
+Export Flags:
+  --format <name>   markdown (default), html, or json
+  --output <path>   Write to a file instead of stdout
+  --children        Also export child sessions next to --output, named <session_id>.<ext>
+  --dir <path>      Oubliette home directory
+
+Examples:
+  oubliette session export gogol_20260301_090000_abcd1234
+  oubliette session export gogol_20260301_090000_abcd1234 --format html --output review.html
+  oubliette session export gogol_20260301_090000_abcd1234 --output out/session.md --children`)
+}
+
+func sessionExport(args []string) {
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprintln(os.Stderr, "Error: session ID required")
+		fmt.Fprintln(os.Stderr, "Usage: oubliette session export <session_id> [--format markdown|html|json] [--output <path>]")
+		os.Exit(1)
+	}
+
+	sessionID := args[0]
+	fs := flag.NewFlagSet("session export", flag.ExitOnError)
+	formatName := fs.String("format", "markdown", "Output format: markdown, html, or json")
+	output := fs.String("output", "", "Write to this file instead of stdout")
+	children := fs.Bool("children", false, "Also export child sessions next to --output")
+	dirFlag := fs.String("dir", "", "Oubliette home directory")
+	_ = fs.Parse(args[1:])
+
+	format, err := transcript.ParseFormat(*formatName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *children && *output == "" {
+		fmt.Fprintln(os.Stderr, "Error: --children requires --output")
+		os.Exit(1)
+	}
+
+	dataDir := filepath.Join(resolveOublietteDir(*dirFlag), "data")
+	store, err := session.NewStore(dataDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening session database: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() { _ = store.Close() }()
+	mgr := session.NewManager(filepath.Join(dataDir, "projects"), store, nil, "")
+	// Legacy JSON sessions are imported by the server at startup, never here
+	if pending, err := mgr.CountJSONSessions(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	} else if pending > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d legacy session files have not been imported yet; start the server once to import them\n", pending)
+	}
+
+	t, err := transcript.Load(mgr, sessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading session: %v\n", err)
+		os.Exit(1)
+	}
+	out, err := t.Render(format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *output == "" {
+		_, _ = os.Stdout.Write(out)
+		return
+	}
+	if err := os.WriteFile(*output, out, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", *output, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Exported %s to %s\n", sessionID, *output)
+
+	if !*children {
+		return
+	}
+	// Child transcripts are named after their session ID so the parent's links resolve
+	dir := filepath.Dir(*output)
+	pending := t.Children
+	for len(pending) > 0 {
+		child := pending[0]
+		pending = pending[1:]
+		ct, err := transcript.Load(mgr, child.SessionID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping child %s: %v\n", child.SessionID, err)
+			continue
+		}
+		data, err := ct.Render(format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping child %s: %v\n", child.SessionID, err)
+			continue
+		}
+		path := filepath.Join(dir, child.SessionID+format.Extension())
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Exported %s to %s\n", child.SessionID, path)
+		pending = append(pending, ct.Children...)
+	}
+}
+
+func cmdAttach(args []string) {
+	fs := flag.NewFlagSet("attach", flag.ExitOnError)
+	workspaceFlag := fs.String("workspace", "", "Workspace to start in (default: the project's default workspace)")
+	watchFlag := fs.Bool("watch", false, "Watch another user's shell read-only")
+	terminalFlag := fs.String("terminal", "", "Terminal to watch (default: the project's most recent)")
+	recordFlag := fs.Bool("record", false, "Record the session on the server (asciicast)")
+	serverFlag := fs.String("server", "", "Server URL (default: from config, http://localhost:<port>)")
+	tokenFlag := fs.String("token", "", "API token (default: $OUBLIETTE_API_KEY)")
+	dirFlag := fs.String("dir", "", "Oubliette directory, for the server address")
+	fs.Usage = printAttachUsage
+
+	// The project may come before or after the flags
+	var projectID string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		projectID, args = args[0], args[1:]
+	}
+	_ = fs.Parse(args)
+	if projectID == "" {
+		projectID = fs.Arg(0)
+	}
+	if projectID == "" {
+		printAttachUsage()
+		os.Exit(1)
+	}
+
+	token := *tokenFlag
+	if token == "" {
+		token = os.Getenv("OUBLIETTE_API_KEY")
+	}
+	if token == "" {
+		fmt.Fprintln(os.Stderr, "Error: an API token is required (--token or OUBLIETTE_API_KEY)")
+		os.Exit(1)
+	}
+
+	serverURL := *serverFlag
+	if serverURL == "" {
+		addr := ":8080"
+		if cfg, err := config.LoadAll(filepath.Join(resolveOublietteDir(*dirFlag), "config")); err == nil && cfg.Server.Address != "" {
+			addr = cfg.Server.Address
+		}
+		serverURL = "http://localhost:" + addr[strings.LastIndex(addr, ":")+1:]
+	}
+
+	opts := terminal.DialOptions{
+		ServerURL:   serverURL,
+		Token:       token,
+		ProjectID:   projectID,
+		WorkspaceID: *workspaceFlag,
+		Mode:        terminal.ModeShell,
+		TerminalID:  *terminalFlag,
+		Record:      *recordFlag,
+	}
+	if *watchFlag {
+		opts.Mode = terminal.ModeWatch
+	}
+
+	stdinFd, isTerminal := term.GetFdInfo(os.Stdin)
+	if isTerminal {
+		if size, err := term.GetWinsize(stdinFd); err == nil {
+			opts.Cols, opts.Rows = uint(size.Width), uint(size.Height)
+		}
+	}
+
+	ws, err := terminal.Dial(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() { _ = ws.Close() }()
+
+	// Forward local size changes to the shell
+	resize := make(chan terminal.Size, 1)
+	if isTerminal && opts.Mode == terminal.ModeShell {
+		winch := make(chan os.Signal, 1)
+		signal.Notify(winch, syscall.SIGWINCH)
+		go func() {
+			for range winch {
+				if size, err := term.GetWinsize(stdinFd); err == nil {
+					select {
+					case resize <- terminal.Size{Cols: uint(size.Width), Rows: uint(size.Height)}:
+					default:
+					}
+				}
+			}
+		}()
+	}
+
+	// A shell gets every keystroke, Ctrl-C included; a watcher keeps Ctrl-C to leave
+	var restore func()
+	onReady := func(msg *terminal.Message) {
+		if msg.Mode == terminal.ModeWatch {
+			fmt.Fprintf(os.Stderr, "Watching terminal %s (Ctrl-C to stop)\r\n", msg.TerminalID)
+		} else if msg.Recording != "" {
+			fmt.Fprintf(os.Stderr, "Recording to %s\r\n", msg.Recording)
+		}
+		if isTerminal && msg.Mode == terminal.ModeShell {
+			if state, err := term.SetRawTerminal(stdinFd); err == nil {
+				restore = func() { _ = term.RestoreTerminal(stdinFd, state) }
+			}
+		}
+	}
+
+	code, err := terminal.Attach(ws, os.Stdin, os.Stdout, resize, onReady)
+	if restore != nil {
+		restore()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\nError: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(code) //nolint:gocritic // exit with the remote shell's status
+}
+
+func printAttachUsage() {
+	fmt.Println(`Attach to a project's container
+
+Usage: oubliette attach <project_id> [options]
+
+Opens an interactive shell in the project's container, in the same
+environment its agents use. The container is started if it isn't running.
+
+Options:
+  --workspace <id>   Workspace to start in (default: the project's default workspace)
+  --record           Record the session on the server as an asciicast file
+  --watch            Watch a shell someone else has open, read-only
+  --terminal <id>    Terminal to watch (default: the project's most recent)
+  --server <url>     Server URL (default: http://localhost:<port> from config)
+  --token <token>    API token (default: $OUBLIETTE_API_KEY)
+  --dir <path>       Oubliette directory, for the server address
+
+Examples:
+  oubliette attach 550e8400-e29b-41d4-a716-446655440000
+  oubliette attach 550e8400-e29b-41d4-a716-446655440000 --record
+  oubliette attach 550e8400-e29b-41d4-a716-446655440000 --watch`)
+}
+
 func initContainerRuntime() (container.Runtime, error) {
 	runtimePref := container.GetRuntimePreference()
 
@@ -1465,15 +1987,232 @@ func initContainerRuntime() (container.Runtime, error) {
 		return docker.NewRuntime()
 	case "apple-container":
 		return applecontainer.NewRuntime()
+	case "podman":
+		return podman.NewRuntime()
 	default:
 		// Auto-detect
 		if r, err := applecontainer.NewRuntime(); err == nil && r.IsAvailable() {
 			return r, nil
 		}
+		if r, err := docker.NewRuntime(); err == nil && r.IsAvailable() {
+			return r, nil
+		}
+		if r, err := podman.NewRuntime(); err == nil && r.IsAvailable() {
+			return r, nil
+		}
 		return docker.NewRuntime()
 	}
 }
 
+func cmdSecrets(args []string) {
+	if len(args) < 1 {
+		printSecretsUsage()
+		os.Exit(1)
+	}
+
+	cmd := args[0]
+	if cmd == "help" || cmd == "-h" || cmd == "--help" {
+		printSecretsUsage()
+		return
+	}
+
+	var name string
+	cmdArgs := args[1:]
+	if cmd == "set" || cmd == "rm" {
+		if len(cmdArgs) < 1 || strings.HasPrefix(cmdArgs[0], "-") {
+			fmt.Fprintf(os.Stderr, "Error: secret name required\nUsage: oubliette secrets %s <name>\n", cmd)
+			os.Exit(1)
+		}
+		name, cmdArgs = cmdArgs[0], cmdArgs[1:]
+	}
+	fs := flag.NewFlagSet("secrets "+cmd, flag.ExitOnError)
+	dirFlag := fs.String("dir", "", "Oubliette home directory")
+	_ = fs.Parse(cmdArgs)
+
+	configPath := filepath.Join(resolveOublietteDir(*dirFlag), "config", "oubliette.jsonc")
+	section, err := config.LoadSecretsSection(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	path, keyPath := section.Paths(filepath.Dir(configPath))
+
+	if cmd == "init" {
+		store, created, err := config.InitSecretStore(path, keyPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if created {
+			fmt.Printf("🔑 Created secrets key %s\n", keyPath)
+			fmt.Println("   Keep it out of backups of the config directory; without it the secrets file cannot be read.")
+		} else {
+			fmt.Printf("🔑 Using existing secrets key %s\n", keyPath)
+		}
+		if _, err := os.Stat(path); errors.Is(err, iofs.ErrNotExist) {
+			if err := store.Save(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		fmt.Printf("✅ Secrets file ready: %s\n", path)
+		return
+	}
+
+	store, err := config.OpenSecretStore(path, keyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch cmd {
+	case "set":
+		value, err := readSecretValue(name)
+		if err == nil {
+			err = store.Set(name, value)
+		}
+		if err == nil {
+			err = store.Save()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Stored secret %s; reference it as \"secret:%s\"\n", name, name)
+	case "list":
+		names := store.Names()
+		if len(names) == 0 {
+			fmt.Println("No secrets stored.")
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "NAME\tVALUE")
+		for _, n := range names {
+			value, _ := store.Get(n)
+			_, _ = fmt.Fprintf(w, "%s\t%s\n", n, config.MaskSecret(value))
+		}
+		_ = w.Flush()
+	case "rm":
+		if !store.Delete(name) {
+			fmt.Fprintf(os.Stderr, "Error: secret %s not found\n", name)
+			os.Exit(1)
+		}
+		if err := store.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Removed secret %s\n", name)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown secrets command: %s\n", cmd)
+		printSecretsUsage()
+		os.Exit(1)
+	}
+}
+
+// readSecretValue reads a secret from stdin, without echo on a terminal
+func readSecretValue(name string) (string, error) {
+	fd := os.Stdin.Fd()
+	if term.IsTerminal(fd) {
+		fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+		if state, err := term.SaveState(fd); err == nil {
+			_ = term.DisableEcho(fd, state)
+			defer func() {
+				_ = term.RestoreTerminal(fd, state)
+				fmt.Fprintln(os.Stderr)
+			}()
+		}
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("failed to read value: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read value: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func printSecretsUsage() {
+	fmt.Println(`Encrypted Secrets
+
+Usage: oubliette secrets <command> [options]
+
+Commands:
+  init          Create the key file and an empty secrets file
+  set <name>    Store a secret, read from stdin (prompted without echo on a terminal)
+  list          List stored secrets, masked
+  rm <name>     Remove a secret
+  help          Show this help
+
+Flags:
+  --dir <path>  Oubliette home directory
+
+Locations come from the "secrets" section of oubliette.jsonc (default:
+config/secrets.enc and config/secrets.key). Reference a stored secret from a
+credential as "secret:<name>".
+
+Examples:
+  oubliette secrets init
+  echo "$ANTHROPIC_API_KEY" | oubliette secrets set anthropic
+  oubliette secrets list
+  oubliette secrets rm anthropic`)
+}
+
+func cmdDoctor(args []string) {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	jsonFlag := fs.Bool("json", false, "Print the report as JSON")
+	pullFlag := fs.Bool("pull", false, "Pull missing container images")
+	dirFlag := fs.String("dir", "", "Oubliette home directory")
+	fs.Usage = printDoctorUsage
+	_ = fs.Parse(args)
+
+	oublietteDir := resolveOublietteDir(*dirFlag)
+	opts := doctor.Options{
+		ConfigDir:  filepath.Join(oublietteDir, "config"),
+		DataDir:    filepath.Join(oublietteDir, "data"),
+		SocketsDir: mcp.SocketsBaseDir,
+		Pull:       *pullFlag,
+	}
+	if containerRT, err := initContainerRuntime(); err == nil {
+		defer func() { _ = containerRT.Close() }()
+		opts.Runtime = containerRT
+	}
+
+	report := doctor.Run(context.Background(), opts)
+	if *jsonFlag {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("🩺 Checking %s\n\n", oublietteDir)
+		fmt.Print(report.Summary())
+	}
+	if !report.OK {
+		os.Exit(1)
+	}
+}
+
+func printDoctorUsage() {
+	fmt.Println(`Setup Diagnostics
+
+Usage: oubliette doctor [options]
+
+Checks the configuration, credentials, data directory, disk space, databases,
+container runtime and images, relay sockets and orphaned containers, and
+suggests a fix for each problem. Exits non-zero if any check fails.
+
+Flags:
+  --json        Print the report as JSON
+  --pull        Pull missing container images
+  --dir <path>  Oubliette home directory
+
+Examples:
+  oubliette doctor
+  oubliette doctor --pull
+  oubliette doctor --json | jq '.checks[] | select(.status != "ok")'`)
+}
+
 // resolveOublietteDir determines the oubliette home directory with precedence:
 // 1. Explicit flag (if provided)
 // 2. OUBLIETTE_HOME env var
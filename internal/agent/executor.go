@@ -4,6 +4,8 @@
 //
 // This file contains:
 // - StreamingExecutor interface for bidirectional streaming sessions
+// - FileSender optional interface for messages with file parts
+// - Retrier optional interface for resending a failed turn
 //
 // StreamingExecutor enables real-time communication with agent backends,
 // supporting message sending, event streaming, and graceful shutdown.
@@ -41,3 +43,18 @@ type StreamingExecutor interface {
 	// IsClosed returns whether the executor has been closed
 	IsClosed() bool
 }
+
+// FileSender is implemented by executors that can attach files to a message.
+// Executors without it receive the message text only.
+type FileSender interface {
+	SendMessageWithFiles(message string, files []FilePart) error
+}
+
+// Retrier is implemented by executors that can resend the last message after
+// a transient provider error. The resend replaces the failed message in the
+// runtime session rather than following it. model overrides the session's
+// model for that attempt only ("providerID/modelID"; empty keeps the session's
+// model).
+type Retrier interface {
+	RetryLastMessage(model string) error
+}
@@ -104,6 +104,36 @@ func TestParseSSEEvent_SessionStatusIdle(t *testing.T) {
 	}
 }
 
+func TestParseSSEEvent_SessionError(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		wantText      string
+		wantCode      int
+		wantRetryable bool
+	}{
+		{"overloaded", `{"type":"session.error","properties":{"sessionID":"ses_1","error":{"name":"APIError","data":{"message":"Overloaded","statusCode":529,"isRetryable":false}}}}`, "Overloaded", 529, true},
+		{"rate limited", `{"type":"session.error","properties":{"error":{"name":"APIError","data":{"message":"Rate limit exceeded","isRetryable":true}}}}`, "Rate limit exceeded", 0, true},
+		{"bad request", `{"type":"session.error","properties":{"error":{"name":"APIError","data":{"message":"prompt is too long","statusCode":400}}}}`, "prompt is too long", 400, false},
+		{"auth", `{"type":"session.error","properties":{"error":{"name":"ProviderAuthError","data":{"message":"invalid x-api-key","statusCode":401}}}}`, "invalid x-api-key", 401, false},
+		{"aborted", `{"type":"session.error","properties":{"error":{"name":"MessageAbortedError","data":{}}}}`, "MessageAbortedError", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := parseSSEEvent(tt.data)
+			if err != nil {
+				t.Fatalf("parseSSEEvent() returned error: %v", err)
+			}
+			if event.Type != agent.StreamEventError {
+				t.Fatalf("Type = %q, want %q", event.Type, agent.StreamEventError)
+			}
+			if event.Text != tt.wantText || event.ErrorCode != tt.wantCode || event.Retryable != tt.wantRetryable {
+				t.Errorf("event = %q/%d/%v, want %q/%d/%v", event.Text, event.ErrorCode, event.Retryable, tt.wantText, tt.wantCode, tt.wantRetryable)
+			}
+		})
+	}
+}
+
 func TestParseSSEEvent_SessionIdle_Dropped(t *testing.T) {
 	data := `{"type":"session.idle"}`
 
@@ -165,6 +195,42 @@ func TestParseSSEEvent_StepStart(t *testing.T) {
 	}
 }
 
+func TestParseSSEEvent_StepFinishTokens(t *testing.T) {
+	data := `{"type":"message.part.updated","properties":{"part":{"type":"step-finish","tokens":{"input":120,"output":30,"reasoning":10,"cache":{"read":0,"write":0}}}}}`
+
+	event, err := parseSSEEvent(data)
+	if err != nil {
+		t.Fatalf("parseSSEEvent() returned error: %v", err)
+	}
+
+	if event.Subtype != "step-finish" {
+		t.Errorf("Subtype = %q, want 'step-finish'", event.Subtype)
+	}
+	if event.InputTokens != 120 || event.OutputTokens != 40 {
+		t.Errorf("tokens = %d/%d, want 120/40", event.InputTokens, event.OutputTokens)
+	}
+}
+
+func TestParseSSEEvent_Reasoning(t *testing.T) {
+	streaming := `{"type":"message.part.updated","properties":{"part":{"type":"reasoning","id":"prt_1","text":"Check the"},"delta":"the"}}`
+	event, err := parseSSEEvent(streaming)
+	if err != nil {
+		t.Fatalf("parseSSEEvent() returned error: %v", err)
+	}
+	if event.Subtype != "reasoning" || event.Text != "" {
+		t.Errorf("streaming reasoning = %q/%q, want a bare progress event", event.Subtype, event.Text)
+	}
+
+	final := `{"type":"message.part.updated","properties":{"part":{"type":"reasoning","id":"prt_1","text":"Check the tests first"}}}`
+	event, err = parseSSEEvent(final)
+	if err != nil {
+		t.Fatalf("parseSSEEvent() returned error: %v", err)
+	}
+	if event.Text != "Check the tests first" || event.ID != "prt_1" {
+		t.Errorf("consolidated reasoning = %q (id %q)", event.Text, event.ID)
+	}
+}
+
 func TestEventTypeConstants(t *testing.T) {
 	tests := []struct {
 		name     string
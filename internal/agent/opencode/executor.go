@@ -4,7 +4,7 @@
 //
 // This file contains:
 // - StreamingExecutor struct implementing agent.StreamingExecutor
-// - Message sending via async HTTP (SendMessage)
+// - Message sending via async HTTP (SendMessage, RetryLastMessage)
 // - SSE event stream processing (processEvents)
 // - Event parsing and normalization to agent.StreamEvent
 //
@@ -24,6 +24,9 @@ import (
 	"sync"
 
 	"github.com/HyphaGroup/oubliette/internal/agent"
+	"github.com/HyphaGroup/oubliette/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // StreamingExecutor implements agent.StreamingExecutor for OpenCode
@@ -43,10 +46,23 @@ type StreamingExecutor struct {
 	closed    bool
 	eventConn io.ReadCloser
 	exitCode  int
+
+	// Last message sent, kept so a failed turn can be resent
+	lastMessage string
+	lastFiles   []agent.FilePart
+
+	// Current turn span, ended on completion (nil between turns)
+	turnSpan         trace.Span
+	turnInputTokens  int
+	turnOutputTokens int
 }
 
-// Ensure StreamingExecutor implements agent.StreamingExecutor
-var _ agent.StreamingExecutor = (*StreamingExecutor)(nil)
+// Ensure StreamingExecutor implements agent.StreamingExecutor, agent.FileSender and agent.Retrier
+var (
+	_ agent.StreamingExecutor = (*StreamingExecutor)(nil)
+	_ agent.FileSender        = (*StreamingExecutor)(nil)
+	_ agent.Retrier           = (*StreamingExecutor)(nil)
+)
 
 // NewStreamingExecutor creates a new streaming executor
 // model should be in "providerID/modelID" format (e.g., "anthropic/claude-sonnet-4-5")
@@ -82,15 +98,99 @@ func NewStreamingExecutor(ctx context.Context, server *Server, sessionID, model,
 
 // SendMessage sends a user message to the session
 func (e *StreamingExecutor) SendMessage(message string) error {
-	e.mu.RLock()
+	return e.SendMessageWithFiles(message, nil)
+}
+
+// SendMessageWithFiles sends a user message with file parts to the session
+func (e *StreamingExecutor) SendMessageWithFiles(message string, files []agent.FilePart) error {
+	e.mu.Lock()
 	if e.closed {
-		e.mu.RUnlock()
+		e.mu.Unlock()
 		return fmt.Errorf("executor is closed")
 	}
+	e.lastMessage, e.lastFiles = message, files
+	e.mu.Unlock()
+
+	return e.send(message, files, e.model)
+}
+
+// RetryLastMessage replaces the last message, and the failed reply to it, with
+// a resend to model if set
+func (e *StreamingExecutor) RetryLastMessage(model string) error {
+	e.mu.RLock()
+	closed, message, files := e.closed, e.lastMessage, e.lastFiles
 	e.mu.RUnlock()
 
+	if closed {
+		return fmt.Errorf("executor is closed")
+	}
+	if message == "" {
+		return fmt.Errorf("no message to retry")
+	}
+	if model == "" {
+		model = e.model
+	}
+	if err := e.server.RevertLastUserMessage(e.ctx, e.sessionID); err != nil {
+		return fmt.Errorf("failed to revert failed message: %w", err)
+	}
+	return e.send(message, files, model)
+}
+
+// send starts a turn by posting a message to the model
+func (e *StreamingExecutor) send(message string, files []agent.FilePart, model string) error {
+	ctx := e.startTurn(model)
+
 	// Send message via async endpoint (returns immediately, events come via SSE)
-	return e.server.SendMessageAsync(e.ctx, e.sessionID, message, e.model, e.reasoningLevel)
+	if err := e.server.SendMessageAsync(ctx, e.sessionID, message, model, e.reasoningLevel, files); err != nil {
+		e.endTurn(err)
+		return err
+	}
+	return nil
+}
+
+// startTurn opens the span for a new turn, ending any turn still in flight
+func (e *StreamingExecutor) startTurn(model string) context.Context {
+	ctx, span := tracing.Start(e.ctx, "agent.turn",
+		attribute.String("opencode.session_id", e.sessionID),
+		attribute.String("gen_ai.request.model", model),
+	)
+
+	e.mu.Lock()
+	prev := e.turnSpan
+	e.turnSpan = span
+	e.turnInputTokens, e.turnOutputTokens = 0, 0
+	e.mu.Unlock()
+
+	if prev != nil {
+		prev.End()
+	}
+	return ctx
+}
+
+// recordTurnTokens adds step token usage to the current turn
+func (e *StreamingExecutor) recordTurnTokens(input, output int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.turnInputTokens += input
+	e.turnOutputTokens += output
+}
+
+// endTurn ends the current turn span, if any
+func (e *StreamingExecutor) endTurn(err error) {
+	e.mu.Lock()
+	span := e.turnSpan
+	input, output := e.turnInputTokens, e.turnOutputTokens
+	e.turnSpan = nil
+	e.mu.Unlock()
+
+	if span == nil {
+		return
+	}
+	span.SetAttributes(
+		attribute.Int("gen_ai.usage.input_tokens", input),
+		attribute.Int("gen_ai.usage.output_tokens", output),
+	)
+	tracing.End(span, err)
 }
 
 // Cancel requests termination of the current operation via OpenCode's abort endpoint
@@ -129,6 +229,7 @@ func (e *StreamingExecutor) Close() error {
 	e.closed = true
 	e.mu.Unlock()
 
+	e.endTurn(nil)
 	e.cancel()
 
 	if e.eventConn != nil {
@@ -170,7 +271,9 @@ func (e *StreamingExecutor) processEvents() {
 		line, err := reader.ReadString('\n')
 		if err != nil {
 			if err != io.EOF {
-				e.errorsCh <- fmt.Errorf("error reading events: %w", err)
+				err = fmt.Errorf("error reading events: %w", err)
+				e.endTurn(err)
+				e.errorsCh <- err
 			}
 			return
 		}
@@ -196,6 +299,15 @@ func (e *StreamingExecutor) processEvents() {
 			continue
 		}
 
+		switch {
+		case event.InputTokens > 0 || event.OutputTokens > 0:
+			e.recordTurnTokens(event.InputTokens, event.OutputTokens)
+		case event.Type == agent.StreamEventCompletion:
+			e.endTurn(nil)
+		case event.Type == agent.StreamEventError:
+			e.endTurn(fmt.Errorf("%s", event.Text))
+		}
+
 		// Send event
 		select {
 		case e.eventsCh <- event:
@@ -242,13 +354,13 @@ func parseSSEEvent(data string) (*agent.StreamEvent, error) {
 					Raw:  raw,
 				}, nil
 			}
-			// Consolidated full text (no delta prop)
-			text, _ := part["text"].(string)
-			return &agent.StreamEvent{
-				Type: agent.StreamEventMessage,
-				Text: text,
-				Raw:  raw,
-			}, nil
+			// Consolidated full text (no delta prop). The part ID lets
+			// consumers coalesce repeated updates of the same part.
+			event := &agent.StreamEvent{Type: agent.StreamEventMessage, Raw: raw}
+			event.Text, _ = part["text"].(string)
+			event.ID, _ = part["id"].(string)
+			event.MessageID, _ = part["messageID"].(string)
+			return event, nil
 		case "tool-invocation":
 			event := &agent.StreamEvent{Type: agent.StreamEventToolCall, Raw: raw}
 			event.ToolID, _ = part["id"].(string)
@@ -266,11 +378,23 @@ func parseSSEEvent(data string) (*agent.StreamEvent, error) {
 			event.IsError, _ = part["isError"].(bool)
 			return event, nil
 		case "step-start", "step-finish", "reasoning":
-			return &agent.StreamEvent{
+			event := &agent.StreamEvent{
 				Type:    agent.StreamEventSystem,
 				Subtype: partType,
 				Raw:     raw,
-			}, nil
+			}
+			switch partType {
+			case "step-finish":
+				event.InputTokens, event.OutputTokens = parseStepTokens(part)
+			case "reasoning":
+				// Only the consolidated update carries the reasoning text;
+				// streaming updates stay as bare progress events
+				if delta, _ := props["delta"].(string); delta == "" {
+					event.Text, _ = part["text"].(string)
+					event.ID, _ = part["id"].(string)
+				}
+			}
+			return event, nil
 		default:
 			return nil, nil
 		}
@@ -293,6 +417,9 @@ func parseSSEEvent(data string) (*agent.StreamEvent, error) {
 			Raw:     raw,
 		}, nil
 
+	case "session.error":
+		return parseSessionError(props, raw), nil
+
 	case "session.idle":
 		// Redundant -- session.status idle already emits completion
 		return nil, nil
@@ -309,3 +436,56 @@ func parseSSEEvent(data string) (*agent.StreamEvent, error) {
 		}, nil
 	}
 }
+
+// parseSessionError converts a session.error event. OpenCode reports provider
+// failures as {"name": "APIError", "data": {"message", "statusCode", "isRetryable"}}.
+func parseSessionError(props, raw map[string]interface{}) *agent.StreamEvent {
+	event := &agent.StreamEvent{Type: agent.StreamEventError, Raw: raw}
+	event.SessionID, _ = props["sessionID"].(string)
+
+	errObj, _ := props["error"].(map[string]interface{})
+	name, _ := errObj["name"].(string)
+	data, _ := errObj["data"].(map[string]interface{})
+	event.Text, _ = data["message"].(string)
+	if event.Text == "" {
+		event.Text = name
+	}
+	if status, ok := data["statusCode"].(float64); ok {
+		event.ErrorCode = int(status)
+	}
+	isRetryable, _ := data["isRetryable"].(bool)
+	event.Retryable = isRetryableError(name, event.ErrorCode, isRetryable, event.Text)
+	return event
+}
+
+// isRetryableError reports whether a provider error is transient: rate limits,
+// overload (Anthropic's 529) and upstream 5xx. Auth failures and aborts are not.
+func isRetryableError(name string, status int, isRetryable bool, message string) bool {
+	switch name {
+	case "ProviderAuthError", "MessageAbortedError", "MessageOutputLengthError":
+		return false
+	}
+	if isRetryable {
+		return true
+	}
+	switch status {
+	case 408, 429, 500, 502, 503, 504, 529:
+		return true
+	}
+	message = strings.ToLower(message)
+	return strings.Contains(message, "overloaded") || strings.Contains(message, "rate limit")
+}
+
+// parseStepTokens reads model token usage from a step-finish part.
+// Reasoning tokens are billed as output.
+func parseStepTokens(part map[string]interface{}) (input, output int) {
+	tokens, ok := part["tokens"].(map[string]interface{})
+	if !ok {
+		return 0, 0
+	}
+	num := func(key string) int {
+		v, _ := tokens[key].(float64)
+		return int(v)
+	}
+	return num("input"), num("output") + num("reasoning")
+}
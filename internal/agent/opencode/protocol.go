@@ -5,6 +5,7 @@
 // This file contains:
 // - HTTP client methods for OpenCode REST API (doRequest)
 // - Message sending (SendMessage, SendMessageAsync)
+// - Session forking (ForkSession)
 // - SSE event subscription (SubscribeEvents)
 // - SSE stream reader (sseReader)
 //
@@ -22,7 +23,10 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/HyphaGroup/oubliette/internal/agent"
 	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // SendMessage sends a message to a session and returns the response (synchronous)
@@ -79,11 +83,21 @@ func (s *Server) SendMessage(ctx context.Context, sessionID, message string) (st
 // Uses the /session/:id/prompt_async endpoint
 // model format: "providerID/modelID" (e.g., "anthropic/claude-sonnet-4-5")
 // variant maps to OpenCode's reasoning variant ("low", "medium", "high", or "" for none)
-func (s *Server) SendMessageAsync(ctx context.Context, sessionID, message, model, variant string) error {
+// files are sent as OpenCode file parts after the text part
+func (s *Server) SendMessageAsync(ctx context.Context, sessionID, message, model, variant string, files []agent.FilePart) error {
+	parts := []map[string]string{
+		{"type": "text", "text": message},
+	}
+	for _, f := range files {
+		parts = append(parts, map[string]string{
+			"type":     "file",
+			"mime":     f.Mime,
+			"filename": f.Filename,
+			"url":      f.URL,
+		})
+	}
 	body := map[string]interface{}{
-		"parts": []map[string]string{
-			{"type": "text", "text": message},
-		},
+		"parts": parts,
 	}
 
 	// Include model if specified (format: "providerID/modelID")
@@ -129,6 +143,112 @@ func (s *Server) AbortSession(ctx context.Context, sessionID string) error {
 	return nil
 }
 
+// ForkSession creates a new session holding the conversation through the first
+// userMessages user messages of sessionID. OpenCode's fork copies the messages
+// before a given message ID, so the cutoff is the next user message; with no
+// later user message the whole conversation is copied.
+func (s *Server) ForkSession(ctx context.Context, sessionID string, userMessages int) (string, error) {
+	messages, err := s.listMessages(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	body := map[string]string{}
+	seen := 0
+	for _, m := range messages {
+		if m.Info.Role != "user" {
+			continue
+		}
+		if seen == userMessages {
+			body["messageID"] = m.Info.ID
+			break
+		}
+		seen++
+	}
+	jsonBody, _ := json.Marshal(body)
+
+	forkResp, err := s.doRequest(ctx, "POST", fmt.Sprintf("/session/%s/fork", sessionID), bytes.NewReader(jsonBody))
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = forkResp.Body.Close() }()
+	if forkResp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(forkResp.Body)
+		return "", fmt.Errorf("fork session failed: %s", string(respBody))
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(forkResp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode fork response: %w", err)
+	}
+	if result.ID == "" {
+		return "", fmt.Errorf("fork response has no session ID")
+	}
+	return result.ID, nil
+}
+
+// RevertLastUserMessage reverts a session to before its last user message.
+// OpenCode drops the reverted messages when the next message is sent, so a
+// resent prompt replaces the failed one instead of following it.
+func (s *Server) RevertLastUserMessage(ctx context.Context, sessionID string) error {
+	messages, err := s.listMessages(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	var messageID string
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Info.Role == "user" {
+			messageID = messages[i].Info.ID
+			break
+		}
+	}
+	if messageID == "" {
+		return fmt.Errorf("session %s has no user message to revert", sessionID)
+	}
+	jsonBody, _ := json.Marshal(map[string]string{"messageID": messageID})
+
+	resp, err := s.doRequest(ctx, "POST", fmt.Sprintf("/session/%s/revert", sessionID), bytes.NewReader(jsonBody))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("revert session failed: %s", string(respBody))
+	}
+	return nil
+}
+
+// sessionMessage is the part of a listed message that identifies it
+type sessionMessage struct {
+	Info struct {
+		ID   string `json:"id"`
+		Role string `json:"role"`
+	} `json:"info"`
+}
+
+// listMessages returns a session's messages, oldest first
+func (s *Server) listMessages(ctx context.Context, sessionID string) ([]sessionMessage, error) {
+	resp, err := s.doRequest(ctx, "GET", fmt.Sprintf("/session/%s/message", sessionID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("list messages failed: %s", string(respBody))
+	}
+
+	var messages []sessionMessage
+	if err := json.NewDecoder(resp.Body).Decode(&messages); err != nil {
+		return nil, fmt.Errorf("failed to decode messages: %w", err)
+	}
+	return messages, nil
+}
+
 // SubscribeEvents connects to the SSE event stream using interactive exec
 // Returns a reader that streams SSE events incrementally
 func (s *Server) SubscribeEvents(ctx context.Context) (io.ReadCloser, error) {
@@ -178,6 +298,20 @@ func (r *sseReader) Close() error {
 
 // doRequest executes an HTTP request via exec curl in the container
 func (s *Server) doRequest(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	ctx, span := tracing.Start(ctx, "opencode.request",
+		attribute.String("http.request.method", method),
+		attribute.String("url.path", path),
+	)
+	resp, err := s.execRequest(ctx, method, path, body)
+	if err == nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	}
+	tracing.End(span, err)
+	return resp, err
+}
+
+// execRequest performs an OpenCode API request by running curl inside the container
+func (s *Server) execRequest(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
 	url := fmt.Sprintf("http://127.0.0.1:%d%s", serverPort, path)
 
 	var curlCmd string
@@ -7,6 +7,7 @@ import (
 
 	"github.com/HyphaGroup/oubliette/internal/agent"
 	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/metrics"
 )
 
 // Runtime implements agent.Runtime for OpenCode
@@ -18,8 +19,12 @@ type Runtime struct {
 	servers   map[string]*Server // containerID -> server
 }
 
-// Ensure Runtime implements agent.Runtime
-var _ agent.Runtime = (*Runtime)(nil)
+// Ensure Runtime implements agent.Runtime, agent.Warmer and agent.Forker
+var (
+	_ agent.Runtime = (*Runtime)(nil)
+	_ agent.Warmer  = (*Runtime)(nil)
+	_ agent.Forker  = (*Runtime)(nil)
+)
 
 // NewRuntime creates a new OpenCode runtime
 func NewRuntime(containerRuntime container.Runtime) *Runtime {
@@ -75,7 +80,7 @@ func (r *Runtime) ExecuteStreaming(ctx context.Context, req *agent.ExecuteReques
 	}
 
 	if req.Prompt != "" {
-		if err := executor.SendMessage(req.Prompt); err != nil {
+		if err := executor.SendMessageWithFiles(req.Prompt, req.Files); err != nil {
 			_ = executor.Close()
 			return nil, fmt.Errorf("failed to send initial message: %w", err)
 		}
@@ -84,6 +89,15 @@ func (r *Runtime) ExecuteStreaming(ctx context.Context, req *agent.ExecuteReques
 	return executor, nil
 }
 
+// ForkSession forks an OpenCode session through the given number of user messages
+func (r *Runtime) ForkSession(ctx context.Context, containerID, workingDir, sessionID string, userMessages int) (string, error) {
+	server, err := r.ensureServer(ctx, containerID, workingDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to start OpenCode server: %w", err)
+	}
+	return server.ForkSession(ctx, sessionID, userMessages)
+}
+
 // Ping checks if the runtime is available
 func (r *Runtime) Ping(ctx context.Context) error {
 	return nil
@@ -101,6 +115,12 @@ func (r *Runtime) Close() error {
 	return nil
 }
 
+// Warm starts the OpenCode server in a container ahead of its first session
+func (r *Runtime) Warm(ctx context.Context, containerID, workingDir string) error {
+	_, err := r.ensureServer(ctx, containerID, workingDir)
+	return err
+}
+
 // ensureServer ensures an OpenCode server is running in the container
 func (r *Runtime) ensureServer(ctx context.Context, containerID, workingDir string) (*Server, error) {
 	r.serversMu.Lock()
@@ -111,6 +131,7 @@ func (r *Runtime) ensureServer(ctx context.Context, containerID, workingDir stri
 			return server, nil
 		}
 		delete(r.servers, containerID)
+		metrics.RecordOpenCodeRestart()
 	}
 
 	server := NewServer(r.containerRuntime, containerID, workingDir)
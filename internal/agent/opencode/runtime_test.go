@@ -2,9 +2,12 @@ package opencode
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/HyphaGroup/oubliette/internal/agent"
+	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/testutil"
 )
 
 func TestNewRuntime(t *testing.T) {
@@ -32,3 +35,89 @@ func TestRuntimeClose(t *testing.T) {
 }
 
 var _ agent.Runtime = (*Runtime)(nil)
+
+func TestServerForkSession(t *testing.T) {
+	rt := testutil.NewFakeRuntime()
+	defer func() { _ = rt.Close() }()
+	id, err := rt.Create(context.Background(), container.CreateConfig{Name: "oc", Image: "oubliette-base:latest", Cmd: []string{"sleep", "300"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.Start(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+
+	var forkCmd string
+	rt.ExecHandler = func(_ string, cfg container.ExecConfig) (*container.ExecResult, bool) {
+		cmd := cfg.Cmd[len(cfg.Cmd)-1]
+		switch {
+		case strings.Contains(cmd, "/session/ses_src/message"):
+			return &container.ExecResult{Stdout: `[
+				{"info": {"id": "msg_1", "role": "user"}}, {"info": {"id": "msg_2", "role": "assistant"}},
+				{"info": {"id": "msg_3", "role": "user"}}, {"info": {"id": "msg_4", "role": "assistant"}},
+				{"info": {"id": "msg_5", "role": "user"}}, {"info": {"id": "msg_6", "role": "assistant"}}]`}, true
+		case strings.Contains(cmd, "/session/ses_src/fork"):
+			forkCmd = cmd
+			return &container.ExecResult{Stdout: `{"id": "ses_fork"}`}, true
+		}
+		return nil, false
+	}
+	server := NewServer(rt, id, "/workspace")
+
+	tests := []struct {
+		userMessages int
+		wantBody     string
+	}{
+		{2, `{"messageID":"msg_5"}`},
+		{3, `{}`},
+	}
+	for _, tt := range tests {
+		forked, err := server.ForkSession(context.Background(), "ses_src", tt.userMessages)
+		if err != nil {
+			t.Fatalf("ForkSession(%d) error = %v", tt.userMessages, err)
+		}
+		if forked != "ses_fork" || !strings.Contains(forkCmd, "-d '"+tt.wantBody+"'") {
+			t.Errorf("ForkSession(%d) = %q via %q, want body %s", tt.userMessages, forked, forkCmd, tt.wantBody)
+		}
+	}
+}
+
+func TestServerRevertLastUserMessage(t *testing.T) {
+	rt := testutil.NewFakeRuntime()
+	defer func() { _ = rt.Close() }()
+	id, err := rt.Create(context.Background(), container.CreateConfig{Name: "oc", Image: "oubliette-base:latest", Cmd: []string{"sleep", "300"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.Start(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+
+	var revertCmd string
+	rt.ExecHandler = func(_ string, cfg container.ExecConfig) (*container.ExecResult, bool) {
+		cmd := cfg.Cmd[len(cfg.Cmd)-1]
+		switch {
+		case strings.Contains(cmd, "/session/ses_src/message"):
+			return &container.ExecResult{Stdout: `[
+				{"info": {"id": "msg_1", "role": "user"}}, {"info": {"id": "msg_2", "role": "assistant"}},
+				{"info": {"id": "msg_3", "role": "user"}}, {"info": {"id": "msg_4", "role": "assistant"}}]`}, true
+		case strings.Contains(cmd, "/session/ses_empty/message"):
+			return &container.ExecResult{Stdout: `[]`}, true
+		case strings.Contains(cmd, "/session/ses_src/revert"):
+			revertCmd = cmd
+			return &container.ExecResult{Stdout: `{"id": "ses_src"}`}, true
+		}
+		return nil, false
+	}
+	server := NewServer(rt, id, "/workspace")
+
+	if err := server.RevertLastUserMessage(context.Background(), "ses_src"); err != nil {
+		t.Fatalf("RevertLastUserMessage() error = %v", err)
+	}
+	if !strings.Contains(revertCmd, `-d '{"messageID":"msg_3"}'`) {
+		t.Errorf("RevertLastUserMessage() via %q, want the last user message reverted", revertCmd)
+	}
+	if err := server.RevertLastUserMessage(context.Background(), "ses_empty"); err == nil {
+		t.Error("RevertLastUserMessage() with no user message: expected error")
+	}
+}
@@ -4,6 +4,8 @@
 //
 // This file contains:
 // - Runtime interface for agent execution backends
+// - Warmer interface for runtimes that can start ahead of the first session
+// - Forker interface for runtimes that can branch a conversation
 // - ExecuteResponse for single-turn execution results
 
 package agent
@@ -25,6 +27,25 @@ type Runtime interface {
 	Close() error
 }
 
+// Warmer is implemented by runtimes whose in-container server can be started
+// and health-checked before any session needs it (used by the container pool)
+type Warmer interface {
+	// Warm starts the agent server in a container and waits until it is healthy
+	Warm(ctx context.Context, containerID, workingDir string) error
+
+	// StopServer stops the agent server for a container
+	StopServer(containerID string)
+}
+
+// Forker is implemented by runtimes that can branch a conversation. Runtimes
+// without it are forked by seeding a new session with the earlier conversation.
+type Forker interface {
+	// ForkSession copies a runtime session's conversation through its first
+	// userMessages user messages (and the replies to them) into a new runtime
+	// session, returning the new session's ID
+	ForkSession(ctx context.Context, containerID, workingDir, sessionID string, userMessages int) (string, error)
+}
+
 // ExecuteResponse contains output from single-turn execution
 type ExecuteResponse struct {
 	SessionID    string
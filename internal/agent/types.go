@@ -52,12 +52,27 @@ type StreamEvent struct {
 	NumTurns   int    `json:"numTurns,omitempty"`
 	DurationMs int    `json:"durationMs,omitempty"`
 
+	// Error fields (set on error events)
+	ErrorCode int  `json:"errorCode,omitempty"` // Provider HTTP status, when known
+	Retryable bool `json:"retryable,omitempty"` // Transient provider error (rate limit, overload) worth retrying
+
+	// Usage fields (set on events that report model token consumption)
+	InputTokens  int `json:"inputTokens,omitempty"`
+	OutputTokens int `json:"outputTokens,omitempty"`
+
 	Timestamp int64 `json:"timestamp,omitempty"`
 
 	// Raw data for backend-specific fields
 	Raw map[string]interface{} `json:"-"`
 }
 
+// FilePart is a file passed to the model alongside a message
+type FilePart struct {
+	Mime     string // Content type (e.g., image/png)
+	Filename string // Display name
+	URL      string // data: URL carrying the content
+}
+
 // ExecuteRequest contains parameters for agent execution
 type ExecuteRequest struct {
 	// Required
@@ -65,6 +80,9 @@ type ExecuteRequest struct {
 	ContainerID string
 	WorkingDir  string
 
+	// Files sent alongside the prompt (e.g., image attachments)
+	Files []FilePart
+
 	// Session management
 	SessionID string // Empty for new, set for continuation
 	ProjectID string // Project ID for session identity
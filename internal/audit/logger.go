@@ -4,10 +4,14 @@ import (
 	"encoding/json"
 	"log/slog"
 	"os"
+	"strings"
 	"sync"
 	"time"
 )
 
+// identityTokenPrefix matches auth.IdentityTokenPrefix
+const identityTokenPrefix = "oidc:"
+
 // Operation represents the type of auditable operation
 type Operation string
 
@@ -16,6 +20,22 @@ const (
 	OpProjectDelete Operation = "project.delete"
 	OpTokenCreate   Operation = "token.create"
 	OpTokenRevoke   Operation = "token.revoke"
+	OpTokenQuota    Operation = "token.quota"
+
+	OpSessionSpawn       Operation = "session.spawn"
+	OpSessionEnd         Operation = "session.end"
+	OpContainerStart     Operation = "container.start"
+	OpContainerStop      Operation = "container.stop"
+	OpContainerExec      Operation = "container.exec"
+	OpContainerBuild     Operation = "container.build"
+	OpTerminalAttach     Operation = "terminal.attach"
+	OpScheduleCreate     Operation = "schedule.create"
+	OpScheduleUpdate     Operation = "schedule.update"
+	OpScheduleDelete     Operation = "schedule.delete"
+	OpScheduleTrigger    Operation = "schedule.trigger"
+	OpWorkspaceDelete    Operation = "workspace.delete"
+	OpCallerToolResponse Operation = "caller_tool.response"
+	OpConfigReload       Operation = "config.reload"
 )
 
 // Event represents an audit log entry
@@ -24,6 +44,7 @@ type Event struct {
 	Operation   Operation              `json:"operation"`
 	TokenID     string                 `json:"token_id,omitempty"`
 	TokenScope  string                 `json:"token_scope,omitempty"`
+	Identity    string                 `json:"identity,omitempty"` // SSO subject for OIDC callers
 	ProjectID   string                 `json:"project_id,omitempty"`
 	SessionID   string                 `json:"session_id,omitempty"`
 	WorkspaceID string                 `json:"workspace_id,omitempty"`
@@ -33,10 +54,12 @@ type Event struct {
 	Details     map[string]interface{} `json:"details,omitempty"`
 }
 
-// Logger handles audit logging
+// Logger handles audit logging. Events are written to stdout and, when a sink
+// is set, persisted to durable storage.
 type Logger struct {
 	logger  *slog.Logger
 	enabled bool
+	sink    Sink
 	mu      sync.RWMutex
 }
 
@@ -71,10 +94,18 @@ func (l *Logger) SetEnabled(enabled bool) {
 	l.enabled = enabled
 }
 
+// SetSink sets the durable store events are appended to (nil disables persistence)
+func (l *Logger) SetSink(sink Sink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sink = sink
+}
+
 // Log records an audit event
 func (l *Logger) Log(event *Event) {
 	l.mu.RLock()
 	enabled := l.enabled
+	sink := l.sink
 	l.mu.RUnlock()
 
 	if !enabled {
@@ -91,7 +122,13 @@ func (l *Logger) Log(event *Event) {
 		slog.Bool("success", event.Success),
 	}
 
-	if event.TokenID != "" {
+	// OIDC callers carry a synthetic token ID ("oidc:<subject>"), which is not a secret
+	if event.Identity == "" && strings.HasPrefix(event.TokenID, identityTokenPrefix) {
+		event.Identity = strings.TrimPrefix(event.TokenID, identityTokenPrefix)
+	}
+	if event.Identity != "" {
+		attrs = append(attrs, slog.String("identity", event.Identity))
+	} else if event.TokenID != "" {
 		attrs = append(attrs, slog.String("token_id", maskToken(event.TokenID)))
 	}
 	if event.TokenScope != "" {
@@ -118,6 +155,12 @@ func (l *Logger) Log(event *Event) {
 	}
 
 	l.logger.Info("AUDIT", attrs...)
+
+	if sink != nil {
+		if err := sink.Append(event); err != nil {
+			l.logger.Error("failed to persist audit event", slog.String("operation", string(event.Operation)), slog.String("error", err.Error()))
+		}
+	}
 }
 
 // LogSuccess records a successful operation
@@ -160,6 +203,10 @@ func Log(event *Event) {
 	Default().Log(event)
 }
 
+func SetSink(sink Sink) {
+	Default().SetSink(sink)
+}
+
 func LogSuccess(op Operation, tokenID, tokenScope, projectID string) {
 	Default().LogSuccess(op, tokenID, tokenScope, projectID)
 }
@@ -0,0 +1,348 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Default and maximum number of records returned by Query
+const (
+	DefaultQueryLimit = 100
+	MaxQueryLimit     = 1000
+)
+
+// ErrChainBroken is returned by Verify when a record does not match its hash chain
+var ErrChainBroken = errors.New("audit hash chain broken")
+
+// Sink receives audit events for durable storage
+type Sink interface {
+	Append(event *Event) error
+}
+
+// Record is a persisted audit event with its position in the hash chain.
+// Token IDs are never stored; TokenRef is a masked token ID (or the OIDC identity).
+type Record struct {
+	Seq         int64          `json:"seq"`
+	Timestamp   time.Time      `json:"timestamp"`
+	Operation   Operation      `json:"operation"`
+	TokenRef    string         `json:"token,omitempty"`
+	TokenScope  string         `json:"token_scope,omitempty"`
+	Identity    string         `json:"identity,omitempty"`
+	ProjectID   string         `json:"project_id,omitempty"`
+	SessionID   string         `json:"session_id,omitempty"`
+	WorkspaceID string         `json:"workspace_id,omitempty"`
+	RequestID   string         `json:"request_id,omitempty"`
+	Success     bool           `json:"success"`
+	Error       string         `json:"error,omitempty"`
+	Details     map[string]any `json:"details,omitempty"`
+	PrevHash    string         `json:"prev_hash"`
+	Hash        string         `json:"hash"`
+
+	tokenHash   string
+	detailsJSON string
+}
+
+// Filter selects audit records. Empty fields match everything.
+type Filter struct {
+	TokenID   string    // Full token ID or OIDC identity token ("oidc:<subject>")
+	ProjectID string    // Exact project ID
+	Operation Operation // Exact operation, or a prefix ending in "." (e.g. "container.")
+	Since     time.Time // Inclusive
+	Until     time.Time // Exclusive
+	Limit     int       // Defaults to DefaultQueryLimit, capped at MaxQueryLimit
+}
+
+// Store is an append-only, hash-chained audit log backed by SQLite.
+// Each record's hash covers its contents and the previous record's hash, so
+// edits or deletions are detected by Verify.
+type Store struct {
+	db *sql.DB
+	mu sync.Mutex // serializes appends so the chain stays linear
+}
+
+// NewStore opens (or creates) the audit database in dataDir
+func NewStore(dataDir string) (*Store, error) {
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	dbPath := filepath.Join(dataDir, "audit.db")
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
+	}
+
+	store := &Store{db: db}
+	if err := store.migrate(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return store, nil
+}
+
+func (s *Store) migrate() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS audit_events (
+		seq INTEGER PRIMARY KEY AUTOINCREMENT,
+		timestamp TEXT NOT NULL,
+		operation TEXT NOT NULL,
+		token_ref TEXT NOT NULL DEFAULT '',
+		token_hash TEXT NOT NULL DEFAULT '',
+		token_scope TEXT NOT NULL DEFAULT '',
+		identity TEXT NOT NULL DEFAULT '',
+		project_id TEXT NOT NULL DEFAULT '',
+		session_id TEXT NOT NULL DEFAULT '',
+		workspace_id TEXT NOT NULL DEFAULT '',
+		request_id TEXT NOT NULL DEFAULT '',
+		success INTEGER NOT NULL,
+		error TEXT NOT NULL DEFAULT '',
+		details TEXT NOT NULL DEFAULT '',
+		prev_hash TEXT NOT NULL,
+		hash TEXT NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_audit_timestamp ON audit_events(timestamp);
+	CREATE INDEX IF NOT EXISTS idx_audit_token ON audit_events(token_hash);
+	CREATE INDEX IF NOT EXISTS idx_audit_project ON audit_events(project_id);
+	CREATE INDEX IF NOT EXISTS idx_audit_operation ON audit_events(operation);
+
+	CREATE TRIGGER IF NOT EXISTS audit_events_no_update BEFORE UPDATE ON audit_events
+	BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END;
+	CREATE TRIGGER IF NOT EXISTS audit_events_no_delete BEFORE DELETE ON audit_events
+	BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END;
+	`
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// Close closes the database connection
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Append implements Sink, adding an event to the end of the chain
+func (s *Store) Append(event *Event) error {
+	rec, err := newRecord(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var lastSeq int64
+	err = tx.QueryRow(`SELECT seq, hash FROM audit_events ORDER BY seq DESC LIMIT 1`).Scan(&lastSeq, &rec.PrevHash)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to read chain head: %w", err)
+	}
+	rec.Seq = lastSeq + 1
+	rec.Hash = rec.computeHash()
+
+	_, err = tx.Exec(`
+		INSERT INTO audit_events (seq, timestamp, operation, token_ref, token_hash, token_scope, identity,
+			project_id, session_id, workspace_id, request_id, success, error, details, prev_hash, hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rec.Seq, formatTime(rec.Timestamp), string(rec.Operation), rec.TokenRef, rec.tokenHash, rec.TokenScope, rec.Identity,
+		rec.ProjectID, rec.SessionID, rec.WorkspaceID, rec.RequestID, rec.Success, rec.Error, rec.detailsJSON, rec.PrevHash, rec.Hash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to append audit event: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Query returns matching records, newest first
+func (s *Store) Query(f Filter) ([]*Record, error) {
+	var where []string
+	var args []any
+	if f.TokenID != "" {
+		where = append(where, "token_hash = ?")
+		args = append(args, hashToken(f.TokenID))
+	}
+	if f.ProjectID != "" {
+		where = append(where, "project_id = ?")
+		args = append(args, f.ProjectID)
+	}
+	if f.Operation != "" {
+		if strings.HasSuffix(string(f.Operation), ".") {
+			where = append(where, "operation LIKE ?")
+			args = append(args, string(f.Operation)+"%")
+		} else {
+			where = append(where, "operation = ?")
+			args = append(args, string(f.Operation))
+		}
+	}
+	if !f.Since.IsZero() {
+		where = append(where, "timestamp >= ?")
+		args = append(args, formatTime(f.Since))
+	}
+	if !f.Until.IsZero() {
+		where = append(where, "timestamp < ?")
+		args = append(args, formatTime(f.Until))
+	}
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultQueryLimit
+	}
+	if limit > MaxQueryLimit {
+		limit = MaxQueryLimit
+	}
+
+	query := `SELECT ` + recordColumns + ` FROM audit_events`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY seq DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var records []*Record
+	for rows.Next() {
+		rec, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+// Verify walks the whole chain and checks every hash. It returns the number of
+// records checked; on a mismatch the error wraps ErrChainBroken and names the record.
+func (s *Store) Verify() (int, error) {
+	rows, err := s.db.Query(`SELECT ` + recordColumns + ` FROM audit_events ORDER BY seq ASC`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	checked := 0
+	prevHash := ""
+	var prevSeq int64
+	for rows.Next() {
+		rec, err := scanRecord(rows)
+		if err != nil {
+			return checked, err
+		}
+		if rec.Seq != prevSeq+1 {
+			return checked, fmt.Errorf("%w: record %d missing", ErrChainBroken, prevSeq+1)
+		}
+		if rec.PrevHash != prevHash || rec.computeHash() != rec.Hash {
+			return checked, fmt.Errorf("%w at record %d", ErrChainBroken, rec.Seq)
+		}
+		prevHash = rec.Hash
+		prevSeq = rec.Seq
+		checked++
+	}
+	return checked, rows.Err()
+}
+
+const recordColumns = `seq, timestamp, operation, token_ref, token_hash, token_scope, identity,
+	project_id, session_id, workspace_id, request_id, success, error, details, prev_hash, hash`
+
+func scanRecord(rows *sql.Rows) (*Record, error) {
+	var rec Record
+	var ts, op string
+	if err := rows.Scan(&rec.Seq, &ts, &op, &rec.TokenRef, &rec.tokenHash, &rec.TokenScope, &rec.Identity,
+		&rec.ProjectID, &rec.SessionID, &rec.WorkspaceID, &rec.RequestID, &rec.Success, &rec.Error,
+		&rec.detailsJSON, &rec.PrevHash, &rec.Hash); err != nil {
+		return nil, fmt.Errorf("failed to scan audit record: %w", err)
+	}
+	rec.Operation = Operation(op)
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit timestamp %q: %w", ts, err)
+	}
+	rec.Timestamp = t
+	if rec.detailsJSON != "" {
+		if err := json.Unmarshal([]byte(rec.detailsJSON), &rec.Details); err != nil {
+			return nil, fmt.Errorf("invalid audit details: %w", err)
+		}
+	}
+	return &rec, nil
+}
+
+// newRecord converts an event into an unchained record
+func newRecord(event *Event) (*Record, error) {
+	ts := event.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	rec := &Record{
+		Timestamp:   ts.UTC(),
+		Operation:   event.Operation,
+		TokenScope:  event.TokenScope,
+		Identity:    event.Identity,
+		ProjectID:   event.ProjectID,
+		SessionID:   event.SessionID,
+		WorkspaceID: event.WorkspaceID,
+		RequestID:   event.RequestID,
+		Success:     event.Success,
+		Error:       event.Error,
+		Details:     event.Details,
+	}
+	if event.TokenID != "" {
+		rec.tokenHash = hashToken(event.TokenID)
+		if strings.HasPrefix(event.TokenID, identityTokenPrefix) {
+			rec.TokenRef = event.TokenID
+		} else {
+			rec.TokenRef = maskToken(event.TokenID)
+		}
+	}
+	if len(event.Details) > 0 {
+		data, err := json.Marshal(event.Details)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode audit details: %w", err)
+		}
+		rec.detailsJSON = string(data)
+	}
+	return rec, nil
+}
+
+// computeHash hashes the record's stored fields together with the previous hash
+func (r *Record) computeHash() string {
+	fields, _ := json.Marshal([]any{
+		r.PrevHash, r.Seq, formatTime(r.Timestamp), string(r.Operation), r.TokenRef, r.tokenHash, r.TokenScope,
+		r.Identity, r.ProjectID, r.SessionID, r.WorkspaceID, r.RequestID, r.Success, r.Error, r.detailsJSON,
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
+
+// hashToken derives the lookup key for a token without storing the token itself
+func hashToken(tokenID string) string {
+	sum := sha256.Sum256([]byte(tokenID))
+	return hex.EncodeToString(sum[:])
+}
+
+// formatTime uses a fixed-width UTC format so timestamps sort lexically
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000000000Z07:00")
+}
@@ -0,0 +1,176 @@
+package audit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestStore_AppendAndQuery(t *testing.T) {
+	store := newTestStore(t)
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	events := []*Event{
+		{Timestamp: base, Operation: OpContainerExec, TokenID: "oub_aaaaaaaaaaaaaaaaaaaa", ProjectID: "p1", Success: true, Details: map[string]interface{}{"command": "ls"}},
+		{Timestamp: base.Add(time.Hour), Operation: OpContainerStop, TokenID: "oub_bbbbbbbbbbbbbbbbbbbb", ProjectID: "p1", Success: true},
+		{Timestamp: base.Add(2 * time.Hour), Operation: OpSessionSpawn, TokenID: "oidc:alice", ProjectID: "p2", Success: false, Error: "quota exceeded"},
+	}
+	for _, e := range events {
+		if err := store.Append(e); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	all, err := store.Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("Query() returned %d records, want 3", len(all))
+	}
+	if all[0].Seq != 3 || all[2].Seq != 1 {
+		t.Errorf("Query() should return newest first, got seqs %d..%d", all[0].Seq, all[2].Seq)
+	}
+	if all[2].PrevHash != "" || all[1].PrevHash != all[2].Hash {
+		t.Error("records are not chained")
+	}
+
+	// Token IDs are masked, OIDC identities kept
+	if strings.Contains(all[2].TokenRef, "aaaaaaaaaaaa") {
+		t.Errorf("TokenRef %q leaks the token", all[2].TokenRef)
+	}
+	if all[0].TokenRef != "oidc:alice" {
+		t.Errorf("TokenRef = %q, want oidc:alice", all[0].TokenRef)
+	}
+	if all[2].Details["command"] != "ls" {
+		t.Errorf("Details = %v, want command ls", all[2].Details)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []int64
+	}{
+		{"by token", Filter{TokenID: "oub_aaaaaaaaaaaaaaaaaaaa"}, []int64{1}},
+		{"by oidc identity", Filter{TokenID: "oidc:alice"}, []int64{3}},
+		{"by project", Filter{ProjectID: "p1"}, []int64{2, 1}},
+		{"by operation", Filter{Operation: OpContainerExec}, []int64{1}},
+		{"by operation prefix", Filter{Operation: "container."}, []int64{2, 1}},
+		{"since", Filter{Since: base.Add(time.Hour)}, []int64{3, 2}},
+		{"until", Filter{Until: base.Add(time.Hour)}, []int64{1}},
+		{"limit", Filter{Limit: 1}, []int64{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := store.Query(tt.filter)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			var got []int64
+			for _, r := range records {
+				got = append(got, r.Seq)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Query() seqs = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Query() seqs = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestStore_AppendOnly(t *testing.T) {
+	store := newTestStore(t)
+	if err := store.Append(&Event{Operation: OpProjectCreate, Success: true}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	if _, err := store.db.Exec(`UPDATE audit_events SET success = 0`); err == nil {
+		t.Error("UPDATE should be rejected")
+	}
+	if _, err := store.db.Exec(`DELETE FROM audit_events`); err == nil {
+		t.Error("DELETE should be rejected")
+	}
+}
+
+func TestStore_Verify(t *testing.T) {
+	store := newTestStore(t)
+	for i := 0; i < 5; i++ {
+		if err := store.Append(&Event{Operation: OpContainerExec, ProjectID: "p1", Success: true, Details: map[string]interface{}{"n": i}}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	checked, err := store.Verify()
+	if err != nil || checked != 5 {
+		t.Fatalf("Verify() = %d, %v; want 5, nil", checked, err)
+	}
+
+	// Tamper with a record behind the triggers' back
+	if _, err := store.db.Exec(`DROP TRIGGER audit_events_no_update`); err != nil {
+		t.Fatalf("drop trigger: %v", err)
+	}
+	if _, err := store.db.Exec(`UPDATE audit_events SET project_id = 'p2' WHERE seq = 3`); err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+
+	checked, err = store.Verify()
+	if !errors.Is(err, ErrChainBroken) {
+		t.Fatalf("Verify() error = %v, want ErrChainBroken", err)
+	}
+	if checked != 2 {
+		t.Errorf("Verify() checked %d records before the break, want 2", checked)
+	}
+}
+
+func TestStore_VerifyDetectsDeletion(t *testing.T) {
+	store := newTestStore(t)
+	for i := 0; i < 3; i++ {
+		if err := store.Append(&Event{Operation: OpSessionEnd, Success: true}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	if _, err := store.db.Exec(`DROP TRIGGER audit_events_no_delete`); err != nil {
+		t.Fatalf("drop trigger: %v", err)
+	}
+	if _, err := store.db.Exec(`DELETE FROM audit_events WHERE seq = 2`); err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+
+	if _, err := store.Verify(); !errors.Is(err, ErrChainBroken) {
+		t.Fatalf("Verify() error = %v, want ErrChainBroken", err)
+	}
+}
+
+func TestLogger_Sink(t *testing.T) {
+	store := newTestStore(t)
+	l := New(true)
+	l.SetSink(store)
+
+	l.LogSuccess(OpTokenCreate, "oub_cccccccccccccccccccc", "admin", "")
+	l.SetEnabled(false)
+	l.LogSuccess(OpTokenRevoke, "oub_cccccccccccccccccccc", "admin", "")
+
+	records, err := store.Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(records) != 1 || records[0].Operation != OpTokenCreate {
+		t.Fatalf("Query() = %+v, want one token.create record", records)
+	}
+}
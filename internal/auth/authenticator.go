@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrUnsupportedCredential is returned by an Authenticator when the credential
+// is not in a format it handles, so the next authenticator in a chain can try.
+var ErrUnsupportedCredential = errors.New("unsupported credential")
+
+// Authenticator validates a bearer credential and returns the resulting auth context
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*AuthContext, error)
+}
+
+// Chain tries each authenticator in order until one accepts the credential format
+type Chain []Authenticator
+
+// NewChain creates an authenticator chain, skipping nil entries
+func NewChain(authenticators ...Authenticator) Chain {
+	chain := make(Chain, 0, len(authenticators))
+	for _, a := range authenticators {
+		if a != nil {
+			chain = append(chain, a)
+		}
+	}
+	return chain
+}
+
+// Authenticate implements Authenticator
+func (c Chain) Authenticate(ctx context.Context, credential string) (*AuthContext, error) {
+	for _, a := range c {
+		authCtx, err := a.Authenticate(ctx, credential)
+		if errors.Is(err, ErrUnsupportedCredential) {
+			continue
+		}
+		return authCtx, err
+	}
+	return nil, ErrUnsupportedCredential
+}
+
+// TokenAuthenticator validates opaque API tokens against the auth store
+type TokenAuthenticator struct {
+	store *Store
+}
+
+// NewTokenAuthenticator creates an authenticator for static API tokens
+func NewTokenAuthenticator(store *Store) *TokenAuthenticator {
+	return &TokenAuthenticator{store: store}
+}
+
+// Authenticate implements Authenticator. JWTs are left to other authenticators.
+func (a *TokenAuthenticator) Authenticate(ctx context.Context, credential string) (*AuthContext, error) {
+	if looksLikeJWT(credential) {
+		return nil, ErrUnsupportedCredential
+	}
+	token, err := a.store.ValidateToken(credential)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthContext{Type: AuthTypeToken, Token: token}, nil
+}
+
+// looksLikeJWT reports whether a credential has the compact JWS shape (three dot-separated parts)
+func looksLikeJWT(credential string) bool {
+	return strings.Count(credential, ".") == 2
+}
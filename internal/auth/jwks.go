@@ -0,0 +1,305 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Minimum interval between JWKS refreshes triggered by unknown key IDs
+const jwksRefreshInterval = time.Minute
+
+// KeySet resolves the public key used to verify a JWT signature
+type KeySet interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// JWK is a single JSON Web Key (RSA and EC keys are supported)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set document
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// ParseJWKS parses a JWKS document into public keys by key ID.
+// Keys not intended for signatures are skipped.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set JWKS
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+// PublicKey converts the JWK to a crypto public key
+func (k *JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("exponent: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, fmt.Errorf("missing value")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// StaticKeySet is a fixed set of keys, e.g. loaded from a local JWKS file
+type StaticKeySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+// NewStaticKeySet creates a key set from a JWKS document
+func NewStaticKeySet(jwks []byte) (*StaticKeySet, error) {
+	keys, err := ParseJWKS(jwks)
+	if err != nil {
+		return nil, err
+	}
+	return &StaticKeySet{keys: keys}, nil
+}
+
+// Key implements KeySet
+func (s *StaticKeySet) Key(_ context.Context, kid string) (crypto.PublicKey, error) {
+	return lookupKey(s.keys, kid)
+}
+
+// lookupKey finds a key by ID; an empty kid matches when the set has exactly one key
+func lookupKey(keys map[string]crypto.PublicKey, kid string) (crypto.PublicKey, error) {
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// RemoteKeySet fetches and caches a JWKS from a URL. The cache is refreshed after
+// the TTL expires, or early (at most once per minute) when an unknown key ID is seen.
+// Fetches run outside the lock, one at a time; while one is in flight, or for a
+// minute after one fails, callers keep using the cached keys.
+type RemoteKeySet struct {
+	url    string
+	ttl    time.Duration
+	client *http.Client
+
+	mu         sync.Mutex
+	keys       map[string]crypto.PublicKey
+	fetchedAt  time.Time
+	failedAt   time.Time     // last failed refresh; refreshes back off from it
+	fetchErr   error         // error of the last failed refresh
+	refreshing chan struct{} // closed when the in-flight refresh finishes
+}
+
+// NewRemoteKeySet creates a cached key set for a JWKS URL
+func NewRemoteKeySet(url string, ttl time.Duration, client *http.Client) *RemoteKeySet {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &RemoteKeySet{url: url, ttl: ttl, client: client}
+}
+
+// Key implements KeySet
+func (r *RemoteKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	keys, err := r.current(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	key, err := lookupKey(keys, kid)
+	if err == nil {
+		return key, nil
+	}
+
+	// Unknown key: the issuer may have rotated keys
+	if keys, err = r.current(ctx, true); err != nil {
+		return nil, err
+	}
+	return lookupKey(keys, kid)
+}
+
+// current returns the cached keys, refreshing them first when they have
+// expired or, for a rotation, when they are older than jwksRefreshInterval
+func (r *RemoteKeySet) current(ctx context.Context, rotation bool) (map[string]crypto.PublicKey, error) {
+	r.mu.Lock()
+	keys := r.keys
+	due := keys == nil || time.Since(r.fetchedAt) > r.ttl
+	if rotation {
+		due = time.Since(r.fetchedAt) >= jwksRefreshInterval
+	}
+	if !due || time.Since(r.failedAt) < jwksRefreshInterval {
+		err := r.fetchErr
+		r.mu.Unlock()
+		if keys == nil {
+			return nil, err
+		}
+		return keys, nil
+	}
+
+	if done := r.refreshing; done != nil {
+		r.mu.Unlock()
+		if keys != nil {
+			return keys, nil
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		r.mu.Lock()
+		keys, err := r.keys, r.fetchErr
+		r.mu.Unlock()
+		if keys == nil {
+			return nil, err
+		}
+		return keys, nil
+	}
+	done := make(chan struct{})
+	r.refreshing = done
+	r.mu.Unlock()
+
+	// The fetch outlives a caller that gives up; the client timeout bounds it
+	fetched, err := r.fetch(context.WithoutCancel(ctx))
+
+	r.mu.Lock()
+	if err != nil {
+		r.failedAt, r.fetchErr = time.Now(), err
+	} else {
+		r.keys, r.fetchedAt = fetched, time.Now()
+		r.failedAt, r.fetchErr = time.Time{}, nil
+	}
+	keys = r.keys
+	r.refreshing = nil
+	close(done)
+	r.mu.Unlock()
+
+	if keys == nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *RemoteKeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	body, err := fetchJSON(ctx, r.client, r.url)
+	if err != nil {
+		return nil, fmt.Errorf("fetching JWKS: %w", err)
+	}
+	return ParseJWKS(body)
+}
+
+// discoverJWKSURL reads jwks_uri from the issuer's OpenID configuration document
+func discoverJWKSURL(ctx context.Context, client *http.Client, issuer string) (string, error) {
+	url := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	body, err := fetchJSON(ctx, client, url)
+	if err != nil {
+		return "", fmt.Errorf("OIDC discovery: %w", err)
+	}
+	var doc struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return "", fmt.Errorf("OIDC discovery: %w", err)
+	}
+	if doc.Issuer != issuer {
+		return "", fmt.Errorf("OIDC discovery: issuer mismatch (got %q)", doc.Issuer)
+	}
+	if doc.JWKSURI == "" {
+		return "", fmt.Errorf("OIDC discovery: jwks_uri missing")
+	}
+	return doc.JWKSURI, nil
+}
+
+func fetchJSON(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
@@ -12,6 +12,12 @@ import (
 // Only Bearer token authentication is supported for external HTTP access.
 // Internal gogol connections use unix sockets (no HTTP auth needed).
 func Middleware(store *Store) func(http.Handler) http.Handler {
+	return AuthenticatorMiddleware(NewTokenAuthenticator(store))
+}
+
+// AuthenticatorMiddleware creates HTTP middleware that validates Bearer credentials
+// with the given authenticator (typically a Chain of static tokens and OIDC).
+func AuthenticatorMiddleware(authenticator Authenticator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			auth := r.Header.Get("Authorization")
@@ -21,19 +27,19 @@ func Middleware(store *Store) func(http.Handler) http.Handler {
 				return
 			}
 
-			tokenID := strings.TrimPrefix(auth, "Bearer ")
-			token, err := store.ValidateToken(tokenID)
+			credential := strings.TrimPrefix(auth, "Bearer ")
+			authContext, err := authenticator.Authenticate(r.Context(), credential)
 			if err != nil {
 				logger.Info("Token validation failed: %v", err)
 				jsonError(w, "Invalid or expired token", http.StatusUnauthorized)
 				return
 			}
 
-			authContext := &AuthContext{
-				Type:  AuthTypeToken,
-				Token: token,
+			if authContext.Identity != nil {
+				logger.Info("Authenticated OIDC identity: %s (scope: %s)", authContext.Identity, authContext.Token.Scope)
+			} else {
+				logger.Info("Authenticated with token: %s (scope: %s)", maskToken(credential), authContext.Token.Scope)
 			}
-			logger.Info("Authenticated with token: %s (scope: %s)", maskToken(tokenID), token.Scope)
 
 			ctx := WithContext(r.Context(), authContext)
 			next.ServeHTTP(w, r.WithContext(ctx))
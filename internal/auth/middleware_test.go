@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestMiddleware_ValidToken(t *testing.T) {
@@ -214,6 +215,63 @@ func TestRateLimitMiddleware_UsesTokenID(t *testing.T) {
 	}
 }
 
+func TestIPRateLimitMiddleware(t *testing.T) {
+	limiter := NewRateLimiter(0.01, 1)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	wrapped := IPRateLimitMiddleware(limiter)(handler)
+
+	tests := []struct {
+		remoteAddr string
+		want       int
+	}{
+		{"192.168.1.1:12345", http.StatusOK},
+		{"192.168.1.1:54321", http.StatusTooManyRequests}, // same IP, different port
+		{"192.168.1.2:12345", http.StatusOK},
+	}
+	for _, tt := range tests {
+		// No credentials: the limiter runs before auth
+		req := httptest.NewRequest("GET", "/", http.NoBody)
+		req.RemoteAddr = tt.remoteAddr
+		rec := httptest.NewRecorder()
+		wrapped.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s status = %v, want %v", tt.remoteAddr, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestUsageMiddleware_RecordsBeforeServing(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	var seen int64
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		usage, err := store.GetUsage("token-1", time.Now())
+		if err != nil {
+			t.Fatalf("GetUsage() error = %v", err)
+		}
+		seen = usage.Requests
+		w.WriteHeader(http.StatusOK)
+	})
+
+	wrapped := UsageMiddleware(store)(handler)
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	authCtx := &AuthContext{Type: AuthTypeToken, Token: &Token{ID: "token-1"}}
+	req = req.WithContext(WithContext(req.Context(), authCtx))
+	wrapped.ServeHTTP(httptest.NewRecorder(), req)
+
+	if seen != 1 {
+		t.Errorf("handler saw %d requests recorded, want 1", seen)
+	}
+}
+
 func Test_maskToken(t *testing.T) {
 	tests := []struct {
 		name    string
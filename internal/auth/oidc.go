@@ -0,0 +1,305 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OIDC defaults
+const (
+	DefaultOIDCScopeClaim  = "oubliette_scope"
+	DefaultOIDCGroupsClaim = "groups"
+	DefaultJWKSCacheTTL    = time.Hour
+	DefaultOIDCClockSkew   = time.Minute
+)
+
+// OIDCConfig configures JWT bearer authentication against an OIDC issuer
+type OIDCConfig struct {
+	Issuer       string            // Expected "iss" claim (required)
+	Audience     string            // Expected "aud" claim (required)
+	JWKSURL      string            // Defaults to jwks_uri from issuer discovery
+	ScopeClaim   string            // Claim holding Oubliette scope entries (string or list)
+	GroupsClaim  string            // Claim holding group names
+	GroupScopes  map[string]string // Group name -> scope entries granted to members
+	DefaultScope string            // Scope for identities with no mapped scope (empty = reject)
+	JWKSCacheTTL time.Duration
+	ClockSkew    time.Duration
+	HTTPClient   *http.Client
+	KeySet       KeySet // Overrides JWKSURL/discovery (e.g. a StaticKeySet)
+}
+
+// OIDCAuthenticator validates JWTs issued by an OIDC provider and maps their
+// claims to an Oubliette scope
+type OIDCAuthenticator struct {
+	cfg OIDCConfig
+	now func() time.Time
+
+	mu          sync.Mutex
+	keys        KeySet
+	failedAt    time.Time     // last failed discovery; discovery backs off from it
+	discoverErr error         // error of the last failed discovery
+	discovering chan struct{} // closed when the in-flight discovery finishes
+}
+
+// NewOIDCAuthenticator validates the config and creates an OIDC authenticator.
+// The JWKS is fetched lazily on first use.
+func NewOIDCAuthenticator(cfg OIDCConfig) (*OIDCAuthenticator, error) {
+	if cfg.Issuer == "" {
+		return nil, fmt.Errorf("oidc: issuer is required")
+	}
+	if cfg.Audience == "" {
+		return nil, fmt.Errorf("oidc: audience is required")
+	}
+	if cfg.ScopeClaim == "" {
+		cfg.ScopeClaim = DefaultOIDCScopeClaim
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = DefaultOIDCGroupsClaim
+	}
+	if cfg.JWKSCacheTTL <= 0 {
+		cfg.JWKSCacheTTL = DefaultJWKSCacheTTL
+	}
+	if cfg.ClockSkew <= 0 {
+		cfg.ClockSkew = DefaultOIDCClockSkew
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if cfg.DefaultScope != "" {
+		if err := ValidateScope(cfg.DefaultScope); err != nil {
+			return nil, fmt.Errorf("oidc: invalid default_scope: %w", err)
+		}
+	}
+	for group, scope := range cfg.GroupScopes {
+		if err := validateScopeEntries(scope); err != nil {
+			return nil, fmt.Errorf("oidc: invalid scope for group %q: %w", group, err)
+		}
+	}
+
+	a := &OIDCAuthenticator{cfg: cfg, now: time.Now, keys: cfg.KeySet}
+	if a.keys == nil && cfg.JWKSURL != "" {
+		a.keys = NewRemoteKeySet(cfg.JWKSURL, cfg.JWKSCacheTTL, cfg.HTTPClient)
+	}
+	return a, nil
+}
+
+// keySet returns the key set, running issuer discovery on first use.
+// Discovery runs outside the lock, one at a time, and like a JWKS refresh is
+// retried at most once per jwksRefreshInterval after it fails.
+func (a *OIDCAuthenticator) keySet(ctx context.Context) (KeySet, error) {
+	a.mu.Lock()
+	if a.keys != nil {
+		keys := a.keys
+		a.mu.Unlock()
+		return keys, nil
+	}
+	if time.Since(a.failedAt) < jwksRefreshInterval {
+		err := a.discoverErr
+		a.mu.Unlock()
+		return nil, err
+	}
+	if done := a.discovering; done != nil {
+		a.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		a.mu.Lock()
+		keys, err := a.keys, a.discoverErr
+		a.mu.Unlock()
+		if keys == nil {
+			return nil, err
+		}
+		return keys, nil
+	}
+	done := make(chan struct{})
+	a.discovering = done
+	a.mu.Unlock()
+
+	// Discovery outlives a caller that gives up; the client timeout bounds it
+	url, err := discoverJWKSURL(context.WithoutCancel(ctx), a.cfg.HTTPClient, a.cfg.Issuer)
+
+	a.mu.Lock()
+	if err != nil {
+		a.failedAt, a.discoverErr = time.Now(), err
+	} else {
+		a.keys = NewRemoteKeySet(url, a.cfg.JWKSCacheTTL, a.cfg.HTTPClient)
+		a.failedAt, a.discoverErr = time.Time{}, nil
+	}
+	keys := a.keys
+	a.discovering = nil
+	close(done)
+	a.mu.Unlock()
+
+	if keys == nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Authenticate implements Authenticator. Non-JWT credentials are left to other authenticators.
+func (a *OIDCAuthenticator) Authenticate(ctx context.Context, credential string) (*AuthContext, error) {
+	if !looksLikeJWT(credential) {
+		return nil, ErrUnsupportedCredential
+	}
+
+	keys, err := a.keySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := verifyJWT(ctx, credential, keys)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Issuer:  a.cfg.Issuer,
+		Subject: claimString(claims, "sub"),
+		Email:   claimString(claims, "email"),
+		Name:    claimString(claims, "name"),
+		Groups:  claimStrings(claims, a.cfg.GroupsClaim),
+	}
+	if identity.Subject == "" {
+		return nil, fmt.Errorf("JWT has no subject")
+	}
+
+	scope, err := a.mapScope(claims, identity.Groups)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &Token{
+		ID:    IdentityTokenPrefix + identity.Subject,
+		Name:  identity.String(),
+		Scope: scope,
+	}
+	if iat, ok := claimTime(claims, "iat"); ok {
+		token.CreatedAt = iat
+	}
+	if exp, ok := claimTime(claims, "exp"); ok {
+		token.ExpiresAt = &exp
+	}
+
+	return &AuthContext{Type: AuthTypeOIDC, Token: token, Identity: identity}, nil
+}
+
+// validateClaims checks issuer, audience and time-based claims
+func (a *OIDCAuthenticator) validateClaims(claims map[string]any) error {
+	if iss := claimString(claims, "iss"); iss != a.cfg.Issuer {
+		return fmt.Errorf("JWT issuer %q not accepted", iss)
+	}
+
+	audOK := false
+	for _, aud := range claimStrings(claims, "aud") {
+		if aud == a.cfg.Audience {
+			audOK = true
+			break
+		}
+	}
+	if !audOK {
+		return fmt.Errorf("JWT audience not accepted")
+	}
+
+	now := a.now()
+	exp, ok := claimTime(claims, "exp")
+	if !ok {
+		return fmt.Errorf("JWT has no expiry")
+	}
+	if now.After(exp.Add(a.cfg.ClockSkew)) {
+		return fmt.Errorf("JWT expired")
+	}
+	if nbf, ok := claimTime(claims, "nbf"); ok && now.Add(a.cfg.ClockSkew).Before(nbf) {
+		return fmt.Errorf("JWT not yet valid")
+	}
+	return nil
+}
+
+// mapScope builds the Oubliette scope from the scope claim and group mappings.
+// Identities without any mapped entries get DefaultScope, or are rejected.
+func (a *OIDCAuthenticator) mapScope(claims map[string]any, groups []string) (string, error) {
+	var entries []string
+	for _, value := range claimStrings(claims, a.cfg.ScopeClaim) {
+		entries = append(entries, splitScope(value)...)
+	}
+
+	// Sort groups so the resulting scope string is stable
+	sorted := append([]string(nil), groups...)
+	sort.Strings(sorted)
+	for _, group := range sorted {
+		if scope, ok := a.cfg.GroupScopes[group]; ok {
+			entries = append(entries, splitScope(scope)...)
+		}
+	}
+
+	if len(entries) == 0 {
+		if a.cfg.DefaultScope == "" {
+			return "", fmt.Errorf("no Oubliette scope granted to identity")
+		}
+		return a.cfg.DefaultScope, nil
+	}
+
+	scope := strings.Join(entries, " ")
+	if err := ValidateScope(scope); err != nil {
+		return "", fmt.Errorf("invalid scope from claims: %w", err)
+	}
+	return scope, nil
+}
+
+// validateScopeEntries checks that scope entries parse, without requiring a base
+// admin/project entry (group mappings may only add tool rules)
+func validateScopeEntries(scope string) error {
+	entries := splitScope(scope)
+	if len(entries) == 0 {
+		return fmt.Errorf("scope cannot be empty")
+	}
+	_, err := ParseScope(strings.Join(append(entries, ScopeAdminRO), " "))
+	return err
+}
+
+func claimString(claims map[string]any, name string) string {
+	s, _ := claims[name].(string)
+	return s
+}
+
+// claimStrings reads a claim that may be a single string or a list of strings
+func claimStrings(claims map[string]any, name string) []string {
+	switch v := claims[name].(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// claimTime reads a NumericDate claim (seconds since the epoch)
+func claimTime(claims map[string]any, name string) (time.Time, bool) {
+	n, ok := claims[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	secs, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(secs), 0), true
+}
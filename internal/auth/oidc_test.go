@@ -0,0 +1,493 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	testIssuer   = "https://sso.example.com"
+	testAudience = "oubliette"
+)
+
+// testSigner signs JWTs with a locally generated RSA key
+type testSigner struct {
+	kid string
+	key *rsa.PrivateKey
+}
+
+func newTestSigner(t *testing.T, kid string) *testSigner {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	return &testSigner{kid: kid, key: key}
+}
+
+func (s *testSigner) jwk() JWK {
+	return JWK{
+		Kty: "RSA",
+		Kid: s.kid,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+	}
+}
+
+func jwksJSON(t *testing.T, keys ...JWK) []byte {
+	t.Helper()
+	data, err := json.Marshal(JWKS{Keys: keys})
+	if err != nil {
+		t.Fatalf("marshal JWKS: %v", err)
+	}
+	return data
+}
+
+func (s *testSigner) sign(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	signingInput := encodeJWTPart(t, map[string]any{"alg": "RS256", "kid": s.kid, "typ": "JWT"}) + "." + encodeJWTPart(t, claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("SignPKCS1v15() error = %v", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func encodeJWTPart(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal JWT part: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func validClaims() map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"sub":   "user-123",
+		"email": "dev@example.com",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+}
+
+func newTestOIDC(t *testing.T, signer *testSigner, cfg OIDCConfig) *OIDCAuthenticator {
+	t.Helper()
+	keys, err := NewStaticKeySet(jwksJSON(t, signer.jwk()))
+	if err != nil {
+		t.Fatalf("NewStaticKeySet() error = %v", err)
+	}
+	cfg.Issuer = testIssuer
+	cfg.Audience = testAudience
+	cfg.KeySet = keys
+	a, err := NewOIDCAuthenticator(cfg)
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator() error = %v", err)
+	}
+	return a
+}
+
+func TestNewOIDCAuthenticator_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  OIDCConfig
+	}{
+		{"missing issuer", OIDCConfig{Audience: testAudience}},
+		{"missing audience", OIDCConfig{Issuer: testIssuer}},
+		{"bad default scope", OIDCConfig{Issuer: testIssuer, Audience: testAudience, DefaultScope: "root"}},
+		{"bad group scope", OIDCConfig{Issuer: testIssuer, Audience: testAudience, GroupScopes: map[string]string{"eng": "project:"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewOIDCAuthenticator(tt.cfg); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestOIDCAuthenticator_Authenticate(t *testing.T) {
+	signer := newTestSigner(t, "key-1")
+	a := newTestOIDC(t, signer, OIDCConfig{
+		GroupScopes: map[string]string{
+			"platform": "admin",
+			"payments": "project:pay project:ledger:ro",
+		},
+	})
+
+	t.Run("scope claim", func(t *testing.T) {
+		claims := validClaims()
+		claims[DefaultOIDCScopeClaim] = "project:abc:ro"
+		authCtx, err := a.Authenticate(context.Background(), signer.sign(t, claims))
+		if err != nil {
+			t.Fatalf("Authenticate() error = %v", err)
+		}
+		if authCtx.Type != AuthTypeOIDC {
+			t.Errorf("Type = %v, want AuthTypeOIDC", authCtx.Type)
+		}
+		if authCtx.Token.Scope != "project:abc:ro" {
+			t.Errorf("Scope = %q, want project:abc:ro", authCtx.Token.Scope)
+		}
+		if authCtx.Token.ID != "oidc:user-123" {
+			t.Errorf("Token.ID = %q, want oidc:user-123", authCtx.Token.ID)
+		}
+		if authCtx.Identity == nil || authCtx.Identity.Email != "dev@example.com" {
+			t.Errorf("Identity = %+v, want email dev@example.com", authCtx.Identity)
+		}
+		if authCtx.Token.ExpiresAt == nil {
+			t.Error("expected ExpiresAt from exp claim")
+		}
+	})
+
+	t.Run("group mapping", func(t *testing.T) {
+		claims := validClaims()
+		claims["groups"] = []any{"payments", "unmapped"}
+		authCtx, err := a.Authenticate(context.Background(), signer.sign(t, claims))
+		if err != nil {
+			t.Fatalf("Authenticate() error = %v", err)
+		}
+		if !authCtx.CanWriteProject("pay") || authCtx.CanWriteProject("ledger") || !authCtx.CanAccessProject("ledger") {
+			t.Errorf("unexpected access for scope %q", authCtx.Token.Scope)
+		}
+		if authCtx.IsAdmin() {
+			t.Error("payments group should not be admin")
+		}
+	})
+
+	t.Run("admin group", func(t *testing.T) {
+		claims := validClaims()
+		claims["groups"] = []any{"platform"}
+		authCtx, err := a.Authenticate(context.Background(), signer.sign(t, claims))
+		if err != nil {
+			t.Fatalf("Authenticate() error = %v", err)
+		}
+		if !authCtx.IsAdmin() {
+			t.Error("platform group should be admin")
+		}
+	})
+
+	t.Run("no scope rejected", func(t *testing.T) {
+		if _, err := a.Authenticate(context.Background(), signer.sign(t, validClaims())); err == nil {
+			t.Error("expected error for identity without scope")
+		}
+	})
+
+	t.Run("opaque token not handled", func(t *testing.T) {
+		_, err := a.Authenticate(context.Background(), "oub_abcdef")
+		if !errors.Is(err, ErrUnsupportedCredential) {
+			t.Errorf("error = %v, want ErrUnsupportedCredential", err)
+		}
+	})
+}
+
+func TestOIDCAuthenticator_Rejects(t *testing.T) {
+	signer := newTestSigner(t, "key-1")
+	other := newTestSigner(t, "key-1")
+	a := newTestOIDC(t, signer, OIDCConfig{DefaultScope: "admin:ro"})
+
+	tests := []struct {
+		name   string
+		token  func() string
+		modify func(map[string]any)
+	}{
+		{name: "wrong issuer", modify: func(c map[string]any) { c["iss"] = "https://evil.example.com" }},
+		{name: "wrong audience", modify: func(c map[string]any) { c["aud"] = []any{"other"} }},
+		{name: "expired", modify: func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{name: "not yet valid", modify: func(c map[string]any) { c["nbf"] = time.Now().Add(time.Hour).Unix() }},
+		{name: "no expiry", modify: func(c map[string]any) { delete(c, "exp") }},
+		{name: "no subject", modify: func(c map[string]any) { delete(c, "sub") }},
+		{name: "invalid scope claim", modify: func(c map[string]any) { c[DefaultOIDCScopeClaim] = "superuser" }},
+		{name: "wrong key", token: func() string { return other.sign(t, validClaims()) }},
+		{name: "alg none", token: func() string {
+			return encodeJWTPart(t, map[string]any{"alg": "none"}) + "." + encodeJWTPart(t, validClaims()) + "."
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var token string
+			if tt.token != nil {
+				token = tt.token()
+			} else {
+				claims := validClaims()
+				tt.modify(claims)
+				token = signer.sign(t, claims)
+			}
+			if _, err := a.Authenticate(context.Background(), token); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	// Sanity check: the default scope applies to otherwise valid tokens
+	authCtx, err := a.Authenticate(context.Background(), signer.sign(t, validClaims()))
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if authCtx.Token.Scope != "admin:ro" {
+		t.Errorf("Scope = %q, want default admin:ro", authCtx.Token.Scope)
+	}
+}
+
+func TestOIDCAuthenticator_ECDSA(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	jwk := JWK{
+		Kty: "EC",
+		Kid: "ec-1",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+	keys, err := NewStaticKeySet(jwksJSON(t, jwk))
+	if err != nil {
+		t.Fatalf("NewStaticKeySet() error = %v", err)
+	}
+	a, err := NewOIDCAuthenticator(OIDCConfig{Issuer: testIssuer, Audience: testAudience, DefaultScope: "admin:ro", KeySet: keys})
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator() error = %v", err)
+	}
+
+	signingInput := encodeJWTPart(t, map[string]any{"alg": "ES256", "kid": "ec-1"}) + "." + encodeJWTPart(t, validClaims())
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	sig := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+
+	if _, err := a.Authenticate(context.Background(), signingInput+"."+base64.RawURLEncoding.EncodeToString(sig)); err != nil {
+		t.Errorf("Authenticate() error = %v", err)
+	}
+}
+
+func TestOIDCAuthenticator_DiscoveryAndJWKSCache(t *testing.T) {
+	signer := newTestSigner(t, "key-1")
+	rotated := newTestSigner(t, "key-2")
+
+	var jwksFetches atomic.Int32
+	var serveRotated atomic.Bool
+	var issuer string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]string{"issuer": issuer, "jwks_uri": issuer + "/jwks"})
+		case "/jwks":
+			jwksFetches.Add(1)
+			keys := []JWK{signer.jwk()}
+			if serveRotated.Load() {
+				keys = append(keys, rotated.jwk())
+			}
+			_, _ = w.Write(jwksJSON(t, keys...))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	issuer = srv.URL
+
+	a, err := NewOIDCAuthenticator(OIDCConfig{Issuer: issuer, Audience: testAudience, DefaultScope: "admin:ro"})
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator() error = %v", err)
+	}
+
+	claims := validClaims()
+	claims["iss"] = issuer
+	for i := 0; i < 3; i++ {
+		if _, err := a.Authenticate(context.Background(), signer.sign(t, claims)); err != nil {
+			t.Fatalf("Authenticate() error = %v", err)
+		}
+	}
+	if got := jwksFetches.Load(); got != 1 {
+		t.Errorf("JWKS fetched %d times, want 1 (cached)", got)
+	}
+
+	// An unknown kid right after a fetch does not hammer the issuer
+	serveRotated.Store(true)
+	if _, err := a.Authenticate(context.Background(), rotated.sign(t, claims)); err == nil {
+		t.Error("expected unknown key to fail within refresh interval")
+	}
+	if got := jwksFetches.Load(); got != 1 {
+		t.Errorf("JWKS fetched %d times, want 1", got)
+	}
+
+	// Once the refresh interval has passed, an unknown kid triggers a refresh
+	ks := a.keys.(*RemoteKeySet)
+	ks.mu.Lock()
+	ks.fetchedAt = time.Now().Add(-2 * jwksRefreshInterval)
+	ks.mu.Unlock()
+	if _, err := a.Authenticate(context.Background(), rotated.sign(t, claims)); err != nil {
+		t.Errorf("Authenticate() with rotated key error = %v", err)
+	}
+	if got := jwksFetches.Load(); got != 2 {
+		t.Errorf("JWKS fetched %d times, want 2", got)
+	}
+}
+
+func TestOIDCAuthenticator_UnreachableDiscovery(t *testing.T) {
+	signer := newTestSigner(t, "key-1")
+
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	a, err := NewOIDCAuthenticator(OIDCConfig{Issuer: srv.URL, Audience: testAudience, DefaultScope: "admin:ro"})
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator() error = %v", err)
+	}
+	claims := validClaims()
+	claims["iss"] = srv.URL
+	jwt := signer.sign(t, claims)
+
+	// While discovery hangs, other callers wait only as long as their context
+	discovered := make(chan error)
+	go func() {
+		_, err := a.Authenticate(context.Background(), jwt)
+		discovered <- err
+	}()
+	for fetches.Load() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := a.Authenticate(ctx, jwt); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Authenticate() during discovery error = %v, want deadline exceeded", err)
+	}
+	close(release)
+	if err := <-discovered; err == nil {
+		t.Fatal("Authenticate() with failed discovery: expected error")
+	}
+
+	// The failure is remembered instead of retried on every request
+	for i := 0; i < 3; i++ {
+		if _, err := a.Authenticate(context.Background(), jwt); err == nil || !strings.Contains(err.Error(), "OIDC discovery") {
+			t.Errorf("Authenticate() after failed discovery error = %v, want the discovery error", err)
+		}
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("discovery fetched %d times, want 1", got)
+	}
+}
+
+func TestRemoteKeySet_UnreachableIssuer(t *testing.T) {
+	signer := newTestSigner(t, "key-1")
+
+	var fetches atomic.Int32
+	var down atomic.Bool
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if down.Load() {
+			<-release
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(jwksJSON(t, signer.jwk()))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	ks := NewRemoteKeySet(srv.URL, time.Hour, nil)
+	if _, err := ks.Key(context.Background(), "key-1"); err != nil {
+		t.Fatalf("Key() error = %v", err)
+	}
+
+	// Expire the cache while the issuer hangs: one caller refreshes, the
+	// others keep verifying with the cached keys instead of queueing
+	down.Store(true)
+	ks.mu.Lock()
+	ks.fetchedAt = time.Now().Add(-2 * time.Hour)
+	ks.mu.Unlock()
+	refreshed := make(chan error)
+	go func() {
+		_, err := ks.Key(context.Background(), "key-1")
+		refreshed <- err
+	}()
+	for fetches.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := ks.Key(context.Background(), "key-1"); err != nil {
+			t.Fatalf("Key() during refresh error = %v", err)
+		}
+	}
+	release <- struct{}{}
+	if err := <-refreshed; err != nil {
+		t.Errorf("Key() with a failed refresh error = %v, want the stale key", err)
+	}
+
+	// The failed refresh backs off instead of retrying on every request
+	for i := 0; i < 3; i++ {
+		if _, err := ks.Key(context.Background(), "key-1"); err != nil {
+			t.Errorf("Key() after failed refresh error = %v", err)
+		}
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("JWKS fetched %d times, want 2", got)
+	}
+}
+
+func TestAuthenticatorMiddleware_Chain(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	_, tokenID, _ := store.CreateToken("static", ScopeAdmin, nil)
+
+	signer := newTestSigner(t, "key-1")
+	oidc := newTestOIDC(t, signer, OIDCConfig{DefaultScope: "project:abc"})
+
+	var got *AuthContext
+	handler := AuthenticatorMiddleware(NewChain(NewTokenAuthenticator(store), oidc))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(credential string) int {
+		got = nil
+		req := httptest.NewRequest("GET", "/", http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+credential)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := serve(tokenID); code != http.StatusOK || got.Type != AuthTypeToken {
+		t.Errorf("static token: status %d, auth %+v", code, got)
+	}
+	if code := serve(signer.sign(t, validClaims())); code != http.StatusOK || got.Type != AuthTypeOIDC || got.Token.Scope != "project:abc" {
+		t.Errorf("JWT: status %d, auth %+v", code, got)
+	}
+	if code := serve("not-a-token"); code != http.StatusUnauthorized {
+		t.Errorf("invalid token: status %d, want 401", code)
+	}
+}
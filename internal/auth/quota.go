@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrQuotaExceeded is returned when a token has used up one of its quotas
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Quota holds per-token limits. Zero values mean unlimited (or the server
+// default, for the request rate).
+type Quota struct {
+	RequestsPerSecond     float64 `json:"requests_per_second,omitempty"`
+	Burst                 int     `json:"burst,omitempty"`
+	MaxConcurrentSessions int     `json:"max_concurrent_sessions,omitempty"`
+	MaxSessionsPerDay     int     `json:"max_sessions_per_day,omitempty"`
+	MaxTokensPerDay       int64   `json:"max_tokens_per_day,omitempty"`
+}
+
+// IsZero reports whether the quota sets no limits
+func (q *Quota) IsZero() bool {
+	return q == nil || *q == Quota{}
+}
+
+// String renders the quota on one line
+func (q *Quota) String() string {
+	if q.IsZero() {
+		return "unlimited"
+	}
+	var parts []string
+	if q.RequestsPerSecond > 0 {
+		parts = append(parts, fmt.Sprintf("%g req/s", q.RequestsPerSecond))
+	}
+	if q.Burst > 0 {
+		parts = append(parts, fmt.Sprintf("burst %d", q.Burst))
+	}
+	if q.MaxConcurrentSessions > 0 {
+		parts = append(parts, fmt.Sprintf("%d concurrent sessions", q.MaxConcurrentSessions))
+	}
+	if q.MaxSessionsPerDay > 0 {
+		parts = append(parts, fmt.Sprintf("%d sessions/day", q.MaxSessionsPerDay))
+	}
+	if q.MaxTokensPerDay > 0 {
+		parts = append(parts, fmt.Sprintf("%d tokens/day", q.MaxTokensPerDay))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Validate checks that quota values are not negative
+func (q *Quota) Validate() error {
+	if q.RequestsPerSecond < 0 || q.Burst < 0 || q.MaxConcurrentSessions < 0 || q.MaxSessionsPerDay < 0 || q.MaxTokensPerDay < 0 {
+		return fmt.Errorf("quota values cannot be negative")
+	}
+	return nil
+}
+
+// Usage is a token's consumption for one UTC day
+type Usage struct {
+	TokenID      string `json:"token_id"`
+	Day          string `json:"day"` // YYYY-MM-DD (UTC)
+	Requests     int64  `json:"requests"`
+	Sessions     int    `json:"sessions"`
+	InputTokens  int64  `json:"input_tokens"`
+	OutputTokens int64  `json:"output_tokens"`
+}
+
+// ModelTokens returns the total model tokens consumed
+func (u *Usage) ModelTokens() int64 {
+	return u.InputTokens + u.OutputTokens
+}
+
+// usageDay returns the usage bucket for a time
+func usageDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// SetQuota sets or replaces the quota of a token. A nil or zero quota removes all limits.
+func (s *Store) SetQuota(tokenID string, quota *Quota) error {
+	var exists int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM tokens WHERE id = ?`, tokenID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to query token: %w", err)
+	}
+	if exists == 0 {
+		return ErrTokenNotFound
+	}
+
+	if quota.IsZero() {
+		_, err := s.db.Exec(`DELETE FROM token_quotas WHERE token_id = ?`, tokenID)
+		return err
+	}
+	if err := quota.Validate(); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO token_quotas (token_id, requests_per_second, burst, max_concurrent_sessions, max_sessions_per_day, max_tokens_per_day)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(token_id) DO UPDATE SET
+			requests_per_second = excluded.requests_per_second,
+			burst = excluded.burst,
+			max_concurrent_sessions = excluded.max_concurrent_sessions,
+			max_sessions_per_day = excluded.max_sessions_per_day,
+			max_tokens_per_day = excluded.max_tokens_per_day`,
+		tokenID, quota.RequestsPerSecond, quota.Burst, quota.MaxConcurrentSessions, quota.MaxSessionsPerDay, quota.MaxTokensPerDay,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set quota: %w", err)
+	}
+	return nil
+}
+
+// GetQuota returns the quota of a token, or nil if it has none
+func (s *Store) GetQuota(tokenID string) (*Quota, error) {
+	var q Quota
+	err := s.db.QueryRow(`
+		SELECT requests_per_second, burst, max_concurrent_sessions, max_sessions_per_day, max_tokens_per_day
+		FROM token_quotas WHERE token_id = ?`, tokenID,
+	).Scan(&q.RequestsPerSecond, &q.Burst, &q.MaxConcurrentSessions, &q.MaxSessionsPerDay, &q.MaxTokensPerDay)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quota: %w", err)
+	}
+	return &q, nil
+}
+
+// RecordRequest counts an authenticated request against today's usage
+func (s *Store) RecordRequest(tokenID string) error {
+	return s.addUsage(tokenID, 1, 0, 0, 0)
+}
+
+// RecordSession counts a started session against today's usage
+func (s *Store) RecordSession(tokenID string) error {
+	return s.addUsage(tokenID, 0, 1, 0, 0)
+}
+
+// RecordModelTokens counts model tokens against today's usage
+func (s *Store) RecordModelTokens(tokenID string, inputTokens, outputTokens int) error {
+	if inputTokens == 0 && outputTokens == 0 {
+		return nil
+	}
+	return s.addUsage(tokenID, 0, 0, inputTokens, outputTokens)
+}
+
+func (s *Store) addUsage(tokenID string, requests, sessions, inputTokens, outputTokens int) error {
+	_, err := s.db.Exec(`
+		INSERT INTO token_usage (token_id, day, requests, sessions, input_tokens, output_tokens)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(token_id, day) DO UPDATE SET
+			requests = requests + excluded.requests,
+			sessions = sessions + excluded.sessions,
+			input_tokens = input_tokens + excluded.input_tokens,
+			output_tokens = output_tokens + excluded.output_tokens`,
+		tokenID, usageDay(time.Now()), requests, sessions, inputTokens, outputTokens,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record usage: %w", err)
+	}
+	return nil
+}
+
+// GetUsage returns a token's usage for the UTC day containing t
+func (s *Store) GetUsage(tokenID string, t time.Time) (*Usage, error) {
+	usage := &Usage{TokenID: tokenID, Day: usageDay(t)}
+	err := s.db.QueryRow(`
+		SELECT requests, sessions, input_tokens, output_tokens
+		FROM token_usage WHERE token_id = ? AND day = ?`, tokenID, usage.Day,
+	).Scan(&usage.Requests, &usage.Sessions, &usage.InputTokens, &usage.OutputTokens)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	return usage, nil
+}
+
+// CheckSessionQuota checks whether a token may start another session, given its
+// number of currently active sessions
+func (s *Store) CheckSessionQuota(tokenID string, quota *Quota, activeSessions int) error {
+	if quota.IsZero() {
+		return nil
+	}
+	if quota.MaxConcurrentSessions > 0 && activeSessions >= quota.MaxConcurrentSessions {
+		return fmt.Errorf("%w: %d concurrent sessions allowed", ErrQuotaExceeded, quota.MaxConcurrentSessions)
+	}
+	if quota.MaxSessionsPerDay == 0 && quota.MaxTokensPerDay == 0 {
+		return nil
+	}
+	usage, err := s.GetUsage(tokenID, time.Now())
+	if err != nil {
+		return err
+	}
+	if quota.MaxSessionsPerDay > 0 && usage.Sessions >= quota.MaxSessionsPerDay {
+		return fmt.Errorf("%w: %d sessions per day allowed", ErrQuotaExceeded, quota.MaxSessionsPerDay)
+	}
+	return checkTokenQuota(quota, usage)
+}
+
+// CheckModelTokenQuota checks whether a token has model tokens left today
+func (s *Store) CheckModelTokenQuota(tokenID string, quota *Quota) error {
+	if quota.IsZero() || quota.MaxTokensPerDay == 0 {
+		return nil
+	}
+	usage, err := s.GetUsage(tokenID, time.Now())
+	if err != nil {
+		return err
+	}
+	return checkTokenQuota(quota, usage)
+}
+
+func checkTokenQuota(quota *Quota, usage *Usage) error {
+	if quota.MaxTokensPerDay > 0 && usage.ModelTokens() >= quota.MaxTokensPerDay {
+		return fmt.Errorf("%w: %d model tokens per day allowed", ErrQuotaExceeded, quota.MaxTokensPerDay)
+	}
+	return nil
+}
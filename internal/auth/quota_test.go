@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newQuotaTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	_, tokenID, err := store.CreateToken("quota-token", ScopeAdmin, nil)
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+	return store, tokenID
+}
+
+func TestStore_SetAndGetQuota(t *testing.T) {
+	store, tokenID := newQuotaTestStore(t)
+
+	quota, err := store.GetQuota(tokenID)
+	if err != nil || quota != nil {
+		t.Fatalf("GetQuota() = %v, %v; want nil, nil", quota, err)
+	}
+
+	want := &Quota{RequestsPerSecond: 2.5, Burst: 4, MaxConcurrentSessions: 1, MaxSessionsPerDay: 10, MaxTokensPerDay: 50000}
+	if err := store.SetQuota(tokenID, want); err != nil {
+		t.Fatalf("SetQuota() error = %v", err)
+	}
+
+	got, err := store.GetQuota(tokenID)
+	if err != nil {
+		t.Fatalf("GetQuota() error = %v", err)
+	}
+	if *got != *want {
+		t.Errorf("GetQuota() = %+v, want %+v", got, want)
+	}
+
+	// ValidateToken and ListTokens carry the quota
+	validated, err := store.ValidateToken(tokenID)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if validated.Quota == nil || *validated.Quota != *want {
+		t.Errorf("ValidateToken().Quota = %+v, want %+v", validated.Quota, want)
+	}
+	tokens, err := store.ListTokens()
+	if err != nil {
+		t.Fatalf("ListTokens() error = %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].Quota == nil || *tokens[0].Quota != *want {
+		t.Errorf("ListTokens() quota = %+v, want %+v", tokens[0].Quota, want)
+	}
+
+	// Zero quota clears limits
+	if err := store.SetQuota(tokenID, &Quota{}); err != nil {
+		t.Fatalf("SetQuota(zero) error = %v", err)
+	}
+	if got, _ := store.GetQuota(tokenID); got != nil {
+		t.Errorf("GetQuota() after clear = %+v, want nil", got)
+	}
+}
+
+func TestStore_SetQuota_Errors(t *testing.T) {
+	store, tokenID := newQuotaTestStore(t)
+
+	if err := store.SetQuota("oub_missing", &Quota{MaxSessionsPerDay: 1}); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("SetQuota(missing) error = %v, want ErrTokenNotFound", err)
+	}
+	if err := store.SetQuota(tokenID, &Quota{MaxSessionsPerDay: -1}); err == nil {
+		t.Error("SetQuota(negative) should fail")
+	}
+}
+
+func TestStore_RecordUsage(t *testing.T) {
+	store, tokenID := newQuotaTestStore(t)
+
+	for i := 0; i < 3; i++ {
+		if err := store.RecordRequest(tokenID); err != nil {
+			t.Fatalf("RecordRequest() error = %v", err)
+		}
+	}
+	if err := store.RecordSession(tokenID); err != nil {
+		t.Fatalf("RecordSession() error = %v", err)
+	}
+	if err := store.RecordModelTokens(tokenID, 100, 40); err != nil {
+		t.Fatalf("RecordModelTokens() error = %v", err)
+	}
+	if err := store.RecordModelTokens(tokenID, 10, 5); err != nil {
+		t.Fatalf("RecordModelTokens() error = %v", err)
+	}
+
+	usage, err := store.GetUsage(tokenID, time.Now())
+	if err != nil {
+		t.Fatalf("GetUsage() error = %v", err)
+	}
+	if usage.Requests != 3 || usage.Sessions != 1 || usage.InputTokens != 110 || usage.OutputTokens != 45 {
+		t.Errorf("GetUsage() = %+v", usage)
+	}
+	if usage.ModelTokens() != 155 {
+		t.Errorf("ModelTokens() = %d, want 155", usage.ModelTokens())
+	}
+
+	// Other days are empty
+	yesterday, err := store.GetUsage(tokenID, time.Now().Add(-48*time.Hour))
+	if err != nil {
+		t.Fatalf("GetUsage() error = %v", err)
+	}
+	if yesterday.Requests != 0 || yesterday.Sessions != 0 {
+		t.Errorf("GetUsage(yesterday) = %+v, want empty", yesterday)
+	}
+}
+
+func TestStore_CheckSessionQuota(t *testing.T) {
+	store, tokenID := newQuotaTestStore(t)
+
+	if err := store.CheckSessionQuota(tokenID, nil, 100); err != nil {
+		t.Errorf("CheckSessionQuota(nil quota) error = %v", err)
+	}
+
+	concurrent := &Quota{MaxConcurrentSessions: 2}
+	if err := store.CheckSessionQuota(tokenID, concurrent, 1); err != nil {
+		t.Errorf("CheckSessionQuota(1 active) error = %v", err)
+	}
+	if err := store.CheckSessionQuota(tokenID, concurrent, 2); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("CheckSessionQuota(2 active) error = %v, want ErrQuotaExceeded", err)
+	}
+
+	daily := &Quota{MaxSessionsPerDay: 1}
+	if err := store.CheckSessionQuota(tokenID, daily, 0); err != nil {
+		t.Errorf("CheckSessionQuota(no sessions) error = %v", err)
+	}
+	_ = store.RecordSession(tokenID)
+	if err := store.CheckSessionQuota(tokenID, daily, 0); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("CheckSessionQuota(daily) error = %v, want ErrQuotaExceeded", err)
+	}
+
+	tokens := &Quota{MaxTokensPerDay: 100}
+	if err := store.CheckSessionQuota(tokenID, tokens, 0); err != nil {
+		t.Errorf("CheckSessionQuota(tokens left) error = %v", err)
+	}
+	_ = store.RecordModelTokens(tokenID, 60, 40)
+	if err := store.CheckSessionQuota(tokenID, tokens, 0); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("CheckSessionQuota(tokens used) error = %v, want ErrQuotaExceeded", err)
+	}
+	if err := store.CheckModelTokenQuota(tokenID, tokens); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("CheckModelTokenQuota() error = %v, want ErrQuotaExceeded", err)
+	}
+}
+
+func TestStore_RevokeToken_RemovesQuota(t *testing.T) {
+	store, tokenID := newQuotaTestStore(t)
+
+	if err := store.SetQuota(tokenID, &Quota{MaxSessionsPerDay: 5}); err != nil {
+		t.Fatalf("SetQuota() error = %v", err)
+	}
+	if err := store.RevokeToken(tokenID); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+	if quota, _ := store.GetQuota(tokenID); quota != nil {
+		t.Errorf("GetQuota() after revoke = %+v, want nil", quota)
+	}
+}
+
+func TestQuota_String(t *testing.T) {
+	tests := []struct {
+		quota *Quota
+		want  string
+	}{
+		{nil, "unlimited"},
+		{&Quota{}, "unlimited"},
+		{&Quota{RequestsPerSecond: 0.5}, "0.5 req/s"},
+		{&Quota{MaxConcurrentSessions: 2, MaxTokensPerDay: 1000}, "2 concurrent sessions, 1000 tokens/day"},
+	}
+	for _, tt := range tests {
+		if got := tt.quota.String(); got != tt.want {
+			t.Errorf("String(%+v) = %q, want %q", tt.quota, got, tt.want)
+		}
+	}
+}
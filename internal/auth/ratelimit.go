@@ -2,10 +2,13 @@ package auth
 
 import (
 	"encoding/json"
+	"math"
+	"net"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/HyphaGroup/oubliette/internal/logger"
 	"golang.org/x/time/rate"
 )
 
@@ -34,26 +37,40 @@ func DefaultRateLimiter() *RateLimiter {
 	return NewRateLimiter(10, 20)
 }
 
-// getLimiter returns the rate limiter for a given key (token ID)
+// DefaultIPRateLimiter returns the per-client-IP limiter applied before
+// authentication: 30 requests/second with burst of 60
+func DefaultIPRateLimiter() *RateLimiter {
+	return NewRateLimiter(30, 60)
+}
+
+// getLimiter returns the rate limiter for a given key (token ID) with the default limits
 func (r *RateLimiter) getLimiter(key string) *rate.Limiter {
+	return r.limiterFor(key, r.rate, r.burst)
+}
+
+// limiterFor returns the rate limiter for a given key, adjusting its limits if
+// they changed (e.g. the token's quota was updated)
+func (r *RateLimiter) limiterFor(key string, limit rate.Limit, burst int) *rate.Limiter {
 	r.mu.RLock()
 	limiter, exists := r.limiters[key]
 	r.mu.RUnlock()
 
-	if exists {
-		return limiter
+	if !exists {
+		r.mu.Lock()
+		// Double-check after acquiring write lock
+		if limiter, exists = r.limiters[key]; !exists {
+			limiter = rate.NewLimiter(limit, burst)
+			r.limiters[key] = limiter
+		}
+		r.mu.Unlock()
 	}
 
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	// Double-check after acquiring write lock
-	if limiter, exists = r.limiters[key]; exists {
-		return limiter
+	if limiter.Limit() != limit {
+		limiter.SetLimit(limit)
+	}
+	if limiter.Burst() != burst {
+		limiter.SetBurst(burst)
 	}
-
-	limiter = rate.NewLimiter(r.rate, r.burst)
-	r.limiters[key] = limiter
 	return limiter
 }
 
@@ -62,6 +79,20 @@ func (r *RateLimiter) Allow(key string) bool {
 	return r.getLimiter(key).Allow()
 }
 
+// AllowQuota checks if a request should be allowed for the given key, using the
+// token's request rate quota when set and the limiter defaults otherwise
+func (r *RateLimiter) AllowQuota(key string, quota *Quota) bool {
+	if quota == nil || quota.RequestsPerSecond <= 0 {
+		return r.Allow(key)
+	}
+	burst := quota.Burst
+	if burst <= 0 {
+		// Default burst: two seconds worth of requests
+		burst = int(math.Ceil(quota.RequestsPerSecond * 2))
+	}
+	return r.limiterFor(key, rate.Limit(quota.RequestsPerSecond), burst).Allow()
+}
+
 // Cleanup removes stale limiters that haven't been used recently
 // Call this periodically to prevent memory growth
 func (r *RateLimiter) Cleanup(maxAge time.Duration) {
@@ -81,25 +112,17 @@ func RateLimitMiddleware(limiter *RateLimiter) func(http.Handler) http.Handler {
 			authCtx := FromContext(r.Context())
 
 			var key string
+			var quota *Quota
 			if authCtx != nil && authCtx.Token != nil {
 				key = authCtx.Token.ID
+				quota = authCtx.Token.Quota
 			} else {
 				// Fall back to IP address for unauthenticated requests
-				key = r.RemoteAddr
+				key = clientIP(r)
 			}
 
-			if !limiter.Allow(key) {
-				w.Header().Set("Content-Type", "application/json")
-				w.Header().Set("Retry-After", "1")
-				w.WriteHeader(http.StatusTooManyRequests)
-				_ = json.NewEncoder(w).Encode(map[string]interface{}{
-					"jsonrpc": "2.0",
-					"error": map[string]interface{}{
-						"code":    -32029,
-						"message": "Rate limit exceeded. Please slow down.",
-					},
-					"id": nil,
-				})
+			if !limiter.AllowQuota(key, quota) {
+				rateLimitExceeded(w)
 				return
 			}
 
@@ -107,3 +130,57 @@ func RateLimitMiddleware(limiter *RateLimiter) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// IPRateLimitMiddleware limits requests per client IP. Apply it BEFORE auth
+// middleware so requests with missing or invalid credentials, and the token
+// lookups and JWT verifications they cause, are limited too.
+func IPRateLimitMiddleware(limiter *RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !limiter.Allow(clientIP(r)) {
+				rateLimitExceeded(w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the request's remote IP without the port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func rateLimitExceeded(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", "1")
+	w.WriteHeader(http.StatusTooManyRequests)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"error": map[string]interface{}{
+			"code":    -32029,
+			"message": "Rate limit exceeded. Please slow down.",
+		},
+		"id": nil,
+	})
+}
+
+// UsageMiddleware counts authenticated requests against each token's daily usage.
+// The count is recorded before the request is served, so quota checks further
+// down see it. Must be applied AFTER auth middleware (needs token from context)
+func UsageMiddleware(store *Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if authCtx := FromContext(r.Context()); authCtx != nil && authCtx.Token != nil {
+				if err := store.RecordRequest(authCtx.Token.ID); err != nil {
+					logger.Error("Failed to record request usage for token %s: %v", maskToken(authCtx.Token.ID), err)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
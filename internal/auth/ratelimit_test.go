@@ -141,3 +141,25 @@ func TestRateLimiter_getLimiter_DoubleCheck(t *testing.T) {
 		}
 	}
 }
+
+func TestRateLimiter_AllowQuota(t *testing.T) {
+	limiter := NewRateLimiter(100, 100)
+
+	// Quota overrides the default burst
+	quota := &Quota{RequestsPerSecond: 1, Burst: 2}
+	for i := 0; i < 2; i++ {
+		if !limiter.AllowQuota("quota-key", quota) {
+			t.Fatalf("request %d should be allowed", i)
+		}
+	}
+	if limiter.AllowQuota("quota-key", quota) {
+		t.Error("request beyond quota burst should be denied")
+	}
+
+	// Without a rate quota the defaults apply
+	for i := 0; i < 50; i++ {
+		if !limiter.AllowQuota("default-key", &Quota{MaxSessionsPerDay: 1}) {
+			t.Fatalf("request %d should be allowed with default limits", i)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scope prefixes for tool-level rules
+const (
+	toolRulePrefix = "tool:"
+	denyRulePrefix = "deny:"
+)
+
+// ToolRule grants or denies a tool, optionally narrowed to one action.
+// ReadOnly grants only the tool's read actions (allow rules only).
+type ToolRule struct {
+	Tool     string
+	Action   string
+	ReadOnly bool
+}
+
+// matches reports whether the rule covers the given tool call
+func (r ToolRule) matches(tool, action string, write bool) bool {
+	if r.Tool != tool {
+		return false
+	}
+	if r.ReadOnly {
+		return !write
+	}
+	return r.Action == "" || r.Action == action
+}
+
+// Scope is the parsed form of a token scope string.
+//
+// A scope string holds one or more entries separated by spaces or commas:
+//
+//	admin | admin:ro                 all projects
+//	project:<id> | project:<id>:ro   one project (repeatable for multi-project tokens)
+//	tool:<name>                      allow all actions of a tool
+//	tool:<name>:<action>             allow one action of a tool
+//	tool:<name>:ro                   allow only the read actions of a tool
+//	deny:<name>[:<action>]           deny a tool or one of its actions
+//
+// When any tool: entry is present, only the listed tools are allowed. deny:
+// entries always win. Tool rules narrow, never widen, what the admin/project
+// entries grant.
+type Scope struct {
+	Admin    bool            // Full admin
+	AdminRO  bool            // Read-only admin (implied by Admin)
+	Projects map[string]bool // Project ID -> read-only
+	Allowed  []ToolRule      // Tool allowlist; empty means all tools
+	Denied   []ToolRule      // Tool denylist
+}
+
+// splitScope splits a scope string into its entries
+func splitScope(scope string) []string {
+	return strings.FieldsFunc(scope, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+}
+
+// ParseScope parses and validates a token scope string
+func ParseScope(scope string) (*Scope, error) {
+	entries := splitScope(scope)
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("scope cannot be empty")
+	}
+
+	s := &Scope{Projects: make(map[string]bool)}
+	for _, entry := range entries {
+		switch {
+		case entry == ScopeAdmin:
+			s.Admin = true
+			s.AdminRO = true
+		case entry == ScopeAdminRO:
+			s.AdminRO = true
+		case strings.HasPrefix(entry, "project:"):
+			projectID := ExtractProjectID(entry)
+			if projectID == "" {
+				return nil, fmt.Errorf("invalid scope entry '%s': missing project ID", entry)
+			}
+			readOnly := strings.HasSuffix(entry, ":ro")
+			// A writable grant for the same project takes precedence
+			if existing, ok := s.Projects[projectID]; ok {
+				readOnly = readOnly && existing
+			}
+			s.Projects[projectID] = readOnly
+		case strings.HasPrefix(entry, toolRulePrefix):
+			rule, err := parseToolRule(entry, toolRulePrefix)
+			if err != nil {
+				return nil, err
+			}
+			s.Allowed = append(s.Allowed, rule)
+		case strings.HasPrefix(entry, denyRulePrefix):
+			rule, err := parseToolRule(entry, denyRulePrefix)
+			if err != nil {
+				return nil, err
+			}
+			if rule.ReadOnly {
+				return nil, fmt.Errorf("invalid scope entry '%s': deny rules cannot be read-only", entry)
+			}
+			s.Denied = append(s.Denied, rule)
+		default:
+			return nil, fmt.Errorf("invalid scope entry '%s'", entry)
+		}
+	}
+
+	if !s.AdminRO && len(s.Projects) == 0 {
+		return nil, fmt.Errorf("scope must include admin, admin:ro or at least one project:<id> entry")
+	}
+
+	return s, nil
+}
+
+func parseToolRule(entry, prefix string) (ToolRule, error) {
+	parts := strings.Split(strings.TrimPrefix(entry, prefix), ":")
+	if len(parts) > 2 || parts[0] == "" || (len(parts) == 2 && parts[1] == "") {
+		return ToolRule{}, fmt.Errorf("invalid scope entry '%s': expected %s<tool>[:<action>]", entry, prefix)
+	}
+	rule := ToolRule{Tool: parts[0]}
+	if len(parts) == 2 {
+		if parts[1] == "ro" {
+			rule.ReadOnly = true
+		} else {
+			rule.Action = parts[1]
+		}
+	}
+	return rule, nil
+}
+
+// ValidateScope checks that a scope string is well-formed
+func ValidateScope(scope string) error {
+	_, err := ParseScope(scope)
+	return err
+}
+
+// IsAdmin reports whether the scope grants full admin access
+func (s *Scope) IsAdmin() bool {
+	return s.Admin
+}
+
+// HasAdmin reports whether the scope grants admin or admin:ro access
+func (s *Scope) HasAdmin() bool {
+	return s.AdminRO
+}
+
+// CanAccessProject reports whether the scope can read a project
+func (s *Scope) CanAccessProject(projectID string) bool {
+	if s.AdminRO {
+		return true
+	}
+	_, ok := s.Projects[projectID]
+	return ok
+}
+
+// CanWriteProject reports whether the scope can modify a project
+func (s *Scope) CanWriteProject(projectID string) bool {
+	if s.Admin {
+		return true
+	}
+	readOnly, ok := s.Projects[projectID]
+	return ok && !readOnly
+}
+
+// CanWrite reports whether the scope grants write access to anything
+func (s *Scope) CanWrite() bool {
+	if s.Admin {
+		return true
+	}
+	for _, readOnly := range s.Projects {
+		if !readOnly {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectIDs returns the project IDs granted by the scope
+func (s *Scope) ProjectIDs() []string {
+	ids := make([]string, 0, len(s.Projects))
+	for id := range s.Projects {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// HasToolRules reports whether the scope restricts tools
+func (s *Scope) HasToolRules() bool {
+	return len(s.Allowed) > 0 || len(s.Denied) > 0
+}
+
+// AllowsToolAction reports whether tool rules permit a call to a tool action.
+// write indicates whether the action modifies state.
+func (s *Scope) AllowsToolAction(tool, action string, write bool) bool {
+	for _, rule := range s.Denied {
+		if rule.matches(tool, action, write) {
+			return false
+		}
+	}
+	if len(s.Allowed) == 0 {
+		return true
+	}
+	for _, rule := range s.Allowed {
+		if rule.matches(tool, action, write) {
+			return true
+		}
+	}
+	return false
+}
+
+// MayUseTool reports whether any action of a tool could be permitted.
+// Used for tool discovery; calls are still checked per action.
+func (s *Scope) MayUseTool(tool string) bool {
+	for _, rule := range s.Denied {
+		if rule.Tool == tool && rule.Action == "" {
+			return false
+		}
+	}
+	if len(s.Allowed) == 0 {
+		return true
+	}
+	for _, rule := range s.Allowed {
+		if rule.Tool == tool {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"testing"
+)
+
+func TestParseScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		scope   string
+		wantErr bool
+	}{
+		{"admin", "admin", false},
+		{"admin:ro", "admin:ro", false},
+		{"project", "project:abc", false},
+		{"project ro", "project:abc:ro", false},
+		{"multi project space", "project:a project:b:ro", false},
+		{"multi project comma", "project:a,project:b", false},
+		{"tool grant", "project:a tool:session:message", false},
+		{"tool read-only", "admin tool:schedule:ro", false},
+		{"deny", "admin deny:container:exec", false},
+		{"empty", "", true},
+		{"unknown entry", "superuser", true},
+		{"missing project id", "project:", true},
+		{"tools only", "tool:session", true},
+		{"empty tool", "admin tool:", true},
+		{"too many parts", "admin tool:a:b:c", true},
+		{"deny ro", "admin deny:schedule:ro", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseScope(tt.scope)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseScope(%q) error = %v, wantErr %v", tt.scope, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScope_Projects(t *testing.T) {
+	s, err := ParseScope("project:a project:b:ro project:c:ro project:c")
+	if err != nil {
+		t.Fatalf("ParseScope() error = %v", err)
+	}
+
+	if !s.CanAccessProject("a") || !s.CanAccessProject("b") || !s.CanAccessProject("c") {
+		t.Error("expected access to projects a, b and c")
+	}
+	if s.CanAccessProject("d") {
+		t.Error("expected no access to project d")
+	}
+	if !s.CanWriteProject("a") {
+		t.Error("expected write access to project a")
+	}
+	if s.CanWriteProject("b") {
+		t.Error("expected read-only access to project b")
+	}
+	if !s.CanWriteProject("c") {
+		t.Error("writable grant should take precedence for project c")
+	}
+	if !s.CanWrite() {
+		t.Error("CanWrite() should be true with any writable project")
+	}
+	if s.IsAdmin() || s.HasAdmin() {
+		t.Error("project scope should not be admin")
+	}
+}
+
+func TestScope_AllowsToolAction(t *testing.T) {
+	tests := []struct {
+		name   string
+		scope  string
+		tool   string
+		action string
+		write  bool
+		want   bool
+	}{
+		{"no rules allows all", "admin", "container", "exec", true, true},
+		{"action grant", "project:a tool:session:message", "session", "message", true, true},
+		{"action grant other action", "project:a tool:session:message", "session", "end", true, false},
+		{"action grant other tool", "project:a tool:session:message", "container", "exec", true, false},
+		{"tool grant all actions", "project:a tool:session", "session", "end", true, true},
+		{"read-only grant read", "admin tool:schedule:ro", "schedule", "list", false, true},
+		{"read-only grant write", "admin tool:schedule:ro", "schedule", "create", true, false},
+		{"deny action", "admin deny:container:exec", "container", "exec", true, false},
+		{"deny leaves other actions", "admin deny:container:exec", "container", "logs", false, true},
+		{"deny whole tool", "admin deny:token", "token", "list", false, false},
+		{"deny wins over grant", "admin tool:container deny:container:exec", "container", "exec", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseScope(tt.scope)
+			if err != nil {
+				t.Fatalf("ParseScope() error = %v", err)
+			}
+			if got := s.AllowsToolAction(tt.tool, tt.action, tt.write); got != tt.want {
+				t.Errorf("AllowsToolAction(%q, %q, %v) = %v, want %v", tt.tool, tt.action, tt.write, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScope_MayUseTool(t *testing.T) {
+	s, err := ParseScope("project:a tool:session:message tool:schedule:ro deny:container")
+	if err != nil {
+		t.Fatalf("ParseScope() error = %v", err)
+	}
+	if !s.MayUseTool("session") || !s.MayUseTool("schedule") {
+		t.Error("expected session and schedule to be usable")
+	}
+	if s.MayUseTool("container") || s.MayUseTool("workspace") {
+		t.Error("expected container and workspace to be hidden")
+	}
+}
+
+func TestAuthContext_MultiProject(t *testing.T) {
+	authCtx := &AuthContext{Type: AuthTypeToken, Token: &Token{Scope: "project:a project:b:ro"}}
+	if !authCtx.CanAccessProject("a") || !authCtx.CanAccessProject("b") {
+		t.Error("expected access to both projects")
+	}
+	if !authCtx.CanWriteProject("a") || authCtx.CanWriteProject("b") {
+		t.Error("expected write on a only")
+	}
+	if authCtx.IsAdmin() {
+		t.Error("multi-project scope should not be admin")
+	}
+}
@@ -34,7 +34,7 @@ func NewStore(dataDir string) (*Store, error) {
 
 	dbPath := filepath.Join(dataDir, "auth.db")
 	// Enable busy timeout for better concurrent access
-	db, err := sql.Open("sqlite", dbPath+"?_busy_timeout=5000")
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -65,6 +65,25 @@ func (s *Store) migrate() error {
 		expires_at DATETIME
 	);
 	CREATE INDEX IF NOT EXISTS idx_tokens_scope ON tokens(scope);
+
+	CREATE TABLE IF NOT EXISTS token_quotas (
+		token_id TEXT PRIMARY KEY,
+		requests_per_second REAL NOT NULL DEFAULT 0,
+		burst INTEGER NOT NULL DEFAULT 0,
+		max_concurrent_sessions INTEGER NOT NULL DEFAULT 0,
+		max_sessions_per_day INTEGER NOT NULL DEFAULT 0,
+		max_tokens_per_day INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS token_usage (
+		token_id TEXT NOT NULL,
+		day TEXT NOT NULL,
+		requests INTEGER NOT NULL DEFAULT 0,
+		sessions INTEGER NOT NULL DEFAULT 0,
+		input_tokens INTEGER NOT NULL DEFAULT 0,
+		output_tokens INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (token_id, day)
+	);
 	`
 	_, err := s.db.Exec(schema)
 	return err
@@ -135,6 +154,12 @@ func (s *Store) ValidateToken(tokenID string) (*Token, error) {
 		}
 	}
 
+	quota, err := s.GetQuota(tokenID)
+	if err != nil {
+		return nil, err
+	}
+	token.Quota = quota
+
 	// Update last used time
 	go s.updateLastUsed(tokenID)
 
@@ -148,7 +173,10 @@ func (s *Store) updateLastUsed(tokenID string) {
 // ListTokens returns all tokens (without exposing the full token ID)
 func (s *Store) ListTokens() ([]*Token, error) {
 	rows, err := s.db.Query(
-		`SELECT id, name, scope, created_at, last_used_at, expires_at FROM tokens ORDER BY created_at DESC`,
+		`SELECT t.id, t.name, t.scope, t.created_at, t.last_used_at, t.expires_at,
+			q.requests_per_second, q.burst, q.max_concurrent_sessions, q.max_sessions_per_day, q.max_tokens_per_day
+		FROM tokens t LEFT JOIN token_quotas q ON q.token_id = t.id
+		ORDER BY t.created_at DESC`,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tokens: %w", err)
@@ -159,11 +187,24 @@ func (s *Store) ListTokens() ([]*Token, error) {
 	for rows.Next() {
 		var token Token
 		var lastUsedAt, expiresAt sql.NullTime
+		var rps sql.NullFloat64
+		var burst, maxConcurrent, maxSessions, maxTokens sql.NullInt64
 
-		if err := rows.Scan(&token.ID, &token.Name, &token.Scope, &token.CreatedAt, &lastUsedAt, &expiresAt); err != nil {
+		if err := rows.Scan(&token.ID, &token.Name, &token.Scope, &token.CreatedAt, &lastUsedAt, &expiresAt,
+			&rps, &burst, &maxConcurrent, &maxSessions, &maxTokens); err != nil {
 			return nil, fmt.Errorf("failed to scan token: %w", err)
 		}
 
+		if rps.Valid {
+			token.Quota = &Quota{
+				RequestsPerSecond:     rps.Float64,
+				Burst:                 int(burst.Int64),
+				MaxConcurrentSessions: int(maxConcurrent.Int64),
+				MaxSessionsPerDay:     int(maxSessions.Int64),
+				MaxTokensPerDay:       maxTokens.Int64,
+			}
+		}
+
 		if lastUsedAt.Valid {
 			token.LastUsedAt = &lastUsedAt.Time
 		}
@@ -189,6 +230,9 @@ func (s *Store) RevokeToken(tokenID string) error {
 		return ErrTokenNotFound
 	}
 
+	// Usage history is kept for auditing; only the limits go with the token
+	_, _ = s.db.Exec(`DELETE FROM token_quotas WHERE token_id = ?`, tokenID)
+
 	return nil
 }
 
@@ -13,6 +13,7 @@ type Token struct {
 	CreatedAt  time.Time  `json:"created_at"`
 	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
 	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	Quota      *Quota     `json:"quota,omitempty"` // Per-token limits (nil = server defaults)
 }
 
 // Scope constants
@@ -31,23 +32,54 @@ func ScopeProjectRO(projectID string) string {
 	return "project:" + projectID + ":ro"
 }
 
-// IsAdminScope returns true if scope is admin or admin:ro
+// IsAdminScope returns true if scope includes admin or admin:ro
 func IsAdminScope(scope string) bool {
-	return scope == ScopeAdmin || scope == ScopeAdminRO
+	for _, entry := range splitScope(scope) {
+		if entry == ScopeAdmin || entry == ScopeAdminRO {
+			return true
+		}
+	}
+	return false
 }
 
-// IsProjectScope returns true if scope is project:<uuid> or project:<uuid>:ro
+// IsProjectScope returns true if scope includes project:<uuid> or project:<uuid>:ro
 func IsProjectScope(scope string) bool {
-	return strings.HasPrefix(scope, "project:")
+	for _, entry := range splitScope(scope) {
+		if strings.HasPrefix(entry, "project:") {
+			return true
+		}
+	}
+	return false
 }
 
-// IsReadOnlyScope returns true if scope is read-only (admin:ro, project:*:ro, or legacy read-only)
+// IsReadOnlyScope returns true if every access entry in the scope is read-only
+// (admin:ro, project:*:ro, or legacy read-only). Tool rules are ignored.
 func IsReadOnlyScope(scope string) bool {
-	return scope == ScopeAdminRO || strings.HasSuffix(scope, ":ro")
+	found := false
+	for _, entry := range splitScope(scope) {
+		if strings.HasPrefix(entry, toolRulePrefix) || strings.HasPrefix(entry, denyRulePrefix) {
+			continue
+		}
+		if entry != ScopeAdminRO && !strings.HasSuffix(entry, ":ro") {
+			return false
+		}
+		found = true
+	}
+	return found
 }
 
-// ExtractProjectID extracts project ID from a project scope, returns empty if not a project scope
+// ExtractProjectID extracts the project ID from a project scope entry, returns empty if
+// not a project scope. For multi-entry scopes the first project entry is used.
 func ExtractProjectID(scope string) string {
+	entries := splitScope(scope)
+	if len(entries) > 1 {
+		for _, entry := range entries {
+			if strings.HasPrefix(entry, "project:") {
+				return ExtractProjectID(entry)
+			}
+		}
+		return ""
+	}
 	if !strings.HasPrefix(scope, "project:") {
 		return ""
 	}
@@ -65,42 +97,79 @@ type AuthType int
 
 const (
 	AuthTypeToken AuthType = iota
+	AuthTypeOIDC
 )
 
-// AuthContext holds authentication information for a request
+// IdentityTokenPrefix prefixes the synthetic token ID of OIDC identities
+const IdentityTokenPrefix = "oidc:"
+
+// Identity describes an SSO user authenticated via OIDC
+type Identity struct {
+	Issuer  string   `json:"issuer"`
+	Subject string   `json:"subject"`
+	Email   string   `json:"email,omitempty"`
+	Name    string   `json:"name,omitempty"`
+	Groups  []string `json:"groups,omitempty"`
+}
+
+// String returns the most readable identifier for the identity
+func (i *Identity) String() string {
+	if i.Email != "" {
+		return i.Email
+	}
+	return i.Subject
+}
+
+// AuthContext holds authentication information for a request.
+// OIDC identities carry a synthetic Token (ID "oidc:<subject>") with the mapped scope,
+// so scope checks work the same for both authentication types.
 type AuthContext struct {
-	Type  AuthType
-	Token *Token
+	Type     AuthType
+	Token    *Token
+	Identity *Identity // Set for AuthTypeOIDC
 }
 
-// CanAccessProject checks if the auth context allows access to a project
-func (a *AuthContext) CanAccessProject(projectID string) bool {
+// Scope parses the token scope, returning nil if there is no token or the scope is invalid
+func (a *AuthContext) Scope() *Scope {
 	if a.Token == nil {
-		return false
+		return nil
 	}
-	// Admin scopes (admin, admin:ro, read-only) can access any project
-	if IsAdminScope(a.Token.Scope) {
-		return true
+	scope, err := ParseScope(a.Token.Scope)
+	if err != nil {
+		return nil
 	}
-	// Project scopes can only access their specific project
-	if IsProjectScope(a.Token.Scope) {
-		return ExtractProjectID(a.Token.Scope) == projectID
+	return scope
+}
+
+// CanAccessProject checks if the auth context allows access to a project
+func (a *AuthContext) CanAccessProject(projectID string) bool {
+	scope := a.Scope()
+	if scope == nil {
+		return false
 	}
-	return false
+	return scope.CanAccessProject(projectID)
 }
 
-// CanWrite checks if the auth context allows write operations
+// CanWrite checks if the auth context allows write operations on at least one project
 func (a *AuthContext) CanWrite() bool {
-	if a.Token == nil {
+	scope := a.Scope()
+	if scope == nil {
 		return false
 	}
-	return !IsReadOnlyScope(a.Token.Scope)
+	return scope.CanWrite()
 }
 
-// IsAdmin checks if the auth context has admin scope (full admin, not read-only)
-func (a *AuthContext) IsAdmin() bool {
-	if a.Type != AuthTypeToken || a.Token == nil {
+// CanWriteProject checks if the auth context allows write operations on a project
+func (a *AuthContext) CanWriteProject(projectID string) bool {
+	scope := a.Scope()
+	if scope == nil {
 		return false
 	}
-	return a.Token.Scope == ScopeAdmin
+	return scope.CanWriteProject(projectID)
+}
+
+// IsAdmin checks if the auth context has admin scope (full admin, not read-only)
+func (a *AuthContext) IsAdmin() bool {
+	scope := a.Scope()
+	return scope != nil && scope.IsAdmin()
 }
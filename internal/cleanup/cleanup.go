@@ -13,9 +13,16 @@ import (
 	"github.com/HyphaGroup/oubliette/internal/logger"
 )
 
+// SessionPruner deletes ended sessions, and their event logs, last updated
+// before maxAge. Implemented by session.Manager.
+type SessionPruner interface {
+	CleanupAllOldSessions(maxAge time.Duration) (map[string]int, error)
+}
+
 // Cleaner performs periodic resource cleanup.
 type Cleaner struct {
 	projectsDir string
+	sessions    SessionPruner
 	interval    time.Duration
 	retention   time.Duration
 	diskWarn    float64
@@ -27,8 +34,9 @@ type Cleaner struct {
 // Config holds cleanup configuration.
 type Config struct {
 	ProjectsDir      string
+	Sessions         SessionPruner // Optional; ended sessions are deleted after SessionRetention
 	Interval         time.Duration // How often to run cleanup
-	SessionRetention time.Duration // How long to keep completed sessions
+	SessionRetention time.Duration // How long to keep completed sessions and orphaned .tmp files
 	DiskWarnPercent  float64       // Warn at this disk usage percentage
 	DiskErrorPercent float64       // Error at this disk usage percentage
 }
@@ -48,6 +56,7 @@ func DefaultConfig(projectsDir string) Config {
 func New(cfg Config) *Cleaner {
 	return &Cleaner{
 		projectsDir: cfg.ProjectsDir,
+		sessions:    cfg.Sessions,
 		interval:    cfg.Interval,
 		retention:   cfg.SessionRetention,
 		diskWarn:    cfg.DiskWarnPercent,
@@ -127,68 +136,25 @@ func (c *Cleaner) cleanupTmpFiles() {
 	}
 }
 
-// cleanupOldSessions removes completed session metadata files older than retention.
-// Sessions are stored as flat JSON files: sessions/<session_id>.json
+// cleanupOldSessions removes completed and failed sessions older than retention.
+// Active sessions are never removed.
 func (c *Cleaner) cleanupOldSessions() {
-	cutoff := time.Now().Add(-c.retention)
-	var removed int
+	if c.sessions == nil {
+		return
+	}
 
-	// Walk project directories looking for sessions
-	entries, err := os.ReadDir(c.projectsDir)
+	results, err := c.sessions.CleanupAllOldSessions(c.retention)
 	if err != nil {
+		logger.Printf("⚠️  Session cleanup error: %v", err)
 		return
 	}
 
-	for _, projectEntry := range entries {
-		if !projectEntry.IsDir() {
-			continue
-		}
-
-		sessionsDir := filepath.Join(c.projectsDir, projectEntry.Name(), "sessions")
-		sessionFiles, err := os.ReadDir(sessionsDir)
-		if err != nil {
-			continue
-		}
-
-		for _, sessionFile := range sessionFiles {
-			// Skip directories and non-JSON files
-			if sessionFile.IsDir() || !strings.HasSuffix(sessionFile.Name(), ".json") {
-				continue
-			}
-
-			sessionPath := filepath.Join(sessionsDir, sessionFile.Name())
-
-			// Read session metadata
-			data, err := os.ReadFile(sessionPath)
-			if err != nil {
-				continue
-			}
-
-			// Check if session is completed or failed (not active)
-			status := string(data)
-			isCompleted := strings.Contains(status, `"status":"completed"`) ||
-				strings.Contains(status, `"status":"failed"`)
-
-			if !isCompleted {
-				continue // Never delete active sessions
-			}
-
-			// Check file modification time
-			info, err := sessionFile.Info()
-			if err != nil {
-				continue
-			}
-
-			if info.ModTime().Before(cutoff) {
-				if err := os.Remove(sessionPath); err == nil {
-					removed++
-				}
-			}
-		}
+	var removed int
+	for _, n := range results {
+		removed += n
 	}
-
 	if removed > 0 {
-		logger.Printf("🧹 Cleaned up %d old session files", removed)
+		logger.Printf("🧹 Cleaned up %d old sessions", removed)
 	}
 }
 
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/session"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -219,78 +221,79 @@ func TestCleaner_RunCleanup(t *testing.T) {
 	cleaner.runCleanup()
 }
 
+// newSessionManager returns a session manager with its store under dir
+func newSessionManager(t *testing.T, dir string) *session.Manager {
+	t.Helper()
+	store, err := session.NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return session.NewManager(filepath.Join(dir, "projects"), store, nil, "")
+}
+
 func TestCleaner_CleanupOldSessions(t *testing.T) {
 	tmpDir := t.TempDir()
-
-	// Create a project with sessions (flat JSON files, not directories)
-	projectDir := filepath.Join(tmpDir, "project-1")
-	sessionsDir := filepath.Join(projectDir, "sessions")
-	_ = os.MkdirAll(sessionsDir, 0o755)
+	mgr := newSessionManager(t, tmpDir)
+	projectID := "550e8400-e29b-41d4-a716-446655440001"
 
 	oldTime := time.Now().Add(-2 * time.Hour)
-
-	// Create old completed session file
-	oldSessionFile := filepath.Join(sessionsDir, "old-session.json")
-	_ = os.WriteFile(oldSessionFile, []byte(`{"status":"completed"}`), 0o644)
-	_ = os.Chtimes(oldSessionFile, oldTime, oldTime)
-
-	// Create new completed session file
-	newSessionFile := filepath.Join(sessionsDir, "new-session.json")
-	_ = os.WriteFile(newSessionFile, []byte(`{"status":"completed"}`), 0o644)
-
-	// Create old active session file (should not be removed)
-	activeSessionFile := filepath.Join(sessionsDir, "active-session.json")
-	_ = os.WriteFile(activeSessionFile, []byte(`{"status":"active"}`), 0o644)
-	_ = os.Chtimes(activeSessionFile, oldTime, oldTime)
+	oldDone := &session.Session{SessionID: "gogol_20250101_100000_a1", ProjectID: projectID, Status: session.StatusCompleted, UpdatedAt: oldTime}
+	newDone := &session.Session{SessionID: "gogol_20250101_110000_b2", ProjectID: projectID, Status: session.StatusCompleted, UpdatedAt: time.Now()}
+	oldActive := &session.Session{SessionID: "gogol_20250101_120000_c3", ProjectID: projectID, Status: session.StatusActive, UpdatedAt: oldTime}
+	for _, sess := range []*session.Session{oldDone, newDone, oldActive} {
+		if err := mgr.SaveSession(sess); err != nil {
+			t.Fatal(err)
+		}
+	}
 
 	cfg := Config{
-		ProjectsDir:      tmpDir,
+		ProjectsDir:      filepath.Join(tmpDir, "projects"),
+		Sessions:         mgr,
 		SessionRetention: 1 * time.Hour,
 	}
 
 	cleaner := New(cfg)
 	cleaner.cleanupOldSessions()
 
-	// Old completed session file should be removed
-	if _, err := os.Stat(oldSessionFile); !errors.Is(err, fs.ErrNotExist) {
+	// Old completed session should be removed
+	if _, err := mgr.Load(oldDone.SessionID); err == nil {
 		t.Error("old completed session should have been removed")
 	}
 
-	// New completed session file should still exist
-	if _, err := os.Stat(newSessionFile); err != nil {
+	// New completed session should still exist
+	if _, err := mgr.Load(newDone.SessionID); err != nil {
 		t.Error("new completed session should still exist")
 	}
 
-	// Active session file should still exist
-	if _, err := os.Stat(activeSessionFile); err != nil {
+	// Active session should still exist
+	if _, err := mgr.Load(oldActive.SessionID); err != nil {
 		t.Error("active session should still exist even if old")
 	}
 }
 
 func TestCleaner_CleanupOldSessions_FailedStatus(t *testing.T) {
 	tmpDir := t.TempDir()
+	mgr := newSessionManager(t, tmpDir)
 
-	projectDir := filepath.Join(tmpDir, "project-1")
-	sessionsDir := filepath.Join(projectDir, "sessions")
-	_ = os.MkdirAll(sessionsDir, 0o755)
-
+	// Create old failed session (should be cleaned up)
 	oldTime := time.Now().Add(-2 * time.Hour)
-
-	// Create old failed session file (should be cleaned up)
-	failedSessionFile := filepath.Join(sessionsDir, "failed-session.json")
-	_ = os.WriteFile(failedSessionFile, []byte(`{"status":"failed"}`), 0o644)
-	_ = os.Chtimes(failedSessionFile, oldTime, oldTime)
+	failed := &session.Session{SessionID: "gogol_20250101_100000_d4", ProjectID: "550e8400-e29b-41d4-a716-446655440001", Status: session.StatusFailed, UpdatedAt: oldTime}
+	if err := mgr.SaveSession(failed); err != nil {
+		t.Fatal(err)
+	}
 
 	cfg := Config{
-		ProjectsDir:      tmpDir,
+		ProjectsDir:      filepath.Join(tmpDir, "projects"),
+		Sessions:         mgr,
 		SessionRetention: 1 * time.Hour,
 	}
 
 	cleaner := New(cfg)
 	cleaner.cleanupOldSessions()
 
-	// Old failed session file should be removed
-	if _, err := os.Stat(failedSessionFile); !errors.Is(err, fs.ErrNotExist) {
+	// Old failed session should be removed
+	if _, err := mgr.Load(failed.SessionID); err == nil {
 		t.Error("old failed session should have been removed")
 	}
 }
@@ -1,5 +1,7 @@
 package config
 
+import "encoding/json"
+
 // CredentialRegistry holds all credentials
 type CredentialRegistry struct {
 	GitHub    GitHubCredentials   `json:"github"`
@@ -14,8 +16,19 @@ type GitHubCredentials struct {
 
 // GitHubCredential is a single GitHub token
 type GitHubCredential struct {
-	Token       string `json:"token"`
+	Token       string `json:"token,omitempty"`
 	Description string `json:"description"`
+
+	// TokenRef is the secret reference Token was resolved from, if any
+	TokenRef string `json:"-"`
+}
+
+// MarshalJSON writes the token's reference, never the resolved token
+func (c GitHubCredential) MarshalJSON() ([]byte, error) {
+	type credential GitHubCredential
+	out := credential(c)
+	out.Token = c.TokenRef
+	return json.Marshal(out)
 }
 
 // ProviderCredentials holds AI provider API credentials
@@ -27,8 +40,19 @@ type ProviderCredentials struct {
 // ProviderCredential is a single provider API key (Anthropic, OpenAI, etc.)
 type ProviderCredential struct {
 	Provider    string `json:"provider"` // anthropic, openai, google
-	APIKey      string `json:"api_key"`
+	APIKey      string `json:"api_key,omitempty"`
 	Description string `json:"description"`
+
+	// APIKeyRef is the secret reference APIKey was resolved from, if any
+	APIKeyRef string `json:"-"`
+}
+
+// MarshalJSON writes the API key's reference, never the resolved key
+func (c ProviderCredential) MarshalJSON() ([]byte, error) {
+	type credential ProviderCredential
+	out := credential(c)
+	out.APIKey = c.APIKeyRef
+	return json.Marshal(out)
 }
 
 // GetGitHubToken returns the GitHub token for a named credential
@@ -80,6 +104,8 @@ type CredentialInfo struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	IsDefault   bool   `json:"is_default,omitempty"`
+	Source      string `json:"source"`           // plaintext, env, file, exec or secret
+	Masked      string `json:"masked,omitempty"` // e.g. ****abcd
 }
 
 // ProviderCredentialInfo includes provider type
@@ -88,6 +114,8 @@ type ProviderCredentialInfo struct {
 	Provider    string `json:"provider"`
 	Description string `json:"description"`
 	IsDefault   bool   `json:"is_default,omitempty"`
+	Source      string `json:"source"`
+	Masked      string `json:"masked,omitempty"`
 }
 
 // CredentialsList is the response for project_options
@@ -108,6 +136,8 @@ func (r *CredentialRegistry) ListCredentials() CredentialsList {
 			Name:        name,
 			Description: cred.Description,
 			IsDefault:   name == r.GitHub.Default,
+			Source:      SecretSource(cred.TokenRef),
+			Masked:      MaskSecret(cred.Token),
 		})
 	}
 
@@ -117,6 +147,8 @@ func (r *CredentialRegistry) ListCredentials() CredentialsList {
 			Provider:    cred.Provider,
 			Description: cred.Description,
 			IsDefault:   name == r.Providers.Default,
+			Source:      SecretSource(cred.APIKeyRef),
+			Masked:      MaskSecret(cred.APIKey),
 		})
 	}
 
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Kinds of configuration change
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// hiddenValue stands in for secret values in a diff
+const hiddenValue = "(hidden)"
+
+// restartPaths are config paths read only at startup; changing them takes
+// effect after a restart
+var restartPaths = []string{
+	"server",
+	"defaults.backup",
+	"defaults.container.pool",
+	"defaults.container.idle_stop_minutes",
+}
+
+// ConfigChange is one difference between two configurations. Path uses the
+// key names of oubliette.jsonc; Old and New are compact JSON.
+type ConfigChange struct {
+	Path    string `json:"path"`
+	Change  string `json:"change"` // added, removed or changed
+	Old     string `json:"old,omitempty"`
+	New     string `json:"new,omitempty"`
+	Restart bool   `json:"restart_required,omitempty"`
+}
+
+// String renders the change as a single diff line
+func (c ConfigChange) String() string {
+	var line string
+	switch c.Change {
+	case ChangeAdded:
+		line = fmt.Sprintf("+ %s: %s", c.Path, c.New)
+	case ChangeRemoved:
+		line = fmt.Sprintf("- %s: %s", c.Path, c.Old)
+	default:
+		line = fmt.Sprintf("~ %s: %s -> %s", c.Path, c.Old, c.New)
+	}
+	if c.Restart {
+		line += " (restart required)"
+	}
+	return line
+}
+
+// DiffConfig lists what changed from old to new, sorted by path. Secret
+// values never appear: credentials show their references, and a changed
+// plaintext or resolved value is reported as hidden.
+func DiffConfig(old, new *LoadedConfig) []ConfigChange {
+	var changes []ConfigChange
+	diffTree("", configTree(old), configTree(new), &changes)
+	changes = append(changes, diffSecretValues(old, new)...)
+
+	for i := range changes {
+		for _, prefix := range restartPaths {
+			if changes[i].Path == prefix || strings.HasPrefix(changes[i].Path, prefix+".") {
+				changes[i].Restart = true
+			}
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// RetainRestartSettings copies the settings that only take effect at startup
+// from the running config, so a reloaded config describes what is in effect
+// and later reloads keep reporting the pending changes
+func (c *LoadedConfig) RetainRestartSettings(running *LoadedConfig) {
+	c.Server = running.Server
+	c.ConfigDefaults.Backup = running.ConfigDefaults.Backup
+	c.ConfigDefaults.Container.Pool = running.ConfigDefaults.Container.Pool
+	c.ConfigDefaults.Container.IdleStopMinutes = running.ConfigDefaults.Container.IdleStopMinutes
+}
+
+// configTree converts a config to generic JSON values keyed like oubliette.jsonc
+func configTree(c *LoadedConfig) map[string]any {
+	tree := struct {
+		Server      ServerJSONConfig     `json:"server"`
+		Credentials *CredentialRegistry  `json:"credentials"`
+		Defaults    ConfigDefaultsConfig `json:"defaults"`
+		Models      *ModelRegistry       `json:"models"`
+		Containers  map[string]string    `json:"containers"`
+	}{c.Server, c.Credentials, c.ConfigDefaults, c.Models, c.Containers}
+
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return nil
+	}
+	var out map[string]any
+	_ = json.Unmarshal(data, &out)
+	return out
+}
+
+// diffTree compares two JSON values, descending into objects present on both sides
+func diffTree(path string, old, new any, changes *[]ConfigChange) {
+	oldMap, oldIsMap := old.(map[string]any)
+	newMap, newIsMap := new.(map[string]any)
+	if oldIsMap && newIsMap {
+		keys := make(map[string]struct{}, len(oldMap)+len(newMap))
+		for k := range oldMap {
+			keys[k] = struct{}{}
+		}
+		for k := range newMap {
+			keys[k] = struct{}{}
+		}
+		for k := range keys {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			oldVal, inOld := oldMap[k]
+			newVal, inNew := newMap[k]
+			switch {
+			case !inOld:
+				*changes = append(*changes, ConfigChange{Path: child, Change: ChangeAdded, New: compactJSON(newVal)})
+			case !inNew:
+				*changes = append(*changes, ConfigChange{Path: child, Change: ChangeRemoved, Old: compactJSON(oldVal)})
+			default:
+				diffTree(child, oldVal, newVal, changes)
+			}
+		}
+		return
+	}
+	if !reflect.DeepEqual(old, new) {
+		*changes = append(*changes, ConfigChange{Path: path, Change: ChangeChanged, Old: compactJSON(old), New: compactJSON(new)})
+	}
+}
+
+// diffSecretValues reports secrets whose value changed while their reference
+// did not (plaintext edits, or a reference now resolving to something else)
+func diffSecretValues(old, new *LoadedConfig) []ConfigChange {
+	var changes []ConfigChange
+	changed := func(path string) {
+		changes = append(changes, ConfigChange{Path: path, Change: ChangeChanged, Old: hiddenValue, New: hiddenValue})
+	}
+	if old.Credentials != nil && new.Credentials != nil {
+		for name, o := range old.Credentials.GitHub.Credentials {
+			if n, ok := new.Credentials.GitHub.Credentials[name]; ok && o.TokenRef == n.TokenRef && o.Token != n.Token {
+				changed("credentials.github.credentials." + name + ".token")
+			}
+		}
+		for name, o := range old.Credentials.Providers.Credentials {
+			if n, ok := new.Credentials.Providers.Credentials[name]; ok && o.APIKeyRef == n.APIKeyRef && o.APIKey != n.APIKey {
+				changed("credentials.providers.credentials." + name + ".api_key")
+			}
+		}
+	}
+	if old.Models != nil && new.Models != nil {
+		for name, o := range old.Models.Models {
+			if n, ok := new.Models.Models[name]; ok && o.APIKeyRef == n.APIKeyRef && o.APIKey != n.APIKey {
+				changed("models.models." + name + ".apiKey")
+			}
+		}
+	}
+	return changes
+}
+
+func compactJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
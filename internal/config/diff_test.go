@@ -0,0 +1,65 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func diffTestConfig() *LoadedConfig {
+	u := &UnifiedConfig{
+		Server: ServerSection{Address: ":8080"},
+		Credentials: CredentialsSection{
+			GitHub: GitHubCredentials{Credentials: map[string]GitHubCredential{
+				"default": {Token: "ghp_resolved_value_1", TokenRef: "env:GITHUB_TOKEN"},
+			}},
+			Providers: ProviderCredentials{Credentials: map[string]ProviderCredential{
+				"anthropic": {Provider: "anthropic", APIKey: "sk-ant-plaintext-1"},
+			}},
+		},
+		Defaults:   DefaultsSection{Limits: LimitsDefaults{MaxRecursionDepth: 3}},
+		Models:     ModelsSection{Models: map[string]ModelDefinition{"sonnet": {Model: "claude-sonnet-4-5"}}},
+		Containers: map[string]string{"dev": "oubliette-dev:latest"},
+	}
+	return u.ToLoadedConfig("/etc/oubliette")
+}
+
+func TestDiffConfig(t *testing.T) {
+	old := diffTestConfig()
+	if changes := DiffConfig(old, diffTestConfig()); len(changes) != 0 {
+		t.Fatalf("DiffConfig() of equal configs = %v", changes)
+	}
+
+	next := diffTestConfig()
+	next.Server.Address = ":9090"
+	next.Credentials.GitHub.Credentials["default"] = GitHubCredential{Token: "ghp_resolved_value_2", TokenRef: "env:GITHUB_TOKEN"}
+	next.Credentials.Providers.Credentials["anthropic"] = ProviderCredential{Provider: "anthropic", APIKey: "sk-ant-plaintext-2"}
+	next.ConfigDefaults.Limits.MaxRecursionDepth = 5
+	next.Models.Models["haiku"] = ModelDefinition{Model: "claude-haiku-4-5"}
+	delete(next.Containers, "dev")
+
+	var lines []string
+	for _, change := range DiffConfig(old, next) {
+		lines = append(lines, change.String())
+	}
+	want := []string{
+		"- containers.dev: \"oubliette-dev:latest\"",
+		"~ credentials.github.credentials.default.token: (hidden) -> (hidden)",
+		"~ credentials.providers.credentials.anthropic.api_key: (hidden) -> (hidden)",
+		"~ defaults.limits.max_recursion_depth: 3 -> 5",
+		"+ models.models.haiku: {\"baseUrl\":\"\",\"displayName\":\"\",\"maxOutputTokens\":0,\"model\":\"claude-haiku-4-5\",\"provider\":\"\"}",
+		"~ server.address: \":8080\" -> \":9090\" (restart required)",
+	}
+	if got := strings.Join(lines, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("DiffConfig() =\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+	for _, line := range lines {
+		if strings.Contains(line, "ghp_") || strings.Contains(line, "sk-ant") {
+			t.Errorf("diff leaks a secret: %s", line)
+		}
+	}
+
+	next.RetainRestartSettings(old)
+	if next.Server.Address != ":8080" || next.ConfigDefaults.Limits.MaxRecursionDepth != 5 {
+		t.Errorf("RetainRestartSettings() kept address %q, depth %d", next.Server.Address, next.ConfigDefaults.Limits.MaxRecursionDepth)
+	}
+}
@@ -6,7 +6,9 @@ import (
 
 // ServerJSONConfig holds server settings
 type ServerJSONConfig struct {
-	Address string `json:"address"`
+	Address string         `json:"address"`
+	Auth    AuthSection    `json:"auth"`
+	Tracing TracingSection `json:"tracing"`
 }
 
 // ConfigDefaultsConfig holds default settings for projects/sessions
@@ -42,7 +44,11 @@ type MCPServerDefaults struct {
 
 // ContainerDefaults contains default container configuration
 type ContainerDefaults struct {
-	Type string `json:"type"`
+	Type string         `json:"type"`
+	Pool map[string]int `json:"pool,omitempty"` // Container type -> warm containers kept ready
+
+	// IdleStopMinutes stops containers with no session activity for this long (0 disables)
+	IdleStopMinutes int `json:"idle_stop_minutes,omitempty"`
 }
 
 // BackupDefaults contains default backup configuration
@@ -70,6 +76,9 @@ type LoadedConfig struct {
 	Models          *ModelRegistry
 	Containers      map[string]string
 	ConfigDir       string
+
+	// PlaintextSecrets lists credential fields not given as secret references
+	PlaintextSecrets []string
 }
 
 // DefaultConfigDefaults returns default configuration values
@@ -121,5 +130,8 @@ func LoadAll(configDir string) (*LoadedConfig, error) {
 
 // Validate checks that required configuration is present
 func (c *LoadedConfig) Validate() error {
+	if c.Models != nil {
+		return c.Models.Validate()
+	}
 	return nil
 }
@@ -1,14 +1,33 @@
 package config
 
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // ModelDefinition represents a model configuration
 type ModelDefinition struct {
 	Model           string            `json:"model"`
 	DisplayName     string            `json:"displayName"`
 	BaseURL         string            `json:"baseUrl"`
-	APIKey          string            `json:"apiKey"`
+	APIKey          string            `json:"apiKey,omitempty"`
 	MaxOutputTokens int               `json:"maxOutputTokens"`
 	Provider        string            `json:"provider"`
 	ExtraHeaders    map[string]string `json:"extraHeaders,omitempty"`
+	Fallbacks       []string          `json:"fallbacks,omitempty"` // Shorthand names of models to fail over to, in order
+
+	// APIKeyRef is the secret reference APIKey was resolved from, if any
+	APIKeyRef string `json:"-"`
+}
+
+// MarshalJSON writes the API key's reference, never the resolved key
+func (d ModelDefinition) MarshalJSON() ([]byte, error) {
+	type definition ModelDefinition
+	out := definition(d)
+	out.APIKey = d.APIKeyRef
+	return json.Marshal(out)
 }
 
 // ModelRegistry holds model configurations keyed by shorthand name
@@ -23,6 +42,14 @@ type ModelInfo struct {
 	Provider    string `json:"provider"`
 }
 
+// RuntimeModel returns the model in "providerID/modelID" format
+func (d ModelDefinition) RuntimeModel() string {
+	if d.Provider == "" || strings.Contains(d.Model, "/") {
+		return d.Model
+	}
+	return d.Provider + "/" + d.Model
+}
+
 // GetModel returns a model definition by shorthand name
 func (r *ModelRegistry) GetModel(name string) (ModelDefinition, bool) {
 	model, ok := r.Models[name]
@@ -56,3 +83,56 @@ func (r *ModelRegistry) ResolveModel(name string) string {
 	}
 	return name
 }
+
+// FallbackModels returns the models a turn fails over to when the named model
+// keeps returning retryable provider errors, in "providerID/modelID" format.
+// name may be a shorthand or a full model ID.
+func (r *ModelRegistry) FallbackModels(name string) []string {
+	def, ok := r.lookup(name)
+	if !ok {
+		return nil
+	}
+	var models []string
+	for _, fallback := range def.Fallbacks {
+		if fb, ok := r.Models[fallback]; ok {
+			models = append(models, fb.RuntimeModel())
+		}
+	}
+	return models
+}
+
+// lookup finds a model by shorthand name or full model ID
+func (r *ModelRegistry) lookup(name string) (ModelDefinition, bool) {
+	if def, ok := r.Models[name]; ok {
+		return def, true
+	}
+	if name == "" {
+		return ModelDefinition{}, false
+	}
+	for _, def := range r.Models {
+		if def.Model == name || def.RuntimeModel() == name {
+			return def, true
+		}
+	}
+	return ModelDefinition{}, false
+}
+
+// Validate checks that every fallback names another defined model
+func (r *ModelRegistry) Validate() error {
+	names := make([]string, 0, len(r.Models))
+	for name := range r.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, fallback := range r.Models[name].Fallbacks {
+			if fallback == name {
+				return fmt.Errorf("model %q lists itself as a fallback", name)
+			}
+			if _, ok := r.Models[fallback]; !ok {
+				return fmt.Errorf("model %q has unknown fallback %q", name, fallback)
+			}
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -148,3 +149,45 @@ func TestModelRegistry_ResolveModel(t *testing.T) {
 		}
 	})
 }
+
+func TestModelRegistry_FallbackModels(t *testing.T) {
+	registry := &ModelRegistry{
+		Models: map[string]ModelDefinition{
+			"opus":   {Model: "claude-opus-4-6", Provider: "anthropic", Fallbacks: []string{"sonnet", "gpt"}},
+			"sonnet": {Model: "claude-sonnet-4-5", Provider: "anthropic"},
+			"gpt":    {Model: "openai/gpt-5.1"},
+		},
+	}
+
+	want := "anthropic/claude-sonnet-4-5 openai/gpt-5.1"
+	for _, name := range []string{"opus", "claude-opus-4-6", "anthropic/claude-opus-4-6"} {
+		if got := strings.Join(registry.FallbackModels(name), " "); got != want {
+			t.Errorf("FallbackModels(%q) = %q, want %q", name, got, want)
+		}
+	}
+	for _, name := range []string{"sonnet", "unknown", ""} {
+		if got := registry.FallbackModels(name); len(got) != 0 {
+			t.Errorf("FallbackModels(%q) = %v, want none", name, got)
+		}
+	}
+}
+
+func TestModelRegistry_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		models  map[string]ModelDefinition
+		wantErr string
+	}{
+		{"valid", map[string]ModelDefinition{"opus": {Fallbacks: []string{"sonnet"}}, "sonnet": {}}, ""},
+		{"unknown fallback", map[string]ModelDefinition{"opus": {Fallbacks: []string{"haiku"}}}, `model "opus" has unknown fallback "haiku"`},
+		{"self fallback", map[string]ModelDefinition{"opus": {Fallbacks: []string{"opus"}}}, `model "opus" lists itself as a fallback`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&ModelRegistry{Models: tt.models}).Validate()
+			if (err == nil && tt.wantErr != "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,447 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+SECRET REFERENCES
+
+Credential values in oubliette.jsonc may be references instead of plaintext.
+They are resolved once, by LoadUnifiedConfig:
+
+	env:VAR          Environment variable VAR
+	file:/path       Contents of a file (relative paths are under the config dir)
+	exec:cmd args    Stdout of a helper command (run without a shell)
+	secret:NAME      Entry in the encrypted secrets file
+
+The encrypted secrets file is a JSON envelope around an AES-256-GCM sealed
+map of names to values. It is unlocked by a key file holding 32 random bytes
+in hex, which must only be readable by its owner. Keep the key file out of
+anything that gets backed up with the config; `oubliette secrets` manages both.
+
+The reference a value came from is kept next to it (TokenRef, APIKeyRef) and
+is what the credential marshals to: a resolved value is never written out.
+*/
+
+// Secret sources, as reported by SecretSource
+const (
+	SecretSourcePlaintext = "plaintext"
+	SecretSourceEnv       = "env"
+	SecretSourceFile      = "file"
+	SecretSourceExec      = "exec"
+	SecretSourceStore     = "secret"
+)
+
+// Secrets defaults
+const (
+	DefaultSecretsFile        = "secrets.enc"
+	DefaultSecretsKeyFile     = "secrets.key"
+	DefaultSecretsExecTimeout = 10 * time.Second
+)
+
+// SecretsSection locates the encrypted secrets file and configures exec: helpers
+type SecretsSection struct {
+	File               string `json:"file,omitempty"`                 // Default: secrets.enc in the config dir
+	KeyFile            string `json:"key_file,omitempty"`             // Default: secrets.key in the config dir
+	ExecTimeoutSeconds int    `json:"exec_timeout_seconds,omitempty"` // Default: 10
+}
+
+// Paths returns the secrets file and key file, resolving relative paths
+// against the config dir
+func (s SecretsSection) Paths(configDir string) (file, keyFile string) {
+	file, keyFile = s.File, s.KeyFile
+	if file == "" {
+		file = DefaultSecretsFile
+	}
+	if keyFile == "" {
+		keyFile = DefaultSecretsKeyFile
+	}
+	return secretPath(configDir, file), secretPath(configDir, keyFile)
+}
+
+var secretRefPattern = regexp.MustCompile(`^(env|file|exec|secret):`)
+
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,128}$`)
+
+// SecretSource returns the source a credential value is read from: the
+// scheme of a reference, or "plaintext"
+func SecretSource(value string) string {
+	if m := secretRefPattern.FindStringSubmatch(value); m != nil {
+		return m[1]
+	}
+	return SecretSourcePlaintext
+}
+
+// IsSecretRef reports whether a credential value is a reference
+func IsSecretRef(value string) bool {
+	return secretRefPattern.MatchString(value)
+}
+
+// MaskSecret hides a secret, keeping its last four characters when it is long
+// enough that they give nothing away
+func MaskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	if len(value) < 16 {
+		return "****"
+	}
+	return "****" + value[len(value)-4:]
+}
+
+// ValidateSecretName checks the name of an encrypted secrets file entry
+func ValidateSecretName(name string) error {
+	if !secretNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q: use letters, digits, '_', '.' or '-'", name)
+	}
+	return nil
+}
+
+// SecretResolver resolves secret references. The encrypted secrets file is
+// only opened when a secret: reference needs it.
+type SecretResolver struct {
+	configDir string
+	section   SecretsSection
+	store     *SecretStore
+}
+
+// NewSecretResolver creates a resolver for a config in configDir
+func NewSecretResolver(configDir string, section SecretsSection) *SecretResolver {
+	return &SecretResolver{configDir: configDir, section: section}
+}
+
+// Resolve returns the value a reference points to. Plaintext values are
+// returned unchanged. Errors never include the value.
+func (r *SecretResolver) Resolve(value string) (string, error) {
+	source := SecretSource(value)
+	if source == SecretSourcePlaintext {
+		return value, nil
+	}
+	target := strings.TrimSpace(strings.TrimPrefix(value, source+":"))
+	if target == "" {
+		return "", fmt.Errorf("empty %s: reference", source)
+	}
+
+	var resolved string
+	var err error
+	switch source {
+	case SecretSourceEnv:
+		resolved, err = r.resolveEnv(target)
+	case SecretSourceFile:
+		resolved, err = r.resolveFile(target)
+	case SecretSourceExec:
+		resolved, err = r.resolveExec(target)
+	case SecretSourceStore:
+		resolved, err = r.resolveStore(target)
+	}
+	if err != nil {
+		return "", err
+	}
+	if resolved == "" {
+		return "", fmt.Errorf("%s:%s resolved to an empty value", source, target)
+	}
+	return resolved, nil
+}
+
+func (r *SecretResolver) resolveEnv(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+func (r *SecretResolver) resolveFile(path string) (string, error) {
+	data, err := os.ReadFile(secretPath(r.configDir, path))
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func (r *SecretResolver) resolveExec(command string) (string, error) {
+	args := strings.Fields(command)
+	timeout := DefaultSecretsExecTimeout
+	if r.section.ExecTimeoutSeconds > 0 {
+		timeout = time.Duration(r.section.ExecTimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = r.configDir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("secret helper %s timed out after %v", args[0], timeout)
+		}
+		if msg := firstLine(stderr.String()); msg != "" {
+			return "", fmt.Errorf("secret helper %s failed: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("secret helper %s failed: %w", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func (r *SecretResolver) resolveStore(name string) (string, error) {
+	if r.store == nil {
+		file, keyFile := r.section.Paths(r.configDir)
+		store, err := OpenSecretStore(file, keyFile)
+		if err != nil {
+			return "", err
+		}
+		r.store = store
+	}
+	value, ok := r.store.Get(name)
+	if !ok {
+		return "", fmt.Errorf("secret %q not found in %s", name, r.store.Path)
+	}
+	return value, nil
+}
+
+// SecretStore is the encrypted secrets file read by secret: references
+type SecretStore struct {
+	Path    string
+	KeyPath string
+	key     []byte
+	secrets map[string]string
+}
+
+// secretsEnvelope is the on-disk format of the encrypted secrets file
+type secretsEnvelope struct {
+	Version int    `json:"version"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// OpenSecretStore unlocks the secrets file at path with the key at keyPath.
+// A missing secrets file opens as an empty store; a missing key is an error.
+func OpenSecretStore(path, keyPath string) (*SecretStore, error) {
+	key, err := readSecretsKey(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	store := &SecretStore{Path: path, KeyPath: keyPath, key: key, secrets: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	var envelope secretsEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file %s: %w", path, err)
+	}
+	if envelope.Version != 1 {
+		return nil, fmt.Errorf("unsupported secrets file version %d", envelope.Version)
+	}
+	gcm, err := newSecretsCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, envelope.Nonce, envelope.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secrets file %s: wrong key or corrupted file", path)
+	}
+	if err := json.Unmarshal(plain, &store.secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted secrets: %w", err)
+	}
+	return store, nil
+}
+
+// InitSecretStore creates the key file if it does not exist and opens the store
+func InitSecretStore(path, keyPath string) (store *SecretStore, created bool, err error) {
+	if _, statErr := os.Stat(keyPath); errors.Is(statErr, fs.ErrNotExist) {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, false, fmt.Errorf("failed to generate secrets key: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(keyPath), 0o700); err != nil {
+			return nil, false, fmt.Errorf("failed to create key directory: %w", err)
+		}
+		f, err := os.OpenFile(keyPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to create secrets key: %w", err)
+		}
+		_, werr := f.WriteString(hex.EncodeToString(key) + "\n")
+		if cerr := f.Close(); werr == nil {
+			werr = cerr
+		}
+		if werr != nil {
+			return nil, false, fmt.Errorf("failed to write secrets key: %w", werr)
+		}
+		created = true
+	}
+	store, err = OpenSecretStore(path, keyPath)
+	return store, created, err
+}
+
+// Get returns a secret by name
+func (s *SecretStore) Get(name string) (string, bool) {
+	value, ok := s.secrets[name]
+	return value, ok
+}
+
+// Set adds or replaces a secret. Call Save to write it.
+func (s *SecretStore) Set(name, value string) error {
+	if err := ValidateSecretName(name); err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("secret %q has an empty value", name)
+	}
+	s.secrets[name] = value
+	return nil
+}
+
+// Delete removes a secret and reports whether it existed. Call Save to write it.
+func (s *SecretStore) Delete(name string) bool {
+	_, ok := s.secrets[name]
+	delete(s.secrets, name)
+	return ok
+}
+
+// Names returns the names of all secrets, sorted
+func (s *SecretStore) Names() []string {
+	names := make([]string, 0, len(s.secrets))
+	for name := range s.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Save encrypts the secrets with a fresh nonce and atomically replaces the file
+func (s *SecretStore) Save() error {
+	plain, err := json.Marshal(s.secrets)
+	if err != nil {
+		return fmt.Errorf("failed to encode secrets: %w", err)
+	}
+	gcm, err := newSecretsCipher(s.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	data, err := json.MarshalIndent(secretsEnvelope{
+		Version: 1,
+		Nonce:   nonce,
+		Data:    gcm.Seal(nil, nonce, plain, nil),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode secrets file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o700); err != nil {
+		return fmt.Errorf("failed to create secrets directory: %w", err)
+	}
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+	if err := os.Rename(tmp, s.Path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to replace secrets file: %w", err)
+	}
+	return nil
+}
+
+// readSecretsKey reads a hex key file, refusing one that others can read
+func readSecretsKey(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("secrets key %s not found (run 'oubliette secrets init')", path)
+		}
+		return nil, fmt.Errorf("failed to read secrets key: %w", err)
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("secrets key %s is accessible by other users (mode %04o); run chmod 600", path, info.Mode().Perm())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets key: %w", err)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("secrets key %s must hold 32 bytes in hex", path)
+	}
+	return key, nil
+}
+
+func newSecretsCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return gcm, nil
+}
+
+// secretPath expands ~/ and resolves relative paths against the config dir
+func secretPath(configDir, path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(configDir, path)
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	if len(s) > 200 {
+		s = s[:200]
+	}
+	return s
+}
+
+// LoadSecretsSection reads only the secrets section of a config file, without
+// resolving any references, so the secrets file can be managed before the
+// references in the config resolve
+func LoadSecretsSection(configPath string) (SecretsSection, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return SecretsSection{}, fmt.Errorf("reading %s: %w", configPath, err)
+	}
+	var cfg struct {
+		Secrets SecretsSection `json:"secrets"`
+	}
+	if err := json.Unmarshal(StripJSONComments(data), &cfg); err != nil {
+		return SecretsSection{}, fmt.Errorf("parsing %s: %w", configPath, err)
+	}
+	return cfg.Secrets, nil
+}
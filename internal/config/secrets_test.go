@@ -0,0 +1,182 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecretResolver_Resolve(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "gh.token"), []byte("ghp_from_file\n"), 0o600)
+	t.Setenv("TEST_OUBLIETTE_KEY", "sk-from-env")
+
+	resolver := NewSecretResolver(dir, SecretsSection{})
+	tests := []struct {
+		value, want string
+	}{
+		{"sk-plaintext", "sk-plaintext"},
+		{"env:TEST_OUBLIETTE_KEY", "sk-from-env"},
+		{"file:gh.token", "ghp_from_file"},
+		{"file:" + filepath.Join(dir, "gh.token"), "ghp_from_file"},
+		{"exec:echo sk-from-helper", "sk-from-helper"},
+	}
+	for _, tt := range tests {
+		got, err := resolver.Resolve(tt.value)
+		if err != nil {
+			t.Errorf("Resolve(%q) error = %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"env:TEST_OUBLIETTE_UNSET", "file:missing", "exec:false", "env:", "secret:anthropic"} {
+		if _, err := resolver.Resolve(value); err == nil {
+			t.Errorf("Resolve(%q) succeeded, want error", value)
+		}
+	}
+}
+
+func TestSecretStore(t *testing.T) {
+	dir := t.TempDir()
+	path, keyPath := SecretsSection{KeyFile: filepath.Join(dir, "keys", "oubliette.key")}.Paths(dir)
+
+	store, created, err := InitSecretStore(path, keyPath)
+	if err != nil || !created {
+		t.Fatalf("InitSecretStore() = %v, %v", created, err)
+	}
+	if info, _ := os.Stat(keyPath); info.Mode().Perm() != 0o600 {
+		t.Errorf("key mode = %04o, want 0600", info.Mode().Perm())
+	}
+	if err := store.Set("anthropic", "sk-ant-stored"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("../bad", "x"); err == nil {
+		t.Error("Set() accepted an invalid name")
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "sk-ant-stored") {
+		t.Error("secrets file contains the plaintext value")
+	}
+
+	if _, created, err := InitSecretStore(path, keyPath); err != nil || created {
+		t.Errorf("second InitSecretStore() = %v, %v, want existing key kept", created, err)
+	}
+	reopened, err := OpenSecretStore(path, keyPath)
+	if err != nil {
+		t.Fatalf("OpenSecretStore() error = %v", err)
+	}
+	if value, ok := reopened.Get("anthropic"); !ok || value != "sk-ant-stored" {
+		t.Errorf("Get() = %q, %v", value, ok)
+	}
+
+	t.Run("wrong key", func(t *testing.T) {
+		otherKey := filepath.Join(dir, "other.key")
+		if _, _, err := InitSecretStore(filepath.Join(dir, "unused.enc"), otherKey); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := OpenSecretStore(path, otherKey); err == nil {
+			t.Error("OpenSecretStore() with the wrong key succeeded")
+		}
+	})
+
+	t.Run("key readable by others", func(t *testing.T) {
+		_ = os.Chmod(keyPath, 0o644)
+		defer func() { _ = os.Chmod(keyPath, 0o600) }()
+		if _, err := OpenSecretStore(path, keyPath); err == nil || !strings.Contains(err.Error(), "chmod 600") {
+			t.Errorf("OpenSecretStore() error = %v, want permission error", err)
+		}
+	})
+}
+
+func TestLoadUnifiedConfig_SecretRefs(t *testing.T) {
+	dir := t.TempDir()
+	path, keyPath := SecretsSection{}.Paths(dir)
+	store, _, err := InitSecretStore(path, keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Set("anthropic", "sk-ant-0123456789abcdef")
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_OUBLIETTE_GH", "ghp_0123456789abcdefWXYZ")
+
+	configPath := filepath.Join(dir, "oubliette.jsonc")
+	writeConfig := func(providerKey string) {
+		_ = os.WriteFile(configPath, []byte(`{
+			"credentials": {
+				"github": {"credentials": {"default": {"token": "env:TEST_OUBLIETTE_GH"}}, "default": "default"},
+				"providers": {"credentials": {"anthropic": {"provider": "anthropic", "api_key": "`+providerKey+`"}}, "default": "anthropic"}
+			},
+			"models": {"models": {"local": {"model": "llama", "provider": "ollama", "apiKey": "plain-model-key"}}}
+		}`), 0o644)
+	}
+
+	writeConfig("secret:anthropic")
+	cfg, err := LoadUnifiedConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadUnifiedConfig() error = %v", err)
+	}
+	loaded := cfg.ToLoadedConfig(dir)
+
+	if token, _ := loaded.Credentials.GetDefaultGitHubToken(); token != "ghp_0123456789abcdefWXYZ" {
+		t.Errorf("GitHub token = %q, want the env value", token)
+	}
+	cred, _ := loaded.Credentials.GetDefaultProviderCredential()
+	if cred.APIKey != "sk-ant-0123456789abcdef" || cred.APIKeyRef != "secret:anthropic" {
+		t.Errorf("provider credential = %q from %q", cred.APIKey, cred.APIKeyRef)
+	}
+	if got := strings.Join(loaded.PlaintextSecrets, ","); got != "models.models.local.apiKey" {
+		t.Errorf("PlaintextSecrets = %q, want only the model key", got)
+	}
+
+	list := loaded.Credentials.ListCredentials()
+	if got := list.GitHub[0]; got.Source != SecretSourceEnv || got.Masked != "****WXYZ" {
+		t.Errorf("GitHub info = %+v", got)
+	}
+	if got := list.Providers[0]; got.Source != SecretSourceStore || got.Masked != "****cdef" {
+		t.Errorf("provider info = %+v", got)
+	}
+
+	// Marshalling writes references and drops plaintext values
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{"ghp_0123456789abcdefWXYZ", "sk-ant-0123456789abcdef", "plain-model-key"} {
+		if strings.Contains(string(data), leaked) {
+			t.Errorf("marshalled config contains %q", leaked)
+		}
+	}
+	if !strings.Contains(string(data), `"api_key":"secret:anthropic"`) {
+		t.Errorf("marshalled config lost the reference: %s", data)
+	}
+
+	writeConfig("secret:missing")
+	_, err = LoadUnifiedConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "credentials.providers.credentials.anthropic.api_key") {
+		t.Errorf("LoadUnifiedConfig() error = %v, want the failing field named", err)
+	}
+}
+
+func TestMaskSecret(t *testing.T) {
+	tests := map[string]string{
+		"":                          "",
+		"short":                     "****",
+		"sk-ant-api03-abcdefgh1234": "****1234",
+	}
+	for value, want := range tests {
+		if got := MaskSecret(value); got != want {
+			t.Errorf("MaskSecret(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
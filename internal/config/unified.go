@@ -2,9 +2,11 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 )
 
 // UnifiedConfig is the single configuration file format for oubliette.jsonc
@@ -14,11 +16,44 @@ type UnifiedConfig struct {
 	Defaults    DefaultsSection    `json:"defaults"`
 	Models      ModelsSection      `json:"models"`
 	Containers  map[string]string  `json:"containers"` // Container type name -> image name
+	Secrets     SecretsSection     `json:"secrets"`
+
+	// PlaintextSecrets lists the credential fields given in plaintext rather
+	// than as secret references
+	PlaintextSecrets []string `json:"-"`
 }
 
 // ServerSection contains server configuration
 type ServerSection struct {
-	Address string `json:"address"`
+	Address string         `json:"address"`
+	Auth    AuthSection    `json:"auth"`
+	Tracing TracingSection `json:"tracing"`
+}
+
+// TracingSection configures OpenTelemetry trace export over OTLP/HTTP
+type TracingSection struct {
+	Enabled     bool    `json:"enabled"`
+	Endpoint    string  `json:"endpoint,omitempty"`     // Collector host:port (default: localhost:4318)
+	Insecure    bool    `json:"insecure,omitempty"`     // Plain HTTP to the collector
+	ServiceName string  `json:"service_name,omitempty"` // Default: oubliette
+	SampleRatio float64 `json:"sample_ratio,omitempty"` // Default: 1.0
+}
+
+// AuthSection configures authentication methods in addition to API tokens
+type AuthSection struct {
+	OIDC *OIDCSection `json:"oidc,omitempty"`
+}
+
+// OIDCSection configures JWT bearer authentication against an OIDC issuer
+type OIDCSection struct {
+	Issuer              string            `json:"issuer"`
+	Audience            string            `json:"audience"`
+	JWKSURL             string            `json:"jwks_url,omitempty"`     // Default: discovered from issuer
+	ScopeClaim          string            `json:"scope_claim,omitempty"`  // Default: oubliette_scope
+	GroupsClaim         string            `json:"groups_claim,omitempty"` // Default: groups
+	GroupScopes         map[string]string `json:"group_scopes,omitempty"` // Group -> scope entries
+	DefaultScope        string            `json:"default_scope,omitempty"`
+	JWKSCacheTTLSeconds int               `json:"jwks_cache_ttl_seconds,omitempty"`
 }
 
 // CredentialsSection contains all API credentials
@@ -115,9 +150,64 @@ func LoadUnifiedConfig(configPath string) (*UnifiedConfig, error) {
 		cfg.Defaults.Agent.MCPServers = make(map[string]MCPServerDefaults)
 	}
 
+	if err := cfg.resolveSecrets(filepath.Dir(configPath)); err != nil {
+		return nil, fmt.Errorf("resolving secrets in %s: %w", configPath, err)
+	}
+
 	return &cfg, nil
 }
 
+// resolveSecrets replaces secret references in credential fields with the
+// values they point to, keeping each reference in its Ref field. Fields given
+// in plaintext are recorded in PlaintextSecrets.
+func (u *UnifiedConfig) resolveSecrets(configDir string) error {
+	resolver := NewSecretResolver(configDir, u.Secrets)
+	var errs []error
+	resolve := func(field string, value, ref *string) {
+		if *value == "" {
+			return
+		}
+		if !IsSecretRef(*value) {
+			u.PlaintextSecrets = append(u.PlaintextSecrets, field)
+			return
+		}
+		resolved, err := resolver.Resolve(*value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field, err))
+			return
+		}
+		*ref, *value = *value, resolved
+	}
+
+	for _, name := range SortedKeys(u.Credentials.GitHub.Credentials) {
+		cred := u.Credentials.GitHub.Credentials[name]
+		resolve("credentials.github.credentials."+name+".token", &cred.Token, &cred.TokenRef)
+		u.Credentials.GitHub.Credentials[name] = cred
+	}
+	for _, name := range SortedKeys(u.Credentials.Providers.Credentials) {
+		cred := u.Credentials.Providers.Credentials[name]
+		resolve("credentials.providers.credentials."+name+".api_key", &cred.APIKey, &cred.APIKeyRef)
+		u.Credentials.Providers.Credentials[name] = cred
+	}
+	for _, name := range SortedKeys(u.Models.Models) {
+		def := u.Models.Models[name]
+		resolve("models.models."+name+".apiKey", &def.APIKey, &def.APIKeyRef)
+		u.Models.Models[name] = def
+	}
+	return errors.Join(errs...)
+}
+
+// SortedKeys returns a map's keys in order, for stable iteration over named
+// config entries
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func applyUnifiedDefaults(cfg *UnifiedConfig) {
 	if cfg.Server.Address == "" {
 		cfg.Server.Address = ":8080"
@@ -178,6 +268,8 @@ func (u *UnifiedConfig) ToLoadedConfig(configDir string) *LoadedConfig {
 	return &LoadedConfig{
 		Server: ServerJSONConfig{
 			Address: u.Server.Address,
+			Auth:    u.Server.Auth,
+			Tracing: u.Server.Tracing,
 		},
 		Credentials: &CredentialRegistry{
 			GitHub:    u.Credentials.GitHub,
@@ -195,9 +287,10 @@ func (u *UnifiedConfig) ToLoadedConfig(configDir string) *LoadedConfig {
 			MaxCostUSD:          u.Defaults.Limits.MaxCostUSD,
 			ContainerType:       u.Defaults.Container.Type,
 		},
-		Models:     u.GetModelRegistry(),
-		Containers: u.Containers,
-		ConfigDir:  configDir,
+		Models:           u.GetModelRegistry(),
+		Containers:       u.Containers,
+		ConfigDir:        configDir,
+		PlaintextSecrets: u.PlaintextSecrets,
 	}
 }
 
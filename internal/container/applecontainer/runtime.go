@@ -1,11 +1,14 @@
 package applecontainer
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -95,6 +98,10 @@ func (r *Runtime) Create(ctx context.Context, cfg container.CreateConfig) (strin
 		args = append(args, "-w", cfg.WorkingDir)
 	}
 
+	for k, v := range cfg.Labels {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", k, v))
+	}
+
 	for _, m := range cfg.Mounts {
 		mountStr := fmt.Sprintf("%s:%s", m.Source, m.Target)
 		if m.ReadOnly {
@@ -176,6 +183,11 @@ func (r *Runtime) Remove(ctx context.Context, containerID string, force bool) er
 	return nil
 }
 
+// Rename is not supported: Apple Container names are fixed at creation
+func (r *Runtime) Rename(ctx context.Context, containerID, newName string) error {
+	return fmt.Errorf("apple-container runtime does not support renaming containers")
+}
+
 // Exec executes a command in a running container
 func (r *Runtime) Exec(ctx context.Context, containerID string, cfg container.ExecConfig) (*container.ExecResult, error) {
 	args := []string{"exec"}
@@ -203,11 +215,13 @@ func (r *Runtime) Exec(ctx context.Context, containerID string, cfg container.Ex
 	args = append(args, containerID)
 	args = append(args, cfg.Cmd...)
 
+	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, r.binaryPath, args...)
-	output, err := cmd.CombinedOutput()
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
 
 	exitCode := 0
-	if err != nil {
+	if err := cmd.Run(); err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			exitCode = exitErr.ExitCode()
 		} else {
@@ -216,8 +230,8 @@ func (r *Runtime) Exec(ctx context.Context, containerID string, cfg container.Ex
 	}
 
 	return &container.ExecResult{
-		Stdout:   string(output),
-		Stderr:   "",
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
 		ExitCode: exitCode,
 	}, nil
 }
@@ -225,6 +239,9 @@ func (r *Runtime) Exec(ctx context.Context, containerID string, cfg container.Ex
 // ExecInteractive starts an interactive command execution with I/O pipes
 func (r *Runtime) ExecInteractive(ctx context.Context, containerID string, cfg container.ExecConfig) (*container.InteractiveExec, error) {
 	args := []string{"exec", "-i"} // -i for interactive stdin
+	if cfg.TTY {
+		args = append(args, "-t")
+	}
 
 	for _, env := range cfg.Env {
 		args = append(args, "-e", env)
@@ -292,9 +309,13 @@ type appleContainerInspect struct {
 		Network  string `json:"network"`
 	} `json:"networks"`
 	Configuration struct {
-		ID       string `json:"id"`
-		Hostname string `json:"hostname"`
-		Mounts   []struct {
+		ID          string            `json:"id"`
+		Hostname    string            `json:"hostname"`
+		Labels      map[string]string `json:"labels"`
+		InitProcess struct {
+			Environment []string `json:"environment"`
+		} `json:"initProcess"`
+		Mounts []struct {
 			Source      string `json:"source"`
 			Destination string `json:"destination"`
 			ReadOnly    bool   `json:"readonly"`
@@ -352,10 +373,12 @@ func (r *Runtime) Inspect(ctx context.Context, containerID string) (*container.C
 
 	return &container.ContainerInfo{
 		ID:        inspect.Configuration.ID,
-		Name:      inspect.Configuration.Hostname,
+		Name:      inspect.Configuration.ID, // Apple Container uses the name as the ID
 		Status:    status,
 		IPAddress: ipAddress,
 		Mounts:    mounts,
+		Env:       inspect.Configuration.InitProcess.Environment,
+		Labels:    inspect.Configuration.Labels,
 	}, nil
 }
 
@@ -378,6 +401,47 @@ func (r *Runtime) Logs(ctx context.Context, containerID string, opts container.L
 	return string(output), nil
 }
 
+// FollowLogs streams container logs to w until the container stops or ctx is done
+func (r *Runtime) FollowLogs(ctx context.Context, containerID string, opts container.LogsOptions, w io.Writer) error {
+	args := []string{"logs", "--follow"}
+	if opts.Tail != "" {
+		args = append(args, "-n", opts.Tail)
+	}
+	args = append(args, containerID)
+
+	cmd := exec.CommandContext(ctx, r.binaryPath, args...)
+	cmd.Stdout = w
+	cmd.Stderr = w
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to follow container logs: %w", err)
+	}
+	return nil
+}
+
+// ListContainers returns the names of all containers whose name starts with prefix
+func (r *Runtime) ListContainers(ctx context.Context, prefix string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, r.binaryPath, "list", "--all", "--format", "json")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var containers []appleContainerInspect
+	if err := json.Unmarshal(output, &containers); err != nil {
+		return nil, fmt.Errorf("failed to parse list output: %w", err)
+	}
+	var names []string
+	for _, c := range containers {
+		if strings.HasPrefix(c.Configuration.ID, prefix) {
+			names = append(names, c.Configuration.ID)
+		}
+	}
+	return names, nil
+}
+
 // Status returns the container status
 func (r *Runtime) Status(ctx context.Context, containerID string) (container.ContainerStatus, error) {
 	info, err := r.Inspect(ctx, containerID)
@@ -396,7 +460,11 @@ func (r *Runtime) Build(ctx context.Context, cfg container.BuildConfig) error {
 	}
 
 	if cfg.DockerfilePath != "" {
-		args = append(args, "-f", cfg.DockerfilePath)
+		dockerfile := cfg.DockerfilePath
+		if !filepath.IsAbs(dockerfile) {
+			dockerfile = filepath.Join(cfg.ContextPath, dockerfile)
+		}
+		args = append(args, "-f", dockerfile)
 	}
 
 	for k, v := range cfg.BuildArgs {
@@ -408,6 +476,10 @@ func (r *Runtime) Build(ctx context.Context, cfg container.BuildConfig) error {
 	cmd := exec.CommandContext(ctx, r.binaryPath, args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
+	if cfg.Output != nil {
+		cmd.Stdout = cfg.Output
+		cmd.Stderr = cfg.Output
+	}
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to build image: %w", err)
@@ -416,6 +488,18 @@ func (r *Runtime) Build(ctx context.Context, cfg container.BuildConfig) error {
 	return nil
 }
 
+// RemoveImage removes a local image from Apple Container. A missing image is not an error.
+func (r *Runtime) RemoveImage(ctx context.Context, imageName string) error {
+	if exists, err := r.ImageExists(ctx, imageName); err != nil || !exists {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, r.binaryPath, "image", "delete", imageName)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to remove image %s: %w (output: %s)", imageName, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 // ImageExists checks if an image exists in Apple Container
 func (r *Runtime) ImageExists(ctx context.Context, imageName string) (bool, error) {
 	cmd := exec.CommandContext(ctx, r.binaryPath, "image", "inspect", imageName)
@@ -0,0 +1,24 @@
+package applecontainer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/HyphaGroup/oubliette/internal/container/containertest"
+)
+
+// TestConformance runs the runtime conformance suite against Apple Container.
+// It creates real containers, so it only runs when OUBLIETTE_RUNTIME_CONFORMANCE is set.
+func TestConformance(t *testing.T) {
+	if os.Getenv("OUBLIETTE_RUNTIME_CONFORMANCE") == "" {
+		t.Skip("OUBLIETTE_RUNTIME_CONFORMANCE not set")
+	}
+	r, err := NewRuntime()
+	if err != nil {
+		t.Fatalf("NewRuntime() error = %v", err)
+	}
+	containertest.Run(t, r, containertest.Config{
+		Image:    os.Getenv("OUBLIETTE_CONFORMANCE_IMAGE"),
+		NoRename: true,
+	})
+}
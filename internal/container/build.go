@@ -0,0 +1,169 @@
+package container
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// BaseImageBuildArg is the build argument carrying the Oubliette base image.
+// Project Dockerfiles layer on it with:
+//
+//	ARG OUBLIETTE_BASE_IMAGE
+//	FROM ${OUBLIETTE_BASE_IMAGE}
+const BaseImageBuildArg = "OUBLIETTE_BASE_IMAGE"
+
+// ProjectImageBuild describes a project's custom image build
+type ProjectImageBuild struct {
+	ProjectID     string
+	Dockerfile    string    // Absolute path to the Dockerfile
+	ContextDir    string    // Build context; empty builds the Dockerfile alone
+	BaseImage     string    // Passed as OUBLIETTE_BASE_IMAGE
+	PreviousImage string    // Removed after a successful build with a new tag
+	Force         bool      // Rebuild even if the tag already exists
+	Output        io.Writer // Build log destination
+}
+
+// ProjectImage is the result of a project image build
+type ProjectImage struct {
+	Image string // Content-addressed image name
+	Hash  string // Full content hash
+	Built bool   // False when an existing image was reused
+}
+
+// ProjectImageName returns the content-addressed image name for a project build
+func ProjectImageName(projectID, hash string) string {
+	return fmt.Sprintf("oubliette-%s:%s", projectID[:8], hash[:12])
+}
+
+// ContentHash hashes the base image, the Dockerfile and every file in the build
+// context, so the tag changes whenever anything that feeds the build changes.
+func ContentHash(dockerfile, contextDir, baseImage string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "base:%s\n", baseImage)
+
+	data, err := os.ReadFile(dockerfile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Dockerfile: %w", err)
+	}
+	fmt.Fprintf(h, "dockerfile:%d\n", len(data))
+	h.Write(data)
+
+	if contextDir == "" {
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(contextDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if d.Type().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to walk build context: %w", err)
+	}
+	sort.Strings(files)
+
+	for _, path := range files {
+		rel, err := filepath.Rel(contextDir, path)
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		fmt.Fprintf(h, "file:%s:%d\n", filepath.ToSlash(rel), len(data))
+		h.Write(data)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// BuildProjectImage builds a project's image from its Dockerfile, tagged by
+// content hash. An existing image with the same tag is reused unless Force is set.
+func (m *ImageManager) BuildProjectImage(ctx context.Context, b ProjectImageBuild) (*ProjectImage, error) {
+	hash, err := ContentHash(b.Dockerfile, b.ContextDir, b.BaseImage)
+	if err != nil {
+		return nil, err
+	}
+	result := &ProjectImage{Image: ProjectImageName(b.ProjectID, hash), Hash: hash}
+
+	if !b.Force {
+		exists, err := m.runtime.ImageExists(ctx, result.Image)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check image %s: %w", result.Image, err)
+		}
+		if exists {
+			return result, nil
+		}
+	}
+
+	if b.BaseImage != "" {
+		exists, err := m.runtime.ImageExists(ctx, b.BaseImage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check base image %s: %w", b.BaseImage, err)
+		}
+		if !exists {
+			if err := m.runtime.Pull(ctx, b.BaseImage); err != nil {
+				return nil, fmt.Errorf("failed to pull base image %s: %w", b.BaseImage, err)
+			}
+		}
+	}
+
+	contextDir, dockerfile := b.ContextDir, "Dockerfile"
+	if contextDir != "" {
+		rel, err := filepath.Rel(contextDir, b.Dockerfile)
+		if err != nil {
+			return nil, fmt.Errorf("dockerfile is outside the build context: %w", err)
+		}
+		dockerfile = rel
+	} else {
+		// Build the Dockerfile alone rather than sending an unrelated directory
+		tmpDir, err := os.MkdirTemp("", "oubliette-build-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create build context: %w", err)
+		}
+		defer func() { _ = os.RemoveAll(tmpDir) }()
+		data, err := os.ReadFile(b.Dockerfile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Dockerfile: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, "Dockerfile"), data, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to create build context: %w", err)
+		}
+		contextDir = tmpDir
+	}
+
+	err = m.runtime.Build(ctx, BuildConfig{
+		ImageName:      result.Image,
+		DockerfilePath: filepath.ToSlash(dockerfile),
+		ContextPath:    contextDir,
+		BuildArgs:      map[string]string{BaseImageBuildArg: b.BaseImage},
+		Output:         b.Output,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Built = true
+
+	if b.PreviousImage != "" && b.PreviousImage != result.Image {
+		// Best effort: an old tag may still be used by a stopped container
+		_ = m.runtime.RemoveImage(ctx, b.PreviousImage)
+	}
+
+	return result, nil
+}
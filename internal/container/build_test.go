@@ -0,0 +1,146 @@
+package container_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/testutil"
+)
+
+const testProjectID = "550e8400-e29b-41d4-a716-446655440000"
+
+func writeBuildContext(t *testing.T) (dockerfile, contextDir string) {
+	t.Helper()
+	contextDir = t.TempDir()
+	dockerfile = filepath.Join(contextDir, "Dockerfile")
+	if err := os.WriteFile(dockerfile, []byte("ARG OUBLIETTE_BASE_IMAGE\nFROM ${OUBLIETTE_BASE_IMAGE}\nCOPY setup.sh /tmp/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(contextDir, "setup.sh"), []byte("apt-get install -y rustc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dockerfile, contextDir
+}
+
+func TestContentHash(t *testing.T) {
+	dockerfile, contextDir := writeBuildContext(t)
+
+	first, err := container.ContentHash(dockerfile, contextDir, "oubliette-dev:latest")
+	if err != nil {
+		t.Fatalf("ContentHash() error = %v", err)
+	}
+	again, _ := container.ContentHash(dockerfile, contextDir, "oubliette-dev:latest")
+	if first != again {
+		t.Error("hash should be stable for unchanged inputs")
+	}
+
+	otherBase, _ := container.ContentHash(dockerfile, contextDir, "oubliette-base:latest")
+	if otherBase == first {
+		t.Error("hash should change with the base image")
+	}
+
+	_ = os.WriteFile(filepath.Join(contextDir, "setup.sh"), []byte("apt-get install -y golang\n"), 0o644)
+	changed, _ := container.ContentHash(dockerfile, contextDir, "oubliette-dev:latest")
+	if changed == first {
+		t.Error("hash should change when a context file changes")
+	}
+
+	if got := container.ProjectImageName(testProjectID, first); got != "oubliette-550e8400:"+first[:12] {
+		t.Errorf("ProjectImageName() = %q", got)
+	}
+}
+
+func TestBuildProjectImage(t *testing.T) {
+	ctx := context.Background()
+	dockerfile, contextDir := writeBuildContext(t)
+
+	t.Run("builds missing image", func(t *testing.T) {
+		rt := testutil.NewMockRuntime(t)
+		rt.ImageExistsFunc = func(name string) (bool, error) {
+			return !strings.HasPrefix(name, "oubliette-550e8400:"), nil
+		}
+		m := container.NewImageManager(nil, rt)
+
+		img, err := m.BuildProjectImage(ctx, container.ProjectImageBuild{
+			ProjectID:     testProjectID,
+			Dockerfile:    dockerfile,
+			ContextDir:    contextDir,
+			BaseImage:     "oubliette-dev:latest",
+			PreviousImage: "oubliette-550e8400:000000000000",
+		})
+		if err != nil {
+			t.Fatalf("BuildProjectImage() error = %v", err)
+		}
+		if !img.Built {
+			t.Error("expected a build")
+		}
+		if len(rt.BuildCalls) != 1 {
+			t.Fatalf("Build calls = %d, want 1", len(rt.BuildCalls))
+		}
+		cfg := rt.BuildCalls[0]
+		if cfg.ImageName != img.Image || cfg.ContextPath != contextDir || cfg.DockerfilePath != "Dockerfile" {
+			t.Errorf("unexpected build config: %+v", cfg)
+		}
+		if cfg.BuildArgs[container.BaseImageBuildArg] != "oubliette-dev:latest" {
+			t.Errorf("base image build arg = %q", cfg.BuildArgs[container.BaseImageBuildArg])
+		}
+		if len(rt.RemoveImageCalls) != 1 || rt.RemoveImageCalls[0] != "oubliette-550e8400:000000000000" {
+			t.Errorf("RemoveImage calls = %v, want previous image removed", rt.RemoveImageCalls)
+		}
+	})
+
+	t.Run("reuses existing image", func(t *testing.T) {
+		rt := testutil.NewMockRuntime(t)
+		m := container.NewImageManager(nil, rt)
+
+		img, err := m.BuildProjectImage(ctx, container.ProjectImageBuild{
+			ProjectID:  testProjectID,
+			Dockerfile: dockerfile,
+			ContextDir: contextDir,
+		})
+		if err != nil {
+			t.Fatalf("BuildProjectImage() error = %v", err)
+		}
+		if img.Built || len(rt.BuildCalls) != 0 {
+			t.Error("expected the existing image to be reused")
+		}
+	})
+
+	t.Run("force rebuilds", func(t *testing.T) {
+		rt := testutil.NewMockRuntime(t)
+		m := container.NewImageManager(nil, rt)
+
+		img, err := m.BuildProjectImage(ctx, container.ProjectImageBuild{
+			ProjectID:  testProjectID,
+			Dockerfile: dockerfile,
+			ContextDir: contextDir,
+			Force:      true,
+		})
+		if err != nil {
+			t.Fatalf("BuildProjectImage() error = %v", err)
+		}
+		if !img.Built || len(rt.BuildCalls) != 1 {
+			t.Error("expected a forced build")
+		}
+	})
+
+	t.Run("dockerfile without context", func(t *testing.T) {
+		rt := testutil.NewMockRuntime(t)
+		rt.ImageExistsFunc = func(string) (bool, error) { return false, nil }
+		m := container.NewImageManager(nil, rt)
+
+		if _, err := m.BuildProjectImage(ctx, container.ProjectImageBuild{
+			ProjectID:  testProjectID,
+			Dockerfile: dockerfile,
+		}); err != nil {
+			t.Fatalf("BuildProjectImage() error = %v", err)
+		}
+		if cfg := rt.BuildCalls[0]; cfg.ContextPath == contextDir || cfg.DockerfilePath != "Dockerfile" {
+			t.Errorf("expected a temporary context with only the Dockerfile, got %+v", cfg)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package container
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 )
@@ -38,6 +39,15 @@ func NewCachedRuntime(r Runtime, ttl time.Duration) *CachedRuntime {
 	return cr
 }
 
+// ListContainers lists containers if the underlying runtime can
+func (cr *CachedRuntime) ListContainers(ctx context.Context, prefix string) ([]string, error) {
+	lister, ok := cr.Runtime.(Lister)
+	if !ok {
+		return nil, fmt.Errorf("%s runtime cannot list containers", cr.Name())
+	}
+	return lister.ListContainers(ctx, prefix)
+}
+
 // Status returns the cached status or fetches from underlying runtime
 func (cr *CachedRuntime) Status(ctx context.Context, containerID string) (ContainerStatus, error) {
 	// Check cache first
@@ -101,6 +111,13 @@ func (cr *CachedRuntime) Remove(ctx context.Context, containerID string, force b
 	return err
 }
 
+func (cr *CachedRuntime) Rename(ctx context.Context, containerID, newName string) error {
+	err := cr.Runtime.Rename(ctx, containerID, newName)
+	cr.InvalidateStatus(containerID)
+	cr.InvalidateStatus(newName)
+	return err
+}
+
 func (cr *CachedRuntime) Create(ctx context.Context, config CreateConfig) (string, error) {
 	id, err := cr.Runtime.Create(ctx, config)
 	if err == nil {
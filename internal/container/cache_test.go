@@ -3,6 +3,7 @@ package container
 import (
 	"context"
 	"errors"
+	"io"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -51,6 +52,10 @@ func (m *mockRuntimeForCache) Remove(ctx context.Context, containerID string, fo
 	return m.removeError
 }
 
+func (m *mockRuntimeForCache) Rename(ctx context.Context, containerID, newName string) error {
+	return nil
+}
+
 func (m *mockRuntimeForCache) Exec(ctx context.Context, containerID string, config ExecConfig) (*ExecResult, error) {
 	return &ExecResult{}, nil
 }
@@ -67,6 +72,10 @@ func (m *mockRuntimeForCache) Logs(ctx context.Context, containerID string, opts
 	return "", nil
 }
 
+func (m *mockRuntimeForCache) FollowLogs(ctx context.Context, containerID string, opts LogsOptions, w io.Writer) error {
+	return nil
+}
+
 func (m *mockRuntimeForCache) Status(ctx context.Context, containerID string) (ContainerStatus, error) {
 	m.statusCalls.Add(1)
 	if m.statusError != nil {
@@ -87,6 +96,10 @@ func (m *mockRuntimeForCache) Pull(ctx context.Context, imageName string) error
 	return nil
 }
 
+func (m *mockRuntimeForCache) RemoveImage(ctx context.Context, imageName string) error {
+	return nil
+}
+
 func (m *mockRuntimeForCache) Ping(ctx context.Context) error {
 	return nil
 }
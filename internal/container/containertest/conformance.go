@@ -0,0 +1,580 @@
+// Package containertest provides a conformance suite that checks a
+// container.Runtime behaves the way the rest of oubliette expects, so that
+// Docker, Apple Container, Podman and test fakes are interchangeable.
+package containertest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/container"
+)
+
+// DefaultImage is the image test containers run when Config.Image is empty
+const DefaultImage = "docker.io/library/alpine:3.20"
+
+// Config configures a conformance run
+type Config struct {
+	// Image test containers run. It must provide sh, echo, cat, printenv,
+	// touch, sleep, true and false. Defaults to DefaultImage.
+	Image string
+
+	// NoRename expects Rename to fail, for runtimes that cannot rename containers
+	NoRename bool
+
+	// Timeout bounds each wait for an asynchronous state change (default 30s)
+	Timeout time.Duration
+}
+
+// Run checks rt against the behaviour oubliette relies on: status mapping,
+// exec exit codes and output streams, env, labels, mounts, AutoRemove, log
+// tailing and following, interactive exec close semantics and image management. Test
+// containers are named oubliette-conformance-* and removed afterwards.
+func Run(t *testing.T, rt container.Runtime, cfg Config) {
+	t.Helper()
+	if cfg.Image == "" {
+		cfg.Image = DefaultImage
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	s := &suite{rt: rt, cfg: cfg, prefix: "oubliette-conformance-" + randomSuffix()}
+
+	if rt.Name() == "" {
+		t.Error("Name() is empty")
+	}
+	if !rt.IsAvailable() {
+		t.Fatal("IsAvailable() = false")
+	}
+	if err := rt.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if err := rt.Pull(context.Background(), cfg.Image); err != nil {
+		t.Fatalf("Pull(%q) error = %v", cfg.Image, err)
+	}
+
+	t.Run("Lifecycle", s.testLifecycle)
+	t.Run("DuplicateName", s.testDuplicateName)
+	t.Run("UnknownContainer", s.testUnknownContainer)
+	t.Run("Rename", s.testRename)
+	t.Run("ExecExitCode", s.testExecExitCode)
+	t.Run("ExecOutput", s.testExecOutput)
+	t.Run("Env", s.testEnv)
+	t.Run("Labels", s.testLabels)
+	t.Run("Mounts", s.testMounts)
+	t.Run("AutoRemove", s.testAutoRemove)
+	t.Run("LogsTail", s.testLogsTail)
+	t.Run("FollowLogs", s.testFollowLogs)
+	t.Run("ExecInteractive", s.testExecInteractive)
+	t.Run("Images", s.testImages)
+	t.Run("Build", s.testBuild)
+}
+
+type suite struct {
+	rt     container.Runtime
+	cfg    Config
+	prefix string
+}
+
+func randomSuffix() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// sleeper keeps a test container running until it is stopped
+var sleeper = []string{"sleep", "300"}
+
+// create creates a container that is force-removed when the test ends
+func (s *suite) create(t *testing.T, suffix string, cfg container.CreateConfig) (string, string) {
+	t.Helper()
+	name := s.prefix + "-" + suffix
+	cfg.Name = name
+	cfg.Image = s.cfg.Image
+	// Forward SIGTERM so Stop doesn't wait out the kill timeout
+	cfg.Init = true
+	if cfg.Cmd == nil {
+		cfg.Cmd = sleeper
+	}
+
+	id, err := s.rt.Create(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Create(%s) error = %v", name, err)
+	}
+	if id == "" {
+		t.Fatalf("Create(%s) returned an empty ID", name)
+	}
+	t.Cleanup(func() { _ = s.rt.Remove(context.Background(), id, true) })
+	return id, name
+}
+
+// running creates and starts a container
+func (s *suite) running(t *testing.T, suffix string, cfg container.CreateConfig) string {
+	t.Helper()
+	id, _ := s.create(t, suffix, cfg)
+	if err := s.rt.Start(context.Background(), id); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	return id
+}
+
+// exec runs a command that must not fail to execute
+func (s *suite) exec(t *testing.T, id string, cfg container.ExecConfig) *container.ExecResult {
+	t.Helper()
+	cfg.AttachStdout = true
+	cfg.AttachStderr = true
+	result, err := s.rt.Exec(context.Background(), id, cfg)
+	if err != nil {
+		t.Fatalf("Exec(%v) error = %v", cfg.Cmd, err)
+	}
+	return result
+}
+
+// eventually polls cond until it holds or the timeout passes
+func (s *suite) eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(s.cfg.Timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out after %s waiting for %s", s.cfg.Timeout, what)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (s *suite) expectStatus(t *testing.T, ref string, want container.ContainerStatus) {
+	t.Helper()
+	got, err := s.rt.Status(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("Status(%s) error = %v", ref, err)
+	}
+	if got != want {
+		t.Fatalf("Status(%s) = %q, want %q", ref, got, want)
+	}
+}
+
+func (s *suite) testLifecycle(t *testing.T) {
+	ctx := context.Background()
+	id, name := s.create(t, "lifecycle", container.CreateConfig{})
+
+	s.expectStatus(t, id, container.StatusCreated)
+	info, err := s.rt.Inspect(ctx, name)
+	if err != nil {
+		t.Fatalf("Inspect(name) error = %v", err)
+	}
+	if info.Name != name {
+		t.Errorf("Inspect().Name = %q, want %q", info.Name, name)
+	}
+	if info.ID == "" {
+		t.Error("Inspect().ID is empty")
+	}
+
+	if err := s.rt.Start(ctx, id); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	s.expectStatus(t, id, container.StatusRunning)
+	s.expectStatus(t, name, container.StatusRunning)
+
+	if err := s.rt.Stop(ctx, id); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	s.expectStatus(t, id, container.StatusExited)
+
+	// A stopped container can be started again with its state intact
+	if err := s.rt.Start(ctx, name); err != nil {
+		t.Fatalf("Start() after Stop error = %v", err)
+	}
+	s.expectStatus(t, id, container.StatusRunning)
+
+	// Force removes a running container
+	if err := s.rt.Remove(ctx, id, true); err != nil {
+		t.Fatalf("Remove(force) error = %v", err)
+	}
+	if _, err := s.rt.Inspect(ctx, id); err == nil {
+		t.Error("Inspect() after Remove succeeded, want error")
+	}
+	if _, err := s.rt.Status(ctx, name); err == nil {
+		t.Error("Status() after Remove succeeded, want error")
+	}
+}
+
+func (s *suite) testDuplicateName(t *testing.T) {
+	_, name := s.create(t, "duplicate", container.CreateConfig{})
+	id, err := s.rt.Create(context.Background(), container.CreateConfig{Name: name, Image: s.cfg.Image, Cmd: sleeper})
+	if err == nil {
+		_ = s.rt.Remove(context.Background(), id, true)
+		t.Fatalf("Create() with a taken name succeeded, want error")
+	}
+}
+
+func (s *suite) testUnknownContainer(t *testing.T) {
+	ctx := context.Background()
+	missing := s.prefix + "-missing"
+
+	if _, err := s.rt.Inspect(ctx, missing); err == nil {
+		t.Error("Inspect() of a missing container succeeded, want error")
+	}
+	if _, err := s.rt.Status(ctx, missing); err == nil {
+		t.Error("Status() of a missing container succeeded, want error")
+	}
+	if err := s.rt.Start(ctx, missing); err == nil {
+		t.Error("Start() of a missing container succeeded, want error")
+	}
+	if err := s.rt.Remove(ctx, missing, true); err == nil {
+		t.Error("Remove() of a missing container succeeded, want error")
+	}
+}
+
+func (s *suite) testRename(t *testing.T) {
+	ctx := context.Background()
+	id, name := s.create(t, "rename", container.CreateConfig{})
+	newName := name + "-renamed"
+
+	err := s.rt.Rename(ctx, id, newName)
+	if s.cfg.NoRename {
+		if err == nil {
+			t.Fatal("Rename() succeeded on a runtime configured with NoRename")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+
+	info, err := s.rt.Inspect(ctx, newName)
+	if err != nil {
+		t.Fatalf("Inspect(new name) error = %v", err)
+	}
+	if info.Name != newName {
+		t.Errorf("Inspect().Name = %q, want %q", info.Name, newName)
+	}
+	if _, err := s.rt.Inspect(ctx, name); err == nil {
+		t.Error("Inspect(old name) succeeded after Rename, want error")
+	}
+}
+
+func (s *suite) testExecExitCode(t *testing.T) {
+	id := s.running(t, "exitcode", container.CreateConfig{})
+
+	if got := s.exec(t, id, container.ExecConfig{Cmd: []string{"true"}}).ExitCode; got != 0 {
+		t.Errorf("true: ExitCode = %d, want 0", got)
+	}
+	if got := s.exec(t, id, container.ExecConfig{Cmd: []string{"false"}}).ExitCode; got != 1 {
+		t.Errorf("false: ExitCode = %d, want 1", got)
+	}
+	// A failing command is reported through ExitCode, not as an error
+	if got := s.exec(t, id, container.ExecConfig{Cmd: []string{"sh", "-c", "exit 3"}}).ExitCode; got != 3 {
+		t.Errorf("exit 3: ExitCode = %d, want 3", got)
+	}
+}
+
+func (s *suite) testExecOutput(t *testing.T) {
+	id := s.running(t, "output", container.CreateConfig{})
+
+	result := s.exec(t, id, container.ExecConfig{Cmd: []string{"echo", "hello", "world"}})
+	if result.Stdout != "hello world\n" || result.Stderr != "" {
+		t.Errorf("echo: Stdout = %q, Stderr = %q; want %q, empty", result.Stdout, result.Stderr, "hello world\n")
+	}
+
+	// Stderr is kept separate from stdout
+	result = s.exec(t, id, container.ExecConfig{Cmd: []string{"cat", "/nonexistent"}})
+	if result.ExitCode == 0 || result.Stdout != "" || result.Stderr == "" {
+		t.Errorf("cat /nonexistent: ExitCode = %d, Stdout = %q, Stderr = %q; want failure on stderr only",
+			result.ExitCode, result.Stdout, result.Stderr)
+	}
+}
+
+func (s *suite) testEnv(t *testing.T) {
+	id := s.running(t, "env", container.CreateConfig{Env: []string{"OUBLIETTE_TEST=container"}})
+
+	if got := s.exec(t, id, container.ExecConfig{Cmd: []string{"printenv", "OUBLIETTE_TEST"}}).Stdout; got != "container\n" {
+		t.Errorf("container env: printenv = %q, want %q", got, "container\n")
+	}
+
+	// Exec env overrides the container's
+	result := s.exec(t, id, container.ExecConfig{
+		Cmd: []string{"printenv", "OUBLIETTE_TEST"},
+		Env: []string{"OUBLIETTE_TEST=exec"},
+	})
+	if result.Stdout != "exec\n" {
+		t.Errorf("exec env: printenv = %q, want %q", result.Stdout, "exec\n")
+	}
+
+	info, err := s.rt.Inspect(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if !slices.Contains(info.Env, "OUBLIETTE_TEST=container") {
+		t.Errorf("Inspect().Env = %v, want it to contain OUBLIETTE_TEST=container", info.Env)
+	}
+}
+
+func (s *suite) testLabels(t *testing.T) {
+	id, _ := s.create(t, "labels", container.CreateConfig{
+		Labels: map[string]string{"oubliette.test": "conformance"},
+	})
+
+	info, err := s.rt.Inspect(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if got := info.Labels["oubliette.test"]; got != "conformance" {
+		t.Errorf("Inspect().Labels[oubliette.test] = %q, want %q", got, "conformance")
+	}
+}
+
+func (s *suite) testMounts(t *testing.T) {
+	rwDir, roDir := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(rwDir, "hello.txt"), []byte("from host\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	id := s.running(t, "mounts", container.CreateConfig{
+		Mounts: []container.Mount{
+			{Type: container.MountTypeBind, Source: rwDir, Target: "/mnt/rw"},
+			{Type: container.MountTypeBind, Source: roDir, Target: "/mnt/ro", ReadOnly: true},
+		},
+	})
+
+	if got := s.exec(t, id, container.ExecConfig{Cmd: []string{"cat", "/mnt/rw/hello.txt"}}).Stdout; got != "from host\n" {
+		t.Errorf("cat mounted file = %q, want %q", got, "from host\n")
+	}
+
+	if result := s.exec(t, id, container.ExecConfig{Cmd: []string{"touch", "/mnt/rw/created"}}); result.ExitCode != 0 {
+		t.Errorf("touch on read-write mount: ExitCode = %d, Stderr = %q", result.ExitCode, result.Stderr)
+	}
+	if _, err := os.Stat(filepath.Join(rwDir, "created")); err != nil {
+		t.Errorf("file created in container not visible on host: %v", err)
+	}
+
+	if result := s.exec(t, id, container.ExecConfig{Cmd: []string{"touch", "/mnt/ro/created"}}); result.ExitCode == 0 {
+		t.Error("touch on read-only mount succeeded, want failure")
+	}
+	if _, err := os.Stat(filepath.Join(roDir, "created")); err == nil {
+		t.Error("file created through a read-only mount")
+	}
+
+	info, err := s.rt.Inspect(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	readOnly := map[string]bool{}
+	for _, m := range info.Mounts {
+		readOnly[m.Target] = m.ReadOnly
+	}
+	if ro, ok := readOnly["/mnt/rw"]; !ok || ro {
+		t.Errorf("Inspect().Mounts = %+v, want /mnt/rw read-write", info.Mounts)
+	}
+	if ro, ok := readOnly["/mnt/ro"]; !ok || !ro {
+		t.Errorf("Inspect().Mounts = %+v, want /mnt/ro read-only", info.Mounts)
+	}
+}
+
+func (s *suite) testAutoRemove(t *testing.T) {
+	ctx := context.Background()
+	id, _ := s.create(t, "autoremove", container.CreateConfig{Cmd: []string{"true"}, AutoRemove: true})
+	if err := s.rt.Start(ctx, id); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	s.eventually(t, "auto-removed container to disappear", func() bool {
+		_, err := s.rt.Inspect(ctx, id)
+		return err != nil
+	})
+}
+
+func (s *suite) testLogsTail(t *testing.T) {
+	ctx := context.Background()
+	id := s.running(t, "logs", container.CreateConfig{
+		Cmd: []string{"sh", "-c", "echo line1; echo line2; echo line3; exec sleep 300"},
+	})
+
+	s.eventually(t, "container output in logs", func() bool {
+		logs, err := s.rt.Logs(ctx, id, container.LogsOptions{})
+		return err == nil && strings.Contains(logs, "line3")
+	})
+
+	logs, err := s.rt.Logs(ctx, id, container.LogsOptions{Tail: "2"})
+	if err != nil {
+		t.Fatalf("Logs(tail 2) error = %v", err)
+	}
+	if logs != "line2\nline3\n" {
+		t.Errorf("Logs(tail 2) = %q, want %q", logs, "line2\nline3\n")
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for a writer and a polling reader
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (s *suite) testFollowLogs(t *testing.T) {
+	ctx := context.Background()
+
+	// Following a running container streams new output until cancelled
+	id := s.running(t, "follow", container.CreateConfig{
+		Cmd: []string{"sh", "-c", "echo line1; echo line2; sleep 2; echo line3; exec sleep 300"},
+	})
+	s.eventually(t, "first output in logs", func() bool {
+		logs, err := s.rt.Logs(ctx, id, container.LogsOptions{})
+		return err == nil && strings.Contains(logs, "line2")
+	})
+
+	followCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var out syncBuffer
+	errc := make(chan error, 1)
+	go func() { errc <- s.rt.FollowLogs(followCtx, id, container.LogsOptions{Tail: "1"}, &out) }()
+
+	s.eventually(t, "followed output", func() bool { return strings.Contains(out.String(), "line3") })
+	cancel()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("FollowLogs() after cancel = %v, want context.Canceled", err)
+		}
+	case <-time.After(s.cfg.Timeout):
+		t.Fatal("FollowLogs() did not return after cancel")
+	}
+	if got := out.String(); got != "line2\nline3\n" {
+		t.Errorf("FollowLogs(tail 1) = %q, want %q", got, "line2\nline3\n")
+	}
+
+	// Following ends without error when the container exits
+	id = s.running(t, "follow-exit", container.CreateConfig{
+		Cmd: []string{"sh", "-c", "echo first; sleep 1; echo last"},
+	})
+	var exited syncBuffer
+	if err := s.rt.FollowLogs(ctx, id, container.LogsOptions{}, &exited); err != nil {
+		t.Errorf("FollowLogs() of exiting container = %v, want nil", err)
+	}
+	if got := exited.String(); got != "first\nlast\n" {
+		t.Errorf("FollowLogs() of exiting container = %q, want %q", got, "first\nlast\n")
+	}
+}
+
+func (s *suite) testExecInteractive(t *testing.T) {
+	ctx := context.Background()
+	id := s.running(t, "interactive", container.CreateConfig{})
+
+	ie, err := s.rt.ExecInteractive(ctx, id, container.ExecConfig{Cmd: []string{"sh", "-c", "cat; echo done"}})
+	if err != nil {
+		t.Fatalf("ExecInteractive() error = %v", err)
+	}
+	defer func() { _ = ie.Close() }()
+	go func() { _, _ = io.Copy(io.Discard, ie.Stderr) }()
+
+	if _, err := io.WriteString(ie.Stdin, "hello\n"); err != nil {
+		t.Fatalf("write stdin: %v", err)
+	}
+	// Closing stdin delivers EOF; output written afterwards is still readable
+	if err := ie.Stdin.Close(); err != nil {
+		t.Fatalf("close stdin: %v", err)
+	}
+	var out bytes.Buffer
+	if _, err := io.Copy(&out, ie.Stdout); err != nil {
+		t.Fatalf("read stdout: %v", err)
+	}
+	if out.String() != "hello\ndone\n" {
+		t.Errorf("stdout = %q, want %q", out.String(), "hello\ndone\n")
+	}
+
+	code, err := ie.Wait()
+	if err != nil || code != 0 {
+		t.Errorf("Wait() = %d, %v; want 0, nil", code, err)
+	}
+	select {
+	case <-ie.Done():
+	default:
+		t.Error("Done() not closed after Wait()")
+	}
+
+	exited, err := s.rt.ExecInteractive(ctx, id, container.ExecConfig{Cmd: []string{"sh", "-c", "exit 4"}})
+	if err != nil {
+		t.Fatalf("ExecInteractive() error = %v", err)
+	}
+	defer func() { _ = exited.Close() }()
+	go func() { _, _ = io.Copy(io.Discard, exited.Stderr) }()
+	_ = exited.Stdin.Close()
+	_, _ = io.Copy(io.Discard, exited.Stdout)
+	if code, err := exited.Wait(); err != nil || code != 4 {
+		t.Errorf("Wait() = %d, %v; want 4, nil", code, err)
+	}
+}
+
+func (s *suite) testImages(t *testing.T) {
+	ctx := context.Background()
+
+	exists, err := s.rt.ImageExists(ctx, s.cfg.Image)
+	if err != nil || !exists {
+		t.Errorf("ImageExists(pulled image) = %v, %v; want true, nil", exists, err)
+	}
+
+	missing := s.prefix + "-missing:latest"
+	exists, err = s.rt.ImageExists(ctx, missing)
+	if err != nil || exists {
+		t.Errorf("ImageExists(missing image) = %v, %v; want false, nil", exists, err)
+	}
+	if err := s.rt.RemoveImage(ctx, missing); err != nil {
+		t.Errorf("RemoveImage(missing image) error = %v, want nil", err)
+	}
+}
+
+func (s *suite) testBuild(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dockerfile := "FROM " + s.cfg.Image + "\nCOPY marker.txt /marker.txt\n"
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte(dockerfile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "marker.txt"), []byte("built\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	imageName := s.prefix + "-build:latest"
+	var output bytes.Buffer
+	err := s.rt.Build(ctx, container.BuildConfig{
+		ImageName:      imageName,
+		DockerfilePath: "Dockerfile",
+		ContextPath:    dir,
+		Output:         &output,
+	})
+	if err != nil {
+		t.Fatalf("Build() error = %v\n%s", err, output.String())
+	}
+	t.Cleanup(func() { _ = s.rt.RemoveImage(context.Background(), imageName) })
+
+	if exists, err := s.rt.ImageExists(ctx, imageName); err != nil || !exists {
+		t.Fatalf("ImageExists(built image) = %v, %v; want true, nil", exists, err)
+	}
+	if err := s.rt.RemoveImage(ctx, imageName); err != nil {
+		t.Fatalf("RemoveImage() error = %v", err)
+	}
+	if exists, _ := s.rt.ImageExists(ctx, imageName); exists {
+		t.Error("ImageExists() after RemoveImage = true, want false")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/HyphaGroup/oubliette/internal/container"
@@ -135,6 +136,11 @@ func (r *Runtime) Remove(ctx context.Context, containerID string, force bool) er
 	return r.client.ContainerRemove(ctx, containerID, dockercontainer.RemoveOptions{Force: force})
 }
 
+// Rename changes a container's name
+func (r *Runtime) Rename(ctx context.Context, containerID, newName string) error {
+	return r.client.ContainerRename(ctx, containerID, newName)
+}
+
 // Exec executes a command in a running container
 func (r *Runtime) Exec(ctx context.Context, containerID string, cfg container.ExecConfig) (*container.ExecResult, error) {
 	execConfig := dockercontainer.ExecOptions{
@@ -185,7 +191,7 @@ func (r *Runtime) ExecInteractive(ctx context.Context, containerID string, cfg c
 		AttachStdout: true,
 		AttachStderr: true,
 		AttachStdin:  true,
-		Tty:          false,
+		Tty:          cfg.TTY,
 		User:         cfg.User,
 	}
 
@@ -194,7 +200,7 @@ func (r *Runtime) ExecInteractive(ctx context.Context, containerID string, cfg c
 		return nil, fmt.Errorf("failed to create exec: %w", err)
 	}
 
-	attachResp, err := r.client.ContainerExecAttach(ctx, execResp.ID, dockercontainer.ExecStartOptions{})
+	attachResp, err := r.client.ContainerExecAttach(ctx, execResp.ID, dockercontainer.ExecStartOptions{Tty: cfg.TTY})
 	if err != nil {
 		return nil, fmt.Errorf("failed to attach to exec: %w", err)
 	}
@@ -203,10 +209,16 @@ func (r *Runtime) ExecInteractive(ctx context.Context, containerID string, cfg c
 	stdoutReader, stdoutWriter := io.Pipe()
 	stderrReader, stderrWriter := io.Pipe()
 
-	// Demux stdout/stderr in background
+	// Demux stdout/stderr in background; the connection is closed once the exec's output ends.
+	// A TTY exec's output is a single raw stream.
 	go func() {
+		defer attachResp.Close()
 		defer func() { _ = stdoutWriter.Close() }()
 		defer func() { _ = stderrWriter.Close() }()
+		if cfg.TTY {
+			_, _ = io.Copy(stdoutWriter, attachResp.Reader)
+			return
+		}
 		_, _ = stdcopy.StdCopy(stdoutWriter, stderrWriter, attachResp.Reader)
 	}()
 
@@ -232,7 +244,13 @@ func (r *Runtime) ExecInteractive(ctx context.Context, containerID string, cfg c
 	// Wrap the hijacked connection's writer as stdin
 	stdin := &hijackedWriteCloser{conn: attachResp}
 
-	return container.NewInteractiveExec(stdin, stdoutReader, stderrReader, wait), nil
+	ie := container.NewInteractiveExec(stdin, stdoutReader, stderrReader, wait)
+	if cfg.TTY {
+		ie.SetResize(func(height, width uint) error {
+			return r.client.ContainerExecResize(context.Background(), execID, dockercontainer.ResizeOptions{Height: height, Width: width})
+		})
+	}
+	return ie, nil
 }
 
 // hijackedWriteCloser wraps a HijackedResponse to implement io.WriteCloser
@@ -244,9 +262,10 @@ func (h *hijackedWriteCloser) Write(p []byte) (n int, err error) {
 	return h.conn.Conn.Write(p)
 }
 
+// Close half-closes the connection: the process sees EOF on stdin while its
+// remaining output can still be read
 func (h *hijackedWriteCloser) Close() error {
-	h.conn.Close()
-	return nil
+	return h.conn.CloseWrite()
 }
 
 // Inspect returns container information
@@ -267,7 +286,9 @@ func (r *Runtime) Inspect(ctx context.Context, containerID string) (*container.C
 	}
 
 	status := container.StatusUnknown
+	var startedAt time.Time
 	if inspect.State != nil {
+		startedAt, _ = time.Parse(time.RFC3339Nano, inspect.State.StartedAt)
 		switch inspect.State.Status {
 		case "created":
 			status = container.StatusCreated
@@ -291,13 +312,15 @@ func (r *Runtime) Inspect(ctx context.Context, containerID string) (*container.C
 
 	return &container.ContainerInfo{
 		ID:        inspect.ID,
-		Name:      inspect.Name,
-		Image:     inspect.Image,
+		Name:      strings.TrimPrefix(inspect.Name, "/"),
+		Image:     inspect.Config.Image,
 		Status:    status,
 		IPAddress: ipAddress,
 		Mounts:    mounts,
 		Env:       inspect.Config.Env,
+		Labels:    inspect.Config.Labels,
 		CreatedAt: createdAt,
+		StartedAt: startedAt,
 	}, nil
 }
 
@@ -328,6 +351,47 @@ func (r *Runtime) Logs(ctx context.Context, containerID string, opts container.L
 	return buf.String(), nil
 }
 
+// FollowLogs streams container logs to w until the container stops or ctx is done
+func (r *Runtime) FollowLogs(ctx context.Context, containerID string, opts container.LogsOptions, w io.Writer) error {
+	logs, err := r.client.ContainerLogs(ctx, containerID, dockercontainer.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Timestamps: opts.Timestamps,
+		Tail:       opts.Tail,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to follow container logs: %w", err)
+	}
+	defer func() { _ = logs.Close() }()
+
+	if _, err := stdcopy.StdCopy(w, w, logs); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to read logs: %w", err)
+	}
+	return ctx.Err()
+}
+
+// ListContainers returns the names of all containers whose name starts with prefix
+func (r *Runtime) ListContainers(ctx context.Context, prefix string) ([]string, error) {
+	containers, err := r.client.ContainerList(ctx, dockercontainer.ListOptions{All: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	var names []string
+	for _, c := range containers {
+		for _, name := range c.Names {
+			if name = strings.TrimPrefix(name, "/"); strings.HasPrefix(name, prefix) {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names, nil
+}
+
 // Status returns the container status
 func (r *Runtime) Status(ctx context.Context, containerID string) (container.ContainerStatus, error) {
 	inspect, err := r.client.ContainerInspect(ctx, containerID)
@@ -439,13 +503,28 @@ func (r *Runtime) Build(ctx context.Context, cfg container.BuildConfig) error {
 		}
 
 		if msg.Stream != "" {
-			fmt.Print(msg.Stream)
+			if cfg.Output != nil {
+				_, _ = io.WriteString(cfg.Output, msg.Stream)
+			} else {
+				fmt.Print(msg.Stream)
+			}
 		}
 	}
 
 	return nil
 }
 
+// RemoveImage removes a local Docker image
+func (r *Runtime) RemoveImage(ctx context.Context, imageName string) error {
+	if _, err := r.client.ImageRemove(ctx, imageName, image.RemoveOptions{PruneChildren: true}); err != nil {
+		if client.IsErrNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to remove image %s: %w", imageName, err)
+	}
+	return nil
+}
+
 // ImageExists checks if a Docker image exists locally
 func (r *Runtime) ImageExists(ctx context.Context, imageName string) (bool, error) {
 	_, err := r.client.ImageInspect(ctx, imageName)
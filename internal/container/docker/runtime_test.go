@@ -1,7 +1,10 @@
 package docker
 
 import (
+	"os"
 	"testing"
+
+	"github.com/HyphaGroup/oubliette/internal/container/containertest"
 )
 
 func TestParseMemoryString(t *testing.T) {
@@ -60,3 +63,18 @@ func TestBuildResourceConstraints(t *testing.T) {
 		})
 	}
 }
+
+// TestConformance runs the runtime conformance suite against a local Docker
+// daemon. It creates real containers, so it only runs when
+// OUBLIETTE_RUNTIME_CONFORMANCE is set.
+func TestConformance(t *testing.T) {
+	if os.Getenv("OUBLIETTE_RUNTIME_CONFORMANCE") == "" {
+		t.Skip("OUBLIETTE_RUNTIME_CONFORMANCE not set")
+	}
+	r, err := NewRuntime()
+	if err != nil {
+		t.Fatalf("NewRuntime() error = %v", err)
+	}
+	defer func() { _ = r.Close() }()
+	containertest.Run(t, r, containertest.Config{Image: os.Getenv("OUBLIETTE_CONFORMANCE_IMAGE")})
+}
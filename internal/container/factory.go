@@ -4,7 +4,8 @@ import (
 	"os"
 )
 
-// GetRuntimePreference returns the configured runtime preference from environment
+// GetRuntimePreference returns the configured runtime preference from environment:
+// "docker", "apple-container", "podman" or "auto" (the default)
 func GetRuntimePreference() string {
 	pref := os.Getenv("CONTAINER_RUNTIME")
 	if pref == "" {
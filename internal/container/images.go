@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"sync"
 )
 
 // ImageManager handles container image operations using config-defined container types
 type ImageManager struct {
+	mu         sync.RWMutex
 	containers map[string]string // type name -> image name
 	runtime    Runtime
 }
@@ -21,8 +23,17 @@ func NewImageManager(containers map[string]string, runtime Runtime) *ImageManage
 	}
 }
 
+// SetContainers replaces the container type mappings, e.g. on config reload
+func (m *ImageManager) SetContainers(containers map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.containers = containers
+}
+
 // GetImageName returns the image name for a container type
 func (m *ImageManager) GetImageName(typeName string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	imageName, ok := m.containers[typeName]
 	if !ok {
 		return "", fmt.Errorf("unknown container type: %s", typeName)
@@ -32,6 +43,8 @@ func (m *ImageManager) GetImageName(typeName string) (string, error) {
 
 // ValidTypes returns all valid container type names sorted alphabetically
 func (m *ImageManager) ValidTypes() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	types := make([]string, 0, len(m.containers))
 	for typeName := range m.containers {
 		types = append(types, typeName)
@@ -42,6 +55,8 @@ func (m *ImageManager) ValidTypes() []string {
 
 // IsValidType checks if a container type is valid
 func (m *ImageManager) IsValidType(typeName string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	_, ok := m.containers[typeName]
 	return ok
 }
@@ -70,7 +85,7 @@ func (m *ImageManager) EnsureImageExists(ctx context.Context, typeName string) e
 	}
 
 	// Pull the image
-	fmt.Printf("📦 Pulling image %s...\n", imageName)
+	fmt.Fprintf(os.Stderr, "📦 Pulling image %s...\n", imageName)
 	if err := m.runtime.Pull(ctx, imageName); err != nil {
 		return fmt.Errorf("failed to pull image %s: %w", imageName, err)
 	}
@@ -80,7 +95,7 @@ func (m *ImageManager) EnsureImageExists(ctx context.Context, typeName string) e
 
 // EnsureAllImages ensures all configured container images exist
 func (m *ImageManager) EnsureAllImages(ctx context.Context) error {
-	for typeName := range m.containers {
+	for _, typeName := range m.ValidTypes() {
 		if err := m.EnsureImageExists(ctx, typeName); err != nil {
 			return err
 		}
@@ -0,0 +1,555 @@
+package podman
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/container"
+)
+
+// Runtime implements container.Runtime using the Podman CLI. It is intended
+// for rootless hosts where a root Docker daemon is not allowed.
+type Runtime struct {
+	binaryPath string
+	userns     string // --userns mode for created containers ("" for podman's default)
+}
+
+// NewRuntime creates a new Podman runtime. PODMAN_BINARY overrides the podman
+// binary and PODMAN_USERNS the user namespace mode; rootless runs default to
+// keep-id so bind-mounted project files and sockets stay owned by the host user.
+func NewRuntime() (*Runtime, error) {
+	binaryPath := os.Getenv("PODMAN_BINARY")
+	if binaryPath == "" {
+		binaryPath = "podman"
+		if path, err := exec.LookPath("podman"); err == nil {
+			binaryPath = path
+		}
+	}
+
+	userns, ok := os.LookupEnv("PODMAN_USERNS")
+	if !ok && os.Geteuid() != 0 {
+		userns = "keep-id"
+	}
+
+	return &Runtime{binaryPath: binaryPath, userns: userns}, nil
+}
+
+// Name returns the runtime name
+func (r *Runtime) Name() string {
+	return "podman"
+}
+
+// IsAvailable checks if podman is installed and can reach its storage
+func (r *Runtime) IsAvailable() bool {
+	if _, err := exec.LookPath(r.binaryPath); err != nil {
+		return false
+	}
+	return exec.Command(r.binaryPath, "info", "--format", "{{.Host.Arch}}").Run() == nil
+}
+
+// Ping verifies podman is usable
+func (r *Runtime) Ping(ctx context.Context) error {
+	if _, err := r.run(ctx, "info", "--format", "{{.Host.Arch}}"); err != nil {
+		return fmt.Errorf("podman not available: %w", err)
+	}
+	return nil
+}
+
+// Close is a no-op for CLI-based runtime
+func (r *Runtime) Close() error {
+	return nil
+}
+
+// run executes a podman command and returns its trimmed stdout. Failures
+// include podman's stderr, which carries the actual reason.
+func (r *Runtime) run(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, r.binaryPath, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// Create creates a new container
+func (r *Runtime) Create(ctx context.Context, cfg container.CreateConfig) (string, error) {
+	// For published sockets, bind mount the socket's parent directory like Docker.
+	// The relay inside the container creates the socket and it appears on the host.
+	for _, ps := range cfg.PublishedSockets {
+		_ = os.MkdirAll(filepath.Dir(ps.HostPath), 0o755)
+	}
+
+	args, err := r.createArgs(cfg)
+	if err != nil {
+		return "", err
+	}
+	id, err := r.run(ctx, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to create container: %w", err)
+	}
+	return id, nil
+}
+
+// createArgs builds the podman create command line for a container config
+func (r *Runtime) createArgs(cfg container.CreateConfig) ([]string, error) {
+	// Bind mounts would otherwise need relabeling on SELinux hosts
+	args := []string{"create", "--security-opt", "label=disable"}
+
+	if cfg.Name != "" {
+		args = append(args, "--name", cfg.Name)
+	}
+	if r.userns != "" {
+		args = append(args, "--userns", r.userns)
+	}
+
+	for _, env := range cfg.Env {
+		args = append(args, "-e", env)
+	}
+	if cfg.WorkingDir != "" {
+		args = append(args, "-w", cfg.WorkingDir)
+	}
+	for k, v := range cfg.Labels {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", k, v))
+	}
+
+	for _, m := range cfg.Mounts {
+		args = append(args, "--mount", mountSpec(m))
+	}
+	for _, ps := range cfg.PublishedSockets {
+		args = append(args, "--mount", mountSpec(container.Mount{
+			Type:   container.MountTypeBind,
+			Source: filepath.Dir(ps.HostPath),
+			Target: filepath.Dir(ps.ContainerPath),
+		}))
+	}
+
+	if cfg.Init {
+		args = append(args, "--init")
+	}
+	if cfg.AutoRemove {
+		args = append(args, "--rm")
+	}
+	if cfg.NetworkMode != "" {
+		args = append(args, "--network", cfg.NetworkMode)
+	}
+
+	// Resource limits
+	if cfg.Memory != "" {
+		args = append(args, "--memory", cfg.Memory)
+	}
+	if cfg.CPUs > 0 {
+		args = append(args, "--cpus", fmt.Sprintf("%d", cfg.CPUs))
+	}
+
+	if len(cfg.Entrypoint) > 0 {
+		// A JSON array keeps multi-word entrypoints intact
+		entrypoint, err := json.Marshal(cfg.Entrypoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode entrypoint: %w", err)
+		}
+		args = append(args, "--entrypoint", string(entrypoint))
+	}
+
+	args = append(args, cfg.Image)
+	args = append(args, cfg.Cmd...)
+	return args, nil
+}
+
+// mountSpec formats a mount for podman's --mount flag
+func mountSpec(m container.Mount) string {
+	mountType := m.Type
+	if mountType == "" {
+		mountType = container.MountTypeBind
+	}
+	spec := fmt.Sprintf("type=%s", mountType)
+	if m.Source != "" && mountType != container.MountTypeTmpfs {
+		spec += fmt.Sprintf(",source=%s", m.Source)
+	}
+	spec += fmt.Sprintf(",target=%s", m.Target)
+	if m.ReadOnly {
+		spec += ",readonly"
+	}
+	return spec
+}
+
+// Start starts a container
+func (r *Runtime) Start(ctx context.Context, containerID string) error {
+	if _, err := r.run(ctx, "start", containerID); err != nil {
+		return fmt.Errorf("failed to start container: %w", err)
+	}
+	return nil
+}
+
+// Stop stops a container
+func (r *Runtime) Stop(ctx context.Context, containerID string) error {
+	if _, err := r.run(ctx, "stop", containerID); err != nil {
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+	return nil
+}
+
+// Remove removes a container
+func (r *Runtime) Remove(ctx context.Context, containerID string, force bool) error {
+	args := []string{"rm"}
+	if force {
+		args = append(args, "-f")
+	}
+	args = append(args, containerID)
+
+	if _, err := r.run(ctx, args...); err != nil {
+		return fmt.Errorf("failed to remove container: %w", err)
+	}
+	return nil
+}
+
+// Rename changes a container's name
+func (r *Runtime) Rename(ctx context.Context, containerID, newName string) error {
+	if _, err := r.run(ctx, "rename", containerID, newName); err != nil {
+		return fmt.Errorf("failed to rename container: %w", err)
+	}
+	return nil
+}
+
+// execArgs builds the podman exec command line shared by Exec and ExecInteractive
+func execArgs(containerID string, cfg container.ExecConfig, interactive bool) []string {
+	var args []string
+	if cfg.TTY && interactive {
+		// Our stdin is a pipe, not a terminal; podman warns about that but
+		// still allocates the terminal in the container
+		args = append(args, "--log-level=error")
+	}
+	args = append(args, "exec")
+
+	if interactive || cfg.AttachStdin {
+		args = append(args, "-i")
+	}
+	if cfg.TTY {
+		args = append(args, "-t")
+	}
+	for _, env := range cfg.Env {
+		args = append(args, "-e", env)
+	}
+	if cfg.WorkingDir != "" {
+		args = append(args, "-w", cfg.WorkingDir)
+	}
+	if cfg.User != "" {
+		args = append(args, "-u", cfg.User)
+	}
+
+	args = append(args, containerID)
+	return append(args, cfg.Cmd...)
+}
+
+// podmanErrorExit is the exit code podman uses for its own failures, as
+// opposed to the exit code of the command run in the container
+const podmanErrorExit = 125
+
+// Exec executes a command in a running container
+func (r *Runtime) Exec(ctx context.Context, containerID string, cfg container.ExecConfig) (*container.ExecResult, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, r.binaryPath, execArgs(containerID, cfg, false)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	exitCode := 0
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("failed to exec in container: %w", err)
+		}
+		if exitErr.ExitCode() == podmanErrorExit {
+			return nil, fmt.Errorf("failed to exec in container: %s", strings.TrimSpace(stderr.String()))
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	return &container.ExecResult{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: exitCode,
+	}, nil
+}
+
+// ExecInteractive starts an interactive command execution with I/O pipes
+func (r *Runtime) ExecInteractive(ctx context.Context, containerID string, cfg container.ExecConfig) (*container.InteractiveExec, error) {
+	cmd := exec.CommandContext(ctx, r.binaryPath, execArgs(containerID, cfg, true)...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		_ = stdin.Close()
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		_ = stdin.Close()
+		_ = stdout.Close()
+		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		_ = stdin.Close()
+		_ = stdout.Close()
+		_ = stderr.Close()
+		return nil, fmt.Errorf("failed to start interactive exec: %w", err)
+	}
+
+	wait := func() (int, error) {
+		err := cmd.Wait()
+		if err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				return exitErr.ExitCode(), nil
+			}
+			return -1, err
+		}
+		return 0, nil
+	}
+
+	return container.NewInteractiveExec(stdin, stdout, stderr, wait), nil
+}
+
+// podmanInspect represents the JSON output from `podman container inspect`
+type podmanInspect struct {
+	ID        string    `json:"Id"`
+	Name      string    `json:"Name"`
+	ImageName string    `json:"ImageName"`
+	Created   time.Time `json:"Created"`
+	State     struct {
+		Status    string    `json:"Status"`
+		StartedAt time.Time `json:"StartedAt"`
+	} `json:"State"`
+	Config struct {
+		Env    []string          `json:"Env"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	Mounts []struct {
+		Type        string `json:"Type"`
+		Source      string `json:"Source"`
+		Destination string `json:"Destination"`
+		RW          bool   `json:"RW"`
+	} `json:"Mounts"`
+	NetworkSettings struct {
+		IPAddress string `json:"IPAddress"`
+	} `json:"NetworkSettings"`
+}
+
+// Inspect returns container information
+func (r *Runtime) Inspect(ctx context.Context, containerID string) (*container.ContainerInfo, error) {
+	output, err := r.run(ctx, "container", "inspect", containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	var inspects []podmanInspect
+	if err := json.Unmarshal([]byte(output), &inspects); err != nil {
+		return nil, fmt.Errorf("failed to parse inspect output: %w", err)
+	}
+	if len(inspects) == 0 {
+		return nil, fmt.Errorf("container not found: %s", containerID)
+	}
+	inspect := inspects[0]
+
+	var mounts []container.Mount
+	for _, m := range inspect.Mounts {
+		mounts = append(mounts, container.Mount{
+			Type:     container.MountType(m.Type),
+			Source:   m.Source,
+			Target:   m.Destination,
+			ReadOnly: !m.RW,
+		})
+	}
+
+	return &container.ContainerInfo{
+		ID:        inspect.ID,
+		Name:      inspect.Name,
+		Image:     inspect.ImageName,
+		Status:    parseStatus(inspect.State.Status),
+		IPAddress: inspect.NetworkSettings.IPAddress,
+		Mounts:    mounts,
+		Env:       inspect.Config.Env,
+		Labels:    inspect.Config.Labels,
+		CreatedAt: inspect.Created,
+		StartedAt: inspect.State.StartedAt,
+	}, nil
+}
+
+// parseStatus maps a podman container state to a ContainerStatus
+func parseStatus(state string) container.ContainerStatus {
+	switch state {
+	case "created", "configured", "initialized":
+		return container.StatusCreated
+	case "running", "stopping":
+		return container.StatusRunning
+	case "paused":
+		return container.StatusPaused
+	case "stopped", "exited":
+		return container.StatusExited
+	case "dead", "removing":
+		return container.StatusDead
+	default:
+		return container.StatusUnknown
+	}
+}
+
+// Logs retrieves container logs
+func (r *Runtime) Logs(ctx context.Context, containerID string, opts container.LogsOptions) (string, error) {
+	tail := opts.Tail
+	if tail == "" {
+		tail = "1000"
+	}
+	args := []string{"logs", "--tail", tail}
+	if opts.Timestamps {
+		args = append(args, "--timestamps")
+	}
+	args = append(args, containerID)
+
+	// podman logs replays the container's stdout and stderr on its own
+	cmd := exec.CommandContext(ctx, r.binaryPath, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to get container logs: %w, output: %s", err, string(output))
+	}
+	return string(output), nil
+}
+
+// FollowLogs streams container logs to w until the container stops or ctx is done
+func (r *Runtime) FollowLogs(ctx context.Context, containerID string, opts container.LogsOptions, w io.Writer) error {
+	args := []string{"logs", "--follow"}
+	if opts.Tail != "" {
+		args = append(args, "--tail", opts.Tail)
+	}
+	if opts.Timestamps {
+		args = append(args, "--timestamps")
+	}
+	args = append(args, containerID)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, r.binaryPath, args...)
+	cmd.Stdout = w
+	cmd.Stderr = io.MultiWriter(w, &stderr)
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to follow container logs: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// ListContainers returns the names of all containers whose name starts with prefix
+func (r *Runtime) ListContainers(ctx context.Context, prefix string) ([]string, error) {
+	out, err := r.run(ctx, "ps", "--all", "--format", "{{.Names}}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	var names []string
+	for _, name := range strings.Fields(out) {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Status returns the container status
+func (r *Runtime) Status(ctx context.Context, containerID string) (container.ContainerStatus, error) {
+	state, err := r.run(ctx, "container", "inspect", "--format", "{{.State.Status}}", containerID)
+	if err != nil {
+		return container.StatusUnknown, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	return parseStatus(state), nil
+}
+
+// Build builds an image with podman build
+func (r *Runtime) Build(ctx context.Context, cfg container.BuildConfig) error {
+	args := []string{"build"}
+
+	if cfg.ImageName != "" {
+		args = append(args, "-t", cfg.ImageName)
+	}
+
+	if cfg.DockerfilePath != "" {
+		dockerfile := cfg.DockerfilePath
+		if !filepath.IsAbs(dockerfile) {
+			dockerfile = filepath.Join(cfg.ContextPath, dockerfile)
+		}
+		args = append(args, "-f", dockerfile)
+	}
+
+	for k, v := range cfg.BuildArgs {
+		args = append(args, "--build-arg", fmt.Sprintf("%s=%s", k, v))
+	}
+
+	args = append(args, cfg.ContextPath)
+
+	cmd := exec.CommandContext(ctx, r.binaryPath, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if cfg.Output != nil {
+		cmd.Stdout = cfg.Output
+		cmd.Stderr = cfg.Output
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to build image: %w", err)
+	}
+	return nil
+}
+
+// RemoveImage removes a local image. A missing image is not an error.
+func (r *Runtime) RemoveImage(ctx context.Context, imageName string) error {
+	exists, err := r.ImageExists(ctx, imageName)
+	if err != nil || !exists {
+		return err
+	}
+	if _, err := r.run(ctx, "rmi", imageName); err != nil {
+		return fmt.Errorf("failed to remove image %s: %w", imageName, err)
+	}
+	return nil
+}
+
+// ImageExists checks if an image exists in local storage
+func (r *Runtime) ImageExists(ctx context.Context, imageName string) (bool, error) {
+	cmd := exec.CommandContext(ctx, r.binaryPath, "image", "exists", imageName)
+	if err := cmd.Run(); err != nil {
+		// podman image exists exits 1 when the image is not present
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to inspect image: %w", err)
+	}
+	return true, nil
+}
+
+// Pull pulls an image from a registry
+func (r *Runtime) Pull(ctx context.Context, imageName string) error {
+	cmd := exec.CommandContext(ctx, r.binaryPath, "pull", imageName)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to pull image %s: %w", imageName, err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package podman
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/container/containertest"
+)
+
+func TestMountSpec(t *testing.T) {
+	tests := []struct {
+		name  string
+		mount container.Mount
+		want  string
+	}{
+		{"bind", container.Mount{Type: container.MountTypeBind, Source: "/data/p", Target: "/workspace"}, "type=bind,source=/data/p,target=/workspace"},
+		{"default type", container.Mount{Source: "/data/p", Target: "/workspace"}, "type=bind,source=/data/p,target=/workspace"},
+		{"readonly", container.Mount{Type: container.MountTypeBind, Source: "/cfg", Target: "/cfg", ReadOnly: true}, "type=bind,source=/cfg,target=/cfg,readonly"},
+		{"volume", container.Mount{Type: container.MountTypeVolume, Source: "cache", Target: "/cache"}, "type=volume,source=cache,target=/cache"},
+		{"tmpfs", container.Mount{Type: container.MountTypeTmpfs, Source: "ignored", Target: "/tmp"}, "type=tmpfs,target=/tmp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mountSpec(tt.mount); got != tt.want {
+				t.Errorf("mountSpec() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateArgs(t *testing.T) {
+	r := &Runtime{binaryPath: "podman", userns: "keep-id"}
+	args, err := r.createArgs(container.CreateConfig{
+		Name:       "oubliette-550e8400",
+		Image:      "ghcr.io/hyphagroup/oubliette-base:latest",
+		Cmd:        []string{"sleep", "infinity"},
+		Entrypoint: []string{"/usr/local/bin/container-init.sh", "--verbose"},
+		Env:        []string{"OUBLIETTE_PROJECT_ID=550e8400"},
+		WorkingDir: "/workspace",
+		Mounts:     []container.Mount{{Type: container.MountTypeBind, Source: "/data/p", Target: "/workspace"}},
+		Init:       true,
+		Memory:     "4G",
+		CPUs:       2,
+		PublishedSockets: []container.PublishedSocket{
+			{HostPath: "/tmp/oubliette-sockets/550e8400/relay.sock", ContainerPath: "/mcp/relay.sock"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("createArgs() error = %v", err)
+	}
+	got := strings.Join(args, " ")
+
+	for _, want := range []string{
+		"--name oubliette-550e8400",
+		"--userns keep-id",
+		"-e OUBLIETTE_PROJECT_ID=550e8400",
+		"-w /workspace",
+		"--mount type=bind,source=/data/p,target=/workspace",
+		"--mount type=bind,source=/tmp/oubliette-sockets/550e8400,target=/mcp",
+		"--init",
+		"--memory 4G",
+		"--cpus 2",
+		`--entrypoint ["/usr/local/bin/container-init.sh","--verbose"]`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("createArgs() missing %q in %q", want, got)
+		}
+	}
+	if !strings.HasSuffix(got, "ghcr.io/hyphagroup/oubliette-base:latest sleep infinity") {
+		t.Errorf("createArgs() should end with image and command, got %q", got)
+	}
+
+	r.userns = ""
+	args, _ = r.createArgs(container.CreateConfig{Image: "alpine"})
+	if slices.Contains(args, "--userns") {
+		t.Errorf("createArgs() with no userns = %v, want no --userns", args)
+	}
+}
+
+func TestExecArgs(t *testing.T) {
+	cfg := container.ExecConfig{
+		Cmd:        []string{"opencode", "serve"},
+		Env:        []string{"A=1"},
+		WorkingDir: "/workspace",
+		User:       "gogol",
+		TTY:        true,
+	}
+
+	got := strings.Join(execArgs("abc", cfg, false), " ")
+	if want := "exec -t -e A=1 -w /workspace -u gogol abc opencode serve"; got != want {
+		t.Errorf("execArgs() = %q, want %q", got, want)
+	}
+
+	// An interactive TTY exec quiets podman's warning that stdin is not a terminal
+	got = strings.Join(execArgs("abc", cfg, true), " ")
+	if want := "--log-level=error exec -i -t -e A=1 -w /workspace -u gogol abc opencode serve"; got != want {
+		t.Errorf("execArgs(interactive) = %q, want %q", got, want)
+	}
+
+	cfg.TTY = false
+	got = strings.Join(execArgs("abc", cfg, true), " ")
+	if want := "exec -i -e A=1 -w /workspace -u gogol abc opencode serve"; got != want {
+		t.Errorf("execArgs(interactive, no TTY) = %q, want %q", got, want)
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	tests := map[string]container.ContainerStatus{
+		"configured": container.StatusCreated,
+		"created":    container.StatusCreated,
+		"running":    container.StatusRunning,
+		"paused":     container.StatusPaused,
+		"stopped":    container.StatusExited,
+		"exited":     container.StatusExited,
+		"dead":       container.StatusDead,
+		"bogus":      container.StatusUnknown,
+	}
+	for state, want := range tests {
+		if got := parseStatus(state); got != want {
+			t.Errorf("parseStatus(%q) = %q, want %q", state, got, want)
+		}
+	}
+}
+
+// TestConformance runs the runtime conformance suite against podman. It
+// creates real containers, so it only runs when OUBLIETTE_RUNTIME_CONFORMANCE is set.
+func TestConformance(t *testing.T) {
+	if os.Getenv("OUBLIETTE_RUNTIME_CONFORMANCE") == "" {
+		t.Skip("OUBLIETTE_RUNTIME_CONFORMANCE not set")
+	}
+	r, err := NewRuntime()
+	if err != nil {
+		t.Fatalf("NewRuntime() error = %v", err)
+	}
+	containertest.Run(t, r, containertest.Config{Image: os.Getenv("OUBLIETTE_CONFORMANCE_IMAGE")})
+}
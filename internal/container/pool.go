@@ -0,0 +1,310 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/logger"
+	"github.com/HyphaGroup/oubliette/internal/metrics"
+)
+
+// poolRetryInterval is how long the pool waits before retrying a failed warm-up
+const poolRetryInterval = 30 * time.Second
+
+// WarmContainer is a pre-started, health-checked container waiting in the pool
+type WarmContainer struct {
+	Type      string // Container type (e.g., "dev")
+	Image     string // Image the container runs
+	ID        string
+	Name      string
+	Dir       string // Host directory backing the container's project mounts
+	SocketDir string // Host directory backing the container's relay socket
+	WarmedAt  time.Time
+
+	slot int
+}
+
+// PoolWarmer creates and disposes of pool containers. The pool only decides
+// when to warm and which name to use; the warmer knows the mount layout and
+// how to health-check the agent inside.
+type PoolWarmer interface {
+	// Warm creates, starts and health-checks a container with the given name
+	Warm(ctx context.Context, containerType, name string) (*WarmContainer, error)
+	// Discard removes a warm container that will not be claimed
+	Discard(ctx context.Context, wc *WarmContainer)
+}
+
+// PoolStatus summarizes the pool for one container type
+type PoolStatus struct {
+	Type    string `json:"type"`
+	Size    int    `json:"size"`
+	Idle    int    `json:"idle"`
+	Warming int    `json:"warming"`
+}
+
+// Pool keeps a configured number of warm containers per container type and
+// refills itself in the background as containers are claimed.
+type Pool struct {
+	warmer PoolWarmer
+	sizes  map[string]int
+
+	mu      sync.Mutex
+	idle    map[string][]*WarmContainer
+	slots   map[string]map[int]bool // Slots in use (idle, warming or being bound)
+	warming map[string]int
+	// generation is bumped by Flush; containers that finish warming in a
+	// later generation than they started in are discarded
+	generation int
+
+	kick   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPool creates a pool with the given number of warm containers per type.
+// Types with a size of zero or less are ignored.
+func NewPool(warmer PoolWarmer, sizes map[string]int) *Pool {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		warmer:  warmer,
+		sizes:   make(map[string]int),
+		idle:    make(map[string][]*WarmContainer),
+		slots:   make(map[string]map[int]bool),
+		warming: make(map[string]int),
+		kick:    make(chan struct{}, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	for containerType, size := range sizes {
+		if size > 0 {
+			p.sizes[containerType] = size
+			p.slots[containerType] = make(map[int]bool)
+		}
+	}
+	return p
+}
+
+// PoolContainerPrefix starts the names of pool containers and their socket directories
+const PoolContainerPrefix = "oubliette-pool-"
+
+// PoolContainerName returns the name of the pool container in a slot
+func PoolContainerName(containerType string, slot int) string {
+	return fmt.Sprintf("%s%s-%d", PoolContainerPrefix, containerType, slot)
+}
+
+// Start begins filling the pool in the background
+func (p *Pool) Start() {
+	p.wg.Add(1)
+	go p.refillLoop()
+	p.signal()
+}
+
+// Stop halts refilling and discards all idle containers
+func (p *Pool) Stop(ctx context.Context) {
+	p.cancel()
+	p.wg.Wait()
+
+	p.mu.Lock()
+	var idle []*WarmContainer
+	for containerType, containers := range p.idle {
+		idle = append(idle, containers...)
+		delete(p.idle, containerType)
+		metrics.SetContainerPoolIdle(containerType, 0)
+	}
+	p.mu.Unlock()
+
+	for _, wc := range idle {
+		p.warmer.Discard(ctx, wc)
+	}
+}
+
+// Flush discards the idle containers, and those still warming once they are
+// ready, and refills the pool. Used when the config pool containers were
+// created with, such as the provider credentials in their env, has changed.
+func (p *Pool) Flush() {
+	p.mu.Lock()
+	p.generation++
+	var stale []*WarmContainer
+	for containerType, containers := range p.idle {
+		for _, wc := range containers {
+			delete(p.slots[containerType], wc.slot)
+		}
+		stale = append(stale, containers...)
+		delete(p.idle, containerType)
+		metrics.SetContainerPoolIdle(containerType, 0)
+	}
+	p.mu.Unlock()
+
+	for _, wc := range stale {
+		p.warmer.Discard(context.Background(), wc)
+	}
+	p.signal()
+}
+
+// Claim takes a warm container of the given type running image, or returns
+// nil if none is ready. The caller must call Release once the container has
+// been renamed or discarded, which frees its slot for a replacement.
+func (p *Pool) Claim(containerType, image string) *WarmContainer {
+	if _, ok := p.sizes[containerType]; !ok {
+		return nil
+	}
+
+	p.mu.Lock()
+	var claimed *WarmContainer
+	var stale []*WarmContainer
+	for len(p.idle[containerType]) > 0 && claimed == nil {
+		wc := p.idle[containerType][0]
+		p.idle[containerType] = p.idle[containerType][1:]
+		if wc.Image == image {
+			claimed = wc
+		} else {
+			// The type's image changed since this container was warmed
+			stale = append(stale, wc)
+		}
+	}
+	for _, wc := range stale {
+		delete(p.slots[containerType], wc.slot)
+	}
+	metrics.SetContainerPoolIdle(containerType, len(p.idle[containerType]))
+	p.mu.Unlock()
+
+	for _, wc := range stale {
+		p.warmer.Discard(context.Background(), wc)
+	}
+	metrics.RecordContainerPoolClaim(containerType, claimed != nil)
+	p.signal()
+	return claimed
+}
+
+// Release frees the slot of a claimed container so the pool can refill it
+func (p *Pool) Release(wc *WarmContainer) {
+	p.mu.Lock()
+	delete(p.slots[wc.Type], wc.slot)
+	p.mu.Unlock()
+	p.signal()
+}
+
+// Status reports the pool state per container type, sorted by type
+func (p *Pool) Status() []PoolStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := make([]PoolStatus, 0, len(p.sizes))
+	for containerType, size := range p.sizes {
+		status = append(status, PoolStatus{
+			Type:    containerType,
+			Size:    size,
+			Idle:    len(p.idle[containerType]),
+			Warming: p.warming[containerType],
+		})
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Type < status[j].Type })
+	return status
+}
+
+func (p *Pool) signal() {
+	select {
+	case p.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (p *Pool) refillLoop() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(poolRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-p.kick:
+		case <-ticker.C:
+		}
+		p.refill()
+	}
+}
+
+// refill warms containers until every type is at its configured size. A type
+// whose warm-up fails is left alone until the next retry tick.
+func (p *Pool) refill() {
+	types := make([]string, 0, len(p.sizes))
+	for containerType := range p.sizes {
+		types = append(types, containerType)
+	}
+	sort.Strings(types)
+
+	for _, containerType := range types {
+		for p.ctx.Err() == nil {
+			slot, ok := p.reserveSlot(containerType)
+			if !ok {
+				break
+			}
+			if err := p.warm(containerType, slot); err != nil {
+				logger.Error("Failed to warm %s pool container: %v", containerType, err)
+				break
+			}
+		}
+	}
+}
+
+// reserveSlot picks the lowest free slot for a type, or reports that the type is full
+func (p *Pool) reserveSlot(containerType string) (int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	size := p.sizes[containerType]
+	if len(p.idle[containerType])+p.warming[containerType] >= size {
+		return 0, false
+	}
+	for slot := 0; slot < size; slot++ {
+		if !p.slots[containerType][slot] {
+			p.slots[containerType][slot] = true
+			p.warming[containerType]++
+			return slot, true
+		}
+	}
+	// Every slot is still being bound to a project; wait for a Release
+	return 0, false
+}
+
+func (p *Pool) warm(containerType string, slot int) error {
+	p.mu.Lock()
+	generation := p.generation
+	p.mu.Unlock()
+
+	start := time.Now()
+	wc, err := p.warmer.Warm(p.ctx, containerType, PoolContainerName(containerType, slot))
+	metrics.RecordContainerPoolWarm(containerType, time.Since(start), err)
+
+	p.mu.Lock()
+	p.warming[containerType]--
+	if err != nil {
+		delete(p.slots[containerType], slot)
+		p.mu.Unlock()
+		return err
+	}
+	wc.Type = containerType
+	wc.slot = slot
+	if wc.WarmedAt.IsZero() {
+		wc.WarmedAt = time.Now()
+	}
+	if p.ctx.Err() != nil || generation != p.generation {
+		// Stopped or flushed while warming; a flushed slot is refilled by the caller's loop
+		delete(p.slots[containerType], slot)
+		p.mu.Unlock()
+		p.warmer.Discard(context.Background(), wc)
+		return nil
+	}
+	p.idle[containerType] = append(p.idle[containerType], wc)
+	metrics.SetContainerPoolIdle(containerType, len(p.idle[containerType]))
+	p.mu.Unlock()
+
+	logger.Info("Warmed %s pool container %s in %s", containerType, wc.Name, time.Since(start).Round(time.Millisecond))
+	return nil
+}
@@ -0,0 +1,159 @@
+package container_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/container"
+)
+
+type fakeWarmer struct {
+	mu        sync.Mutex
+	image     string
+	err       error
+	warmed    []string
+	discarded []string
+}
+
+func (w *fakeWarmer) Warm(ctx context.Context, containerType, name string) (*container.WarmContainer, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return nil, w.err
+	}
+	w.warmed = append(w.warmed, name)
+	return &container.WarmContainer{Image: w.image, ID: "id-" + name, Name: name}, nil
+}
+
+func (w *fakeWarmer) Discard(ctx context.Context, wc *container.WarmContainer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.discarded = append(w.discarded, wc.Name)
+}
+
+func (w *fakeWarmer) setImage(image string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.image = image
+}
+
+func waitForIdle(t *testing.T, p *container.Pool, containerType string, want int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, st := range p.Status() {
+			if st.Type == containerType && st.Idle == want && st.Warming == 0 {
+				return
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("pool %s did not reach %d idle containers: %+v", containerType, want, p.Status())
+}
+
+func TestPoolFillsAndRefills(t *testing.T) {
+	warmer := &fakeWarmer{image: "dev:1"}
+	p := container.NewPool(warmer, map[string]int{"dev": 2, "osint": 0})
+	p.Start()
+	defer p.Stop(context.Background())
+
+	waitForIdle(t, p, "dev", 2)
+	if status := p.Status(); len(status) != 1 || status[0].Size != 2 {
+		t.Fatalf("Status() = %+v, want only dev with size 2", status)
+	}
+
+	wc := p.Claim("dev", "dev:1")
+	if wc == nil {
+		t.Fatal("Claim() = nil, want a warm container")
+	}
+	if wc.Type != "dev" || wc.Name != "oubliette-pool-dev-0" {
+		t.Errorf("claimed %s/%s, want dev/oubliette-pool-dev-0", wc.Type, wc.Name)
+	}
+
+	// The slot stays reserved until the claimer releases it
+	waitForIdle(t, p, "dev", 1)
+	p.Release(wc)
+	waitForIdle(t, p, "dev", 2)
+
+	warmer.mu.Lock()
+	defer warmer.mu.Unlock()
+	if len(warmer.warmed) != 3 || warmer.warmed[2] != "oubliette-pool-dev-0" {
+		t.Errorf("warmed = %v, want slot 0 refilled", warmer.warmed)
+	}
+}
+
+func TestPoolClaimMiss(t *testing.T) {
+	warmer := &fakeWarmer{err: errors.New("no runtime")}
+	p := container.NewPool(warmer, map[string]int{"dev": 1})
+	p.Start()
+	defer p.Stop(context.Background())
+
+	if wc := p.Claim("dev", "dev:1"); wc != nil {
+		t.Errorf("Claim() with failing warmer = %+v, want nil", wc)
+	}
+	if wc := p.Claim("unpooled", "dev:1"); wc != nil {
+		t.Errorf("Claim() for unpooled type = %+v, want nil", wc)
+	}
+}
+
+func TestPoolDiscardsStaleImage(t *testing.T) {
+	warmer := &fakeWarmer{image: "dev:1"}
+	p := container.NewPool(warmer, map[string]int{"dev": 1})
+	p.Start()
+	waitForIdle(t, p, "dev", 1)
+
+	warmer.setImage("dev:2")
+	if wc := p.Claim("dev", "dev:2"); wc != nil {
+		t.Errorf("Claim() with new image = %+v, want nil", wc)
+	}
+	waitForIdle(t, p, "dev", 1)
+	if wc := p.Claim("dev", "dev:2"); wc == nil {
+		t.Error("Claim() after refill = nil, want container with new image")
+	}
+
+	p.Stop(context.Background())
+	warmer.mu.Lock()
+	defer warmer.mu.Unlock()
+	if len(warmer.discarded) != 1 {
+		t.Errorf("discarded = %v, want the stale container only", warmer.discarded)
+	}
+}
+
+func TestPoolStopDiscardsIdle(t *testing.T) {
+	warmer := &fakeWarmer{image: "dev:1"}
+	p := container.NewPool(warmer, map[string]int{"dev": 2})
+	p.Start()
+	waitForIdle(t, p, "dev", 2)
+
+	p.Stop(context.Background())
+
+	warmer.mu.Lock()
+	defer warmer.mu.Unlock()
+	if len(warmer.discarded) != 2 {
+		t.Errorf("discarded = %v, want both idle containers", warmer.discarded)
+	}
+}
+
+func TestPoolFlush(t *testing.T) {
+	warmer := &fakeWarmer{image: "dev:1"}
+	p := container.NewPool(warmer, map[string]int{"dev": 2})
+	p.Start()
+	defer p.Stop(context.Background())
+	waitForIdle(t, p, "dev", 2)
+
+	p.Flush()
+	waitForIdle(t, p, "dev", 2)
+
+	warmer.mu.Lock()
+	warmed, discarded := len(warmer.warmed), len(warmer.discarded)
+	warmer.mu.Unlock()
+	if discarded != 2 || warmed != 4 {
+		t.Errorf("after Flush() warmed %d and discarded %d containers, want 4 and 2", warmed, discarded)
+	}
+	if wc := p.Claim("dev", "dev:1"); wc == nil {
+		t.Error("Claim() after Flush() = nil, want a rewarmed container")
+	}
+}
@@ -2,8 +2,12 @@ package container
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/validation"
 )
 
 // Runtime defines the container runtime abstraction
@@ -13,6 +17,7 @@ type Runtime interface {
 	Start(ctx context.Context, containerID string) error
 	Stop(ctx context.Context, containerID string) error
 	Remove(ctx context.Context, containerID string, force bool) error
+	Rename(ctx context.Context, containerID, newName string) error
 
 	// Execution
 	Exec(ctx context.Context, containerID string, config ExecConfig) (*ExecResult, error)
@@ -21,12 +26,17 @@ type Runtime interface {
 	// Inspection
 	Inspect(ctx context.Context, containerID string) (*ContainerInfo, error)
 	Logs(ctx context.Context, containerID string, opts LogsOptions) (string, error)
+	// FollowLogs writes the last opts.Tail lines of the container's output to w
+	// and keeps writing new output until the container stops (nil) or ctx is
+	// done (ctx.Err())
+	FollowLogs(ctx context.Context, containerID string, opts LogsOptions, w io.Writer) error
 	Status(ctx context.Context, containerID string) (ContainerStatus, error)
 
 	// Images
 	Build(ctx context.Context, config BuildConfig) error
 	ImageExists(ctx context.Context, imageName string) (bool, error)
 	Pull(ctx context.Context, imageName string) error
+	RemoveImage(ctx context.Context, imageName string) error
 
 	// Health
 	Ping(ctx context.Context) error
@@ -37,6 +47,17 @@ type Runtime interface {
 	IsAvailable() bool
 }
 
+// Lister is implemented by runtimes that can enumerate containers
+type Lister interface {
+	// ListContainers returns the names of all containers, running or not,
+	// whose name starts with prefix
+	ListContainers(ctx context.Context, prefix string) ([]string, error)
+}
+
+// ErrResizeUnsupported is returned by InteractiveExec.Resize when the runtime
+// cannot change the size of an exec's terminal
+var ErrResizeUnsupported = errors.New("terminal resize not supported by this runtime")
+
 // InteractiveExec represents an interactive command execution with I/O pipes
 type InteractiveExec struct {
 	Stdin  io.WriteCloser
@@ -44,6 +65,7 @@ type InteractiveExec struct {
 	Stderr io.ReadCloser
 	done   chan struct{}
 	wait   func() (int, error)
+	resize func(height, width uint) error
 }
 
 // NewInteractiveExec creates a new InteractiveExec
@@ -73,6 +95,20 @@ func (e *InteractiveExec) Wait() (int, error) {
 	return code, err
 }
 
+// SetResize sets how Resize changes the exec's terminal size. Runtimes call
+// it for TTY execs they can resize.
+func (e *InteractiveExec) SetResize(resize func(height, width uint) error) {
+	e.resize = resize
+}
+
+// Resize changes the terminal size of a TTY exec
+func (e *InteractiveExec) Resize(height, width uint) error {
+	if e.resize == nil {
+		return ErrResizeUnsupported
+	}
+	return e.resize(height, width)
+}
+
 // Close closes all I/O streams
 func (e *InteractiveExec) Close() error {
 	if e.Stdin != nil {
@@ -140,7 +176,7 @@ type ExecConfig struct {
 	AttachStdout bool
 	AttachStderr bool
 	AttachStdin  bool
-	TTY          bool
+	TTY          bool // Allocate a pseudo-terminal; interactive output then all arrives on Stdout
 	User         string
 }
 
@@ -166,6 +202,7 @@ type ContainerInfo struct {
 	IPAddress string
 	Mounts    []Mount
 	Env       []string
+	Labels    map[string]string
 	CreatedAt time.Time
 	StartedAt time.Time
 }
@@ -186,7 +223,17 @@ const (
 // BuildConfig for image building
 type BuildConfig struct {
 	ImageName      string
-	DockerfilePath string
+	DockerfilePath string // Relative to ContextPath
 	ContextPath    string
 	BuildArgs      map[string]string
+	Output         io.Writer // Build log destination (default: stdout)
+}
+
+// ProjectContainerName returns the name of a project's container. The project
+// ID must be a UUID; its first 8 characters name the container.
+func ProjectContainerName(projectID string) (string, error) {
+	if err := validation.ValidateProjectID(projectID); err != nil {
+		return "", fmt.Errorf("invalid project ID: %w", err)
+	}
+	return "oubliette-" + projectID[:8], nil
 }
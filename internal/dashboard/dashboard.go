@@ -0,0 +1,42 @@
+// Package dashboard serves the embedded web UI for watching projects, sessions
+// and schedules from a browser.
+//
+// The UI is static: it holds no data and needs no authentication to load. It
+// asks for an API token, keeps it in the browser tab's session storage, and
+// sends it as a Bearer token to the server's dashboard API under Path + "api/".
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// Path is where the dashboard is served
+const Path = "/ui/"
+
+//go:embed static
+var static embed.FS
+
+// Handler serves the dashboard's static files under Path
+func Handler() http.Handler {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err) // The embedded directory always exists
+	}
+	fileServer := http.StripPrefix(Path, http.FileServerFS(files))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Scripts, styles and API calls only from this server; never framed
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; img-src 'self' data:; frame-ancestors 'none'; base-uri 'none'; form-action 'self'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+
+		// Views are addressed by URL fragment, so every page path is the app
+		if r.URL.Path != Path && !strings.Contains(strings.TrimPrefix(r.URL.Path, Path), ".") {
+			r.URL.Path = Path
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
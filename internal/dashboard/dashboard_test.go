@@ -0,0 +1,49 @@
+package dashboard
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	srv := httptest.NewServer(Handler())
+	defer srv.Close()
+
+	tests := []struct {
+		path        string
+		status      int
+		contentType string
+		contains    string
+	}{
+		{"/ui/", http.StatusOK, "text/html", `src="/ui/app.js"`},
+		{"/ui/schedules", http.StatusOK, "text/html", `src="/ui/app.js"`},
+		{"/ui/app.js", http.StatusOK, "javascript", "function route"},
+		{"/ui/style.css", http.StatusOK, "text/css", ""},
+		{"/ui/missing.js", http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(srv.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != tt.status {
+			t.Errorf("GET %s status = %d, want %d", tt.path, resp.StatusCode, tt.status)
+			continue
+		}
+		if !strings.Contains(resp.Header.Get("Content-Type"), tt.contentType) {
+			t.Errorf("GET %s Content-Type = %q, want %s", tt.path, resp.Header.Get("Content-Type"), tt.contentType)
+		}
+		if !strings.Contains(string(body), tt.contains) {
+			t.Errorf("GET %s body missing %q", tt.path, tt.contains)
+		}
+		if csp := resp.Header.Get("Content-Security-Policy"); !strings.Contains(csp, "default-src 'self'") || !strings.Contains(csp, "frame-ancestors 'none'") {
+			t.Errorf("GET %s Content-Security-Policy = %q", tt.path, csp)
+		}
+	}
+}
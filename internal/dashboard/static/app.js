@@ -0,0 +1,523 @@
+// Oubliette dashboard. Everything shown comes from the server's dashboard API
+// and is inserted as text, never as HTML: agent output is untrusted.
+"use strict";
+
+const API = "/ui/api/";
+const TOKEN_KEY = "oubliette-token";
+const POLL_MS = 1500;
+
+let me = null;
+let stopView = null; // Cancels the current view's polling
+let generation = 0; // Bumped on navigation so late refreshes don't redraw a view that was left
+
+// ---- API ------------------------------------------------------------------
+
+class APIError extends Error {
+  constructor(status, message) {
+    super(message);
+    this.status = status;
+  }
+}
+
+async function api(path, options = {}) {
+  const token = sessionStorage.getItem(TOKEN_KEY);
+  const resp = await fetch(API + path, {
+    ...options,
+    headers: {
+      "Authorization": "Bearer " + token,
+      ...(options.body ? { "Content-Type": "application/json" } : {}),
+    },
+  });
+  let body = null;
+  try {
+    body = await resp.json();
+  } catch (e) {
+    // Non-JSON errors fall through to the status check
+  }
+  if (resp.status === 401) {
+    signOut("Your token was rejected. Sign in again.");
+    throw new APIError(401, "unauthorized");
+  }
+  if (!resp.ok) {
+    // Dashboard errors are {"error": "..."}; auth and rate limit errors are JSON-RPC shaped
+    const e = body && body.error;
+    throw new APIError(resp.status, typeof e === "string" ? e : (e && e.message) || resp.statusText);
+  }
+  return body;
+}
+
+// ---- DOM helpers -------------------------------------------------------------
+
+// h builds an element. Strings and numbers become text nodes.
+function h(tag, attrs, ...children) {
+  const el = document.createElement(tag);
+  for (const [key, value] of Object.entries(attrs || {})) {
+    if (value === null || value === undefined || value === false) continue;
+    if (key.startsWith("on")) {
+      el.addEventListener(key.slice(2), value);
+    } else if (key === "class") {
+      el.className = value;
+    } else {
+      el.setAttribute(key, value === true ? "" : value);
+    }
+  }
+  for (const child of children.flat()) {
+    if (child === null || child === undefined || child === false) continue;
+    el.append(child instanceof Node ? child : String(child));
+  }
+  return el;
+}
+
+function show(...children) {
+  const view = document.getElementById("view");
+  view.replaceChildren(...children);
+}
+
+function when(value) {
+  if (!value) return "—";
+  const d = new Date(value);
+  if (isNaN(d) || d.getFullYear() < 2000) return "—";
+  return d.toLocaleString();
+}
+
+function shortID(id) {
+  return id ? id.slice(0, 8) : "";
+}
+
+function badge(text, kind) {
+  return h("span", { class: "badge " + (kind || "") }, text);
+}
+
+function statusBadge(status) {
+  const kinds = {
+    running: "ok", active: "ok", idle: "ok", completed: "", enabled: "ok",
+    success: "ok", stopped: "warn", "not created": "", paused: "warn", skipped: "warn",
+    failed: "bad", timed_out: "bad", disabled: "",
+  };
+  return badge(status || "unknown", kinds[status] || "");
+}
+
+function errorCard(err) {
+  return h("div", { class: "card error" }, err.message || String(err));
+}
+
+function confirmAction(message) {
+  return window.confirm(message);
+}
+
+// ---- Auth ----------------------------------------------------------------------
+
+function signOut(message) {
+  sessionStorage.removeItem(TOKEN_KEY);
+  me = null;
+  if (stopView) stopView();
+  document.getElementById("nav").hidden = true;
+  document.getElementById("whoami").hidden = true;
+  document.getElementById("login").hidden = false;
+  document.getElementById("login-error").textContent = message || "";
+  show();
+}
+
+async function signIn() {
+  me = await api("me");
+  document.getElementById("login").hidden = true;
+  document.getElementById("nav").hidden = false;
+  document.getElementById("whoami").hidden = false;
+  document.getElementById("whoami-name").textContent = `${me.name} (${me.scope})`;
+  document.getElementById("nav-tokens").hidden = !me.admin;
+  route();
+}
+
+// ---- Views -----------------------------------------------------------------------
+
+async function projectsView() {
+  const projects = await api("projects");
+  const rows = projects.map((p) =>
+    h("tr", { class: "clickable", onclick: () => (location.hash = "#/projects/" + p.id) },
+      h("td", {}, h("strong", {}, p.name), p.description ? h("div", { class: "muted" }, p.description) : null),
+      h("td", {}, statusBadge(p.container_status)),
+      h("td", {}, p.active_sessions),
+      h("td", {}, p.container_type || "—"),
+      h("td", {}, when(p.created_at)),
+    ));
+  show(
+    h("h1", {}, "Projects"),
+    projects.length === 0
+      ? h("div", { class: "card muted" }, "No projects.")
+      : h("table", {},
+        h("thead", {}, h("tr", {}, h("th", {}, "Project"), h("th", {}, "Container"), h("th", {}, "Active sessions"), h("th", {}, "Type"), h("th", {}, "Created"))),
+        h("tbody", {}, rows)),
+  );
+  return poll(projectsView);
+}
+
+async function projectView(projectID) {
+  const [projects, sessions] = await Promise.all([api("projects"), api(`projects/${encodeURIComponent(projectID)}/sessions`)]);
+  const project = projects.find((p) => p.id === projectID) || { id: projectID, name: projectID };
+
+  // Sessions whose parent is listed nest under it; the rest are roots
+  const byID = new Map(sessions.map((s) => [s.session_id, s]));
+  const children = new Map();
+  const roots = [];
+  for (const s of sessions) {
+    if (s.parent_session_id && byID.has(s.parent_session_id)) {
+      if (!children.has(s.parent_session_id)) children.set(s.parent_session_id, []);
+      children.get(s.parent_session_id).push(s);
+    } else {
+      roots.push(s);
+    }
+  }
+  const newest = (a, b) => new Date(b.created_at) - new Date(a.created_at);
+  roots.sort(newest);
+
+  const tree = (list, root) => h("ul", { class: root ? "tree root" : "tree" }, list.map((s) =>
+    h("li", {},
+      h("div", { class: "row" },
+        h("a", { href: "#/sessions/" + s.session_id, class: "mono" }, shortID(s.session_id)),
+        statusBadge(s.live_status || s.status),
+        h("span", { class: "muted" }, `${s.turn_count} turn${s.turn_count === 1 ? "" : "s"} · ${when(s.created_at)}`),
+        s.model ? h("span", { class: "muted" }, s.model) : null,
+      ),
+      s.last_prompt ? h("div", { class: "muted" }, truncate(s.last_prompt, 160)) : null,
+      children.has(s.session_id) ? tree(children.get(s.session_id).sort(newest), false) : null,
+    )));
+
+  show(
+    h("p", {}, h("a", { href: "#/" }, "← Projects")),
+    h("h1", {}, project.name),
+    h("div", { class: "card" },
+      h("dl", { class: "meta" },
+        h("dt", {}, "Project ID"), h("dd", { class: "mono" }, project.id),
+        h("dt", {}, "Container"), h("dd", {}, project.container_status ? statusBadge(project.container_status) : "—"),
+        h("dt", {}, "Active sessions"), h("dd", {}, project.active_sessions ?? "—"),
+      )),
+    h("h2", {}, "Sessions"),
+    sessions.length === 0 ? h("div", { class: "card muted" }, "No sessions yet.") : h("div", { class: "card" }, tree(roots, true)),
+  );
+  return poll(() => projectView(projectID));
+}
+
+async function sessionView(sessionID) {
+  const sess = await api("sessions/" + encodeURIComponent(sessionID));
+  const events = h("div", { class: "events" });
+  const streaming = h("div", { class: "event message", hidden: true });
+  const status = h("span", {}, statusBadge(sess.live_status || sess.status));
+  const notice = h("div", {});
+
+  const related = [];
+  if (sess.parent_session_id) {
+    related.push(h("dt", {}, "Parent"), h("dd", {}, h("a", { href: "#/sessions/" + sess.parent_session_id, class: "mono" }, shortID(sess.parent_session_id))));
+  }
+  if (sess.child_sessions && sess.child_sessions.length) {
+    related.push(h("dt", {}, "Children"), h("dd", { class: "row" },
+      sess.child_sessions.map((id) => h("a", { href: "#/sessions/" + id, class: "mono" }, shortID(id)))));
+  }
+
+  show(
+    h("p", {}, h("a", { href: "#/projects/" + sess.project_id }, "← Project")),
+    h("h1", { class: "row" }, "Session ", h("span", { class: "mono" }, shortID(sess.session_id)), status),
+    h("div", { class: "card" },
+      h("dl", { class: "meta" },
+        h("dt", {}, "Session ID"), h("dd", { class: "mono" }, sess.session_id),
+        h("dt", {}, "Workspace"), h("dd", { class: "mono" }, sess.workspace_id || "—"),
+        h("dt", {}, "Model"), h("dd", {}, sess.model || "—"),
+        h("dt", {}, "Depth"), h("dd", {}, sess.depth),
+        h("dt", {}, "Tokens"), h("dd", {}, `${sess.total_cost.input_tokens} in · ${sess.total_cost.output_tokens} out`),
+        h("dt", {}, "Created"), h("dd", {}, when(sess.created_at)),
+        related,
+      )),
+    notice,
+    h("h2", {}, "Activity"),
+    events,
+    streaming,
+  );
+
+  // Live sessions stream their event buffer; finished ones show their turns
+  let since = -1;
+  let stopped = false;
+  let timer = null;
+  const tick = async () => {
+    if (stopped) return;
+    try {
+      const batch = await api(`sessions/${encodeURIComponent(sessionID)}/events?since_index=${since}`);
+      if (!batch.live) {
+        streaming.hidden = true;
+        if (since === -1) {
+          events.replaceChildren(...turnsView(sess.turns || []));
+          notice.replaceChildren(h("div", { class: "notice" }, "This session is not streaming. Showing its recorded turns."));
+        } else {
+          notice.replaceChildren(h("div", { class: "notice" }, "The session stopped streaming."));
+        }
+        return;
+      }
+      status.replaceChildren(statusBadge(batch.status));
+      notice.replaceChildren(...(batch.dropped_events ? [h("div", { class: "notice" }, `${batch.dropped_events} older events were dropped from the buffer.`)] : []),
+        ...(batch.error ? [h("div", { class: "notice error" }, batch.error)] : []));
+      for (const e of batch.events) {
+        if (e.type === "delta") {
+          streaming.hidden = false;
+          streaming.textContent += e.text || "";
+          continue;
+        }
+        if (e.type === "message" || e.type === "completion") {
+          streaming.hidden = true;
+          streaming.textContent = "";
+        }
+        const el = eventView(e);
+        if (el) events.append(el);
+      }
+      since = batch.last_index;
+    } catch (err) {
+      if (err.status === 401) return;
+      notice.replaceChildren(errorCard(err));
+    }
+    timer = setTimeout(tick, POLL_MS);
+  };
+  tick();
+  return () => {
+    stopped = true;
+    clearTimeout(timer);
+  };
+}
+
+function eventView(e) {
+  const head = (label) => h("div", { class: "head" }, `${label} · ${new Date(e.timestamp).toLocaleTimeString()}`);
+  switch (e.type) {
+    case "message":
+      if (!e.text) return null;
+      return h("div", { class: "event message" }, head(e.role || "message"), e.text);
+    case "tool_call":
+      return h("div", { class: "event tool_call" }, head("tool call"),
+        h("details", {}, h("summary", {}, h("strong", {}, e.tool_name || "tool")),
+          h("pre", {}, JSON.stringify(e.parameters || {}, null, 2))));
+    case "tool_result":
+      return h("div", { class: "event tool_result" + (e.is_error ? " failed" : "") }, head(e.is_error ? "tool error" : "tool result"),
+        h("details", {}, h("summary", {}, e.tool_name || "result", " · ", `${(e.value || e.text || "").length} chars`),
+          h("pre", {}, e.value || e.text || "")));
+    case "completion":
+      return h("div", { class: "event completion" }, head("completed"), e.text || "");
+    case "error":
+      return h("div", { class: "event error" }, head("error"), e.text || "");
+    default:
+      return null; // system metadata
+  }
+}
+
+function turnsView(turns) {
+  if (turns.length === 0) return [h("div", { class: "card muted" }, "No turns recorded.")];
+  return turns.map((t) => h("div", { class: "card" },
+    h("div", { class: "muted" }, `Turn ${t.number} · ${when(t.started_at)}`),
+    h("div", { class: "event message" }, h("div", { class: "head" }, "prompt"), t.prompt),
+    h("div", { class: "event " + (t.error ? "error" : "completion") }, h("div", { class: "head" }, t.error ? "error" : "output"), t.error || t.output),
+  ));
+}
+
+async function schedulesView() {
+  const schedules = await api("schedules");
+  const canWrite = me.admin;
+
+  const action = (label, path, cls, confirmText) => h("button", {
+    type: "button",
+    class: cls,
+    disabled: !canWrite,
+    title: canWrite ? null : "Requires an admin token",
+    onclick: async (ev) => {
+      if (confirmText && !confirmAction(confirmText)) return;
+      ev.target.disabled = true;
+      try {
+        const result = await api(path, { method: "POST" });
+        if (result.message) flash(result.message);
+        route();
+      } catch (err) {
+        flash(err.message, true);
+        ev.target.disabled = false;
+      }
+    },
+  }, label);
+
+  const rows = schedules.map((s) => {
+    const history = h("tr", { hidden: true }, h("td", { colspan: 6 }, h("div", { class: "muted" }, "Loading…")));
+    const toggleHistory = async () => {
+      history.hidden = !history.hidden;
+      if (history.hidden) return;
+      try {
+        const executions = await api(`schedules/${encodeURIComponent(s.id)}/executions?limit=20`);
+        history.firstChild.replaceChildren(executionsView(executions));
+      } catch (err) {
+        history.firstChild.replaceChildren(errorCard(err));
+      }
+    };
+    return [
+      h("tr", {},
+        h("td", {}, h("strong", {}, s.name), h("div", { class: "muted" }, truncate(s.prompt, 120))),
+        h("td", { class: "mono" }, s.cron_expr),
+        h("td", {}, statusBadge(s.enabled ? "enabled" : "disabled")),
+        h("td", {}, when(s.last_run_at), h("div", { class: "muted" }, "next: " + (s.enabled ? when(s.next_run_at) : "—"))),
+        h("td", {}, (s.targets || []).map((t) => h("div", {}, h("a", { href: "#/projects/" + t.project_id, class: "mono" }, shortID(t.project_id)),
+          t.session_id ? [" → ", h("a", { href: "#/sessions/" + t.session_id, class: "mono" }, shortID(t.session_id))] : null))),
+        h("td", { class: "row" },
+          s.enabled
+            ? action("Disable", `schedules/${encodeURIComponent(s.id)}/disable`, "secondary")
+            : action("Enable", `schedules/${encodeURIComponent(s.id)}/enable`, "secondary"),
+          action("Run now", `schedules/${encodeURIComponent(s.id)}/trigger`, "", `Run "${s.name}" now?`),
+          h("button", { type: "button", class: "link", onclick: toggleHistory }, "History"),
+        ),
+      ),
+      history,
+    ];
+  });
+
+  show(
+    h("h1", {}, "Schedules"),
+    h("div", { id: "flash" }),
+    schedules.length === 0
+      ? h("div", { class: "card muted" }, "No schedules.")
+      : h("table", {},
+        h("thead", {}, h("tr", {}, h("th", {}, "Schedule"), h("th", {}, "Cron"), h("th", {}, "Status"), h("th", {}, "Last run"), h("th", {}, "Targets"), h("th", {}, ""))),
+        h("tbody", {}, rows)),
+  );
+}
+
+function executionsView(executions) {
+  if (executions.length === 0) return h("div", { class: "muted" }, "No executions yet.");
+  return h("table", {},
+    h("thead", {}, h("tr", {}, h("th", {}, "Ran"), h("th", {}, "Status"), h("th", {}, "Duration"), h("th", {}, "Session"), h("th", {}, "Output"))),
+    h("tbody", {}, executions.map((e) => h("tr", {},
+      h("td", {}, when(e.executed_at)),
+      h("td", {}, statusBadge(e.status)),
+      h("td", {}, e.duration_ms ? `${(e.duration_ms / 1000).toFixed(1)}s` : "—"),
+      h("td", {}, e.session_id ? h("a", { href: "#/sessions/" + e.session_id, class: "mono" }, shortID(e.session_id)) : "—"),
+      h("td", {}, e.error ? h("span", { class: "error" }, e.error) : truncate(e.output || "", 200)),
+    ))));
+}
+
+async function tokensView() {
+  if (!me.admin) {
+    show(h("h1", {}, "Tokens"), h("div", { class: "card muted" }, "Token management requires an admin token."));
+    return;
+  }
+  const tokens = await api("tokens");
+  const created = h("div", {});
+  const name = h("input", { placeholder: "name", required: true });
+  const scope = h("input", { placeholder: "scope, e.g. project:<id>:ro", required: true, size: 36 });
+
+  const form = h("form", {
+    class: "row",
+    onsubmit: async (ev) => {
+      ev.preventDefault();
+      try {
+        const token = await api("tokens", { method: "POST", body: JSON.stringify({ name: name.value, scope: scope.value }) });
+        await tokensView();
+        document.getElementById("token-created").replaceChildren(h("div", { class: "notice" },
+          h("div", {}, `Token "${token.name}" created. Copy it now — it cannot be shown again:`),
+          h("pre", {}, token.token_id)));
+      } catch (err) {
+        created.replaceChildren(h("p", { class: "error" }, err.message));
+      }
+    },
+  }, name, scope, h("button", { type: "submit" }, "Create token"));
+
+  const rows = tokens.map((t) => h("tr", {},
+    h("td", {}, t.name),
+    h("td", { class: "mono" }, t.masked_id),
+    h("td", { class: "mono" }, t.scope),
+    h("td", {}, when(t.created_at)),
+    h("td", {}, when(t.last_used_at)),
+    h("td", {}, h("button", {
+      type: "button",
+      class: "danger",
+      onclick: async () => {
+        if (!confirmAction(`Revoke token "${t.name}"? Clients using it will lose access.`)) return;
+        try {
+          await api("tokens/" + encodeURIComponent(t.key), { method: "DELETE" });
+          tokensView();
+        } catch (err) {
+          flash(err.message, true);
+        }
+      },
+    }, "Revoke")),
+  ));
+
+  show(
+    h("h1", {}, "Tokens"),
+    h("div", { id: "flash" }),
+    h("div", { id: "token-created" }),
+    h("div", { class: "card" }, form, created),
+    h("table", {},
+      h("thead", {}, h("tr", {}, h("th", {}, "Name"), h("th", {}, "Token"), h("th", {}, "Scope"), h("th", {}, "Created"), h("th", {}, "Last used"), h("th", {}, ""))),
+      h("tbody", {}, rows)),
+  );
+}
+
+// ---- Routing -------------------------------------------------------------------
+
+function truncate(text, n) {
+  return text.length > n ? text.slice(0, n) + "…" : text;
+}
+
+function flash(message, isError) {
+  const el = document.getElementById("flash");
+  if (el) el.replaceChildren(h("div", { class: "notice" + (isError ? " error" : "") }, message));
+}
+
+// poll re-renders a view periodically while it is shown
+function poll(render) {
+  const gen = generation;
+  const timer = setTimeout(async () => {
+    if (gen !== generation) return;
+    try {
+      stopView = await render();
+    } catch (err) {
+      if (err.status !== 401) show(errorCard(err));
+    }
+  }, POLL_MS * 4);
+  return () => clearTimeout(timer);
+}
+
+async function route() {
+  if (!me) return;
+  if (stopView) {
+    stopView();
+    stopView = null;
+  }
+  generation++;
+  const path = location.hash.replace(/^#/, "") || "/";
+  const parts = path.split("/").filter(Boolean);
+  const section = parts[0] || "projects";
+  for (const a of document.querySelectorAll("nav a")) {
+    a.classList.toggle("active", a.dataset.view === (section === "sessions" ? "projects" : section));
+  }
+  try {
+    if (section === "projects" && parts[1]) stopView = await projectView(decodeURIComponent(parts[1]));
+    else if (section === "sessions" && parts[1]) stopView = await sessionView(decodeURIComponent(parts[1]));
+    else if (section === "schedules") stopView = await schedulesView();
+    else if (section === "tokens") stopView = await tokensView();
+    else stopView = await projectsView();
+  } catch (err) {
+    if (err.status !== 401) show(errorCard(err));
+  }
+}
+
+document.addEventListener("DOMContentLoaded", () => {
+  document.getElementById("login-form").addEventListener("submit", async (ev) => {
+    ev.preventDefault();
+    const input = document.getElementById("login-token");
+    sessionStorage.setItem(TOKEN_KEY, input.value.trim());
+    input.value = "";
+    try {
+      await signIn();
+    } catch (err) {
+      if (err.status !== 401) signOut(err.message);
+    }
+  });
+  document.getElementById("sign-out").addEventListener("click", () => signOut());
+  window.addEventListener("hashchange", route);
+
+  if (sessionStorage.getItem(TOKEN_KEY)) {
+    signIn().catch((err) => {
+      if (err.status !== 401) signOut(err.message);
+    });
+  } else {
+    signOut();
+  }
+});
@@ -0,0 +1,551 @@
+// Package doctor runs setup diagnostics for Oubliette.
+//
+// Each check reports a status and, when something is wrong, a remediation,
+// so problems surface before they turn into failures deep inside a session
+// spawn.
+package doctor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	iofs "io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/cleanup"
+	"github.com/HyphaGroup/oubliette/internal/config"
+	"github.com/HyphaGroup/oubliette/internal/container"
+
+	_ "modernc.org/sqlite"
+)
+
+// Status is the outcome of a single check
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+)
+
+// Names of the checks, in the order they run
+const (
+	CheckConfig      = "config"
+	CheckCredentials = "credentials"
+	CheckDataDir     = "data_dir"
+	CheckDisk        = "disk"
+	CheckDatabases   = "databases"
+	CheckRuntime     = "runtime"
+	CheckImages      = "images"
+	CheckSockets     = "sockets"
+	CheckOrphans     = "orphaned_containers"
+)
+
+// databases are the SQLite files kept in the data directory
+var databases = []string{"auth.db", "sessions.db", "schedules.db", "audit.db"}
+
+// socketDialTimeout bounds each relay socket probe
+const socketDialTimeout = 2 * time.Second
+
+// Result is the outcome of one check
+type Result struct {
+	Name        string `json:"name"`
+	Status      Status `json:"status"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// Report collects the results of a doctor run
+type Report struct {
+	Checks []Result `json:"checks"`
+	OK     bool     `json:"ok"` // false if any check failed
+}
+
+// Options configures a doctor run
+type Options struct {
+	ConfigDir  string            // Directory holding oubliette.jsonc
+	DataDir    string            // Data directory (databases, projects)
+	SocketsDir string            // Relay socket base directory
+	Runtime    container.Runtime // Optional; runtime checks fail without one
+	Images     *container.ImageManager
+	Pull       bool // Pull missing images instead of only reporting them
+}
+
+// Run performs all checks and returns the report. Checks that depend on a
+// failed check are skipped.
+func Run(ctx context.Context, opts Options) *Report {
+	r := &Report{OK: true}
+
+	res, cfg := checkConfig(opts.ConfigDir)
+	r.add(res)
+	r.add(checkCredentials(cfg))
+	r.add(checkDataDir(opts.DataDir))
+	r.add(checkDisk(filepath.Join(opts.DataDir, "projects")))
+	r.add(checkDatabases(opts.DataDir))
+
+	if !r.add(checkRuntime(ctx, opts.Runtime)) {
+		r.skip("container runtime unavailable", CheckImages, CheckSockets, CheckOrphans)
+		return r
+	}
+	images := opts.Images
+	if images == nil && cfg != nil {
+		images = container.NewImageManager(cfg.Containers, opts.Runtime)
+	}
+	r.add(checkImages(ctx, opts.Runtime, images, opts.Pull))
+
+	projects, err := projectIDs(filepath.Join(opts.DataDir, "projects"))
+	if err != nil {
+		r.skip("cannot read projects directory: "+err.Error(), CheckSockets, CheckOrphans)
+		return r
+	}
+	r.add(checkSockets(ctx, opts.Runtime, opts.SocketsDir, projects))
+	r.add(checkOrphans(ctx, opts.Runtime, projects))
+	return r
+}
+
+// add records a result and reports whether the check passed or only warned
+func (r *Report) add(res Result) bool {
+	r.Checks = append(r.Checks, res)
+	if res.Status == StatusFail {
+		r.OK = false
+		return false
+	}
+	return true
+}
+
+// skip records the named checks as skipped
+func (r *Report) skip(reason string, names ...string) {
+	for _, name := range names {
+		r.add(skipped(name, reason))
+	}
+}
+
+// Counts returns the number of checks with each status
+func (r *Report) Counts() map[Status]int {
+	counts := make(map[Status]int)
+	for _, c := range r.Checks {
+		counts[c.Status]++
+	}
+	return counts
+}
+
+// Summary renders the report for people
+func (r *Report) Summary() string {
+	var b strings.Builder
+	for _, c := range r.Checks {
+		fmt.Fprintf(&b, "%s %-20s %s\n", statusIcon(c.Status), c.Name, c.Message)
+		if c.Remediation != "" {
+			fmt.Fprintf(&b, "   → %s\n", c.Remediation)
+		}
+	}
+	counts := r.Counts()
+	fmt.Fprintf(&b, "\n%d ok, %d warning(s), %d failure(s), %d skipped\n",
+		counts[StatusOK], counts[StatusWarn], counts[StatusFail], counts[StatusSkip])
+	return b.String()
+}
+
+func statusIcon(s Status) string {
+	switch s {
+	case StatusOK:
+		return "✅"
+	case StatusWarn:
+		return "⚠️ "
+	case StatusFail:
+		return "❌"
+	default:
+		return "⏭️ "
+	}
+}
+
+func ok(name, message string) Result {
+	return Result{Name: name, Status: StatusOK, Message: message}
+}
+
+func warn(name, message, remediation string) Result {
+	return Result{Name: name, Status: StatusWarn, Message: message, Remediation: remediation}
+}
+
+func fail(name, message, remediation string) Result {
+	return Result{Name: name, Status: StatusFail, Message: message, Remediation: remediation}
+}
+
+func skipped(name, reason string) Result {
+	return Result{Name: name, Status: StatusSkip, Message: "skipped: " + reason}
+}
+
+func checkConfig(configDir string) (Result, *config.LoadedConfig) {
+	cfg, err := config.LoadAll(configDir)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		return fail(CheckConfig, err.Error(),
+			"Fix oubliette.jsonc (see docs/CONFIGURATION.md), or run 'oubliette init' if it is missing"), nil
+	}
+	if n := len(cfg.PlaintextSecrets); n > 0 {
+		return warn(CheckConfig,
+			fmt.Sprintf("%d credential(s) stored in plaintext: %s", n, strings.Join(cfg.PlaintextSecrets, ", ")),
+			"Use env:, file:, exec: or secret: references instead (see docs/CONFIGURATION.md)"), cfg
+	}
+	return ok(CheckConfig, "loaded "+filepath.Join(cfg.ConfigDir, "oubliette.jsonc")), cfg
+}
+
+// providerKeyPrefixes are the expected API key prefixes for known providers
+var providerKeyPrefixes = map[string][]string{
+	"anthropic": {"sk-ant-"},
+	"openai":    {"sk-"},
+}
+
+// githubTokenPrefixes are the prefixes of GitHub personal, OAuth and app tokens
+var githubTokenPrefixes = []string{"ghp_", "github_pat_", "gho_", "ghu_", "ghs_", "ghr_"}
+
+func checkCredentials(cfg *config.LoadedConfig) Result {
+	if cfg == nil {
+		return skipped(CheckCredentials, "configuration did not load")
+	}
+	creds := cfg.Credentials
+	if creds == nil || len(creds.Providers.Credentials) == 0 {
+		if modelKeys(cfg.Models) > 0 {
+			return ok(CheckCredentials, "no provider credentials; models carry their own API keys")
+		}
+		return fail(CheckCredentials, "no provider credentials configured",
+			"Add an API key under credentials.providers in oubliette.jsonc")
+	}
+
+	if creds.Providers.Default != "" && !creds.HasProviderCredential(creds.Providers.Default) {
+		return fail(CheckCredentials,
+			fmt.Sprintf("default provider credential %q is not defined", creds.Providers.Default),
+			"Set credentials.providers.default to one of the configured credentials")
+	}
+	if creds.GitHub.Default != "" && !creds.HasGitHubCredential(creds.GitHub.Default) {
+		return fail(CheckCredentials,
+			fmt.Sprintf("default GitHub credential %q is not defined", creds.GitHub.Default),
+			"Set credentials.github.default to one of the configured credentials")
+	}
+	var problems []string
+	for _, name := range config.SortedKeys(creds.Providers.Credentials) {
+		cred := creds.Providers.Credentials[name]
+		if problem := keyProblem(cred.APIKey, providerKeyPrefixes[cred.Provider]); problem != "" {
+			problems = append(problems, fmt.Sprintf("provider %q: %s", name, problem))
+		}
+	}
+	for _, name := range config.SortedKeys(creds.GitHub.Credentials) {
+		if problem := keyProblem(creds.GitHub.Credentials[name].Token, githubTokenPrefixes); problem != "" {
+			problems = append(problems, fmt.Sprintf("github %q: %s", name, problem))
+		}
+	}
+	if len(problems) > 0 {
+		return warn(CheckCredentials, strings.Join(problems, "; "),
+			"Check the keys against the provider dashboards; the server only rejects them when a session starts")
+	}
+	return ok(CheckCredentials, fmt.Sprintf("%d provider and %d GitHub credential(s) well-formed",
+		len(creds.Providers.Credentials), len(creds.GitHub.Credentials)))
+}
+
+// modelKeys counts models configured with their own API key
+func modelKeys(models *config.ModelRegistry) int {
+	if models == nil {
+		return 0
+	}
+	n := 0
+	for _, m := range models.Models {
+		if m.APIKey != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// keyProblem describes what looks wrong with a key, or returns ""
+func keyProblem(key string, prefixes []string) string {
+	switch {
+	case key == "":
+		return "empty"
+	case strings.TrimSpace(key) != key || strings.ContainsAny(key, " \t\r\n"):
+		return "contains whitespace"
+	case len(prefixes) == 0:
+		return ""
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("expected a key starting with %s", strings.Join(prefixes, " or "))
+}
+
+func checkDataDir(dataDir string) Result {
+	info, err := os.Stat(dataDir)
+	if err != nil {
+		return fail(CheckDataDir, fmt.Sprintf("cannot access %s: %v", dataDir, err),
+			"Run 'oubliette init' to create the directory structure")
+	}
+	if !info.IsDir() {
+		return fail(CheckDataDir, dataDir+" is not a directory", "Move the file aside and run 'oubliette init'")
+	}
+	probe, err := os.CreateTemp(dataDir, ".doctor-*")
+	if err != nil {
+		return fail(CheckDataDir, fmt.Sprintf("%s is not writable: %v", dataDir, err),
+			"Make the directory owned by the user running oubliette: chown -R $(id -un) "+dataDir)
+	}
+	_ = probe.Close()
+	_ = os.Remove(probe.Name())
+
+	if perm := info.Mode().Perm(); perm&0o022 != 0 {
+		return warn(CheckDataDir, fmt.Sprintf("%s is writable by other users (mode %04o)", dataDir, perm),
+			"chmod go-w "+dataDir)
+	}
+	return ok(CheckDataDir, dataDir+" is writable")
+}
+
+func checkDisk(projectsDir string) Result {
+	cfg := cleanup.DefaultConfig(projectsDir)
+	used, total, percent, err := cleanup.New(cfg).DiskUsage()
+	if err != nil {
+		return fail(CheckDisk, fmt.Sprintf("cannot read disk usage for %s: %v", projectsDir, err),
+			"Run 'oubliette init' to create the projects directory")
+	}
+	message := fmt.Sprintf("%.1f%% used (%s of %s)", percent, formatBytes(used), formatBytes(total))
+	remediation := "Free space on the volume holding " + projectsDir + ": delete unused projects or prune old images"
+	switch {
+	case percent >= cfg.DiskErrorPercent:
+		return fail(CheckDisk, message, remediation)
+	case percent >= cfg.DiskWarnPercent:
+		return warn(CheckDisk, message, remediation)
+	}
+	return ok(CheckDisk, message)
+}
+
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+func checkDatabases(dataDir string) Result {
+	var checked, problems []string
+	for _, name := range databases {
+		path := filepath.Join(dataDir, name)
+		if _, err := os.Stat(path); errors.Is(err, iofs.ErrNotExist) {
+			continue
+		}
+		if err := integrityCheck(path); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		checked = append(checked, name)
+	}
+	if len(problems) > 0 {
+		return fail(CheckDatabases, strings.Join(problems, "; "),
+			"Stop the server and restore the database from a backup (see docs/OPERATIONS.md)")
+	}
+	if len(checked) == 0 {
+		return ok(CheckDatabases, "no databases yet; they are created on first start")
+	}
+	return ok(CheckDatabases, "integrity ok: "+strings.Join(checked, ", "))
+}
+
+// integrityCheck runs PRAGMA integrity_check on a database without writing to it
+func integrityCheck(path string) error {
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	rows, err := db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return fmt.Errorf("failed to run integrity check: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var messages []string
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			return fmt.Errorf("failed to read integrity check: %w", err)
+		}
+		if msg != "ok" {
+			messages = append(messages, msg)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to run integrity check: %w", err)
+	}
+	if len(messages) > 0 {
+		return errors.New(strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+func checkRuntime(ctx context.Context, rt container.Runtime) Result {
+	remediation := "Start Docker, Podman or Apple Container, or set CONTAINER_RUNTIME to the one installed"
+	if rt == nil {
+		return fail(CheckRuntime, "no container runtime found", remediation)
+	}
+	if err := rt.Ping(ctx); err != nil {
+		return fail(CheckRuntime, fmt.Sprintf("%s runtime is not responding: %v", rt.Name(), err), remediation)
+	}
+	return ok(CheckRuntime, rt.Name()+" runtime is responding")
+}
+
+func checkImages(ctx context.Context, rt container.Runtime, images *container.ImageManager, pull bool) Result {
+	if images == nil {
+		return skipped(CheckImages, "configuration did not load")
+	}
+	if pull {
+		if err := images.EnsureAllImages(ctx); err != nil {
+			return fail(CheckImages, err.Error(), "Check network access to the registry and the image names under containers")
+		}
+	}
+	var missing []string
+	types := images.ValidTypes()
+	sort.Strings(types)
+	for _, typeName := range types {
+		imageName, err := images.GetImageName(typeName)
+		if err != nil {
+			return fail(CheckImages, err.Error(), "Check the containers section of oubliette.jsonc")
+		}
+		exists, err := rt.ImageExists(ctx, imageName)
+		if err != nil {
+			return fail(CheckImages, fmt.Sprintf("cannot inspect %s: %v", imageName, err), "Check that the container runtime is healthy")
+		}
+		if !exists {
+			missing = append(missing, fmt.Sprintf("%s (%s)", imageName, typeName))
+		}
+	}
+	if len(missing) > 0 {
+		return warn(CheckImages, "not pulled yet: "+strings.Join(missing, ", "),
+			"Run 'oubliette doctor --pull', otherwise the first session of each type waits for the pull")
+	}
+	return ok(CheckImages, fmt.Sprintf("%d image(s) present", len(types)))
+}
+
+// projectIDs returns the IDs of the projects on disk
+func projectIDs(projectsDir string) (map[string]bool, error) {
+	entries, err := os.ReadDir(projectsDir)
+	if errors.Is(err, iofs.ErrNotExist) {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			ids[e.Name()] = true
+		}
+	}
+	return ids, nil
+}
+
+func checkSockets(ctx context.Context, rt container.Runtime, socketsDir string, projects map[string]bool) Result {
+	entries, err := os.ReadDir(socketsDir)
+	if errors.Is(err, iofs.ErrNotExist) {
+		return ok(CheckSockets, "no relay sockets")
+	}
+	if err != nil {
+		return fail(CheckSockets, fmt.Sprintf("cannot read %s: %v", socketsDir, err),
+			"Make "+socketsDir+" readable by the user running oubliette")
+	}
+
+	var stale, unreachable []string
+	live := 0
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		id := e.Name()
+		if strings.HasPrefix(id, container.PoolContainerPrefix) {
+			// Warm pool containers' sockets belong to the running server
+			continue
+		}
+		if !projects[id] {
+			stale = append(stale, id)
+			continue
+		}
+		socketPath := filepath.Join(socketsDir, id, "relay.sock")
+		if _, err := os.Stat(socketPath); errors.Is(err, iofs.ErrNotExist) {
+			continue
+		}
+		conn, err := net.DialTimeout("unix", socketPath, socketDialTimeout)
+		if err == nil {
+			_ = conn.Close()
+			live++
+			continue
+		}
+		if running(ctx, rt, id) {
+			unreachable = append(unreachable, id)
+		} else {
+			stale = append(stale, id)
+		}
+	}
+
+	switch {
+	case len(unreachable) > 0:
+		return fail(CheckSockets,
+			fmt.Sprintf("relay socket unreachable for running project(s): %s", strings.Join(unreachable, ", ")),
+			"Restart the server, or restart the project containers with 'oubliette container refresh <project_id>'")
+	case len(stale) > 0:
+		return warn(CheckSockets,
+			fmt.Sprintf("%d stale socket director(ies) in %s: %s", len(stale), socketsDir, strings.Join(stale, ", ")),
+			"Remove them while the server is stopped: rm -r "+filepath.Join(socketsDir, "<project_id>"))
+	}
+	return ok(CheckSockets, fmt.Sprintf("%d relay socket(s) reachable", live))
+}
+
+// running reports whether a project's container is running
+func running(ctx context.Context, rt container.Runtime, projectID string) bool {
+	name, err := container.ProjectContainerName(projectID)
+	if err != nil {
+		return false
+	}
+	status, err := rt.Status(ctx, name)
+	return err == nil && status == container.StatusRunning
+}
+
+func checkOrphans(ctx context.Context, rt container.Runtime, projects map[string]bool) Result {
+	lister, isLister := rt.(container.Lister)
+	if !isLister {
+		return skipped(CheckOrphans, rt.Name()+" runtime cannot list containers")
+	}
+	names, err := lister.ListContainers(ctx, "oubliette-")
+	if err != nil {
+		return warn(CheckOrphans, err.Error(), "Check that the container runtime is healthy")
+	}
+
+	known := make(map[string]bool, len(projects))
+	for id := range projects {
+		if name, err := container.ProjectContainerName(id); err == nil {
+			known[name] = true
+		}
+	}
+	var orphans []string
+	for _, name := range names {
+		if strings.HasPrefix(name, container.PoolContainerPrefix) || known[name] {
+			continue
+		}
+		orphans = append(orphans, name)
+	}
+	sort.Strings(orphans)
+	if len(orphans) > 0 {
+		return warn(CheckOrphans,
+			fmt.Sprintf("%d container(s) without a project: %s", len(orphans), strings.Join(orphans, ", ")),
+			"Remove them with the runtime's rm command, e.g. 'docker rm -f <name>'")
+	}
+	return ok(CheckOrphans, fmt.Sprintf("%d project container(s), none orphaned", len(names)))
+}
@@ -0,0 +1,192 @@
+package doctor
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/testutil"
+)
+
+const (
+	liveProject  = "11111111-aaaa-4aaa-8aaa-000000000001"
+	quietProject = "22222222-bbbb-4bbb-8bbb-000000000002"
+)
+
+// doctorFixture lays out a config dir, data dir and sockets dir with a
+// project whose relay is listening, one whose socket is left over, a
+// socket directory with no project at all, and a warm pool container's
+func doctorFixture(t *testing.T, providerKey string) Options {
+	t.Helper()
+	root := t.TempDir()
+	configDir := filepath.Join(root, "config")
+	dataDir := filepath.Join(root, "data")
+	for _, dir := range []string{configDir, filepath.Join(dataDir, "projects", liveProject), filepath.Join(dataDir, "projects", quietProject)} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = os.Chmod(dataDir, 0o755)
+	if err := os.WriteFile(filepath.Join(configDir, "oubliette.jsonc"), []byte(`{
+		"credentials": {
+			"providers": {"credentials": {"anthropic": {"provider": "anthropic", "api_key": "`+providerKey+`"}}, "default": "anthropic"}
+		},
+		"containers": {"dev": "oubliette-dev:latest", "osint": "oubliette-osint:latest"}
+	}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite", filepath.Join(dataDir, "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE TABLE sessions (id TEXT PRIMARY KEY)"); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+
+	// Unix socket paths are short; t.TempDir can exceed the limit
+	socketsDir, err := os.MkdirTemp("", "doctor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(socketsDir) })
+	for _, id := range []string{liveProject, quietProject, "deleted-project", "oubliette-pool-dev-0"} {
+		_ = os.MkdirAll(filepath.Join(socketsDir, id), 0o755)
+	}
+	listener, err := net.Listen("unix", filepath.Join(socketsDir, liveProject, "relay.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	_ = os.WriteFile(filepath.Join(socketsDir, quietProject, "relay.sock"), nil, 0o600)
+
+	rt := testutil.NewFakeRuntime()
+	rt.AddImage("oubliette-dev:latest")
+	for _, name := range []string{"oubliette-11111111", "oubliette-99999999", "oubliette-pool-dev-0"} {
+		if _, err := rt.Create(context.Background(), container.CreateConfig{Name: name, Image: "oubliette-dev:latest"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return Options{ConfigDir: configDir, DataDir: dataDir, SocketsDir: socketsDir, Runtime: rt}
+}
+
+func checkByName(r *Report, name string) Result {
+	for _, c := range r.Checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	return Result{}
+}
+
+func TestRun(t *testing.T) {
+	opts := doctorFixture(t, "sk-ant-0123456789abcdef")
+	report := Run(context.Background(), opts)
+
+	want := map[string]Status{
+		CheckConfig:      StatusWarn, // plaintext API key
+		CheckCredentials: StatusOK,
+		CheckDataDir:     StatusOK,
+		CheckDatabases:   StatusOK,
+		CheckRuntime:     StatusOK,
+		CheckImages:      StatusWarn,
+		CheckSockets:     StatusWarn,
+		CheckOrphans:     StatusWarn,
+	}
+	for name, status := range want {
+		if got := checkByName(report, name); got.Status != status {
+			t.Errorf("%s = %s (%s), want %s", name, got.Status, got.Message, status)
+		}
+	}
+	if len(report.Checks) != 9 {
+		t.Errorf("ran %d checks, want 9", len(report.Checks))
+	}
+
+	if got := checkByName(report, CheckImages); !strings.Contains(got.Message, "oubliette-osint:latest") || strings.Contains(got.Message, "oubliette-dev") {
+		t.Errorf("images message = %q, want only the osint image missing", got.Message)
+	}
+	sockets := checkByName(report, CheckSockets)
+	if !strings.Contains(sockets.Message, "deleted-project") || !strings.Contains(sockets.Message, quietProject) || strings.Contains(sockets.Message, liveProject) ||
+		strings.Contains(sockets.Message, "oubliette-pool-") {
+		t.Errorf("sockets message = %q", sockets.Message)
+	}
+	if got := checkByName(report, CheckOrphans); got.Message != "1 container(s) without a project: oubliette-99999999" {
+		t.Errorf("orphans message = %q", got.Message)
+	}
+	for _, c := range report.Checks {
+		if c.Status == StatusWarn && c.Remediation == "" {
+			t.Errorf("%s warns without a remediation", c.Name)
+		}
+	}
+
+	t.Run("pull", func(t *testing.T) {
+		opts.Pull = true
+		if got := checkByName(Run(context.Background(), opts), CheckImages); got.Status != StatusOK {
+			t.Errorf("images after pull = %s (%s)", got.Status, got.Message)
+		}
+	})
+}
+
+func TestRun_Failures(t *testing.T) {
+	opts := doctorFixture(t, " sk-ant-0123456789abcdef")
+	if err := os.WriteFile(filepath.Join(opts.DataDir, "audit.db"), []byte("not a database"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	opts.Runtime = nil
+
+	report := Run(context.Background(), opts)
+	if report.OK {
+		t.Error("report OK with failing checks")
+	}
+	want := map[string]Status{
+		CheckCredentials: StatusWarn,
+		CheckDatabases:   StatusFail,
+		CheckRuntime:     StatusFail,
+		CheckImages:      StatusSkip,
+		CheckSockets:     StatusSkip,
+		CheckOrphans:     StatusSkip,
+	}
+	for name, status := range want {
+		if got := checkByName(report, name); got.Status != status {
+			t.Errorf("%s = %s (%s), want %s", name, got.Status, got.Message, status)
+		}
+	}
+
+	t.Run("missing config", func(t *testing.T) {
+		report := Run(context.Background(), Options{ConfigDir: t.TempDir(), DataDir: opts.DataDir})
+		if got := checkByName(report, CheckConfig); got.Status != StatusFail || got.Remediation == "" {
+			t.Errorf("config = %+v, want a failure with a remediation", got)
+		}
+		if got := checkByName(report, CheckCredentials); got.Status != StatusSkip {
+			t.Errorf("credentials = %s, want skipped", got.Status)
+		}
+	})
+}
+
+func TestKeyProblem(t *testing.T) {
+	tests := []struct {
+		key      string
+		prefixes []string
+		want     string
+	}{
+		{"sk-ant-abc", providerKeyPrefixes["anthropic"], ""},
+		{"ghp_abc", githubTokenPrefixes, ""},
+		{"github_pat_abc", githubTokenPrefixes, ""},
+		{"anything", nil, ""},
+		{"", nil, "empty"},
+		{"sk-ant-abc\n", providerKeyPrefixes["anthropic"], "contains whitespace"},
+		{"sk-proj-abc", providerKeyPrefixes["anthropic"], "expected a key starting with sk-ant-"},
+	}
+	for _, tt := range tests {
+		if got := keyProblem(tt.key, tt.prefixes); got != tt.want {
+			t.Errorf("keyProblem(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
+	"github.com/google/uuid"
+)
+
+// Attachment delivery limits
+const (
+	attachmentsDir          = ".oubliette/attachments"
+	maxAttachments          = 10
+	maxAttachmentSize       = 10 << 20 // per attachment
+	maxAttachmentsTotalSize = 25 << 20 // per message
+	maxImagePartSize        = 5 << 20  // larger images are written to the workspace only
+	attachmentFetchTimeout  = 30 * time.Second
+)
+
+// imageAttachmentTypes are the image types sent to the model as file parts
+var imageAttachmentTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// textAttachmentTypes are accepted in addition to text/*
+var textAttachmentTypes = map[string]bool{
+	"application/json":   true,
+	"application/xml":    true,
+	"application/yaml":   true,
+	"application/x-yaml": true,
+}
+
+// attachmentHTTPClient fetches URL attachments. It refuses to dial loopback,
+// private and link-local addresses so attachments cannot reach internal services.
+var attachmentHTTPClient = &http.Client{
+	Timeout: attachmentFetchTimeout,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: refuseInternalAddress,
+		}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 3 {
+			return fmt.Errorf("too many redirects")
+		}
+		return nil
+	},
+}
+
+func refuseInternalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return fmt.Errorf("address %s is not allowed for attachments", host)
+	}
+	return nil
+}
+
+// loadedAttachment is a decoded and checked attachment ready to be written
+type loadedAttachment struct {
+	filename    string
+	contentType string
+	data        []byte
+}
+
+// loadAttachments decodes or fetches each attachment and checks its size and
+// content type. It runs before any session work so bad input fails fast.
+func loadAttachments(ctx context.Context, attachments []Attachment) ([]loadedAttachment, error) {
+	if len(attachments) > maxAttachments {
+		return nil, fmt.Errorf("too many attachments: %d (max %d)", len(attachments), maxAttachments)
+	}
+
+	var loaded []loadedAttachment
+	var total int
+	names := map[string]bool{}
+	for i, a := range attachments {
+		data, headerType, err := readAttachment(ctx, a)
+		if err != nil {
+			return nil, fmt.Errorf("attachment %d: %w", i+1, err)
+		}
+		if a.Size > 0 && a.Size != int64(len(data)) {
+			return nil, fmt.Errorf("attachment %d: size is %d bytes, declared %d", i+1, len(data), a.Size)
+		}
+		total += len(data)
+		if total > maxAttachmentsTotalSize {
+			return nil, fmt.Errorf("attachments exceed %d MiB in total", maxAttachmentsTotalSize>>20)
+		}
+
+		declared := a.ContentType
+		if declared == "" {
+			declared = headerType
+		}
+		contentType, err := checkAttachmentType(declared, data)
+		if err != nil {
+			return nil, fmt.Errorf("attachment %d: %w", i+1, err)
+		}
+
+		name := attachmentFilename(a, i, contentType)
+		for n := 2; names[name]; n++ {
+			ext := path.Ext(name)
+			name = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), n, ext)
+		}
+		names[name] = true
+
+		loaded = append(loaded, loadedAttachment{filename: name, contentType: contentType, data: data})
+	}
+	return loaded, nil
+}
+
+// readAttachment returns the attachment content and, for URLs, the response Content-Type
+func readAttachment(ctx context.Context, a Attachment) ([]byte, string, error) {
+	switch {
+	case a.Data != "" && a.URL != "":
+		return nil, "", fmt.Errorf("set either data or url, not both")
+	case a.Data != "":
+		if base64.StdEncoding.DecodedLen(len(a.Data)) > maxAttachmentSize+2 {
+			return nil, "", fmt.Errorf("larger than %d MiB", maxAttachmentSize>>20)
+		}
+		data, err := base64.StdEncoding.DecodeString(a.Data)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid base64 data: %w", err)
+		}
+		if len(data) > maxAttachmentSize {
+			return nil, "", fmt.Errorf("larger than %d MiB", maxAttachmentSize>>20)
+		}
+		return data, "", nil
+	case a.URL != "":
+		return fetchAttachment(ctx, a.URL)
+	default:
+		return nil, "", fmt.Errorf("data or url is required")
+	}
+}
+
+func fetchAttachment(ctx context.Context, rawURL string) ([]byte, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, "", fmt.Errorf("url must be an absolute http or https URL")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := attachmentHTTPClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", u.Redacted(), err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to fetch %s: %s", u.Redacted(), resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAttachmentSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", u.Redacted(), err)
+	}
+	if len(data) > maxAttachmentSize {
+		return nil, "", fmt.Errorf("larger than %d MiB", maxAttachmentSize>>20)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// checkAttachmentType resolves the attachment's content type and checks it is
+// allowed and matches the sniffed content. An empty declared type is sniffed.
+func checkAttachmentType(declared string, data []byte) (string, error) {
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+
+	contentType := sniffed
+	if declared != "" {
+		mt, _, err := mime.ParseMediaType(declared)
+		if err != nil {
+			return "", fmt.Errorf("invalid content type %q", declared)
+		}
+		contentType = mt
+	}
+
+	switch {
+	case imageAttachmentTypes[contentType], contentType == "application/pdf":
+		if sniffed != contentType {
+			return "", fmt.Errorf("content does not look like %s", contentType)
+		}
+	case strings.HasPrefix(contentType, "text/"), textAttachmentTypes[contentType]:
+		if !strings.HasPrefix(sniffed, "text/") {
+			return "", fmt.Errorf("content does not look like text")
+		}
+	default:
+		return "", fmt.Errorf("unsupported content type %q", contentType)
+	}
+	return contentType, nil
+}
+
+// attachmentFilename returns a safe file name for the attachment, falling back
+// to the URL path or a generated name
+func attachmentFilename(a Attachment, index int, contentType string) string {
+	name := a.Filename
+	if name == "" && a.URL != "" {
+		if u, err := url.Parse(a.URL); err == nil {
+			name = path.Base(u.Path)
+		}
+	}
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	name = strings.TrimLeft(name, ".")
+	if len(name) > 100 {
+		name = name[len(name)-100:]
+	}
+
+	if name == "" || name == "_" {
+		name = fmt.Sprintf("attachment-%d", index+1)
+		if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+	return name
+}
+
+// deliverAttachments writes attachments to .oubliette/attachments/<turn>/ in
+// the workspace. It returns a prompt note listing the files and the image file
+// parts for the model.
+func (s *Server) deliverAttachments(projectID, workspaceID string, attachments []loadedAttachment) (string, []agent.FilePart, error) {
+	if len(attachments) == 0 {
+		return "", nil, nil
+	}
+
+	// The workspace is writable by the agent, so open it as a root to keep
+	// symlinks from redirecting writes outside it
+	root, err := os.OpenRoot(s.projectMgr.GetWorkspacePath(projectID, workspaceID))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open workspace: %w", err)
+	}
+	defer func() { _ = root.Close() }()
+
+	turn := time.Now().UTC().Format("20060102T150405Z") + "-" + uuid.New().String()[:8]
+	dir := path.Join(attachmentsDir, turn)
+	var parent string
+	for _, elem := range strings.Split(dir, "/") {
+		parent = path.Join(parent, elem)
+		if err := root.Mkdir(parent, 0o755); err != nil && !errors.Is(err, fs.ErrExist) {
+			return "", nil, fmt.Errorf("failed to create attachments directory: %w", err)
+		}
+	}
+
+	var note strings.Builder
+	note.WriteString("\n\nAttached files (relative to the workspace):\n")
+	var files []agent.FilePart
+	for _, a := range attachments {
+		rel := path.Join(dir, a.filename)
+		if err := writeRootFile(root, rel, a.data); err != nil {
+			return "", nil, fmt.Errorf("failed to write attachment %s: %w", a.filename, err)
+		}
+		fmt.Fprintf(&note, "- %s (%s, %d bytes)\n", rel, a.contentType, len(a.data))
+
+		if imageAttachmentTypes[a.contentType] && len(a.data) <= maxImagePartSize {
+			files = append(files, agent.FilePart{
+				Mime:     a.contentType,
+				Filename: a.filename,
+				URL:      "data:" + a.contentType + ";base64," + base64.StdEncoding.EncodeToString(a.data),
+			})
+		}
+	}
+	if len(files) > 0 {
+		note.WriteString("Images are also included in this message.\n")
+	}
+	return note.String(), files, nil
+}
+
+func writeRootFile(root *os.Root, name string, data []byte) error {
+	f, err := root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
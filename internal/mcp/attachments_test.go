@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// pngHeader is enough of a PNG for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func b64(data []byte) string { return base64.StdEncoding.EncodeToString(data) }
+
+func TestLoadAttachments(t *testing.T) {
+	tests := []struct {
+		name        string
+		attachments []Attachment
+		wantErr     string
+		wantName    string
+		wantType    string
+	}{
+		{name: "image", attachments: []Attachment{{Filename: "screen shot.png", ContentType: "image/png", Data: b64(pngHeader)}}, wantName: "screen_shot.png", wantType: "image/png"},
+		{name: "sniffed text", attachments: []Attachment{{Filename: "../../etc/app.log", Data: b64([]byte("ERROR boom\n"))}}, wantName: "app.log", wantType: "text/plain"},
+		{name: "json", attachments: []Attachment{{ContentType: "application/json; charset=utf-8", Data: b64([]byte(`{"a":1}`))}}, wantName: "attachment-1.json", wantType: "application/json"},
+		{name: "mislabelled image", attachments: []Attachment{{ContentType: "image/png", Data: b64([]byte("not a png"))}}, wantErr: "does not look like image/png"},
+		{name: "binary as text", attachments: []Attachment{{ContentType: "text/plain", Data: b64(pngHeader)}}, wantErr: "does not look like text"},
+		{name: "unsupported type", attachments: []Attachment{{ContentType: "application/x-sh", Data: b64([]byte("echo hi"))}}, wantErr: "unsupported content type"},
+		{name: "size mismatch", attachments: []Attachment{{Data: b64([]byte("abc")), Size: 4}}, wantErr: "declared 4"},
+		{name: "bad base64", attachments: []Attachment{{Data: "%%%"}}, wantErr: "invalid base64"},
+		{name: "no content", attachments: []Attachment{{Filename: "a.txt"}}, wantErr: "data or url is required"},
+		{name: "too large", attachments: []Attachment{{Data: b64(make([]byte, maxAttachmentSize+1))}}, wantErr: "larger than"},
+		{name: "too many", attachments: make([]Attachment, maxAttachments+1), wantErr: "too many attachments"},
+		{name: "loopback url", attachments: []Attachment{{URL: "http://127.0.0.1:1/secret"}}, wantErr: "not allowed"},
+		{name: "file url", attachments: []Attachment{{URL: "file:///etc/passwd"}}, wantErr: "http or https"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loaded, err := loadAttachments(context.Background(), tt.attachments)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadAttachments() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadAttachments() error = %v", err)
+			}
+			if loaded[0].filename != tt.wantName || loaded[0].contentType != tt.wantType {
+				t.Errorf("loaded = %s (%s), want %s (%s)", loaded[0].filename, loaded[0].contentType, tt.wantName, tt.wantType)
+			}
+		})
+	}
+
+	t.Run("duplicate names", func(t *testing.T) {
+		a := Attachment{Filename: "log.txt", Data: b64([]byte("x"))}
+		loaded, err := loadAttachments(context.Background(), []Attachment{a, a})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded[0].filename != "log.txt" || loaded[1].filename != "log-2.txt" {
+			t.Errorf("names = %s, %s", loaded[0].filename, loaded[1].filename)
+		}
+	})
+}
+
+func TestDeliverAttachments(t *testing.T) {
+	s, projectID := newPromptTestServer(t)
+	workspaceID := "550e8400-e29b-41d4-a716-446655440001"
+	workspace := s.projectMgr.GetWorkspacePath(projectID, workspaceID)
+	if err := os.MkdirAll(workspace, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := loadAttachments(context.Background(), []Attachment{
+		{Filename: "screen.png", Data: b64(pngHeader)},
+		{Filename: "spec.md", ContentType: "text/markdown", Data: b64([]byte("# Spec"))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	note, files, err := s.deliverAttachments(projectID, workspaceID, loaded)
+	if err != nil {
+		t.Fatalf("deliverAttachments() error = %v", err)
+	}
+	if len(files) != 1 || files[0].Mime != "image/png" || !strings.HasPrefix(files[0].URL, "data:image/png;base64,") {
+		t.Errorf("files = %+v, want the image as a data URL", files)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(workspace, attachmentsDir, "*", "spec.md"))
+	if len(matches) != 1 {
+		t.Fatalf("spec.md not written to the workspace: %v", matches)
+	}
+	if data, _ := os.ReadFile(matches[0]); string(data) != "# Spec" {
+		t.Errorf("spec.md = %q", data)
+	}
+	rel, _ := filepath.Rel(workspace, matches[0])
+	if !strings.Contains(note, rel+" (text/markdown, 6 bytes)") || !strings.Contains(note, "screen.png (image/png") {
+		t.Errorf("prompt note = %q", note)
+	}
+
+	t.Run("symlink escape", func(t *testing.T) {
+		outside := t.TempDir()
+		if err := os.RemoveAll(filepath.Join(workspace, ".oubliette")); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(outside, filepath.Join(workspace, ".oubliette")); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := s.deliverAttachments(projectID, workspaceID, loaded); err == nil {
+			t.Error("deliverAttachments() followed a symlink out of the workspace")
+		}
+		if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+			t.Errorf("files written outside the workspace: %v", entries)
+		}
+	})
+}
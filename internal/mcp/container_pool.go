@@ -0,0 +1,185 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
+	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/logger"
+	"github.com/HyphaGroup/oubliette/internal/project"
+)
+
+// poolContainerEnv marks containers created by the pool. Their mounts name the
+// pool slot they were warmed in, so they are recreated rather than restarted.
+const poolContainerEnv = "OUBLIETTE_POOL=1"
+
+// poolWarmer implements container.PoolWarmer for project containers. Pool
+// containers use the non-isolated project layout rooted at a slot directory
+// and run without a relay until they are bound to a project.
+type poolWarmer struct {
+	s   *Server
+	dir string // Parent of the slot directories; same filesystem as projects
+}
+
+// Warm creates, starts and health-checks a pool container
+func (w *poolWarmer) Warm(ctx context.Context, containerType, name string) (*container.WarmContainer, error) {
+	s := w.s
+	if s.imageManager == nil {
+		return nil, fmt.Errorf("image manager not configured")
+	}
+	imageName, err := s.imageManager.GetImageName(containerType)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := s.runtime.ImageExists(ctx, imageName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check image %s: %w", imageName, err)
+	}
+	if !exists {
+		if err := s.runtime.Pull(ctx, imageName); err != nil {
+			return nil, fmt.Errorf("failed to pull image %s: %w", imageName, err)
+		}
+	}
+
+	// Clear out anything left in this slot by a previous run
+	_ = s.runtime.Stop(ctx, name)
+	_ = s.runtime.Remove(ctx, name, true)
+
+	slotDir := filepath.Join(w.dir, name)
+	if _, err := os.Stat(project.AdoptMarkerPath(slotDir)); err == nil {
+		return nil, fmt.Errorf("slot %s holds files from an interrupted project bind; see %s", slotDir, project.AdoptMarkerPath(slotDir))
+	}
+	if err := os.RemoveAll(slotDir); err != nil {
+		return nil, fmt.Errorf("failed to reset slot directory: %w", err)
+	}
+	ensureMountDirs(slotDir)
+
+	socketDir := filepath.Join(SocketsBaseDir, name)
+	_ = os.RemoveAll(socketDir)
+	if err := os.MkdirAll(socketDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	wc := &container.WarmContainer{
+		Image:     imageName,
+		Name:      name,
+		Dir:       slotDir,
+		SocketDir: socketDir,
+	}
+
+	cfg := s.containerConfig(name, imageName, slotDir, slotDir, filepath.Join(socketDir, "relay.sock"), false)
+	cfg.Env = append(cfg.Env, poolContainerEnv)
+	wc.ID, err = s.runtime.Create(ctx, cfg)
+	if err != nil {
+		w.Discard(ctx, wc)
+		return nil, fmt.Errorf("failed to create container: %w", err)
+	}
+	if err := s.runtime.Start(ctx, wc.ID); err != nil {
+		w.Discard(ctx, wc)
+		return nil, fmt.Errorf("failed to start container: %w", err)
+	}
+
+	if warmer, ok := s.agentRuntime.(agent.Warmer); ok {
+		if err := warmer.Warm(ctx, wc.ID, "/workspace"); err != nil {
+			w.Discard(ctx, wc)
+			return nil, fmt.Errorf("agent server did not become healthy: %w", err)
+		}
+	}
+
+	wc.WarmedAt = time.Now()
+	return wc, nil
+}
+
+// Discard removes a pool container and its slot. A slot marked as being
+// adopted may hold project files and is left for manual recovery.
+func (w *poolWarmer) Discard(ctx context.Context, wc *container.WarmContainer) {
+	s := w.s
+	if wc.ID != "" {
+		if warmer, ok := s.agentRuntime.(agent.Warmer); ok {
+			warmer.StopServer(wc.ID)
+		}
+		if err := s.runtime.Remove(ctx, wc.ID, true); err != nil {
+			logger.Error("Failed to remove pool container %s: %v", wc.Name, err)
+		}
+	}
+
+	if _, err := os.Stat(project.AdoptMarkerPath(wc.Dir)); !errors.Is(err, fs.ErrNotExist) {
+		logger.Error("Keeping pool slot %s: it may hold project files (see %s)", wc.Dir, project.AdoptMarkerPath(wc.Dir))
+	} else {
+		_ = os.RemoveAll(wc.Dir)
+	}
+	_ = os.RemoveAll(wc.SocketDir)
+}
+
+// claimWarmContainer binds a warm pool container to a project and returns its
+// ID, or "" if the project should get a freshly created container instead.
+func (s *Server) claimWarmContainer(ctx context.Context, proj *project.Project, containerName, imageName string) string {
+	// Pool containers only have the plain layout and the type's base image
+	if s.pool == nil || proj.WorkspaceIsolation || proj.HasDockerfile {
+		return ""
+	}
+
+	wc := s.pool.Claim(proj.ContainerType, imageName)
+	if wc == nil {
+		return ""
+	}
+	defer s.pool.Release(wc)
+
+	if err := s.bindWarmContainer(ctx, wc, proj.ID, containerName); err != nil {
+		logger.Error("Failed to bind pool container %s to project %s: %v", wc.Name, proj.ID, err)
+		s.poolWarmer.Discard(ctx, wc)
+		return ""
+	}
+
+	logger.Info("Bound warm %s container %s to project %s (warmed %s ago)",
+		wc.Type, wc.ID[:12], proj.ID, time.Since(wc.WarmedAt).Round(time.Second))
+	return wc.ID
+}
+
+// bindWarmContainer hands a pool container over to a project: the slot becomes
+// the project directory, the socket directory moves to the project's socket
+// path, the container takes the project's name and the relay is started.
+func (s *Server) bindWarmContainer(ctx context.Context, wc *container.WarmContainer, projectID, containerName string) error {
+	_ = s.runtime.Stop(ctx, containerName)
+	_ = s.runtime.Remove(ctx, containerName, true)
+
+	if err := s.projectMgr.AdoptDirectory(projectID, wc.Dir); err != nil {
+		return fmt.Errorf("failed to move project files: %w", err)
+	}
+
+	if err := CleanupSocketDir(projectID); err != nil {
+		return fmt.Errorf("failed to clear socket directory: %w", err)
+	}
+	if err := os.Rename(wc.SocketDir, SocketDir(projectID)); err != nil {
+		return fmt.Errorf("failed to move socket directory: %w", err)
+	}
+
+	if err := s.runtime.Rename(ctx, wc.ID, containerName); err != nil {
+		return fmt.Errorf("failed to rename container: %w", err)
+	}
+
+	return s.startRelay(ctx, wc.ID, projectID)
+}
+
+// startRelay starts the relay in a pool container being bound to a project
+func (s *Server) startRelay(ctx context.Context, containerID, projectID string) error {
+	result, err := s.runtime.Exec(ctx, containerID, container.ExecConfig{
+		Cmd:          []string{"sh", "-c", "nohup /usr/local/bin/oubliette-relay > /tmp/oubliette-relay.log 2>&1 &"},
+		Env:          []string{"OUBLIETTE_PROJECT_ID=" + projectID},
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start relay: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("failed to start relay: %s", result.Stderr)
+	}
+	return nil
+}
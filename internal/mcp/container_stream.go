@@ -0,0 +1,358 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/audit"
+	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/logger"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// streamFlushInterval is how often buffered output is sent to the client
+	streamFlushInterval = 250 * time.Millisecond
+	// streamChunkBytes sends a chunk early once this much output is buffered
+	streamChunkBytes = 8 * 1024
+	// streamResultBytes is how much of each stream is kept for the tool result
+	streamResultBytes = 64 * 1024
+
+	defaultStreamExecTimeout = 30 * time.Minute
+	defaultLogFollowTimeout  = 10 * time.Minute
+	maxStreamTimeout         = 4 * time.Hour
+
+	// streamKillGrace is how long a timed-out command gets between SIGTERM and SIGKILL
+	streamKillGrace = 10 * time.Second
+)
+
+// progressStream sends command output to the calling MCP client as progress
+// notifications while keeping the tail of each stream for the tool result.
+// Clients that send no progress token only get the result.
+type progressStream struct {
+	ctx     context.Context
+	session *mcp.ServerSession
+	token   any
+
+	mu      sync.Mutex
+	seq     int
+	pending map[string][]byte
+	order   []string // streams in first-write order, so flushes are stable
+	tails   map[string]*tailBuffer
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newProgressStream(ctx context.Context, request *mcp.CallToolRequest) *progressStream {
+	p := &progressStream{
+		ctx:     ctx,
+		pending: make(map[string][]byte),
+		tails:   make(map[string]*tailBuffer),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if request != nil && request.Session != nil && request.Params != nil {
+		if token := request.Params.GetProgressToken(); token != nil {
+			p.session = request.Session
+			p.token = token
+		}
+	}
+
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(streamFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.flush()
+			}
+		}
+	}()
+	return p
+}
+
+// Writer returns a writer for one named stream (stdout, stderr, logs)
+func (p *progressStream) Writer(stream string) io.Writer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.tails[stream]; !ok {
+		p.tails[stream] = &tailBuffer{limit: streamResultBytes}
+		p.order = append(p.order, stream)
+	}
+	return &streamWriter{p: p, stream: stream}
+}
+
+// Close stops the flusher and sends any remaining output
+func (p *progressStream) Close() {
+	close(p.stop)
+	<-p.done
+	p.flush()
+}
+
+// Output returns the kept tail of a stream
+func (p *progressStream) Output(stream string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if t, ok := p.tails[stream]; ok {
+		return t.String()
+	}
+	return ""
+}
+
+func (p *progressStream) write(stream string, data []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tails[stream].Write(data)
+	if p.session == nil {
+		return
+	}
+	p.pending[stream] = append(p.pending[stream], data...)
+	if len(p.pending[stream]) >= streamChunkBytes {
+		p.send(stream)
+	}
+}
+
+func (p *progressStream) flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, stream := range p.order {
+		p.send(stream)
+	}
+}
+
+// send notifies the client of a stream's pending output. Caller holds p.mu,
+// which keeps chunks in order.
+func (p *progressStream) send(stream string) {
+	chunk := p.pending[stream]
+	if len(chunk) == 0 || p.session == nil {
+		return
+	}
+	p.pending[stream] = nil
+	p.seq++
+	err := p.session.NotifyProgress(p.ctx, &mcp.ProgressNotificationParams{
+		Meta:          mcp.Meta{"stream": stream},
+		ProgressToken: p.token,
+		Progress:      float64(p.seq),
+		Message:       string(chunk),
+	})
+	if err != nil && p.ctx.Err() == nil {
+		logger.Error("Failed to send %s progress notification: %v", stream, err)
+	}
+}
+
+type streamWriter struct {
+	p      *progressStream
+	stream string
+}
+
+func (w *streamWriter) Write(data []byte) (int, error) {
+	w.p.write(w.stream, data)
+	return len(data), nil
+}
+
+// tailBuffer keeps the last limit bytes written to it
+type tailBuffer struct {
+	limit     int
+	data      []byte
+	truncated bool
+}
+
+func (t *tailBuffer) Write(p []byte) {
+	t.data = append(t.data, p...)
+	if over := len(t.data) - t.limit; over > 0 {
+		t.data = t.data[over:]
+		t.truncated = true
+	}
+}
+
+func (t *tailBuffer) String() string {
+	if t.truncated {
+		return fmt.Sprintf("[earlier output truncated; last %d bytes shown]\n%s", t.limit, t.data)
+	}
+	return string(t.data)
+}
+
+// streamTimeout resolves a requested timeout in seconds
+func streamTimeout(seconds int, fallback time.Duration) time.Duration {
+	if seconds <= 0 {
+		return fallback
+	}
+	return min(time.Duration(seconds)*time.Second, maxStreamTimeout)
+}
+
+// streamContainerExec runs a command with ExecInteractive and streams its
+// output to the client as it is produced. The command runs under coreutils
+// timeout so it dies with its whole process group on timeout or cancellation.
+func (s *Server) streamContainerExec(ctx context.Context, request *mcp.CallToolRequest, params *ContainerParams, containerName string) (*mcp.CallToolResult, any, error) {
+	authCtx, err := requireProjectAccess(ctx, params.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !authCtx.CanWriteProject(params.ProjectID) {
+		return nil, nil, forbidden("read-only access, cannot exec in container")
+	}
+
+	timeout := streamTimeout(params.TimeoutSeconds, defaultStreamExecTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// The marker is the script's $0, so the command can be found to kill it
+	marker := execMarker("exec")
+
+	started := time.Now()
+	ie, err := s.runtime.ExecInteractive(ctx, containerName, container.ExecConfig{
+		Cmd: []string{
+			"timeout", "-k", fmt.Sprintf("%d", int(streamKillGrace.Seconds())), fmt.Sprintf("%d", int(timeout.Seconds())),
+			"/bin/bash", "-c", params.Command, marker,
+		},
+		WorkingDir: params.WorkingDir,
+	})
+	if err != nil {
+		logAudit(ctx, &audit.Event{
+			Operation: audit.OpContainerExec,
+			ProjectID: params.ProjectID,
+			Details:   map[string]interface{}{"command": params.Command, "working_dir": params.WorkingDir, "stream": true},
+		}, err)
+		return nil, nil, fmt.Errorf("failed to start command: %w", err)
+	}
+	defer func() { _ = ie.Close() }()
+	_ = ie.Stdin.Close()
+
+	// A running command keeps the container awake
+	running := make(chan struct{})
+	defer close(running)
+	s.idle.KeepAwake(params.ProjectID, running)
+
+	stream := newProgressStream(ctx, request)
+	var readers sync.WaitGroup
+	readers.Add(2)
+	go func() { defer readers.Done(); _, _ = io.Copy(stream.Writer("stdout"), ie.Stdout) }()
+	go func() { defer readers.Done(); _, _ = io.Copy(stream.Writer("stderr"), ie.Stderr) }()
+	drained := make(chan struct{})
+	go func() { readers.Wait(); close(drained) }()
+
+	// Output ends when the command exits; on timeout or cancellation the command
+	// is killed and the streams are closed so the readers return
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		s.signalExec(containerName, "TERM", marker)
+		_ = ie.Close()
+		<-drained
+	}
+	exitCode, waitErr := ie.Wait()
+	stream.Close()
+
+	// timeout exits 124 when it had to stop the command
+	timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded) || exitCode == 124
+	event := &audit.Event{
+		Operation: audit.OpContainerExec,
+		ProjectID: params.ProjectID,
+		Details: map[string]interface{}{
+			"command":     params.Command,
+			"working_dir": params.WorkingDir,
+			"stream":      true,
+			"exit_code":   exitCode,
+			"duration_ms": time.Since(started).Milliseconds(),
+		},
+	}
+	if timedOut {
+		event.Details["timed_out"] = true
+	}
+	logAudit(ctx, event, waitErr)
+
+	output := stream.Output("stdout")
+	if stderr := stream.Output("stderr"); stderr != "" {
+		output += "\nSTDERR:\n" + stderr
+	}
+
+	var status string
+	switch {
+	case timedOut:
+		status = fmt.Sprintf("Command timed out after %s", timeout)
+	case ctx.Err() != nil:
+		status = "Command cancelled"
+	case waitErr != nil:
+		status = fmt.Sprintf("Command failed: %v", waitErr)
+	default:
+		status = fmt.Sprintf("Exit code: %d", exitCode)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output + "\n" + status},
+		},
+	}, nil, nil
+}
+
+// signalExec signals the processes in a container whose command line holds
+// marker. Runtimes don't reliably stop an exec when its caller goes away, so
+// streamed commands and terminals are stopped this way. A streamed command's
+// signal goes to timeout, which passes it on to the command's process group.
+func (s *Server) signalExec(containerName, signal, marker string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := s.runtime.Exec(ctx, containerName, container.ExecConfig{
+		Cmd: []string{"pkill", "-" + signal, "-f", marker},
+	})
+	if err != nil {
+		logger.Error("Failed to signal %s in %s: %v", marker, containerName, err)
+	}
+}
+
+// execMarker returns a unique name to tag an exec's command line with
+func execMarker(kind string) string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("oubliette-%s-%s", kind, hex.EncodeToString(b))
+}
+
+// followContainerLogs streams a container's logs to the client until the
+// container stops, the timeout passes or the call is cancelled
+func (s *Server) followContainerLogs(ctx context.Context, request *mcp.CallToolRequest, params *ContainerParams, containerName string) (*mcp.CallToolResult, any, error) {
+	if _, err := requireProjectAccess(ctx, params.ProjectID); err != nil {
+		return nil, nil, err
+	}
+
+	timeout := streamTimeout(params.TimeoutSeconds, defaultLogFollowTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stream := newProgressStream(ctx, request)
+	w := stream.Writer("logs")
+	err := s.runtime.FollowLogs(ctx, containerName, container.LogsOptions{Tail: "100"}, w)
+	stream.Close()
+
+	var status string
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		status = fmt.Sprintf("Stopped following logs after %s", timeout)
+	case errors.Is(err, context.Canceled):
+		status = "Stopped following logs: cancelled"
+	case err != nil:
+		return nil, nil, err
+	default:
+		status = "Container stopped"
+	}
+
+	output := stream.Output("logs")
+	if !strings.HasSuffix(output, "\n") && output != "" {
+		output += "\n"
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output + status},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,214 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/auth"
+	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/testutil"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const streamTestProjectID = "550e8400-e29b-41d4-a716-446655440000"
+
+// newStreamTestClient serves the container tool over in-memory transports
+// backed by a fake runtime with a running project container. Calls are made
+// with the given token scope.
+func newStreamTestClient(t *testing.T, scope string, cmd []string, progress func(*mcp.ProgressNotificationParams)) (*mcp.ClientSession, *testutil.FakeRuntime) {
+	t.Helper()
+	rt := testutil.NewFakeRuntime()
+	t.Cleanup(func() { _ = rt.Close() })
+	ctx := context.Background()
+	id, err := rt.Create(ctx, container.CreateConfig{Name: testContainerName(t, streamTestProjectID), Image: "oubliette-base:latest", Cmd: cmd})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := rt.Start(ctx, id); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	s := &Server{runtime: rt}
+	server := mcp.NewServer(&mcp.Implementation{Name: "oubliette-test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "container"}, func(ctx context.Context, request *mcp.CallToolRequest, params ContainerParams) (*mcp.CallToolResult, any, error) {
+		ctx = auth.WithContext(ctx, &auth.AuthContext{Type: auth.AuthTypeToken, Token: &auth.Token{ID: "oub_stream-test", Scope: scope}})
+		return s.handleContainer(ctx, request, &params)
+	})
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			progress(req.Params)
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return session, rt
+}
+
+// progressRecorder collects streamed chunks by stream name
+type progressRecorder struct {
+	mu     sync.Mutex
+	chunks map[string]string
+	last   float64
+	sorted bool
+}
+
+func newProgressRecorder() *progressRecorder {
+	return &progressRecorder{chunks: make(map[string]string), sorted: true}
+}
+
+func (r *progressRecorder) record(p *mcp.ProgressNotificationParams) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stream, _ := p.Meta["stream"].(string)
+	r.chunks[stream] += p.Message
+	if p.Progress <= r.last {
+		r.sorted = false
+	}
+	r.last = p.Progress
+}
+
+func (r *progressRecorder) get(stream string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.chunks[stream]
+}
+
+func callContainer(t *testing.T, session *mcp.ClientSession, args map[string]any) string {
+	t.Helper()
+	params := &mcp.CallToolParams{
+		Meta:      mcp.Meta{"progressToken": "stream-test"},
+		Name:      "container",
+		Arguments: args,
+	}
+	result, err := session.CallTool(context.Background(), params)
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("CallTool() tool error: %v", result.Content[0].(*mcp.TextContent).Text)
+	}
+	return result.Content[0].(*mcp.TextContent).Text
+}
+
+func TestContainerExecStream(t *testing.T) {
+	rec := newProgressRecorder()
+	session, _ := newStreamTestClient(t, "admin", []string{"sleep", "300"}, rec.record)
+
+	text := callContainer(t, session, map[string]any{
+		"action":     "exec",
+		"project_id": streamTestProjectID,
+		"command":    "echo one; sleep 1; echo two; cat /nonexistent; exit 3",
+		"stream":     true,
+	})
+
+	// Notifications are delivered asynchronously to the client handler
+	deadline := time.Now().Add(5 * time.Second)
+	for rec.get("stderr") == "" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := rec.get("stdout"); got != "one\ntwo\n" {
+		t.Errorf("streamed stdout = %q, want %q", got, "one\ntwo\n")
+	}
+	if got := rec.get("stderr"); !strings.Contains(got, "/nonexistent") {
+		t.Errorf("streamed stderr = %q, want cat error", got)
+	}
+	if !rec.sorted {
+		t.Error("progress values not increasing")
+	}
+	if !strings.HasPrefix(text, "one\ntwo\n") || !strings.Contains(text, "STDERR:") || !strings.HasSuffix(text, "Exit code: 3") {
+		t.Errorf("result = %q, want output, stderr and exit code", text)
+	}
+}
+
+func TestContainerExecStreamTimeout(t *testing.T) {
+	rec := newProgressRecorder()
+	session, _ := newStreamTestClient(t, "admin", []string{"sleep", "300"}, rec.record)
+
+	start := time.Now()
+	text := callContainer(t, session, map[string]any{
+		"action":          "exec",
+		"project_id":      streamTestProjectID,
+		"command":         "echo started; sleep 300",
+		"stream":          true,
+		"timeout_seconds": 1,
+	})
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("timed out exec took %s", elapsed)
+	}
+	if !strings.HasPrefix(text, "started\n") || !strings.Contains(text, "timed out after 1s") {
+		t.Errorf("result = %q, want output and timeout", text)
+	}
+}
+
+func TestContainerLogsFollow(t *testing.T) {
+	rec := newProgressRecorder()
+	session, _ := newStreamTestClient(t, "admin", []string{"sh", "-c", "echo booting; sleep 1; echo ready"}, rec.record)
+
+	text := callContainer(t, session, map[string]any{
+		"action":     "logs",
+		"project_id": streamTestProjectID,
+		"follow":     true,
+	})
+	if text != "booting\nready\nContainer stopped" {
+		t.Errorf("result = %q, want logs until the container stopped", text)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for rec.get("logs") != "booting\nready\n" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := rec.get("logs"); got != "booting\nready\n" {
+		t.Errorf("streamed logs = %q, want %q", got, "booting\nready\n")
+	}
+}
+
+func TestContainerStreamScope(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope string
+		args  map[string]any
+		want  string
+	}{
+		{"exec read-only", "project:" + streamTestProjectID + ":ro", map[string]any{"action": "exec", "command": "true", "stream": true}, "read-only access"},
+		{"exec other project", "project:00000000-0000-0000-0000-000000000000", map[string]any{"action": "exec", "command": "true", "stream": true}, "not authorized"},
+		{"logs other project", "project:00000000-0000-0000-0000-000000000000", map[string]any{"action": "logs", "follow": true}, "not authorized"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, _ := newStreamTestClient(t, tt.scope, []string{"sleep", "300"}, func(*mcp.ProgressNotificationParams) {})
+			tt.args["project_id"] = streamTestProjectID
+			result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "container", Arguments: tt.args})
+			if err != nil {
+				t.Fatalf("CallTool() error = %v", err)
+			}
+			if text := result.Content[0].(*mcp.TextContent).Text; !result.IsError || !strings.Contains(text, tt.want) {
+				t.Errorf("result = %q (error %v), want %q", text, result.IsError, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := &tailBuffer{limit: 8}
+	b.Write([]byte("0123"))
+	if got := b.String(); got != "0123" {
+		t.Errorf("String() = %q, want %q", got, "0123")
+	}
+	b.Write([]byte("456789"))
+	if got := b.String(); !strings.HasSuffix(got, "\n23456789") || !strings.HasPrefix(got, "[earlier output truncated") {
+		t.Errorf("String() = %q, want truncation note and last 8 bytes", got)
+	}
+}
@@ -0,0 +1,576 @@
+package mcp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
+	"github.com/HyphaGroup/oubliette/internal/auth"
+	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/dashboard"
+	"github.com/HyphaGroup/oubliette/internal/schedule"
+	"github.com/HyphaGroup/oubliette/internal/session"
+	mcp_sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// dashboardAPIPath is the prefix of the JSON API behind the web dashboard
+const dashboardAPIPath = dashboard.Path + "api/"
+
+// errDashboardForbidden is returned when the caller's scope does not allow a request
+var errDashboardForbidden = errors.New("not authorized")
+
+// dashboardAPI returns the dashboard's JSON API. Reads use the same scope
+// checks as the matching tool actions; changes go through the tool registry so
+// they are permission-checked and audited exactly like MCP calls.
+func (s *Server) dashboardAPI() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+dashboardAPIPath+"me", s.handleDashboardMe)
+	mux.HandleFunc("GET "+dashboardAPIPath+"projects", s.handleDashboardProjects)
+	mux.HandleFunc("GET "+dashboardAPIPath+"projects/{project_id}/sessions", s.handleDashboardSessions)
+	mux.HandleFunc("GET "+dashboardAPIPath+"sessions/{session_id}", s.handleDashboardSession)
+	mux.HandleFunc("GET "+dashboardAPIPath+"sessions/{session_id}/events", s.handleDashboardEvents)
+	mux.HandleFunc("GET "+dashboardAPIPath+"schedules", s.handleDashboardSchedules)
+	mux.HandleFunc("GET "+dashboardAPIPath+"schedules/{schedule_id}/executions", s.handleDashboardExecutions)
+	mux.HandleFunc("POST "+dashboardAPIPath+"schedules/{schedule_id}/enable", s.handleDashboardScheduleEnable(true))
+	mux.HandleFunc("POST "+dashboardAPIPath+"schedules/{schedule_id}/disable", s.handleDashboardScheduleEnable(false))
+	mux.HandleFunc("POST "+dashboardAPIPath+"schedules/{schedule_id}/trigger", s.handleDashboardScheduleTrigger)
+	mux.HandleFunc("GET "+dashboardAPIPath+"tokens", s.handleDashboardTokens)
+	mux.HandleFunc("POST "+dashboardAPIPath+"tokens", s.handleDashboardTokenCreate)
+	mux.HandleFunc("DELETE "+dashboardAPIPath+"tokens/{key}", s.handleDashboardTokenRevoke)
+	mux.HandleFunc(dashboardAPIPath, func(w http.ResponseWriter, r *http.Request) {
+		writeDashboardError(w, http.StatusNotFound, fmt.Errorf("unknown dashboard API endpoint %s %s", r.Method, r.URL.Path))
+	})
+	return mux
+}
+
+type dashboardProject struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description,omitempty"`
+	ContainerType   string    `json:"container_type,omitempty"`
+	Model           string    `json:"model,omitempty"`
+	ContainerStatus string    `json:"container_status"`
+	ActiveSessions  int       `json:"active_sessions"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type dashboardSession struct {
+	SessionID       string          `json:"session_id"`
+	ProjectID       string          `json:"project_id"`
+	WorkspaceID     string          `json:"workspace_id"`
+	Status          session.Status  `json:"status"`
+	LiveStatus      string          `json:"live_status,omitempty"` // Set while the session is streaming
+	Model           string          `json:"model,omitempty"`
+	ParentSessionID string          `json:"parent_session_id,omitempty"`
+	ChildSessions   []string        `json:"child_sessions,omitempty"`
+	Depth           int             `json:"depth"`
+	TurnCount       int             `json:"turn_count"`
+	LastPrompt      string          `json:"last_prompt,omitempty"`
+	Cost            session.Cost    `json:"total_cost"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	Turns           []dashboardTurn `json:"turns,omitempty"`
+}
+
+type dashboardTurn struct {
+	Number      int          `json:"number"`
+	Prompt      string       `json:"prompt"`
+	Output      string       `json:"output"`
+	Error       string       `json:"error,omitempty"`
+	StartedAt   time.Time    `json:"started_at"`
+	CompletedAt time.Time    `json:"completed_at"`
+	Cost        session.Cost `json:"cost"`
+
+	Model    string                `json:"model,omitempty"`
+	Attempts []session.TurnAttempt `json:"attempts,omitempty"`
+}
+
+type dashboardEvent struct {
+	Index      int            `json:"index"`
+	Timestamp  time.Time      `json:"timestamp"`
+	Type       string         `json:"type"`
+	Role       string         `json:"role,omitempty"`
+	Text       string         `json:"text,omitempty"`
+	ToolName   string         `json:"tool_name,omitempty"`
+	Parameters map[string]any `json:"parameters,omitempty"`
+	Value      string         `json:"value,omitempty"`
+	IsError    bool           `json:"is_error,omitempty"`
+}
+
+type dashboardEvents struct {
+	SessionID     string           `json:"session_id"`
+	Live          bool             `json:"live"` // False once the session stops streaming; show its turns instead
+	Status        string           `json:"status,omitempty"`
+	LastIndex     int              `json:"last_index"`
+	DroppedEvents int64            `json:"dropped_events,omitempty"`
+	Error         string           `json:"error,omitempty"`
+	Events        []dashboardEvent `json:"events"`
+}
+
+type dashboardSchedule struct {
+	ID              string                    `json:"id"`
+	Name            string                    `json:"name"`
+	CronExpr        string                    `json:"cron_expr"`
+	Prompt          string                    `json:"prompt"`
+	Enabled         bool                      `json:"enabled"`
+	OverlapBehavior schedule.OverlapBehavior  `json:"overlap_behavior"`
+	SessionBehavior schedule.SessionBehavior  `json:"session_behavior"`
+	Targets         []dashboardScheduleTarget `json:"targets"`
+	LastRunAt       *time.Time                `json:"last_run_at,omitempty"`
+	NextRunAt       *time.Time                `json:"next_run_at,omitempty"`
+	CreatedAt       time.Time                 `json:"created_at"`
+}
+
+type dashboardScheduleTarget struct {
+	ProjectID      string     `json:"project_id"`
+	WorkspaceID    string     `json:"workspace_id,omitempty"`
+	SessionID      string     `json:"session_id,omitempty"`
+	LastExecutedAt *time.Time `json:"last_executed_at,omitempty"`
+}
+
+// dashboardToken describes a token without revealing it. Key identifies the
+// token for revocation; the token ID itself is the bearer secret.
+type dashboardToken struct {
+	Key        string      `json:"key"`
+	MaskedID   string      `json:"masked_id"`
+	Name       string      `json:"name"`
+	Scope      string      `json:"scope"`
+	Quota      *auth.Quota `json:"quota,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	LastUsedAt *time.Time  `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time  `json:"expires_at,omitempty"`
+}
+
+func (s *Server) handleDashboardMe(w http.ResponseWriter, r *http.Request) {
+	authCtx := auth.FromContext(r.Context())
+	if authCtx == nil || authCtx.Token == nil {
+		writeDashboardError(w, http.StatusUnauthorized, fmt.Errorf("authentication required"))
+		return
+	}
+	me := map[string]any{
+		"name":      authCtx.Token.Name,
+		"scope":     authCtx.Token.Scope,
+		"admin":     authCtx.IsAdmin(),
+		"can_write": authCtx.CanWrite(),
+	}
+	if authCtx.Identity != nil {
+		me["name"] = authCtx.Identity.String()
+	}
+	writeDashboardJSON(w, me)
+}
+
+func (s *Server) handleDashboardProjects(w http.ResponseWriter, r *http.Request) {
+	authCtx, err := s.dashboardAuth(r, "project", "list", "")
+	if err != nil {
+		writeDashboardError(w, http.StatusForbidden, err)
+		return
+	}
+
+	projects, err := s.projectMgr.List(nil)
+	if err != nil {
+		writeDashboardError(w, http.StatusInternalServerError, err)
+		return
+	}
+	views := make([]dashboardProject, 0, len(projects))
+	for _, proj := range projects {
+		if !authCtx.CanAccessProject(proj.ID) {
+			continue
+		}
+		status := "not created"
+		if name, err := container.ProjectContainerName(proj.ID); err == nil {
+			if st, err := s.runtime.Status(r.Context(), name); err == nil {
+				status = string(st)
+			}
+		}
+		views = append(views, dashboardProject{
+			ID:              proj.ID,
+			Name:            proj.Name,
+			Description:     proj.Description,
+			ContainerType:   proj.ContainerType,
+			Model:           proj.Model,
+			ContainerStatus: status,
+			ActiveSessions:  s.activeSessions.CountByProject(proj.ID),
+			CreatedAt:       proj.CreatedAt,
+		})
+	}
+	writeDashboardJSON(w, views)
+}
+
+func (s *Server) handleDashboardSessions(w http.ResponseWriter, r *http.Request) {
+	projectID := r.PathValue("project_id")
+	if _, err := s.dashboardAuth(r, "session", "list", projectID); err != nil {
+		writeDashboardError(w, http.StatusForbidden, err)
+		return
+	}
+	if _, err := s.projectMgr.Get(projectID); err != nil {
+		writeDashboardError(w, http.StatusNotFound, err)
+		return
+	}
+
+	summaries, err := s.sessionMgr.List(projectID, nil)
+	if err != nil {
+		writeDashboardError(w, http.StatusInternalServerError, err)
+		return
+	}
+	// The tree needs each session's parent, which only the full record has
+	views := make([]dashboardSession, 0, len(summaries))
+	for _, summary := range summaries {
+		sess, err := s.sessionMgr.Load(summary.SessionID)
+		if err != nil {
+			continue
+		}
+		views = append(views, s.dashboardSessionView(sess, false))
+	}
+	writeDashboardJSON(w, views)
+}
+
+func (s *Server) handleDashboardSession(w http.ResponseWriter, r *http.Request) {
+	sess, status, err := s.dashboardLoadSession(r, "get")
+	if err != nil {
+		writeDashboardError(w, status, err)
+		return
+	}
+	writeDashboardJSON(w, s.dashboardSessionView(sess, true))
+}
+
+func (s *Server) handleDashboardEvents(w http.ResponseWriter, r *http.Request) {
+	sess, status, err := s.dashboardLoadSession(r, "events")
+	if err != nil {
+		writeDashboardError(w, status, err)
+		return
+	}
+	sinceIndex := -1
+	if since := r.URL.Query().Get("since_index"); since != "" {
+		if sinceIndex, err = strconv.Atoi(since); err != nil {
+			writeDashboardError(w, http.StatusBadRequest, fmt.Errorf("invalid since_index %q", since))
+			return
+		}
+	}
+
+	result := dashboardEvents{SessionID: sess.SessionID, LastIndex: sinceIndex, Events: []dashboardEvent{}}
+	activeSess, ok := s.activeSessions.Get(sess.SessionID)
+	if !ok {
+		writeDashboardJSON(w, result)
+		return
+	}
+	events, err := activeSess.GetEvents(sinceIndex)
+	if err != nil {
+		writeDashboardError(w, http.StatusInternalServerError, err)
+		return
+	}
+	stats := activeSess.EventBuffer.Stats()
+	result.Live = true
+	result.Status = string(activeSess.GetStatus())
+	result.LastIndex = stats.LastIndex
+	result.DroppedEvents = stats.DroppedEvents
+	if activeSess.Error != nil {
+		result.Error = activeSess.Error.Error()
+	}
+	for _, e := range events {
+		result.Events = append(result.Events, dashboardEventView(e))
+	}
+	writeDashboardJSON(w, result)
+}
+
+func (s *Server) handleDashboardSchedules(w http.ResponseWriter, r *http.Request) {
+	authCtx, err := s.dashboardAuth(r, "schedule", "list", "")
+	if err != nil {
+		writeDashboardError(w, http.StatusForbidden, err)
+		return
+	}
+	schedules, err := s.scheduleStore.List(&schedule.ListFilter{})
+	if err != nil {
+		writeDashboardError(w, http.StatusInternalServerError, err)
+		return
+	}
+	schedules = accessibleSchedules(authCtx, schedules)
+
+	views := make([]dashboardSchedule, 0, len(schedules))
+	for _, sched := range schedules {
+		view := dashboardSchedule{
+			ID:              sched.ID,
+			Name:            sched.Name,
+			CronExpr:        sched.CronExpr,
+			Prompt:          sched.Prompt,
+			Enabled:         sched.Enabled,
+			OverlapBehavior: sched.OverlapBehavior,
+			SessionBehavior: sched.SessionBehavior,
+			LastRunAt:       sched.LastRunAt,
+			NextRunAt:       sched.NextRunAt,
+			CreatedAt:       sched.CreatedAt,
+		}
+		for _, t := range sched.Targets {
+			view.Targets = append(view.Targets, dashboardScheduleTarget{
+				ProjectID:      t.ProjectID,
+				WorkspaceID:    t.WorkspaceID,
+				SessionID:      t.SessionID,
+				LastExecutedAt: t.LastExecutedAt,
+			})
+		}
+		views = append(views, view)
+	}
+	writeDashboardJSON(w, views)
+}
+
+func (s *Server) handleDashboardExecutions(w http.ResponseWriter, r *http.Request) {
+	authCtx, err := s.dashboardAuth(r, "schedule", "history", "")
+	if err != nil {
+		writeDashboardError(w, http.StatusForbidden, err)
+		return
+	}
+	sched, err := s.scheduleStore.Get(r.PathValue("schedule_id"))
+	if err != nil {
+		writeDashboardError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := requireScheduleAccess(authCtx, sched); err != nil {
+		writeDashboardError(w, http.StatusForbidden, err)
+		return
+	}
+	limit := 20
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if limit, err = strconv.Atoi(l); err != nil || limit <= 0 {
+			writeDashboardError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", l))
+			return
+		}
+	}
+	executions, err := s.scheduleStore.ListExecutions(sched.ID, limit)
+	if err != nil {
+		writeDashboardError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if executions == nil {
+		executions = []*schedule.Execution{}
+	}
+	writeDashboardJSON(w, executions)
+}
+
+func (s *Server) handleDashboardScheduleEnable(enabled bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.dashboardCallTool(w, r, "schedule", map[string]any{
+			"action":      "update",
+			"schedule_id": r.PathValue("schedule_id"),
+			"enabled":     enabled,
+		})
+	}
+}
+
+func (s *Server) handleDashboardScheduleTrigger(w http.ResponseWriter, r *http.Request) {
+	s.dashboardCallTool(w, r, "schedule", map[string]any{
+		"action":      "trigger",
+		"schedule_id": r.PathValue("schedule_id"),
+	})
+}
+
+func (s *Server) handleDashboardTokens(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.dashboardAuth(r, "token", "list", ""); err != nil {
+		writeDashboardError(w, http.StatusForbidden, err)
+		return
+	}
+	tokens, err := s.authStore.ListTokens()
+	if err != nil {
+		writeDashboardError(w, http.StatusInternalServerError, err)
+		return
+	}
+	views := make([]dashboardToken, 0, len(tokens))
+	for _, t := range tokens {
+		views = append(views, dashboardToken{
+			Key:        dashboardTokenKey(t.ID),
+			MaskedID:   maskToken(t.ID),
+			Name:       t.Name,
+			Scope:      t.Scope,
+			Quota:      t.Quota,
+			CreatedAt:  t.CreatedAt,
+			LastUsedAt: t.LastUsedAt,
+			ExpiresAt:  t.ExpiresAt,
+		})
+	}
+	writeDashboardJSON(w, views)
+}
+
+func (s *Server) handleDashboardTokenCreate(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.dashboardAuth(r, "token", "create", ""); err != nil {
+		writeDashboardError(w, http.StatusForbidden, err)
+		return
+	}
+	var params TokenParams
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&params); err != nil {
+		writeDashboardError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	token, tokenID, err := s.createToken(r.Context(), &params)
+	if err != nil {
+		writeDashboardError(w, http.StatusBadRequest, err)
+		return
+	}
+	// The only time the token is shown; it cannot be retrieved later
+	writeDashboardJSON(w, map[string]any{
+		"token_id": tokenID,
+		"key":      dashboardTokenKey(tokenID),
+		"name":     token.Name,
+		"scope":    token.Scope,
+	})
+}
+
+func (s *Server) handleDashboardTokenRevoke(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.dashboardAuth(r, "token", "revoke", ""); err != nil {
+		writeDashboardError(w, http.StatusForbidden, err)
+		return
+	}
+	tokens, err := s.authStore.ListTokens()
+	if err != nil {
+		writeDashboardError(w, http.StatusInternalServerError, err)
+		return
+	}
+	key := r.PathValue("key")
+	for _, t := range tokens {
+		if dashboardTokenKey(t.ID) == key {
+			s.dashboardCallTool(w, r, "token", map[string]any{"action": "revoke", "token_id": t.ID})
+			return
+		}
+	}
+	writeDashboardError(w, http.StatusNotFound, fmt.Errorf("token not found"))
+}
+
+// dashboardAuth checks that the caller may perform a tool action
+func (s *Server) dashboardAuth(r *http.Request, tool, action, projectID string) (*auth.AuthContext, error) {
+	authCtx := auth.FromContext(r.Context())
+	if authCtx == nil || authCtx.Token == nil {
+		return nil, errDashboardForbidden
+	}
+	if !s.registry.IsToolActionAllowed(tool, action, authCtx.Token.Scope, projectID) {
+		return nil, fmt.Errorf("%w to %s %s", errDashboardForbidden, tool, action)
+	}
+	return authCtx, nil
+}
+
+// dashboardLoadSession loads the session in the request path if the caller
+// may perform the session action on it, returning the status to fail with
+func (s *Server) dashboardLoadSession(r *http.Request, action string) (*session.Session, int, error) {
+	sess, err := s.sessionMgr.Load(r.PathValue("session_id"))
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	if _, err := s.dashboardAuth(r, "session", action, sess.ProjectID); err != nil {
+		return nil, http.StatusForbidden, err
+	}
+	return sess, 0, nil
+}
+
+// dashboardCallTool runs a tool action for the caller and writes its result
+func (s *Server) dashboardCallTool(w http.ResponseWriter, r *http.Request, tool string, args map[string]any) {
+	authCtx := auth.FromContext(r.Context())
+	action, _ := args["action"].(string)
+	if authCtx == nil || authCtx.Token == nil || !s.registry.IsToolActionAllowed(tool, action, authCtx.Token.Scope, "") {
+		writeDashboardError(w, http.StatusForbidden, fmt.Errorf("%w to %s %s", errDashboardForbidden, tool, action))
+		return
+	}
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		writeDashboardError(w, http.StatusInternalServerError, err)
+		return
+	}
+	result, err := s.registry.CallTool(r.Context(), tool, argsJSON)
+	if err != nil {
+		writeDashboardError(w, http.StatusBadRequest, err)
+		return
+	}
+	resp := map[string]any{"ok": true}
+	if ctr, ok := result.(*mcp_sdk.CallToolResult); ok {
+		var message strings.Builder
+		for _, c := range ctr.Content {
+			if tc, ok := c.(*mcp_sdk.TextContent); ok {
+				message.WriteString(tc.Text)
+			}
+		}
+		resp["message"] = message.String()
+	} else {
+		resp["result"] = result
+	}
+	writeDashboardJSON(w, resp)
+}
+
+func (s *Server) dashboardSessionView(sess *session.Session, withTurns bool) dashboardSession {
+	view := dashboardSession{
+		SessionID:     sess.SessionID,
+		ProjectID:     sess.ProjectID,
+		WorkspaceID:   sess.WorkspaceID,
+		Status:        sess.Status,
+		Model:         sess.Model,
+		ChildSessions: sess.ChildSessions,
+		Depth:         sess.Depth,
+		TurnCount:     len(sess.Turns),
+		Cost:          sess.TotalCost,
+		CreatedAt:     sess.CreatedAt,
+		UpdatedAt:     sess.UpdatedAt,
+	}
+	if sess.ParentSessionID != nil {
+		view.ParentSessionID = *sess.ParentSessionID
+	}
+	if len(sess.Turns) > 0 {
+		view.LastPrompt = sess.Turns[len(sess.Turns)-1].Prompt
+	}
+	if activeSess, ok := s.activeSessions.Get(sess.SessionID); ok {
+		view.LiveStatus = string(activeSess.GetStatus())
+	}
+	if withTurns {
+		for _, t := range sess.Turns {
+			view.Turns = append(view.Turns, dashboardTurn{
+				Number:      t.TurnNumber,
+				Prompt:      t.Prompt,
+				Output:      t.Output.Text,
+				Error:       t.Output.Error,
+				StartedAt:   t.StartedAt,
+				CompletedAt: t.CompletedAt,
+				Cost:        t.Cost,
+				Model:       t.Model,
+				Attempts:    t.Attempts,
+			})
+		}
+	}
+	return view
+}
+
+func dashboardEventView(e *session.BufferedEvent) dashboardEvent {
+	view := dashboardEvent{Index: e.Index, Timestamp: e.Timestamp}
+	if e.Event == nil {
+		return view
+	}
+	view.Type = string(e.Event.Type)
+	view.Role = e.Event.Role
+	view.Text = e.Event.Text
+	view.ToolName = e.Event.ToolName
+	view.Parameters = e.Event.Parameters
+	view.Value = e.Event.Value
+	view.IsError = e.Event.IsError
+	if e.Event.Type == agent.StreamEventCompletion && view.Text == "" {
+		view.Text = e.Event.FinalText
+	}
+	return view
+}
+
+// dashboardTokenKey identifies a token to the dashboard without revealing it
+func dashboardTokenKey(tokenID string) string {
+	sum := sha256.Sum256([]byte(tokenID))
+	return hex.EncodeToString(sum[:8])
+}
+
+func writeDashboardJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeDashboardError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
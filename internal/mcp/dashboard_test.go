@@ -0,0 +1,212 @@
+package mcp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/auth"
+	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/schedule"
+	"github.com/HyphaGroup/oubliette/internal/session"
+)
+
+const (
+	dashboardRootSession  = "650e8400-e29b-41d4-a716-446655440000"
+	dashboardChildSession = "650e8400-e29b-41d4-a716-446655440001"
+)
+
+// newDashboardTestServer serves the dashboard API for a project with a running
+// container and a parent/child session pair
+func newDashboardTestServer(t *testing.T) (*httptest.Server, *Server, string) {
+	t.Helper()
+	s, _, projectID := newProjectTestServer(t)
+	projectsDir := filepath.Dir(s.projectMgr.GetProjectDir(projectID))
+
+	store, err := session.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	s.sessionMgr = session.NewManager(projectsDir, store, nil, "")
+	if err := os.MkdirAll(filepath.Join(projectsDir, projectID, "sessions"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	parent := dashboardRootSession
+	for _, sess := range []*session.Session{
+		{SessionID: dashboardRootSession, ProjectID: projectID, Status: session.StatusCompleted, ChildSessions: []string{dashboardChildSession},
+			Turns: []session.Turn{{TurnNumber: 1, Prompt: "review the payments code", Output: session.TurnOutput{Text: "looks good"}}}},
+		{SessionID: dashboardChildSession, ProjectID: projectID, Status: session.StatusActive, ParentSessionID: &parent, Depth: 1},
+	} {
+		if err := s.sessionMgr.SaveSession(sess); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.sessionMgr.IndexUnindexedSessions(); err != nil {
+		t.Fatal(err)
+	}
+	s.activeSessions = session.NewActiveSessionManager(10, time.Hour)
+	t.Cleanup(s.activeSessions.Close)
+
+	dataDir := t.TempDir()
+	if s.authStore, err = auth.NewStore(dataDir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.authStore.Close() })
+	if s.scheduleStore, err = schedule.NewStore(dataDir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.scheduleStore.Close() })
+
+	return serveWithScope(t, s.dashboardAPI()), s, projectID
+}
+
+// dashboardRequest calls the dashboard API, decoding the JSON response into out
+func dashboardRequest(t *testing.T, srv *httptest.Server, scope, method, path, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+dashboardAPIPath+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+scope)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, _ := io.ReadAll(resp.Body)
+	if out != nil && resp.StatusCode == http.StatusOK {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s %s: invalid JSON %q: %v", method, path, data, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestDashboardProjectsAndSessions(t *testing.T) {
+	srv, _, projectID := newDashboardTestServer(t)
+	other := "project:00000000-0000-0000-0000-000000000000"
+
+	var projects []dashboardProject
+	if code := dashboardRequest(t, srv, "admin:ro", "GET", "projects", "", &projects); code != http.StatusOK {
+		t.Fatalf("projects status = %d", code)
+	}
+	if len(projects) != 1 || projects[0].ID != projectID || projects[0].ContainerStatus != string(container.StatusRunning) {
+		t.Errorf("projects = %+v, want the project with a running container", projects)
+	}
+	if dashboardRequest(t, srv, other, "GET", "projects", "", &projects); len(projects) != 0 {
+		t.Errorf("projects for another project's token = %+v, want none", projects)
+	}
+
+	var sessions []dashboardSession
+	if code := dashboardRequest(t, srv, "project:"+projectID+":ro", "GET", "projects/"+projectID+"/sessions", "", &sessions); code != http.StatusOK {
+		t.Fatalf("sessions status = %d", code)
+	}
+	parents := map[string]string{}
+	for _, sess := range sessions {
+		parents[sess.SessionID] = sess.ParentSessionID
+	}
+	if len(parents) != 2 || parents[dashboardChildSession] != dashboardRootSession || parents[dashboardRootSession] != "" {
+		t.Errorf("session tree = %v", parents)
+	}
+	if code := dashboardRequest(t, srv, other, "GET", "projects/"+projectID+"/sessions", "", nil); code != http.StatusForbidden {
+		t.Errorf("sessions for another project's token status = %d, want 403", code)
+	}
+
+	var sess dashboardSession
+	dashboardRequest(t, srv, "admin", "GET", "sessions/"+dashboardRootSession, "", &sess)
+	if len(sess.Turns) != 1 || sess.Turns[0].Output != "looks good" {
+		t.Errorf("session turns = %+v", sess.Turns)
+	}
+	if code := dashboardRequest(t, srv, other, "GET", "sessions/"+dashboardRootSession, "", nil); code != http.StatusForbidden {
+		t.Errorf("session for another project's token status = %d, want 403", code)
+	}
+
+	var events dashboardEvents
+	dashboardRequest(t, srv, "admin", "GET", "sessions/"+dashboardRootSession+"/events?since_index=-1", "", &events)
+	if events.Live || len(events.Events) != 0 {
+		t.Errorf("events for a session that is not streaming = %+v", events)
+	}
+}
+
+func TestDashboardSchedules(t *testing.T) {
+	srv, s, projectID := newDashboardTestServer(t)
+	sched := &schedule.Schedule{
+		Name:           "nightly",
+		CronExpr:       "0 2 * * *",
+		Prompt:         "run the tests",
+		Enabled:        true,
+		Targets:        []schedule.ScheduleTarget{{ProjectID: projectID}},
+		CreatorTokenID: "oub_creator-secret",
+		CreatorScope:   "admin",
+	}
+	if err := s.scheduleStore.Create(sched); err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("GET", srv.URL+dashboardAPIPath+"schedules", nil)
+	req.Header.Set("Authorization", "Bearer project:"+projectID+":ro")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !strings.Contains(string(raw), sched.ID) || strings.Contains(string(raw), "oub_creator-secret") {
+		t.Errorf("schedules = %s, want the schedule without its creator token", raw)
+	}
+
+	if code := dashboardRequest(t, srv, "project:"+projectID+":ro", "POST", "schedules/"+sched.ID+"/disable", "", nil); code != http.StatusForbidden {
+		t.Errorf("disable with a read-only token status = %d, want 403", code)
+	}
+	if code := dashboardRequest(t, srv, "admin", "POST", "schedules/"+sched.ID+"/disable", "", nil); code != http.StatusOK {
+		t.Fatalf("disable status = %d", code)
+	}
+	if got, _ := s.scheduleStore.Get(sched.ID); got.Enabled {
+		t.Error("schedule still enabled after disable")
+	}
+
+	var executions []*schedule.Execution
+	if code := dashboardRequest(t, srv, "admin", "GET", "schedules/"+sched.ID+"/executions", "", &executions); code != http.StatusOK || len(executions) != 0 {
+		t.Errorf("executions status = %d, executions = %v", code, executions)
+	}
+}
+
+func TestDashboardTokens(t *testing.T) {
+	srv, s, projectID := newDashboardTestServer(t)
+
+	if code := dashboardRequest(t, srv, "admin:ro", "GET", "tokens", "", nil); code != http.StatusForbidden {
+		t.Errorf("tokens with a read-only admin token status = %d, want 403", code)
+	}
+
+	var created map[string]string
+	body := `{"name": "stakeholders", "scope": "project:` + projectID + `:ro"}`
+	if code := dashboardRequest(t, srv, "admin", "POST", "tokens", body, &created); code != http.StatusOK {
+		t.Fatalf("create status = %d", code)
+	}
+	if _, err := s.authStore.ValidateToken(created["token_id"]); err != nil {
+		t.Fatalf("created token is not valid: %v", err)
+	}
+
+	var tokens []dashboardToken
+	dashboardRequest(t, srv, "admin", "GET", "tokens", "", &tokens)
+	if len(tokens) != 1 || tokens[0].Key != created["key"] || tokens[0].MaskedID == created["token_id"] {
+		t.Fatalf("tokens = %+v, want the new token masked", tokens)
+	}
+
+	if code := dashboardRequest(t, srv, "admin", "DELETE", "tokens/"+created["key"], "", nil); code != http.StatusOK {
+		t.Fatalf("revoke status = %d", code)
+	}
+	if _, err := s.authStore.ValidateToken(created["token_id"]); err == nil {
+		t.Error("token still valid after revoke")
+	}
+	if code := dashboardRequest(t, srv, "admin", "DELETE", "tokens/"+created["key"], "", nil); code != http.StatusNotFound {
+		t.Errorf("revoking a revoked token status = %d, want 404", code)
+	}
+}
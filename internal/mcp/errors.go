@@ -1,12 +1,53 @@
 package mcp
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/HyphaGroup/oubliette/internal/logger"
 )
 
+// Kinds of tool error. Handlers tag their errors with one of these so callers
+// such as the REST API can tell client mistakes from server failures; test
+// with errors.Is. Untagged errors are server failures.
+var (
+	ErrUnauthenticated = errors.New("authentication required")
+	ErrForbidden       = errors.New("forbidden")
+	ErrNotFound        = errors.New("not found")
+	ErrInvalidParams   = errors.New("invalid parameters")
+	ErrConflict        = errors.New("conflict")
+)
+
+// toolError tags an error with its kind without changing its message
+type toolError struct {
+	kind error
+	err  error
+}
+
+func (e *toolError) Error() string   { return e.err.Error() }
+func (e *toolError) Unwrap() []error { return []error{e.kind, e.err} }
+
+// forbidden returns an ErrForbidden error with a formatted message
+func forbidden(format string, args ...any) error {
+	return &toolError{kind: ErrForbidden, err: fmt.Errorf(format, args...)}
+}
+
+// notFound returns an ErrNotFound error with a formatted message
+func notFound(format string, args ...any) error {
+	return &toolError{kind: ErrNotFound, err: fmt.Errorf(format, args...)}
+}
+
+// invalidParams returns an ErrInvalidParams error with a formatted message
+func invalidParams(format string, args ...any) error {
+	return &toolError{kind: ErrInvalidParams, err: fmt.Errorf(format, args...)}
+}
+
+// conflict returns an ErrConflict error with a formatted message
+func conflict(format string, args ...any) error {
+	return &toolError{kind: ErrConflict, err: fmt.Errorf(format, args...)}
+}
+
 // sensitivePatterns contains substrings that indicate sensitive error details
 var sensitivePatterns = []string{
 	"API_KEY",
@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/audit"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// AuditParams is the params struct for the audit tool
+type AuditParams struct {
+	Action string `json:"action"` // Required: query, verify
+
+	TokenID   string `json:"token_id,omitempty"`
+	ProjectID string `json:"project_id,omitempty"`
+	Operation string `json:"operation,omitempty"`
+	Since     string `json:"since,omitempty"` // RFC 3339
+	Until     string `json:"until,omitempty"` // RFC 3339
+	Limit     int    `json:"limit,omitempty"`
+}
+
+// AuditQueryResult is the structured result of an audit query
+type AuditQueryResult struct {
+	Records []*audit.Record `json:"records"`
+	Count   int             `json:"count"`
+}
+
+var auditActions = []string{"query", "verify"}
+
+func (s *Server) handleAudit(ctx context.Context, request *mcp.CallToolRequest, params *AuditParams) (*mcp.CallToolResult, any, error) {
+	if params.Action == "" {
+		return nil, nil, missingActionError("audit", auditActions)
+	}
+
+	switch params.Action {
+	case "query":
+		return s.handleAuditQuery(ctx, request, params)
+	case "verify":
+		return s.handleAuditVerify(ctx, request, params)
+	default:
+		return nil, nil, actionError("audit", params.Action, auditActions)
+	}
+}
+
+func (s *Server) handleAuditQuery(ctx context.Context, request *mcp.CallToolRequest, params *AuditParams) (*mcp.CallToolResult, any, error) {
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, nil, err
+	}
+	if s.auditStore == nil {
+		return nil, nil, fmt.Errorf("audit store not configured")
+	}
+
+	filter, err := params.filter()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records, err := s.auditStore.Query(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	if records == nil {
+		records = []*audit.Record{}
+	}
+
+	result := AuditQueryResult{Records: records, Count: len(records)}
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(resultJSON)}},
+	}, result, nil
+}
+
+func (s *Server) handleAuditVerify(ctx context.Context, request *mcp.CallToolRequest, params *AuditParams) (*mcp.CallToolResult, any, error) {
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, nil, err
+	}
+	if s.auditStore == nil {
+		return nil, nil, fmt.Errorf("audit store not configured")
+	}
+
+	checked, err := s.auditStore.Verify()
+	if err != nil {
+		return nil, nil, fmt.Errorf("audit log verification failed after %d record(s): %w", checked, err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("✅ Audit log intact: %d record(s) verified.", checked)},
+		},
+	}, nil, nil
+}
+
+// filter converts the tool params into a store filter
+func (p *AuditParams) filter() (audit.Filter, error) {
+	f := audit.Filter{
+		TokenID:   p.TokenID,
+		ProjectID: p.ProjectID,
+		Operation: audit.Operation(p.Operation),
+		Limit:     p.Limit,
+	}
+	var err error
+	if p.Since != "" {
+		if f.Since, err = time.Parse(time.RFC3339, p.Since); err != nil {
+			return f, invalidParams("invalid since %q: expected RFC 3339 time", p.Since)
+		}
+	}
+	if p.Until != "" {
+		if f.Until, err = time.Parse(time.RFC3339, p.Until); err != nil {
+			return f, invalidParams("invalid until %q: expected RFC 3339 time", p.Until)
+		}
+	}
+	return f, nil
+}
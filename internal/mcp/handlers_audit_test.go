@@ -0,0 +1,31 @@
+package mcp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuditParams_Filter(t *testing.T) {
+	params := &AuditParams{
+		TokenID:   "oub_x",
+		ProjectID: "p1",
+		Operation: "container.exec",
+		Since:     "2026-03-01T00:00:00Z",
+		Until:     "2026-03-08T00:00:00Z",
+		Limit:     10,
+	}
+	f, err := params.filter()
+	if err != nil {
+		t.Fatalf("filter() error = %v", err)
+	}
+	if f.TokenID != "oub_x" || f.ProjectID != "p1" || f.Operation != "container.exec" || f.Limit != 10 {
+		t.Errorf("filter() = %+v", f)
+	}
+	if !f.Since.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) || !f.Until.Equal(time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("filter() time range = %v..%v", f.Since, f.Until)
+	}
+
+	if _, err := (&AuditParams{Since: "last week"}).filter(); err == nil {
+		t.Error("filter() should reject a non-RFC 3339 since")
+	}
+}
@@ -2,8 +2,8 @@ package mcp
 
 import (
 	"context"
-	"fmt"
 
+	"github.com/HyphaGroup/oubliette/internal/audit"
 	"github.com/HyphaGroup/oubliette/internal/auth"
 )
 
@@ -11,7 +11,7 @@ import (
 func requireAuth(ctx context.Context) (*auth.AuthContext, error) {
 	authCtx := auth.FromContext(ctx)
 	if authCtx == nil {
-		return nil, fmt.Errorf("authentication required")
+		return nil, ErrUnauthenticated
 	}
 	return authCtx, nil
 }
@@ -23,7 +23,7 @@ func requireProjectAccess(ctx context.Context, projectID string) (*auth.AuthCont
 		return nil, err
 	}
 	if !authCtx.CanAccessProject(projectID) {
-		return nil, fmt.Errorf("not authorized to access project %s", projectID)
+		return nil, forbidden("not authorized to access project %s", projectID)
 	}
 	return authCtx, nil
 }
@@ -35,7 +35,7 @@ func requireWriteAccess(ctx context.Context) (*auth.AuthContext, error) {
 		return nil, err
 	}
 	if !authCtx.CanWrite() {
-		return nil, fmt.Errorf("read-only access, write operations not permitted")
+		return nil, forbidden("read-only access, write operations not permitted")
 	}
 	return authCtx, nil
 }
@@ -47,7 +47,18 @@ func requireAdmin(ctx context.Context) (*auth.AuthContext, error) {
 		return nil, err
 	}
 	if !authCtx.IsAdmin() {
-		return nil, fmt.Errorf("admin access required")
+		return nil, forbidden("admin access required")
 	}
 	return authCtx, nil
 }
+
+// logAudit records an audit event attributed to the caller in ctx.
+// A nil err marks the operation successful.
+func logAudit(ctx context.Context, event *audit.Event, err error) {
+	event.TokenID, event.TokenScope = getTokenInfo(auth.FromContext(ctx))
+	event.Success = err == nil
+	if err != nil {
+		event.Error = err.Error()
+	}
+	audit.Log(event)
+}
@@ -0,0 +1,177 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/HyphaGroup/oubliette/internal/audit"
+	"github.com/HyphaGroup/oubliette/internal/config"
+	"github.com/HyphaGroup/oubliette/internal/logger"
+	"github.com/HyphaGroup/oubliette/internal/metrics"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ConfigParams is the params struct for the config tool
+type ConfigParams struct {
+	Action string `json:"action"` // Required: reload, diff
+}
+
+// ConfigReload is the outcome of re-reading oubliette.jsonc
+type ConfigReload struct {
+	Changes         []config.ConfigChange `json:"changes"`
+	Applied         bool                  `json:"applied"`
+	RestartRequired bool                  `json:"restart_required,omitempty"`
+}
+
+var configActions = []string{"reload", "diff"}
+
+func (s *Server) handleConfig(ctx context.Context, request *mcp.CallToolRequest, params *ConfigParams) (*mcp.CallToolResult, any, error) {
+	if params.Action == "" {
+		return nil, nil, missingActionError("config", configActions)
+	}
+
+	switch params.Action {
+	case "reload", "diff":
+		return s.handleConfigReload(ctx, request, params)
+	default:
+		return nil, nil, actionError("config", params.Action, configActions)
+	}
+}
+
+func (s *Server) handleConfigReload(ctx context.Context, request *mcp.CallToolRequest, params *ConfigParams) (*mcp.CallToolResult, any, error) {
+	authCtx, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	apply := params.Action == "reload"
+	result, err := s.ReloadConfig(apply)
+	if apply {
+		callerTokenID, callerScope := getTokenInfo(authCtx)
+		if err != nil {
+			audit.LogFailure(audit.OpConfigReload, callerTokenID, callerScope, "", err)
+		} else if result.Applied {
+			audit.Log(&audit.Event{
+				Operation:  audit.OpConfigReload,
+				TokenID:    callerTokenID,
+				TokenScope: callerScope,
+				Success:    true,
+				Details:    map[string]interface{}{"changes": len(result.Changes)},
+			})
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result.Summary()},
+			&mcp.TextContent{Text: string(resultJSON)},
+		},
+	}, result, nil
+}
+
+// Summary describes the reload for people
+func (r *ConfigReload) Summary() string {
+	if len(r.Changes) == 0 {
+		return "Configuration unchanged."
+	}
+	var b strings.Builder
+	switch {
+	case r.Applied:
+		fmt.Fprintf(&b, "✅ Configuration reloaded, %d change(s):\n", len(r.Changes))
+	default:
+		fmt.Fprintf(&b, "%d change(s) pending in oubliette.jsonc:\n", len(r.Changes))
+	}
+	for _, change := range r.Changes {
+		b.WriteString("  " + change.String() + "\n")
+	}
+	if r.RestartRequired {
+		b.WriteString("Changes marked (restart required) take effect after a server restart.\n")
+	}
+	return b.String()
+}
+
+// ReloadConfig re-reads oubliette.jsonc and compares it with the config in
+// effect. With apply set, a changed config is swapped into the project
+// manager, image manager and server; an invalid one is rejected and the
+// current config stays in effect. Sessions already running are unaffected;
+// warm pool containers are replaced when the credentials change.
+func (s *Server) ReloadConfig(apply bool) (*ConfigReload, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	s.configMu.RLock()
+	current := s.config
+	s.configMu.RUnlock()
+	if current == nil || current.ConfigDir == "" {
+		return nil, fmt.Errorf("config reload is not available: server was not started from a config file")
+	}
+
+	next, err := config.LoadAll(current.ConfigDir)
+	if err == nil {
+		err = next.Validate()
+	}
+	if err != nil {
+		if apply {
+			metrics.RecordConfigReload("failed")
+			logger.Error("Config reload failed, keeping the current configuration: %v", err)
+		}
+		return nil, fmt.Errorf("invalid configuration, keeping the current one: %w", err)
+	}
+
+	result := &ConfigReload{Changes: config.DiffConfig(current, next)}
+	for _, change := range result.Changes {
+		result.RestartRequired = result.RestartRequired || change.Restart
+	}
+	if !apply {
+		return result, nil
+	}
+	if len(result.Changes) == 0 {
+		metrics.RecordConfigReload("unchanged")
+		return result, nil
+	}
+
+	next.RetainRestartSettings(current)
+	s.projectMgr.ApplyConfig(next.Models, next.ConfigDefaults, next.Containers)
+	if s.imageManager != nil {
+		s.imageManager.SetContainers(next.Containers)
+	}
+	s.configMu.Lock()
+	s.config = next
+	s.credentials = next.Credentials
+	s.modelRegistry = next.Models
+	s.configMu.Unlock()
+
+	// Pool containers carry the provider API key in their env
+	if s.pool != nil && credentialsChanged(result.Changes) {
+		logger.Info("Credentials changed, replacing warm pool containers")
+		s.pool.Flush()
+	}
+
+	result.Applied = true
+	metrics.RecordConfigReload("applied")
+	logger.Info("Configuration reloaded with %d change(s)", len(result.Changes))
+	for _, change := range result.Changes {
+		logger.Info("  %s", change.String())
+	}
+	if len(next.PlaintextSecrets) > 0 {
+		logger.Info("%d credential(s) stored in plaintext in oubliette.jsonc: %s",
+			len(next.PlaintextSecrets), strings.Join(next.PlaintextSecrets, ", "))
+	}
+	return result, nil
+}
+
+// credentialsChanged reports whether any of changes is to a credential
+func credentialsChanged(changes []config.ConfigChange) bool {
+	for _, change := range changes {
+		if change.Path == "credentials" || strings.HasPrefix(change.Path, "credentials.") {
+			return true
+		}
+	}
+	return false
+}
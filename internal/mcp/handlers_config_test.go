@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/config"
+	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/project"
+)
+
+func writeReloadConfig(t *testing.T, dir, models string, maxDepth int, address string) {
+	t.Helper()
+	data := `{
+		"server": {"address": "` + address + `"},
+		"defaults": {"limits": {"max_recursion_depth": ` + strconv.Itoa(maxDepth) + `}, "agent": {"model": "sonnet"}},
+		"containers": {"dev": "oubliette-dev:latest"},
+		"models": {"models": {` + models + `}}
+	}`
+	if err := os.WriteFile(filepath.Join(dir, "oubliette.jsonc"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerReloadConfig(t *testing.T) {
+	dir := t.TempDir()
+	sonnet := `"sonnet": {"model": "claude-sonnet-4-5", "provider": "anthropic"}`
+	writeReloadConfig(t, dir, sonnet, 1, ":8080")
+
+	cfg, err := config.LoadAll(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectMgr := project.NewManager(t.TempDir(), 1, 50, 10)
+	projectMgr.ApplyConfig(cfg.Models, cfg.ConfigDefaults, cfg.Containers)
+	s := &Server{
+		projectMgr:    projectMgr,
+		imageManager:  container.NewImageManager(cfg.Containers, nil),
+		config:        cfg,
+		credentials:   cfg.Credentials,
+		modelRegistry: cfg.Models,
+	}
+
+	if result, err := s.ReloadConfig(true); err != nil || result.Applied || len(result.Changes) != 0 {
+		t.Fatalf("ReloadConfig() of an unchanged file = %+v, %v", result, err)
+	}
+
+	// Add a model, raise the depth limit and move the server
+	writeReloadConfig(t, dir, sonnet+`, "haiku": {"model": "claude-haiku-4-5", "provider": "anthropic"}`, 5, ":9090")
+
+	diff, err := s.ReloadConfig(false)
+	if err != nil {
+		t.Fatalf("ReloadConfig(false) error = %v", err)
+	}
+	if diff.Applied || s.models().HasModel("haiku") {
+		t.Error("diff applied the new config")
+	}
+
+	result, err := s.ReloadConfig(true)
+	if err != nil {
+		t.Fatalf("ReloadConfig(true) error = %v", err)
+	}
+	var paths []string
+	for _, change := range result.Changes {
+		paths = append(paths, change.Path)
+	}
+	want := "defaults.limits.max_recursion_depth models.models.haiku server.address"
+	if strings.Join(paths, " ") != want {
+		t.Errorf("changed paths = %v, want %s", paths, want)
+	}
+	if !result.Applied || !result.RestartRequired {
+		t.Errorf("result = %+v, want applied with a restart required", result)
+	}
+	if !s.models().HasModel("haiku") || projectMgr.GetDefaultMaxDepth() != 5 {
+		t.Errorf("reloaded config not in effect: haiku=%v depth=%d", s.models().HasModel("haiku"), projectMgr.GetDefaultMaxDepth())
+	}
+
+	// The address change stays pending until a restart
+	if again, err := s.ReloadConfig(true); err != nil || len(again.Changes) != 1 || again.Changes[0].Path != "server.address" {
+		t.Errorf("second reload = %+v, %v, want only the pending address change", again, err)
+	}
+
+	t.Run("invalid config keeps the current one", func(t *testing.T) {
+		writeReloadConfig(t, dir, `"opus": {"model": "claude-opus-4-6", "fallbacks": ["missing"]}`, 5, ":8080")
+		if _, err := s.ReloadConfig(true); err == nil || !strings.Contains(err.Error(), "keeping the current one") {
+			t.Errorf("ReloadConfig() error = %v, want rejection", err)
+		}
+		if !s.models().HasModel("haiku") || s.models().HasModel("opus") {
+			t.Error("rejected config replaced the models")
+		}
+	})
+
+	t.Run("without a config file", func(t *testing.T) {
+		if _, err := (&Server{}).ReloadConfig(true); err == nil {
+			t.Error("ReloadConfig() without a config succeeded")
+		}
+	})
+}
+
+// countingWarmer is a container.PoolWarmer that only counts warm-ups
+type countingWarmer struct {
+	mu     sync.Mutex
+	warmed int
+}
+
+func (w *countingWarmer) Warm(_ context.Context, _, name string) (*container.WarmContainer, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.warmed++
+	return &container.WarmContainer{Image: "oubliette-dev:latest", Name: name}, nil
+}
+
+func (w *countingWarmer) Discard(context.Context, *container.WarmContainer) {}
+
+func (w *countingWarmer) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.warmed
+}
+
+func TestServerReloadConfigReplacesPoolOnCredentialChange(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig := func(apiKey string, maxDepth int) {
+		data := `{
+			"credentials": {"providers": {"credentials": {"main": {"provider": "anthropic", "api_key": "` + apiKey + `"}}, "default": "main"}},
+			"defaults": {"limits": {"max_recursion_depth": ` + strconv.Itoa(maxDepth) + `}},
+			"containers": {"dev": "oubliette-dev:latest"}
+		}`
+		if err := os.WriteFile(filepath.Join(dir, "oubliette.jsonc"), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig("sk-ant-old", 1)
+	cfg, err := config.LoadAll(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	warmer := &countingWarmer{}
+	pool := container.NewPool(warmer, map[string]int{"dev": 1})
+	pool.Start()
+	defer pool.Stop(context.Background())
+	s := &Server{
+		projectMgr:    project.NewManager(t.TempDir(), 1, 50, 10),
+		config:        cfg,
+		credentials:   cfg.Credentials,
+		modelRegistry: cfg.Models,
+		pool:          pool,
+	}
+	waitForWarmups := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for warmer.count() < want && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		time.Sleep(20 * time.Millisecond)
+		if got := warmer.count(); got != want {
+			t.Fatalf("pool warmed %d containers, want %d", got, want)
+		}
+	}
+	waitForWarmups(1)
+
+	// Other changes leave the pool alone
+	writeConfig("sk-ant-old", 2)
+	if _, err := s.ReloadConfig(true); err != nil {
+		t.Fatalf("ReloadConfig() error = %v", err)
+	}
+	waitForWarmups(1)
+
+	// A rotated key replaces the warm container
+	writeConfig("sk-ant-new", 2)
+	if _, err := s.ReloadConfig(true); err != nil {
+		t.Fatalf("ReloadConfig() error = %v", err)
+	}
+	waitForWarmups(2)
+}
@@ -5,11 +5,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/HyphaGroup/oubliette/internal/audit"
 	"github.com/HyphaGroup/oubliette/internal/config"
 	"github.com/HyphaGroup/oubliette/internal/container"
 	"github.com/HyphaGroup/oubliette/internal/logger"
+	"github.com/HyphaGroup/oubliette/internal/metrics"
+	"github.com/HyphaGroup/oubliette/internal/project"
+	"github.com/HyphaGroup/oubliette/internal/tracing"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ContainerParams is the params struct for the container tool
@@ -19,9 +25,14 @@ type ContainerParams struct {
 	ProjectID  string `json:"project_id,omitempty"`
 	Command    string `json:"command,omitempty"`
 	WorkingDir string `json:"working_dir,omitempty"`
+	Force      bool   `json:"force,omitempty"` // build: rebuild even if the image is up to date
+
+	Stream         bool `json:"stream,omitempty"`          // exec: send output as progress notifications while it runs
+	Follow         bool `json:"follow,omitempty"`          // logs: keep sending new lines until the container stops
+	TimeoutSeconds int  `json:"timeout_seconds,omitempty"` // exec, logs: stop after this long
 }
 
-var containerActions = []string{"start", "stop", "logs", "exec"}
+var containerActions = []string{"start", "stop", "logs", "exec", "build"}
 
 func (s *Server) handleContainer(ctx context.Context, request *mcp.CallToolRequest, params *ContainerParams) (*mcp.CallToolResult, any, error) {
 	if params.Action == "" {
@@ -37,6 +48,8 @@ func (s *Server) handleContainer(ctx context.Context, request *mcp.CallToolReque
 		return s.handleContainerLogs(ctx, request, params)
 	case "exec":
 		return s.handleContainerExec(ctx, request, params)
+	case "build":
+		return s.handleContainerBuild(ctx, request, params)
 	default:
 		return nil, nil, actionError("container", params.Action, containerActions)
 	}
@@ -44,15 +57,15 @@ func (s *Server) handleContainer(ctx context.Context, request *mcp.CallToolReque
 
 func (s *Server) handleContainerStart(ctx context.Context, request *mcp.CallToolRequest, params *ContainerParams) (*mcp.CallToolResult, any, error) {
 	if params.ProjectID == "" {
-		return nil, nil, fmt.Errorf("project_id is required")
+		return nil, nil, invalidParams("project_id is required")
 	}
 
 	authCtx, err := requireProjectAccess(ctx, params.ProjectID)
 	if err != nil {
 		return nil, nil, err
 	}
-	if !authCtx.CanWrite() {
-		return nil, nil, fmt.Errorf("read-only access, cannot start container")
+	if !authCtx.CanWriteProject(params.ProjectID) {
+		return nil, nil, forbidden("read-only access, cannot start container")
 	}
 
 	logger.Info("Spawning container for project: %s", params.ProjectID)
@@ -63,8 +76,12 @@ func (s *Server) handleContainerStart(ctx context.Context, request *mcp.CallTool
 		return nil, nil, err
 	}
 
-	containerName := fmt.Sprintf("oubliette-%s", params.ProjectID[:8])
+	containerName, err := container.ProjectContainerName(params.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
 	containerID, err := s.createAndStartContainer(ctx, containerName, proj.ImageName, params.ProjectID)
+	logAudit(ctx, &audit.Event{Operation: audit.OpContainerStart, ProjectID: params.ProjectID}, err)
 	if err != nil {
 		logger.Error("Failed to spawn container for %s: %v", params.ProjectID, err)
 		return nil, nil, err
@@ -83,15 +100,76 @@ func (s *Server) handleContainerStart(ctx context.Context, request *mcp.CallTool
 	}, nil, nil
 }
 
+func (s *Server) handleContainerBuild(ctx context.Context, request *mcp.CallToolRequest, params *ContainerParams) (*mcp.CallToolResult, any, error) {
+	if params.ProjectID == "" {
+		return nil, nil, invalidParams("project_id is required")
+	}
+
+	authCtx, err := requireProjectAccess(ctx, params.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !authCtx.CanWriteProject(params.ProjectID) {
+		return nil, nil, forbidden("read-only access, cannot build image")
+	}
+
+	proj, err := s.projectMgr.Get(params.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	img, err := s.ensureProjectImage(ctx, proj, params.Force)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build image: %w", err)
+	}
+
+	result := "✅ Image up to date\n\n"
+	if img.Built {
+		result = "✅ Image built successfully\n\n"
+	}
+	result += fmt.Sprintf("Image: %s\n", img.Image)
+	result += fmt.Sprintf("Base image: %s\n", proj.ImageName)
+	if img.Built {
+		result += "\nUse container start to recreate the container with the new image.\n"
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
+
 func (s *Server) handleContainerExec(ctx context.Context, request *mcp.CallToolRequest, params *ContainerParams) (*mcp.CallToolResult, any, error) {
 	if params.ProjectID == "" {
-		return nil, nil, fmt.Errorf("project_id is required")
+		return nil, nil, invalidParams("project_id is required")
 	}
 	if params.Command == "" {
-		return nil, nil, fmt.Errorf("command is required")
+		return nil, nil, invalidParams("command is required")
+	}
+
+	authCtx, err := requireProjectAccess(ctx, params.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !authCtx.CanWriteProject(params.ProjectID) {
+		return nil, nil, forbidden("read-only access, cannot exec in container")
+	}
+
+	containerName, err := container.ProjectContainerName(params.ProjectID)
+	if err != nil {
+		return nil, nil, err
 	}
+	s.idle.Touch(params.ProjectID)
 
-	containerName := fmt.Sprintf("oubliette-%s", params.ProjectID[:8])
+	if params.Stream {
+		return s.streamContainerExec(ctx, request, params, containerName)
+	}
+	if params.TimeoutSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, streamTimeout(params.TimeoutSeconds, 0))
+		defer cancel()
+	}
 
 	execResult, err := s.runtime.Exec(ctx, containerName, container.ExecConfig{
 		Cmd:          []string{"/bin/bash", "-c", params.Command},
@@ -100,6 +178,16 @@ func (s *Server) handleContainerExec(ctx context.Context, request *mcp.CallToolR
 		AttachStderr: true,
 	})
 
+	event := &audit.Event{
+		Operation: audit.OpContainerExec,
+		ProjectID: params.ProjectID,
+		Details:   map[string]interface{}{"command": params.Command, "working_dir": params.WorkingDir},
+	}
+	if execResult != nil {
+		event.Details["exit_code"] = execResult.ExitCode
+	}
+	logAudit(ctx, event, err)
+
 	var output string
 	if execResult != nil {
 		output = execResult.Stdout
@@ -126,14 +214,24 @@ func (s *Server) handleContainerExec(ctx context.Context, request *mcp.CallToolR
 
 func (s *Server) handleContainerStop(ctx context.Context, request *mcp.CallToolRequest, params *ContainerParams) (*mcp.CallToolResult, any, error) {
 	if params.ProjectID == "" {
-		return nil, nil, fmt.Errorf("project_id is required")
+		return nil, nil, invalidParams("project_id is required")
 	}
 
-	logger.Info("Stopping container for project: %s", params.ProjectID)
+	authCtx, err := requireProjectAccess(ctx, params.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !authCtx.CanWriteProject(params.ProjectID) {
+		return nil, nil, forbidden("read-only access, cannot stop container")
+	}
 
-	containerName := fmt.Sprintf("oubliette-%s", params.ProjectID[:8])
+	logger.Info("Stopping container for project: %s", params.ProjectID)
 
-	if err := s.runtime.Stop(ctx, containerName); err != nil {
+	err = s.stopProjectContainer(ctx, params.ProjectID, &project.ContainerStop{
+		Reason:    project.StopReasonManual,
+		StoppedAt: time.Now().UTC(),
+	})
+	if err != nil {
 		logger.Error("Failed to stop container for %s: %v", params.ProjectID, err)
 		return nil, nil, err
 	}
@@ -149,10 +247,21 @@ func (s *Server) handleContainerStop(ctx context.Context, request *mcp.CallToolR
 
 func (s *Server) handleContainerLogs(ctx context.Context, request *mcp.CallToolRequest, params *ContainerParams) (*mcp.CallToolResult, any, error) {
 	if params.ProjectID == "" {
-		return nil, nil, fmt.Errorf("project_id is required")
+		return nil, nil, invalidParams("project_id is required")
 	}
 
-	containerName := fmt.Sprintf("oubliette-%s", params.ProjectID[:8])
+	if _, err := requireProjectAccess(ctx, params.ProjectID); err != nil {
+		return nil, nil, err
+	}
+
+	containerName, err := container.ProjectContainerName(params.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if params.Follow {
+		return s.followContainerLogs(ctx, request, params, containerName)
+	}
 
 	logs, err := s.runtime.Logs(ctx, containerName, container.LogsOptions{Tail: "1000"})
 	if err != nil {
@@ -174,7 +283,7 @@ type ContainerRefreshParams struct {
 
 func (s *Server) handleContainerRefresh(ctx context.Context, request *mcp.CallToolRequest, params *ContainerRefreshParams) (*mcp.CallToolResult, any, error) {
 	if params.ProjectID == "" && params.ContainerType == "" {
-		return nil, nil, fmt.Errorf("either project_id or container_type is required")
+		return nil, nil, invalidParams("either project_id or container_type is required")
 	}
 
 	if s.imageManager == nil {
@@ -183,7 +292,7 @@ func (s *Server) handleContainerRefresh(ctx context.Context, request *mcp.CallTo
 
 	if params.ContainerType != "" {
 		if !s.imageManager.IsValidType(params.ContainerType) {
-			return nil, nil, fmt.Errorf("invalid container_type: %s (valid types: %v)", params.ContainerType, s.imageManager.ValidTypes())
+			return nil, nil, invalidParams("invalid container_type: %s (valid types: %v)", params.ContainerType, s.imageManager.ValidTypes())
 		}
 
 		imageName, _ := s.imageManager.GetImageName(params.ContainerType)
@@ -206,32 +315,81 @@ func (s *Server) handleContainerRefresh(ctx context.Context, request *mcp.CallTo
 	}
 
 	if s.activeSessions.CountByProject(params.ProjectID) > 0 {
-		return nil, nil, fmt.Errorf("cannot refresh container: project has active sessions (stop sessions first)")
+		return nil, nil, conflict("cannot refresh container: project has active sessions (stop sessions first)")
 	}
 
-	containerName := fmt.Sprintf("oubliette-%s", params.ProjectID[:8])
+	containerName, err := container.ProjectContainerName(params.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	logger.Info("Refreshing container for project %s (image: %s)", params.ProjectID, proj.ImageName)
 	if err := s.runtime.Pull(ctx, proj.ImageName); err != nil {
 		return nil, nil, fmt.Errorf("failed to pull image %s: %w", proj.ImageName, err)
 	}
 
+	// Rebuild the custom image on top of the freshly pulled base
+	imageName := proj.ImageName
+	if proj.HasDockerfile {
+		img, err := s.ensureProjectImage(ctx, proj, true)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to build image: %w", err)
+		}
+		imageName = img.Image
+	}
+
 	_ = s.runtime.Stop(ctx, containerName)
 	_ = s.runtime.Remove(ctx, containerName, true)
 
 	containerID, err := s.createAndStartContainer(ctx, containerName, proj.ImageName, params.ProjectID)
+	logAudit(ctx, &audit.Event{
+		Operation: audit.OpContainerStart,
+		ProjectID: params.ProjectID,
+		Details:   map[string]interface{}{"refresh": true, "image": imageName},
+	}, err)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to start refreshed container: %w", err)
 	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: fmt.Sprintf("✅ Container refreshed successfully\n\nContainer ID: %s\nImage: %s", containerID[:12], proj.ImageName)},
+			&mcp.TextContent{Text: fmt.Sprintf("✅ Container refreshed successfully\n\nContainer ID: %s\nImage: %s", containerID[:12], imageName)},
 		},
 	}, nil, nil
 }
 
 func (s *Server) createAndStartContainer(ctx context.Context, containerName, imageName, projectName string) (string, error) {
+	ctx, span := tracing.Start(ctx, "container.start",
+		attribute.String("oubliette.project_id", projectName),
+		attribute.String("container.name", containerName),
+		attribute.String("container.image.name", imageName),
+	)
+	start := time.Now()
+	containerID, err := s.startContainer(ctx, containerName, imageName, projectName)
+	metrics.RecordContainerStart(time.Since(start), err)
+	tracing.End(span, err)
+	if err == nil {
+		_ = s.projectMgr.ClearContainerStop(projectName)
+		s.idle.Touch(projectName)
+	}
+	return containerID, err
+}
+
+func (s *Server) startContainer(ctx context.Context, containerName, imageName, projectName string) (string, error) {
+	proj, err := s.projectMgr.Get(projectName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get project: %w", err)
+	}
+
+	// Projects with a Dockerfile run their own image, built when its content changes
+	if proj.HasDockerfile {
+		img, err := s.ensureProjectImage(ctx, proj, false)
+		if err != nil {
+			return "", fmt.Errorf("failed to build project image: %w", err)
+		}
+		imageName = img.Image
+	}
+
 	exists, err := s.runtime.ImageExists(ctx, imageName)
 	if err != nil {
 		logger.Error("Failed to check image %s: %v", imageName, err)
@@ -243,28 +401,21 @@ func (s *Server) createAndStartContainer(ctx context.Context, containerName, ima
 		logger.Info("Pulled image %s successfully", imageName)
 	}
 
+	if containerID := s.claimWarmContainer(ctx, proj, containerName, imageName); containerID != "" {
+		return containerID, nil
+	}
+
 	_ = s.runtime.Stop(ctx, containerName)
 	_ = s.runtime.Remove(ctx, containerName, true)
 
 	projectDir := s.projectMgr.GetProjectDir(projectName)
-
-	_ = os.MkdirAll(filepath.Join(projectDir, "cache", "npm"), 0o755)
-	_ = os.MkdirAll(filepath.Join(projectDir, "cache", "pip"), 0o755)
-	_ = os.MkdirAll(filepath.Join(projectDir, "cache", "maven"), 0o755)
-	_ = os.MkdirAll(filepath.Join(projectDir, "cache", "gradle"), 0o755)
-	_ = os.MkdirAll(filepath.Join(projectDir, "history"), 0o755)
-	_ = os.MkdirAll(filepath.Join(projectDir, "ssh"), 0o755)
+	ensureMountDirs(projectDir)
 
 	if err := EnsureSocketDir(projectName); err != nil {
 		logger.Error("Failed to create socket directory: %v", err)
 		return "", fmt.Errorf("failed to create socket directory: %w", err)
 	}
 
-	proj, err := s.projectMgr.Get(projectName)
-	if err != nil {
-		return "", fmt.Errorf("failed to get project: %w", err)
-	}
-
 	var workspaceSource string
 	var additionalMounts []container.Mount
 	if proj.WorkspaceIsolation {
@@ -292,6 +443,36 @@ func (s *Server) createAndStartContainer(ctx context.Context, containerName, ima
 		workspaceSource = projectDir
 	}
 
+	cfg := s.containerConfig(containerName, imageName, projectDir, workspaceSource, SocketPath(projectName), proj.WorkspaceIsolation)
+	cfg.Mounts = append(cfg.Mounts, additionalMounts...)
+	cfg.Env = append(cfg.Env, fmt.Sprintf("OUBLIETTE_PROJECT_ID=%s", projectName))
+
+	containerID, err := s.runtime.Create(ctx, cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to create container: %w", err)
+	}
+
+	if err := s.runtime.Start(ctx, containerID); err != nil {
+		return "", fmt.Errorf("failed to start container: %w", err)
+	}
+
+	return containerID, nil
+}
+
+// ensureMountDirs creates the cache, history and ssh directories mounted into
+// a container from projectDir
+func ensureMountDirs(projectDir string) {
+	_ = os.MkdirAll(filepath.Join(projectDir, "cache", "npm"), 0o755)
+	_ = os.MkdirAll(filepath.Join(projectDir, "cache", "pip"), 0o755)
+	_ = os.MkdirAll(filepath.Join(projectDir, "cache", "maven"), 0o755)
+	_ = os.MkdirAll(filepath.Join(projectDir, "cache", "gradle"), 0o755)
+	_ = os.MkdirAll(filepath.Join(projectDir, "history"), 0o755)
+	_ = os.MkdirAll(filepath.Join(projectDir, "ssh"), 0o755)
+}
+
+// containerConfig builds the create config shared by project and pool
+// containers. The relay only starts when OUBLIETTE_PROJECT_ID is in the env.
+func (s *Server) containerConfig(containerName, imageName, projectDir, workspaceSource, socketPath string, workspaceIsolation bool) container.CreateConfig {
 	mounts := []container.Mount{
 		{Type: container.MountTypeBind, Source: workspaceSource, Target: "/workspace"},
 		{Type: container.MountTypeBind, Source: filepath.Join(projectDir, "cache", "npm"), Target: "/home/gogol/.npm"},
@@ -302,8 +483,6 @@ func (s *Server) createAndStartContainer(ctx context.Context, containerName, ima
 		{Type: container.MountTypeBind, Source: filepath.Join(projectDir, "ssh"), Target: "/home/gogol/.ssh"},
 	}
 
-	mounts = append(mounts, additionalMounts...)
-
 	cfg := container.CreateConfig{
 		Name:       containerName,
 		Image:      imageName,
@@ -313,13 +492,12 @@ func (s *Server) createAndStartContainer(ctx context.Context, containerName, ima
 			"HOME=/home/gogol",
 			"HISTFILE=/home/gogol/.shell_history/bash_history",
 			"MAX_MCP_OUTPUT_TOKENS=50000",
-			fmt.Sprintf("OUBLIETTE_PROJECT_ID=%s", projectName),
-			fmt.Sprintf("OUBLIETTE_WORKSPACE_ISOLATION=%t", proj.WorkspaceIsolation),
+			fmt.Sprintf("OUBLIETTE_WORKSPACE_ISOLATION=%t", workspaceIsolation),
 		},
 		Mounts: mounts,
 		PublishedSockets: []container.PublishedSocket{
 			{
-				HostPath:      SocketPath(projectName),
+				HostPath:      socketPath,
 				ContainerPath: "/mcp/relay.sock",
 			},
 		},
@@ -329,8 +507,8 @@ func (s *Server) createAndStartContainer(ctx context.Context, containerName, ima
 		CPUs:        s.containerCPUs,
 	}
 
-	if s.credentials != nil {
-		if provCred, ok := s.credentials.GetDefaultProviderCredential(); ok && provCred.APIKey != "" {
+	if credentials := s.credentialRegistry(); credentials != nil {
+		if provCred, ok := credentials.GetDefaultProviderCredential(); ok && provCred.APIKey != "" {
 			envVar := config.ProviderEnvVar(provCred.Provider)
 			if envVar != "" {
 				cfg.Env = append(cfg.Env, fmt.Sprintf("%s=%s", envVar, provCred.APIKey))
@@ -338,14 +516,5 @@ func (s *Server) createAndStartContainer(ctx context.Context, containerName, ima
 		}
 	}
 
-	containerID, err := s.runtime.Create(ctx, cfg)
-	if err != nil {
-		return "", fmt.Errorf("failed to create container: %w", err)
-	}
-
-	if err := s.runtime.Start(ctx, containerID); err != nil {
-		return "", fmt.Errorf("failed to start container: %w", err)
-	}
-
-	return containerID, nil
+	return cfg
 }
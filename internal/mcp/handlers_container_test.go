@@ -1,7 +1,12 @@
 package mcp
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/HyphaGroup/oubliette/internal/auth"
 )
 
 func TestContainerParams(t *testing.T) {
@@ -55,3 +60,61 @@ func TestContainerRefreshParams(t *testing.T) {
 		}
 	})
 }
+
+func TestBuildLogWriter(t *testing.T) {
+	w := newBuildLogWriter(context.Background(), "550e8400-e29b-41d4-a716-446655440000")
+
+	_, _ = w.Write([]byte("Step 1/2 : FROM base\n Step 2"))
+	_, _ = w.Write([]byte("/2 : RUN make\r\n\n"))
+	_, _ = w.Write([]byte("Successfully built"))
+	w.Flush()
+
+	want := []string{"Step 1/2 : FROM base", " Step 2/2 : RUN make", "Successfully built"}
+	got := w.Tail()
+	if len(got) != len(want) {
+		t.Fatalf("Tail() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	for i := 0; i < buildLogTailLines+5; i++ {
+		_, _ = fmt.Fprintf(w, "line %d\n", i)
+	}
+	if tail := w.Tail(); len(tail) != buildLogTailLines || tail[len(tail)-1] != fmt.Sprintf("line %d", buildLogTailLines+4) {
+		t.Errorf("Tail() kept %d lines ending %q", len(tail), tail[len(tail)-1])
+	}
+}
+
+func TestContainerHandlersCheckProjectAccess(t *testing.T) {
+	s, _, projectID := newProjectTestServer(t)
+	other := "project:00000000-0000-0000-0000-000000000000"
+	readOnly := "project:" + projectID + ":ro"
+
+	tests := []struct {
+		name   string
+		scope  string
+		params ContainerParams
+		want   string
+	}{
+		{"exec other project", other, ContainerParams{Action: "exec", ProjectID: projectID, Command: "true"}, "not authorized"},
+		{"exec read-only", readOnly, ContainerParams{Action: "exec", ProjectID: projectID, Command: "true"}, "read-only access"},
+		{"stream exec read-only", readOnly, ContainerParams{Action: "exec", ProjectID: projectID, Command: "true", Stream: true}, "read-only access"},
+		{"stop other project", other, ContainerParams{Action: "stop", ProjectID: projectID}, "not authorized"},
+		{"stop read-only", readOnly, ContainerParams{Action: "stop", ProjectID: projectID}, "read-only access"},
+		{"logs other project", other, ContainerParams{Action: "logs", ProjectID: projectID}, "not authorized"},
+		{"follow logs other project", other, ContainerParams{Action: "logs", ProjectID: projectID, Follow: true}, "not authorized"},
+		{"short project ID", auth.ScopeAdmin, ContainerParams{Action: "logs", ProjectID: "abc"}, "invalid project ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := auth.WithContext(context.Background(), &auth.AuthContext{Token: &auth.Token{ID: "test", Scope: tt.scope}})
+			_, _, err := s.handleContainer(ctx, nil, &tt.params)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("handleContainer() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
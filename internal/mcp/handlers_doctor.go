@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/HyphaGroup/oubliette/internal/doctor"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DoctorParams is the params struct for the doctor tool
+type DoctorParams struct {
+	Action string `json:"action"` // Required: run, pull
+}
+
+var doctorActions = []string{"run", "pull"}
+
+func (s *Server) handleDoctor(ctx context.Context, request *mcp.CallToolRequest, params *DoctorParams) (*mcp.CallToolResult, any, error) {
+	if params.Action == "" {
+		return nil, nil, missingActionError("doctor", doctorActions)
+	}
+
+	switch params.Action {
+	case "run", "pull":
+		return s.handleDoctorRun(ctx, request, params)
+	default:
+		return nil, nil, actionError("doctor", params.Action, doctorActions)
+	}
+}
+
+func (s *Server) handleDoctorRun(ctx context.Context, request *mcp.CallToolRequest, params *DoctorParams) (*mcp.CallToolResult, any, error) {
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	s.configMu.RLock()
+	current := s.config
+	s.configMu.RUnlock()
+	if current == nil || current.ConfigDir == "" || s.dataDir == "" {
+		return nil, nil, fmt.Errorf("doctor is not available: server was not started from a config file")
+	}
+
+	report := doctor.Run(ctx, doctor.Options{
+		ConfigDir:  current.ConfigDir,
+		DataDir:    s.dataDir,
+		SocketsDir: SocketsBaseDir,
+		Runtime:    s.runtime,
+		Images:     s.imageManager,
+		Pull:       params.Action == "pull",
+	})
+
+	reportJSON, _ := json.MarshalIndent(report, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: report.Summary()},
+			&mcp.TextContent{Text: string(reportJSON)},
+		},
+	}, report, nil
+}
@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/HyphaGroup/oubliette/internal/auth"
+	"github.com/HyphaGroup/oubliette/internal/config"
+	"github.com/HyphaGroup/oubliette/internal/doctor"
+	"github.com/HyphaGroup/oubliette/internal/testutil"
+)
+
+func TestHandleDoctor(t *testing.T) {
+	configDir := t.TempDir()
+	dataDir := t.TempDir()
+	writeReloadConfig(t, configDir, `"sonnet": {"model": "claude-sonnet-4-5", "provider": "anthropic"}`, 1, ":8080")
+	if err := os.MkdirAll(filepath.Join(dataDir, "projects"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadAll(configDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{runtime: testutil.NewFakeRuntime(), config: cfg, dataDir: dataDir}
+
+	admin := auth.WithContext(context.Background(), &auth.AuthContext{
+		Type:  auth.AuthTypeToken,
+		Token: &auth.Token{ID: "test", Scope: auth.ScopeAdmin},
+	})
+	_, structured, err := s.handleDoctor(admin, nil, &DoctorParams{Action: "run"})
+	if err != nil {
+		t.Fatalf("handleDoctor() error = %v", err)
+	}
+	report := structured.(*doctor.Report)
+	if len(report.Checks) == 0 || report.Checks[0].Name != doctor.CheckConfig {
+		t.Errorf("report = %+v, want checks starting with config", report)
+	}
+
+	projectScoped := auth.WithContext(context.Background(), &auth.AuthContext{
+		Type:  auth.AuthTypeToken,
+		Token: &auth.Token{ID: "test", Scope: "project:proj-1"},
+	})
+	if _, _, err := s.handleDoctor(projectScoped, nil, &DoctorParams{Action: "run"}); err == nil {
+		t.Error("handleDoctor() allowed a non-admin token")
+	}
+	if _, _, err := (&Server{}).handleDoctor(admin, nil, &DoctorParams{Action: "run"}); err == nil {
+		t.Error("handleDoctor() without a config succeeded")
+	}
+}
@@ -77,7 +77,7 @@ func (s *Server) handleCreateProject(ctx context.Context, request *mcp.CallToolR
 	}
 
 	if params.Name == "" {
-		return nil, nil, fmt.Errorf("name is required")
+		return nil, nil, invalidParams("name is required")
 	}
 
 	logger.Info("Creating project: %s", params.Name)
@@ -97,16 +97,16 @@ func (s *Server) handleCreateProject(ctx context.Context, request *mcp.CallToolR
 
 	// Resolve GitHub token: explicit token > credential_refs > default
 	githubToken := params.GitHubToken
-	if githubToken == "" && s.credentials != nil {
+	if credentials := s.credentialRegistry(); githubToken == "" && credentials != nil {
 		if params.CredentialRefs != nil && params.CredentialRefs.GitHub != "" {
 			// Validate and lookup specified credential
-			if !s.credentials.HasGitHubCredential(params.CredentialRefs.GitHub) {
-				return nil, nil, fmt.Errorf("unknown github credential: %s (use project_options to list available credentials)", params.CredentialRefs.GitHub)
+			if !credentials.HasGitHubCredential(params.CredentialRefs.GitHub) {
+				return nil, nil, invalidParams("unknown github credential: %s (use project_options to list available credentials)", params.CredentialRefs.GitHub)
 			}
-			githubToken, _ = s.credentials.GetGitHubToken(params.CredentialRefs.GitHub)
+			githubToken, _ = credentials.GetGitHubToken(params.CredentialRefs.GitHub)
 		} else {
 			// Use default credential
-			githubToken, _ = s.credentials.GetDefaultGitHubToken()
+			githubToken, _ = credentials.GetDefaultGitHubToken()
 		}
 	}
 
@@ -121,7 +121,7 @@ func (s *Server) handleCreateProject(ctx context.Context, request *mcp.CallToolR
 			}
 		}
 		if !found {
-			return nil, nil, fmt.Errorf("invalid autonomy: %s (must be one of: off, low, medium, high)", params.Autonomy)
+			return nil, nil, invalidParams("invalid autonomy: %s (must be one of: off, low, medium, high)", params.Autonomy)
 		}
 	}
 
@@ -136,7 +136,7 @@ func (s *Server) handleCreateProject(ctx context.Context, request *mcp.CallToolR
 			}
 		}
 		if !found {
-			return nil, nil, fmt.Errorf("invalid reasoning: %s (must be one of: off, low, medium, high)", params.Reasoning)
+			return nil, nil, invalidParams("invalid reasoning: %s (must be one of: off, low, medium, high)", params.Reasoning)
 		}
 	}
 
@@ -146,7 +146,7 @@ func (s *Server) handleCreateProject(ctx context.Context, request *mcp.CallToolR
 			return nil, nil, fmt.Errorf("container types not configured")
 		}
 		if !s.imageManager.IsValidType(params.ContainerType) {
-			return nil, nil, fmt.Errorf("invalid container_type: %s (valid types: %v)", params.ContainerType, s.imageManager.ValidTypes())
+			return nil, nil, invalidParams("invalid container_type: %s (valid types: %v)", params.ContainerType, s.imageManager.ValidTypes())
 		}
 	}
 
@@ -224,7 +224,10 @@ func (s *Server) handleCreateProject(ctx context.Context, request *mcp.CallToolR
 	// Auto-start container after project creation
 	logger.Info("Starting container for new project: %s", proj.ID)
 
-	containerName := fmt.Sprintf("oubliette-%s", proj.ID[:8])
+	containerName, err := container.ProjectContainerName(proj.ID)
+	if err != nil {
+		return nil, nil, err
+	}
 	containerID, err := s.createAndStartContainer(ctx, containerName, proj.ImageName, proj.ID)
 	if err != nil {
 		logger.Error("Failed to start container for project %s: %v", proj.ID, err)
@@ -297,7 +300,7 @@ func (s *Server) handleListProjects(ctx context.Context, request *mcp.CallToolRe
 			result += fmt.Sprintf("  Remote: %s\n", proj.RemoteURL)
 		}
 
-		containerName := fmt.Sprintf("oubliette-%s", proj.ID[:8])
+		containerName, _ := container.ProjectContainerName(proj.ID)
 		if status, err := s.runtime.Status(ctx, containerName); err == nil {
 			result += fmt.Sprintf("  Container: %s\n", status)
 		} else {
@@ -316,7 +319,7 @@ func (s *Server) handleListProjects(ctx context.Context, request *mcp.CallToolRe
 
 func (s *Server) handleGetProject(ctx context.Context, request *mcp.CallToolRequest, params *ProjectParams) (*mcp.CallToolResult, any, error) {
 	if params.ProjectID == "" {
-		return nil, nil, fmt.Errorf("project_id is required")
+		return nil, nil, invalidParams("project_id is required")
 	}
 
 	if _, err := requireProjectAccess(ctx, params.ProjectID); err != nil {
@@ -335,14 +338,24 @@ func (s *Server) handleGetProject(ctx context.Context, request *mcp.CallToolRequ
 	}
 	result += fmt.Sprintf("Image: %s\n", proj.ImageName)
 	result += fmt.Sprintf("Custom Dockerfile: %v\n", proj.HasDockerfile)
+	if proj.CustomImage != "" {
+		result += fmt.Sprintf("Custom Image: %s\n", proj.CustomImage)
+	}
 	result += fmt.Sprintf("Workspace: %s\n", s.projectMgr.GetWorkspaceDir(proj.ID))
 
-	containerName := fmt.Sprintf("oubliette-%s", proj.ID[:8])
+	containerName, _ := container.ProjectContainerName(proj.ID)
 	if status, err := s.runtime.Status(ctx, containerName); err == nil {
 		result += fmt.Sprintf("Container Status: %s\n", status)
 	} else {
 		result += "Container Status: not running\n"
 	}
+	if stop, err := s.projectMgr.GetContainerStop(proj.ID); err == nil && stop != nil {
+		result += fmt.Sprintf("Container Stopped: %s at %s", stop.Reason, stop.StoppedAt.Format("2006-01-02 15:04:05"))
+		if stop.IdleFor != "" {
+			result += fmt.Sprintf(" (idle for %s; wakes on next session message)", stop.IdleFor)
+		}
+		result += "\n"
+	}
 
 	if proj.RemoteURL != "" {
 		result += fmt.Sprintf("Git remote: %s\n", proj.RemoteURL)
@@ -359,33 +372,40 @@ func (s *Server) handleGetProject(ctx context.Context, request *mcp.CallToolRequ
 
 func (s *Server) handleDeleteProject(ctx context.Context, request *mcp.CallToolRequest, params *ProjectParams) (*mcp.CallToolResult, any, error) {
 	if params.ProjectID == "" {
-		return nil, nil, fmt.Errorf("project_id is required")
+		return nil, nil, invalidParams("project_id is required")
 	}
 
 	authCtx, err := requireProjectAccess(ctx, params.ProjectID)
 	if err != nil {
 		return nil, nil, err
 	}
-	if !authCtx.CanWrite() {
-		return nil, nil, fmt.Errorf("read-only access, cannot delete project")
+	if !authCtx.CanWriteProject(params.ProjectID) {
+		return nil, nil, forbidden("read-only access, cannot delete project")
 	}
 
 	tokenID, tokenScope := getTokenInfo(authCtx)
 	logger.Info("Deleting project: %s", params.ProjectID)
 
-	containerName := fmt.Sprintf("oubliette-%s", params.ProjectID[:8])
+	containerName, err := container.ProjectContainerName(params.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
 	_ = s.runtime.Stop(ctx, containerName)
 	_ = s.runtime.Remove(ctx, containerName, true)
 
 	if err := CleanupSocketDir(params.ProjectID); err != nil {
 		logger.Error("Failed to cleanup socket dir for project %s: %v", params.ProjectID, err)
 	}
+	s.removeProjectImage(ctx, params.ProjectID)
 
 	if err := s.projectMgr.Delete(params.ProjectID); err != nil {
 		logger.Error("Failed to delete project %s: %v", params.ProjectID, err)
 		audit.LogFailure(audit.OpProjectDelete, tokenID, tokenScope, params.ProjectID, err)
 		return nil, nil, err
 	}
+	if err := s.sessionMgr.DeleteProjectSessions(params.ProjectID); err != nil {
+		logger.Error("Failed to delete sessions for project %s: %v", params.ProjectID, err)
+	}
 
 	audit.LogSuccess(audit.OpProjectDelete, tokenID, tokenScope, params.ProjectID)
 	logger.Info("Project deleted successfully: %s", params.ProjectID)
@@ -427,7 +447,7 @@ type OpenSpecChangeInfo struct {
 
 func (s *Server) handleProjectChanges(ctx context.Context, request *mcp.CallToolRequest, params *ProjectChangesParams) (*mcp.CallToolResult, any, error) {
 	if params.ProjectID == "" {
-		return nil, nil, fmt.Errorf("project_id is required")
+		return nil, nil, invalidParams("project_id is required")
 	}
 
 	_, err := requireProjectAccess(ctx, params.ProjectID)
@@ -436,7 +456,10 @@ func (s *Server) handleProjectChanges(ctx context.Context, request *mcp.CallTool
 	}
 
 	// Execute openspec list --json in the container
-	containerName := fmt.Sprintf("oubliette-%s", params.ProjectID[:8])
+	containerName, err := container.ProjectContainerName(params.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Run openspec list --json --sort name (working dir is /workspace inside container)
 	execResult, err := s.runtime.Exec(ctx, containerName, container.ExecConfig{
@@ -505,10 +528,10 @@ type ProjectTasksParams struct {
 
 func (s *Server) handleProjectTasks(ctx context.Context, request *mcp.CallToolRequest, params *ProjectTasksParams) (*mcp.CallToolResult, any, error) {
 	if params.ProjectID == "" {
-		return nil, nil, fmt.Errorf("project_id is required")
+		return nil, nil, invalidParams("project_id is required")
 	}
 	if params.ChangeID == "" {
-		return nil, nil, fmt.Errorf("change_id is required")
+		return nil, nil, invalidParams("change_id is required")
 	}
 
 	_, err := requireProjectAccess(ctx, params.ProjectID)
@@ -517,7 +540,10 @@ func (s *Server) handleProjectTasks(ctx context.Context, request *mcp.CallToolRe
 	}
 
 	// Execute openspec instructions apply --json in the container
-	containerName := fmt.Sprintf("oubliette-%s", params.ProjectID[:8])
+	containerName, err := container.ProjectContainerName(params.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Run openspec instructions apply --change <change_id> --json (working dir is /workspace inside container)
 	cmd := fmt.Sprintf(
@@ -581,6 +607,8 @@ type CredentialOptionInfo struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	IsDefault   bool   `json:"is_default,omitempty"`
+	Source      string `json:"source"`
+	Masked      string `json:"masked,omitempty"`
 }
 
 type ProviderCredentialOptionInfo struct {
@@ -588,6 +616,8 @@ type ProviderCredentialOptionInfo struct {
 	Provider    string `json:"provider"`
 	Description string `json:"description"`
 	IsDefault   bool   `json:"is_default,omitempty"`
+	Source      string `json:"source"`
+	Masked      string `json:"masked,omitempty"`
 }
 
 type ModelsOptionsResponse struct {
@@ -625,8 +655,8 @@ func (s *Server) handleProjectOptions(ctx context.Context, request *mcp.CallTool
 	}
 
 	// Add credentials if configured
-	if s.credentials != nil {
-		credsList := s.credentials.ListCredentials()
+	if credentials := s.credentialRegistry(); credentials != nil {
+		credsList := credentials.ListCredentials()
 
 		github := make([]CredentialOptionInfo, len(credsList.GitHub))
 		for i, c := range credsList.GitHub {
@@ -634,6 +664,8 @@ func (s *Server) handleProjectOptions(ctx context.Context, request *mcp.CallTool
 				Name:        c.Name,
 				Description: c.Description,
 				IsDefault:   c.IsDefault,
+				Source:      c.Source,
+				Masked:      c.Masked,
 			}
 		}
 
@@ -644,6 +676,8 @@ func (s *Server) handleProjectOptions(ctx context.Context, request *mcp.CallTool
 				Provider:    c.Provider,
 				Description: c.Description,
 				IsDefault:   c.IsDefault,
+				Source:      c.Source,
+				Masked:      c.Masked,
 			}
 		}
 
@@ -654,8 +688,8 @@ func (s *Server) handleProjectOptions(ctx context.Context, request *mcp.CallTool
 	}
 
 	// Add models if configured
-	if s.modelRegistry != nil && len(s.modelRegistry.Models) > 0 {
-		models := s.modelRegistry.ListModels()
+	if registry := s.models(); registry != nil && len(registry.Models) > 0 {
+		models := registry.ListModels()
 		available := make([]ModelOptionInfo, len(models))
 		for i, m := range models {
 			available[i] = ModelOptionInfo{
@@ -692,6 +726,8 @@ func (s *Server) handleProjectOptions(ctx context.Context, request *mcp.CallTool
 			{Format: "admin:ro", Description: "Read-only access to all tools and all projects", Example: "admin:ro"},
 			{Format: "project:<uuid>", Description: "Full access to one project only", Example: "project:proj_abc123"},
 			{Format: "project:<uuid>:ro", Description: "Read-only access to one project only", Example: "project:proj_abc123:ro"},
+			{Format: "tool:<name>[:<action>|:ro]", Description: "Allow only the listed tools or actions (combine with a project or admin entry)", Example: "project:proj_abc123 tool:session:message"},
+			{Format: "deny:<name>[:<action>]", Description: "Deny a tool or one of its actions", Example: "admin deny:container:exec"},
 		},
 	}
 
@@ -0,0 +1,271 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/project"
+	"github.com/HyphaGroup/oubliette/internal/prompt"
+	"github.com/HyphaGroup/oubliette/internal/schedule"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// PromptParams is the params struct for the prompt tool
+type PromptParams struct {
+	Action string `json:"action"` // Required: save, list, get, delete, render
+
+	ProjectID   string            `json:"project_id,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Body        string            `json:"body,omitempty"`
+	Variables   map[string]string `json:"variables,omitempty"`
+	WorkspaceID string            `json:"workspace_id,omitempty"`
+	Message     string            `json:"message,omitempty"`
+}
+
+var promptActions = []string{"save", "list", "get", "delete", "render"}
+
+func (s *Server) handlePrompt(ctx context.Context, request *mcp.CallToolRequest, params *PromptParams) (*mcp.CallToolResult, any, error) {
+	if params.Action == "" {
+		return nil, nil, missingActionError("prompt", promptActions)
+	}
+
+	switch params.Action {
+	case "save":
+		return s.handlePromptSave(ctx, request, params)
+	case "list":
+		return s.handlePromptList(ctx, request, params)
+	case "get":
+		return s.handlePromptGet(ctx, request, params)
+	case "delete":
+		return s.handlePromptDelete(ctx, request, params)
+	case "render":
+		return s.handlePromptRender(ctx, request, params)
+	default:
+		return nil, nil, actionError("prompt", params.Action, promptActions)
+	}
+}
+
+func (s *Server) handlePromptSave(ctx context.Context, request *mcp.CallToolRequest, params *PromptParams) (*mcp.CallToolResult, any, error) {
+	if params.ProjectID == "" {
+		return nil, nil, invalidParams("project_id is required")
+	}
+	if params.Name == "" {
+		return nil, nil, invalidParams("name is required")
+	}
+	if params.Body == "" {
+		return nil, nil, invalidParams("body is required")
+	}
+
+	authCtx, err := requireProjectAccess(ctx, params.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !authCtx.CanWriteProject(params.ProjectID) {
+		return nil, nil, forbidden("read-only access, cannot save prompt templates")
+	}
+
+	tmpl := &project.PromptTemplate{
+		Name:        params.Name,
+		Description: params.Description,
+		Body:        params.Body,
+	}
+	if err := s.projectMgr.SavePromptTemplate(params.ProjectID, tmpl); err != nil {
+		return nil, nil, fmt.Errorf("failed to save prompt template: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("✅ Prompt template %s saved.", tmpl.Name)}},
+	}, tmpl, nil
+}
+
+func (s *Server) handlePromptList(ctx context.Context, request *mcp.CallToolRequest, params *PromptParams) (*mcp.CallToolResult, any, error) {
+	if params.ProjectID == "" {
+		return nil, nil, invalidParams("project_id is required")
+	}
+	if _, err := requireProjectAccess(ctx, params.ProjectID); err != nil {
+		return nil, nil, err
+	}
+
+	templates, err := s.projectMgr.ListPromptTemplates(params.ProjectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list prompt templates: %w", err)
+	}
+
+	if len(templates) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "No prompt templates found."}},
+		}, nil, nil
+	}
+
+	result := fmt.Sprintf("Found %d prompt template(s):\n\n", len(templates))
+	for _, tmpl := range templates {
+		result += fmt.Sprintf("• %s\n", tmpl.Name)
+		if tmpl.Description != "" {
+			result += fmt.Sprintf("  %s\n", tmpl.Description)
+		}
+		result += fmt.Sprintf("  Updated: %s\n\n", tmpl.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: result}},
+	}, templates, nil
+}
+
+func (s *Server) handlePromptGet(ctx context.Context, request *mcp.CallToolRequest, params *PromptParams) (*mcp.CallToolResult, any, error) {
+	if params.ProjectID == "" {
+		return nil, nil, invalidParams("project_id is required")
+	}
+	if params.Name == "" {
+		return nil, nil, invalidParams("name is required")
+	}
+	if _, err := requireProjectAccess(ctx, params.ProjectID); err != nil {
+		return nil, nil, err
+	}
+
+	tmpl, err := s.projectMgr.GetPromptTemplate(params.ProjectID, params.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := fmt.Sprintf("Prompt template: %s\n\n", tmpl.Name)
+	if tmpl.Description != "" {
+		result += fmt.Sprintf("Description: %s\n", tmpl.Description)
+	}
+	result += fmt.Sprintf("Updated:     %s\n", tmpl.UpdatedAt.Format("2006-01-02 15:04"))
+	result += fmt.Sprintf("\nBody:\n%s\n", tmpl.Body)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: result}},
+	}, tmpl, nil
+}
+
+func (s *Server) handlePromptDelete(ctx context.Context, request *mcp.CallToolRequest, params *PromptParams) (*mcp.CallToolResult, any, error) {
+	if params.ProjectID == "" {
+		return nil, nil, invalidParams("project_id is required")
+	}
+	if params.Name == "" {
+		return nil, nil, invalidParams("name is required")
+	}
+
+	authCtx, err := requireProjectAccess(ctx, params.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !authCtx.CanWriteProject(params.ProjectID) {
+		return nil, nil, forbidden("read-only access, cannot delete prompt templates")
+	}
+
+	if err := s.projectMgr.DeletePromptTemplate(params.ProjectID, params.Name); err != nil {
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("✅ Prompt template %s deleted.", params.Name)}},
+	}, nil, nil
+}
+
+// handlePromptRender previews a template (named or inline body) without sending it
+func (s *Server) handlePromptRender(ctx context.Context, request *mcp.CallToolRequest, params *PromptParams) (*mcp.CallToolResult, any, error) {
+	if params.ProjectID == "" {
+		return nil, nil, invalidParams("project_id is required")
+	}
+	if params.Name == "" && params.Body == "" {
+		return nil, nil, invalidParams("name or body is required")
+	}
+	if _, err := requireProjectAccess(ctx, params.ProjectID); err != nil {
+		return nil, nil, err
+	}
+
+	body := params.Body
+	if params.Name != "" {
+		tmpl, err := s.projectMgr.GetPromptTemplate(params.ProjectID, params.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		body = tmpl.Body
+	}
+
+	rendered, err := prompt.Render(body, s.promptData(params.ProjectID, params.WorkspaceID, "", "", params.Message, params.Variables))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: rendered}},
+	}, nil, nil
+}
+
+// renderMessage resolves the message for session message. When a template name is
+// given its body is rendered with the message available as {{.Message}}; when only
+// variables are given the message itself is rendered. Otherwise it is returned as-is.
+func (s *Server) renderMessage(params *SessionParams, workspaceID string) (string, error) {
+	if params.Template == "" && len(params.Variables) == 0 {
+		return params.Message, nil
+	}
+
+	body := params.Message
+	if params.Template != "" {
+		tmpl, err := s.projectMgr.GetPromptTemplate(params.ProjectID, params.Template)
+		if err != nil {
+			return "", err
+		}
+		body = tmpl.Body
+	}
+
+	return prompt.Render(body, s.promptData(params.ProjectID, workspaceID, params.ExternalID, params.Source, params.Message, params.Variables))
+}
+
+// renderSchedulePrompt renders a schedule's prompt for one target, injecting the
+// target's previous execution so recurring jobs can build on their last result
+func (s *Server) renderSchedulePrompt(sched *schedule.Schedule, target *schedule.ScheduleTarget, workspaceID string) (string, error) {
+	data := s.promptData(target.ProjectID, workspaceID, "", "schedule", "", nil)
+	data.ScheduleID = sched.ID
+	data.ScheduleName = sched.Name
+	data.LastOutput = target.LastOutput
+	data.LastRunAt = target.LastExecutedAt
+
+	if last, err := s.scheduleStore.GetLastExecution(target.ID); err == nil && last != nil {
+		data.LastStatus = string(last.Status)
+		if data.LastRunAt == nil {
+			data.LastRunAt = &last.ExecutedAt
+		}
+	}
+
+	return prompt.Render(sched.Prompt, data)
+}
+
+// promptData builds template data for a project/workspace. Missing project or
+// workspace metadata leaves the corresponding fields empty.
+func (s *Server) promptData(projectID, workspaceID, externalID, source, message string, vars map[string]string) *prompt.Data {
+	data := &prompt.Data{
+		Now:         time.Now(),
+		ProjectID:   projectID,
+		WorkspaceID: workspaceID,
+		ExternalID:  externalID,
+		Source:      source,
+		Message:     message,
+		Vars:        vars,
+	}
+
+	proj, err := s.projectMgr.Get(projectID)
+	if err != nil {
+		return data
+	}
+	data.ProjectName = proj.Name
+	if data.WorkspaceID == "" {
+		data.WorkspaceID = proj.DefaultWorkspaceID
+	}
+
+	if ws, err := s.projectMgr.GetWorkspaceMetadata(projectID, data.WorkspaceID); err == nil {
+		if data.ExternalID == "" {
+			data.ExternalID = ws.ExternalID
+		}
+		if data.Source == "" {
+			data.Source = ws.Source
+		}
+	}
+
+	return data
+}
@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/HyphaGroup/oubliette/internal/project"
+)
+
+func newPromptTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	dir := t.TempDir()
+	projectID := "550e8400-e29b-41d4-a716-446655440000"
+	projectDir := filepath.Join(dir, projectID)
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatalf("failed to create project dir: %v", err)
+	}
+	data, _ := json.Marshal(&project.Project{ID: projectID, Name: "payments", DefaultWorkspaceID: "550e8400-e29b-41d4-a716-446655440001"})
+	if err := os.WriteFile(filepath.Join(projectDir, "metadata.json"), data, 0o644); err != nil {
+		t.Fatalf("failed to write metadata: %v", err)
+	}
+	return &Server{projectMgr: project.NewManager(dir, 3, 10, 5.0)}, projectID
+}
+
+func TestRenderMessage(t *testing.T) {
+	s, projectID := newPromptTestServer(t)
+	if err := s.projectMgr.SavePromptTemplate(projectID, &project.PromptTemplate{
+		Name: "review",
+		Body: "Review {{.Vars.pr}} in {{.ProjectName}}: {{.Message}}",
+	}); err != nil {
+		t.Fatalf("SavePromptTemplate() error = %v", err)
+	}
+
+	t.Run("plain message untouched", func(t *testing.T) {
+		got, err := s.renderMessage(&SessionParams{ProjectID: projectID, Message: "literal {{braces}}"}, "")
+		if err != nil {
+			t.Fatalf("renderMessage() error = %v", err)
+		}
+		if got != "literal {{braces}}" {
+			t.Errorf("renderMessage() = %q, want message unchanged", got)
+		}
+	})
+
+	t.Run("named template", func(t *testing.T) {
+		got, err := s.renderMessage(&SessionParams{
+			ProjectID: projectID,
+			Template:  "review",
+			Message:   "focus on tests",
+			Variables: map[string]string{"pr": "#42"},
+		}, "")
+		if err != nil {
+			t.Fatalf("renderMessage() error = %v", err)
+		}
+		if want := "Review #42 in payments: focus on tests"; got != want {
+			t.Errorf("renderMessage() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("variables render inline message", func(t *testing.T) {
+		got, err := s.renderMessage(&SessionParams{
+			ProjectID: projectID,
+			Message:   "Check {{.Vars.target}}",
+			Variables: map[string]string{"target": "api"},
+		}, "")
+		if err != nil {
+			t.Fatalf("renderMessage() error = %v", err)
+		}
+		if got != "Check api" {
+			t.Errorf("renderMessage() = %q, want %q", got, "Check api")
+		}
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		if _, err := s.renderMessage(&SessionParams{ProjectID: projectID, Template: "missing"}, ""); err == nil {
+			t.Error("expected error for unknown template")
+		}
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/HyphaGroup/oubliette/internal/audit"
 	"github.com/HyphaGroup/oubliette/internal/auth"
+	"github.com/HyphaGroup/oubliette/internal/prompt"
 	"github.com/HyphaGroup/oubliette/internal/schedule"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -64,21 +66,24 @@ func (s *Server) handleScheduleCreate(ctx context.Context, request *mcp.CallTool
 	}
 
 	if params.Name == "" {
-		return nil, nil, fmt.Errorf("name is required")
+		return nil, nil, invalidParams("name is required")
 	}
 	if params.CronExpr == "" {
-		return nil, nil, fmt.Errorf("cron_expr is required")
+		return nil, nil, invalidParams("cron_expr is required")
 	}
 	if params.Prompt == "" {
-		return nil, nil, fmt.Errorf("prompt is required")
+		return nil, nil, invalidParams("prompt is required")
+	}
+	if err := prompt.Validate(params.Prompt); err != nil {
+		return nil, nil, err
 	}
 	if len(params.Targets) == 0 {
-		return nil, nil, fmt.Errorf("at least one target is required")
+		return nil, nil, invalidParams("at least one target is required")
 	}
 
 	for _, target := range params.Targets {
-		if !authCtx.CanAccessProject(target.ProjectID) {
-			return nil, nil, fmt.Errorf("access denied to project %s", target.ProjectID)
+		if !authCtx.CanWriteProject(target.ProjectID) {
+			return nil, nil, forbidden("access denied to project %s", target.ProjectID)
 		}
 	}
 
@@ -98,13 +103,13 @@ func (s *Server) handleScheduleCreate(ctx context.Context, request *mcp.CallTool
 	}
 	if params.OverlapBehavior != nil {
 		if !schedule.IsValidOverlapBehavior(*params.OverlapBehavior) {
-			return nil, nil, fmt.Errorf("invalid overlap_behavior: %s", *params.OverlapBehavior)
+			return nil, nil, invalidParams("invalid overlap_behavior: %s", *params.OverlapBehavior)
 		}
 		sched.OverlapBehavior = *params.OverlapBehavior
 	}
 	if params.SessionBehavior != nil {
 		if !schedule.IsValidSessionBehavior(*params.SessionBehavior) {
-			return nil, nil, fmt.Errorf("invalid session_behavior: %s", *params.SessionBehavior)
+			return nil, nil, invalidParams("invalid session_behavior: %s", *params.SessionBehavior)
 		}
 		sched.SessionBehavior = *params.SessionBehavior
 	}
@@ -116,7 +121,12 @@ func (s *Server) handleScheduleCreate(ctx context.Context, request *mcp.CallTool
 		})
 	}
 
-	if err := s.scheduleStore.Create(sched); err != nil {
+	err = s.scheduleStore.Create(sched)
+	logAudit(ctx, &audit.Event{
+		Operation: audit.OpScheduleCreate,
+		Details:   map[string]interface{}{"schedule_id": sched.ID, "name": sched.Name, "cron_expr": sched.CronExpr, "targets": scheduleTargetProjects(sched.Targets)},
+	}, err)
+	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create schedule: %w", err)
 	}
 
@@ -144,7 +154,7 @@ func (s *Server) handleScheduleList(ctx context.Context, request *mcp.CallToolRe
 	filter := &schedule.ListFilter{}
 	if params.ProjectID != "" {
 		if !authCtx.CanAccessProject(params.ProjectID) {
-			return nil, nil, fmt.Errorf("access denied to project %s", params.ProjectID)
+			return nil, nil, forbidden("access denied to project %s", params.ProjectID)
 		}
 		filter.ProjectID = params.ProjectID
 	}
@@ -154,19 +164,7 @@ func (s *Server) handleScheduleList(ctx context.Context, request *mcp.CallToolRe
 		return nil, nil, fmt.Errorf("failed to list schedules: %w", err)
 	}
 
-	if !auth.IsAdminScope(authCtx.Token.Scope) {
-		projectID := auth.ExtractProjectID(authCtx.Token.Scope)
-		var filtered []*schedule.Schedule
-		for _, sched := range schedules {
-			for _, target := range sched.Targets {
-				if target.ProjectID == projectID {
-					filtered = append(filtered, sched)
-					break
-				}
-			}
-		}
-		schedules = filtered
-	}
+	schedules = accessibleSchedules(authCtx, schedules)
 
 	if len(schedules) == 0 {
 		return &mcp.CallToolResult{
@@ -202,7 +200,7 @@ func (s *Server) handleScheduleGet(ctx context.Context, request *mcp.CallToolReq
 	}
 
 	if params.ScheduleID == "" {
-		return nil, nil, fmt.Errorf("schedule_id is required")
+		return nil, nil, invalidParams("schedule_id is required")
 	}
 
 	sched, err := s.scheduleStore.Get(params.ScheduleID)
@@ -267,7 +265,7 @@ func (s *Server) handleScheduleUpdate(ctx context.Context, request *mcp.CallTool
 	}
 
 	if params.ScheduleID == "" {
-		return nil, nil, fmt.Errorf("schedule_id is required")
+		return nil, nil, invalidParams("schedule_id is required")
 	}
 
 	sched, err := s.scheduleStore.Get(params.ScheduleID)
@@ -280,7 +278,7 @@ func (s *Server) handleScheduleUpdate(ctx context.Context, request *mcp.CallTool
 	}
 
 	// Convert non-empty strings to pointers for partial update
-	var name, cronExpr, prompt *string
+	var name, cronExpr, promptText *string
 	if params.Name != "" {
 		name = &params.Name
 	}
@@ -288,29 +286,32 @@ func (s *Server) handleScheduleUpdate(ctx context.Context, request *mcp.CallTool
 		cronExpr = &params.CronExpr
 	}
 	if params.Prompt != "" {
-		prompt = &params.Prompt
+		if err := prompt.Validate(params.Prompt); err != nil {
+			return nil, nil, err
+		}
+		promptText = &params.Prompt
 	}
 
 	update := &schedule.ScheduleUpdate{
 		Name:            name,
 		CronExpr:        cronExpr,
-		Prompt:          prompt,
+		Prompt:          promptText,
 		Enabled:         params.Enabled,
 		OverlapBehavior: params.OverlapBehavior,
 		SessionBehavior: params.SessionBehavior,
 	}
 
 	if params.OverlapBehavior != nil && !schedule.IsValidOverlapBehavior(*params.OverlapBehavior) {
-		return nil, nil, fmt.Errorf("invalid overlap_behavior: %s", *params.OverlapBehavior)
+		return nil, nil, invalidParams("invalid overlap_behavior: %s", *params.OverlapBehavior)
 	}
 	if params.SessionBehavior != nil && !schedule.IsValidSessionBehavior(*params.SessionBehavior) {
-		return nil, nil, fmt.Errorf("invalid session_behavior: %s", *params.SessionBehavior)
+		return nil, nil, invalidParams("invalid session_behavior: %s", *params.SessionBehavior)
 	}
 
 	if len(params.Targets) > 0 {
 		for _, target := range params.Targets {
 			if !authCtx.CanAccessProject(target.ProjectID) {
-				return nil, nil, fmt.Errorf("access denied to project %s", target.ProjectID)
+				return nil, nil, forbidden("access denied to project %s", target.ProjectID)
 			}
 		}
 		for _, t := range params.Targets {
@@ -321,7 +322,13 @@ func (s *Server) handleScheduleUpdate(ctx context.Context, request *mcp.CallTool
 		}
 	}
 
-	if err := s.scheduleStore.Update(params.ScheduleID, update); err != nil {
+	err = s.scheduleStore.Update(params.ScheduleID, update)
+	details := map[string]interface{}{"schedule_id": params.ScheduleID}
+	if update.Targets != nil {
+		details["targets"] = scheduleTargetProjects(update.Targets)
+	}
+	logAudit(ctx, &audit.Event{Operation: audit.OpScheduleUpdate, Details: details}, err)
+	if err != nil {
 		return nil, nil, fmt.Errorf("failed to update schedule: %w", err)
 	}
 
@@ -337,7 +344,7 @@ func (s *Server) handleScheduleDelete(ctx context.Context, request *mcp.CallTool
 	}
 
 	if params.ScheduleID == "" {
-		return nil, nil, fmt.Errorf("schedule_id is required")
+		return nil, nil, invalidParams("schedule_id is required")
 	}
 
 	sched, err := s.scheduleStore.Get(params.ScheduleID)
@@ -349,7 +356,12 @@ func (s *Server) handleScheduleDelete(ctx context.Context, request *mcp.CallTool
 		return nil, nil, err
 	}
 
-	if err := s.scheduleStore.Delete(params.ScheduleID); err != nil {
+	err = s.scheduleStore.Delete(params.ScheduleID)
+	logAudit(ctx, &audit.Event{
+		Operation: audit.OpScheduleDelete,
+		Details:   map[string]interface{}{"schedule_id": sched.ID, "name": sched.Name},
+	}, err)
+	if err != nil {
 		return nil, nil, fmt.Errorf("failed to delete schedule: %w", err)
 	}
 
@@ -365,7 +377,7 @@ func (s *Server) handleScheduleTrigger(ctx context.Context, request *mcp.CallToo
 	}
 
 	if params.ScheduleID == "" {
-		return nil, nil, fmt.Errorf("schedule_id is required")
+		return nil, nil, invalidParams("schedule_id is required")
 	}
 
 	sched, err := s.scheduleStore.Get(params.ScheduleID)
@@ -382,6 +394,10 @@ func (s *Server) handleScheduleTrigger(ctx context.Context, request *mcp.CallToo
 	}
 
 	sessionIDs, err := s.scheduleRunner.TriggerNow(sched)
+	logAudit(ctx, &audit.Event{
+		Operation: audit.OpScheduleTrigger,
+		Details:   map[string]interface{}{"schedule_id": sched.ID, "session_ids": sessionIDs},
+	}, err)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to trigger schedule: %w", err)
 	}
@@ -404,7 +420,7 @@ func (s *Server) handleScheduleHistory(ctx context.Context, request *mcp.CallToo
 	}
 
 	if params.ScheduleID == "" {
-		return nil, nil, fmt.Errorf("schedule_id is required")
+		return nil, nil, invalidParams("schedule_id is required")
 	}
 
 	sched, err := s.scheduleStore.Get(params.ScheduleID)
@@ -459,16 +475,41 @@ func (s *Server) handleScheduleHistory(ctx context.Context, request *mcp.CallToo
 	}, executions, nil
 }
 
+// accessibleSchedules filters schedules to those targeting a project the caller can access
+func accessibleSchedules(authCtx *auth.AuthContext, schedules []*schedule.Schedule) []*schedule.Schedule {
+	if auth.IsAdminScope(authCtx.Token.Scope) {
+		return schedules
+	}
+	var filtered []*schedule.Schedule
+	for _, sched := range schedules {
+		for _, target := range sched.Targets {
+			if authCtx.CanAccessProject(target.ProjectID) {
+				filtered = append(filtered, sched)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
 // requireScheduleAccess checks if the auth context has access to a schedule
 func requireScheduleAccess(authCtx *auth.AuthContext, sched *schedule.Schedule) error {
 	if auth.IsAdminScope(authCtx.Token.Scope) {
 		return nil
 	}
-	projectID := auth.ExtractProjectID(authCtx.Token.Scope)
 	for _, target := range sched.Targets {
-		if target.ProjectID == projectID {
+		if authCtx.CanAccessProject(target.ProjectID) {
 			return nil
 		}
 	}
-	return fmt.Errorf("access denied to schedule %s", sched.ID)
+	return forbidden("access denied to schedule %s", sched.ID)
+}
+
+// scheduleTargetProjects lists the project IDs of schedule targets for audit details
+func scheduleTargetProjects(targets []schedule.ScheduleTarget) []string {
+	projects := make([]string, 0, len(targets))
+	for _, t := range targets {
+		projects = append(projects, t.ProjectID)
+	}
+	return projects
 }
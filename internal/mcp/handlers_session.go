@@ -6,13 +6,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/HyphaGroup/oubliette/internal/audit"
 	"github.com/HyphaGroup/oubliette/internal/container"
 	"github.com/HyphaGroup/oubliette/internal/logger"
+	"github.com/HyphaGroup/oubliette/internal/metrics"
 	"github.com/HyphaGroup/oubliette/internal/project"
 	"github.com/HyphaGroup/oubliette/internal/session"
+	"github.com/HyphaGroup/oubliette/internal/tracing"
+	"github.com/HyphaGroup/oubliette/internal/transcript"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 /*
@@ -89,8 +95,11 @@ func (s *Server) prepareSessionEnvironment(ctx context.Context, projectID, works
 	if err != nil {
 		return nil, err
 	}
-	if !authCtx.CanWrite() {
-		return nil, fmt.Errorf("read-only access, cannot spawn sessions")
+	if !authCtx.CanWriteProject(projectID) {
+		return nil, forbidden("read-only access, cannot spawn sessions")
+	}
+	if err := s.checkSessionQuota(authCtx); err != nil {
+		return nil, err
 	}
 
 	proj, err := s.projectMgr.Get(projectID)
@@ -109,16 +118,10 @@ func (s *Server) prepareSessionEnvironment(ctx context.Context, projectID, works
 		logger.Error("Failed to update workspace last_session_at: %v", err)
 	}
 
-	// Ensure container is running
-	containerName := fmt.Sprintf("oubliette-%s", projectID[:8])
-	status, err := s.runtime.Status(ctx, containerName)
-	if err != nil || status != container.StatusRunning {
-		logger.Info("Container not running for project %s, starting automatically", projectID)
-		_, err = s.createAndStartContainer(ctx, containerName, proj.ImageName, projectID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to auto-start container: %w", err)
-		}
-		logger.Info("Container started automatically for project %s", projectID)
+	// Ensure container is running (waking it if it was stopped for inactivity)
+	containerName, err := s.ensureProjectContainer(ctx, proj)
+	if err != nil {
+		return nil, err
 	}
 
 	return &sessionEnv{
@@ -145,7 +148,7 @@ func (s *Server) resolveWorkspaceGeneric(projectID string, proj *project.Project
 
 	if !s.projectMgr.WorkspaceExists(projectID, workspaceID) {
 		if !createWorkspace {
-			return "", false, fmt.Errorf("workspace %s not found", workspaceID)
+			return "", false, notFound("workspace %s not found", workspaceID)
 		}
 		_, err := s.projectMgr.CreateWorkspace(projectID, workspaceID, externalID, source)
 		if err != nil {
@@ -174,6 +177,7 @@ func (s *Server) spawnAndRegisterSession(ctx context.Context, projectID, contain
 
 	// Register as active session FIRST (before socket handler goroutine)
 	activeSess := session.NewActiveSession(sess.SessionID, projectID, workspaceID, containerName, executor)
+	activeSess.Model = sess.Model
 
 	// Set caller tools if provided - MUST happen before socket handler goroutine
 	if config != nil && config.CallerID != "" && len(config.CallerTools) > 0 {
@@ -181,16 +185,19 @@ func (s *Server) spawnAndRegisterSession(ctx context.Context, projectID, contain
 		logger.Info("Session %s configured with caller tools from %s: %d tools", sess.SessionID, config.CallerID, len(config.CallerTools))
 	}
 
+	s.setSessionOwner(ctx, activeSess)
+
 	// Register session BEFORE starting socket handler
 	// (socket handler needs to find it via activeSessions.Get)
 	if err := s.activeSessions.Register(activeSess); err != nil {
 		_ = executor.Close()
 		return nil, nil, fmt.Errorf("failed to register active session: %w", err)
 	}
+	logAudit(ctx, &audit.Event{Operation: audit.OpSessionSpawn, ProjectID: projectID, SessionID: sess.SessionID, WorkspaceID: workspaceID}, nil)
 
 	// NOW connect to relay in background (after session is registered with all config)
 	go func() {
-		if err := s.socketHandler.ConnectSession(context.Background(), projectID, sess.SessionID, 0); err != nil {
+		if err := s.socketHandler.ConnectSession(tracing.Detach(ctx), projectID, sess.SessionID, 0); err != nil {
 			logger.Error("Failed to connect to relay for session %s: %v", sess.SessionID, err)
 		}
 		// Connection closed - mark session as completed if still active
@@ -206,7 +213,7 @@ func (s *Server) spawnAndRegisterSession(ctx context.Context, projectID, contain
 // SpawnParams unifies parameters for both prime and child gogol spawning
 func (s *Server) handleSpawn(ctx context.Context, request *mcp.CallToolRequest, params *SessionParams) (*mcp.CallToolResult, any, error) {
 	if params.Message == "" {
-		return nil, nil, fmt.Errorf("message is required")
+		return nil, nil, invalidParams("message is required")
 	}
 
 	mcpCtx := ExtractMCPContext(ctx)
@@ -220,7 +227,7 @@ func (s *Server) handleSpawn(ctx context.Context, request *mcp.CallToolRequest,
 
 func (s *Server) handleSpawnPrime(ctx context.Context, request *mcp.CallToolRequest, params *SessionParams) (*mcp.CallToolResult, any, error) {
 	if params.ProjectID == "" {
-		return nil, nil, fmt.Errorf("project_id is required for prime gogol")
+		return nil, nil, invalidParams("project_id is required for prime gogol")
 	}
 
 	// Check for API credentials before attempting to spawn a session
@@ -275,10 +282,19 @@ func (s *Server) handleSpawnPrime(ctx context.Context, request *mcp.CallToolRequ
 					s.activeSessions.RestartEventCollection(activeSess)
 				} else {
 					activeSess = session.NewActiveSession(sess.SessionID, params.ProjectID, env.workspaceID, env.containerName, executor)
+					activeSess.Model = sess.Model
+					s.setSessionOwner(ctx, activeSess)
 					if err := s.activeSessions.Register(activeSess); err != nil {
 						_ = executor.Close()
 						return nil, nil, fmt.Errorf("failed to register active session: %w", err)
 					}
+					logAudit(ctx, &audit.Event{
+						Operation:   audit.OpSessionSpawn,
+						ProjectID:   params.ProjectID,
+						SessionID:   sess.SessionID,
+						WorkspaceID: env.workspaceID,
+						Details:     map[string]interface{}{"resumed": true},
+					}, nil)
 				}
 
 			}
@@ -330,6 +346,16 @@ func (s *Server) handleSpawnPrime(ctx context.Context, request *mcp.CallToolRequ
 }
 
 func (s *Server) handleSpawnChild(ctx context.Context, mcpCtx MCPContext, params *SessionParams) (*mcp.CallToolResult, any, error) {
+	ctx, span := tracing.Start(ctx, "session.spawn_child",
+		attribute.String("oubliette.parent_session_id", mcpCtx.SessionID),
+		attribute.Int("oubliette.depth", mcpCtx.Depth+1),
+	)
+	result, structured, err := s.spawnChild(ctx, mcpCtx, params)
+	tracing.End(span, err)
+	return result, structured, err
+}
+
+func (s *Server) spawnChild(ctx context.Context, mcpCtx MCPContext, params *SessionParams) (*mcp.CallToolResult, any, error) {
 	logger.Info("Spawning child session from parent: %s", mcpCtx.SessionID)
 
 	parentSession, err := s.sessionMgr.Load(mcpCtx.SessionID)
@@ -364,10 +390,22 @@ func (s *Server) handleSpawnChild(ctx context.Context, mcpCtx MCPContext, params
 		}, nil, nil
 	}
 
-	containerName := fmt.Sprintf("oubliette-%s", parentSession.ProjectID[:8])
+	// Children are billed to the token that owns the parent session
+	var ownerTokenID string
+	if parent, ok := s.activeSessions.Get(mcpCtx.SessionID); ok {
+		ownerTokenID = parent.OwnerTokenID
+	}
+	if err := s.checkOwnerTokenQuota(ownerTokenID); err != nil {
+		return nil, nil, err
+	}
+
+	containerName, err := container.ProjectContainerName(parentSession.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
 	status, err := s.runtime.Status(ctx, containerName)
 	if err != nil || status != container.StatusRunning {
-		return nil, nil, fmt.Errorf("container for project '%s' is not running. Use container_start first", parentSession.ProjectID)
+		return nil, nil, conflict("container for project '%s' is not running. Use container_start first", parentSession.ProjectID)
 	}
 
 	explorationID := parentSession.ExplorationID
@@ -441,7 +479,17 @@ The .rlm-context/ directory is shared with your parent and siblings for result a
 		logger.Error("Failed to add child to parent session: %v", err)
 		return nil, nil, fmt.Errorf("failed to add child to parent session: %w", err)
 	}
-
+	audit.Log(&audit.Event{
+		Operation:   audit.OpSessionSpawn,
+		TokenID:     ownerTokenID,
+		ProjectID:   parentSession.ProjectID,
+		SessionID:   childSession.SessionID,
+		WorkspaceID: parentSession.WorkspaceID,
+		Success:     true,
+		Details:     map[string]interface{}{"parent_session_id": mcpCtx.SessionID, "depth": childDepth},
+	})
+
+	metrics.RecordChildSpawn(childDepth)
 	logger.Info("Child gogol spawned successfully: %s (depth %d/%d)", childSession.SessionID, childDepth, maxDepth)
 
 	result := fmt.Sprintf("✅ Child gogol spawned: %s\n\n", childSession.SessionID)
@@ -452,6 +500,8 @@ The .rlm-context/ directory is shared with your parent and siblings for result a
 
 	if len(childSession.Turns) > 0 {
 		lastTurn := childSession.Turns[len(childSession.Turns)-1]
+		s.recordModelTokens(ownerTokenID, lastTurn.Cost.InputTokens, lastTurn.Cost.OutputTokens)
+		metrics.RecordModelTokens(parentSession.ProjectID, childSession.Model, lastTurn.Cost.InputTokens, lastTurn.Cost.OutputTokens)
 		result += fmt.Sprintf("Output:\n%s\n\n", lastTurn.Output.Text)
 		result += fmt.Sprintf("Cost: %d input tokens, %d output tokens\n", lastTurn.Cost.InputTokens, lastTurn.Cost.OutputTokens)
 	}
@@ -465,16 +515,22 @@ The .rlm-context/ directory is shared with your parent and siblings for result a
 
 func (s *Server) handleGetSession(ctx context.Context, request *mcp.CallToolRequest, params *SessionParams) (*mcp.CallToolResult, any, error) {
 	if params.SessionID == "" {
-		return nil, nil, fmt.Errorf("session_id is required")
+		return nil, nil, invalidParams("session_id is required")
 	}
 
 	sess, err := s.sessionMgr.Load(params.SessionID)
 	if err != nil {
 		return nil, nil, err
 	}
+	if _, err := requireProjectAccess(ctx, sess.ProjectID); err != nil {
+		return nil, nil, err
+	}
 
 	result := fmt.Sprintf("Session: %s\n\n", sess.SessionID)
 	result += fmt.Sprintf("Project: %s\n", sess.ProjectID)
+	if sess.ForkedFromTurn > 0 && sess.ParentSessionID != nil {
+		result += fmt.Sprintf("Forked From: %s (turn %d)\n", *sess.ParentSessionID, sess.ForkedFromTurn)
+	}
 	result += fmt.Sprintf("Status: %s\n", sess.Status)
 	result += fmt.Sprintf("Created: %s\n", sess.CreatedAt.Format("2006-01-02 15:04:05"))
 	result += fmt.Sprintf("Updated: %s\n", sess.UpdatedAt.Format("2006-01-02 15:04:05"))
@@ -489,6 +545,16 @@ func (s *Server) handleGetSession(ctx context.Context, request *mcp.CallToolRequ
 		if len(lastTurn.Output.Text) > 200 {
 			result += "  ...(truncated)\n"
 		}
+		if lastTurn.Model != "" {
+			result += fmt.Sprintf("  Failed Over To: %s\n", lastTurn.Model)
+		}
+		for _, a := range lastTurn.Attempts {
+			result += fmt.Sprintf("  Provider Error: %s", a.Error)
+			if a.ErrorCode != 0 {
+				result += fmt.Sprintf(" (%d)", a.ErrorCode)
+			}
+			result += fmt.Sprintf(" at %s -> %s\n", a.At.Format("15:04:05"), a.Outcome)
+		}
 	}
 
 	return &mcp.CallToolResult{
@@ -498,21 +564,191 @@ func (s *Server) handleGetSession(ctx context.Context, request *mcp.CallToolRequ
 	}, nil, nil
 }
 
+func (s *Server) handleSessionExport(ctx context.Context, request *mcp.CallToolRequest, params *SessionParams) (*mcp.CallToolResult, any, error) {
+	if params.SessionID == "" {
+		return nil, nil, invalidParams("session_id is required")
+	}
+	format, err := transcript.ParseFormat(params.Format)
+	if err != nil {
+		return nil, nil, invalidParams("%w", err)
+	}
+
+	sess, err := s.sessionMgr.Load(params.SessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := requireProjectAccess(ctx, sess.ProjectID); err != nil {
+		return nil, nil, err
+	}
+
+	t, err := transcript.Load(s.sessionMgr, sess.SessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := t.Render(format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(out)},
+		},
+	}, nil, nil
+}
+
+// handleSessionFork starts a new session from a source session's conversation
+// through params.Turn. The fork runs in the source's workspace unless
+// fork_workspace copies the workspace first.
+func (s *Server) handleSessionFork(ctx context.Context, request *mcp.CallToolRequest, params *SessionParams) (*mcp.CallToolResult, any, error) {
+	if params.SessionID == "" {
+		return nil, nil, invalidParams("session_id is required")
+	}
+	if params.Turn < 1 {
+		return nil, nil, invalidParams("turn is required (1-based turn number to fork from)")
+	}
+
+	source, err := s.sessionMgr.Load(params.SessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	authCtx, err := requireProjectAccess(ctx, source.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !authCtx.CanWriteProject(source.ProjectID) {
+		return nil, nil, forbidden("read-only access, cannot fork sessions")
+	}
+	if params.Turn > len(source.Turns) {
+		return nil, nil, invalidParams("turn %d out of range: session %s has %d turns", params.Turn, source.SessionID, len(source.Turns))
+	}
+	if !s.HasAPICredentials() {
+		return nil, nil, fmt.Errorf("no API credentials configured - add credentials.providers in oubliette.jsonc")
+	}
+
+	workspaceID := source.WorkspaceID
+	if params.ForkWorkspace {
+		ws, err := s.projectMgr.ForkWorkspace(source.ProjectID, source.WorkspaceID, "fork")
+		if err != nil {
+			return nil, nil, SanitizeError(err, "fork workspace")
+		}
+		workspaceID = ws.ID
+	} else if active, ok := s.activeSessions.GetByWorkspace(source.ProjectID, workspaceID); ok {
+		return nil, nil, conflict("workspace %s already has active session %s - end it first or set fork_workspace=true", workspaceID, active.SessionID)
+	}
+
+	env, err := s.prepareSessionEnvironment(ctx, source.ProjectID, workspaceID, false, "", "fork")
+	if err != nil {
+		if params.ForkWorkspace {
+			_ = s.projectMgr.DeleteWorkspace(source.ProjectID, workspaceID)
+		}
+		return nil, nil, SanitizeError(err, "prepare session environment")
+	}
+
+	// Keep the source's model unless overridden
+	model := params.Model
+	if model == "" {
+		model = source.Model
+	}
+	if model == "" {
+		model = env.project.Model
+	}
+
+	opts := session.StartOptions{
+		Model:          model,
+		AutonomyLevel:  params.AutonomyLevel,
+		ReasoningLevel: params.ReasoningLevel,
+
+		WorkspaceID:        env.workspaceID,
+		ToolsAllowed:       params.ToolsAllowed,
+		ToolsDisallowed:    params.ToolsDisallowed,
+		WorkspaceIsolation: env.project.WorkspaceIsolation,
+		RuntimeOverride:    s.agentRuntime,
+	}
+
+	sess, executor, err := s.sessionMgr.ForkBidirectionalSession(ctx, source, params.Turn, env.containerName, params.Message, opts)
+	if err != nil {
+		if params.ForkWorkspace {
+			_ = s.projectMgr.DeleteWorkspace(source.ProjectID, workspaceID)
+		}
+		logger.Error("Failed to fork session %s: %v", source.SessionID, err)
+		return nil, nil, err
+	}
+
+	activeSess := session.NewActiveSession(sess.SessionID, sess.ProjectID, env.workspaceID, env.containerName, executor)
+	activeSess.Model = sess.Model
+	s.setSessionOwner(ctx, activeSess)
+	if err := s.activeSessions.Register(activeSess); err != nil {
+		_ = executor.Close()
+		if delErr := s.sessionMgr.DeleteSession(sess.ProjectID, sess.SessionID); delErr != nil {
+			logger.Error("Failed to remove unregistered fork %s: %v", sess.SessionID, delErr)
+		}
+		if params.ForkWorkspace {
+			_ = s.projectMgr.DeleteWorkspace(source.ProjectID, workspaceID)
+		}
+		return nil, nil, fmt.Errorf("failed to register active session: %w", err)
+	}
+	logAudit(ctx, &audit.Event{
+		Operation:   audit.OpSessionSpawn,
+		ProjectID:   sess.ProjectID,
+		SessionID:   sess.SessionID,
+		WorkspaceID: env.workspaceID,
+		Details:     map[string]interface{}{"forked_from": source.SessionID, "turn": params.Turn},
+	}, nil)
+
+	go func() {
+		if err := s.socketHandler.ConnectSession(tracing.Detach(ctx), sess.ProjectID, sess.SessionID, 0); err != nil {
+			logger.Error("Failed to connect to relay for session %s: %v", sess.SessionID, err)
+		}
+		if activeSess, ok := s.activeSessions.Get(sess.SessionID); ok && activeSess.IsRunning() {
+			logger.Info("Session %s relay connection closed, marking as completed", sess.SessionID)
+			activeSess.SetStatus(session.ActiveStatusCompleted, nil)
+		}
+	}()
+
+	// Set MCP session for SSE event push; REST calls have none
+	if request.Session != nil {
+		activeSess.SetMCPSession(request.Session)
+	}
+
+	logger.Info("Session %s forked from %s at turn %d", sess.SessionID, source.SessionID, params.Turn)
+	result := fmt.Sprintf("✅ Session forked: %s\n\n", sess.SessionID)
+	result += fmt.Sprintf("Forked From: %s (turn %d of %d)\n", source.SessionID, params.Turn, len(source.Turns))
+	result += fmt.Sprintf("Project: %s\n", sess.ProjectID)
+	result += fmt.Sprintf("Workspace: %s", env.workspaceID)
+	if params.ForkWorkspace {
+		result += fmt.Sprintf(" (copied from %s)\n", source.WorkspaceID)
+	} else {
+		result += "\n"
+	}
+	result += fmt.Sprintf("Runtime Session: %s\n", sess.RuntimeSessionID)
+	result += fmt.Sprintf("Status: %s\n\n", activeSess.GetStatus())
+	result += "Use session_events to get streaming output\n"
+	result += "Use session_message to send messages\n"
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: result}},
+	}, nil, nil
+}
+
 func (s *Server) handleListSessions(ctx context.Context, request *mcp.CallToolRequest, params *SessionParams) (*mcp.CallToolResult, any, error) {
 	if params.ProjectID == "" {
-		return nil, nil, fmt.Errorf("project_id is required")
+		return nil, nil, invalidParams("project_id is required")
+	}
+	if _, err := requireProjectAccess(ctx, params.ProjectID); err != nil {
+		return nil, nil, err
 	}
 
-	var statusFilter *session.Status
-	if params.Status != "" {
-		status := session.Status(params.Status)
-		statusFilter = &status
+	filter, err := params.listFilter()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	sessions, err := s.sessionMgr.List(params.ProjectID, statusFilter)
+	page, err := s.sessionMgr.ListSessions(filter)
 	if err != nil {
 		return nil, nil, err
 	}
+	sessions := page.Sessions
 
 	if len(sessions) == 0 {
 		return &mcp.CallToolResult{
@@ -526,6 +762,12 @@ func (s *Server) handleListSessions(ctx context.Context, request *mcp.CallToolRe
 	for _, sess := range sessions {
 		result += fmt.Sprintf("• %s\n", sess.SessionID)
 		result += fmt.Sprintf("  Status: %s\n", sess.Status)
+		if sess.Model != "" {
+			result += fmt.Sprintf("  Model: %s\n", sess.Model)
+		}
+		if sess.ParentSessionID != "" {
+			result += fmt.Sprintf("  Parent: %s\n", sess.ParentSessionID)
+		}
 		result += fmt.Sprintf("  Turns: %d\n", sess.TurnCount)
 		result += fmt.Sprintf("  Created: %s\n", sess.CreatedAt.Format("2006-01-02 15:04:05"))
 		if sess.LastPrompt != "" {
@@ -533,6 +775,64 @@ func (s *Server) handleListSessions(ctx context.Context, request *mcp.CallToolRe
 		}
 		result += "\n"
 	}
+	if page.NextCursor != "" {
+		result += fmt.Sprintf("More sessions available. Next cursor: %s\n", page.NextCursor)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
+
+func (s *Server) handleSearchSessions(ctx context.Context, request *mcp.CallToolRequest, params *SessionParams) (*mcp.CallToolResult, any, error) {
+	authCtx, err := requireAuth(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if params.ProjectID != "" {
+		if !authCtx.CanAccessProject(params.ProjectID) {
+			return nil, nil, forbidden("not authorized to access project %s", params.ProjectID)
+		}
+	} else if !authCtx.IsAdmin() {
+		return nil, nil, forbidden("admin access required to search sessions of all projects")
+	}
+
+	filter := session.SearchFilter{Query: params.Query, ProjectID: params.ProjectID, Limit: params.Limit}
+	if params.Since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, params.Since); err != nil {
+			return nil, nil, invalidParams("invalid since %q: expected RFC 3339 time", params.Since)
+		}
+	}
+	if params.Until != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, params.Until); err != nil {
+			return nil, nil, invalidParams("invalid until %q: expected RFC 3339 time", params.Until)
+		}
+	}
+
+	hits, err := s.sessionMgr.Search(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(hits) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("No sessions match %q", params.Query)},
+			},
+		}, nil, nil
+	}
+
+	result := fmt.Sprintf("Found %d match(es) for %q:\n\n", len(hits), params.Query)
+	for _, hit := range hits {
+		result += fmt.Sprintf("• %s turn %d (%s)\n", hit.SessionID, hit.Turn, hit.Kind)
+		if params.ProjectID == "" {
+			result += fmt.Sprintf("  Project: %s\n", hit.ProjectID)
+		}
+		result += fmt.Sprintf("  At: %s\n", hit.At.Format("2006-01-02 15:04:05"))
+		result += fmt.Sprintf("  %s\n\n", strings.Join(strings.Fields(hit.Snippet), " "))
+	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
@@ -541,14 +841,55 @@ func (s *Server) handleListSessions(ctx context.Context, request *mcp.CallToolRe
 	}, nil, nil
 }
 
+// listFilter builds the session list filter from the list parameters
+func (p *SessionParams) listFilter() (session.ListFilter, error) {
+	f := session.ListFilter{
+		ProjectID:       p.ProjectID,
+		WorkspaceID:     p.WorkspaceID,
+		Status:          session.Status(p.Status),
+		Model:           p.Model,
+		ExplorationID:   p.ExplorationID,
+		ParentSessionID: p.ParentSessionID,
+		Limit:           p.Limit,
+		Cursor:          p.Cursor,
+	}
+	var err error
+	if p.Since != "" {
+		if f.Since, err = time.Parse(time.RFC3339, p.Since); err != nil {
+			return f, invalidParams("invalid since %q: expected RFC 3339 time", p.Since)
+		}
+	}
+	if p.Until != "" {
+		if f.Until, err = time.Parse(time.RFC3339, p.Until); err != nil {
+			return f, invalidParams("invalid until %q: expected RFC 3339 time", p.Until)
+		}
+	}
+	return f, nil
+}
+
 func (s *Server) handleEndSession(ctx context.Context, request *mcp.CallToolRequest, params *SessionParams) (*mcp.CallToolResult, any, error) {
 	if params.SessionID == "" {
-		return nil, nil, fmt.Errorf("session_id is required")
+		return nil, nil, invalidParams("session_id is required")
 	}
 
 	logger.Info("Ending session: %s", params.SessionID)
 
-	if err := s.sessionMgr.End(params.SessionID); err != nil {
+	event := &audit.Event{Operation: audit.OpSessionEnd, SessionID: params.SessionID}
+	if sess, err := s.sessionMgr.Load(params.SessionID); err == nil {
+		authCtx, err := requireProjectAccess(ctx, sess.ProjectID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !authCtx.CanWriteProject(sess.ProjectID) {
+			return nil, nil, forbidden("read-only access, cannot end sessions")
+		}
+		event.ProjectID = sess.ProjectID
+		event.WorkspaceID = sess.WorkspaceID
+	}
+
+	err := s.sessionMgr.End(params.SessionID)
+	logAudit(ctx, event, err)
+	if err != nil {
 		logger.Error("Failed to end session %s: %v", params.SessionID, err)
 		return nil, nil, err
 	}
@@ -570,7 +911,8 @@ func minInt(a, b int) int {
 }
 
 // SendMessageParams for the unified session_message tool
-// Attachment represents a file attachment sent with a message
+// Attachment represents a file attachment sent with a message. Attachments are
+// written to .oubliette/attachments/<turn>/ in the workspace (see attachments.go).
 type Attachment struct {
 	ID          string `json:"id,omitempty"`
 	Filename    string `json:"filename,omitempty"`
@@ -589,21 +931,22 @@ type SendMessageResult struct {
 
 func (s *Server) handleSendMessage(ctx context.Context, request *mcp.CallToolRequest, params *SessionParams) (*mcp.CallToolResult, any, error) {
 	if params.ProjectID == "" {
-		return nil, nil, fmt.Errorf("project_id is required")
+		return nil, nil, invalidParams("project_id is required")
 	}
-	if params.Message == "" {
-		return nil, nil, fmt.Errorf("message is required")
+	if params.Message == "" && params.Template == "" {
+		return nil, nil, invalidParams("message or template is required")
 	}
 
-	message := params.Message
-
 	// Check auth and resolve workspace early so we can look up active sessions
 	authCtx, err := requireProjectAccess(ctx, params.ProjectID)
 	if err != nil {
 		return nil, nil, err
 	}
-	if !authCtx.CanWrite() {
-		return nil, nil, fmt.Errorf("read-only access, cannot send messages")
+	if !authCtx.CanWriteProject(params.ProjectID) {
+		return nil, nil, forbidden("read-only access, cannot send messages")
+	}
+	if err := s.checkModelTokenQuota(authCtx); err != nil {
+		return nil, nil, err
 	}
 
 	// Resolve workspace ID (empty = use default workspace)
@@ -618,6 +961,15 @@ func (s *Server) handleSendMessage(ctx context.Context, request *mcp.CallToolReq
 
 	logger.Info("Session message for project %s, workspace %s", params.ProjectID, workspaceID)
 
+	message, err := s.renderMessage(params, workspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachments, err := loadAttachments(ctx, params.Attachments)
+	if err != nil {
+		return nil, nil, invalidParams("%w", err)
+	}
+
 	// Fast path: send to existing active session
 	activeSess, found := s.activeSessions.GetByWorkspace(params.ProjectID, workspaceID)
 	if found {
@@ -633,8 +985,11 @@ func (s *Server) handleSendMessage(ctx context.Context, request *mcp.CallToolReq
 	}
 	if found {
 
-		// Update MCP session for SSE event push (may be a reconnecting client)
-		activeSess.SetMCPSession(request.Session)
+		// Update MCP session for SSE event push (may be a reconnecting client).
+		// REST calls have no MCP session and leave the current one in place.
+		if request.Session != nil {
+			activeSess.SetMCPSession(request.Session)
+		}
 
 		// Update caller tools if provided (allows updating tools on existing session)
 		if params.CallerID != "" && len(params.CallerTools) > 0 {
@@ -642,9 +997,16 @@ func (s *Server) handleSendMessage(ctx context.Context, request *mcp.CallToolReq
 			logger.Info("Session %s configured with caller tools from %s: %d tools", activeSess.SessionID, params.CallerID, len(params.CallerTools))
 		}
 
-		if err := s.activeSessions.SendMessage(activeSess.SessionID, message); err != nil {
+		note, files, err := s.deliverAttachments(params.ProjectID, workspaceID, attachments)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := s.activeSessions.SendMessageWithFiles(activeSess.SessionID, message+note, files); err != nil {
 			return nil, nil, fmt.Errorf("failed to send message: %w", err)
 		}
+		if err := s.sessionMgr.AddTurn(activeSess.SessionID, message); err != nil {
+			logger.Error("Failed to record turn for session %s: %v", activeSess.SessionID, err)
+		}
 
 		result := SendMessageResult{
 			SessionID:        activeSess.SessionID,
@@ -666,6 +1028,12 @@ func (s *Server) handleSendMessage(ctx context.Context, request *mcp.CallToolReq
 		return nil, nil, err
 	}
 
+	note, files, err := s.deliverAttachments(params.ProjectID, env.workspaceID, attachments)
+	if err != nil {
+		return nil, nil, err
+	}
+	message += note
+
 	// Use project model as default if not specified in params
 	model := params.Model
 	if model == "" {
@@ -681,6 +1049,7 @@ func (s *Server) handleSendMessage(ctx context.Context, request *mcp.CallToolReq
 		ToolsDisallowed:    params.ToolsDisallowed,
 		WorkspaceIsolation: env.project.WorkspaceIsolation,
 		RuntimeOverride:    s.agentRuntime,
+		Files:              files,
 	}
 
 	// Build spawn config with all session configuration
@@ -732,12 +1101,15 @@ type SessionEventItem struct {
 
 func (s *Server) handleSessionEvents(ctx context.Context, request *mcp.CallToolRequest, params *SessionParams) (*mcp.CallToolResult, any, error) {
 	if params.SessionID == "" {
-		return nil, nil, fmt.Errorf("session_id is required")
+		return nil, nil, invalidParams("session_id is required")
 	}
 
 	activeSess, ok := s.activeSessions.Get(params.SessionID)
 	if !ok {
-		return nil, nil, fmt.Errorf("session %s is not an active streaming session", params.SessionID)
+		return nil, nil, notFound("session %s is not an active streaming session", params.SessionID)
+	}
+	if _, err := requireProjectAccess(ctx, activeSess.ProjectID); err != nil {
+		return nil, nil, err
 	}
 
 	sinceIndex := -1
@@ -857,7 +1229,7 @@ func (s *Server) handleGetRecursionLimits(ctx context.Context, request *mcp.Call
 		if mcpCtx.ProjectID != "" {
 			params.ProjectID = mcpCtx.ProjectID
 		} else {
-			return nil, nil, fmt.Errorf("project_id is required")
+			return nil, nil, invalidParams("project_id is required")
 		}
 	}
 
@@ -926,16 +1298,16 @@ type CallerToolResponseParams struct {
 
 func (s *Server) handleCallerToolResponse(ctx context.Context, request *mcp.CallToolRequest, params *CallerToolResponseParams) (*mcp.CallToolResult, any, error) {
 	if params.SessionID == "" {
-		return nil, nil, fmt.Errorf("session_id is required")
+		return nil, nil, invalidParams("session_id is required")
 	}
 	if params.RequestID == "" {
-		return nil, nil, fmt.Errorf("request_id is required")
+		return nil, nil, invalidParams("request_id is required")
 	}
 
 	// Look up the session
 	activeSess, ok := s.activeSessions.Get(params.SessionID)
 	if !ok {
-		return nil, nil, fmt.Errorf("session %s not found or not active", params.SessionID)
+		return nil, nil, notFound("session %s not found or not active", params.SessionID)
 	}
 
 	// Build response
@@ -945,8 +1317,20 @@ func (s *Server) handleCallerToolResponse(ctx context.Context, request *mcp.Call
 	}
 
 	// Resolve the pending request
+	var resolveErr error
 	if !activeSess.ResolveCallerRequest(params.RequestID, response) {
-		return nil, nil, fmt.Errorf("request %s not found or already resolved", params.RequestID)
+		resolveErr = notFound("request %s not found or already resolved", params.RequestID)
+	}
+	logAudit(ctx, &audit.Event{
+		Operation:   audit.OpCallerToolResponse,
+		ProjectID:   activeSess.ProjectID,
+		SessionID:   params.SessionID,
+		WorkspaceID: activeSess.WorkspaceID,
+		RequestID:   params.RequestID,
+		Details:     map[string]interface{}{"tool_error": params.Error != ""},
+	}, resolveErr)
+	if resolveErr != nil {
+		return nil, nil, resolveErr
 	}
 
 	logger.Info("Resolved caller_tool_response for session %s, request %s", params.SessionID, params.RequestID)
@@ -964,7 +1348,7 @@ func (s *Server) handleSessionCleanup(ctx context.Context, request *mcp.CallTool
 		return nil, nil, err
 	}
 	if !authCtx.CanWrite() {
-		return nil, nil, fmt.Errorf("read-only access, cannot cleanup sessions")
+		return nil, nil, forbidden("read-only access, cannot cleanup sessions")
 	}
 
 	// Default to 24 hours
@@ -978,6 +1362,9 @@ func (s *Server) handleSessionCleanup(ctx context.Context, request *mcp.CallTool
 	var totalDeleted int
 
 	if params.ProjectID != "" {
+		if !authCtx.CanWriteProject(params.ProjectID) {
+			return nil, nil, forbidden("not authorized to clean up sessions of project %s", params.ProjectID)
+		}
 		// Clean up specific project
 		deleted, err := s.sessionMgr.CleanupOldSessions(params.ProjectID, maxAge)
 		if err != nil {
@@ -986,6 +1373,9 @@ func (s *Server) handleSessionCleanup(ctx context.Context, request *mcp.CallTool
 		totalDeleted = deleted
 		result = fmt.Sprintf("Cleaned up %d session(s) older than %d hours from project '%s'", deleted, maxAgeHours, params.ProjectID)
 	} else {
+		if !authCtx.IsAdmin() {
+			return nil, nil, forbidden("admin access required to clean up sessions of all projects")
+		}
 		// Clean up all projects
 		results, err := s.sessionMgr.CleanupAllOldSessions(maxAge)
 		if err != nil {
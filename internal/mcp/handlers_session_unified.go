@@ -9,7 +9,7 @@ import (
 
 // SessionParams is the params struct for the session tool
 type SessionParams struct {
-	Action string `json:"action"` // Required: spawn, message, get, list, end, events, cleanup
+	Action string `json:"action"` // Required: spawn, message, get, list, search, end, events, export, fork, cleanup
 
 	// Common
 	ProjectID   string `json:"project_id,omitempty"`
@@ -33,20 +33,38 @@ type SessionParams struct {
 	Attachments []Attachment                   `json:"attachments,omitempty"`
 	CallerTools []session.CallerToolDefinition `json:"caller_tools,omitempty"`
 	CallerID    string                         `json:"caller_id,omitempty"`
+	Template    string                         `json:"template,omitempty"`  // Named project prompt template
+	Variables   map[string]string              `json:"variables,omitempty"` // Template variables ({{.Vars.name}})
 
-	// For list
-	Status string `json:"status,omitempty"`
+	// For list (also filters on workspace_id and model)
+	Status          string `json:"status,omitempty"`
+	ExplorationID   string `json:"exploration_id,omitempty"`
+	ParentSessionID string `json:"parent_session_id,omitempty"`
+	Since           string `json:"since,omitempty"`  // RFC 3339; also for search
+	Until           string `json:"until,omitempty"`  // RFC 3339; also for search
+	Limit           int    `json:"limit,omitempty"`  // Also for search
+	Cursor          string `json:"cursor,omitempty"` // From the previous page
+
+	// For search
+	Query string `json:"query,omitempty"`
 
 	// For events
 	SinceIndex      *int `json:"since_index,omitempty"`
 	MaxEvents       *int `json:"max_events,omitempty"`
 	IncludeChildren bool `json:"include_children,omitempty"`
 
+	// For export
+	Format string `json:"format,omitempty"` // markdown (default), html, json
+
+	// For fork
+	Turn          int  `json:"turn,omitempty"`           // 1-based turn to fork after
+	ForkWorkspace bool `json:"fork_workspace,omitempty"` // Copy the workspace instead of sharing it
+
 	// For cleanup
 	MaxAgeHours *int `json:"max_age_hours,omitempty"`
 }
 
-var sessionActions = []string{"spawn", "message", "get", "list", "end", "events", "cleanup"}
+var sessionActions = []string{"spawn", "message", "get", "list", "search", "end", "events", "export", "fork", "cleanup"}
 
 func (s *Server) handleSession(ctx context.Context, request *mcp.CallToolRequest, params *SessionParams) (*mcp.CallToolResult, any, error) {
 	if params.Action == "" {
@@ -62,10 +80,16 @@ func (s *Server) handleSession(ctx context.Context, request *mcp.CallToolRequest
 		return s.handleGetSession(ctx, request, params)
 	case "list":
 		return s.handleListSessions(ctx, request, params)
+	case "search":
+		return s.handleSearchSessions(ctx, request, params)
 	case "end":
 		return s.handleEndSession(ctx, request, params)
 	case "events":
 		return s.handleSessionEvents(ctx, request, params)
+	case "export":
+		return s.handleSessionExport(ctx, request, params)
+	case "fork":
+		return s.handleSessionFork(ctx, request, params)
 	case "cleanup":
 		return s.handleSessionCleanup(ctx, request, params)
 	default:
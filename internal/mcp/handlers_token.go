@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/HyphaGroup/oubliette/internal/audit"
 	"github.com/HyphaGroup/oubliette/internal/auth"
@@ -11,14 +12,15 @@ import (
 
 // TokenParams is the params struct for the token tool
 type TokenParams struct {
-	Action string `json:"action"` // Required: create, list, revoke
+	Action string `json:"action"` // Required: create, list, revoke, usage, set_quota
 
-	Name    string `json:"name,omitempty"`
-	Scope   string `json:"scope,omitempty"`
-	TokenID string `json:"token_id,omitempty"`
+	Name    string      `json:"name,omitempty"`
+	Scope   string      `json:"scope,omitempty"`
+	TokenID string      `json:"token_id,omitempty"`
+	Quota   *auth.Quota `json:"quota,omitempty"` // For create/set_quota; omitted or empty = unlimited
 }
 
-var tokenActions = []string{"create", "list", "revoke"}
+var tokenActions = []string{"create", "list", "revoke", "usage", "set_quota"}
 
 func (s *Server) handleToken(ctx context.Context, request *mcp.CallToolRequest, params *TokenParams) (*mcp.CallToolResult, any, error) {
 	if params.Action == "" {
@@ -32,33 +34,71 @@ func (s *Server) handleToken(ctx context.Context, request *mcp.CallToolRequest,
 		return s.handleTokenList(ctx, request, params)
 	case "revoke":
 		return s.handleTokenRevoke(ctx, request, params)
+	case "usage":
+		return s.handleTokenUsage(ctx, request, params)
+	case "set_quota":
+		return s.handleTokenSetQuota(ctx, request, params)
 	default:
 		return nil, nil, actionError("token", params.Action, tokenActions)
 	}
 }
 
 func (s *Server) handleTokenCreate(ctx context.Context, request *mcp.CallToolRequest, params *TokenParams) (*mcp.CallToolResult, any, error) {
-	authCtx, err := requireAdmin(ctx)
+	token, tokenID, err := s.createToken(ctx, params)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	result := "✅ Token created successfully!\n\n"
+	result += fmt.Sprintf("Token ID: %s\n", tokenID)
+	result += fmt.Sprintf("Name:     %s\n", token.Name)
+	result += fmt.Sprintf("Scope:    %s\n", token.Scope)
+	if !params.Quota.IsZero() {
+		result += fmt.Sprintf("Quota:    %s\n", params.Quota.String())
+	}
+	result += "\n⚠️  IMPORTANT: Save this token now. It cannot be retrieved later."
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
+
+// createToken validates and creates a token, returning it and its ID
+func (s *Server) createToken(ctx context.Context, params *TokenParams) (*auth.Token, string, error) {
+	authCtx, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
 	if params.Name == "" {
-		return nil, nil, fmt.Errorf("name is required")
+		return nil, "", invalidParams("name is required")
 	}
 	if params.Scope == "" {
-		return nil, nil, fmt.Errorf("scope is required")
+		return nil, "", invalidParams("scope is required")
 	}
 
-	if !isValidScope(params.Scope) {
-		return nil, nil, fmt.Errorf("invalid scope '%s'. Valid scopes: admin, admin:ro, project:<uuid>, project:<uuid>:ro", params.Scope)
+	if err := auth.ValidateScope(params.Scope); err != nil {
+		return nil, "", invalidParams("invalid scope '%s': %w. Valid entries: admin, admin:ro, project:<uuid>[:ro], tool:<name>[:<action>|:ro], deny:<name>[:<action>]", params.Scope, err)
+	}
+	if params.Quota != nil {
+		if err := params.Quota.Validate(); err != nil {
+			return nil, "", err
+		}
 	}
 
 	callerTokenID, callerScope := getTokenInfo(authCtx)
 	token, tokenID, err := s.authStore.CreateToken(params.Name, params.Scope, nil)
 	if err != nil {
 		audit.LogFailure(audit.OpTokenCreate, callerTokenID, callerScope, "", err)
-		return nil, nil, fmt.Errorf("failed to create token: %w", err)
+		return nil, "", fmt.Errorf("failed to create token: %w", err)
+	}
+	if !params.Quota.IsZero() {
+		if err := s.authStore.SetQuota(tokenID, params.Quota); err != nil {
+			return nil, "", fmt.Errorf("token created but failed to set quota: %w", err)
+		}
+		token.Quota = params.Quota
 	}
 
 	audit.Log(&audit.Event{
@@ -68,18 +108,7 @@ func (s *Server) handleTokenCreate(ctx context.Context, request *mcp.CallToolReq
 		Success:    true,
 		Details:    map[string]interface{}{"new_token_name": params.Name, "new_token_scope": params.Scope},
 	})
-
-	result := "✅ Token created successfully!\n\n"
-	result += fmt.Sprintf("Token ID: %s\n", tokenID)
-	result += fmt.Sprintf("Name:     %s\n", token.Name)
-	result += fmt.Sprintf("Scope:    %s\n", token.Scope)
-	result += "\n⚠️  IMPORTANT: Save this token now. It cannot be retrieved later."
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: result},
-		},
-	}, nil, nil
+	return token, tokenID, nil
 }
 
 func (s *Server) handleTokenList(ctx context.Context, request *mcp.CallToolRequest, params *TokenParams) (*mcp.CallToolResult, any, error) {
@@ -109,6 +138,9 @@ func (s *Server) handleTokenList(ctx context.Context, request *mcp.CallToolReque
 		result += fmt.Sprintf("• %s\n", maskToken(t.ID))
 		result += fmt.Sprintf("  Name:      %s\n", t.Name)
 		result += fmt.Sprintf("  Scope:     %s\n", t.Scope)
+		if !t.Quota.IsZero() {
+			result += fmt.Sprintf("  Quota:     %s\n", t.Quota.String())
+		}
 		result += fmt.Sprintf("  Created:   %s\n", t.CreatedAt.Format("2006-01-02 15:04"))
 		result += fmt.Sprintf("  Last Used: %s\n\n", lastUsed)
 	}
@@ -127,7 +159,7 @@ func (s *Server) handleTokenRevoke(ctx context.Context, request *mcp.CallToolReq
 	}
 
 	if params.TokenID == "" {
-		return nil, nil, fmt.Errorf("token_id is required")
+		return nil, nil, invalidParams("token_id is required")
 	}
 
 	callerTokenID, callerScope := getTokenInfo(authCtx)
@@ -151,14 +183,80 @@ func (s *Server) handleTokenRevoke(ctx context.Context, request *mcp.CallToolReq
 	}, nil, nil
 }
 
-func isValidScope(scope string) bool {
-	if scope == auth.ScopeAdmin || scope == auth.ScopeAdminRO {
-		return true
+func (s *Server) handleTokenUsage(ctx context.Context, request *mcp.CallToolRequest, params *TokenParams) (*mcp.CallToolResult, any, error) {
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	if params.TokenID == "" {
+		return nil, nil, invalidParams("token_id is required")
 	}
-	if len(scope) > 8 && scope[:8] == "project:" {
-		return true
+
+	quota, err := s.authStore.GetQuota(params.TokenID)
+	if err != nil {
+		return nil, nil, err
+	}
+	usage, err := s.authStore.GetUsage(params.TokenID, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var limits auth.Quota
+	if quota != nil {
+		limits = *quota
+	}
+
+	result := fmt.Sprintf("Usage for %s on %s (UTC):\n\n", maskToken(params.TokenID), usage.Day)
+	result += fmt.Sprintf("Requests:        %d\n", usage.Requests)
+	result += fmt.Sprintf("Sessions:        %s\n", formatUsage(int64(usage.Sessions), int64(limits.MaxSessionsPerDay)))
+	result += fmt.Sprintf("Active sessions: %s\n", formatUsage(int64(s.activeSessions.CountByOwner(params.TokenID)), int64(limits.MaxConcurrentSessions)))
+	result += fmt.Sprintf("Model tokens:    %s (%d input, %d output)\n", formatUsage(usage.ModelTokens(), limits.MaxTokensPerDay), usage.InputTokens, usage.OutputTokens)
+	result += fmt.Sprintf("\nQuota: %s\n", quota.String())
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, map[string]any{"usage": usage, "quota": quota}, nil
+}
+
+func (s *Server) handleTokenSetQuota(ctx context.Context, request *mcp.CallToolRequest, params *TokenParams) (*mcp.CallToolResult, any, error) {
+	authCtx, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if params.TokenID == "" {
+		return nil, nil, invalidParams("token_id is required")
+	}
+
+	callerTokenID, callerScope := getTokenInfo(authCtx)
+	if err := s.authStore.SetQuota(params.TokenID, params.Quota); err != nil {
+		audit.LogFailure(audit.OpTokenQuota, callerTokenID, callerScope, "", err)
+		return nil, nil, fmt.Errorf("failed to set quota: %w", err)
+	}
+
+	audit.Log(&audit.Event{
+		Operation:  audit.OpTokenQuota,
+		TokenID:    callerTokenID,
+		TokenScope: callerScope,
+		Success:    true,
+		Details:    map[string]interface{}{"target_token_id": maskToken(params.TokenID), "quota": params.Quota.String()},
+	})
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("✅ Quota for %s set: %s", maskToken(params.TokenID), params.Quota.String())},
+		},
+	}, nil, nil
+}
+
+// formatUsage renders "used / limit", or just "used" when unlimited
+func formatUsage(used, limit int64) string {
+	if limit <= 0 {
+		return fmt.Sprintf("%d", used)
 	}
-	return false
+	return fmt.Sprintf("%d / %d", used, limit)
 }
 
 func maskToken(tokenID string) string {
@@ -6,7 +6,7 @@ import (
 	"github.com/HyphaGroup/oubliette/internal/auth"
 )
 
-func TestIsValidScope(t *testing.T) {
+func TestValidateScope(t *testing.T) {
 	tests := []struct {
 		name  string
 		scope string
@@ -20,13 +20,17 @@ func TestIsValidScope(t *testing.T) {
 		{"random string", "invalid", false},
 		{"project without id", "project:", false}, // Too short after prefix
 		{"partial project", "projec", false},
+		{"multi project", "project:a project:b:ro", true},
+		{"tool rules", "project:a tool:session:message deny:container:exec", true},
+		{"tool rules only", "tool:session", false},
+		{"bad tool rule", "admin tool:", false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isValidScope(tt.scope)
+			got := auth.ValidateScope(tt.scope) == nil
 			if got != tt.want {
-				t.Errorf("isValidScope(%q) = %v, want %v", tt.scope, got, tt.want)
+				t.Errorf("ValidateScope(%q) valid = %v, want %v", tt.scope, got, tt.want)
 			}
 		})
 	}
@@ -112,3 +116,12 @@ func TestTokenParams(t *testing.T) {
 		t.Errorf("TokenID = %q, want %q", revokeParams.TokenID, "token-to-revoke")
 	}
 }
+
+func TestFormatUsage(t *testing.T) {
+	if got := formatUsage(3, 0); got != "3" {
+		t.Errorf("formatUsage(3, 0) = %q, want %q", got, "3")
+	}
+	if got := formatUsage(3, 10); got != "3 / 10" {
+		t.Errorf("formatUsage(3, 10) = %q, want %q", got, "3 / 10")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/HyphaGroup/oubliette/internal/audit"
 	"github.com/HyphaGroup/oubliette/internal/logger"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -35,7 +36,7 @@ func (s *Server) handleWorkspace(ctx context.Context, request *mcp.CallToolReque
 
 func (s *Server) handleWorkspaceList(ctx context.Context, request *mcp.CallToolRequest, params *WorkspaceParams) (*mcp.CallToolResult, any, error) {
 	if params.ProjectID == "" {
-		return nil, nil, fmt.Errorf("project_id is required")
+		return nil, nil, invalidParams("project_id is required")
 	}
 
 	if _, err := requireProjectAccess(ctx, params.ProjectID); err != nil {
@@ -90,21 +91,23 @@ func (s *Server) handleWorkspaceList(ctx context.Context, request *mcp.CallToolR
 
 func (s *Server) handleWorkspaceDelete(ctx context.Context, request *mcp.CallToolRequest, params *WorkspaceParams) (*mcp.CallToolResult, any, error) {
 	if params.ProjectID == "" {
-		return nil, nil, fmt.Errorf("project_id is required")
+		return nil, nil, invalidParams("project_id is required")
 	}
 	if params.WorkspaceID == "" {
-		return nil, nil, fmt.Errorf("workspace_id is required")
+		return nil, nil, invalidParams("workspace_id is required")
 	}
 
 	authCtx, err := requireProjectAccess(ctx, params.ProjectID)
 	if err != nil {
 		return nil, nil, err
 	}
-	if !authCtx.CanWrite() {
-		return nil, nil, fmt.Errorf("read-only access, cannot delete workspaces")
+	if !authCtx.CanWriteProject(params.ProjectID) {
+		return nil, nil, forbidden("read-only access, cannot delete workspaces")
 	}
 
-	if err := s.projectMgr.DeleteWorkspace(params.ProjectID, params.WorkspaceID); err != nil {
+	err = s.projectMgr.DeleteWorkspace(params.ProjectID, params.WorkspaceID)
+	logAudit(ctx, &audit.Event{Operation: audit.OpWorkspaceDelete, ProjectID: params.ProjectID, WorkspaceID: params.WorkspaceID}, err)
+	if err != nil {
 		return nil, nil, fmt.Errorf("failed to delete workspace: %w", err)
 	}
 
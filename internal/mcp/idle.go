@@ -0,0 +1,252 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
+	"github.com/HyphaGroup/oubliette/internal/audit"
+	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/logger"
+	"github.com/HyphaGroup/oubliette/internal/metrics"
+	"github.com/HyphaGroup/oubliette/internal/project"
+)
+
+// idleCheckInterval is how often running containers are checked for inactivity
+const idleCheckInterval = time.Minute
+
+// idleStopper stops project containers that have had no session activity for
+// the idle period. Running sessions, session starts, container exec, and
+// streamed execs and terminal shells for as long as they run count as
+// activity. A container first seen running starts its idle clock then.
+type idleStopper struct {
+	s       *Server
+	timeout time.Duration
+
+	mu         sync.Mutex
+	lastActive map[string]time.Time // projectID -> last activity
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newIdleStopper(s *Server, timeout time.Duration) *idleStopper {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &idleStopper{
+		s:          s,
+		timeout:    timeout,
+		lastActive: make(map[string]time.Time),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start begins checking for idle containers
+func (i *idleStopper) Start() {
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+		ticker := time.NewTicker(idleCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-i.ctx.Done():
+				return
+			case now := <-ticker.C:
+				i.check(i.ctx, now)
+			}
+		}
+	}()
+}
+
+// Stop ends idle checking and waits for an in-flight check
+func (i *idleStopper) Stop() {
+	i.cancel()
+	i.wg.Wait()
+}
+
+// Touch records activity for a project. Safe to call when idle stopping is disabled.
+func (i *idleStopper) Touch(projectID string) {
+	if i == nil {
+		return
+	}
+	i.mu.Lock()
+	i.lastActive[projectID] = time.Now()
+	i.mu.Unlock()
+}
+
+// KeepAwake records activity for a project every check interval until done is
+// closed, so long-running execs and terminals aren't mistaken for idleness
+func (i *idleStopper) KeepAwake(projectID string, done <-chan struct{}) {
+	if i == nil {
+		return
+	}
+	i.Touch(projectID)
+	go func() {
+		ticker := time.NewTicker(idleCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				i.Touch(projectID)
+			}
+		}
+	}()
+}
+
+// check stops every running container idle for longer than the timeout
+func (i *idleStopper) check(ctx context.Context, now time.Time) {
+	projects, err := i.s.projectMgr.List(nil)
+	if err != nil {
+		logger.Error("Idle check: failed to list projects: %v", err)
+		return
+	}
+
+	for _, proj := range projects {
+		if ctx.Err() != nil {
+			return
+		}
+		if idleFor, ok := i.idleFor(ctx, proj.ID, now); ok {
+			stop := &project.ContainerStop{
+				Reason:    project.StopReasonIdle,
+				StoppedAt: now.UTC(),
+				IdleFor:   idleFor.Round(time.Second).String(),
+			}
+			if err := i.s.stopProjectContainer(ctx, proj.ID, stop); err != nil {
+				logger.Error("Failed to stop idle container for project %s: %v", proj.ID, err)
+				continue
+			}
+			metrics.RecordContainerIdleStop()
+			logger.Info("Stopped container for project %s after %s without activity", proj.ID, stop.IdleFor)
+		}
+	}
+}
+
+// idleFor reports how long a project's running container has been idle, and
+// whether that exceeds the timeout
+func (i *idleStopper) idleFor(ctx context.Context, projectID string, now time.Time) (time.Duration, bool) {
+	for _, sess := range i.s.activeSessions.ListByProject(projectID) {
+		if sess.IsRunning() {
+			i.mu.Lock()
+			i.lastActive[projectID] = now
+			i.mu.Unlock()
+			return 0, false
+		}
+	}
+
+	name, err := container.ProjectContainerName(projectID)
+	if err != nil {
+		return 0, false
+	}
+	status, err := i.s.runtime.Status(ctx, name)
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if err != nil || status != container.StatusRunning {
+		delete(i.lastActive, projectID)
+		return 0, false
+	}
+
+	last, ok := i.lastActive[projectID]
+	if !ok {
+		i.lastActive[projectID] = now
+		return 0, false
+	}
+	if idle := now.Sub(last); idle >= i.timeout {
+		delete(i.lastActive, projectID)
+		return idle, true
+	}
+	return 0, false
+}
+
+// stopProjectContainer stops a project's container and its agent server and
+// records why it was stopped
+func (s *Server) stopProjectContainer(ctx context.Context, projectID string, stop *project.ContainerStop) error {
+	name, err := container.ProjectContainerName(projectID)
+	if err != nil {
+		return err
+	}
+
+	// The agent server dies with the container; forget it so the next session starts a fresh one
+	if info, err := s.runtime.Inspect(ctx, name); err == nil {
+		if warmer, ok := s.agentRuntime.(agent.Warmer); ok {
+			warmer.StopServer(info.ID)
+		}
+	}
+
+	err = s.runtime.Stop(ctx, name)
+	logAudit(ctx, &audit.Event{
+		Operation: audit.OpContainerStop,
+		ProjectID: projectID,
+		Details:   map[string]interface{}{"reason": stop.Reason},
+	}, err)
+	if err != nil {
+		return err
+	}
+
+	if err := s.projectMgr.SaveContainerStop(projectID, stop); err != nil {
+		logger.Error("Failed to record container stop for project %s: %v", projectID, err)
+	}
+	return nil
+}
+
+// ensureProjectContainer makes sure a project's container is running for a
+// session. A container stopped for inactivity is restarted as it was; anything
+// else missing or stopped gets a freshly created container.
+func (s *Server) ensureProjectContainer(ctx context.Context, proj *project.Project) (string, error) {
+	name, err := container.ProjectContainerName(proj.ID)
+	if err != nil {
+		return "", err
+	}
+	defer s.idle.Touch(proj.ID)
+
+	status, err := s.runtime.Status(ctx, name)
+	if err == nil && status == container.StatusRunning {
+		return name, nil
+	}
+
+	// The container exists but is stopped: wake it if the idle policy stopped it
+	if err == nil {
+		if stop, _ := s.projectMgr.GetContainerStop(proj.ID); stop != nil && stop.Reason == project.StopReasonIdle {
+			wakeErr := s.wakeContainer(ctx, name, proj.ID)
+			metrics.RecordContainerWake(wakeErr)
+			if wakeErr == nil {
+				logger.Info("Woke container for project %s (stopped idle at %s)", proj.ID, stop.StoppedAt.Format(time.RFC3339))
+				_ = s.projectMgr.ClearContainerStop(proj.ID)
+				return name, nil
+			}
+			logger.Error("Failed to wake container for project %s, creating a new one: %v", proj.ID, wakeErr)
+		}
+	}
+
+	logger.Info("Container not running for project %s, starting automatically", proj.ID)
+	if _, err := s.createAndStartContainer(ctx, name, proj.ImageName, proj.ID); err != nil {
+		return "", fmt.Errorf("failed to auto-start container: %w", err)
+	}
+	logger.Info("Container started automatically for project %s", proj.ID)
+	return name, nil
+}
+
+// wakeContainer restarts a stopped container; its init script starts the
+// relay and the agent server starts on the next session as usual. Pool-created
+// containers are not restarted: their mounts still name the pool slot.
+func (s *Server) wakeContainer(ctx context.Context, name, projectID string) error {
+	info, err := s.runtime.Inspect(ctx, name)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(info.Env, poolContainerEnv) {
+		return fmt.Errorf("container was created by the warm pool")
+	}
+
+	// Recreate the socket directory so a stale socket doesn't look ready
+	if err := EnsureSocketDir(projectID); err != nil {
+		return fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	return s.runtime.Start(ctx, name)
+}
@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/project"
+	"github.com/HyphaGroup/oubliette/internal/session"
+	"github.com/HyphaGroup/oubliette/internal/testutil"
+)
+
+func TestIdleStopAndWake(t *testing.T) {
+	s, projectID := newPromptTestServer(t)
+	rt := testutil.NewFakeRuntime()
+	defer func() { _ = rt.Close() }()
+	s.runtime = rt
+	s.activeSessions = session.NewActiveSessionManager(session.DefaultMaxActiveSessions, session.DefaultSessionIdleTimeout)
+	defer s.activeSessions.Close()
+	s.idle = newIdleStopper(s, 30*time.Minute)
+	t.Cleanup(func() { _ = CleanupSocketDir(projectID) })
+
+	ctx := context.Background()
+	name := testContainerName(t, projectID)
+	id, err := rt.Create(ctx, container.CreateConfig{Name: name, Image: "oubliette-base:latest", Cmd: []string{"sleep", "3600"}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := rt.Start(ctx, id); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	start := time.Now()
+
+	// First sighting starts the idle clock
+	s.idle.check(ctx, start)
+	s.idle.check(ctx, start.Add(29*time.Minute))
+	if status, _ := rt.Status(ctx, name); status != container.StatusRunning {
+		t.Fatalf("container %s before idle period, want running", status)
+	}
+
+	s.idle.check(ctx, start.Add(31*time.Minute))
+	if status, _ := rt.Status(ctx, name); status != container.StatusExited {
+		t.Fatalf("container %s after idle period, want exited", status)
+	}
+	stop, err := s.projectMgr.GetContainerStop(projectID)
+	if err != nil || stop == nil || stop.Reason != project.StopReasonIdle || stop.IdleFor != "31m0s" {
+		t.Fatalf("GetContainerStop() = %+v, %v; want idle stop after 31m0s", stop, err)
+	}
+
+	proj, err := s.projectMgr.Get(projectID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	got, err := s.ensureProjectContainer(ctx, proj)
+	if err != nil {
+		t.Fatalf("ensureProjectContainer() error = %v", err)
+	}
+	if got != name {
+		t.Errorf("ensureProjectContainer() = %q, want %q", got, name)
+	}
+
+	// Waking restarts the same container rather than creating a new one
+	info, err := rt.Inspect(ctx, name)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if info.ID != id || info.Status != container.StatusRunning {
+		t.Errorf("after wake: ID = %s, status = %s; want %s running", info.ID, info.Status, id)
+	}
+	if stop, _ := s.projectMgr.GetContainerStop(projectID); stop != nil {
+		t.Errorf("stop record not cleared after wake: %+v", stop)
+	}
+}
+
+func TestIdleStopperTouch(t *testing.T) {
+	var disabled *idleStopper
+	disabled.Touch("550e8400-e29b-41d4-a716-446655440000") // must not panic
+	disabled.KeepAwake("550e8400-e29b-41d4-a716-446655440000", nil)
+
+	s, projectID := newPromptTestServer(t)
+	rt := testutil.NewMockRuntime(t)
+	s.runtime = rt
+	s.activeSessions = session.NewActiveSessionManager(session.DefaultMaxActiveSessions, session.DefaultSessionIdleTimeout)
+	defer s.activeSessions.Close()
+	s.idle = newIdleStopper(s, time.Minute)
+
+	rt.SetContainerStatus(testContainerName(t, projectID), container.StatusRunning)
+	s.idle.Touch(projectID)
+	s.idle.check(context.Background(), time.Now().Add(30*time.Second))
+	if len(rt.StopCalls) != 0 {
+		t.Errorf("recently active container stopped: %v", rt.StopCalls)
+	}
+}
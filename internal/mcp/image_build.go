@@ -0,0 +1,175 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/audit"
+	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/logger"
+	"github.com/HyphaGroup/oubliette/internal/project"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// buildLogTailLines is how many build log lines are kept for error reports
+const buildLogTailLines = 20
+
+// buildEvent is pushed to the calling MCP client while a project image builds
+type buildEvent struct {
+	Type      string `json:"type"` // build_log, build_completed, build_failed
+	ProjectID string `json:"project_id"`
+	Image     string `json:"image,omitempty"`
+	Line      string `json:"line,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// buildLogWriter splits build output into lines, logs them and streams them to
+// the MCP client that triggered the build (if any)
+type buildLogWriter struct {
+	ctx       context.Context
+	session   *mcp.ServerSession
+	projectID string
+
+	mu      sync.Mutex
+	partial []byte
+	tail    []string
+}
+
+func newBuildLogWriter(ctx context.Context, projectID string) *buildLogWriter {
+	w := &buildLogWriter{ctx: ctx, projectID: projectID}
+	if req := CallToolRequestFromContext(ctx); req != nil {
+		w.session = req.Session
+	}
+	return w
+}
+
+func (w *buildLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(string(w.partial[:i]))
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush emits any trailing output without a newline
+func (w *buildLogWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.partial) > 0 {
+		w.emit(string(w.partial))
+		w.partial = nil
+	}
+}
+
+// Tail returns the last lines of build output
+func (w *buildLogWriter) Tail() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.tail...)
+}
+
+func (w *buildLogWriter) emit(line string) {
+	line = strings.TrimRight(line, "\r")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	w.tail = append(w.tail, line)
+	if len(w.tail) > buildLogTailLines {
+		w.tail = w.tail[len(w.tail)-buildLogTailLines:]
+	}
+	logger.Info("[build %s] %s", w.projectID[:8], line)
+	w.notify(buildEvent{Type: "build_log", ProjectID: w.projectID, Line: line})
+}
+
+func (w *buildLogWriter) notify(event buildEvent) {
+	if w.session == nil {
+		return
+	}
+	_ = w.session.Log(w.ctx, &mcp.LoggingMessageParams{
+		Logger: "oubliette.build",
+		Level:  "info",
+		Data:   event,
+	})
+}
+
+// ensureProjectImage builds the project's custom image if its content hash has
+// no image yet (or force is set) and returns the image to run
+func (s *Server) ensureProjectImage(ctx context.Context, proj *project.Project, force bool) (*container.ProjectImage, error) {
+	dockerfile, contextDir := s.projectMgr.Dockerfile(proj)
+	if dockerfile == "" {
+		return nil, fmt.Errorf("project %s has no Dockerfile (add %s to the repository)", proj.ID, project.RepoDockerfilePath)
+	}
+	if s.imageManager == nil {
+		return nil, fmt.Errorf("image manager not configured")
+	}
+
+	var previousImage string
+	if prev, err := s.projectMgr.GetImageBuild(proj.ID); err == nil && prev != nil {
+		previousImage = prev.Image
+	}
+
+	output := newBuildLogWriter(ctx, proj.ID)
+	img, err := s.imageManager.BuildProjectImage(ctx, container.ProjectImageBuild{
+		ProjectID:     proj.ID,
+		Dockerfile:    dockerfile,
+		ContextDir:    contextDir,
+		BaseImage:     proj.ImageName,
+		PreviousImage: previousImage,
+		Force:         force,
+		Output:        output,
+	})
+	output.Flush()
+
+	if err != nil {
+		if tail := output.Tail(); len(tail) > 0 {
+			err = fmt.Errorf("%w\n\n%s", err, strings.Join(tail, "\n"))
+		}
+		output.notify(buildEvent{Type: "build_failed", ProjectID: proj.ID, Error: err.Error()})
+		logAudit(ctx, &audit.Event{Operation: audit.OpContainerBuild, ProjectID: proj.ID}, err)
+		return nil, err
+	}
+	if !img.Built {
+		return img, nil
+	}
+
+	output.notify(buildEvent{Type: "build_completed", ProjectID: proj.ID, Image: img.Image})
+	logAudit(ctx, &audit.Event{
+		Operation: audit.OpContainerBuild,
+		ProjectID: proj.ID,
+		Details:   map[string]interface{}{"image": img.Image, "base_image": proj.ImageName, "force": force},
+	}, nil)
+	logger.Info("Built image %s for project %s", img.Image, proj.ID)
+
+	if err := s.projectMgr.SaveImageBuild(proj.ID, &project.ImageBuild{
+		Image:     img.Image,
+		Hash:      img.Hash,
+		BaseImage: proj.ImageName,
+		BuiltAt:   time.Now().UTC(),
+	}); err != nil {
+		logger.Error("Failed to record image build for project %s: %v", proj.ID, err)
+	}
+	return img, nil
+}
+
+// removeProjectImage removes the project's custom image, if one was built
+func (s *Server) removeProjectImage(ctx context.Context, projectID string) {
+	build, err := s.projectMgr.GetImageBuild(projectID)
+	if err != nil || build == nil {
+		return
+	}
+	if err := s.runtime.RemoveImage(ctx, build.Image); err != nil {
+		logger.Error("Failed to remove image %s for project %s: %v", build.Image, projectID, err)
+	}
+}
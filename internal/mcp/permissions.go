@@ -1,57 +1,92 @@
 package mcp
 
-import "github.com/HyphaGroup/oubliette/internal/auth"
+import (
+	"github.com/HyphaGroup/oubliette/internal/auth"
+)
 
 // IsToolAllowed checks if a tool is allowed for a given token scope and project
 func IsToolAllowed(tool *ToolDef, tokenScope, projectID string) bool {
-	isAdmin := tokenScope == auth.ScopeAdmin
-	isAdminRO := auth.IsAdminScope(tokenScope) && auth.IsReadOnlyScope(tokenScope)
-	isProjectScope := auth.IsProjectScope(tokenScope)
-	isReadOnly := auth.IsReadOnlyScope(tokenScope)
-	scopeProjectID := auth.ExtractProjectID(tokenScope)
+	return IsToolActionAllowed(tool, "", tokenScope, projectID)
+}
 
-	// Admin-only tools (token management) require full admin
-	if tool.Access == AccessAdmin {
-		return isAdmin
+// IsToolActionAllowed checks if a tool action is allowed for a given token scope and project.
+// It combines the tool rules in the scope (CheckToolScope) with project/admin access.
+func IsToolActionAllowed(tool *ToolDef, action, tokenScope, projectID string) bool {
+	scope, err := auth.ParseScope(tokenScope)
+	if err != nil {
+		return false
 	}
-
-	// Write access check - read-only tokens can't write
-	if tool.Access == AccessWrite && isReadOnly {
+	if CheckToolScope(tool, action, scope) != nil {
 		return false
 	}
 
+	access := tool.ActionAccess(action)
+
+	// Admin-only tools (token management) require full admin
+	if access == AccessAdmin {
+		return scope.IsAdmin()
+	}
+
 	// Global tools
 	if tool.Target == TargetGlobal {
-		// Admin scopes can access all global tools (respecting read/write)
-		if isAdmin || isAdminRO {
-			return true
-		}
-		// Project scopes can access global read tools only
-		if isProjectScope && tool.Access == AccessRead {
-			return true
+		if access == AccessWrite {
+			return scope.IsAdmin()
 		}
-		return false
+		// Admin and project scopes can access global read tools
+		return scope.HasAdmin() || len(scope.Projects) > 0
 	}
 
 	// Project-targeted tools
 	if tool.Target == TargetProject {
 		// Admin scopes can access any project
-		if isAdmin || isAdminRO {
-			return true
+		if scope.HasAdmin() {
+			return access == AccessRead || scope.IsAdmin()
+		}
+		// Empty projectID means we can't verify - deny for safety
+		if projectID == "" {
+			return false
 		}
-		// Project scopes can only access their project
-		if isProjectScope {
-			// Empty projectID means we can't verify - deny for safety
-			if projectID == "" {
-				return false
-			}
-			return scopeProjectID == projectID
+		if access == AccessRead {
+			return scope.CanAccessProject(projectID)
 		}
+		return scope.CanWriteProject(projectID)
 	}
 
 	return false
 }
 
+// CheckToolScope applies the tool-level rules of a scope (tool: grants, deny: rules
+// and read-only grants) to a tool call. Project and admin access are checked separately.
+func CheckToolScope(tool *ToolDef, action string, scope *auth.Scope) error {
+	write := tool.ActionAccess(action) != AccessRead
+	if !scope.AllowsToolAction(tool.Name, action, write) {
+		if action != "" {
+			return forbidden("token scope does not permit %s %s", tool.Name, action)
+		}
+		return forbidden("token scope does not permit %s", tool.Name)
+	}
+	return nil
+}
+
+// isToolVisible reports whether a tool should be listed for a token scope.
+// Project-scoped tokens see project tools; enforcement happens at call time.
+func isToolVisible(tool *ToolDef, scope *auth.Scope) bool {
+	if !scope.MayUseTool(tool.Name) {
+		return false
+	}
+	switch {
+	case tool.Access == AccessAdmin:
+		return scope.IsAdmin()
+	case tool.Target == TargetProject:
+		return scope.HasAdmin() || len(scope.Projects) > 0
+	case tool.Access == AccessWrite && !scope.IsAdmin():
+		// Global write tools are visible only if some of their actions are read-only
+		return len(tool.ReadActions) > 0 && (scope.HasAdmin() || len(scope.Projects) > 0)
+	default:
+		return scope.HasAdmin() || len(scope.Projects) > 0
+	}
+}
+
 // ExtractProjectIDFromArgs extracts project ID from tool arguments
 func ExtractProjectIDFromArgs(args map[string]any) string {
 	if pid, ok := args["project_id"].(string); ok && pid != "" {
@@ -59,3 +94,11 @@ func ExtractProjectIDFromArgs(args map[string]any) string {
 	}
 	return ""
 }
+
+// ExtractActionFromArgs extracts the action from tool arguments
+func ExtractActionFromArgs(args map[string]any) string {
+	if action, ok := args["action"].(string); ok {
+		return action
+	}
+	return ""
+}
@@ -160,6 +160,61 @@ func TestIsToolAllowed(t *testing.T) {
 	}
 }
 
+func TestIsToolActionAllowed(t *testing.T) {
+	session := ToolDef{Name: "session", Target: TargetProject, Access: AccessWrite, ReadActions: []string{"get", "list", "events"}}
+	schedule := ToolDef{Name: "schedule", Target: TargetGlobal, Access: AccessWrite, ReadActions: []string{"list", "get", "history"}}
+	container := ToolDef{Name: "container", Target: TargetProject, Access: AccessWrite, ReadActions: []string{"logs"}}
+
+	tests := []struct {
+		name       string
+		tool       ToolDef
+		action     string
+		tokenScope string
+		projectID  string
+		want       bool
+	}{
+		{"multi project first", session, "spawn", "project:a project:b", "a", true},
+		{"multi project second", session, "spawn", "project:a,project:b", "b", true},
+		{"multi project other", session, "get", "project:a project:b", "c", false},
+		{"multi project ro write", session, "spawn", "project:a project:b:ro", "b", false},
+		{"multi project ro read", session, "get", "project:a project:b:ro", "b", true},
+		{"project:ro read action", container, "logs", "project:a:ro", "a", true},
+		{"admin:ro read action", container, "logs", auth.ScopeAdminRO, "a", true},
+		{"admin:ro write action", container, "exec", auth.ScopeAdminRO, "a", false},
+		{"project scope global read action", schedule, "list", "project:a", "", true},
+		{"project scope global write action", schedule, "create", "project:a", "", false},
+		{"action grant allowed", session, "message", "project:a tool:session:message", "a", true},
+		{"action grant other action", session, "spawn", "project:a tool:session:message", "a", false},
+		{"action grant other tool", container, "start", "project:a tool:session:message", "a", false},
+		{"read-only tool grant", session, "events", "project:a tool:session:ro", "a", true},
+		{"read-only tool grant write", session, "end", "project:a tool:session:ro", "a", false},
+		{"deny action", container, "exec", "admin deny:container:exec", "a", false},
+		{"deny leaves other actions", container, "start", "admin deny:container:exec", "a", true},
+		{"invalid scope", session, "get", "bogus", "a", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := IsToolActionAllowed(&tt.tool, tt.action, tt.tokenScope, tt.projectID)
+			if got != tt.want {
+				t.Errorf("IsToolActionAllowed(%q, %q) = %v, want %v", tt.tool.Name, tt.action, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractActionFromArgs(t *testing.T) {
+	if got := ExtractActionFromArgs(map[string]any{"action": "spawn"}); got != "spawn" {
+		t.Errorf("ExtractActionFromArgs() = %q, want %q", got, "spawn")
+	}
+	if got := ExtractActionFromArgs(map[string]any{"action": 1}); got != "" {
+		t.Errorf("ExtractActionFromArgs() = %q, want empty", got)
+	}
+	if got := ExtractActionFromArgs(nil); got != "" {
+		t.Errorf("ExtractActionFromArgs() = %q, want empty", got)
+	}
+}
+
 func TestExtractProjectIDFromArgs(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/HyphaGroup/oubliette/internal/auth"
+	"github.com/HyphaGroup/oubliette/internal/logger"
+	"github.com/HyphaGroup/oubliette/internal/session"
+)
+
+// checkSessionQuota verifies the caller may start another session
+func (s *Server) checkSessionQuota(authCtx *auth.AuthContext) error {
+	if s.authStore == nil || authCtx == nil || authCtx.Token == nil {
+		return nil
+	}
+	token := authCtx.Token
+	return s.authStore.CheckSessionQuota(token.ID, token.Quota, s.activeSessions.CountByOwner(token.ID))
+}
+
+// checkModelTokenQuota verifies the caller has model tokens left today
+func (s *Server) checkModelTokenQuota(authCtx *auth.AuthContext) error {
+	if s.authStore == nil || authCtx == nil || authCtx.Token == nil {
+		return nil
+	}
+	return s.authStore.CheckModelTokenQuota(authCtx.Token.ID, authCtx.Token.Quota)
+}
+
+// checkOwnerTokenQuota verifies a session owner has model tokens left today.
+// Used for work started from inside a session, where no caller token is present.
+func (s *Server) checkOwnerTokenQuota(tokenID string) error {
+	if s.authStore == nil || tokenID == "" {
+		return nil
+	}
+	quota, err := s.authStore.GetQuota(tokenID)
+	if err != nil {
+		return err
+	}
+	return s.authStore.CheckModelTokenQuota(tokenID, quota)
+}
+
+// checkOwnerSessionQuota verifies a session owner may start another session.
+// Used for children spawned from inside a session; running counts the owner's
+// sessions that are not tracked as active sessions.
+func (s *Server) checkOwnerSessionQuota(tokenID string, running int) error {
+	if s.authStore == nil || tokenID == "" {
+		return nil
+	}
+	quota, err := s.authStore.GetQuota(tokenID)
+	if err != nil {
+		return err
+	}
+	return s.authStore.CheckSessionQuota(tokenID, quota, s.activeSessions.CountByOwner(tokenID)+running)
+}
+
+// setSessionOwner attributes an active session to the calling token and counts
+// it against the token's daily session usage. Must be called before Register.
+func (s *Server) setSessionOwner(ctx context.Context, activeSess *session.ActiveSession) {
+	authCtx := auth.FromContext(ctx)
+	if authCtx == nil || authCtx.Token == nil {
+		return
+	}
+	activeSess.OwnerTokenID = authCtx.Token.ID
+	s.recordSession(authCtx.Token.ID)
+}
+
+// recordSession counts a started session against its owner's daily usage
+func (s *Server) recordSession(tokenID string) {
+	if s.authStore == nil || tokenID == "" {
+		return
+	}
+	if err := s.authStore.RecordSession(tokenID); err != nil {
+		logger.Error("Failed to record session usage: %v", err)
+	}
+}
+
+// recordModelTokens counts model tokens used by a session against its owner
+func (s *Server) recordModelTokens(tokenID string, inputTokens, outputTokens int) {
+	if s.authStore == nil || tokenID == "" {
+		return
+	}
+	if err := s.authStore.RecordModelTokens(tokenID, inputTokens, outputTokens); err != nil {
+		logger.Error("Failed to record model token usage: %v", err)
+	}
+}
+
+// scheduleAuthContext builds the auth context a schedule runs under: its creator's
+// token, with the token's current quota when it still exists
+func (s *Server) scheduleAuthContext(ctx context.Context, tokenID, scope string) context.Context {
+	token := &auth.Token{ID: tokenID, Scope: scope}
+	if s.authStore != nil {
+		if quota, err := s.authStore.GetQuota(tokenID); err == nil {
+			token.Quota = quota
+		}
+	}
+	return auth.WithContext(ctx, &auth.AuthContext{Type: auth.AuthTypeToken, Token: token})
+}
@@ -9,7 +9,10 @@ import (
 	"sync"
 
 	"github.com/HyphaGroup/oubliette/internal/auth"
+	"github.com/HyphaGroup/oubliette/internal/metrics"
+	"github.com/HyphaGroup/oubliette/internal/tracing"
 	mcp_sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ToolHandler is a function that handles a tool call
@@ -36,15 +39,31 @@ type ToolDef struct {
 	Description string         `json:"description"`
 	Target      ToolTarget     `json:"target,omitempty"`
 	Access      ToolAccess     `json:"access,omitempty"`
+	Actions     []string       `json:"actions,omitempty"`      // Valid values of the action parameter, if the tool takes one
+	ReadActions []string       `json:"read_actions,omitempty"` // Actions that only read, for write tools
 	InputSchema map[string]any `json:"inputSchema,omitempty"`
 }
 
+// ActionAccess returns the access level required for an action of the tool
+func (d *ToolDef) ActionAccess(action string) ToolAccess {
+	for _, a := range d.ReadActions {
+		if a == action {
+			return AccessRead
+		}
+	}
+	return d.Access
+}
+
 // Registry stores tool definitions and handlers
 type Registry struct {
 	mu       sync.RWMutex
 	tools    map[string]*ToolDef
 	handlers map[string]ToolHandler
 	order    []string // preserve registration order
+
+	// sessionProject maps a session ID to its project for calls that name
+	// only a session; optional
+	sessionProject func(sessionID string) string
 }
 
 // NewRegistry creates a new tool registry
@@ -72,6 +91,14 @@ func Register[P any](r *Registry, def ToolDef, handler func(ctx context.Context,
 	r.order = append(r.order, def.Name)
 }
 
+// SetSessionProjectResolver sets how calls that name a session_id but no
+// project_id are attributed to a project for scope checks
+func (r *Registry) SetSessionProjectResolver(resolve func(sessionID string) string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessionProject = resolve
+}
+
 // GetTool returns a tool definition by name
 func (r *Registry) GetTool(name string) (*ToolDef, bool) {
 	r.mu.RLock()
@@ -98,15 +125,18 @@ func (r *Registry) GetAllTools() []*ToolDef {
 // Note: For project-scoped tokens, this returns tools the scope CAN access
 // but project-targeted tools will still be checked per-call with the actual project ID
 func (r *Registry) GetToolsForScope(tokenScope string) []*ToolDef {
+	scope, err := auth.ParseScope(tokenScope)
+	if err != nil {
+		return nil
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	tools := make([]*ToolDef, 0)
 	for _, name := range r.order {
 		def := r.tools[name]
-		// For listing, use empty projectID - project-scoped tokens see project tools
-		// but enforcement happens at call time
-		if IsToolAllowed(def, tokenScope, "") || (auth.IsProjectScope(tokenScope) && def.Target == TargetProject) {
+		if isToolVisible(def, scope) {
 			tools = append(tools, def)
 		}
 	}
@@ -116,11 +146,16 @@ func (r *Registry) GetToolsForScope(tokenScope string) []*ToolDef {
 // IsToolAllowed checks if a specific tool name is allowed for a token scope
 // projectID is used for project-scoped permission checks
 func (r *Registry) IsToolAllowedWithProject(toolName, tokenScope, projectID string) bool {
+	return r.IsToolActionAllowed(toolName, "", tokenScope, projectID)
+}
+
+// IsToolActionAllowed checks if a tool action is allowed for a token scope and project
+func (r *Registry) IsToolActionAllowed(toolName, action, tokenScope, projectID string) bool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	if def, ok := r.tools[toolName]; ok {
-		return IsToolAllowed(def, tokenScope, projectID)
+		return IsToolActionAllowed(def, action, tokenScope, projectID)
 	}
 	return false
 }
@@ -134,14 +169,85 @@ func (r *Registry) IsToolAllowed(toolName, tokenScope string) bool {
 func (r *Registry) CallTool(ctx context.Context, name string, args json.RawMessage) (any, error) {
 	r.mu.RLock()
 	handler, ok := r.handlers[name]
+	def := r.tools[name]
+	sessionProject := r.sessionProject
 	r.mu.RUnlock()
 
 	if !ok {
-		return nil, fmt.Errorf("unknown tool: %s", name)
+		return nil, notFound("unknown tool: %s", name)
+	}
+
+	var call struct {
+		Action    string `json:"action"`
+		ProjectID string `json:"project_id"`
+		SessionID string `json:"session_id"`
+	}
+	if len(args) > 0 {
+		_ = json.Unmarshal(args, &call)
+	}
+	if call.ProjectID == "" && call.SessionID != "" && sessionProject != nil {
+		call.ProjectID = sessionProject(call.SessionID)
+	}
+	ctx, span := tracing.Start(ctx, "tool "+name,
+		attribute.String("mcp.tool", name),
+		attribute.String("mcp.action", call.Action),
+		attribute.String("oubliette.project_id", call.ProjectID),
+	)
+
+	result, err := r.callTool(ctx, def, handler, call.Action, call.ProjectID, args)
+	metrics.RecordToolCall(name, err)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (r *Registry) callTool(ctx context.Context, def *ToolDef, handler ToolHandler, action, projectID string, args json.RawMessage) (any, error) {
+	if err := checkCallScope(ctx, def, action, projectID); err != nil {
+		return nil, err
 	}
 	return handler(ctx, args)
 }
 
+// checkCallScope enforces the caller's token scope on a tool call: tool
+// rules, admin access and access to the project named by the call. Handlers
+// check again against the resources they touch, but a handler that forgets
+// to is still covered here.
+func checkCallScope(ctx context.Context, def *ToolDef, action, projectID string) error {
+	authCtx := auth.FromContext(ctx)
+	if authCtx == nil || authCtx.Token == nil {
+		return ErrUnauthenticated
+	}
+	scope, err := auth.ParseScope(authCtx.Token.Scope)
+	if err != nil {
+		return forbidden("invalid token scope: %w", err)
+	}
+	if err := CheckToolScope(def, action, scope); err != nil {
+		return err
+	}
+	if IsToolActionAllowed(def, action, authCtx.Token.Scope, projectID) {
+		return nil
+	}
+	switch {
+	case def.ActionAccess(action) == AccessAdmin:
+		return forbidden("admin access required")
+	case def.Target == TargetProject && projectID != "" && scope.CanAccessProject(projectID):
+		return forbidden("read-only access, write operations not permitted")
+	case def.Target == TargetProject && projectID != "":
+		return forbidden("not authorized to access project %s", projectID)
+	case def.Target == TargetProject:
+		return forbidden("admin access required for %s without a project_id", callName(def.Name, action))
+	default:
+		return forbidden("token scope does not permit %s", callName(def.Name, action))
+	}
+}
+
+// callName names a tool call for error messages
+func callName(tool, action string) string {
+	if action == "" {
+		return tool
+	}
+	return tool + " " + action
+}
+
 // CallToolWithMap executes a tool by name with map arguments (for socket dispatch)
 func (r *Registry) CallToolWithMap(ctx context.Context, name string, args map[string]any) (map[string]any, error) {
 	argsJSON, err := json.Marshal(args)
@@ -207,7 +313,6 @@ func (r *Registry) RegisterWithMCPServer(server *mcp_sdk.Server) {
 
 	for _, name := range r.order {
 		def := r.tools[name]
-		handler := r.handlers[name]
 
 		tool := &mcp_sdk.Tool{
 			Name:        name,
@@ -215,15 +320,15 @@ func (r *Registry) RegisterWithMCPServer(server *mcp_sdk.Server) {
 			InputSchema: def.InputSchema,
 		}
 
-		// Capture handler in closure properly
-		h := handler
+		// Capture name in closure properly; calls go through CallTool so scope rules apply
+		toolName := name
 		sdkHandler := func(ctx context.Context, req *mcp_sdk.CallToolRequest) (*mcp_sdk.CallToolResult, error) {
 			ctx = WithCallToolRequest(ctx, req)
 			var args json.RawMessage
 			if req.Params != nil {
 				args = req.Params.Arguments
 			}
-			result, err := h(ctx, args)
+			result, err := r.CallTool(ctx, toolName, args)
 			if err != nil {
 				return NewErrorResult(err.Error()), nil
 			}
@@ -247,7 +352,7 @@ func wrapHandler[P any](handler func(ctx context.Context, req *mcp_sdk.CallToolR
 		var params P
 		if len(args) > 0 {
 			if err := json.Unmarshal(args, &params); err != nil {
-				return nil, fmt.Errorf("invalid parameters: %w", err)
+				return nil, invalidParams("invalid parameters: %w", err)
 			}
 		}
 
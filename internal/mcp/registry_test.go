@@ -3,8 +3,10 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 
+	"github.com/HyphaGroup/oubliette/internal/auth"
 	mcp_sdk "github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -188,6 +190,12 @@ func TestRegistry_GetToolsForScope(t *testing.T) {
 		t.Errorf("admin:ro should see 1 read tool, got %v", toolNames(readTools))
 	}
 
+	// Tool rules hide tools outside the allowlist
+	ruleTools := r.GetToolsForScope("admin tool:read_tool")
+	if len(ruleTools) != 1 || ruleTools[0].Name != "read_tool" {
+		t.Errorf("tool allowlist should leave 1 tool, got %v", toolNames(ruleTools))
+	}
+
 	// Project scope sees only read global tools (not write/admin globals)
 	projectTools := r.GetToolsForScope("project:abc123")
 	if len(projectTools) != 1 || projectTools[0].Name != "read_tool" {
@@ -209,7 +217,8 @@ func TestRegistry_CallTool(t *testing.T) {
 	Register(r, ToolDef{Name: "greet", Target: TargetGlobal, Access: AccessRead}, handler)
 
 	args, _ := json.Marshal(map[string]string{"name": "World"})
-	result, err := r.CallTool(context.Background(), "greet", args)
+	ctx := auth.WithContext(context.Background(), &auth.AuthContext{Type: auth.AuthTypeToken, Token: &auth.Token{Scope: "admin:ro"}})
+	result, err := r.CallTool(ctx, "greet", args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -234,6 +243,83 @@ func TestRegistry_CallTool_UnknownTool(t *testing.T) {
 	}
 }
 
+func TestRegistry_CallTool_EnforcesToolRules(t *testing.T) {
+	r := NewRegistry()
+
+	type Params struct {
+		Action string `json:"action"`
+	}
+	handler := func(ctx context.Context, req *mcp_sdk.CallToolRequest, params Params) (*mcp_sdk.CallToolResult, any, error) {
+		return NewTextResult("ok"), nil, nil
+	}
+
+	Register(r, ToolDef{Name: "session", Target: TargetProject, Access: AccessWrite, ReadActions: []string{"get"}}, handler)
+	Register(r, ToolDef{Name: "token", Target: TargetGlobal, Access: AccessAdmin}, handler)
+	r.SetSessionProjectResolver(func(sessionID string) string {
+		if sessionID == "sess-a" {
+			return "a"
+		}
+		return ""
+	})
+
+	call := func(scope, action string) error {
+		ctx := auth.WithContext(context.Background(), &auth.AuthContext{Type: auth.AuthTypeToken, Token: &auth.Token{Scope: scope}})
+		args, _ := json.Marshal(map[string]string{"action": action, "project_id": "a"})
+		_, err := r.CallTool(ctx, "session", args)
+		return err
+	}
+
+	if err := call("project:a tool:session:message", "message"); err != nil {
+		t.Errorf("granted action should be allowed: %v", err)
+	}
+	if err := call("project:a tool:session:message", "spawn"); err == nil {
+		t.Error("action outside allowlist should be rejected")
+	}
+	if err := call("project:a tool:session:ro", "get"); err != nil {
+		t.Errorf("read action should be allowed by read-only grant: %v", err)
+	}
+	if err := call("admin deny:session:get", "get"); err == nil {
+		t.Error("denied action should be rejected")
+	}
+	if err := call("project:b", "get"); err == nil || !strings.Contains(err.Error(), "not authorized") {
+		t.Errorf("other project should be rejected, got %v", err)
+	}
+	if err := call("project:a:ro", "spawn"); err == nil || !strings.Contains(err.Error(), "read-only access") {
+		t.Errorf("write action on a read-only project should be rejected, got %v", err)
+	}
+	if err := call("admin:ro", "spawn"); err == nil {
+		t.Error("write action with admin:ro should be rejected")
+	}
+
+	// Calls naming only a session are checked against the session's project
+	sessionCall := func(scope, sessionID string) error {
+		ctx := auth.WithContext(context.Background(), &auth.AuthContext{Type: auth.AuthTypeToken, Token: &auth.Token{Scope: scope}})
+		_, err := r.CallTool(ctx, "session", json.RawMessage(`{"action":"get","session_id":"`+sessionID+`"}`))
+		return err
+	}
+	if err := sessionCall("project:a:ro", "sess-a"); err != nil {
+		t.Errorf("session in the token's project should be allowed: %v", err)
+	}
+	if err := sessionCall("project:b", "sess-a"); err == nil {
+		t.Error("session in another project should be rejected")
+	}
+	if err := sessionCall("project:a", "sess-unknown"); err == nil || !strings.Contains(err.Error(), "admin access required") {
+		t.Errorf("unresolvable session should need admin, got %v", err)
+	}
+
+	// Admin-only tools and unauthenticated calls fail closed
+	if err := func() error {
+		ctx := auth.WithContext(context.Background(), &auth.AuthContext{Type: auth.AuthTypeToken, Token: &auth.Token{Scope: "admin:ro"}})
+		_, err := r.CallTool(ctx, "token", json.RawMessage(`{"action":"list"}`))
+		return err
+	}(); err == nil || !strings.Contains(err.Error(), "admin access required") {
+		t.Errorf("admin tool with admin:ro should be rejected, got %v", err)
+	}
+	if _, err := r.CallTool(context.Background(), "session", json.RawMessage(`{"action":"get","project_id":"a"}`)); err == nil {
+		t.Error("calls without auth context should be rejected")
+	}
+}
+
 func TestRegistry_IsToolAllowed(t *testing.T) {
 	r := NewRegistry()
 
@@ -0,0 +1,385 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/HyphaGroup/oubliette/internal/auth"
+	"github.com/HyphaGroup/oubliette/internal/project"
+	"github.com/HyphaGroup/oubliette/internal/prompt"
+	"github.com/HyphaGroup/oubliette/internal/schedule"
+	"github.com/HyphaGroup/oubliette/internal/session"
+	"github.com/HyphaGroup/oubliette/internal/validation"
+	mcp_sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// restAPIPath is the prefix of the REST API. Every registry tool is served as
+//
+//	POST /api/v1/<tool>/<action>  JSON body with the tool's other parameters
+//	GET  /api/v1/<tool>/<action>  query parameters, for read actions only
+//
+// and tools without actions as /api/v1/<tool>. Calls go through
+// Registry.CallTool, so permissions, audit logging and results match MCP.
+const restAPIPath = "/api/v1/"
+
+// maxRESTBodySize bounds request bodies; message attachments are the largest
+const maxRESTBodySize = 64 << 20
+
+// restAPI returns the handler for the REST API, mounted at restAPIPath
+func (s *Server) restAPI() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(restAPIPath+"{tool}", s.handleREST)
+	mux.HandleFunc(restAPIPath+"{tool}/{action}", s.handleREST)
+	return mux
+}
+
+func (s *Server) handleREST(w http.ResponseWriter, r *http.Request) {
+	def, ok := s.registry.GetTool(r.PathValue("tool"))
+	if !ok {
+		writeRESTError(w, http.StatusNotFound, fmt.Errorf("unknown tool: %s", r.PathValue("tool")))
+		return
+	}
+	action := r.PathValue("action")
+	switch {
+	case len(def.Actions) == 0 && action != "":
+		writeRESTError(w, http.StatusNotFound, fmt.Errorf("%s tool has no actions", def.Name))
+		return
+	case len(def.Actions) > 0 && !slices.Contains(def.Actions, action):
+		writeRESTError(w, http.StatusNotFound, actionError(def.Name, action, def.Actions))
+		return
+	}
+
+	var args map[string]any
+	switch r.Method {
+	case http.MethodPost:
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRESTBodySize))
+		if err != nil {
+			writeRESTError(w, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		args = map[string]any{}
+		if len(strings.TrimSpace(string(body))) > 0 {
+			dec := json.NewDecoder(bytes.NewReader(body))
+			dec.UseNumber() // keep integers exact on the way back to the handler
+			if err := dec.Decode(&args); err != nil {
+				writeRESTError(w, http.StatusBadRequest, fmt.Errorf("request body must be a JSON object: %w", err))
+				return
+			}
+		}
+	case http.MethodGet:
+		if def.ActionAccess(action) != AccessRead {
+			w.Header().Set("Allow", http.MethodPost)
+			writeRESTError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s changes state; use POST", restOperation(def, action)))
+			return
+		}
+		var err error
+		if args, err = restQueryArgs(def, r.URL.Query()); err != nil {
+			writeRESTError(w, http.StatusBadRequest, err)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeRESTError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	if action != "" {
+		if a, ok := args["action"]; ok && a != action {
+			writeRESTError(w, http.StatusBadRequest, fmt.Errorf("action %v in body does not match path action %s", a, action))
+			return
+		}
+		args["action"] = action
+	}
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		writeRESTError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := s.registry.CallTool(r.Context(), def.Name, argsJSON)
+	if err != nil {
+		writeRESTError(w, restErrorStatus(err), err)
+		return
+	}
+	writeRESTResult(w, result)
+}
+
+// restQueryArgs converts query parameters to tool arguments using the types in
+// the tool's input schema
+func restQueryArgs(def *ToolDef, query map[string][]string) (map[string]any, error) {
+	props, _ := def.InputSchema["properties"].(map[string]any)
+	args := map[string]any{}
+	for name, values := range query {
+		prop, ok := props[name].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unknown parameter %q for %s", name, def.Name)
+		}
+		value, err := restQueryValue(prop, values)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %q: %w", name, err)
+		}
+		args[name] = value
+	}
+	return args, nil
+}
+
+func restQueryValue(prop map[string]any, values []string) (any, error) {
+	last := values[len(values)-1]
+	switch prop["type"] {
+	case "integer":
+		return strconv.ParseInt(last, 10, 64)
+	case "number":
+		return strconv.ParseFloat(last, 64)
+	case "boolean":
+		return strconv.ParseBool(last)
+	case "array":
+		items, _ := prop["items"].(map[string]any)
+		var out []any
+		for _, v := range values {
+			for _, part := range strings.Split(v, ",") {
+				item, err := restQueryValue(items, []string{part})
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, item)
+			}
+		}
+		return out, nil
+	case "object":
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(last), &obj); err != nil {
+			return nil, fmt.Errorf("must be a JSON object")
+		}
+		return obj, nil
+	default:
+		return last, nil
+	}
+}
+
+// restErrorStatus maps a tool error to an HTTP status by the sentinel errors
+// it wraps. Errors that match none of them are server failures.
+func restErrorStatus(err error) int {
+	isAny := func(targets ...error) bool {
+		return slices.ContainsFunc(targets, func(target error) bool { return errors.Is(err, target) })
+	}
+	switch {
+	case isAny(auth.ErrQuotaExceeded):
+		return http.StatusTooManyRequests
+	case isAny(ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case isAny(ErrForbidden):
+		return http.StatusForbidden
+	case isAny(ErrNotFound, project.ErrNotFound, session.ErrSessionNotFound,
+		schedule.ErrScheduleNotFound, schedule.ErrTargetNotFound, auth.ErrTokenNotFound):
+		return http.StatusNotFound
+	case isAny(ErrInvalidParams, validation.ErrInvalid, session.ErrInvalidCursor, session.ErrEmptyQuery,
+		schedule.ErrInvalidCron, prompt.ErrInvalidTemplate):
+		return http.StatusBadRequest
+	case isAny(ErrConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeRESTResult writes a tool result as JSON. Structured results are encoded
+// directly; text results are passed through if they are JSON and otherwise
+// wrapped as {"message": ...}.
+func writeRESTResult(w http.ResponseWriter, result any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	ctr, ok := result.(*mcp_sdk.CallToolResult)
+	if !ok {
+		_ = json.NewEncoder(w).Encode(result)
+		return
+	}
+	var text strings.Builder
+	for _, c := range ctr.Content {
+		if tc, ok := c.(*mcp_sdk.TextContent); ok {
+			text.WriteString(tc.Text)
+		}
+	}
+	if trimmed := strings.TrimSpace(text.String()); json.Valid([]byte(trimmed)) && (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) {
+		_, _ = io.WriteString(w, trimmed+"\n")
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": text.String()})
+}
+
+func writeRESTError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// restOperation names a tool action for messages and operation IDs
+func restOperation(def *ToolDef, action string) string {
+	if action == "" {
+		return def.Name
+	}
+	return def.Name + "_" + action
+}
+
+// handleOpenAPI serves the OpenAPI document for the REST API. It describes
+// tools only, so it is served without authentication.
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(OpenAPIDocument(s.registry))
+}
+
+// actionSummaryPattern matches "  <action> — <summary>" lines in tool descriptions
+var actionSummaryPattern = regexp.MustCompile(`(?m)^\s+([a-z_]+)\s+—\s+(.+)$`)
+
+// OpenAPIDocument builds an OpenAPI 3.1 document describing the REST API for
+// every tool in the registry
+func OpenAPIDocument(r *Registry) map[string]any {
+	errorResponse := map[string]any{
+		"description": "Error",
+		"content": map[string]any{"application/json": map[string]any{
+			"schema": map[string]any{"$ref": "#/components/schemas/Error"},
+		}},
+	}
+	paths := map[string]any{}
+
+	for _, def := range r.GetAllTools() {
+		summaries := map[string]string{}
+		for _, m := range actionSummaryPattern.FindAllStringSubmatch(def.Description, -1) {
+			if _, ok := summaries[m[1]]; !ok {
+				summaries[m[1]] = strings.TrimSpace(m[2])
+			}
+		}
+		description, _, _ := strings.Cut(def.Description, "\n")
+
+		actions := def.Actions
+		if len(actions) == 0 {
+			actions = []string{""}
+		}
+		bodySchema := openAPIBodySchema(def.InputSchema)
+		for _, action := range actions {
+			summary := summaries[action]
+			if summary == "" {
+				summary = description
+			}
+			op := func(method string) map[string]any {
+				return map[string]any{
+					"operationId": restOperation(def, action) + "_" + method,
+					"tags":        []string{def.Name},
+					"summary":     summary,
+					"description": def.Description,
+					"responses": map[string]any{
+						"200": map[string]any{
+							"description": "Tool result",
+							"content":     map[string]any{"application/json": map[string]any{"schema": map[string]any{}}},
+						},
+						"400":     errorResponse,
+						"401":     errorResponse,
+						"403":     errorResponse,
+						"404":     errorResponse,
+						"429":     errorResponse,
+						"default": errorResponse,
+					},
+				}
+			}
+
+			item := map[string]any{}
+			post := op("post")
+			post["requestBody"] = map[string]any{
+				"content": map[string]any{"application/json": map[string]any{"schema": bodySchema}},
+			}
+			item["post"] = post
+			if def.ActionAccess(action) == AccessRead {
+				get := op("get")
+				get["parameters"] = openAPIQueryParameters(bodySchema)
+				item["get"] = get
+			}
+
+			path := "/" + def.Name
+			if action != "" {
+				path += "/" + action
+			}
+			paths[path] = item
+		}
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "Oubliette API",
+			"version":     "v1",
+			"description": "REST mirror of the Oubliette MCP tools. Each tool action is a path; read actions also accept GET with query parameters.",
+		},
+		"servers":  []map[string]any{{"url": strings.TrimSuffix(restAPIPath, "/")}},
+		"security": []map[string]any{{"bearer": []string{}}},
+		"paths":    paths,
+		"components": map[string]any{
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer", "description": "Oubliette API token or OIDC access token"},
+			},
+			"schemas": map[string]any{
+				"Error": map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"error": map[string]any{"type": "string"}},
+					"required":   []string{"error"},
+				},
+			},
+		},
+	}
+}
+
+// openAPIBodySchema returns the tool input schema without the action
+// parameter, which the path supplies
+func openAPIBodySchema(schema map[string]any) map[string]any {
+	props, _ := schema["properties"].(map[string]any)
+	out := map[string]any{"type": "object"}
+	bodyProps := map[string]any{}
+	for name, prop := range props {
+		if name != "action" {
+			bodyProps[name] = prop
+		}
+	}
+	out["properties"] = bodyProps
+	if required, ok := schema["required"].([]string); ok {
+		var kept []string
+		for _, name := range required {
+			if name != "action" {
+				kept = append(kept, name)
+			}
+		}
+		if len(kept) > 0 {
+			out["required"] = kept
+		}
+	}
+	return out
+}
+
+// openAPIQueryParameters describes the body properties as query parameters
+func openAPIQueryParameters(bodySchema map[string]any) []map[string]any {
+	props, _ := bodySchema["properties"].(map[string]any)
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	params := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		param := map[string]any{"name": name, "in": "query", "schema": props[name]}
+		if prop, _ := props[name].(map[string]any); prop["type"] == "object" {
+			param["description"] = "JSON-encoded object"
+		}
+		params = append(params, param)
+	}
+	return params
+}
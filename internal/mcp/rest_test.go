@@ -0,0 +1,212 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HyphaGroup/oubliette/internal/auth"
+	"github.com/HyphaGroup/oubliette/internal/schedule"
+	"github.com/HyphaGroup/oubliette/internal/session"
+)
+
+// newRESTTestServer serves the REST API over the dashboard test fixtures
+func newRESTTestServer(t *testing.T) (*httptest.Server, *Server, string) {
+	t.Helper()
+	_, s, projectID := newDashboardTestServer(t)
+	api := s.restAPI()
+	srv := serveWithScope(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == restAPIPath+"openapi.json" {
+			s.handleOpenAPI(w, r)
+			return
+		}
+		api.ServeHTTP(w, r)
+	}))
+	return srv, s, projectID
+}
+
+// restRequest calls the REST API and returns the status and decoded JSON body
+func restRequest(t *testing.T, srv *httptest.Server, scope, method, path, body string) (int, map[string]any) {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+restAPIPath+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+scope)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, _ := io.ReadAll(resp.Body)
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("%s %s: invalid JSON %q: %v", method, path, data, err)
+	}
+	return resp.StatusCode, out
+}
+
+func TestRESTCalls(t *testing.T) {
+	srv, _, projectID := newRESTTestServer(t)
+	projectRO := "project:" + projectID + ":ro"
+
+	tests := []struct {
+		name     string
+		scope    string
+		method   string
+		path     string
+		body     string
+		status   int
+		contains string
+	}{
+		{"post read action", projectRO, "POST", "session/get", `{"session_id": "` + dashboardRootSession + `"}`, http.StatusOK, "review the payments code"},
+		{"get read action", projectRO, "GET", "session/list?project_id=" + projectID + "&status=completed", "", http.StatusOK, dashboardRootSession},
+		{"list by parent", projectRO, "GET", "session/list?project_id=" + projectID + "&parent_session_id=" + dashboardRootSession + "&limit=1", "", http.StatusOK, dashboardChildSession},
+		{"list invalid since", projectRO, "GET", "session/list?project_id=" + projectID + "&since=yesterday", "", http.StatusBadRequest, "expected RFC 3339"},
+		{"list invalid cursor", projectRO, "GET", "session/list?project_id=" + projectID + "&cursor=bogus", "", http.StatusBadRequest, "invalid cursor"},
+		{"search", projectRO, "GET", "session/search?project_id=" + projectID + "&query=payments", "", http.StatusOK, dashboardRootSession + " turn 1 (prompt)"},
+		{"search all projects", projectRO, "GET", "session/search?query=payments", "", http.StatusForbidden, "admin access required"},
+		{"search without query", "admin", "GET", "session/search?project_id=" + projectID, "", http.StatusBadRequest, "query is required"},
+		{"export", projectRO, "GET", "session/export?session_id=" + dashboardRootSession + "&format=html", "", http.StatusOK, "review the payments code"},
+		{"export other project", "project:00000000-0000-0000-0000-000000000000", "POST", "session/export", `{"session_id": "` + dashboardRootSession + `"}`, http.StatusForbidden, "not authorized"},
+		{"export unknown format", "admin", "GET", "session/export?session_id=" + dashboardRootSession + "&format=pdf", "", http.StatusBadRequest, "unknown format"},
+		{"fork read-only", projectRO, "POST", "session/fork", `{"session_id": "` + dashboardRootSession + `", "turn": 1}`, http.StatusForbidden, "read-only access"},
+		{"fork without turn", "admin", "POST", "session/fork", `{"session_id": "` + dashboardRootSession + `"}`, http.StatusBadRequest, "turn is required"},
+		{"fork turn out of range", "admin", "POST", "session/fork", `{"session_id": "` + dashboardRootSession + `", "turn": 2}`, http.StatusBadRequest, "has 1 turns"},
+		{"typed query parameter", "admin", "GET", "session/events?session_id=" + dashboardRootSession + "&since_index=x", "", http.StatusBadRequest, "since_index"},
+		{"unknown query parameter", "admin", "GET", "session/list?bogus=1", "", http.StatusBadRequest, "unknown parameter"},
+		{"get write action", "admin", "GET", "session/end?session_id=" + dashboardRootSession, "", http.StatusMethodNotAllowed, "use POST"},
+		{"body action mismatch", "admin", "POST", "session/get", `{"action": "end", "session_id": "x"}`, http.StatusBadRequest, "does not match"},
+		{"body not an object", "admin", "POST", "session/get", `[1]`, http.StatusBadRequest, "JSON object"},
+		{"unknown tool", "admin", "POST", "nope/list", "", http.StatusNotFound, "unknown tool"},
+		{"unknown action", "admin", "POST", "session/explode", "", http.StatusNotFound, "valid actions"},
+		{"tool without actions", projectRO, "GET", "config_limits?project_id=" + projectID, "", http.StatusOK, "Max Depth"},
+		{"other project", "project:00000000-0000-0000-0000-000000000000", "GET", "session/list?project_id=" + projectID, "", http.StatusForbidden, "not authorized"},
+		{"read-only write", projectRO, "POST", "workspace/delete", `{"project_id": "` + projectID + `", "workspace_id": "550e8400-e29b-41d4-a716-446655440001"}`, http.StatusForbidden, ""},
+		{"container exec other project", "project:00000000-0000-0000-0000-000000000000", "POST", "container/exec", `{"project_id": "` + projectID + `", "command": "true"}`, http.StatusForbidden, "not authorized"},
+		{"container exec read-only", projectRO, "POST", "container/exec", `{"project_id": "` + projectID + `", "command": "true"}`, http.StatusForbidden, "read-only access"},
+		{"container stop other project", "project:00000000-0000-0000-0000-000000000000", "POST", "container/stop", `{"project_id": "` + projectID + `"}`, http.StatusForbidden, "not authorized"},
+		{"container stop read-only", projectRO, "POST", "container/stop", `{"project_id": "` + projectID + `"}`, http.StatusForbidden, "read-only access"},
+		{"container logs other project", "project:00000000-0000-0000-0000-000000000000", "GET", "container/logs?project_id=" + projectID, "", http.StatusForbidden, "not authorized"},
+		{"container logs read-only", projectRO, "GET", "container/logs?project_id=" + projectID, "", http.StatusOK, ""},
+		{"admin tool", projectRO, "POST", "token/list", "", http.StatusForbidden, "admin access required"},
+		{"tool rules", "admin tool:session:ro", "POST", "token/list", "", http.StatusForbidden, "does not permit"},
+		{"not found", "admin", "POST", "schedule/get", `{"schedule_id": "sched_missing"}`, http.StatusNotFound, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, out := restRequest(t, srv, tt.scope, tt.method, tt.path, tt.body)
+			if status != tt.status {
+				t.Fatalf("%s %s status = %d, want %d (%v)", tt.method, tt.path, status, tt.status, out)
+			}
+			raw, _ := json.Marshal(out)
+			if !strings.Contains(string(raw), tt.contains) {
+				t.Errorf("%s %s body = %s, want %q", tt.method, tt.path, raw, tt.contains)
+			}
+		})
+	}
+}
+
+func TestRESTResults(t *testing.T) {
+	srv, _, projectID := newRESTTestServer(t)
+
+	// Structured results are returned as JSON
+	body := `{"name": "nightly", "cron_expr": "0 2 * * *", "prompt": "run the tests", "targets": [{"project_id": "` + projectID + `"}]}`
+	status, sched := restRequest(t, srv, "admin", "POST", "schedule/create", body)
+	if status != http.StatusOK || sched["name"] != "nightly" {
+		t.Fatalf("create status = %d, result = %v", status, sched)
+	}
+	if _, leaked := sched["creator_token_id"]; leaked {
+		t.Errorf("schedule result exposes the creator token: %v", sched)
+	}
+	status, got := restRequest(t, srv, "project:"+projectID+":ro", "GET", "schedule/get?schedule_id="+sched["id"].(string), "")
+	if status != http.StatusOK || got["id"] != sched["id"] {
+		t.Errorf("get status = %d, result = %v", status, got)
+	}
+
+	// JSON text results are passed through
+	status, exported := restRequest(t, srv, "admin", "GET", "session/export?format=json&session_id="+dashboardRootSession, "")
+	if children, _ := exported["children"].([]any); status != http.StatusOK || exported["session_id"] != dashboardRootSession || len(children) != 1 {
+		t.Errorf("export status = %d, result = %v", status, exported)
+	}
+
+	// Text results are wrapped in a message
+	status, created := restRequest(t, srv, "admin", "POST", "token/create", `{"name": "ci", "scope": "admin:ro"}`)
+	if message, _ := created["message"].(string); status != http.StatusOK || !strings.Contains(message, "Token ID: oub_") {
+		t.Errorf("token create status = %d, result = %v", status, created)
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	srv, s, _ := newRESTTestServer(t)
+
+	resp, err := http.Get(srv.URL + restAPIPath + "openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var doc struct {
+		OpenAPI string                               `json:"openapi"`
+		Paths   map[string]map[string]map[string]any `json:"paths"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("openapi = %q", doc.OpenAPI)
+	}
+
+	for _, def := range s.registry.GetAllTools() {
+		for _, action := range def.Actions {
+			item, ok := doc.Paths["/"+def.Name+"/"+action]
+			if !ok {
+				t.Errorf("no path for %s %s", def.Name, action)
+				continue
+			}
+			if _, hasGet := item["get"]; hasGet != (def.ActionAccess(action) == AccessRead) {
+				t.Errorf("%s %s: GET present = %v, read action = %v", def.Name, action, hasGet, def.ActionAccess(action) == AccessRead)
+			}
+		}
+	}
+
+	post := doc.Paths["/session/message"]["post"]
+	if post["summary"] != "Send a task to an agent. Auto-creates session if none active. Most common action." {
+		t.Errorf("session message summary = %v", post["summary"])
+	}
+	body, _ := json.Marshal(post["requestBody"])
+	if !strings.Contains(string(body), `"attachments"`) || strings.Contains(string(body), `"action"`) {
+		t.Errorf("session message body schema = %s, want the tool parameters without action", body)
+	}
+	if _, ok := doc.Paths["/config_limits"]["get"]; !ok {
+		t.Error("no GET for the read-only config_limits tool")
+	}
+}
+
+func TestRESTErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrUnauthenticated, http.StatusUnauthorized},
+		{forbidden("read-only access"), http.StatusForbidden},
+		{notFound("workspace %s not found", "ws"), http.StatusNotFound},
+		{fmt.Errorf("failed to load session: %w", session.ErrSessionNotFound), http.StatusNotFound},
+		{invalidParams("turn is required"), http.StatusBadRequest},
+		{fmt.Errorf("failed to create schedule: %w", schedule.ErrInvalidCron), http.StatusBadRequest},
+		{conflict("container not running"), http.StatusConflict},
+		{fmt.Errorf("failed to check quota: %w", auth.ErrQuotaExceeded), http.StatusTooManyRequests},
+		// Unclassified failures are server errors, whatever their wording
+		{errors.New("docker: image not found"), http.StatusInternalServerError},
+		{errors.New("failed to write session: disk full"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := restErrorStatus(tt.err); got != tt.want {
+			t.Errorf("restErrorStatus(%q) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
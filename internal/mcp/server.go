@@ -5,17 +5,22 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/HyphaGroup/oubliette/internal/agent"
+	"github.com/HyphaGroup/oubliette/internal/audit"
 	"github.com/HyphaGroup/oubliette/internal/auth"
 	"github.com/HyphaGroup/oubliette/internal/config"
 	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/dashboard"
 	"github.com/HyphaGroup/oubliette/internal/logger"
 	"github.com/HyphaGroup/oubliette/internal/metrics"
 	"github.com/HyphaGroup/oubliette/internal/project"
 	"github.com/HyphaGroup/oubliette/internal/schedule"
 	"github.com/HyphaGroup/oubliette/internal/session"
+	"github.com/HyphaGroup/oubliette/internal/terminal"
+	"github.com/HyphaGroup/oubliette/internal/tracing"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -35,15 +40,27 @@ type Server struct {
 	sessionMgr      *session.Manager
 	activeSessions  *session.ActiveSessionManager
 	authStore       *auth.Store
+	oidcAuth        *auth.OIDCAuthenticator // Optional JWT bearer authentication
+	auditStore      *audit.Store            // Optional durable audit log for the audit tool
 	socketHandler   *SocketHandler
-	mcpServer       *mcp.Server                // The underlying MCP server for handling requests
-	registry        *Registry                  // Tool registry for unified tool management
-	containerMemory string                     // Container memory limit (e.g., "4G")
-	containerCPUs   int                        // Container CPU limit
-	credentials     *config.CredentialRegistry // Unified credential registry
-	modelRegistry   *config.ModelRegistry      // Model configuration registry
-	scheduleStore   *schedule.Store            // Schedule persistence
-	scheduleRunner  *schedule.Runner           // Schedule execution runner
+	mcpServer       *mcp.Server      // The underlying MCP server for handling requests
+	registry        *Registry        // Tool registry for unified tool management
+	containerMemory string           // Container memory limit (e.g., "4G")
+	containerCPUs   int              // Container CPU limit
+	scheduleStore   *schedule.Store  // Schedule persistence
+	scheduleRunner  *schedule.Runner // Schedule execution runner
+	pool            *container.Pool  // Optional warm container pool
+	poolWarmer      *poolWarmer
+	idle            *idleStopper // Optional idle container auto-stop
+	terminals       *terminalRegistry
+	dataDir         string // Data directory checked by the doctor tool and holding terminal recordings
+
+	// Reloadable configuration; read through credentialRegistry() and models()
+	configMu      sync.RWMutex
+	reloadMu      sync.Mutex                 // Serializes config reloads
+	config        *config.LoadedConfig       // Config in effect, if loaded from a file
+	credentials   *config.CredentialRegistry // Unified credential registry
+	modelRegistry *config.ModelRegistry      // Model configuration registry
 }
 
 // ServerConfig holds container resource configuration
@@ -55,6 +72,13 @@ type ServerConfig struct {
 	ImageManager    *container.ImageManager
 	AgentRuntime    agent.Runtime
 	ScheduleStore   *schedule.Store
+	OIDC            *auth.OIDCAuthenticator // Optional; accepts OIDC JWTs alongside API tokens
+	AuditStore      *audit.Store            // Optional; enables the audit query action
+	PoolSizes       map[string]int          // Optional; warm containers per container type
+	PoolDir         string                  // Slot directories for pool containers (same filesystem as projects)
+	IdleTimeout     time.Duration           // Optional; stop containers with no session activity for this long
+	Config          *config.LoadedConfig    // Optional; the loaded config, enabling config reload
+	DataDir         string                  // Optional; the data directory, enabling the doctor tool and terminal recording
 }
 
 // NewServer creates a new MCP server instance
@@ -66,6 +90,13 @@ func NewServer(projectMgr *project.Manager, runtime container.Runtime, sessionMg
 	var imageMgr *container.ImageManager
 	var agentRt agent.Runtime
 	var schedStore *schedule.Store
+	var oidcAuth *auth.OIDCAuthenticator
+	var auditStore *audit.Store
+	var poolSizes map[string]int
+	var poolDir string
+	var idleTimeout time.Duration
+	var loaded *config.LoadedConfig
+	var dataDir string
 	if cfg != nil {
 		if cfg.ContainerMemory != "" {
 			memory = cfg.ContainerMemory
@@ -78,6 +109,13 @@ func NewServer(projectMgr *project.Manager, runtime container.Runtime, sessionMg
 		imageMgr = cfg.ImageManager
 		agentRt = cfg.AgentRuntime
 		schedStore = cfg.ScheduleStore
+		oidcAuth = cfg.OIDC
+		auditStore = cfg.AuditStore
+		poolSizes = cfg.PoolSizes
+		poolDir = cfg.PoolDir
+		idleTimeout = cfg.IdleTimeout
+		loaded = cfg.Config
+		dataDir = cfg.DataDir
 	}
 
 	s := &Server{
@@ -88,19 +126,67 @@ func NewServer(projectMgr *project.Manager, runtime container.Runtime, sessionMg
 		sessionMgr:      sessionMgr,
 		activeSessions:  session.NewActiveSessionManager(session.DefaultMaxActiveSessions, session.DefaultSessionIdleTimeout),
 		authStore:       authStore,
+		oidcAuth:        oidcAuth,
+		auditStore:      auditStore,
 		registry:        NewRegistry(),
 		containerMemory: memory,
 		containerCPUs:   cpus,
+		scheduleStore:   schedStore,
+		terminals:       newTerminalRegistry(),
+		dataDir:         dataDir,
+		config:          loaded,
 		credentials:     credentials,
 		modelRegistry:   modelRegistry,
-		scheduleStore:   schedStore,
 	}
 
 	// Initialize schedule runner if store is provided
 	if schedStore != nil {
 		s.scheduleRunner = schedule.NewRunner(schedStore, s.executeScheduleTarget)
 	}
+	// Initialize warm container pool if any type has a size
+	if len(poolSizes) > 0 && poolDir != "" {
+		s.poolWarmer = &poolWarmer{s: s, dir: poolDir}
+		s.pool = container.NewPool(s.poolWarmer, poolSizes)
+	}
+	if idleTimeout > 0 {
+		s.idle = newIdleStopper(s, idleTimeout)
+	}
 	s.socketHandler = NewSocketHandler(s)
+	s.activeSessions.SetUsageFunc(func(sess *session.ActiveSession, inputTokens, outputTokens int) {
+		s.recordModelTokens(sess.OwnerTokenID, inputTokens, outputTokens)
+	})
+	s.activeSessions.SetEventFunc(func(sess *session.ActiveSession, event *session.BufferedEvent) {
+		if err := s.sessionMgr.AppendEvent(sess.ProjectID, sess.SessionID, event); err != nil {
+			logger.Error("Failed to persist event for session %s: %v", sess.SessionID, err)
+		}
+	})
+	s.activeSessions.SetEndFunc(func(sess *session.ActiveSession, status session.ActiveStatus) {
+		details := map[string]interface{}{"status": string(status)}
+		if err := sess.GetError(); err != nil {
+			details["error"] = err.Error()
+		}
+		audit.Log(&audit.Event{
+			Operation:   audit.OpSessionEnd,
+			TokenID:     sess.OwnerTokenID,
+			ProjectID:   sess.ProjectID,
+			SessionID:   sess.SessionID,
+			WorkspaceID: sess.WorkspaceID,
+			Success:     true,
+			Details:     details,
+		})
+	})
+	s.activeSessions.SetFallbackFunc(func(model string) []string {
+		registry := s.models()
+		if registry == nil {
+			return nil
+		}
+		return registry.FallbackModels(model)
+	})
+	s.activeSessions.SetAttemptFunc(func(sess *session.ActiveSession, attempt session.TurnAttempt) {
+		if err := s.sessionMgr.RecordAttempt(sess.SessionID, attempt); err != nil {
+			logger.Error("Failed to record provider error for session %s: %v", sess.SessionID, err)
+		}
+	})
 
 	// Register all tools with the registry
 	s.registerAllTools(s.registry)
@@ -129,18 +215,46 @@ func (s *Server) GetSocketHandler() *SocketHandler {
 	return s.socketHandler
 }
 
+// sessionProjectID returns the project of an active or stored session, or ""
+func (s *Server) sessionProjectID(sessionID string) string {
+	if sess, ok := s.activeSessions.Get(sessionID); ok {
+		return sess.ProjectID
+	}
+	if s.sessionMgr != nil {
+		if sess, err := s.sessionMgr.Load(sessionID); err == nil {
+			return sess.ProjectID
+		}
+	}
+	return ""
+}
+
 // HasAPICredentials returns true if any API credentials are configured
 // (provider credentials for Anthropic, OpenAI, etc.)
 func (s *Server) HasAPICredentials() bool {
-	if s.credentials == nil {
+	credentials := s.credentialRegistry()
+	if credentials == nil {
 		return false
 	}
-	if cred, ok := s.credentials.GetDefaultProviderCredential(); ok && cred.APIKey != "" {
+	if cred, ok := credentials.GetDefaultProviderCredential(); ok && cred.APIKey != "" {
 		return true
 	}
 	return false
 }
 
+// credentialRegistry returns the credentials in effect
+func (s *Server) credentialRegistry() *config.CredentialRegistry {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.credentials
+}
+
+// models returns the model registry in effect
+func (s *Server) models() *config.ModelRegistry {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.modelRegistry
+}
+
 // Close shuts down the server and cleans up resources
 func (s *Server) Close() {
 	// Stop schedule runner first (waits for in-flight)
@@ -148,9 +262,21 @@ func (s *Server) Close() {
 		s.scheduleRunner.Stop()
 	}
 
+	// Stop idle checks before sessions go away
+	if s.idle != nil {
+		s.idle.Stop()
+	}
+
 	// Close all active sessions
 	s.activeSessions.Close()
 
+	// Remove idle warm containers
+	if s.pool != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		s.pool.Stop(ctx)
+		cancel()
+	}
+
 	// Close socket handler
 	s.socketHandler.Close()
 }
@@ -162,6 +288,16 @@ func (s *Server) Serve(addr string) error {
 		s.scheduleRunner.Start()
 	}
 
+	// Start filling the warm container pool
+	if s.pool != nil {
+		s.pool.Start()
+	}
+
+	// Start stopping idle containers
+	if s.idle != nil {
+		s.idle.Start()
+	}
+
 	// Create MCP server (store for socket connections too)
 	s.mcpServer = mcp.NewServer(&mcp.Implementation{
 		Name:    "oubliette",
@@ -202,12 +338,26 @@ func (s *Server) Serve(addr string) error {
 		mcpHandler.ServeHTTP(w, r)
 	})
 
-	// Wrap with auth middleware (Bearer token only, socket auth is separate)
-	authedHandler := auth.Middleware(s.authStore)(loggingHandler)
-
-	// Wrap with rate limiting (after auth, so we can rate limit per-token)
+	// Wrap with auth middleware (Bearer API tokens, then OIDC JWTs if configured; socket auth is separate)
+	var authenticator auth.Authenticator = auth.NewTokenAuthenticator(s.authStore)
+	if s.oidcAuth != nil {
+		authenticator = auth.NewChain(authenticator, s.oidcAuth)
+	}
+	// Every authenticated route gets the same chain: a per-IP limit in front of
+	// auth (so bad credentials are limited too), then per-token rate limiting
+	// and usage accounting; tokens with a request quota get their own rate.
+	ipLimiter := auth.DefaultIPRateLimiter() // 30 req/s, burst 60
 	rateLimiter := auth.DefaultRateLimiter() // 10 req/s, burst 20
-	rateLimitedHandler := auth.RateLimitMiddleware(rateLimiter)(authedHandler)
+	go func() {
+		for range time.Tick(time.Hour) {
+			ipLimiter.Cleanup(time.Hour)
+			rateLimiter.Cleanup(time.Hour)
+		}
+	}()
+	protect := func(h http.Handler) http.Handler {
+		return auth.IPRateLimitMiddleware(ipLimiter)(auth.AuthenticatorMiddleware(authenticator)(
+			auth.RateLimitMiddleware(rateLimiter)(auth.UsageMiddleware(s.authStore)(h))))
+	}
 
 	// Create main mux with health endpoints (no auth required) and MCP endpoints
 	mainMux := http.NewServeMux()
@@ -220,14 +370,31 @@ func (s *Server) Serve(addr string) error {
 	mainMux.Handle("/metrics", metrics.Handler())
 
 	// MCP endpoints - require authentication, rate limiting, wrapped with metrics middleware
-	mainMux.Handle("/mcp", metrics.Middleware(rateLimitedHandler))
-	mainMux.Handle("/mcp/", metrics.Middleware(rateLimitedHandler))
+	mcpProtected := metrics.Middleware(protect(loggingHandler))
+	mainMux.Handle("/mcp", mcpProtected)
+	mainMux.Handle("/mcp/", mcpProtected)
+
+	// Terminal WebSockets - require authentication; not wrapped in metrics, whose
+	// response writer can't be hijacked for the upgrade
+	mainMux.Handle("GET "+terminal.Path+"{project_id}", protect(http.HandlerFunc(s.handleTerminal)))
+
+	// Web dashboard - the static UI holds no data; its API requires authentication
+	mainMux.Handle(dashboard.Path, dashboard.Handler())
+	mainMux.Handle(dashboardAPIPath, metrics.Middleware(protect(s.dashboardAPI())))
+	mainMux.Handle("GET /{$}", http.RedirectHandler(dashboard.Path, http.StatusFound))
+
+	// REST API - the same tools as MCP behind the same middleware
+	mainMux.HandleFunc("GET "+restAPIPath+"openapi.json", s.handleOpenAPI)
+	mainMux.Handle(restAPIPath, metrics.Middleware(protect(s.restAPI())))
 
 	logger.Info("🚀 Oubliette MCP server listening on %s", addr)
 	logger.Info("🔌 Relay sockets directory: %s", SocketsBaseDir)
 	logger.Info("💚 Health check: http://localhost%s/health", addr)
 	logger.Info("💚 Readiness check: http://localhost%s/ready", addr)
 	logger.Info("📊 Metrics: http://localhost%s/metrics", addr)
+	logger.Info("🖥️  Terminals: ws://localhost%s%s<project_id>", addr, terminal.Path)
+	logger.Info("🧭 Dashboard: http://localhost%s%s", addr, dashboard.Path)
+	logger.Info("🔗 REST API: http://localhost%s%s (spec: %sopenapi.json)", addr, restAPIPath, restAPIPath)
 	return http.ListenAndServe(addr, mainMux)
 }
 
@@ -269,6 +436,8 @@ type ScheduleExecutionResult struct {
 func (s *Server) executeScheduleTarget(ctx context.Context, sched *schedule.Schedule, target *schedule.ScheduleTarget) ([]string, error) {
 	startTime := time.Now()
 
+	// Run with the creator's credentials so scope and quota checks apply
+	ctx = s.scheduleAuthContext(ctx, sched.CreatorTokenID, sched.CreatorScope)
 	result, err := s.doExecuteScheduleTarget(ctx, sched, target)
 
 	// Record execution in history
@@ -293,6 +462,7 @@ func (s *Server) executeScheduleTarget(ctx context.Context, sched *schedule.Sche
 		}
 	}
 
+	metrics.RecordScheduleExecution(string(exec.Status))
 	if recordErr := s.scheduleStore.RecordExecution(exec); recordErr != nil {
 		logger.Error("Failed to record execution: %v", recordErr)
 	}
@@ -315,6 +485,16 @@ func (s *Server) doExecuteScheduleTarget(ctx context.Context, sched *schedule.Sc
 		workspaceID = proj.DefaultWorkspaceID
 	}
 
+	if err := s.checkModelTokenQuota(auth.FromContext(ctx)); err != nil {
+		return nil, err
+	}
+
+	// Render the prompt template against this target's context
+	prompt, err := s.renderSchedulePrompt(sched, target, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
 	// For new session behavior, clear pinned session
 	if sched.SessionBehavior == schedule.SessionNew {
 		if activeSess, ok := s.activeSessions.GetByWorkspace(target.ProjectID, workspaceID); ok {
@@ -328,9 +508,12 @@ func (s *Server) doExecuteScheduleTarget(ctx context.Context, sched *schedule.Sc
 	if target.SessionID != "" {
 		// Check if session is active
 		if activeSess, ok := s.activeSessions.Get(target.SessionID); ok && activeSess.IsRunning() {
-			if err := activeSess.SendMessage(sched.Prompt); err != nil {
+			if err := activeSess.SendMessage(prompt); err != nil {
 				return nil, err
 			}
+			if err := s.sessionMgr.AddTurn(target.SessionID, prompt); err != nil {
+				logger.Error("Failed to record turn for session %s: %v", target.SessionID, err)
+			}
 			output := s.waitForSessionOutput(activeSess, target.SessionID)
 			return &ScheduleExecutionResult{SessionID: target.SessionID, Output: output}, nil
 		}
@@ -348,16 +531,25 @@ func (s *Server) doExecuteScheduleTarget(ctx context.Context, sched *schedule.Sc
 					RuntimeOverride:    s.agentRuntime,
 				}
 
-				resumedSess, executor, resumeErr := s.sessionMgr.ResumeBidirectionalSession(ctx, existingSession, env.containerName, sched.Prompt, opts)
+				resumedSess, executor, resumeErr := s.sessionMgr.ResumeBidirectionalSession(ctx, existingSession, env.containerName, prompt, opts)
 				if resumeErr == nil {
 					activeSess := session.NewActiveSession(resumedSess.SessionID, target.ProjectID, workspaceID, env.containerName, executor)
+					activeSess.Model = resumedSess.Model
+					s.setSessionOwner(ctx, activeSess)
 					if regErr := s.activeSessions.Register(activeSess); regErr != nil {
 						_ = executor.Close()
 						logger.Info("Failed to register resumed session: %v", regErr)
 					} else {
+						logAudit(ctx, &audit.Event{
+							Operation:   audit.OpSessionSpawn,
+							ProjectID:   target.ProjectID,
+							SessionID:   resumedSess.SessionID,
+							WorkspaceID: workspaceID,
+							Details:     map[string]interface{}{"resumed": true, "schedule_id": sched.ID},
+						}, nil)
 						// Connect to relay in background
 						go func() {
-							if err := s.socketHandler.ConnectSession(context.Background(), target.ProjectID, resumedSess.SessionID, 0); err != nil {
+							if err := s.socketHandler.ConnectSession(tracing.Detach(ctx), target.ProjectID, resumedSess.SessionID, 0); err != nil {
 								logger.Error("Failed to connect to relay for resumed session %s: %v", resumedSess.SessionID, err)
 							}
 							if activeSess, ok := s.activeSessions.Get(resumedSess.SessionID); ok && activeSess.IsRunning() {
@@ -388,7 +580,7 @@ func (s *Server) doExecuteScheduleTarget(ctx context.Context, sched *schedule.Sc
 		RuntimeOverride:    s.agentRuntime,
 	}
 
-	sess, activeSess, err := s.spawnAndRegisterSession(ctx, target.ProjectID, env.containerName, env.workspaceID, sched.Prompt, opts, nil)
+	sess, activeSess, err := s.spawnAndRegisterSession(ctx, target.ProjectID, env.containerName, env.workspaceID, prompt, opts, nil)
 	if err != nil {
 		return nil, err
 	}
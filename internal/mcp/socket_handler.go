@@ -22,9 +22,12 @@ import (
 
 	"github.com/HyphaGroup/oubliette/internal/agent"
 	"github.com/HyphaGroup/oubliette/internal/logger"
+	"github.com/HyphaGroup/oubliette/internal/metrics"
 	"github.com/HyphaGroup/oubliette/internal/session"
+	"github.com/HyphaGroup/oubliette/internal/tracing"
 	"github.com/google/uuid"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // childSession tracks an async child session execution
@@ -39,6 +42,8 @@ type childSession struct {
 	WorkspaceID string
 	ContainerID string
 	ProjectID   string
+	// OwnerTokenID is the token that owns the root session; children are billed to it
+	OwnerTokenID string
 }
 
 // SocketHandler manages upstream connections to container relay sockets
@@ -83,8 +88,7 @@ func (h *SocketHandler) ConnectSession(ctx context.Context, projectID, sessionID
 	}()
 
 	// Send upstream header
-	header := fmt.Sprintf("OUBLIETTE-UPSTREAM %s %s %d\n", sessionID, projectID, depth)
-	if _, err := conn.Write([]byte(header)); err != nil {
+	if _, err := conn.Write([]byte(upstreamHeader(ctx, sessionID, projectID, depth))); err != nil {
 		_ = conn.Close()
 		return fmt.Errorf("failed to send header: %w", err)
 	}
@@ -109,6 +113,16 @@ func (h *SocketHandler) ConnectSession(ctx context.Context, projectID, sessionID
 	return nil
 }
 
+// upstreamHeader builds the relay handshake line. The optional trailing field is
+// the W3C traceparent of ctx, so requests from the session join the caller's trace.
+func upstreamHeader(ctx context.Context, sessionID, projectID string, depth int) string {
+	header := fmt.Sprintf("OUBLIETTE-UPSTREAM %s %s %d", sessionID, projectID, depth)
+	if tp := tracing.TraceParent(ctx); tp != "" {
+		header += " " + tp
+	}
+	return header + "\n"
+}
+
 // CloseSession closes the connection for a session
 func (h *SocketHandler) CloseSession(sessionID string) {
 	h.mu.Lock()
@@ -229,6 +243,22 @@ type JSONRPCError struct {
 }
 
 func (h *SocketHandler) processRequest(ctx context.Context, req *JSONRPCRequest, sessionID, projectID string, depth int) *JSONRPCResponse {
+	ctx, span := tracing.Start(ctx, "socket "+req.Method,
+		attribute.String("oubliette.session_id", sessionID),
+		attribute.String("oubliette.project_id", projectID),
+		attribute.Int("oubliette.depth", depth),
+	)
+
+	response := h.dispatchRequest(ctx, req, sessionID, projectID, depth)
+	var err error
+	if response.Error != nil {
+		err = fmt.Errorf("%s", response.Error.Message)
+	}
+	tracing.End(span, err)
+	return response
+}
+
+func (h *SocketHandler) dispatchRequest(ctx context.Context, req *JSONRPCRequest, sessionID, projectID string, depth int) *JSONRPCResponse {
 	switch req.Method {
 	case "session_message":
 		return h.handleSessionMessage(ctx, req, sessionID, projectID, depth)
@@ -286,12 +316,13 @@ func (h *SocketHandler) handleSessionMessage(ctx context.Context, req *JSONRPCRe
 	logger.Info("SocketHandler: session_message called with message: %s (parent: %s, depth: %d)", params.Message, parentSessionID, depth)
 
 	// Get parent session info (workspace, container) - check both active sessions and child sessions
-	var workspaceID, containerID string
+	var workspaceID, containerID, ownerTokenID string
 
 	// First try the main active sessions manager
 	if parentSession, ok := h.server.activeSessions.Get(parentSessionID); ok {
 		workspaceID = parentSession.WorkspaceID
 		containerID = parentSession.ContainerID
+		ownerTokenID = parentSession.OwnerTokenID
 	} else {
 		// Check if this is a child session calling to spawn a grandchild
 		h.childMu.RLock()
@@ -301,6 +332,7 @@ func (h *SocketHandler) handleSessionMessage(ctx context.Context, req *JSONRPCRe
 		if isChild && childSess.WorkspaceID != "" {
 			workspaceID = childSess.WorkspaceID
 			containerID = childSess.ContainerID
+			ownerTokenID = childSess.OwnerTokenID
 		} else {
 			return &JSONRPCResponse{
 				JSONRPC: "2.0",
@@ -337,6 +369,19 @@ func (h *SocketHandler) handleSessionMessage(ctx context.Context, req *JSONRPCRe
 		}
 	}
 
+	// Children are billed to the token that owns the root session
+	if err := h.server.checkOwnerSessionQuota(ownerTokenID, h.runningChildren(ownerTokenID)); err != nil {
+		return &JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Error: &JSONRPCError{
+				Code:    -32000,
+				Message: err.Error(),
+			},
+		}
+	}
+	h.server.recordSession(ownerTokenID)
+
 	// Generate child session ID
 	h.childMu.Lock()
 	h.childCounter++
@@ -344,22 +389,40 @@ func (h *SocketHandler) handleSessionMessage(ctx context.Context, req *JSONRPCRe
 
 	// Create child session record with context for potential grandchildren
 	child := &childSession{
-		SessionID:   childSessionID,
-		Status:      "running",
-		StartedAt:   time.Now(),
-		WorkspaceID: workspaceID,
-		ContainerID: containerID,
-		ProjectID:   projectID,
+		SessionID:    childSessionID,
+		Status:       "running",
+		StartedAt:    time.Now(),
+		WorkspaceID:  workspaceID,
+		ContainerID:  containerID,
+		ProjectID:    projectID,
+		OwnerTokenID: ownerTokenID,
 	}
 	h.childSessions[childSessionID] = child
 	h.childMu.Unlock()
 
 	logger.Info("Spawning async child session %s for message: %s", childSessionID, params.Message)
+	metrics.RecordChildSpawn(depth + 1)
 
 	// Execute asynchronously with streaming (MCP enabled)
 	// Child needs its own upstream connection to the relay so its oubliette-client can communicate
 	go func() {
 		childDepth := depth + 1
+
+		ctx, span := tracing.Start(ctx, "session.spawn_child",
+			attribute.String("oubliette.session_id", childSessionID),
+			attribute.String("oubliette.parent_session_id", parentSessionID),
+			attribute.String("oubliette.project_id", projectID),
+			attribute.Int("oubliette.depth", childDepth),
+		)
+		defer func() {
+			var spawnErr error
+			h.childMu.RLock()
+			if cs, ok := h.childSessions[childSessionID]; ok && cs.Status == "failed" {
+				spawnErr = fmt.Errorf("%s", cs.Error)
+			}
+			h.childMu.RUnlock()
+			tracing.End(span, spawnErr)
+		}()
 		// Working directory depends on workspace isolation setting
 		var workingDir string
 		if proj.WorkspaceIsolation {
@@ -386,8 +449,7 @@ func (h *SocketHandler) handleSessionMessage(ctx context.Context, req *JSONRPCRe
 		}
 
 		// Send upstream header for child session
-		header := fmt.Sprintf("OUBLIETTE-UPSTREAM %s %s %d\n", childSessionID, projectID, childDepth)
-		if _, err := childConn.Write([]byte(header)); err != nil {
+		if _, err := childConn.Write([]byte(upstreamHeader(ctx, childSessionID, projectID, childDepth))); err != nil {
 			_ = childConn.Close()
 			h.childMu.Lock()
 			if cs, ok := h.childSessions[childSessionID]; ok {
@@ -451,6 +513,10 @@ func (h *SocketHandler) handleSessionMessage(ctx context.Context, req *JSONRPCRe
 		// Collect events until completion
 		var finalResult string
 		for event := range executor.Events() {
+			if event.InputTokens > 0 || event.OutputTokens > 0 {
+				h.server.recordModelTokens(ownerTokenID, event.InputTokens, event.OutputTokens)
+				metrics.RecordModelTokens(projectID, proj.Model, event.InputTokens, event.OutputTokens)
+			}
 			if event.Type == agent.StreamEventCompletion {
 				finalResult = event.FinalText
 				break
@@ -498,6 +564,22 @@ func (h *SocketHandler) handleSessionMessage(ctx context.Context, req *JSONRPCRe
 	}
 }
 
+// runningChildren counts the running children billed to a token
+func (h *SocketHandler) runningChildren(ownerTokenID string) int {
+	if ownerTokenID == "" {
+		return 0
+	}
+	h.childMu.RLock()
+	defer h.childMu.RUnlock()
+	n := 0
+	for _, cs := range h.childSessions {
+		if cs.OwnerTokenID == ownerTokenID && cs.Status == "running" {
+			n++
+		}
+	}
+	return n
+}
+
 // handleSessionEvents returns events/status from a child session
 func (h *SocketHandler) handleSessionEvents(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
 	var params struct {
@@ -699,11 +781,13 @@ func (h *SocketHandler) handleCallerTool(ctx context.Context, req *JSONRPCReques
 	}
 
 	logger.Info("Pushed caller_tool_request for session %s: tool=%s, request_id=%s", sessionID, params.Tool, requestID)
+	pushedAt := time.Now()
 
 	// Wait for response with timeout
 	select {
 	case response, ok := <-responseCh:
 		if !ok {
+			metrics.RecordCallerTool("error", time.Since(pushedAt))
 			// Channel was closed (cancelled)
 			return &JSONRPCResponse{
 				JSONRPC: "2.0",
@@ -715,6 +799,7 @@ func (h *SocketHandler) handleCallerTool(ctx context.Context, req *JSONRPCReques
 			}
 		}
 		if response.Error != "" {
+			metrics.RecordCallerTool("error", time.Since(pushedAt))
 			return &JSONRPCResponse{
 				JSONRPC: "2.0",
 				ID:      req.ID,
@@ -724,6 +809,7 @@ func (h *SocketHandler) handleCallerTool(ctx context.Context, req *JSONRPCReques
 				},
 			}
 		}
+		metrics.RecordCallerTool("success", time.Since(pushedAt))
 		return &JSONRPCResponse{
 			JSONRPC: "2.0",
 			ID:      req.ID,
@@ -732,6 +818,7 @@ func (h *SocketHandler) handleCallerTool(ctx context.Context, req *JSONRPCReques
 
 	case <-time.After(callerToolTimeout):
 		activeSess.CancelCallerRequest(requestID)
+		metrics.RecordCallerTool("timeout", time.Since(pushedAt))
 		return &JSONRPCResponse{
 			JSONRPC: "2.0",
 			ID:      req.ID,
@@ -894,7 +981,7 @@ func (h *SocketHandler) handleOublietteCallTool(ctx context.Context, req *JSONRP
 	projectID := ExtractProjectIDFromArgs(params.Arguments)
 
 	// Check tool is allowed for scope (with project check for project-scoped tokens)
-	if !h.server.isToolAllowedWithProject(params.Tool, token.Scope, projectID) {
+	if !h.server.isToolAllowedWithProject(params.Tool, ExtractActionFromArgs(params.Arguments), token.Scope, projectID) {
 		return &JSONRPCResponse{
 			JSONRPC: "2.0",
 			ID:      req.ID,
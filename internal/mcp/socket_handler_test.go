@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
+	"github.com/HyphaGroup/oubliette/internal/auth"
+	"github.com/HyphaGroup/oubliette/internal/session"
+)
+
+// idleExecutor is a StreamingExecutor that never produces events
+type idleExecutor struct{ done chan struct{} }
+
+func (e *idleExecutor) SendMessage(string) error          { return nil }
+func (e *idleExecutor) Cancel() error                     { return nil }
+func (e *idleExecutor) Events() <-chan *agent.StreamEvent { return nil }
+func (e *idleExecutor) Errors() <-chan error              { return nil }
+func (e *idleExecutor) Done() <-chan struct{}             { return e.done }
+func (e *idleExecutor) Wait() (int, error)                { return 0, nil }
+func (e *idleExecutor) Close() error                      { return nil }
+func (e *idleExecutor) RuntimeSessionID() string          { return "" }
+func (e *idleExecutor) IsClosed() bool                    { return false }
+
+func TestSocketSessionMessageChecksOwnerQuota(t *testing.T) {
+	s, projectID := newPromptTestServer(t)
+	s.activeSessions = session.NewActiveSessionManager(10, time.Hour)
+	t.Cleanup(s.activeSessions.Close)
+	var err error
+	if s.authStore, err = auth.NewStore(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.authStore.Close() })
+
+	_, tokenID, err := s.authStore.CreateToken("ci", auth.ScopeAdmin, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.authStore.SetQuota(tokenID, &auth.Quota{MaxTokensPerDay: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.authStore.RecordModelTokens(tokenID, 80, 40); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := session.NewActiveSession("gogol_parent", projectID, "550e8400-e29b-41d4-a716-446655440001", "container-1", &idleExecutor{done: make(chan struct{})})
+	parent.OwnerTokenID = tokenID
+	if err := s.activeSessions.Register(parent); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewSocketHandler(s)
+	resp := h.handleSessionMessage(context.Background(), &JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "session_message", Params: []byte(`{"message": "fan out"}`)},
+		parent.SessionID, projectID, 0)
+	if resp.Error == nil || !strings.Contains(resp.Error.Message, "quota exceeded") {
+		t.Fatalf("handleSessionMessage() = %+v, want quota error", resp)
+	}
+	if n := len(h.childSessions); n != 0 {
+		t.Errorf("%d child sessions registered over quota, want 0", n)
+	}
+}
@@ -0,0 +1,443 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/audit"
+	"github.com/HyphaGroup/oubliette/internal/auth"
+	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/logger"
+	"github.com/HyphaGroup/oubliette/internal/project"
+	"github.com/HyphaGroup/oubliette/internal/terminal"
+	"github.com/google/uuid"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	defaultTerminalCols = 80
+	defaultTerminalRows = 24
+	maxTerminalSize     = 1000
+)
+
+// terminalSession is an attached shell that watchers can join
+type terminalSession struct {
+	id          string
+	projectID   string
+	workspaceID string
+	startedAt   time.Time
+	output      *terminal.Broadcaster
+
+	done     chan struct{}
+	exitCode int
+}
+
+// terminalRegistry tracks the shells attached on this server
+type terminalRegistry struct {
+	mu       sync.Mutex
+	sessions map[string]*terminalSession
+}
+
+func newTerminalRegistry() *terminalRegistry {
+	return &terminalRegistry{sessions: make(map[string]*terminalSession)}
+}
+
+func (r *terminalRegistry) add(sess *terminalSession) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[sess.id] = sess
+}
+
+func (r *terminalRegistry) remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, id)
+}
+
+// find returns a project's terminal by ID, or its most recent one when id is empty
+func (r *terminalRegistry) find(projectID, id string) *terminalSession {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id != "" {
+		if sess, ok := r.sessions[id]; ok && sess.projectID == projectID {
+			return sess
+		}
+		return nil
+	}
+	var latest *terminalSession
+	for _, sess := range r.sessions {
+		if sess.projectID == projectID && (latest == nil || sess.startedAt.After(latest.startedAt)) {
+			latest = sess
+		}
+	}
+	return latest
+}
+
+// terminalRequest is a validated request to attach to a terminal
+type terminalRequest struct {
+	project     *project.Project
+	mode        string
+	workspaceID string
+	workingDir  string
+	cols, rows  uint
+	record      bool
+	watch       *terminalSession
+}
+
+// handleTerminal serves GET /terminal/{project_id}: a WebSocket carrying an
+// interactive shell in the project's container, or a read-only view of one
+func (s *Server) handleTerminal(w http.ResponseWriter, r *http.Request) {
+	req, status, err := s.parseTerminalRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	server := websocket.Server{
+		Handshake: checkTerminalOrigin,
+		Handler: func(ws *websocket.Conn) {
+			defer func() { _ = ws.Close() }()
+			if req.mode == terminal.ModeWatch {
+				s.watchTerminal(ws, req)
+				return
+			}
+			s.runTerminal(ws, req)
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+// parseTerminalRequest checks access and resolves the terminal to attach to,
+// returning the HTTP status to fail with
+func (s *Server) parseTerminalRequest(r *http.Request) (*terminalRequest, int, error) {
+	projectID := r.PathValue("project_id")
+	authCtx := auth.FromContext(r.Context())
+	if authCtx == nil {
+		return nil, http.StatusUnauthorized, fmt.Errorf("authentication required")
+	}
+
+	q := r.URL.Query()
+	req := &terminalRequest{mode: q.Get("mode")}
+	if req.mode == "" {
+		req.mode = terminal.ModeShell
+	}
+
+	// A shell is container exec; watching is read-only like container logs
+	var action string
+	switch req.mode {
+	case terminal.ModeShell:
+		action = "exec"
+	case terminal.ModeWatch:
+		action = "logs"
+	default:
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid mode %q (valid: %s, %s)", req.mode, terminal.ModeShell, terminal.ModeWatch)
+	}
+	if authCtx.Token == nil || !s.registry.IsToolActionAllowed("container", action, authCtx.Token.Scope, projectID) {
+		return nil, http.StatusForbidden, fmt.Errorf("not authorized to %s a terminal for project %s", req.mode, projectID)
+	}
+
+	proj, err := s.projectMgr.Get(projectID)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	req.project = proj
+
+	if req.mode == terminal.ModeWatch {
+		req.watch = s.terminals.find(projectID, q.Get("terminal_id"))
+		if req.watch == nil {
+			return nil, http.StatusNotFound, fmt.Errorf("no attached terminal to watch for project %s", projectID)
+		}
+		return req, 0, nil
+	}
+
+	req.workspaceID = q.Get("workspace_id")
+	if req.workspaceID == "" {
+		req.workspaceID = proj.DefaultWorkspaceID
+	}
+	req.workingDir = "/workspace"
+	if req.workspaceID != "" {
+		if !s.projectMgr.WorkspaceExists(projectID, req.workspaceID) {
+			return nil, http.StatusNotFound, fmt.Errorf("workspace %s not found", req.workspaceID)
+		}
+		req.workingDir = workspaceWorkingDir(proj, req.workspaceID)
+	}
+
+	req.cols, req.rows = defaultTerminalCols, defaultTerminalRows
+	if cols, rows := q.Get("cols"), q.Get("rows"); cols != "" || rows != "" {
+		req.cols, req.rows, err = parseTerminalSize(cols, rows)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+	if record := q.Get("record"); record != "" {
+		if req.record, err = strconv.ParseBool(record); err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid record value %q", record)
+		}
+		if req.record && s.dataDir == "" {
+			return nil, http.StatusBadRequest, fmt.Errorf("recording is not available: server has no data directory")
+		}
+	}
+	return req, 0, nil
+}
+
+// recordingPath returns where a terminal's recording is written
+func (s *Server) recordingPath(projectID, terminalID string) string {
+	return filepath.Join(s.dataDir, "recordings", projectID, terminalID+".cast")
+}
+
+// workspaceWorkingDir returns where a workspace is inside the project container
+func workspaceWorkingDir(proj *project.Project, workspaceID string) string {
+	if proj.WorkspaceIsolation {
+		// Isolated mode: /workspace is mounted to workspaces/
+		return fmt.Sprintf("/workspace/%s", workspaceID)
+	}
+	return fmt.Sprintf("/workspace/workspaces/%s", workspaceID)
+}
+
+func parseTerminalSize(cols, rows string) (uint, uint, error) {
+	c, errC := strconv.ParseUint(cols, 10, 32)
+	r, errR := strconv.ParseUint(rows, 10, 32)
+	if errC != nil || errR != nil || c == 0 || r == 0 || c > maxTerminalSize || r > maxTerminalSize {
+		return 0, 0, fmt.Errorf("invalid terminal size %sx%s", cols, rows)
+	}
+	return uint(c), uint(r), nil
+}
+
+// checkTerminalOrigin rejects browser connections from other sites. Clients
+// that send no Origin, like the CLI, are authenticated by token alone.
+func checkTerminalOrigin(cfg *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host != r.Host {
+		return fmt.Errorf("cross-origin terminal connection from %q refused", origin)
+	}
+	cfg.Origin = u
+	return nil
+}
+
+// runTerminal starts a login shell on a TTY in the project's container and
+// connects it to the WebSocket until the shell exits or the client leaves
+func (s *Server) runTerminal(ws *websocket.Conn, req *terminalRequest) {
+	ctx := ws.Request().Context()
+	projectID := req.project.ID
+
+	event := &audit.Event{
+		Operation: audit.OpTerminalAttach,
+		ProjectID: projectID,
+		Details:   map[string]interface{}{"mode": terminal.ModeShell, "workspace_id": req.workspaceID},
+	}
+	fail := func(err error) {
+		logAudit(ctx, event, err)
+		_ = terminal.SendControl(ws, &terminal.Message{Type: terminal.MessageError, Error: err.Error()})
+	}
+
+	containerName, err := s.ensureProjectContainer(ctx, req.project)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	// The shell runs under a marked name so it can be hung up when the client leaves
+	marker := execMarker("terminal")
+	ie, err := s.runtime.ExecInteractive(ctx, containerName, container.ExecConfig{
+		Cmd: []string{terminal.DefaultShell, "-c", fmt.Sprintf("exec -a %s %s -l", marker, terminal.DefaultShell)},
+		Env: []string{
+			"TERM=" + terminal.DefaultTerm,
+			fmt.Sprintf("COLUMNS=%d", req.cols),
+			fmt.Sprintf("LINES=%d", req.rows),
+		},
+		WorkingDir: req.workingDir,
+		TTY:        true,
+	})
+	if err != nil {
+		fail(fmt.Errorf("failed to start shell: %w", err))
+		return
+	}
+	defer func() { _ = ie.Close() }()
+	if err := ie.Resize(req.rows, req.cols); err != nil && !errors.Is(err, container.ErrResizeUnsupported) {
+		logger.Error("Failed to size terminal for project %s: %v", projectID, err)
+	}
+
+	sess := &terminalSession{
+		id:          uuid.New().String(),
+		projectID:   projectID,
+		workspaceID: req.workspaceID,
+		startedAt:   time.Now(),
+		output:      terminal.NewBroadcaster(),
+		done:        make(chan struct{}),
+	}
+	event.Details["terminal_id"] = sess.id
+
+	outputs := []io.Writer{terminal.Writer(ws), sess.output}
+	var recorder *terminal.Recorder
+	if req.record {
+		// Outside the project directory, which the container can write to
+		path := s.recordingPath(projectID, sess.id)
+		recorder, err = terminal.NewRecorder(path, req.cols, req.rows, fmt.Sprintf("%s (%s)", req.project.Name, sess.id))
+		if err != nil {
+			s.signalExec(containerName, "HUP", marker)
+			fail(err)
+			return
+		}
+		defer func() { _ = recorder.Close() }()
+		outputs = append(outputs, recorder)
+		event.Details["recording"] = path
+	}
+
+	s.terminals.add(sess)
+	defer s.terminals.remove(sess.id)
+	defer sess.output.Close()
+
+	ready := &terminal.Message{Type: terminal.MessageReady, TerminalID: sess.id, Mode: terminal.ModeShell}
+	if recorder != nil {
+		ready.Recording = event.Details["recording"].(string)
+	}
+	if err := terminal.SendControl(ws, ready); err != nil {
+		s.signalExec(containerName, "HUP", marker)
+		return
+	}
+	logAudit(ctx, event, nil)
+	logger.Info("Terminal %s attached to project %s", sess.id, projectID)
+
+	// Output: everything the shell writes, until it exits
+	out := &lockedWriter{w: io.MultiWriter(outputs...)}
+	var copies sync.WaitGroup
+	copies.Add(2)
+	go func() { defer copies.Done(); _, _ = io.Copy(out, ie.Stdout) }()
+	go func() { defer copies.Done(); _, _ = io.Copy(out, ie.Stderr) }()
+	go func() {
+		copies.Wait()
+		code, _ := ie.Wait()
+		sess.exitCode = code
+		close(sess.done)
+		_ = terminal.SendControl(ws, &terminal.Message{Type: terminal.MessageExit, ExitCode: code})
+		_ = ws.Close()
+	}()
+
+	// An attached terminal keeps the container awake
+	s.idle.KeepAwake(projectID, sess.done)
+
+	// Input: keystrokes and resizes, until the client leaves or the shell exits
+	for {
+		frame, err := terminal.Receive(ws)
+		if err != nil {
+			break
+		}
+		if frame.Control != nil {
+			if frame.Control.Type == terminal.MessageResize && frame.Control.Cols > 0 && frame.Control.Rows > 0 &&
+				frame.Control.Cols <= maxTerminalSize && frame.Control.Rows <= maxTerminalSize {
+				if err := ie.Resize(frame.Control.Rows, frame.Control.Cols); err != nil && !errors.Is(err, container.ErrResizeUnsupported) {
+					logger.Error("Failed to resize terminal %s: %v", sess.id, err)
+				}
+				if recorder != nil {
+					recorder.Resize(frame.Control.Cols, frame.Control.Rows)
+				}
+			}
+			continue
+		}
+		if _, err := ie.Stdin.Write(frame.Data); err != nil {
+			break
+		}
+	}
+
+	select {
+	case <-sess.done:
+	default:
+		// The client left while the shell was running: hang it up like a closed terminal
+		s.signalExec(containerName, "HUP", marker)
+		_ = ie.Close()
+		select {
+		case <-sess.done:
+		case <-time.After(10 * time.Second):
+			logger.Error("Terminal %s shell did not exit after hangup", sess.id)
+		}
+	}
+	logger.Info("Terminal %s detached from project %s", sess.id, projectID)
+}
+
+// watchTerminal streams another client's shell to the WebSocket read-only
+func (s *Server) watchTerminal(ws *websocket.Conn, req *terminalRequest) {
+	ctx := ws.Request().Context()
+	sess := req.watch
+
+	watcher, recent := sess.output.Watch()
+	logAudit(ctx, &audit.Event{
+		Operation: audit.OpTerminalAttach,
+		ProjectID: sess.projectID,
+		Details:   map[string]interface{}{"mode": terminal.ModeWatch, "terminal_id": sess.id},
+	}, nil)
+	if watcher == nil {
+		// The shell ended between looking the terminal up and watching it
+		exit := &terminal.Message{Type: terminal.MessageExit}
+		select {
+		case <-sess.done:
+			exit.ExitCode = sess.exitCode
+		default:
+		}
+		_ = terminal.SendControl(ws, exit)
+		return
+	}
+	defer sess.output.Unwatch(watcher)
+
+	if err := terminal.SendControl(ws, &terminal.Message{Type: terminal.MessageReady, TerminalID: sess.id, Mode: terminal.ModeWatch}); err != nil {
+		return
+	}
+	if len(recent) > 0 {
+		if err := terminal.SendData(ws, recent); err != nil {
+			return
+		}
+	}
+
+	// Watchers can't type; reading only notices the client leaving
+	left := make(chan struct{})
+	go func() {
+		defer close(left)
+		for {
+			if _, err := terminal.Receive(ws); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-left:
+			return
+		case chunk, ok := <-watcher.C:
+			if !ok {
+				select {
+				case <-sess.done:
+					_ = terminal.SendControl(ws, &terminal.Message{Type: terminal.MessageExit, ExitCode: sess.exitCode})
+				default:
+					_ = terminal.SendControl(ws, &terminal.Message{Type: terminal.MessageError, Error: terminal.ErrWatcherDropped.Error()})
+				}
+				return
+			}
+			if err := terminal.SendData(ws, chunk); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// lockedWriter serializes writes from the stdout and stderr copiers
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
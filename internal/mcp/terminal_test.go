@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/terminal"
+	"github.com/HyphaGroup/oubliette/internal/testutil"
+	"golang.org/x/net/websocket"
+)
+
+// newTerminalTestServer serves the terminal endpoint for a project with a
+// running container
+func newTerminalTestServer(t *testing.T) (*httptest.Server, *testutil.FakeRuntime, *Server, string) {
+	t.Helper()
+	s, rt, projectID := newProjectTestServer(t)
+	proj, err := s.projectMgr.Get(projectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(s.projectMgr.GetWorkspacePath(projectID, proj.DefaultWorkspaceID), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	s.terminals = newTerminalRegistry()
+	s.dataDir = t.TempDir()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+terminal.Path+"{project_id}", s.handleTerminal)
+	return serveWithScope(t, mux), rt, s, projectID
+}
+
+// readUntil reads frames until the output so far contains want
+func readUntil(t *testing.T, ws *websocket.Conn, want string) string {
+	t.Helper()
+	_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var out strings.Builder
+	for !strings.Contains(out.String(), want) {
+		frame, err := terminal.Receive(ws)
+		if err != nil {
+			t.Fatalf("Receive() error = %v (output so far %q)", err, out.String())
+		}
+		if frame.Control != nil {
+			t.Fatalf("unexpected %s message waiting for %q: %+v", frame.Control.Type, want, frame.Control)
+		}
+		out.Write(frame.Data)
+	}
+	return out.String()
+}
+
+func receiveControl(t *testing.T, ws *websocket.Conn, want string) *terminal.Message {
+	t.Helper()
+	_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		frame, err := terminal.Receive(ws)
+		if err != nil {
+			t.Fatalf("Receive() waiting for %s: %v", want, err)
+		}
+		if frame.Control != nil {
+			if frame.Control.Type != want {
+				t.Fatalf("got %s message, want %s: %+v", frame.Control.Type, want, frame.Control)
+			}
+			return frame.Control
+		}
+	}
+}
+
+func TestTerminalShell(t *testing.T) {
+	srv, rt, s, projectID := newTerminalTestServer(t)
+
+	ws, err := terminal.Dial(terminal.DialOptions{ServerURL: srv.URL, Token: "admin", ProjectID: projectID, Cols: 100, Rows: 30, Record: true})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = ws.Close() }()
+	ready := receiveControl(t, ws, terminal.MessageReady)
+	if ready.Mode != terminal.ModeShell || ready.TerminalID == "" || ready.Recording == "" {
+		t.Fatalf("ready = %+v", ready)
+	}
+
+	if err := terminal.SendData(ws, []byte("echo hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	readUntil(t, ws, "hello")
+
+	id := testContainerName(t, projectID)
+	if rows, cols, _ := rt.TerminalSize(id); rows != 30 || cols != 100 {
+		t.Errorf("initial size = %dx%d, want 100x30", cols, rows)
+	}
+	if err := terminal.SendControl(ws, &terminal.Message{Type: terminal.MessageResize, Cols: 132, Rows: 50}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A watcher joins with the output so far and follows what comes next
+	watch, err := terminal.Dial(terminal.DialOptions{ServerURL: srv.URL, Token: "project:" + projectID + ":ro", ProjectID: projectID, Mode: terminal.ModeWatch})
+	if err != nil {
+		t.Fatalf("Dial(watch) error = %v", err)
+	}
+	defer func() { _ = watch.Close() }()
+	if got := receiveControl(t, watch, terminal.MessageReady); got.TerminalID != ready.TerminalID || got.Mode != terminal.ModeWatch {
+		t.Errorf("watch ready = %+v, want terminal %s", got, ready.TerminalID)
+	}
+	readUntil(t, watch, "hello")
+
+	if err := terminal.SendData(ws, []byte("echo second\nexit 3\n")); err != nil {
+		t.Fatal(err)
+	}
+	readUntil(t, ws, "second")
+	readUntil(t, watch, "second")
+	if got := receiveControl(t, ws, terminal.MessageExit); got.ExitCode != 3 {
+		t.Errorf("shell exit code = %d, want 3", got.ExitCode)
+	}
+	if got := receiveControl(t, watch, terminal.MessageExit); got.ExitCode != 3 {
+		t.Errorf("watch exit code = %d, want 3", got.ExitCode)
+	}
+	if rows, cols, _ := rt.TerminalSize(id); rows != 50 || cols != 132 {
+		t.Errorf("resized to %dx%d, want 132x50", cols, rows)
+	}
+
+	wantPath := filepath.Join(s.dataDir, "recordings", projectID, ready.TerminalID+".cast")
+	if ready.Recording != wantPath {
+		t.Errorf("recording = %s, want %s", ready.Recording, wantPath)
+	}
+	if _, err := os.Stat(filepath.Join(s.projectMgr.GetProjectDir(projectID), "recordings")); err == nil {
+		t.Error("recording written inside the project directory")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(wantPath)
+		if strings.Contains(string(data), `"r","132x50"`) && strings.Contains(string(data), "second") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("recording missing output or resize:\n%s", data)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTerminalRefused(t *testing.T) {
+	srv, _, _, projectID := newTerminalTestServer(t)
+	other := "00000000-0000-0000-0000-000000000000"
+
+	tests := []struct {
+		name string
+		opts terminal.DialOptions
+		path string
+		want int
+	}{
+		{"read-only shell", terminal.DialOptions{Token: "project:" + projectID + ":ro", ProjectID: projectID}, "", http.StatusForbidden},
+		{"other project", terminal.DialOptions{Token: "project:" + other, ProjectID: projectID}, "", http.StatusForbidden},
+		{"unknown project", terminal.DialOptions{Token: "admin", ProjectID: other}, "", http.StatusNotFound},
+		{"unknown workspace", terminal.DialOptions{Token: "admin", ProjectID: projectID, WorkspaceID: other}, "", http.StatusNotFound},
+		{"nothing to watch", terminal.DialOptions{Token: "admin", ProjectID: projectID, Mode: terminal.ModeWatch}, "", http.StatusNotFound},
+		{"bad size", terminal.DialOptions{Token: "admin", ProjectID: projectID}, "?cols=0&rows=5000", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.ServerURL = srv.URL
+			u, _ := tt.opts.URL()
+			if tt.path != "" {
+				u = strings.SplitN(u, "?", 2)[0] + tt.path
+			} else if _, err := terminal.Dial(tt.opts); err == nil {
+				t.Fatal("Dial() succeeded")
+			}
+			req, _ := http.NewRequest(http.MethodGet, strings.Replace(u, "ws://", "http://", 1), nil)
+			req.Header.Set("Authorization", "Bearer "+tt.opts.Token)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HyphaGroup/oubliette/internal/auth"
+	"github.com/HyphaGroup/oubliette/internal/container"
+	"github.com/HyphaGroup/oubliette/internal/testutil"
+)
+
+// newProjectTestServer returns a server with every tool registered for a
+// project whose container is running on a fake runtime
+func newProjectTestServer(t *testing.T) (*Server, *testutil.FakeRuntime, string) {
+	t.Helper()
+	s, projectID := newPromptTestServer(t)
+
+	rt := testutil.NewFakeRuntime()
+	t.Cleanup(func() { _ = rt.Close() })
+	ctx := context.Background()
+	id, err := rt.Create(ctx, container.CreateConfig{Name: testContainerName(t, projectID), Image: "oubliette-base:latest", Cmd: []string{"sleep", "300"}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := rt.Start(ctx, id); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	s.runtime = rt
+
+	s.registry = NewRegistry()
+	s.registerAllTools(s.registry)
+	return s, rt, projectID
+}
+
+// serveWithScope serves h over HTTP. Each request's bearer token is used as
+// the caller's scope.
+func serveWithScope(t *testing.T, h http.Handler) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		ctx := auth.WithContext(r.Context(), &auth.AuthContext{Token: &auth.Token{ID: "oub_test-token", Name: "test", Scope: scope}})
+		h.ServeHTTP(w, r.WithContext(ctx))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// testContainerName returns the name of a project's container
+func testContainerName(t *testing.T, projectID string) string {
+	t.Helper()
+	name, err := container.ProjectContainerName(projectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
@@ -49,9 +49,9 @@ func (s *Server) getToolsForScope(scope string) []ToolDefinition {
 	return result
 }
 
-// isToolAllowedWithProject checks if a specific tool name is allowed for a token scope and project
-func (s *Server) isToolAllowedWithProject(toolName, tokenScope, projectID string) bool {
-	return s.registry.IsToolAllowedWithProject(toolName, tokenScope, projectID)
+// isToolAllowedWithProject checks if a tool action is allowed for a token scope and project
+func (s *Server) isToolAllowedWithProject(toolName, action, tokenScope, projectID string) bool {
+	return s.registry.IsToolActionAllowed(toolName, action, tokenScope, projectID)
 }
 
 // dispatchToolCall routes a tool call to the appropriate handler with auth context
@@ -9,6 +9,10 @@ func (s *Server) registerAllTools(r *Registry) {
 	s.registerConfigTools(r)
 	s.registerTokenTools(r)
 	s.registerScheduleTools(r)
+	s.registerPromptTools(r)
+	s.registerAuditTools(r)
+	s.registerDoctorTools(r)
+	r.SetSessionProjectResolver(s.sessionProjectID)
 }
 
 func (s *Server) registerProjectTools(r *Registry) {
@@ -29,8 +33,10 @@ Key parameters (create):
   model           — LLM model for sessions. Use "options" action to see available models.
   description     — Human-readable project description
   name            — Display name (defaults to repo name)`,
-		Target: TargetGlobal,
-		Access: AccessWrite,
+		Actions:     projectActions,
+		Target:      TargetGlobal,
+		Access:      AccessWrite,
+		ReadActions: []string{"list", "get", "options"},
 	}, s.handleProject)
 
 	Register(r, ToolDef{
@@ -63,11 +69,18 @@ Actions:
   start  — Start a project's container. Requires project_id.
   stop   — Stop a running container. Requires project_id.
   logs   — Get container logs. Requires project_id. Use tail (int) and since (duration like "1h") to filter.
+           Set follow to keep streaming new lines until the container stops or timeout_seconds (default 600) passes.
   exec   — Execute a command inside the container. Requires project_id and command (string array).
+           Set stream to receive output while it runs; timeout_seconds bounds the command (streamed default 1800).
+  build  — Build the project image from the repository's .oubliette/Dockerfile. Requires project_id. Set force to rebuild an up-to-date image.
 
-Containers auto-start when sessions spawn. Use "start" to pre-warm, "exec" to debug.`,
-		Target: TargetProject,
-		Access: AccessWrite,
+Containers auto-start when sessions spawn. Use "start" to pre-warm, "exec" to debug.
+Projects with a Dockerfile run their own image, rebuilt automatically when the Dockerfile or its directory changes; build logs stream as "oubliette.build" log notifications.
+Streamed exec output and followed logs arrive as progress notifications (send a progressToken): each message is an output chunk and _meta.stream is "stdout", "stderr" or "logs". The result holds the last 64KB of each stream.`,
+		Actions:     containerActions,
+		Target:      TargetProject,
+		Access:      AccessWrite,
+		ReadActions: []string{"logs"},
 	}, s.handleContainer)
 
 	Register(r, ToolDef{
@@ -90,18 +103,37 @@ Actions:
   message  — Send a task to an agent. Auto-creates session if none active. Most common action.
   spawn    — Create or resume a session explicitly. Use new_session=true to force fresh session.
   get      — Get session details, turns, and token costs by session_id.
-  list     — List sessions for a project. Filter by status (active/completed/failed).
+  list     — List sessions for a project, newest first. Filter by status (active/completed/failed),
+             workspace_id, model, exploration_id, parent_session_id, since/until (RFC 3339).
+             Pages hold limit sessions (default 50); pass the returned cursor for the next page.
+  search   — Full-text search of prompts, final outputs and tool calls (file paths, commands) by
+             query. Filter by project_id and since/until; without project_id requires admin.
   events   — Poll streaming events by session_id. Use since_index for pagination.
   end      — End a session by session_id.
+  export   — Export the full transcript by session_id. format: markdown (default), html, or json.
+  fork     — Start a new session from session_id's conversation through turn (1-based), with an
+             optional message. Set fork_workspace=true to run it in a copy of the workspace.
   cleanup  — Delete old sessions. Optionally filter by project_id and max_age_hours (default: 24).
 
 Key behaviors:
   - Sessions auto-resume: sending a message reuses the active session for that project/workspace.
   - Use new_session=true on spawn to force a fresh session.
   - model/autonomy_level/reasoning_level default to project config if not specified.
-  - Events are also pushed via MCP notifications. Use events action to poll or catch up.`,
-		Target: TargetProject,
-		Access: AccessWrite,
+  - Events are also pushed via MCP notifications. Use events action to poll or catch up.
+  - export renders prompts, assistant text, reasoning, tool calls with parameters and truncated
+    results, child session links, and token totals from the session's persisted event log.
+  - fork keeps the source session untouched. The fork records parent_session_id and
+    forked_from_turn. fork_workspace copies the workspace files as they are now, not as they
+    were at that turn. Without fork_workspace the source workspace must have no active session.
+  - message accepts template (a named project prompt template) and variables. The template is
+    rendered with the message available as {{.Message}} and variables as {{.Vars.name}}.
+  - message accepts attachments ({filename, content_type, data (base64) or url}). Files are written
+    to .oubliette/attachments/<turn>/ in the workspace and listed in the prompt; images are also
+    sent to the model. Limits: 10 files, 10 MiB each, 25 MiB per message.`,
+		Actions:     sessionActions,
+		Target:      TargetProject,
+		Access:      AccessWrite,
+		ReadActions: []string{"get", "list", "search", "events", "export"},
 	}, s.handleSession)
 
 	Register(r, ToolDef{
@@ -127,8 +159,10 @@ Actions:
 
 Each session runs in a workspace. Workspaces persist between sessions for continuity.
 Use external_id and source on spawn/message to correlate workspaces with external systems (PRs, tickets).`,
-		Target: TargetProject,
-		Access: AccessWrite,
+		Actions:     workspaceActions,
+		Target:      TargetProject,
+		Access:      AccessWrite,
+		ReadActions: []string{"list"},
 	}, s.handleWorkspace)
 }
 
@@ -142,6 +176,23 @@ can nest before hitting limits. Requires project_id. Optionally pass session_id
 		Target: TargetProject,
 		Access: AccessRead,
 	}, s.handleGetRecursionLimits)
+
+	Register(r, ToolDef{
+		Name: "config",
+		Description: `Reload the server configuration (oubliette.jsonc) without a restart. Requires admin scope.
+
+Actions:
+  reload  — Re-read and validate the config, swap in models, credentials, default limits and
+            container images, and report what changed. An invalid config is rejected and the
+            current one stays in effect.
+  diff    — Report what a reload would change, without applying it.
+
+Running sessions and existing projects keep their settings. Changes to server, backup,
+container pool and idle-stop settings are reported but need a restart.`,
+		Actions: configActions,
+		Target:  TargetGlobal,
+		Access:  AccessAdmin,
+	}, s.handleConfig)
 }
 
 func (s *Server) registerTokenTools(r *Registry) {
@@ -150,13 +201,20 @@ func (s *Server) registerTokenTools(r *Registry) {
 		Description: `Manage API tokens for MCP authentication. Requires admin scope.
 
 Actions:
-  create  — Create a new token. Specify scope: "admin", "admin:ro", "project:<id>", "project:<id>:ro".
-  list    — List all tokens with metadata (scope, created date, last used).
-  revoke  — Revoke a token by token_id.
+  create     — Create a new token. Specify scope: "admin", "admin:ro", "project:<id>", "project:<id>:ro".
+               Combine entries with spaces or commas (e.g. "project:a project:b:ro"); narrow with
+               "tool:<name>[:<action>|:ro]" and "deny:<name>[:<action>]". Optional quota.
+  list       — List all tokens with metadata (scope, quota, created date, last used).
+  revoke     — Revoke a token by token_id.
+  usage      — Show today's usage (requests, sessions, model tokens) and quota for token_id.
+  set_quota  — Set or clear (empty quota) the quota of token_id. Quota fields:
+               requests_per_second, burst, max_concurrent_sessions, max_sessions_per_day,
+               max_tokens_per_day. Zero or omitted means unlimited.
 
-Tokens authenticate MCP clients. Project-scoped tokens restrict access to one project.`,
-		Target: TargetGlobal,
-		Access: AccessAdmin,
+Tokens authenticate MCP clients. Project-scoped tokens restrict access to the listed projects.`,
+		Actions: tokenActions,
+		Target:  TargetGlobal,
+		Access:  AccessAdmin,
 	}, s.handleToken)
 }
 
@@ -175,8 +233,73 @@ Actions:
   history  — View execution history for a schedule. Optionally limit results.
 
 Schedules spawn sessions on a cron cadence. Set session_behavior to "resume" to reuse the same
-session across runs, or "new" to create a fresh session each time.`,
-		Target: TargetGlobal,
-		Access: AccessWrite,
+session across runs, or "new" to create a fresh session each time.
+
+The prompt is a Go template rendered per target on every run. Available fields:
+  {{.Now}}, {{.ScheduleName}}, {{.ProjectID}}, {{.ProjectName}}, {{.WorkspaceID}}, {{.ExternalID}},
+  {{.LastOutput}}, {{.LastStatus}}, {{.LastRunAt}} (previous execution of the same target).`,
+		Actions:     scheduleActions,
+		Target:      TargetGlobal,
+		Access:      AccessWrite,
+		ReadActions: []string{"list", "get", "history"},
 	}, s.handleSchedule)
 }
+
+func (s *Server) registerPromptTools(r *Registry) {
+	Register(r, ToolDef{
+		Name: "prompt",
+		Description: `Manage reusable prompt templates stored per project.
+
+Actions:
+  save    — Create or replace a template. Requires project_id, name and body.
+  list    — List templates for a project. Requires project_id.
+  get     — Get a template by name. Requires project_id and name.
+  delete  — Delete a template. Requires project_id and name.
+  render  — Preview a template (by name or inline body) with optional variables and workspace_id.
+
+Templates use Go template syntax with fields such as {{.ProjectName}}, {{.WorkspaceID}},
+{{.ExternalID}}, {{.Now}}, {{.Message}} and {{.Vars.name}}. Invoke one with
+session message template=<name> variables={...}.`,
+		Actions:     promptActions,
+		Target:      TargetProject,
+		Access:      AccessWrite,
+		ReadActions: []string{"list", "get", "render"},
+	}, s.handlePrompt)
+}
+
+func (s *Server) registerAuditTools(r *Registry) {
+	Register(r, ToolDef{
+		Name: "audit",
+		Description: `Query the durable audit log of mutating operations. Requires admin scope.
+
+Actions:
+  query   — List audit records, newest first. Filters: token_id, project_id, operation
+            (exact, or a prefix ending in "." such as "container."), since/until (RFC 3339),
+            limit (default 100, max 1000).
+  verify  — Check the hash chain of the whole log and report the first broken record.
+
+Audited operations include project/token/schedule changes, session spawn/end,
+container start/stop/exec, workspace deletes and caller tool responses.`,
+		Actions: auditActions,
+		Target:  TargetGlobal,
+		Access:  AccessAdmin,
+	}, s.handleAudit)
+}
+
+func (s *Server) registerDoctorTools(r *Registry) {
+	Register(r, ToolDef{
+		Name: "doctor",
+		Description: `Diagnose setup problems before they surface as session spawn failures. Requires admin scope.
+
+Actions:
+  run   — Run all checks: config validation, credential format, data directory permissions,
+          disk usage, SQLite integrity, container runtime, images, relay sockets and
+          orphaned containers. Each problem comes with a remediation.
+  pull  — Pull missing container images, then run all checks.
+
+Returns a report with a status (ok, warn, fail, skip) per check; ok is false if any check failed.`,
+		Actions: doctorActions,
+		Target:  TargetGlobal,
+		Access:  AccessAdmin,
+	}, s.handleDoctor)
+}
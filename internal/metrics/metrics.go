@@ -48,6 +48,141 @@ var (
 		},
 		[]string{"project_id", "status"},
 	)
+
+	// ModelTokensTotal counts model tokens reported by agent steps
+	ModelTokensTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oubliette_model_tokens_total",
+			Help: "Model tokens used, by project, model and direction (input/output)",
+		},
+		[]string{"project_id", "model", "direction"},
+	)
+
+	// ToolCallsTotal counts MCP tool calls; error rate is status="error" over all
+	ToolCallsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oubliette_tool_calls_total",
+			Help: "Total number of MCP tool calls by tool and status",
+		},
+		[]string{"tool", "status"},
+	)
+
+	// EventBufferDroppedTotal counts session events lost to ring buffer overflow
+	EventBufferDroppedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "oubliette_event_buffer_dropped_events_total",
+			Help: "Session events dropped because an event buffer was full",
+		},
+	)
+
+	// ScheduleExecutionsTotal counts schedule executions by outcome
+	ScheduleExecutionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oubliette_schedule_executions_total",
+			Help: "Total number of schedule executions by status",
+		},
+		[]string{"status"},
+	)
+
+	// ContainerStartDuration tracks how long container starts take
+	ContainerStartDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "oubliette_container_start_duration_seconds",
+			Help:    "Container start latency in seconds",
+			Buckets: []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120, 300},
+		},
+		[]string{"status"},
+	)
+
+	// OpenCodeServerRestartsTotal counts OpenCode servers started to replace a dead one
+	OpenCodeServerRestartsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "oubliette_opencode_server_restarts_total",
+			Help: "OpenCode servers restarted after the previous server stopped running",
+		},
+	)
+
+	// CallerToolDuration tracks caller tool round-trip time through the MCP client
+	CallerToolDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "oubliette_caller_tool_duration_seconds",
+			Help:    "Caller tool round-trip time in seconds",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"status"},
+	)
+
+	// ChildSpawnDepth tracks the recursion depth at which child sessions spawn
+	ChildSpawnDepth = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "oubliette_child_spawn_depth",
+			Help:    "Recursion depth of spawned child sessions",
+			Buckets: prometheus.LinearBuckets(1, 1, 10),
+		},
+	)
+
+	// ContainerPoolIdle tracks warm containers waiting to be claimed
+	ContainerPoolIdle = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "oubliette_container_pool_idle",
+			Help: "Warm containers ready to be claimed, by container type",
+		},
+		[]string{"container_type"},
+	)
+
+	// ContainerPoolClaimsTotal counts container starts that asked the pool for a warm container
+	ContainerPoolClaimsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oubliette_container_pool_claims_total",
+			Help: "Warm container claims by container type and result (hit/miss)",
+		},
+		[]string{"container_type", "result"},
+	)
+
+	// ContainerPoolWarmDuration tracks how long it takes to warm a pool container
+	ContainerPoolWarmDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "oubliette_container_pool_warm_duration_seconds",
+			Help:    "Time to create, start and health-check a pool container in seconds",
+			Buckets: []float64{1, 2, 5, 10, 20, 30, 60, 120, 300},
+		},
+		[]string{"container_type", "status"},
+	)
+
+	// ContainerIdleStopsTotal counts containers stopped by the idle policy
+	ContainerIdleStopsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "oubliette_container_idle_stops_total",
+			Help: "Project containers stopped after having no session activity for the idle period",
+		},
+	)
+
+	// ContainerWakesTotal counts idle-stopped containers restarted for a session
+	ContainerWakesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oubliette_container_wakes_total",
+			Help: "Idle-stopped containers restarted on demand, by status",
+		},
+		[]string{"status"},
+	)
+
+	// TurnRetriesTotal counts turns retried after retryable provider errors
+	TurnRetriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oubliette_turn_retries_total",
+			Help: "Retryable provider errors during a turn, by outcome (retry, failover, gave_up)",
+		},
+		[]string{"outcome"},
+	)
+
+	// ConfigReloadsTotal counts configuration reloads
+	ConfigReloadsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oubliette_config_reloads_total",
+			Help: "Configuration reloads by outcome (applied, unchanged, failed)",
+		},
+		[]string{"outcome"},
+	)
 )
 
 // responseWriter wraps http.ResponseWriter to capture status code
@@ -112,3 +247,98 @@ func RecordSessionEnd(projectID, status string, durationSeconds float64) {
 	ActiveSessions.WithLabelValues(projectID).Dec()
 	SessionDuration.WithLabelValues(projectID, status).Observe(durationSeconds)
 }
+
+// RecordModelTokens counts model tokens for a project and model
+func RecordModelTokens(projectID, model string, inputTokens, outputTokens int) {
+	if model == "" {
+		model = "default"
+	}
+	if inputTokens > 0 {
+		ModelTokensTotal.WithLabelValues(projectID, model, "input").Add(float64(inputTokens))
+	}
+	if outputTokens > 0 {
+		ModelTokensTotal.WithLabelValues(projectID, model, "output").Add(float64(outputTokens))
+	}
+}
+
+// RecordToolCall counts a tool call and whether it failed
+func RecordToolCall(tool string, err error) {
+	ToolCallsTotal.WithLabelValues(tool, statusLabel(err)).Inc()
+}
+
+// RecordEventDropped counts an event dropped from a full event buffer
+func RecordEventDropped() {
+	EventBufferDroppedTotal.Inc()
+}
+
+// RecordScheduleExecution counts a schedule execution with its final status
+func RecordScheduleExecution(status string) {
+	ScheduleExecutionsTotal.WithLabelValues(status).Inc()
+}
+
+// RecordContainerStart records container start latency
+func RecordContainerStart(duration time.Duration, err error) {
+	ContainerStartDuration.WithLabelValues(statusLabel(err)).Observe(duration.Seconds())
+}
+
+// RecordOpenCodeRestart counts an OpenCode server restart
+func RecordOpenCodeRestart() {
+	OpenCodeServerRestartsTotal.Inc()
+}
+
+// RecordCallerTool records caller tool round-trip time.
+// status is "success", "error" or "timeout".
+func RecordCallerTool(status string, duration time.Duration) {
+	CallerToolDuration.WithLabelValues(status).Observe(duration.Seconds())
+}
+
+// RecordChildSpawn records the depth of a spawned child session
+func RecordChildSpawn(depth int) {
+	ChildSpawnDepth.Observe(float64(depth))
+}
+
+// SetContainerPoolIdle records the number of idle warm containers for a type
+func SetContainerPoolIdle(containerType string, idle int) {
+	ContainerPoolIdle.WithLabelValues(containerType).Set(float64(idle))
+}
+
+// RecordContainerPoolClaim counts a pool claim and whether a warm container was available
+func RecordContainerPoolClaim(containerType string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	ContainerPoolClaimsTotal.WithLabelValues(containerType, result).Inc()
+}
+
+// RecordContainerPoolWarm records how long warming a pool container took
+func RecordContainerPoolWarm(containerType string, duration time.Duration, err error) {
+	ContainerPoolWarmDuration.WithLabelValues(containerType, statusLabel(err)).Observe(duration.Seconds())
+}
+
+// RecordContainerIdleStop counts a container stopped for inactivity
+func RecordContainerIdleStop() {
+	ContainerIdleStopsTotal.Inc()
+}
+
+// RecordContainerWake counts a restart of an idle-stopped container
+func RecordContainerWake(err error) {
+	ContainerWakesTotal.WithLabelValues(statusLabel(err)).Inc()
+}
+
+// RecordTurnRetry counts a retryable provider error and how the turn recovered
+func RecordTurnRetry(outcome string) {
+	TurnRetriesTotal.WithLabelValues(outcome).Inc()
+}
+
+// RecordConfigReload counts a configuration reload by outcome
+func RecordConfigReload(outcome string) {
+	ConfigReloadsTotal.WithLabelValues(outcome).Inc()
+}
+
+func statusLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecordModelTokens(t *testing.T) {
+	RecordModelTokens("proj-tokens", "anthropic/claude-sonnet-4-5", 100, 40)
+	RecordModelTokens("proj-tokens", "", 7, 0)
+
+	if got := testutil.ToFloat64(ModelTokensTotal.WithLabelValues("proj-tokens", "anthropic/claude-sonnet-4-5", "input")); got != 100 {
+		t.Errorf("input tokens = %v, want 100", got)
+	}
+	if got := testutil.ToFloat64(ModelTokensTotal.WithLabelValues("proj-tokens", "anthropic/claude-sonnet-4-5", "output")); got != 40 {
+		t.Errorf("output tokens = %v, want 40", got)
+	}
+	if got := testutil.ToFloat64(ModelTokensTotal.WithLabelValues("proj-tokens", "default", "input")); got != 7 {
+		t.Errorf("default model input tokens = %v, want 7", got)
+	}
+}
+
+func TestRecordToolCall(t *testing.T) {
+	RecordToolCall("metrics_test_tool", nil)
+	RecordToolCall("metrics_test_tool", nil)
+	RecordToolCall("metrics_test_tool", errors.New("boom"))
+
+	if got := testutil.ToFloat64(ToolCallsTotal.WithLabelValues("metrics_test_tool", "success")); got != 2 {
+		t.Errorf("successful calls = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(ToolCallsTotal.WithLabelValues("metrics_test_tool", "error")); got != 1 {
+		t.Errorf("failed calls = %v, want 1", got)
+	}
+}
+
+func TestRecordContainerStart(t *testing.T) {
+	RecordContainerStart(2*time.Second, nil)
+	RecordContainerStart(time.Second, errors.New("boom"))
+
+	// One series per status
+	if got := testutil.CollectAndCount(ContainerStartDuration); got != 2 {
+		t.Errorf("container start series = %d, want 2", got)
+	}
+}
+
+func TestRecordContainerPoolClaim(t *testing.T) {
+	RecordContainerPoolClaim("metrics-test", true)
+	RecordContainerPoolClaim("metrics-test", false)
+	RecordContainerPoolClaim("metrics-test", false)
+
+	if got := testutil.ToFloat64(ContainerPoolClaimsTotal.WithLabelValues("metrics-test", "hit")); got != 1 {
+		t.Errorf("hits = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(ContainerPoolClaimsTotal.WithLabelValues("metrics-test", "miss")); got != 2 {
+		t.Errorf("misses = %v, want 2", got)
+	}
+}
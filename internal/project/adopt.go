@@ -0,0 +1,118 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/HyphaGroup/oubliette/internal/validation"
+)
+
+// AdoptMarkerPath returns the marker written next to a directory while it is
+// being adopted. A directory with a marker may hold project files and must not
+// be deleted.
+func AdoptMarkerPath(dir string) string {
+	return dir + ".adopting"
+}
+
+// AdoptDirectory makes dir the project's directory. The project's files are
+// moved into dir, merging into directories that already exist there, and dir
+// is then renamed into place. Bind mounts of dir and its subdirectories follow
+// the rename, which is how a pre-started container is given a project's
+// workspace. dir must be on the same filesystem as the projects directory.
+func (m *Manager) AdoptDirectory(projectID, dir string) error {
+	if err := validation.ValidateProjectID(projectID); err != nil {
+		return err
+	}
+
+	m.projectLocks.Lock(projectID)
+	defer m.projectLocks.Unlock(projectID)
+
+	projectDir := filepath.Join(m.projectsDir, projectID)
+	if _, err := os.Stat(projectDir); err != nil {
+		return fmt.Errorf("project directory: %w", err)
+	}
+
+	marker := AdoptMarkerPath(dir)
+	if err := os.WriteFile(marker, []byte(projectID+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write adopt marker: %w", err)
+	}
+
+	var moves []move
+	if err := mergeDir(projectDir, dir, &moves); err != nil {
+		// Put back what was moved so the project stays usable
+		if rbErr := undoMoves(moves); rbErr != nil {
+			return fmt.Errorf("failed to move project files: %w (rollback failed: %v, files remain in %s)", err, rbErr, dir)
+		}
+		_ = os.Remove(marker)
+		return fmt.Errorf("failed to move project files: %w", err)
+	}
+
+	if err := os.Rename(dir, projectDir); err != nil {
+		return fmt.Errorf("failed to move adopted directory into place (files remain in %s): %w", dir, err)
+	}
+	_ = os.Remove(marker)
+	return nil
+}
+
+// move records one step of a merge so it can be undone. A move with an empty
+// to is the removal of an emptied source directory.
+type move struct {
+	from, to string
+}
+
+// mergeDir moves the contents of src into dst and removes src. Directories
+// present in both are merged recursively; any other name clash is an error.
+func mergeDir(src, dst string, moves *[]move) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		from := filepath.Join(src, entry.Name())
+		to := filepath.Join(dst, entry.Name())
+
+		info, err := os.Lstat(to)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			if err := os.Rename(from, to); err != nil {
+				return err
+			}
+			*moves = append(*moves, move{from: from, to: to})
+		case err != nil:
+			return err
+		case entry.IsDir() && info.IsDir():
+			if err := mergeDir(from, to, moves); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%s already exists", to)
+		}
+	}
+
+	if err := os.Remove(src); err != nil {
+		return err
+	}
+	*moves = append(*moves, move{from: src})
+	return nil
+}
+
+// undoMoves reverses a partial merge, newest step first
+func undoMoves(moves []move) error {
+	for i := len(moves) - 1; i >= 0; i-- {
+		m := moves[i]
+		if m.to == "" {
+			if err := os.Mkdir(m.from, 0o755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.Rename(m.to, m.from); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAdoptDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr := NewManager(filepath.Join(tmpDir, "projects"), 5, 10, 100.0)
+
+	projectID := "550e8400-e29b-41d4-a716-446655440000"
+	projectDir := filepath.Join(tmpDir, "projects", projectID)
+	_ = os.MkdirAll(filepath.Join(projectDir, "workspaces", "ws1"), 0o755)
+	_ = os.MkdirAll(filepath.Join(projectDir, "cache", "npm"), 0o755)
+	_ = os.WriteFile(filepath.Join(projectDir, "metadata.json"), []byte(`{"id":"x"}`), 0o644)
+	_ = os.WriteFile(filepath.Join(projectDir, "workspaces", "ws1", "main.go"), []byte("package main"), 0o644)
+	_ = os.WriteFile(filepath.Join(projectDir, "cache", "npm", "index"), []byte("npm"), 0o644)
+
+	// A pool slot with its mount points already created
+	slot := filepath.Join(tmpDir, "pool", "oubliette-pool-dev-0")
+	_ = os.MkdirAll(filepath.Join(slot, "cache", "npm"), 0o755)
+	_ = os.MkdirAll(filepath.Join(slot, "history"), 0o755)
+	slotInfo, _ := os.Stat(slot)
+
+	if err := mgr.AdoptDirectory(projectID, slot); err != nil {
+		t.Fatalf("AdoptDirectory() error = %v", err)
+	}
+
+	info, err := os.Stat(projectDir)
+	if err != nil || !os.SameFile(info, slotInfo) {
+		t.Fatalf("project directory is not the adopted slot directory (err=%v)", err)
+	}
+	for _, rel := range []string{"metadata.json", "workspaces/ws1/main.go", "cache/npm/index", "history"} {
+		if _, err := os.Stat(filepath.Join(projectDir, rel)); err != nil {
+			t.Errorf("%s missing after adoption: %v", rel, err)
+		}
+	}
+	if _, err := os.Stat(slot); !os.IsNotExist(err) {
+		t.Errorf("slot directory still exists: %v", err)
+	}
+	if _, err := os.Stat(AdoptMarkerPath(slot)); !os.IsNotExist(err) {
+		t.Errorf("adopt marker left behind: %v", err)
+	}
+}
+
+func TestAdoptDirectoryConflictRollsBack(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir, 5, 10, 100.0)
+
+	projectID := "550e8400-e29b-41d4-a716-446655440000"
+	projectDir := filepath.Join(tmpDir, projectID)
+	_ = os.MkdirAll(projectDir, 0o755)
+	_ = os.WriteFile(filepath.Join(projectDir, "a.txt"), []byte("a"), 0o644)
+	_ = os.WriteFile(filepath.Join(projectDir, "history"), []byte("not a directory"), 0o644)
+
+	slot := filepath.Join(t.TempDir(), "slot")
+	_ = os.MkdirAll(filepath.Join(slot, "history"), 0o755)
+
+	if err := mgr.AdoptDirectory(projectID, slot); err == nil {
+		t.Fatal("AdoptDirectory() with conflicting entry succeeded, want error")
+	}
+	if data, err := os.ReadFile(filepath.Join(projectDir, "a.txt")); err != nil || string(data) != "a" {
+		t.Errorf("a.txt not restored: %q, %v", data, err)
+	}
+	if _, err := os.Stat(AdoptMarkerPath(slot)); !os.IsNotExist(err) {
+		t.Errorf("adopt marker left after rollback: %v", err)
+	}
+}
+
+func TestAdoptDirectoryInvalidID(t *testing.T) {
+	mgr := NewManager(t.TempDir(), 5, 10, 100.0)
+	if err := mgr.AdoptDirectory("../etc", t.TempDir()); err == nil {
+		t.Error("AdoptDirectory() with invalid ID succeeded, want error")
+	}
+}
@@ -0,0 +1,80 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/validation"
+)
+
+// Reasons a project container was stopped
+const (
+	StopReasonManual = "manual" // container stop action
+	StopReasonIdle   = "idle"   // no session activity for the idle period
+)
+
+// ContainerStop records why a project's container was last stopped
+type ContainerStop struct {
+	Reason    string    `json:"reason"`
+	StoppedAt time.Time `json:"stopped_at"`
+	IdleFor   string    `json:"idle_for,omitempty"` // Inactivity before an idle stop
+}
+
+// containerStopPath returns the path of a project's container stop record
+func (m *Manager) containerStopPath(projectID string) string {
+	return filepath.Join(m.projectsDir, projectID, "container_stop.json")
+}
+
+// GetContainerStop returns why the project's container was stopped, or nil if
+// it has not been stopped since it last started
+func (m *Manager) GetContainerStop(projectID string) (*ContainerStop, error) {
+	if err := validation.ValidateProjectID(projectID); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(m.containerStopPath(projectID))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read container stop record: %w", err)
+	}
+
+	var stop ContainerStop
+	if err := json.Unmarshal(data, &stop); err != nil {
+		return nil, fmt.Errorf("failed to parse container stop record: %w", err)
+	}
+	return &stop, nil
+}
+
+// SaveContainerStop records why the project's container was stopped
+func (m *Manager) SaveContainerStop(projectID string, stop *ContainerStop) error {
+	if err := validation.ValidateProjectID(projectID); err != nil {
+		return err
+	}
+
+	m.projectLocks.Lock(projectID)
+	defer m.projectLocks.Unlock(projectID)
+
+	return m.writeJSON(m.containerStopPath(projectID), stop)
+}
+
+// ClearContainerStop removes the stop record once the container runs again
+func (m *Manager) ClearContainerStop(projectID string) error {
+	if err := validation.ValidateProjectID(projectID); err != nil {
+		return err
+	}
+
+	m.projectLocks.Lock(projectID)
+	defer m.projectLocks.Unlock(projectID)
+
+	if err := os.Remove(m.containerStopPath(projectID)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to clear container stop record: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestContainerStopRecord(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir, 5, 10, 100.0)
+
+	projectID := "550e8400-e29b-41d4-a716-446655440000"
+	_ = os.MkdirAll(filepath.Join(tmpDir, projectID), 0o755)
+
+	stop, err := mgr.GetContainerStop(projectID)
+	if err != nil || stop != nil {
+		t.Fatalf("GetContainerStop() = %v, %v; want nil, nil", stop, err)
+	}
+
+	want := &ContainerStop{Reason: StopReasonIdle, StoppedAt: time.Now().UTC(), IdleFor: "30m0s"}
+	if err := mgr.SaveContainerStop(projectID, want); err != nil {
+		t.Fatalf("SaveContainerStop() error = %v", err)
+	}
+	stop, err = mgr.GetContainerStop(projectID)
+	if err != nil {
+		t.Fatalf("GetContainerStop() error = %v", err)
+	}
+	if stop.Reason != StopReasonIdle || stop.IdleFor != want.IdleFor {
+		t.Errorf("GetContainerStop() = %+v, want %+v", stop, want)
+	}
+
+	if err := mgr.ClearContainerStop(projectID); err != nil {
+		t.Fatalf("ClearContainerStop() error = %v", err)
+	}
+	if err := mgr.ClearContainerStop(projectID); err != nil {
+		t.Errorf("ClearContainerStop() twice error = %v", err)
+	}
+	if stop, _ := mgr.GetContainerStop(projectID); stop != nil {
+		t.Errorf("GetContainerStop() after clear = %+v, want nil", stop)
+	}
+}
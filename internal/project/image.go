@@ -0,0 +1,85 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/validation"
+)
+
+// RepoDockerfilePath is where a project repository keeps its custom image
+// definition, relative to the default workspace. Its directory is the build context.
+const RepoDockerfilePath = ".oubliette/Dockerfile"
+
+// ImageBuild records the custom image last built for a project
+type ImageBuild struct {
+	Image     string    `json:"image"`
+	Hash      string    `json:"hash"`
+	BaseImage string    `json:"base_image"`
+	BuiltAt   time.Time `json:"built_at"`
+}
+
+// Dockerfile locates a project's custom Dockerfile. The repository's
+// .oubliette/Dockerfile (built with its directory as context) takes precedence
+// over a project-level Dockerfile (built without a context).
+// Returns empty strings when the project has no Dockerfile.
+func (m *Manager) Dockerfile(proj *Project) (dockerfile, contextDir string) {
+	if proj.DefaultWorkspaceID != "" {
+		repoDockerfile := filepath.Join(m.GetWorkspacePath(proj.ID, proj.DefaultWorkspaceID), RepoDockerfilePath)
+		if info, err := os.Stat(repoDockerfile); err == nil && info.Mode().IsRegular() {
+			return repoDockerfile, filepath.Dir(repoDockerfile)
+		}
+	}
+
+	projectDockerfile := filepath.Join(m.projectsDir, proj.ID, "Dockerfile")
+	if info, err := os.Stat(projectDockerfile); err == nil && info.Mode().IsRegular() {
+		return projectDockerfile, ""
+	}
+	return "", ""
+}
+
+// imageBuildPath returns the path of a project's image build record
+func (m *Manager) imageBuildPath(projectID string) string {
+	return filepath.Join(m.projectsDir, projectID, "image.json")
+}
+
+// GetImageBuild returns the last custom image built for a project, or nil if none
+func (m *Manager) GetImageBuild(projectID string) (*ImageBuild, error) {
+	if err := validation.ValidateProjectID(projectID); err != nil {
+		return nil, err
+	}
+	return m.readImageBuild(projectID)
+}
+
+func (m *Manager) readImageBuild(projectID string) (*ImageBuild, error) {
+	data, err := os.ReadFile(m.imageBuildPath(projectID))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read image build record: %w", err)
+	}
+
+	var build ImageBuild
+	if err := json.Unmarshal(data, &build); err != nil {
+		return nil, fmt.Errorf("failed to parse image build record: %w", err)
+	}
+	return &build, nil
+}
+
+// SaveImageBuild records the custom image built for a project
+func (m *Manager) SaveImageBuild(projectID string, build *ImageBuild) error {
+	if err := validation.ValidateProjectID(projectID); err != nil {
+		return err
+	}
+
+	m.projectLocks.Lock(projectID)
+	defer m.projectLocks.Unlock(projectID)
+
+	return m.writeJSON(m.imageBuildPath(projectID), build)
+}
@@ -0,0 +1,86 @@
+package project
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDockerfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir, 5, 10, 100.0)
+
+	proj := &Project{
+		ID:                 "550e8400-e29b-41d4-a716-446655440000",
+		DefaultWorkspaceID: "660e8400-e29b-41d4-a716-446655440000",
+	}
+	projectDir := filepath.Join(tmpDir, proj.ID)
+	repoDir := mgr.GetWorkspacePath(proj.ID, proj.DefaultWorkspaceID)
+	if err := os.MkdirAll(filepath.Join(repoDir, ".oubliette"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if dockerfile, _ := mgr.Dockerfile(proj); dockerfile != "" {
+		t.Errorf("Dockerfile() = %q, want none", dockerfile)
+	}
+
+	projectDockerfile := filepath.Join(projectDir, "Dockerfile")
+	_ = os.WriteFile(projectDockerfile, []byte("FROM alpine"), 0o644)
+	dockerfile, contextDir := mgr.Dockerfile(proj)
+	if dockerfile != projectDockerfile || contextDir != "" {
+		t.Errorf("Dockerfile() = (%q, %q), want project Dockerfile without context", dockerfile, contextDir)
+	}
+
+	repoDockerfile := filepath.Join(repoDir, RepoDockerfilePath)
+	_ = os.WriteFile(repoDockerfile, []byte("ARG OUBLIETTE_BASE_IMAGE\nFROM ${OUBLIETTE_BASE_IMAGE}"), 0o644)
+	dockerfile, contextDir = mgr.Dockerfile(proj)
+	if dockerfile != repoDockerfile {
+		t.Errorf("Dockerfile() = %q, want repository Dockerfile %q", dockerfile, repoDockerfile)
+	}
+	if contextDir != filepath.Join(repoDir, ".oubliette") {
+		t.Errorf("context = %q, want .oubliette directory", contextDir)
+	}
+}
+
+func TestImageBuildRecord(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir, 5, 10, 100.0)
+
+	projectID := "550e8400-e29b-41d4-a716-446655440000"
+	projectDir := filepath.Join(tmpDir, projectID)
+	_ = os.MkdirAll(projectDir, 0o755)
+	data, _ := json.Marshal(&Project{ID: projectID, Name: "Test", ImageName: "oubliette-dev:latest"})
+	_ = os.WriteFile(filepath.Join(projectDir, "metadata.json"), data, 0o644)
+	_ = os.WriteFile(filepath.Join(projectDir, "Dockerfile"), []byte("FROM alpine"), 0o644)
+
+	build, err := mgr.GetImageBuild(projectID)
+	if err != nil || build != nil {
+		t.Fatalf("GetImageBuild() = %v, %v; want nil, nil", build, err)
+	}
+
+	want := &ImageBuild{Image: "oubliette-550e8400:0123456789ab", Hash: "0123456789abcdef", BaseImage: "oubliette-dev:latest", BuiltAt: time.Now().UTC()}
+	if err := mgr.SaveImageBuild(projectID, want); err != nil {
+		t.Fatalf("SaveImageBuild() error = %v", err)
+	}
+
+	build, err = mgr.GetImageBuild(projectID)
+	if err != nil {
+		t.Fatalf("GetImageBuild() error = %v", err)
+	}
+	if build.Image != want.Image || build.Hash != want.Hash {
+		t.Errorf("GetImageBuild() = %+v, want %+v", build, want)
+	}
+
+	proj, err := mgr.Get(projectID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if proj.CustomImage != want.Image {
+		t.Errorf("CustomImage = %q, want %q", proj.CustomImage, want.Image)
+	}
+	if proj.ImageName != "oubliette-dev:latest" {
+		t.Errorf("ImageName = %q, want base image", proj.ImageName)
+	}
+}
@@ -18,6 +18,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrNotFound is wrapped by errors for a missing project, workspace or prompt template
+var ErrNotFound = errors.New("not found")
+
 // NewManager creates a new project manager
 func NewManager(projectsDir string, defaultMaxDepth, defaultMaxAgents int, defaultMaxCostUSD float64) *Manager {
 	// Template dir is sibling to projects dir
@@ -33,21 +36,43 @@ func NewManager(projectsDir string, defaultMaxDepth, defaultMaxAgents int, defau
 
 // SetModelRegistry sets the model configuration for the manager
 func (m *Manager) SetModelRegistry(registry *ModelRegistry) {
+	m.configMu.Lock()
+	defer m.configMu.Unlock()
 	m.modelRegistry = registry
 }
 
 // SetConfigDefaults sets the config defaults for the manager
 func (m *Manager) SetConfigDefaults(defaults *config.ConfigDefaultsConfig) {
+	m.configMu.Lock()
+	defer m.configMu.Unlock()
 	m.configDefaults = defaults
 }
 
 // SetContainers sets the container type -> image name mapping
 func (m *Manager) SetContainers(containers map[string]string) {
+	m.configMu.Lock()
+	defer m.configMu.Unlock()
+	m.containers = containers
+}
+
+// ApplyConfig swaps in reloaded models, defaults and container images in one
+// step. Projects created afterwards use them; existing projects keep their
+// config.json.
+func (m *Manager) ApplyConfig(registry *ModelRegistry, defaults config.ConfigDefaultsConfig, containers map[string]string) {
+	m.configMu.Lock()
+	defer m.configMu.Unlock()
+	m.modelRegistry = registry
+	m.configDefaults = &defaults
 	m.containers = containers
+	m.defaultMaxDepth = defaults.Limits.MaxRecursionDepth
+	m.defaultMaxAgents = defaults.Limits.MaxAgentsPerSession
+	m.defaultMaxCostUSD = defaults.Limits.MaxCostUSD
 }
 
 // GetImageNameForType returns the image name for a container type
 func (m *Manager) GetImageNameForType(containerType string) string {
+	m.configMu.RLock()
+	defer m.configMu.RUnlock()
 	if m.containers != nil {
 		if imageName, ok := m.containers[containerType]; ok {
 			return imageName
@@ -62,6 +87,8 @@ func (m *Manager) GetMaxDepth(project *Project) int {
 	if project.RecursionConfig != nil && project.RecursionConfig.MaxDepth != nil {
 		return *project.RecursionConfig.MaxDepth
 	}
+	m.configMu.RLock()
+	defer m.configMu.RUnlock()
 	return m.defaultMaxDepth
 }
 
@@ -70,6 +97,8 @@ func (m *Manager) GetMaxAgents(project *Project) int {
 	if project.RecursionConfig != nil && project.RecursionConfig.MaxAgents != nil {
 		return *project.RecursionConfig.MaxAgents
 	}
+	m.configMu.RLock()
+	defer m.configMu.RUnlock()
 	return m.defaultMaxAgents
 }
 
@@ -78,21 +107,29 @@ func (m *Manager) GetMaxCostUSD(project *Project) float64 {
 	if project.RecursionConfig != nil && project.RecursionConfig.MaxCostUSD != nil {
 		return *project.RecursionConfig.MaxCostUSD
 	}
+	m.configMu.RLock()
+	defer m.configMu.RUnlock()
 	return m.defaultMaxCostUSD
 }
 
 // GetDefaultMaxDepth returns the server default max recursion depth
 func (m *Manager) GetDefaultMaxDepth() int {
+	m.configMu.RLock()
+	defer m.configMu.RUnlock()
 	return m.defaultMaxDepth
 }
 
 // GetDefaultMaxAgents returns the server default max agents per session
 func (m *Manager) GetDefaultMaxAgents() int {
+	m.configMu.RLock()
+	defer m.configMu.RUnlock()
 	return m.defaultMaxAgents
 }
 
 // GetDefaultMaxCostUSD returns the server default max cost in USD
 func (m *Manager) GetDefaultMaxCostUSD() float64 {
+	m.configMu.RLock()
+	defer m.configMu.RUnlock()
 	return m.defaultMaxCostUSD
 }
 
@@ -276,7 +313,7 @@ func (m *Manager) Get(projectID string) (*Project, error) {
 	data, err := os.ReadFile(metadataPath)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
-			return nil, fmt.Errorf("project %s not found", projectID)
+			return nil, fmt.Errorf("project %s %w", projectID, ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to read project metadata: %w", err)
 	}
@@ -286,11 +323,12 @@ func (m *Manager) Get(projectID string) (*Project, error) {
 		return nil, fmt.Errorf("failed to parse project metadata: %w", err)
 	}
 
-	// Check if custom Dockerfile exists
-	dockerfilePath := filepath.Join(m.projectsDir, projectID, "Dockerfile")
-	if _, err := os.Stat(dockerfilePath); err == nil {
+	// Check if custom Dockerfile exists; ImageName stays the base it builds on
+	if dockerfile, _ := m.Dockerfile(&project); dockerfile != "" {
 		project.HasDockerfile = true
-		project.ImageName = fmt.Sprintf("oubliette-%s:latest", projectID[:8])
+		if build, err := m.readImageBuild(projectID); err == nil && build != nil {
+			project.CustomImage = build.Image
+		}
 	}
 
 	return &project, nil
@@ -360,7 +398,7 @@ func (m *Manager) Delete(projectID string) error {
 	projectDir := filepath.Join(m.projectsDir, projectID)
 
 	if _, err := os.Stat(projectDir); errors.Is(err, fs.ErrNotExist) {
-		return fmt.Errorf("project %s not found", projectID)
+		return fmt.Errorf("project %s %w", projectID, ErrNotFound)
 	}
 
 	return os.RemoveAll(projectDir)
@@ -458,6 +496,69 @@ func (m *Manager) CreateWorkspace(projectID, workspaceID, externalID, source str
 	return metadata, nil
 }
 
+// ForkWorkspace creates a new workspace holding a copy of the files currently
+// in sourceID. Symlinks are recreated rather than followed, since agents
+// control the workspace contents.
+func (m *Manager) ForkWorkspace(projectID, sourceID, source string) (*WorkspaceMetadata, error) {
+	if _, err := m.GetWorkspaceMetadata(projectID, sourceID); err != nil {
+		return nil, err
+	}
+
+	workspaceID := uuid.New().String()
+	srcDir := m.GetWorkspacePath(projectID, sourceID)
+	dstDir := m.GetWorkspacePath(projectID, workspaceID)
+
+	err := filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		if relPath == "metadata.json" {
+			return nil
+		}
+		targetPath := filepath.Join(dstDir, relPath)
+
+		switch {
+		case d.IsDir():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return os.MkdirAll(targetPath, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, targetPath)
+		case d.Type().IsRegular():
+			return m.copyFile(path, targetPath)
+		default:
+			// Skip sockets, pipes and devices
+			return nil
+		}
+	})
+	if err != nil {
+		_ = os.RemoveAll(dstDir)
+		return nil, fmt.Errorf("failed to copy workspace %s: %w", sourceID, err)
+	}
+
+	metadata := &WorkspaceMetadata{
+		ID:         workspaceID,
+		CreatedAt:  time.Now(),
+		Source:     source,
+		ForkedFrom: sourceID,
+	}
+	if err := m.saveWorkspaceMetadata(projectID, metadata); err != nil {
+		_ = os.RemoveAll(dstDir)
+		return nil, fmt.Errorf("failed to save workspace metadata: %w", err)
+	}
+	return metadata, nil
+}
+
 // GetWorkspaceMetadata retrieves metadata for a workspace
 func (m *Manager) GetWorkspaceMetadata(projectID, workspaceID string) (*WorkspaceMetadata, error) {
 	if err := validation.ValidateProjectID(projectID); err != nil {
@@ -472,7 +573,7 @@ func (m *Manager) GetWorkspaceMetadata(projectID, workspaceID string) (*Workspac
 	data, err := os.ReadFile(metadataPath)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
-			return nil, fmt.Errorf("workspace %s not found", workspaceID)
+			return nil, fmt.Errorf("workspace %s %w", workspaceID, ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to read workspace metadata: %w", err)
 	}
@@ -680,6 +781,9 @@ func (m *Manager) copyFile(src, dst string) error {
 func (m *Manager) buildCanonicalConfig(projectID, defaultWorkspaceID string, req CreateProjectRequest, createdAt time.Time) *agentconfig.ProjectConfig {
 	// Get defaults
 	defaults := m.getConfigDefaults()
+	m.configMu.RLock()
+	registry := m.modelRegistry
+	m.configMu.RUnlock()
 
 	// Determine container type
 	containerType := req.ContainerType
@@ -695,9 +799,9 @@ func (m *Manager) buildCanonicalConfig(projectID, defaultWorkspaceID string, req
 	// Resolve shorthand model name to full model ID and look up metadata
 	model := modelShorthand
 	var modelDef *config.ModelDefinition
-	if m.modelRegistry != nil {
-		model = m.modelRegistry.ResolveModel(modelShorthand)
-		if def, ok := m.modelRegistry.GetModel(modelShorthand); ok {
+	if registry != nil {
+		model = registry.ResolveModel(modelShorthand)
+		if def, ok := registry.GetModel(modelShorthand); ok {
 			modelDef = &def
 		}
 	}
@@ -788,6 +892,8 @@ func convertCredentialRefs(refs *CredentialRefs) *agentconfig.CredentialRefs {
 
 // getConfigDefaults returns config defaults, using coded defaults if not set
 func (m *Manager) getConfigDefaults() config.ConfigDefaultsConfig {
+	m.configMu.RLock()
+	defer m.configMu.RUnlock()
 	if m.configDefaults != nil {
 		return *m.configDefaults
 	}
@@ -149,8 +149,12 @@ func TestManagerGet(t *testing.T) {
 		if !got.HasDockerfile {
 			t.Error("expected HasDockerfile = true")
 		}
-		if !strings.HasPrefix(got.ImageName, "oubliette-") {
-			t.Errorf("ImageName = %q, expected oubliette-* prefix", got.ImageName)
+		// The configured image stays as the base the custom image builds on
+		if got.ImageName != "oubliette:latest" {
+			t.Errorf("ImageName = %q, want base image oubliette:latest", got.ImageName)
+		}
+		if got.CustomImage != "" {
+			t.Errorf("CustomImage = %q, want empty before a build", got.CustomImage)
 		}
 	})
 
@@ -625,6 +629,48 @@ func TestCopyDir(t *testing.T) {
 	}
 }
 
+func TestManagerForkWorkspace(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir, 5, 10, 100.0)
+	projectID := "550e8400-e29b-41d4-a716-446655440000"
+	sourceID := "770e8400-e29b-41d4-a716-446655440000"
+
+	if _, err := mgr.CreateWorkspace(projectID, sourceID, "ext-123", "test"); err != nil {
+		t.Fatal(err)
+	}
+	srcDir := mgr.GetWorkspacePath(projectID, sourceID)
+	_ = os.MkdirAll(filepath.Join(srcDir, "src"), 0o755)
+	_ = os.WriteFile(filepath.Join(srcDir, "src", "main.go"), []byte("package main"), 0o644)
+	secret := filepath.Join(tmpDir, "secret.txt")
+	_ = os.WriteFile(secret, []byte("outside"), 0o600)
+	if err := os.Symlink(secret, filepath.Join(srcDir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	ws, err := mgr.ForkWorkspace(projectID, sourceID, "fork")
+	if err != nil {
+		t.Fatalf("ForkWorkspace() error = %v", err)
+	}
+	if ws.ID == sourceID || ws.ForkedFrom != sourceID || ws.ExternalID != "" {
+		t.Errorf("metadata = %+v", ws)
+	}
+
+	dstDir := mgr.GetWorkspacePath(projectID, ws.ID)
+	if got, _ := os.ReadFile(filepath.Join(dstDir, "src", "main.go")); string(got) != "package main" {
+		t.Errorf("src/main.go = %q", got)
+	}
+	if target, err := os.Readlink(filepath.Join(dstDir, "link")); err != nil || target != secret {
+		t.Errorf("link = %q, %v, want a symlink to %s", target, err, secret)
+	}
+	if loaded, err := mgr.GetWorkspaceMetadata(projectID, ws.ID); err != nil || loaded.ForkedFrom != sourceID {
+		t.Errorf("GetWorkspaceMetadata() = %+v, %v", loaded, err)
+	}
+
+	if _, err := mgr.ForkWorkspace(projectID, "880e8400-e29b-41d4-a716-446655440000", "fork"); err == nil {
+		t.Error("expected error forking a missing workspace")
+	}
+}
+
 func TestManagerCreate(t *testing.T) {
 	tmpDir := t.TempDir()
 	mgr := NewManager(tmpDir, 5, 10, 100.0)
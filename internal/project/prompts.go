@@ -0,0 +1,146 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/prompt"
+	"github.com/HyphaGroup/oubliette/internal/validation"
+)
+
+// promptsDir returns the directory holding a project's prompt templates
+func (m *Manager) promptsDir(projectID string) string {
+	return filepath.Join(m.projectsDir, projectID, "prompts")
+}
+
+// SavePromptTemplate creates or replaces a named prompt template for a project.
+// The body is validated as a Go template before it is stored.
+func (m *Manager) SavePromptTemplate(projectID string, tmpl *PromptTemplate) error {
+	if err := validation.ValidateProjectID(projectID); err != nil {
+		return err
+	}
+	if err := validation.ValidateTemplateName(tmpl.Name); err != nil {
+		return err
+	}
+	if tmpl.Body == "" {
+		return fmt.Errorf("template body is required")
+	}
+	if err := prompt.Validate(tmpl.Body); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(m.GetProjectDir(projectID)); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("project %s %w", projectID, ErrNotFound)
+	}
+
+	m.projectLocks.Lock(projectID)
+	defer m.projectLocks.Unlock(projectID)
+
+	dir := m.promptsDir(projectID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create prompts directory: %w", err)
+	}
+
+	now := time.Now()
+	tmpl.UpdatedAt = now
+	if existing, err := m.readPromptTemplate(projectID, tmpl.Name); err == nil {
+		tmpl.CreatedAt = existing.CreatedAt
+	} else {
+		tmpl.CreatedAt = now
+	}
+
+	return m.writeJSON(filepath.Join(dir, tmpl.Name+".json"), tmpl)
+}
+
+// GetPromptTemplate retrieves a named prompt template for a project
+func (m *Manager) GetPromptTemplate(projectID, name string) (*PromptTemplate, error) {
+	if err := validation.ValidateProjectID(projectID); err != nil {
+		return nil, err
+	}
+	if err := validation.ValidateTemplateName(name); err != nil {
+		return nil, err
+	}
+
+	m.projectLocks.RLock(projectID)
+	defer m.projectLocks.RUnlock(projectID)
+
+	return m.readPromptTemplate(projectID, name)
+}
+
+// ListPromptTemplates returns all prompt templates for a project sorted by name
+func (m *Manager) ListPromptTemplates(projectID string) ([]*PromptTemplate, error) {
+	if err := validation.ValidateProjectID(projectID); err != nil {
+		return nil, err
+	}
+
+	m.projectLocks.RLock(projectID)
+	defer m.projectLocks.RUnlock(projectID)
+
+	entries, err := os.ReadDir(m.promptsDir(projectID))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []*PromptTemplate{}, nil
+		}
+		return nil, fmt.Errorf("failed to read prompts directory: %w", err)
+	}
+
+	templates := make([]*PromptTemplate, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		tmpl, err := m.readPromptTemplate(projectID, strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			// Skip templates with invalid content
+			continue
+		}
+		templates = append(templates, tmpl)
+	}
+
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// DeletePromptTemplate removes a named prompt template from a project
+func (m *Manager) DeletePromptTemplate(projectID, name string) error {
+	if err := validation.ValidateProjectID(projectID); err != nil {
+		return err
+	}
+	if err := validation.ValidateTemplateName(name); err != nil {
+		return err
+	}
+
+	m.projectLocks.Lock(projectID)
+	defer m.projectLocks.Unlock(projectID)
+
+	err := os.Remove(filepath.Join(m.promptsDir(projectID), name+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("prompt template %s %w", name, ErrNotFound)
+	}
+	return err
+}
+
+// readPromptTemplate loads a template from disk
+// Note: Caller should hold the project lock
+func (m *Manager) readPromptTemplate(projectID, name string) (*PromptTemplate, error) {
+	data, err := os.ReadFile(filepath.Join(m.promptsDir(projectID), name+".json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("prompt template %s %w", name, ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to read prompt template: %w", err)
+	}
+
+	var tmpl PromptTemplate
+	if err := json.Unmarshal(data, &tmpl); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+	return &tmpl, nil
+}
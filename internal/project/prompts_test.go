@@ -0,0 +1,106 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPromptTemplates(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir, 5, 10, 100.0)
+
+	projectID := "550e8400-e29b-41d4-a716-446655440000"
+	if err := os.MkdirAll(filepath.Join(tmpDir, projectID), 0o755); err != nil {
+		t.Fatalf("failed to create project dir: %v", err)
+	}
+
+	t.Run("save and get", func(t *testing.T) {
+		tmpl := &PromptTemplate{Name: "summary", Body: "Summarize changes since {{.LastRunAt}}"}
+		if err := mgr.SavePromptTemplate(projectID, tmpl); err != nil {
+			t.Fatalf("SavePromptTemplate() error = %v", err)
+		}
+		if tmpl.CreatedAt.IsZero() || tmpl.UpdatedAt.IsZero() {
+			t.Error("expected timestamps to be set")
+		}
+
+		got, err := mgr.GetPromptTemplate(projectID, "summary")
+		if err != nil {
+			t.Fatalf("GetPromptTemplate() error = %v", err)
+		}
+		if got.Body != tmpl.Body {
+			t.Errorf("Body = %q, want %q", got.Body, tmpl.Body)
+		}
+	})
+
+	t.Run("overwrite preserves created_at", func(t *testing.T) {
+		before, _ := mgr.GetPromptTemplate(projectID, "summary")
+		tmpl := &PromptTemplate{Name: "summary", Body: "Updated {{.ProjectID}}"}
+		if err := mgr.SavePromptTemplate(projectID, tmpl); err != nil {
+			t.Fatalf("SavePromptTemplate() error = %v", err)
+		}
+		got, _ := mgr.GetPromptTemplate(projectID, "summary")
+		if !got.CreatedAt.Equal(before.CreatedAt) {
+			t.Errorf("CreatedAt changed: %v -> %v", before.CreatedAt, got.CreatedAt)
+		}
+		if got.Body != "Updated {{.ProjectID}}" {
+			t.Errorf("Body = %q, want updated body", got.Body)
+		}
+	})
+
+	t.Run("invalid template body", func(t *testing.T) {
+		err := mgr.SavePromptTemplate(projectID, &PromptTemplate{Name: "broken", Body: "{{if .X}}"})
+		if err == nil {
+			t.Error("expected error for invalid template body")
+		}
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		err := mgr.SavePromptTemplate(projectID, &PromptTemplate{Name: "../escape", Body: "x"})
+		if err == nil {
+			t.Error("expected error for invalid template name")
+		}
+	})
+
+	t.Run("missing project", func(t *testing.T) {
+		err := mgr.SavePromptTemplate("550e8400-e29b-41d4-a716-446655440001", &PromptTemplate{Name: "x", Body: "x"})
+		if err == nil {
+			t.Error("expected error for missing project")
+		}
+	})
+
+	t.Run("list sorted", func(t *testing.T) {
+		if err := mgr.SavePromptTemplate(projectID, &PromptTemplate{Name: "alpha", Body: "a"}); err != nil {
+			t.Fatalf("SavePromptTemplate() error = %v", err)
+		}
+		list, err := mgr.ListPromptTemplates(projectID)
+		if err != nil {
+			t.Fatalf("ListPromptTemplates() error = %v", err)
+		}
+		if len(list) != 2 || list[0].Name != "alpha" || list[1].Name != "summary" {
+			t.Errorf("ListPromptTemplates() = %v, want [alpha summary]", list)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := mgr.DeletePromptTemplate(projectID, "alpha"); err != nil {
+			t.Fatalf("DeletePromptTemplate() error = %v", err)
+		}
+		if _, err := mgr.GetPromptTemplate(projectID, "alpha"); err == nil {
+			t.Error("expected error getting deleted template")
+		}
+		if err := mgr.DeletePromptTemplate(projectID, "alpha"); err == nil {
+			t.Error("expected error deleting missing template")
+		}
+	})
+
+	t.Run("list empty project", func(t *testing.T) {
+		list, err := mgr.ListPromptTemplates("550e8400-e29b-41d4-a716-446655440002")
+		if err != nil {
+			t.Fatalf("ListPromptTemplates() error = %v", err)
+		}
+		if len(list) != 0 {
+			t.Errorf("len(list) = %d, want 0", len(list))
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package project
 
 import (
+	"sync"
 	"time"
 
 	agentconfig "github.com/HyphaGroup/oubliette/internal/agent/config"
@@ -38,6 +39,7 @@ type Project struct {
 	ContainerType      string           `json:"container_type"`
 	Model              string           `json:"model,omitempty"`
 	HasDockerfile      bool             `json:"has_dockerfile"`
+	CustomImage        string           `json:"custom_image,omitempty"` // Last image built from the project Dockerfile
 	ContainerID        string           `json:"container_id,omitempty"`
 	ContainerStatus    string           `json:"container_status,omitempty"`
 	RecursionConfig    *RecursionConfig `json:"recursion_config,omitempty"`
@@ -53,6 +55,17 @@ type WorkspaceMetadata struct {
 	LastSessionAt time.Time `json:"last_session_at,omitempty"`
 	ExternalID    string    `json:"external_id,omitempty"`
 	Source        string    `json:"source,omitempty"`
+	ForkedFrom    string    `json:"forked_from,omitempty"` // Workspace this one was copied from
+}
+
+// PromptTemplate is a reusable, named prompt stored per project.
+// Body is rendered with the prompt package before being sent to an agent.
+type PromptTemplate struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 // CreateProjectRequest contains parameters for creating a project
@@ -94,13 +107,16 @@ type ActiveSessionChecker interface {
 
 // Manager handles project CRUD operations
 type Manager struct {
-	projectsDir       string
-	templateDir       string
+	projectsDir    string
+	templateDir    string
+	projectLocks   ProjectLockMap
+	sessionChecker ActiveSessionChecker
+
+	// Settings from oubliette.jsonc, swapped on config reload
+	configMu          sync.RWMutex
 	defaultMaxDepth   int
 	defaultMaxAgents  int
 	defaultMaxCostUSD float64
-	projectLocks      ProjectLockMap
-	sessionChecker    ActiveSessionChecker
 	modelRegistry     *ModelRegistry
 	configDefaults    *config.ConfigDefaultsConfig
 	containers        map[string]string
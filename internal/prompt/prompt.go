@@ -0,0 +1,102 @@
+package prompt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Data holds the variables available to prompt templates.
+// Fields that don't apply to a given invocation are left empty.
+type Data struct {
+	Now time.Time
+
+	ProjectID   string
+	ProjectName string
+	WorkspaceID string
+	ExternalID  string
+	Source      string
+
+	// Schedule fields, populated only for scheduled executions
+	ScheduleID   string
+	ScheduleName string
+	LastOutput   string     // Output from the target's previous execution
+	LastStatus   string     // Status of the target's previous execution (success, failed, skipped)
+	LastRunAt    *time.Time // When the target last executed
+
+	// Message is the free-form message passed alongside a named template
+	Message string
+
+	// Vars holds caller-supplied variables, accessed as {{.Vars.name}}
+	Vars map[string]string
+}
+
+// ErrInvalidTemplate is wrapped by errors for templates that fail to parse or execute
+var ErrInvalidTemplate = errors.New("invalid prompt template")
+
+// funcs are the helper functions available to prompt templates
+var funcs = template.FuncMap{
+	"default": func(def, value string) string {
+		if value == "" {
+			return def
+		}
+		return value
+	},
+	"truncate": func(n int, s string) string {
+		if n < 0 || len(s) <= n {
+			return s
+		}
+		return s[:n] + "..."
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+// Validate reports syntax errors in a prompt template, and errors such as
+// unknown fields that only show when it is executed, by rendering it against
+// empty data. A template that renders on a first run with nothing set passes.
+func Validate(text string) error {
+	tmpl, err := parse(text)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+	if err := tmpl.Execute(io.Discard, &Data{}); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+	return nil
+}
+
+// Render executes a prompt template against the given data.
+// Text without template actions is returned unchanged.
+func Render(text string, data *Data) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+
+	if data == nil {
+		data = &Data{}
+	}
+	if data.Now.IsZero() {
+		data.Now = time.Now()
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+	return buf.String(), nil
+}
+
+func parse(text string) (*template.Template, error) {
+	return template.New("prompt").Funcs(funcs).Option("missingkey=zero").Parse(text)
+}
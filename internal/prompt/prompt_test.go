@@ -0,0 +1,96 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	lastRun := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	data := &Data{
+		Now:          time.Date(2025, 3, 2, 9, 30, 0, 0, time.UTC),
+		ProjectID:    "proj-1",
+		WorkspaceID:  "ws-1",
+		ExternalID:   "PR-42",
+		ScheduleName: "nightly",
+		LastOutput:   "3 files changed",
+		LastStatus:   "success",
+		LastRunAt:    &lastRun,
+		Vars:         map[string]string{"branch": "main"},
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"plain text unchanged", "Run the checks", "Run the checks"},
+		{"builtin fields", "{{.ScheduleName}} on {{.ProjectID}}/{{.WorkspaceID}}", "nightly on proj-1/ws-1"},
+		{"external id", "Review {{.ExternalID}}", "Review PR-42"},
+		{"time formatting", "{{.Now.Format \"2006-01-02\"}}", "2025-03-02"},
+		{"last run", "since {{.LastRunAt.Format \"15:04\"}}", "since 09:30"},
+		{"previous output", "Previously ({{.LastStatus}}): {{.LastOutput}}", "Previously (success): 3 files changed"},
+		{"vars", "branch={{.Vars.branch}}", "branch=main"},
+		{"missing var is empty", "x={{.Vars.nope}}", "x="},
+		{"default func", "{{default \"none\" .Vars.nope}}", "none"},
+		{"truncate func", "{{truncate 3 .LastOutput}}", "3 f..."},
+		{"conditional", "{{if .LastOutput}}has{{else}}empty{{end}}", "has"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.text, data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderNilData(t *testing.T) {
+	got, err := Render("{{if .LastOutput}}x{{else}}first run{{end}}", nil)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if got != "first run" {
+		t.Errorf("Render() = %q, want %q", got, "first run")
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	if _, err := Render("{{.Unclosed", &Data{}); err == nil {
+		t.Error("expected parse error")
+	}
+	if _, err := Render("{{.NoSuchField}}", &Data{}); err == nil {
+		t.Error("expected execution error for unknown field")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate("Summarize changes since {{.LastRunAt}}"); err != nil {
+		t.Errorf("Validate() unexpected error = %v", err)
+	}
+	err := Validate("{{if .LastOutput}}")
+	if err == nil {
+		t.Fatal("Validate() expected error for unterminated if")
+	}
+	if !strings.Contains(err.Error(), "invalid prompt template") {
+		t.Errorf("error = %v, want 'invalid prompt template'", err)
+	}
+}
+
+func TestValidateExecutionErrors(t *testing.T) {
+	for _, text := range []string{"{{.NoSuchField}}", "{{.Vars.x.y}}", "{{truncate .Message 3}}"} {
+		err := Validate(text)
+		if err == nil || !strings.Contains(err.Error(), "invalid prompt template") {
+			t.Errorf("Validate(%q) error = %v, want invalid prompt template", text, err)
+		}
+	}
+	if err := Validate("{{if .LastRunAt}}since {{.LastRunAt.Format \"15:04\"}}{{end}} {{.Vars.branch}}"); err != nil {
+		t.Errorf("Validate() unexpected error = %v", err)
+	}
+}
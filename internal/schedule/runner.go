@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/HyphaGroup/oubliette/internal/logger"
+	"github.com/HyphaGroup/oubliette/internal/metrics"
 )
 
 // ExecutionFunc is called by the runner to execute a schedule target
@@ -208,6 +209,7 @@ func (r *Runner) recordSkippedExecutions(schedule *Schedule, reason string) {
 			Status:     ExecutionSkipped,
 			Error:      reason,
 		}
+		metrics.RecordScheduleExecution(string(ExecutionSkipped))
 		if err := r.store.RecordExecution(exec); err != nil {
 			logger.Error("Failed to record skipped execution for schedule %s: %v", schedule.ID, err)
 		}
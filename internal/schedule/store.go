@@ -15,6 +15,7 @@ import (
 var (
 	ErrScheduleNotFound = errors.New("schedule not found")
 	ErrInvalidCron      = errors.New("invalid cron expression")
+	ErrTargetNotFound   = errors.New("target not found")
 )
 
 // Store handles schedule persistence
@@ -30,7 +31,7 @@ func NewStore(dataDir string) (*Store, error) {
 
 	dbPath := filepath.Join(dataDir, "schedules.db")
 	// Enable busy timeout for better concurrent access
-	db, err := sql.Open("sqlite", dbPath+"?_busy_timeout=5000")
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -96,6 +97,7 @@ func (s *Store) migrate() error {
 		FOREIGN KEY (schedule_id) REFERENCES schedules(id) ON DELETE CASCADE
 	);
 	CREATE INDEX IF NOT EXISTS idx_executions_schedule ON schedule_executions(schedule_id, executed_at DESC);
+	CREATE INDEX IF NOT EXISTS idx_executions_target ON schedule_executions(target_id, executed_at DESC);
 	`
 	_, err := s.db.Exec(schema)
 	return err
@@ -527,7 +529,7 @@ func (s *Store) UpdateTargetExecution(targetID, sessionID, output string) error
 
 	rows, _ := result.RowsAffected()
 	if rows == 0 {
-		return fmt.Errorf("target not found: %s", targetID)
+		return fmt.Errorf("%w: %s", ErrTargetNotFound, targetID)
 	}
 
 	return nil
@@ -596,6 +598,42 @@ func (s *Store) ListExecutions(scheduleID string, limit int) ([]*Execution, erro
 	return executions, rows.Err()
 }
 
+// GetLastExecution returns the most recent execution for a target, or nil if it has never run
+func (s *Store) GetLastExecution(targetID string) (*Execution, error) {
+	var exec Execution
+	var sessionID, output, errMsg sql.NullString
+	var durationMs sql.NullInt64
+
+	err := s.db.QueryRow(`
+		SELECT id, schedule_id, target_id, session_id, executed_at, status, output, error, duration_ms
+		FROM schedule_executions
+		WHERE target_id = ?
+		ORDER BY executed_at DESC
+		LIMIT 1`, targetID,
+	).Scan(&exec.ID, &exec.ScheduleID, &exec.TargetID, &sessionID, &exec.ExecutedAt, &exec.Status, &output, &errMsg, &durationMs)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last execution: %w", err)
+	}
+
+	if sessionID.Valid {
+		exec.SessionID = sessionID.String
+	}
+	if output.Valid {
+		exec.Output = output.String
+	}
+	if errMsg.Valid {
+		exec.Error = errMsg.String
+	}
+	if durationMs.Valid {
+		exec.DurationMs = durationMs.Int64
+	}
+
+	return &exec, nil
+}
+
 // GetTarget retrieves a single target by ID
 func (s *Store) GetTarget(targetID string) (*ScheduleTarget, error) {
 	var target ScheduleTarget
@@ -607,7 +645,7 @@ func (s *Store) GetTarget(targetID string) (*ScheduleTarget, error) {
 		FROM schedule_targets WHERE id = ?`, targetID,
 	).Scan(&target.ID, &target.ScheduleID, &target.ProjectID, &workspaceID, &sessionID, &lastExecutedAt, &lastOutput)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("target not found: %s", targetID)
+		return nil, fmt.Errorf("%w: %s", ErrTargetNotFound, targetID)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get target: %w", err)
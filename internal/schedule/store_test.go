@@ -376,6 +376,55 @@ func TestStore_UpdateRunTimes(t *testing.T) {
 	}
 }
 
+func TestStore_GetLastExecution(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	sched := &Schedule{
+		Name:           "test",
+		CronExpr:       "0 0 * * *",
+		Prompt:         "p",
+		Enabled:        true,
+		CreatorTokenID: "t",
+		CreatorScope:   "admin",
+		Targets:        []ScheduleTarget{{ProjectID: "p1"}},
+	}
+	if err := store.Create(sched); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	targetID := sched.Targets[0].ID
+
+	last, err := store.GetLastExecution(targetID)
+	if err != nil {
+		t.Fatalf("GetLastExecution() error = %v", err)
+	}
+	if last != nil {
+		t.Errorf("GetLastExecution() = %v, want nil before any run", last)
+	}
+
+	now := time.Now()
+	execs := []*Execution{
+		{ScheduleID: sched.ID, TargetID: targetID, ExecutedAt: now.Add(-2 * time.Hour), Status: ExecutionSuccess, Output: "old"},
+		{ScheduleID: sched.ID, TargetID: targetID, ExecutedAt: now.Add(-time.Hour), Status: ExecutionFailed, Error: "boom"},
+	}
+	for _, e := range execs {
+		if err := store.RecordExecution(e); err != nil {
+			t.Fatalf("RecordExecution() error = %v", err)
+		}
+	}
+
+	last, err = store.GetLastExecution(targetID)
+	if err != nil {
+		t.Fatalf("GetLastExecution() error = %v", err)
+	}
+	if last == nil {
+		t.Fatal("GetLastExecution() = nil, want execution")
+	}
+	if last.Status != ExecutionFailed || last.Error != "boom" {
+		t.Errorf("GetLastExecution() = %+v, want most recent failed execution", last)
+	}
+}
+
 func TestStore_DatabaseFile(t *testing.T) {
 	dir, err := os.MkdirTemp("", "schedule_test")
 	if err != nil {
@@ -35,8 +35,8 @@ type Schedule struct {
 	UpdatedAt       time.Time        `json:"updated_at"`
 	LastRunAt       *time.Time       `json:"last_run_at,omitempty"`
 	NextRunAt       *time.Time       `json:"next_run_at,omitempty"`
-	CreatorTokenID  string           `json:"creator_token_id"` // Token that created this schedule
-	CreatorScope    string           `json:"creator_scope"`    // Scope of creating token for auth
+	CreatorTokenID  string           `json:"-"`             // Token that created this schedule; a bearer secret, never serialized
+	CreatorScope    string           `json:"creator_scope"` // Scope of creating token for auth
 }
 
 // ScheduleTarget represents a project/workspace pair to execute on
@@ -43,6 +43,8 @@ type ActiveSession struct {
 	ProjectID    string
 	WorkspaceID  string // For workspace-based lookup
 	ContainerID  string
+	OwnerTokenID string // Token that started the session, for quota accounting
+	Model        string // Model in "providerID/modelID" format, for metrics
 	Executor     agent.StreamingExecutor
 	EventBuffer  *EventBuffer
 	StartedAt    time.Time
@@ -51,6 +53,13 @@ type ActiveSession struct {
 	Error        error              // Set when Status is Failed
 	mcpSession   *mcp.ServerSession // MCP session for SSE event push
 
+	// Provider failover state of the current turn (see failover.go), reset
+	// when a message is sent. Protected by mu.
+	turnSeq       int    // Incremented per message so stale retries are dropped
+	turnModel     string // Fallback model the turn is on ("" for the session's model)
+	turnRetries   int    // Retries on turnModel
+	turnFallbacks int    // Fallback models tried
+
 	// Caller tool relay fields
 	callerID              string                              // ID of the caller (e.g., "myapp")
 	callerTools           []CallerToolDefinition              // Tools declared by the caller
@@ -79,9 +88,16 @@ func NewActiveSession(sessionID, projectID, workspaceID, containerID string, exe
 
 // SendMessage sends a message to the session and updates activity time
 func (a *ActiveSession) SendMessage(message string) error {
+	return a.SendMessageWithFiles(message, nil)
+}
+
+// SendMessageWithFiles sends a message with file parts to the session. Files
+// are dropped if the executor cannot carry them.
+func (a *ActiveSession) SendMessageWithFiles(message string, files []agent.FilePart) error {
 	a.mu.Lock()
 	a.LastActivity = time.Now()
 	a.Status = ActiveStatusRunning // Message sent means we're processing
+	a.beginTurn()
 	a.mu.Unlock()
 
 	a.executorMu.RLock()
@@ -91,6 +107,9 @@ func (a *ActiveSession) SendMessage(message string) error {
 	if executor == nil {
 		return fmt.Errorf("executor not initialized")
 	}
+	if fs, ok := executor.(agent.FileSender); ok && len(files) > 0 {
+		return fs.SendMessageWithFiles(message, files)
+	}
 	return executor.SendMessage(message)
 }
 
@@ -108,11 +127,22 @@ func (a *ActiveSession) GetExecutor() agent.StreamingExecutor {
 
 // SetExecutor replaces the executor (used when resuming a session with a new connection)
 func (a *ActiveSession) SetExecutor(executor agent.StreamingExecutor) {
+	// The new executor was started with the resumed turn's message
+	a.mu.Lock()
+	a.beginTurn()
+	a.mu.Unlock()
+
 	a.executorMu.Lock()
 	defer a.executorMu.Unlock()
 	a.Executor = executor
 }
 
+// beginTurn resets the provider failover state for a new turn. Caller holds mu.
+func (a *ActiveSession) beginTurn() {
+	a.turnSeq++
+	a.turnModel, a.turnRetries, a.turnFallbacks = "", 0, 0
+}
+
 // CloseExecutor safely closes the executor with write lock protection
 func (a *ActiveSession) CloseExecutor() {
 	a.executorMu.Lock()
@@ -147,6 +177,13 @@ func (a *ActiveSession) GetStatus() ActiveStatus {
 	return a.Status
 }
 
+// GetError returns the error the session failed or timed out with
+func (a *ActiveSession) GetError() error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.Error
+}
+
 // LastActivityTime returns the last activity time
 func (a *ActiveSession) LastActivityTime() time.Time {
 	a.mu.RLock()
@@ -281,15 +318,32 @@ func (a *ActiveSession) NotifyEvent(ctx context.Context, event *agent.StreamEven
 
 // ActiveSessionManager manages active streaming sessions
 type ActiveSessionManager struct {
-	sessions    map[string]*ActiveSession    // by session ID
-	byProject   map[string][]string          // project ID -> session IDs
-	byWorkspace map[string]map[string]string // project ID -> workspace ID -> session ID
-	maxPerProj  int
-	idleTimeout time.Duration
-	mu          sync.RWMutex
-	ctx         context.Context
-	cancel      context.CancelFunc
-}
+	sessions     map[string]*ActiveSession    // by session ID
+	byProject    map[string][]string          // project ID -> session IDs
+	byWorkspace  map[string]map[string]string // project ID -> workspace ID -> session ID
+	maxPerProj   int
+	idleTimeout  time.Duration
+	onUsage      UsageFunc     // Optional model token usage callback
+	onEvent      EventFunc     // Optional buffered event callback
+	onEnd        EndFunc       // Optional callback for sessions that end on their own
+	onAttempt    AttemptFunc   // Optional retryable provider error callback
+	fallbacks    FallbackFunc  // Optional fallback model lookup
+	maxRetries   int           // Retries of a failed turn on each model
+	retryBackoff time.Duration // Wait before the first retry, doubled for each further one
+	mu           sync.RWMutex
+	ctx          context.Context
+	cancel       context.CancelFunc
+}
+
+// UsageFunc receives model token usage reported by a session's events
+type UsageFunc func(sess *ActiveSession, inputTokens, outputTokens int)
+
+// EventFunc receives each event as it is buffered for a session
+type EventFunc func(sess *ActiveSession, event *BufferedEvent)
+
+// EndFunc receives a session that ended without being removed: its agent
+// finished (completed or failed) or it was reaped after idling (timed_out)
+type EndFunc func(sess *ActiveSession, status ActiveStatus)
 
 // NewActiveSessionManager creates a new active session manager
 func NewActiveSessionManager(maxPerProject int, idleTimeout time.Duration) *ActiveSessionManager {
@@ -302,13 +356,15 @@ func NewActiveSessionManager(maxPerProject int, idleTimeout time.Duration) *Acti
 
 	ctx, cancel := context.WithCancel(context.Background())
 	m := &ActiveSessionManager{
-		sessions:    make(map[string]*ActiveSession),
-		byProject:   make(map[string][]string),
-		byWorkspace: make(map[string]map[string]string),
-		maxPerProj:  maxPerProject,
-		idleTimeout: idleTimeout,
-		ctx:         ctx,
-		cancel:      cancel,
+		sessions:     make(map[string]*ActiveSession),
+		byProject:    make(map[string][]string),
+		byWorkspace:  make(map[string]map[string]string),
+		maxPerProj:   maxPerProject,
+		idleTimeout:  idleTimeout,
+		maxRetries:   DefaultTurnRetries,
+		retryBackoff: DefaultRetryBackoff,
+		ctx:          ctx,
+		cancel:       cancel,
 	}
 
 	// Start background cleanup goroutine
@@ -351,6 +407,27 @@ func (m *ActiveSessionManager) Register(sess *ActiveSession) error {
 	return nil
 }
 
+// SetUsageFunc sets the callback invoked when a session reports model token usage
+func (m *ActiveSessionManager) SetUsageFunc(fn UsageFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onUsage = fn
+}
+
+// SetEventFunc sets the callback invoked for each event buffered for a session
+func (m *ActiveSessionManager) SetEventFunc(fn EventFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onEvent = fn
+}
+
+// SetEndFunc sets the callback invoked when a session completes, fails or times out
+func (m *ActiveSessionManager) SetEndFunc(fn EndFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onEnd = fn
+}
+
 // RestartEventCollection starts a new event collection goroutine for a session
 // whose executor has been replaced (e.g., after resume).
 func (m *ActiveSessionManager) RestartEventCollection(sess *ActiveSession) {
@@ -424,6 +501,11 @@ func (m *ActiveSessionManager) Remove(sessionID string) {
 
 // SendMessage sends a message to an active session
 func (m *ActiveSessionManager) SendMessage(sessionID, message string) error {
+	return m.SendMessageWithFiles(sessionID, message, nil)
+}
+
+// SendMessageWithFiles sends a message with file parts to an active session
+func (m *ActiveSessionManager) SendMessageWithFiles(sessionID, message string, files []agent.FilePart) error {
 	sess, ok := m.Get(sessionID)
 	if !ok {
 		return fmt.Errorf("session %s not found or not active", sessionID)
@@ -433,7 +515,7 @@ func (m *ActiveSessionManager) SendMessage(sessionID, message string) error {
 		return fmt.Errorf("session %s is not running (status: %s)", sessionID, sess.GetStatus())
 	}
 
-	return sess.SendMessage(message)
+	return sess.SendMessageWithFiles(message, files)
 }
 
 // GetEvents returns buffered events for a session
@@ -468,6 +550,19 @@ func (m *ActiveSessionManager) ListByProject(projectID string) []*ActiveSession
 	return result
 }
 
+// CountByOwner returns the number of running or idle sessions started by a token
+func (m *ActiveSessionManager) CountByOwner(tokenID string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	count := 0
+	for _, sess := range m.sessions {
+		if sess.OwnerTokenID == tokenID && sess.IsRunning() {
+			count++
+		}
+	}
+	return count
+}
+
 // Count returns the total number of active sessions
 func (m *ActiveSessionManager) Count() int {
 	m.mu.RLock()
@@ -498,20 +593,35 @@ func (m *ActiveSessionManager) Close() {
 
 // collectEvents reads events from the executor and buffers them
 func (m *ActiveSessionManager) collectEvents(sess *ActiveSession) {
+	executor := sess.GetExecutor()
 	defer func() {
 		status := sess.GetStatus()
 		if status == ActiveStatusRunning || status == ActiveStatusIdle {
 			sess.SetStatus(ActiveStatusCompleted, nil)
+			status = ActiveStatusCompleted
+		}
+		// Report the end unless the manager closed, the session was removed
+		// (Remove holds the lock while closing the executor), it timed out
+		// (reported by the reaper) or a resumed executor took over
+		if executor == nil || m.ctx.Err() != nil || status == ActiveStatusTimedOut || sess.GetExecutor() != executor {
+			return
+		}
+		m.mu.RLock()
+		_, registered := m.sessions[sess.SessionID]
+		onEnd := m.onEnd
+		m.mu.RUnlock()
+		if registered && onEnd != nil {
+			onEnd(sess, status)
 		}
 	}()
 
-	executor := sess.GetExecutor()
 	if executor == nil {
 		return
 	}
 
 	var lastAssistantText string
 	var completionNotified bool
+	var awaitingRetry bool // A failed attempt is being resent; its completion does not end the turn
 
 	for {
 		select {
@@ -527,6 +637,25 @@ func (m *ActiveSessionManager) collectEvents(sess *ActiveSession) {
 				return
 			}
 
+			// Retryable provider errors are resent in the background rather
+			// than ending the turn
+			if event.Type == agent.StreamEventError && event.Retryable {
+				if retry := m.planRetry(sess, executor, event); retry != nil {
+					awaitingRetry = true
+					go m.retryTurn(sess, executor, retry)
+					event = retry.event(event)
+				}
+			}
+			if awaitingRetry {
+				if event.Type == agent.StreamEventCompletion {
+					awaitingRetry = false
+					continue
+				}
+				if isWorkEvent(event) {
+					awaitingRetry = false
+				}
+			}
+
 			// Track status transitions
 			if event.Type == agent.StreamEventCompletion {
 				sess.SetStatus(ActiveStatusIdle, nil)
@@ -541,7 +670,23 @@ func (m *ActiveSessionManager) collectEvents(sess *ActiveSession) {
 				lastAssistantText = event.Text
 			}
 
-			sess.EventBuffer.Append(event)
+			if event.InputTokens > 0 || event.OutputTokens > 0 {
+				metrics.RecordModelTokens(sess.ProjectID, sess.Model, event.InputTokens, event.OutputTokens)
+				m.mu.RLock()
+				onUsage := m.onUsage
+				m.mu.RUnlock()
+				if onUsage != nil {
+					onUsage(sess, event.InputTokens, event.OutputTokens)
+				}
+			}
+
+			index := sess.EventBuffer.Append(event)
+			m.mu.RLock()
+			onEvent := m.onEvent
+			m.mu.RUnlock()
+			if onEvent != nil {
+				onEvent(sess, &BufferedEvent{Index: index, Timestamp: time.Now(), Event: event})
+			}
 
 			if !isNotifiableEvent(event) {
 				continue
@@ -639,6 +784,12 @@ func (m *ActiveSessionManager) cleanupIdleSessions() {
 				sessionID, now.Sub(sess.LastActivityTime()), sess.ProjectID, sess.WorkspaceID)
 			sess.SetStatus(ActiveStatusTimedOut, fmt.Errorf("session timed out after %v of inactivity", m.idleTimeout))
 			sess.CloseExecutor()
+			m.mu.RLock()
+			onEnd := m.onEnd
+			m.mu.RUnlock()
+			if onEnd != nil {
+				onEnd(sess, ActiveStatusTimedOut)
+			}
 		}
 		m.Remove(sessionID)
 	}
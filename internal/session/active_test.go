@@ -1,6 +1,7 @@
 package session
 
 import (
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -450,6 +451,135 @@ func TestActiveSessionManager_CountByProject(t *testing.T) {
 	}
 }
 
+// fakeExecutor is a minimal StreamingExecutor driven by the test through its events channel
+type fakeExecutor struct {
+	events chan *agent.StreamEvent
+	errors chan error
+	done   chan struct{}
+}
+
+func newFakeExecutor() *fakeExecutor {
+	return &fakeExecutor{
+		events: make(chan *agent.StreamEvent, 10),
+		errors: make(chan error, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+func (f *fakeExecutor) SendMessage(string) error          { return nil }
+func (f *fakeExecutor) Cancel() error                     { return nil }
+func (f *fakeExecutor) Events() <-chan *agent.StreamEvent { return f.events }
+func (f *fakeExecutor) Errors() <-chan error              { return f.errors }
+func (f *fakeExecutor) Done() <-chan struct{}             { return f.done }
+func (f *fakeExecutor) Wait() (int, error)                { return 0, nil }
+func (f *fakeExecutor) Close() error                      { return nil }
+func (f *fakeExecutor) RuntimeSessionID() string          { return "runtime-1" }
+func (f *fakeExecutor) IsClosed() bool                    { return false }
+
+func TestActiveSessionManager_CountByOwner(t *testing.T) {
+	mgr := NewActiveSessionManager(10, time.Hour)
+	defer mgr.Close()
+
+	for i, owner := range []string{"tok-a", "tok-a", "tok-b"} {
+		sess := NewActiveSession(fmt.Sprintf("sess-%d", i), "proj-1", fmt.Sprintf("ws-%d", i), "container-1", newFakeExecutor())
+		sess.OwnerTokenID = owner
+		_ = mgr.Register(sess)
+	}
+
+	if got := mgr.CountByOwner("tok-a"); got != 2 {
+		t.Errorf("CountByOwner(tok-a) = %d, want 2", got)
+	}
+	if got := mgr.CountByOwner("tok-c"); got != 0 {
+		t.Errorf("CountByOwner(tok-c) = %d, want 0", got)
+	}
+}
+
+func TestActiveSessionManager_UsageFunc(t *testing.T) {
+	mgr := NewActiveSessionManager(10, time.Hour)
+	defer mgr.Close()
+
+	type usage struct {
+		owner         string
+		input, output int
+	}
+	got := make(chan usage, 1)
+	mgr.SetUsageFunc(func(sess *ActiveSession, input, output int) {
+		got <- usage{sess.OwnerTokenID, input, output}
+	})
+
+	exec := newFakeExecutor()
+	sess := NewActiveSession("sess-1", "proj-1", "ws-1", "container-1", exec)
+	sess.OwnerTokenID = "tok-a"
+	if err := mgr.Register(sess); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	exec.events <- &agent.StreamEvent{Type: agent.StreamEventSystem, Subtype: "step-finish", InputTokens: 100, OutputTokens: 20}
+
+	select {
+	case u := <-got:
+		if u != (usage{"tok-a", 100, 20}) {
+			t.Errorf("usage = %+v, want tok-a 100/20", u)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("usage callback not called")
+	}
+}
+
+func TestActiveSessionManager_EndFunc(t *testing.T) {
+	mgr := NewActiveSessionManager(10, time.Minute)
+	defer mgr.Close()
+
+	ended := make(chan ActiveStatus, 4)
+	mgr.SetEndFunc(func(sess *ActiveSession, status ActiveStatus) {
+		ended <- status
+	})
+	start := func(id string) (*ActiveSession, *fakeExecutor) {
+		exec := newFakeExecutor()
+		sess := NewActiveSession(id, "proj-1", "ws-"+id, "container-1", exec)
+		if err := mgr.Register(sess); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		return sess, exec
+	}
+	expect := func(want ActiveStatus) {
+		t.Helper()
+		select {
+		case got := <-ended:
+			if got != want {
+				t.Errorf("ended with %s, want %s", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("end callback not called, want %s", want)
+		}
+	}
+
+	_, exec := start("completed")
+	close(exec.events)
+	expect(ActiveStatusCompleted)
+
+	_, exec = start("failed")
+	exec.errors <- fmt.Errorf("agent crashed")
+	expect(ActiveStatusFailed)
+
+	idle, _ := start("idle")
+	idle.mu.Lock()
+	idle.LastActivity = time.Now().Add(-time.Hour)
+	idle.mu.Unlock()
+	mgr.cleanupIdleSessions()
+	expect(ActiveStatusTimedOut)
+
+	// Sessions removed by a caller are not reported
+	_, exec = start("removed")
+	mgr.Remove("removed")
+	close(exec.events)
+	select {
+	case got := <-ended:
+		t.Errorf("removed session ended with %s, want no callback", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
 func TestActiveSessionManager_Close(t *testing.T) {
 	mgr := NewActiveSessionManager(5, time.Hour)
 
@@ -504,3 +634,31 @@ func TestActiveSessionManager_ConcurrentAccess(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+// fileExecutor records file parts sent through agent.FileSender
+type fileExecutor struct {
+	*fakeExecutor
+	files []agent.FilePart
+}
+
+func (f *fileExecutor) SendMessageWithFiles(_ string, files []agent.FilePart) error {
+	f.files = files
+	return nil
+}
+
+func TestActiveSession_SendMessageWithFiles(t *testing.T) {
+	files := []agent.FilePart{{Mime: "image/png", Filename: "a.png", URL: "data:image/png;base64,AA=="}}
+
+	exec := &fileExecutor{fakeExecutor: newFakeExecutor()}
+	if err := NewActiveSession("sess-1", "proj-1", "ws-1", "container-1", exec).SendMessageWithFiles("look", files); err != nil {
+		t.Fatalf("SendMessageWithFiles() error = %v", err)
+	}
+	if len(exec.files) != 1 || exec.files[0].Filename != "a.png" {
+		t.Errorf("executor files = %+v, want the image", exec.files)
+	}
+
+	// Executors without file support still get the message
+	if err := NewActiveSession("sess-2", "proj-1", "ws-2", "container-1", newFakeExecutor()).SendMessageWithFiles("look", files); err != nil {
+		t.Errorf("SendMessageWithFiles() without FileSender error = %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/HyphaGroup/oubliette/internal/agent"
+	"github.com/HyphaGroup/oubliette/internal/metrics"
 )
 
 /*
@@ -126,6 +127,7 @@ func (b *EventBuffer) Append(event *agent.StreamEvent) int {
 		b.events = b.events[1:]
 		b.startIndex++
 		b.droppedEvents++
+		metrics.RecordEventDropped()
 	}
 	b.events = append(b.events, be)
 	return index
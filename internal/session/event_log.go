@@ -0,0 +1,138 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
+	"github.com/HyphaGroup/oubliette/internal/validation"
+)
+
+// EventLogSuffix is the file suffix of a session's persisted event log,
+// stored next to the session metadata as sessions/<session_id>.events.jsonl
+const EventLogSuffix = ".events.jsonl"
+
+// maxEventLineSize bounds a single persisted event when reading the log back
+const maxEventLineSize = 16 << 20
+
+// IsTranscriptEvent reports whether an event is kept in the persisted event
+// log. Streaming deltas and progress events are dropped; the consolidated
+// messages, tool activity, reasoning and token usage are what a transcript needs.
+func IsTranscriptEvent(event *agent.StreamEvent) bool {
+	switch event.Type {
+	case agent.StreamEventMessage:
+		return event.Text != ""
+	case agent.StreamEventToolCall, agent.StreamEventToolResult,
+		agent.StreamEventCompletion, agent.StreamEventError:
+		return true
+	case agent.StreamEventSystem:
+		return (event.Subtype == "reasoning" && event.Text != "") || event.InputTokens > 0 || event.OutputTokens > 0
+	default:
+		return false
+	}
+}
+
+func (m *Manager) eventLogPath(projectID, sessionID string) string {
+	return filepath.Join(m.sessionsBaseDir, projectID, "sessions", sessionID+EventLogSuffix)
+}
+
+// AppendEvent appends an event to the session's persisted event log.
+// Events that are not part of a transcript are ignored.
+func (m *Manager) AppendEvent(projectID, sessionID string, event *BufferedEvent) error {
+	if event == nil || event.Event == nil || !IsTranscriptEvent(event.Event) {
+		return nil
+	}
+	if err := validation.ValidateProjectID(projectID); err != nil {
+		return err
+	}
+	if err := validation.ValidateSessionID(sessionID); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	f, err := os.OpenFile(m.eventLogPath(projectID, sessionID), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open event log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write event log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	// A finished turn's output and tool calls become searchable
+	if t := event.Event.Type; t == agent.StreamEventCompletion || t == agent.StreamEventError {
+		m.indexSessionLogged(sessionID)
+	}
+	return nil
+}
+
+// LoadEvents returns the persisted events for a session in the order they
+// were recorded. A session without an event log has no events.
+func (m *Manager) LoadEvents(sessionID string) ([]*BufferedEvent, error) {
+	sess, err := m.Load(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(m.eventLogPath(sess.ProjectID, sess.SessionID))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []*BufferedEvent{}, nil
+		}
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	events := []*BufferedEvent{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventLineSize)
+	for scanner.Scan() {
+		var event BufferedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.Event == nil {
+			// A partial last line from a crash is skipped rather than failing the read
+			continue
+		}
+		events = append(events, &event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read event log: %w", err)
+	}
+	return events, nil
+}
+
+// TurnIndexes returns, for each event, the index of the turn it belongs to, or
+// -1 when there are no turns. An event belongs to the turn that was running
+// when it was recorded; a turn also ends once it reports completion, since the
+// runtime can start emitting before the next turn is saved.
+func TurnIndexes(turns []Turn, events []*BufferedEvent) []int {
+	indexes := make([]int, len(events))
+	current := 0
+	completed := false
+	for i, be := range events {
+		if len(turns) == 0 {
+			indexes[i] = -1
+			continue
+		}
+		for current+1 < len(turns) && (completed || !be.Timestamp.Before(turns[current+1].StartedAt)) {
+			current++
+			completed = false
+		}
+		indexes[i] = current
+		if be.Event != nil && be.Event.Type == agent.StreamEventCompletion {
+			completed = true
+		}
+	}
+	return indexes
+}
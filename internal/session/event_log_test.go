@@ -0,0 +1,97 @@
+package session
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
+)
+
+func TestManagerEventLog(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionsDir := filepath.Join(tmpDir, "projects")
+	projectID := "550e8400-e29b-41d4-a716-446655440001"
+	sessionID := "gogol_20250101_120000_abc12345"
+	_ = os.MkdirAll(filepath.Join(sessionsDir, projectID, "sessions"), 0o755)
+
+	mgr := newTestManager(t, sessionsDir)
+	old := time.Now().Add(-48 * time.Hour)
+	if err := mgr.SaveSession(&Session{SessionID: sessionID, ProjectID: projectID, Status: StatusActive, UpdatedAt: old}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no log yet", func(t *testing.T) {
+		events, err := mgr.LoadEvents(sessionID)
+		if err != nil || len(events) != 0 {
+			t.Errorf("LoadEvents() = %v, %v, want no events", events, err)
+		}
+	})
+
+	t.Run("append keeps transcript events", func(t *testing.T) {
+		for i, event := range []*agent.StreamEvent{
+			{Type: agent.StreamEventDelta, Text: "Hel"},
+			{Type: agent.StreamEventMessage, Text: "Hello"},
+			{Type: agent.StreamEventSystem, Subtype: "step-start"},
+			{Type: agent.StreamEventSystem, Subtype: "reasoning"},
+			{Type: agent.StreamEventSystem, Subtype: "reasoning", Text: "thinking"},
+			{Type: agent.StreamEventToolCall, ToolID: "call_1", ToolName: "bash"},
+			{Type: agent.StreamEventSystem, Subtype: "step-finish", InputTokens: 10, OutputTokens: 2},
+			{Type: agent.StreamEventCompletion},
+		} {
+			if err := mgr.AppendEvent(projectID, sessionID, &BufferedEvent{Index: i, Timestamp: time.Now(), Event: event}); err != nil {
+				t.Fatalf("AppendEvent() error = %v", err)
+			}
+		}
+
+		events, err := mgr.LoadEvents(sessionID)
+		if err != nil {
+			t.Fatalf("LoadEvents() error = %v", err)
+		}
+		var indexes []int
+		for _, e := range events {
+			indexes = append(indexes, e.Index)
+		}
+		if len(indexes) != 5 || indexes[0] != 1 || indexes[1] != 4 || events[2].Event.ToolName != "bash" {
+			t.Errorf("persisted event indexes = %v, want [1 4 5 6 7]", indexes)
+		}
+	})
+
+	t.Run("invalid IDs", func(t *testing.T) {
+		event := &BufferedEvent{Event: &agent.StreamEvent{Type: agent.StreamEventCompletion}}
+		if err := mgr.AppendEvent("../escape", sessionID, event); err == nil {
+			t.Error("expected error for invalid project ID")
+		}
+		if err := mgr.AppendEvent(projectID, "../escape", event); err == nil {
+			t.Error("expected error for invalid session ID")
+		}
+	})
+
+	t.Run("add turn", func(t *testing.T) {
+		if err := mgr.AddTurn(sessionID, "follow up"); err != nil {
+			t.Fatalf("AddTurn() error = %v", err)
+		}
+		loaded, _ := mgr.Load(sessionID)
+		if len(loaded.Turns) != 1 || loaded.Turns[0].TurnNumber != 1 || loaded.Turns[0].Prompt != "follow up" {
+			t.Errorf("turns = %+v", loaded.Turns)
+		}
+	})
+
+	t.Run("cleanup removes the log", func(t *testing.T) {
+		loaded, _ := mgr.Load(sessionID)
+		loaded.Status = StatusCompleted
+		loaded.UpdatedAt = old
+		if err := mgr.SaveSession(loaded); err != nil {
+			t.Fatal(err)
+		}
+		if deleted, err := mgr.CleanupOldSessions(projectID, 24*time.Hour); err != nil || deleted != 1 {
+			t.Fatalf("CleanupOldSessions() = %d, %v", deleted, err)
+		}
+		if _, err := os.Stat(mgr.eventLogPath(projectID, sessionID)); !errors.Is(err, fs.ErrNotExist) {
+			t.Error("expected event log to be deleted with the session")
+		}
+	})
+}
@@ -0,0 +1,207 @@
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
+	"github.com/HyphaGroup/oubliette/internal/logger"
+	"github.com/HyphaGroup/oubliette/internal/metrics"
+	"github.com/HyphaGroup/oubliette/internal/validation"
+)
+
+/*
+PROVIDER FAILOVER
+
+A turn that hits a retryable provider error (rate limit, overload such as
+Anthropic's 529) is not ended. collectEvents swallows the completion that
+closes the failed attempt and resends the turn's message in the background:
+
+ 1. Up to DefaultTurnRetries times to the same model, waiting
+    DefaultRetryBackoff and doubling the wait for each further retry.
+ 2. Then to each of the model's fallbacks (config.ModelDefinition.Fallbacks)
+    in order, each with the same retries.
+ 3. Once those are exhausted the error is passed on and the turn fails.
+
+Every error and its outcome is recorded on the turn (Turn.Attempts), and a
+turn answered by a fallback records that model (Turn.Model). The next message
+goes to the session's own model again.
+*/
+
+// Provider retry defaults
+const (
+	DefaultTurnRetries  = 2
+	DefaultRetryBackoff = 10 * time.Second
+)
+
+// FallbackFunc returns the models to fail a session's model over to, in
+// order, in "providerID/modelID" format
+type FallbackFunc func(model string) []string
+
+// AttemptFunc receives each retryable provider error of a session's turn
+type AttemptFunc func(sess *ActiveSession, attempt TurnAttempt)
+
+// SetFallbackFunc sets the lookup of fallback models. Without it a failing
+// turn is only retried on its own model.
+func (m *ActiveSessionManager) SetFallbackFunc(fn FallbackFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fallbacks = fn
+}
+
+// SetAttemptFunc sets the callback invoked for each retryable provider error
+func (m *ActiveSessionManager) SetAttemptFunc(fn AttemptFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onAttempt = fn
+}
+
+// turnRetry is a planned resend of a failed turn
+type turnRetry struct {
+	turn  int    // Turn sequence the retry belongs to
+	model string // Model to resend to ("" for the session's model)
+	delay time.Duration
+}
+
+// planRetry decides how a turn recovers from a retryable provider error,
+// records the attempt, and returns the retry to run, or nil if the turn has
+// exhausted its retries and fallbacks
+func (m *ActiveSessionManager) planRetry(sess *ActiveSession, executor agent.StreamingExecutor, event *agent.StreamEvent) *turnRetry {
+	m.mu.RLock()
+	fallbackFunc, onAttempt := m.fallbacks, m.onAttempt
+	maxRetries, backoff := m.maxRetries, m.retryBackoff
+	m.mu.RUnlock()
+
+	var fallbacks []string
+	if fallbackFunc != nil {
+		fallbacks = fallbackFunc(sess.Model)
+	}
+	_, canRetry := executor.(agent.Retrier)
+
+	attempt := TurnAttempt{At: time.Now(), Error: event.Text, ErrorCode: event.ErrorCode}
+	var retry *turnRetry
+
+	sess.mu.Lock()
+	attempt.Model = sess.turnModel
+	if attempt.Model == "" {
+		attempt.Model = sess.Model
+	}
+	switch {
+	case !canRetry:
+		attempt.Outcome = AttemptGaveUp
+	case sess.turnRetries < maxRetries:
+		retry = &turnRetry{turn: sess.turnSeq, model: sess.turnModel, delay: backoff << sess.turnRetries}
+		sess.turnRetries++
+		attempt.Outcome = AttemptRetry
+		attempt.NextModel = attempt.Model
+	case sess.turnFallbacks < len(fallbacks):
+		sess.turnModel = fallbacks[sess.turnFallbacks]
+		sess.turnFallbacks++
+		sess.turnRetries = 0
+		retry = &turnRetry{turn: sess.turnSeq, model: sess.turnModel, delay: backoff}
+		attempt.Outcome = AttemptFailover
+		attempt.NextModel = sess.turnModel
+	default:
+		attempt.Outcome = AttemptGaveUp
+	}
+	sess.mu.Unlock()
+	attempt.Replaced = retry != nil
+
+	metrics.RecordTurnRetry(attempt.Outcome)
+	if retry != nil {
+		logger.Info("Session %s: provider error on %s (%s), resending to %s in %v",
+			sess.SessionID, displayModel(attempt.Model), describeProviderError(event), displayModel(attempt.NextModel), retry.delay)
+	} else {
+		logger.Error("Session %s: provider error on %s (%s), giving up", sess.SessionID, displayModel(attempt.Model), describeProviderError(event))
+	}
+	if onAttempt != nil {
+		onAttempt(sess, attempt)
+	}
+	return retry
+}
+
+// event describes the retry in the session's event stream
+func (r *turnRetry) event(cause *agent.StreamEvent) *agent.StreamEvent {
+	return &agent.StreamEvent{
+		Type:      agent.StreamEventSystem,
+		Subtype:   "retry",
+		Text:      fmt.Sprintf("%s; resending to %s in %v", describeProviderError(cause), displayModel(r.model), r.delay),
+		ErrorCode: cause.ErrorCode,
+	}
+}
+
+// retryTurn resends a failed turn after its backoff, unless the session has
+// moved on (closed, resumed with a new executor, or sent a new message)
+func (m *ActiveSessionManager) retryTurn(sess *ActiveSession, executor agent.StreamingExecutor, retry *turnRetry) {
+	timer := time.NewTimer(retry.delay)
+	defer timer.Stop()
+	select {
+	case <-m.ctx.Done():
+		return
+	case <-timer.C:
+	}
+
+	sess.mu.Lock()
+	current := sess.turnSeq == retry.turn && (sess.Status == ActiveStatusRunning || sess.Status == ActiveStatusIdle)
+	if current {
+		sess.LastActivity = time.Now()
+	}
+	sess.mu.Unlock()
+	if !current || sess.GetExecutor() != executor {
+		return
+	}
+
+	if err := executor.(agent.Retrier).RetryLastMessage(retry.model); err != nil {
+		logger.Error("Failed to resend turn for session %s: %v", sess.SessionID, err)
+		sess.SetStatus(ActiveStatusFailed, fmt.Errorf("failed to resend turn: %w", err))
+	}
+}
+
+// describeProviderError renders a provider error with its status code
+func describeProviderError(event *agent.StreamEvent) string {
+	text := event.Text
+	if text == "" {
+		text = "provider error"
+	}
+	if event.ErrorCode != 0 {
+		return fmt.Sprintf("%s (%d)", text, event.ErrorCode)
+	}
+	return text
+}
+
+// displayModel names a model in logs, where empty means the runtime's default
+func displayModel(model string) string {
+	if model == "" {
+		return "the session model"
+	}
+	return model
+}
+
+// RecordAttempt records a retryable provider error on the session's current
+// turn. A failover also records the model now answering the turn.
+func (m *Manager) RecordAttempt(sessionID string, attempt TurnAttempt) error {
+	if err := validation.ValidateSessionID(sessionID); err != nil {
+		return err
+	}
+
+	m.sessionLocks.Lock(sessionID)
+	defer m.sessionLocks.Unlock(sessionID)
+
+	sess, err := m.Load(sessionID)
+	if err != nil {
+		return err
+	}
+	if len(sess.Turns) == 0 {
+		return fmt.Errorf("session %s has no turns", sessionID)
+	}
+
+	turn := &sess.Turns[len(sess.Turns)-1]
+	turn.Attempts = append(turn.Attempts, attempt)
+	if attempt.Outcome == AttemptFailover {
+		turn.Model = attempt.NextModel
+	}
+	sess.UpdatedAt = time.Now()
+
+	// Use internal saveSession since we already hold the lock
+	return m.saveSession(sess)
+}
@@ -0,0 +1,223 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
+)
+
+// retryExecutor records resends through agent.Retrier
+type retryExecutor struct {
+	*fakeExecutor
+	retries chan string
+}
+
+func newRetryExecutor() *retryExecutor {
+	return &retryExecutor{fakeExecutor: newFakeExecutor(), retries: make(chan string, 10)}
+}
+
+func (r *retryExecutor) RetryLastMessage(model string) error {
+	r.retries <- model
+	return nil
+}
+
+// newFailoverManager returns a manager that retries once per model without
+// waiting, failing opus over to gpt, and reports attempts on the channel
+func newFailoverManager(t *testing.T, backoff time.Duration) (*ActiveSessionManager, chan TurnAttempt) {
+	t.Helper()
+	mgr := NewActiveSessionManager(10, time.Hour)
+	t.Cleanup(mgr.Close)
+	mgr.maxRetries = 1
+	mgr.retryBackoff = backoff
+
+	attempts := make(chan TurnAttempt, 10)
+	mgr.SetFallbackFunc(func(model string) []string {
+		if model == "anthropic/claude-opus-4-6" {
+			return []string{"openai/gpt-5.1"}
+		}
+		return nil
+	})
+	mgr.SetAttemptFunc(func(_ *ActiveSession, attempt TurnAttempt) { attempts <- attempt })
+	return mgr, attempts
+}
+
+func overloaded() *agent.StreamEvent {
+	return &agent.StreamEvent{Type: agent.StreamEventError, Text: "Overloaded", ErrorCode: 529, Retryable: true}
+}
+
+func receive[T any](t *testing.T, ch <-chan T, what string) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+		var zero T
+		return zero
+	}
+}
+
+func TestActiveSessionManager_Failover(t *testing.T) {
+	mgr, attempts := newFailoverManager(t, time.Millisecond)
+	exec := newRetryExecutor()
+	sess := NewActiveSession("sess-1", "proj-1", "ws-1", "container-1", exec)
+	sess.Model = "anthropic/claude-opus-4-6"
+	if err := mgr.Register(sess); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	steps := []struct {
+		outcome, nextModel, resentTo string
+	}{
+		{AttemptRetry, "anthropic/claude-opus-4-6", ""},
+		{AttemptFailover, "openai/gpt-5.1", "openai/gpt-5.1"},
+		{AttemptRetry, "openai/gpt-5.1", "openai/gpt-5.1"},
+	}
+	for i, step := range steps {
+		exec.events <- overloaded()
+		exec.events <- &agent.StreamEvent{Type: agent.StreamEventCompletion}
+
+		attempt := receive(t, attempts, "attempt")
+		if attempt.Outcome != step.outcome || attempt.NextModel != step.nextModel || attempt.ErrorCode != 529 || !attempt.Replaced {
+			t.Errorf("attempt %d = %+v, want %s to %s", i+1, attempt, step.outcome, step.nextModel)
+		}
+		if model := receive(t, exec.retries, "resend"); model != step.resentTo {
+			t.Errorf("attempt %d resent to %q, want %q", i+1, model, step.resentTo)
+		}
+		if status := sess.GetStatus(); status != ActiveStatusRunning {
+			t.Errorf("attempt %d: status = %q, want running while retrying", i+1, status)
+		}
+	}
+
+	// Retries and fallbacks are exhausted: the error ends the turn
+	exec.events <- overloaded()
+	exec.events <- &agent.StreamEvent{Type: agent.StreamEventCompletion}
+	if attempt := receive(t, attempts, "attempt"); attempt.Outcome != AttemptGaveUp || attempt.Model != "openai/gpt-5.1" || attempt.Replaced {
+		t.Errorf("final attempt = %+v, want gave_up on the fallback", attempt)
+	}
+	waitForStatus(t, sess, ActiveStatusIdle)
+
+	events, _ := sess.GetEvents(-1)
+	var got []string
+	for _, e := range events {
+		got = append(got, string(e.Event.Type)+":"+e.Event.Subtype)
+	}
+	want := "system:retry system:retry system:retry error: completion:"
+	if strings.Join(got, " ") != want {
+		t.Errorf("buffered events = %v, want %s", got, want)
+	}
+	if !strings.Contains(events[1].Event.Text, "Overloaded (529); resending to openai/gpt-5.1") {
+		t.Errorf("retry event text = %q", events[1].Event.Text)
+	}
+
+	t.Run("next message starts on the session model", func(t *testing.T) {
+		if err := sess.SendMessage("again"); err != nil {
+			t.Fatal(err)
+		}
+		exec.events <- overloaded()
+		if attempt := receive(t, attempts, "attempt"); attempt.Outcome != AttemptRetry || attempt.Model != "anthropic/claude-opus-4-6" {
+			t.Errorf("attempt = %+v, want a retry on the session model", attempt)
+		}
+	})
+}
+
+func TestActiveSessionManager_FailoverStaleRetry(t *testing.T) {
+	mgr, attempts := newFailoverManager(t, 50*time.Millisecond)
+	exec := newRetryExecutor()
+	sess := NewActiveSession("sess-1", "proj-1", "ws-1", "container-1", exec)
+	if err := mgr.Register(sess); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	exec.events <- overloaded()
+	receive(t, attempts, "attempt")
+
+	// A new message supersedes the failed one before the backoff ends
+	if err := sess.SendMessage("never mind"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case model := <-exec.retries:
+		t.Errorf("stale turn was resent to %q", model)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestActiveSessionManager_FailoverWithoutRetrier(t *testing.T) {
+	mgr, attempts := newFailoverManager(t, time.Millisecond)
+	exec := newFakeExecutor()
+	sess := NewActiveSession("sess-1", "proj-1", "ws-1", "container-1", exec)
+	if err := mgr.Register(sess); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	exec.events <- overloaded()
+	exec.events <- &agent.StreamEvent{Type: agent.StreamEventCompletion}
+	if attempt := receive(t, attempts, "attempt"); attempt.Outcome != AttemptGaveUp {
+		t.Errorf("attempt = %+v, want gave_up", attempt)
+	}
+	waitForStatus(t, sess, ActiveStatusIdle)
+
+	// Errors that are not transient are never retried
+	exec.events <- &agent.StreamEvent{Type: agent.StreamEventError, Text: "invalid x-api-key", ErrorCode: 401}
+	select {
+	case attempt := <-attempts:
+		t.Errorf("non-retryable error recorded as %+v", attempt)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func waitForStatus(t *testing.T, sess *ActiveSession, want ActiveStatus) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for sess.GetStatus() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("status = %q, want %q", sess.GetStatus(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestManagerRecordAttempt(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionsDir := filepath.Join(tmpDir, "projects")
+	projectID := "550e8400-e29b-41d4-a716-446655440001"
+	_ = os.MkdirAll(filepath.Join(sessionsDir, projectID, "sessions"), 0o755)
+	mgr := newTestManager(t, sessionsDir)
+
+	sessionID := "gogol_20250101_120000_aaaa1111"
+	now := time.Now()
+	saveTestSessions(t, mgr, &Session{
+		SessionID: sessionID, ProjectID: projectID, Status: StatusActive, CreatedAt: now, UpdatedAt: now,
+		Model: "claude-opus-4-6",
+		Turns: []Turn{{TurnNumber: 1, Prompt: "first"}, {TurnNumber: 2, Prompt: "nightly report"}},
+	})
+
+	for _, attempt := range []TurnAttempt{
+		{At: now, Model: "claude-opus-4-6", Error: "Overloaded", ErrorCode: 529, Outcome: AttemptRetry, NextModel: "claude-opus-4-6"},
+		{At: now, Model: "claude-opus-4-6", Error: "Overloaded", ErrorCode: 529, Outcome: AttemptFailover, NextModel: "anthropic/claude-sonnet-4-5"},
+	} {
+		if err := mgr.RecordAttempt(sessionID, attempt); err != nil {
+			t.Fatalf("RecordAttempt() error = %v", err)
+		}
+	}
+
+	sess, err := mgr.Load(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first := sess.Turns[0]; first.Model != "" || len(first.Attempts) != 0 {
+		t.Errorf("earlier turn changed: %+v", first)
+	}
+	last := sess.Turns[1]
+	if last.Model != "anthropic/claude-sonnet-4-5" {
+		t.Errorf("turn Model = %q, want the fallback", last.Model)
+	}
+	if len(last.Attempts) != 2 || last.Attempts[1].Outcome != AttemptFailover || last.Attempts[0].ErrorCode != 529 {
+		t.Errorf("turn Attempts = %+v", last.Attempts)
+	}
+}
@@ -0,0 +1,185 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
+	"github.com/HyphaGroup/oubliette/internal/logger"
+)
+
+// ForkBidirectionalSession creates a new session holding source's conversation
+// through turn and starts streaming it in opts.WorkspaceID.
+//
+// When the runtime implements agent.Forker the runtime session is forked, so
+// the agent keeps its full context. Otherwise the new runtime session is
+// seeded with the earlier prompts and replies prepended to prompt. An empty
+// prompt starts a forked session without sending a message.
+//
+// The new session records source as its parent along with the fork turn, keeps
+// the source's turns through turn, and starts its event log with the events
+// of those turns so its transcript shows the shared history.
+func (m *Manager) ForkBidirectionalSession(ctx context.Context, source *Session, turn int, containerID, prompt string, opts StartOptions) (*Session, agent.StreamingExecutor, error) {
+	if turn < 1 || turn > len(source.Turns) {
+		return nil, nil, fmt.Errorf("turn must be between 1 and %d", len(source.Turns))
+	}
+	workspaceID := opts.WorkspaceID
+	if workspaceID == "" {
+		return nil, nil, fmt.Errorf("workspace_id is required - caller must resolve workspace before forking session")
+	}
+	workingDir := containerWorkingDir(workspaceID, opts.WorkspaceIsolation)
+	runtime := m.runtimeFor(opts)
+
+	events, err := m.LoadEvents(source.SessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var history []*BufferedEvent
+	for i, index := range TurnIndexes(source.Turns, events) {
+		if index >= 0 && index < turn {
+			history = append(history, events[i])
+		}
+	}
+
+	req := &agent.ExecuteRequest{
+		Prompt:         prompt,
+		Files:          opts.Files,
+		ContainerID:    containerID,
+		WorkingDir:     workingDir,
+		ProjectID:      source.ProjectID,
+		Depth:          source.Depth,
+		Model:          opts.Model,
+		AutonomyLevel:  opts.AutonomyLevel,
+		ReasoningLevel: opts.ReasoningLevel,
+		EnabledTools:   opts.ToolsAllowed,
+		DisabledTools:  opts.ToolsDisallowed,
+
+		StreamJSONRPC: true,
+	}
+
+	if forker, ok := runtime.(agent.Forker); ok && source.RuntimeSessionID != "" {
+		forkedID, err := forker.ForkSession(ctx, containerID, workingDir, source.RuntimeSessionID, runtimeUserMessages(source.Turns[:turn]))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to fork runtime session: %w", err)
+		}
+		req.SessionID = forkedID
+	} else {
+		logger.Info("Runtime cannot fork sessions, seeding fork of %s with %d turns", source.SessionID, turn)
+		req.Prompt = forkSeedPrompt(source, history, turn, prompt)
+	}
+
+	now := time.Now()
+	sourceID := source.SessionID
+	sess := &Session{
+		SessionID:       generateSessionID(),
+		ProjectID:       source.ProjectID,
+		WorkspaceID:     workspaceID,
+		ContainerID:     containerID,
+		Status:          StatusActive,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		Turns:           append([]Turn{}, source.Turns[:turn]...),
+		Model:           opts.Model,
+		AutonomyLevel:   opts.AutonomyLevel,
+		ReasoningLevel:  opts.ReasoningLevel,
+		ParentSessionID: &sourceID,
+		ForkedFromTurn:  turn,
+		Depth:           source.Depth,
+		ToolsAllowed:    opts.ToolsAllowed,
+	}
+
+	// Write the shared history before the new session emits its own events
+	for _, be := range history {
+		if err := m.AppendEvent(sess.ProjectID, sess.SessionID, be); err != nil {
+			_ = os.Remove(m.eventLogPath(sess.ProjectID, sess.SessionID))
+			return nil, nil, fmt.Errorf("failed to copy event log: %w", err)
+		}
+	}
+
+	// Start streaming - use background context so executor survives request completion
+	executor, err := runtime.ExecuteStreaming(context.Background(), req)
+	if err != nil {
+		_ = os.Remove(m.eventLogPath(sess.ProjectID, sess.SessionID))
+		return nil, nil, fmt.Errorf("failed to start forked session: %w", err)
+	}
+
+	sess.RuntimeSessionID = executor.RuntimeSessionID()
+	if sess.RuntimeSessionID == "" {
+		sess.RuntimeSessionID = sess.SessionID
+	}
+	if prompt != "" {
+		sess.Turns = append(sess.Turns, Turn{
+			TurnNumber: turn + 1,
+			Prompt:     prompt,
+			StartedAt:  time.Now(),
+			Output: TurnOutput{
+				Text:     "Session forked",
+				ExitCode: 0,
+			},
+		})
+	}
+
+	if err := m.saveSessionLocked(sess); err != nil {
+		_ = executor.Close()
+		return nil, nil, err
+	}
+	// Index the shared history now that the session exists
+	m.indexSessionLogged(sess.SessionID)
+	return sess, executor, nil
+}
+
+// runtimeUserMessages counts the user messages turns left in the runtime
+// session: one per turn with a prompt, plus one for each resend after a
+// provider error that was added alongside the failed message rather than
+// replacing it (sessions recorded before resends replaced)
+func runtimeUserMessages(turns []Turn) int {
+	n := 0
+	for _, t := range turns {
+		if t.Prompt == "" {
+			continue
+		}
+		n++
+		for _, a := range t.Attempts {
+			if (a.Outcome == AttemptRetry || a.Outcome == AttemptFailover) && !a.Replaced {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// forkSeedPrompt builds the first message of a fork on a runtime that cannot
+// fork: the earlier prompts and final replies, then the new prompt
+func forkSeedPrompt(source *Session, history []*BufferedEvent, turn int, prompt string) string {
+	replies := make([]string, turn)
+	for i, index := range TurnIndexes(source.Turns[:turn], history) {
+		event := history[i].Event
+		if index < 0 || event.Type != agent.StreamEventMessage || event.Text == "" {
+			continue
+		}
+		// Skip the runtime's echo of the prompt
+		if isPromptEcho(event.Text, source.Turns[index].Prompt) {
+			continue
+		}
+		replies[index] = event.Text
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "This session continues an earlier conversation (session %s, turns 1-%d). ", source.SessionID, turn)
+	b.WriteString("That conversation follows; carry on from where it ends.\n")
+	for i, t := range source.Turns[:turn] {
+		fmt.Fprintf(&b, "\n## Turn %d\n\nUser:\n%s\n", i+1, strings.TrimSpace(t.Prompt))
+		if replies[i] != "" {
+			fmt.Fprintf(&b, "\nAssistant:\n%s\n", strings.TrimSpace(replies[i]))
+		}
+	}
+	b.WriteString("\n---\n\n")
+	if prompt == "" {
+		prompt = "Reply briefly that you are ready, then wait for the next instruction."
+	}
+	b.WriteString(prompt)
+	return b.String()
+}
@@ -0,0 +1,197 @@
+package session
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
+)
+
+// fakeAgentRuntime records the streaming request it was asked to start
+type fakeAgentRuntime struct {
+	request *agent.ExecuteRequest
+}
+
+func (f *fakeAgentRuntime) ExecuteStreaming(_ context.Context, req *agent.ExecuteRequest) (agent.StreamingExecutor, error) {
+	f.request = req
+	return newFakeExecutor(), nil
+}
+
+func (f *fakeAgentRuntime) Execute(context.Context, *agent.ExecuteRequest) (*agent.ExecuteResponse, error) {
+	return nil, nil
+}
+func (f *fakeAgentRuntime) Ping(context.Context) error { return nil }
+func (f *fakeAgentRuntime) Close() error               { return nil }
+
+// forkingAgentRuntime also forks runtime sessions
+type forkingAgentRuntime struct {
+	fakeAgentRuntime
+	sessionID    string
+	userMessages int
+}
+
+func (f *forkingAgentRuntime) ForkSession(_ context.Context, _, _, sessionID string, userMessages int) (string, error) {
+	f.sessionID, f.userMessages = sessionID, userMessages
+	return "ses_forked", nil
+}
+
+func TestManagerForkBidirectionalSession(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionsDir := filepath.Join(tmpDir, "projects")
+	projectID := "550e8400-e29b-41d4-a716-446655440001"
+	_ = os.MkdirAll(filepath.Join(sessionsDir, projectID, "sessions"), 0o755)
+
+	start := time.Now().Add(-time.Hour)
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+	source := &Session{
+		SessionID:        "gogol_20250101_120000_abc12345",
+		ProjectID:        projectID,
+		WorkspaceID:      "660e8400-e29b-41d4-a716-446655440000",
+		Status:           StatusCompleted,
+		RuntimeSessionID: "ses_source",
+		Depth:            1,
+		Turns: []Turn{
+			{TurnNumber: 1, Prompt: "Write the parser", StartedAt: at(0)},
+			{TurnNumber: 2, Prompt: "Add tests", StartedAt: at(10)},
+			{TurnNumber: 3, Prompt: "Refactor it", StartedAt: at(20)},
+		},
+	}
+
+	mgr := newTestManager(t, sessionsDir)
+	if err := mgr.SaveSession(source); err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range []struct {
+		at    int
+		event *agent.StreamEvent
+	}{
+		{1, &agent.StreamEvent{Type: agent.StreamEventMessage, Text: "Write the parser"}},
+		{2, &agent.StreamEvent{Type: agent.StreamEventMessage, Text: "Parser written"}},
+		{3, &agent.StreamEvent{Type: agent.StreamEventCompletion}},
+		{11, &agent.StreamEvent{Type: agent.StreamEventMessage, Text: "Tests added"}},
+		{12, &agent.StreamEvent{Type: agent.StreamEventCompletion}},
+		{21, &agent.StreamEvent{Type: agent.StreamEventMessage, Text: "Refactored"}},
+	} {
+		be := &BufferedEvent{Index: i, Timestamp: at(e.at), Event: e.event}
+		if err := mgr.AppendEvent(projectID, source.SessionID, be); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts := StartOptions{Model: "claude-sonnet", WorkspaceID: source.WorkspaceID}
+
+	t.Run("runtime without fork seeds the conversation", func(t *testing.T) {
+		rt := &fakeAgentRuntime{}
+		opts := opts
+		opts.RuntimeOverride = rt
+
+		sess, _, err := mgr.ForkBidirectionalSession(context.Background(), source, 2, "container-1", "Use a table instead", opts)
+		if err != nil {
+			t.Fatalf("ForkBidirectionalSession() error = %v", err)
+		}
+
+		prompt := rt.request.Prompt
+		for _, want := range []string{"User:\nWrite the parser", "Assistant:\nParser written", "User:\nAdd tests", "Assistant:\nTests added", "---\n\nUse a table instead"} {
+			if !strings.Contains(prompt, want) {
+				t.Errorf("seed prompt missing %q:\n%s", want, prompt)
+			}
+		}
+		if strings.Contains(prompt, "Refactor") || rt.request.SessionID != "" {
+			t.Errorf("seed prompt includes turns after the fork or resumes a session:\n%s", prompt)
+		}
+
+		loaded, err := mgr.Load(sess.SessionID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded.ParentSessionID == nil || *loaded.ParentSessionID != source.SessionID || loaded.ForkedFromTurn != 2 || loaded.Depth != 1 {
+			t.Errorf("lineage = %v, turn %d, depth %d", loaded.ParentSessionID, loaded.ForkedFromTurn, loaded.Depth)
+		}
+		if len(loaded.Turns) != 3 || loaded.Turns[2].Prompt != "Use a table instead" || loaded.Turns[2].TurnNumber != 3 {
+			t.Errorf("turns = %+v", loaded.Turns)
+		}
+
+		events, _ := mgr.LoadEvents(sess.SessionID)
+		if len(events) != 5 || events[4].Event.Type != agent.StreamEventCompletion {
+			t.Errorf("copied %d events, want the 5 events of turns 1-2", len(events))
+		}
+		if parent, _ := mgr.Load(source.SessionID); len(parent.ChildSessions) != 0 || len(parent.Turns) != 3 {
+			t.Errorf("source session was modified: %+v", parent)
+		}
+	})
+
+	t.Run("forking runtime branches the runtime session", func(t *testing.T) {
+		rt := &forkingAgentRuntime{}
+		opts := opts
+		opts.RuntimeOverride = rt
+
+		sess, _, err := mgr.ForkBidirectionalSession(context.Background(), source, 1, "container-1", "", opts)
+		if err != nil {
+			t.Fatalf("ForkBidirectionalSession() error = %v", err)
+		}
+		if rt.sessionID != "ses_source" || rt.userMessages != 1 {
+			t.Errorf("ForkSession(%q, %d), want ses_source after 1 message", rt.sessionID, rt.userMessages)
+		}
+		if rt.request.SessionID != "ses_forked" || rt.request.Prompt != "" {
+			t.Errorf("request = %+v, want the forked session without a prompt", rt.request)
+		}
+		if len(sess.Turns) != 1 {
+			t.Errorf("turns = %d, want 1 without a new prompt", len(sess.Turns))
+		}
+	})
+
+	t.Run("retried turns count their resends", func(t *testing.T) {
+		rt := &forkingAgentRuntime{}
+		opts := opts
+		opts.RuntimeOverride = rt
+
+		retried := *source
+		retried.Turns = append([]Turn{}, source.Turns...)
+		retried.Turns[0].Attempts = []TurnAttempt{
+			{Error: "overloaded", Outcome: AttemptRetry},
+			{Error: "overloaded", Outcome: AttemptFailover, NextModel: "openai/gpt-5"},
+		}
+		retried.Turns[1].Attempts = []TurnAttempt{{Error: "overloaded", Outcome: AttemptGaveUp}}
+
+		if _, _, err := mgr.ForkBidirectionalSession(context.Background(), &retried, 2, "container-1", "", opts); err != nil {
+			t.Fatalf("ForkBidirectionalSession() error = %v", err)
+		}
+		// Turn 1 was sent three times; giving up sends nothing
+		if rt.userMessages != 4 {
+			t.Errorf("ForkSession() after %d messages, want 4", rt.userMessages)
+		}
+	})
+
+	t.Run("replaced resends are not counted", func(t *testing.T) {
+		rt := &forkingAgentRuntime{}
+		opts := opts
+		opts.RuntimeOverride = rt
+
+		retried := *source
+		retried.Turns = append([]Turn{}, source.Turns...)
+		retried.Turns[0].Attempts = []TurnAttempt{
+			{Error: "overloaded", Outcome: AttemptRetry, Replaced: true},
+			{Error: "overloaded", Outcome: AttemptFailover, NextModel: "openai/gpt-5", Replaced: true},
+		}
+
+		if _, _, err := mgr.ForkBidirectionalSession(context.Background(), &retried, 2, "container-1", "", opts); err != nil {
+			t.Fatalf("ForkBidirectionalSession() error = %v", err)
+		}
+		if rt.userMessages != 2 {
+			t.Errorf("ForkSession() after %d messages, want 2", rt.userMessages)
+		}
+	})
+
+	t.Run("invalid turn", func(t *testing.T) {
+		opts := opts
+		opts.RuntimeOverride = &fakeAgentRuntime{}
+		for _, turn := range []int{0, 4} {
+			if _, _, err := mgr.ForkBidirectionalSession(context.Background(), source, turn, "container-1", "", opts); err == nil {
+				t.Errorf("turn %d: expected error", turn)
+			}
+		}
+	})
+}
@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -21,67 +20,25 @@ import (
 // Manager handles agent session lifecycle
 type Manager struct {
 	sessionsBaseDir string
+	store           *Store
 	agentRuntime    agent.Runtime
 	oublietteMCPURL string
-	// Persistent session index for O(1) lookups
-	persistentIndex *SessionIndex
 	// Per-session locks for thread-safe metadata operations
 	sessionLocks *SessionLockMap
 }
 
-// NewManager creates a new session manager
-// Note: Call LoadIndex() after creation to restore persistent index
-func NewManager(sessionsBaseDir string, agentRuntime agent.Runtime, oublietteMCPURL string) *Manager {
-	// Use parent of sessionsBaseDir for data directory (sessionsBaseDir is typically .../projects)
-	dataDir := filepath.Join(filepath.Dir(sessionsBaseDir), "data")
+// NewManager creates a new session manager backed by store. Session
+// directories under sessionsBaseDir still hold event logs and streaming files.
+func NewManager(sessionsBaseDir string, store *Store, agentRuntime agent.Runtime, oublietteMCPURL string) *Manager {
 	return &Manager{
 		sessionsBaseDir: sessionsBaseDir,
+		store:           store,
 		agentRuntime:    agentRuntime,
 		oublietteMCPURL: oublietteMCPURL,
-		persistentIndex: NewSessionIndex(dataDir),
 		sessionLocks:    NewSessionLockMap(),
 	}
 }
 
-// LoadIndex loads the persistent session index from disk
-// Should be called once at startup
-func (m *Manager) LoadIndex() error {
-	return m.persistentIndex.Load()
-}
-
-// SaveIndex persists the session index to disk
-// Called automatically on session updates, but can be called manually
-func (m *Manager) SaveIndex() error {
-	return m.persistentIndex.Save()
-}
-
-// indexSession adds a session to the persistent index
-func (m *Manager) indexSession(session *Session) {
-	m.persistentIndex.Add(&SessionIndexEntry{
-		SessionID:   session.SessionID,
-		ProjectID:   session.ProjectID,
-		WorkspaceID: session.WorkspaceID,
-		Status:      session.Status,
-	})
-	// Best-effort save - don't fail the operation if save fails
-	_ = m.persistentIndex.Save()
-}
-
-// updateIndexStatus updates the status in the index
-func (m *Manager) updateIndexStatus(sessionID string, status Status) {
-	m.persistentIndex.UpdateStatus(sessionID, status)
-	_ = m.persistentIndex.Save()
-}
-
-// lookupProject returns the project ID for a session (O(1) lookup)
-func (m *Manager) lookupProject(sessionID string) (string, bool) {
-	entry, ok := m.persistentIndex.Get(sessionID)
-	if ok {
-		return entry.ProjectID, true
-	}
-	return "", false
-}
-
 // AgentRuntime returns the agent runtime for direct execution
 func (m *Manager) AgentRuntime() agent.Runtime {
 	return m.agentRuntime
@@ -117,15 +74,7 @@ func (m *Manager) Create(ctx context.Context, projectID, containerID, prompt str
 		ReasoningLevel: opts.ReasoningLevel,
 	}
 
-	// Working directory depends on workspace isolation setting
-	var workingDir string
-	if opts.WorkspaceIsolation {
-		// Isolated mode: /workspace is mounted to workspaces/, so workingDir is /workspace/<uuid>
-		workingDir = filepath.Join("/workspace", workspaceID)
-	} else {
-		// Non-isolated mode: /workspace is mounted to project root
-		workingDir = filepath.Join("/workspace", "workspaces", workspaceID)
-	}
+	workingDir := containerWorkingDir(workspaceID, opts.WorkspaceIsolation)
 
 	// Execute first turn via agent runtime (single-turn, non-interactive)
 	// For interactive streaming sessions, use CreateBidirectionalSession instead
@@ -142,13 +91,7 @@ func (m *Manager) Create(ctx context.Context, projectID, containerID, prompt str
 		DisabledTools: opts.ToolsDisallowed,
 	}
 
-	// Determine which runtime to use (override or manager's default)
-	runtime := m.agentRuntime
-	if opts.RuntimeOverride != nil {
-		if rt, ok := opts.RuntimeOverride.(agent.Runtime); ok {
-			runtime = rt
-		}
-	}
+	runtime := m.runtimeFor(opts)
 
 	resp, err := runtime.Execute(ctx, req)
 	if err != nil {
@@ -181,6 +124,7 @@ func (m *Manager) Create(ctx context.Context, projectID, containerID, prompt str
 	if err := m.saveSessionLocked(session); err != nil {
 		return nil, err
 	}
+	m.indexSessionLogged(session.SessionID)
 
 	return session, nil
 }
@@ -239,88 +183,57 @@ func (m *Manager) Continue(ctx context.Context, sessionID, prompt string) (*Turn
 	if err := m.saveSession(session); err != nil {
 		return nil, err
 	}
+	m.indexSessionLogged(sessionID)
 
 	return &turn, nil
 }
 
 // Load retrieves a session by ID
-// Uses in-memory index for O(1) lookup when available, falls back to directory scan
 func (m *Manager) Load(sessionID string) (*Session, error) {
 	if err := validation.ValidateSessionID(sessionID); err != nil {
 		return nil, err
 	}
 
-	// Try indexed lookup first (O(1))
-	if projectID, ok := m.lookupProject(sessionID); ok {
-		sessionPath := filepath.Join(m.sessionsBaseDir, projectID, "sessions", sessionID+".json")
-		if session, err := m.loadSessionFromFile(sessionPath); err == nil {
-			return session, nil
-		}
-		// Index was stale, remove and fall through to scan
-		m.persistentIndex.Remove(sessionID)
-	}
-
-	// Fall back to directory scan (O(n) - populates index for future lookups)
-	entries, err := os.ReadDir(m.sessionsBaseDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read sessions base directory: %w", err)
+	session, err := m.store.Get(sessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
 	}
-
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-
-		sessionPath := filepath.Join(m.sessionsBaseDir, entry.Name(), "sessions", sessionID+".json")
-		if _, err := os.Stat(sessionPath); err == nil {
-			session, err := m.loadSessionFromFile(sessionPath)
-			if err == nil {
-				// Add to persistent index for future O(1) lookups
-				m.indexSession(session)
-			}
-			return session, err
-		}
-	}
-
-	return nil, fmt.Errorf("session %s not found", sessionID)
+	return session, err
 }
 
-// List returns all sessions for a project
+// List returns all sessions for a project, newest first
 func (m *Manager) List(projectID string, statusFilter *Status) ([]*SessionSummary, error) {
 	if err := validation.ValidateProjectID(projectID); err != nil {
 		return nil, err
 	}
 
-	sessionsDir := filepath.Join(m.sessionsBaseDir, projectID, "sessions")
-
-	entries, err := os.ReadDir(sessionsDir)
-	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			return []*SessionSummary{}, nil
-		}
-		return nil, fmt.Errorf("failed to read sessions directory: %w", err)
+	filter := ListFilter{ProjectID: projectID, Limit: MaxListLimit}
+	if statusFilter != nil {
+		filter.Status = *statusFilter
 	}
 
-	var summaries []*SessionSummary
-	for _, entry := range entries {
-		if !strings.HasSuffix(entry.Name(), ".json") {
-			continue
-		}
-
-		sessionPath := filepath.Join(sessionsDir, entry.Name())
-		session, err := m.loadSessionFromFile(sessionPath)
+	summaries := []*SessionSummary{}
+	for {
+		page, err := m.store.List(filter)
 		if err != nil {
-			continue
+			return nil, err
 		}
-
-		if statusFilter != nil && session.Status != *statusFilter {
-			continue
+		summaries = append(summaries, page.Sessions...)
+		if page.NextCursor == "" {
+			return summaries, nil
 		}
-
-		summaries = append(summaries, session.ToSummary())
+		filter.Cursor = page.NextCursor
 	}
+}
 
-	return summaries, nil
+// ListSessions returns one page of sessions matching filter, newest first
+func (m *Manager) ListSessions(filter ListFilter) (*ListPage, error) {
+	if filter.ProjectID != "" {
+		if err := validation.ValidateProjectID(filter.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+	return m.store.List(filter)
 }
 
 // End marks a session as completed
@@ -342,46 +255,26 @@ func (m *Manager) End(sessionID string) error {
 	session.UpdatedAt = time.Now()
 
 	// Use internal saveSession since we already hold the lock
-	return m.saveSession(session)
+	if err := m.saveSession(session); err != nil {
+		return err
+	}
+	m.indexSessionLogged(sessionID)
+	return nil
 }
 
-// saveSession writes session to disk atomically (internal, assumes caller holds lock)
+// saveSession writes session and its turns to the store (internal, assumes caller holds lock)
 func (m *Manager) saveSession(session *Session) error {
-	sessionsDir := filepath.Join(m.sessionsBaseDir, session.ProjectID, "sessions")
-	sessionPath := filepath.Join(sessionsDir, session.SessionID+".json")
-
-	data, err := json.MarshalIndent(session, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal session: %w", err)
-	}
-
-	tmpPath := sessionPath + ".tmp"
-	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
-		return fmt.Errorf("failed to write session file: %w", err)
-	}
-
-	// Remove extended attributes that might prevent rename on macOS
-	// This handles com.apple.provenance and similar attributes
-	_ = exec.Command("xattr", "-c", tmpPath).Run() // Ignore errors, best effort
-
-	if err := os.Rename(tmpPath, sessionPath); err != nil {
-		return fmt.Errorf("failed to rename session file: %w", err)
-	}
-
-	// Add to persistent index for O(1) lookups
-	m.indexSession(session)
-
-	return nil
+	return m.store.Save(session)
 }
 
-// saveSessionLocked writes session to disk atomically with locking
+// saveSessionLocked writes session to the store with locking
 func (m *Manager) saveSessionLocked(session *Session) error {
 	m.sessionLocks.Lock(session.SessionID)
 	defer m.sessionLocks.Unlock(session.SessionID)
 	return m.saveSession(session)
 }
 
-// loadSessionFromFile reads a session from disk
+// loadSessionFromFile reads a legacy JSON session file
 func (m *Manager) loadSessionFromFile(path string) (*Session, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -431,6 +324,32 @@ func (m *Manager) AddChildSession(parentSessionID, childSessionID string) error
 	return m.saveSession(parent)
 }
 
+// AddTurn records a prompt sent to a running session as a new turn
+func (m *Manager) AddTurn(sessionID, prompt string) error {
+	if err := validation.ValidateSessionID(sessionID); err != nil {
+		return err
+	}
+
+	m.sessionLocks.Lock(sessionID)
+	defer m.sessionLocks.Unlock(sessionID)
+
+	sess, err := m.Load(sessionID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	sess.Turns = append(sess.Turns, Turn{
+		TurnNumber: len(sess.Turns) + 1,
+		Prompt:     prompt,
+		StartedAt:  now,
+	})
+	sess.UpdatedAt = now
+
+	// Use internal saveSession since we already hold the lock
+	return m.saveSession(sess)
+}
+
 // generateSessionID creates a unique session identifier
 func generateSessionID() string {
 	timestamp := time.Now().Format("20060102_150405")
@@ -449,7 +368,7 @@ func GenerateExplorationID() string {
 	return fmt.Sprintf("exp_%s_%s", timestamp, randomHex)
 }
 
-// RecoverStaleSessions scans for sessions that were marked active but are now stale
+// RecoverStaleSessions finds sessions that were marked active but are now stale
 // (e.g., due to server crash). Sessions older than maxAge are marked as failed.
 //
 // RECOVERY ALGORITHM:
@@ -458,23 +377,16 @@ func GenerateExplorationID() string {
 // left in "active" state due to an ungraceful shutdown (crash, kill -9, etc.).
 //
 // Algorithm:
-//  1. Scan all project directories under sessionsBaseDir
-//  2. For each project, scan all session metadata files (*.json)
-//  3. For each session with status="active":
-//     - If UpdatedAt < (now - maxAge), mark as "failed"
+//  1. Query the store for sessions with status="active" and UpdatedAt < (now - maxAge)
 //     - UpdatedAt serves as a heartbeat - active sessions update it periodically
-//  4. Return count of recovered (transitioned to failed) sessions
+//  2. Re-check each one under its lock and mark it "failed"
+//  3. Return count of recovered (transitioned to failed) sessions
 //
 // Why maxAge matters:
 //   - Too short: May mark legitimately running sessions as failed
 //   - Too long: Stale sessions sit around longer before cleanup
 //   - Default: 30 minutes (reasonable for long-running agent tasks)
 //
-// Performance considerations:
-//   - Uses persistent session index for O(1) lookup of active sessions
-//   - Only loads metadata for sessions marked active in index
-//   - Falls back to filesystem scan if index is empty (first run or corruption)
-//
 // Thread safety:
 //   - Safe to call from single goroutine (server startup)
 //   - Acquires per-session locks for modifications
@@ -482,93 +394,30 @@ func (m *Manager) RecoverStaleSessions(maxAge time.Duration) (recovered int, err
 	now := time.Now()
 	cutoff := now.Add(-maxAge)
 
-	// Try index-based recovery first (fast path)
-	activeSessions := m.persistentIndex.GetByStatus(StatusActive)
-	if len(activeSessions) > 0 {
-		for _, sessionID := range activeSessions {
-			entry, ok := m.persistentIndex.Get(sessionID)
-			if !ok {
-				continue
-			}
-
-			// Load session metadata to check UpdatedAt
-			sessionPath := filepath.Join(m.sessionsBaseDir, entry.ProjectID, "sessions", sessionID+".json")
-			session, err := m.loadSessionFromFile(sessionPath)
-			if err != nil {
-				// Session file missing, remove from index
-				m.persistentIndex.Remove(sessionID)
-				continue
-			}
-
-			// Check if session is stale
-			if session.UpdatedAt.Before(cutoff) {
-				m.sessionLocks.Lock(sessionID)
-				session.Status = StatusFailed
-				session.UpdatedAt = now
-				err := m.saveSession(session)
-				m.sessionLocks.Unlock(sessionID)
-				if err != nil {
-					continue
-				}
-				recovered++
-			}
-		}
-		return recovered, nil
-	}
-
-	// Fall back to filesystem scan if index is empty (first run or corruption)
-	entries, err := os.ReadDir(m.sessionsBaseDir)
+	staleIDs, err := m.store.IDs("", StatusActive, false, cutoff)
 	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			return 0, nil
-		}
-		return 0, fmt.Errorf("failed to read sessions base directory: %w", err)
+		return 0, err
 	}
 
-	for _, projectEntry := range entries {
-		if !projectEntry.IsDir() {
-			continue
-		}
-
-		sessionsDir := filepath.Join(m.sessionsBaseDir, projectEntry.Name(), "sessions")
-		sessionFiles, err := os.ReadDir(sessionsDir)
-		if err != nil {
-			continue
-		}
-
-		for _, sessionFile := range sessionFiles {
-			if !strings.HasSuffix(sessionFile.Name(), ".json") {
-				continue
-			}
-
-			sessionPath := filepath.Join(sessionsDir, sessionFile.Name())
-			session, err := m.loadSessionFromFile(sessionPath)
-			if err != nil {
-				continue
-			}
-
-			// Index all sessions for future lookups
-			m.indexSession(session)
-
-			// Check if session was active but is now stale
-			if session.Status == StatusActive && session.UpdatedAt.Before(cutoff) {
-				m.sessionLocks.Lock(session.SessionID)
-				session.Status = StatusFailed
-				session.UpdatedAt = now
-				err := m.saveSession(session)
-				m.sessionLocks.Unlock(session.SessionID)
-				if err != nil {
-					continue
-				}
+	for _, sessionID := range staleIDs {
+		m.sessionLocks.Lock(sessionID)
+		session, err := m.store.Get(sessionID)
+		// The session may have been updated since the query
+		if err == nil && session.Status == StatusActive && session.UpdatedAt.Before(cutoff) {
+			session.Status = StatusFailed
+			session.UpdatedAt = now
+			if err = m.saveSession(session); err == nil {
+				m.indexSessionLogged(sessionID)
 				recovered++
 			}
 		}
+		m.sessionLocks.Unlock(sessionID)
 	}
 
 	return recovered, nil
 }
 
-// CleanupOldSessions deletes session metadata files older than maxAge for a specific project.
+// CleanupOldSessions deletes sessions last updated more than maxAge ago for a specific project.
 // Only deletes sessions that are not in "active" status.
 // Returns the count of deleted sessions.
 func (m *Manager) CleanupOldSessions(projectID string, maxAge time.Duration) (int, error) {
@@ -576,73 +425,39 @@ func (m *Manager) CleanupOldSessions(projectID string, maxAge time.Duration) (in
 		return 0, err
 	}
 
-	sessionsDir := filepath.Join(m.sessionsBaseDir, projectID, "sessions")
-	entries, err := os.ReadDir(sessionsDir)
+	// Never delete active sessions
+	oldIDs, err := m.store.IDs(projectID, StatusActive, true, time.Now().Add(-maxAge))
 	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			return 0, nil
-		}
-		return 0, fmt.Errorf("failed to read sessions directory: %w", err)
+		return 0, err
 	}
 
-	cutoff := time.Now().Add(-maxAge)
 	deleted := 0
-
-	for _, entry := range entries {
-		if !strings.HasSuffix(entry.Name(), ".json") {
-			continue
-		}
-
-		sessionPath := filepath.Join(sessionsDir, entry.Name())
-		session, err := m.loadSessionFromFile(sessionPath)
+	for _, sessionID := range oldIDs {
+		m.sessionLocks.Lock(sessionID)
+		err := m.store.Delete(sessionID)
+		m.sessionLocks.Unlock(sessionID)
 		if err != nil {
-			// Can't parse, skip
-			continue
-		}
-
-		// Never delete active sessions
-		if session.Status == StatusActive {
 			continue
 		}
-
-		// Check if session is old enough to delete
-		if session.UpdatedAt.Before(cutoff) {
-			// Remove from index first
-			m.persistentIndex.Remove(session.SessionID)
-
-			// Delete the file
-			if err := os.Remove(sessionPath); err != nil {
-				continue
-			}
-			deleted++
-		}
+		// Delete its event log with it
+		_ = os.Remove(m.eventLogPath(projectID, sessionID))
+		deleted++
 	}
 
-	// Save index after bulk removal
-	_ = m.persistentIndex.Save()
-
 	return deleted, nil
 }
 
-// CleanupAllOldSessions deletes old session metadata files across all projects.
+// CleanupAllOldSessions deletes old sessions across all projects.
 // Returns a map of project_id -> deleted count.
 func (m *Manager) CleanupAllOldSessions(maxAge time.Duration) (map[string]int, error) {
 	results := make(map[string]int)
 
-	entries, err := os.ReadDir(m.sessionsBaseDir)
+	projectIDs, err := m.store.ProjectIDs()
 	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			return results, nil
-		}
-		return nil, fmt.Errorf("failed to read projects directory: %w", err)
+		return nil, err
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-
-		projectID := entry.Name()
+	for _, projectID := range projectIDs {
 		deleted, err := m.CleanupOldSessions(projectID, maxAge)
 		if err != nil {
 			// Log but continue with other projects
@@ -656,3 +471,95 @@ func (m *Manager) CleanupAllOldSessions(maxAge time.Duration) (map[string]int, e
 
 	return results, nil
 }
+
+// DeleteSession removes a session and its event log
+func (m *Manager) DeleteSession(projectID, sessionID string) error {
+	if err := validation.ValidateSessionID(sessionID); err != nil {
+		return err
+	}
+
+	m.sessionLocks.Lock(sessionID)
+	defer m.sessionLocks.Unlock(sessionID)
+
+	if err := m.store.Delete(sessionID); err != nil {
+		return err
+	}
+	_ = os.Remove(m.eventLogPath(projectID, sessionID))
+	return nil
+}
+
+// DeleteProjectSessions removes all sessions of a deleted project
+func (m *Manager) DeleteProjectSessions(projectID string) error {
+	if err := validation.ValidateProjectID(projectID); err != nil {
+		return err
+	}
+	return m.store.DeleteProject(projectID)
+}
+
+// ImportJSONSessions moves sessions saved as JSON files by earlier versions
+// into the store. Each imported file is renamed to <id>.json.migrated, and the
+// legacy sessions_index.json is removed once every file has been imported.
+// Sessions already in the store are left as they are. Safe to call on every
+// startup; returns the number of sessions imported.
+func (m *Manager) ImportJSONSessions() (int, error) {
+	paths, err := m.jsonSessionFiles()
+	if err != nil {
+		return 0, err
+	}
+
+	imported := 0
+	var failed []string
+	for _, path := range paths {
+		session, err := m.loadSessionFromFile(path)
+		if err == nil && session.SessionID == "" {
+			err = fmt.Errorf("missing session_id")
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", filepath.Base(path), err))
+			continue
+		}
+		if session.ProjectID == "" {
+			session.ProjectID = filepath.Base(filepath.Dir(filepath.Dir(path)))
+		}
+
+		if _, err := m.store.Get(session.SessionID); errors.Is(err, ErrSessionNotFound) {
+			if err := m.saveSessionLocked(session); err != nil {
+				return imported, fmt.Errorf("failed to import %s: %w", session.SessionID, err)
+			}
+			imported++
+		} else if err != nil {
+			return imported, err
+		}
+
+		if err := os.Rename(path, path+".migrated"); err != nil {
+			return imported, fmt.Errorf("failed to mark %s as migrated: %w", filepath.Base(path), err)
+		}
+	}
+
+	if len(failed) > 0 {
+		return imported, fmt.Errorf("failed to import %d session files: %s", len(failed), strings.Join(failed, "; "))
+	}
+
+	// sessionsBaseDir is typically <data>/projects; the index lived in <data>/data
+	indexPath := filepath.Join(filepath.Dir(m.sessionsBaseDir), "data", "sessions_index.json")
+	if err := os.Remove(indexPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return imported, fmt.Errorf("failed to remove legacy session index: %w", err)
+	}
+
+	return imported, nil
+}
+
+// CountJSONSessions returns the number of legacy JSON session files still
+// waiting for ImportJSONSessions, without touching them
+func (m *Manager) CountJSONSessions() (int, error) {
+	paths, err := m.jsonSessionFiles()
+	return len(paths), err
+}
+
+func (m *Manager) jsonSessionFiles() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(m.sessionsBaseDir, "*", "sessions", "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find session files: %w", err)
+	}
+	return paths, nil
+}
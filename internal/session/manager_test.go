@@ -9,13 +9,36 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
 )
 
+// newTestManager returns a manager whose store lives next to sessionsDir
+func newTestManager(t *testing.T, sessionsDir string) *Manager {
+	t.Helper()
+	store, err := NewStore(filepath.Join(filepath.Dir(sessionsDir), "data"))
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return NewManager(sessionsDir, store, nil, "http://localhost:8080/mcp")
+}
+
+// saveTestSessions saves sessions through the manager
+func saveTestSessions(t *testing.T, mgr *Manager, sessions ...*Session) {
+	t.Helper()
+	for _, s := range sessions {
+		if err := mgr.SaveSession(s); err != nil {
+			t.Fatalf("SaveSession(%s) error = %v", s.SessionID, err)
+		}
+	}
+}
+
 func TestNewManager(t *testing.T) {
 	tmpDir := t.TempDir()
 	sessionsDir := filepath.Join(tmpDir, "projects")
 
-	mgr := NewManager(sessionsDir, nil, "http://localhost:8080/mcp")
+	mgr := newTestManager(t, sessionsDir)
 
 	if mgr == nil {
 		t.Fatal("expected non-nil manager")
@@ -23,44 +46,110 @@ func TestNewManager(t *testing.T) {
 	if mgr.sessionsBaseDir != sessionsDir {
 		t.Errorf("sessionsBaseDir = %q, want %q", mgr.sessionsBaseDir, sessionsDir)
 	}
-	if mgr.persistentIndex == nil {
-		t.Error("expected non-nil persistentIndex")
+	if mgr.store == nil {
+		t.Error("expected non-nil store")
 	}
 	if mgr.sessionLocks == nil {
 		t.Error("expected non-nil sessionLocks")
 	}
 }
 
-func TestManagerLoadIndex(t *testing.T) {
+func TestManagerImportJSONSessions(t *testing.T) {
 	tmpDir := t.TempDir()
-	// NewManager uses filepath.Dir(sessionsBaseDir) as parent, then adds "data"
-	// So if sessionsBaseDir = tmpDir/projects, dataDir = tmpDir/data
 	sessionsDir := filepath.Join(tmpDir, "projects")
+	projectID := "550e8400-e29b-41d4-a716-446655440001"
+	sessDir := filepath.Join(sessionsDir, projectID, "sessions")
 	dataDir := filepath.Join(tmpDir, "data")
+	_ = os.MkdirAll(sessDir, 0o755)
 	_ = os.MkdirAll(dataDir, 0o755)
 
-	// Create index file with some entries (note: file format is array not map)
-	indexData := []*SessionIndexEntry{
-		{SessionID: "gogol_20250101_120000_abc12345", ProjectID: "550e8400-e29b-41d4-a716-446655440001", Status: StatusActive},
-		{SessionID: "gogol_20250101_120001_def67890", ProjectID: "550e8400-e29b-41d4-a716-446655440002", Status: StatusCompleted},
+	parentID := "gogol_20250101_120000_abc12345"
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	legacy := []*Session{
+		{
+			SessionID: parentID, ProjectID: projectID, WorkspaceID: "660e8400-e29b-41d4-a716-446655440001",
+			Status: StatusCompleted, CreatedAt: created, UpdatedAt: created.Add(time.Minute), Model: "claude-sonnet",
+			ChildSessions: []string{"gogol_20250101_120001_def67890"}, TaskContext: map[string]interface{}{"ticket": "PAY-1"},
+			TotalCost: Cost{InputTokens: 10, OutputTokens: 20},
+			Turns: []Turn{
+				{TurnNumber: 1, Prompt: "Fix the bug", StartedAt: created, CompletedAt: created.Add(time.Second), Output: TurnOutput{Text: "Fixed"}},
+				{TurnNumber: 2, Prompt: "Add a test", StartedAt: created.Add(time.Minute)},
+			},
+		},
+		{SessionID: "gogol_20250101_120001_def67890", ProjectID: projectID, Status: StatusActive, ParentSessionID: &parentID, Depth: 1, CreatedAt: created},
+	}
+	for _, s := range legacy {
+		data, _ := json.MarshalIndent(s, "", "  ")
+		_ = os.WriteFile(filepath.Join(sessDir, s.SessionID+".json"), data, 0o644)
+	}
+	_ = os.WriteFile(filepath.Join(sessDir, parentID+EventLogSuffix), []byte("{}\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dataDir, "sessions_index.json"), []byte("[]"), 0o644)
+
+	mgr := newTestManager(t, sessionsDir)
+
+	if pending, err := mgr.CountJSONSessions(); err != nil || pending != 2 {
+		t.Fatalf("CountJSONSessions() = %d, %v, want 2", pending, err)
+	}
+	if _, err := os.Stat(filepath.Join(sessDir, parentID+".json")); err != nil {
+		t.Fatalf("CountJSONSessions() touched the session files: %v", err)
 	}
-	indexBytes, _ := json.Marshal(indexData)
-	_ = os.WriteFile(filepath.Join(dataDir, "sessions_index.json"), indexBytes, 0o644)
 
-	mgr := NewManager(sessionsDir, nil, "http://localhost:8080/mcp")
+	imported, err := mgr.ImportJSONSessions()
+	if err != nil {
+		t.Fatalf("ImportJSONSessions() error = %v", err)
+	}
+	if imported != 2 {
+		t.Errorf("imported = %d, want 2", imported)
+	}
+	if pending, err := mgr.CountJSONSessions(); err != nil || pending != 0 {
+		t.Errorf("CountJSONSessions() after import = %d, %v, want 0", pending, err)
+	}
 
-	if err := mgr.LoadIndex(); err != nil {
-		t.Fatalf("LoadIndex() error = %v", err)
+	loaded, err := mgr.Load(parentID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Turns) != 2 || loaded.Turns[0].Output.Text != "Fixed" || !loaded.Turns[1].StartedAt.Equal(created.Add(time.Minute)) {
+		t.Errorf("Turns = %+v", loaded.Turns)
+	}
+	if loaded.Model != "claude-sonnet" || loaded.TotalCost.OutputTokens != 20 || loaded.TaskContext["ticket"] != "PAY-1" || len(loaded.ChildSessions) != 1 {
+		t.Errorf("loaded = %+v", loaded)
+	}
+	if child, _ := mgr.Load("gogol_20250101_120001_def67890"); child == nil || child.ParentSessionID == nil || *child.ParentSessionID != parentID {
+		t.Errorf("child lineage not imported: %+v", child)
 	}
 
-	// Verify entries were loaded
-	entry, ok := mgr.persistentIndex.Get("gogol_20250101_120000_abc12345")
-	if !ok {
-		t.Error("expected to find session in index")
+	if _, err := os.Stat(filepath.Join(sessDir, parentID+".json.migrated")); err != nil {
+		t.Errorf("session file not marked as migrated: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(sessDir, parentID+EventLogSuffix)); err != nil {
+		t.Errorf("event log should be left in place: %v", err)
 	}
-	if entry.ProjectID != "550e8400-e29b-41d4-a716-446655440001" {
-		t.Errorf("session ProjectID = %q, want %q", entry.ProjectID, "550e8400-e29b-41d4-a716-446655440001")
+	if _, err := os.Stat(filepath.Join(dataDir, "sessions_index.json")); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("expected legacy session index to be removed")
 	}
+
+	t.Run("store wins over a leftover file", func(t *testing.T) {
+		loaded.Status = StatusFailed
+		saveTestSessions(t, mgr, loaded)
+		data, _ := json.Marshal(legacy[0])
+		_ = os.WriteFile(filepath.Join(sessDir, parentID+".json"), data, 0o644)
+
+		imported, err := mgr.ImportJSONSessions()
+		if err != nil || imported != 0 {
+			t.Fatalf("ImportJSONSessions() = %d, %v, want 0", imported, err)
+		}
+		if s, _ := mgr.Load(parentID); s.Status != StatusFailed {
+			t.Errorf("Status = %q, want the stored %q", s.Status, StatusFailed)
+		}
+	})
+
+	t.Run("unreadable file", func(t *testing.T) {
+		_ = os.WriteFile(filepath.Join(sessDir, "gogol_20250101_130000_00000000.json"), []byte("not json"), 0o644)
+		if _, err := mgr.ImportJSONSessions(); err == nil {
+			t.Error("expected error for unreadable session file")
+		}
+	})
 }
 
 func TestManagerSaveSession(t *testing.T) {
@@ -73,7 +162,7 @@ func TestManagerSaveSession(t *testing.T) {
 	sessDir := filepath.Join(sessionsDir, projectID, "sessions")
 	_ = os.MkdirAll(sessDir, 0o755)
 
-	mgr := NewManager(sessionsDir, nil, "http://localhost:8080/mcp")
+	mgr := newTestManager(t, sessionsDir)
 
 	session := &Session{
 		SessionID:   sessionID,
@@ -91,19 +180,16 @@ func TestManagerSaveSession(t *testing.T) {
 		t.Fatalf("SaveSession() error = %v", err)
 	}
 
-	// Verify file was created
-	sessionPath := filepath.Join(sessDir, sessionID+".json")
-	if _, err := os.Stat(sessionPath); err != nil {
-		t.Errorf("session file not created: %v", err)
+	// Verify session was stored with its turns
+	stored, err := mgr.store.Get(sessionID)
+	if err != nil {
+		t.Fatalf("store.Get() error = %v", err)
 	}
-
-	// Verify session was indexed
-	entry, ok := mgr.persistentIndex.Get(sessionID)
-	if !ok {
-		t.Error("session not added to index")
+	if stored.ProjectID != projectID {
+		t.Errorf("stored ProjectID = %q, want %q", stored.ProjectID, projectID)
 	}
-	if entry.ProjectID != projectID {
-		t.Errorf("indexed ProjectID = %q, want %q", entry.ProjectID, projectID)
+	if len(stored.Turns) != 1 || stored.Turns[0].Prompt != "Hello" {
+		t.Errorf("stored Turns = %+v", stored.Turns)
 	}
 }
 
@@ -125,10 +211,8 @@ func TestManagerLoad(t *testing.T) {
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
-	sessionData, _ := json.Marshal(session)
-	_ = os.WriteFile(filepath.Join(sessDir, sessionID+".json"), sessionData, 0o644)
-
-	mgr := NewManager(sessionsDir, nil, "http://localhost:8080/mcp")
+	mgr := newTestManager(t, sessionsDir)
+	saveTestSessions(t, mgr, session)
 
 	t.Run("load existing session", func(t *testing.T) {
 		loaded, err := mgr.Load(sessionID)
@@ -143,17 +227,6 @@ func TestManagerLoad(t *testing.T) {
 		}
 	})
 
-	t.Run("load indexed session", func(t *testing.T) {
-		// Session should now be indexed - reload should be O(1)
-		loaded, err := mgr.Load(sessionID)
-		if err != nil {
-			t.Fatalf("Load() cached error = %v", err)
-		}
-		if loaded.SessionID != sessionID {
-			t.Errorf("SessionID = %q, want %q", loaded.SessionID, sessionID)
-		}
-	})
-
 	t.Run("load non-existent session", func(t *testing.T) {
 		_, err := mgr.Load("gogol_20250101_000000_00000000")
 		if err == nil {
@@ -184,12 +257,8 @@ func TestManagerList(t *testing.T) {
 		{SessionID: "gogol_20250101_120000_ccc33333", ProjectID: projectID, Status: StatusFailed, CreatedAt: time.Now()},
 	}
 
-	for _, s := range sessions {
-		data, _ := json.Marshal(s)
-		_ = os.WriteFile(filepath.Join(sessDir, s.SessionID+".json"), data, 0o644)
-	}
-
-	mgr := NewManager(sessionsDir, nil, "http://localhost:8080/mcp")
+	mgr := newTestManager(t, sessionsDir)
+	saveTestSessions(t, mgr, sessions...)
 
 	t.Run("list all sessions", func(t *testing.T) {
 		list, err := mgr.List(projectID, nil)
@@ -249,10 +318,8 @@ func TestManagerEnd(t *testing.T) {
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	data, _ := json.Marshal(session)
-	_ = os.WriteFile(filepath.Join(sessDir, sessionID+".json"), data, 0o644)
-
-	mgr := NewManager(sessionsDir, nil, "http://localhost:8080/mcp")
+	mgr := newTestManager(t, sessionsDir)
+	saveTestSessions(t, mgr, session)
 
 	t.Run("end active session", func(t *testing.T) {
 		err := mgr.End(sessionID)
@@ -309,12 +376,8 @@ func TestManagerAddChildSession(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	parentData, _ := json.Marshal(parent)
-	childData, _ := json.Marshal(child)
-	_ = os.WriteFile(filepath.Join(sessDir, parentID+".json"), parentData, 0o644)
-	_ = os.WriteFile(filepath.Join(sessDir, childID+".json"), childData, 0o644)
-
-	mgr := NewManager(sessionsDir, nil, "http://localhost:8080/mcp")
+	mgr := newTestManager(t, sessionsDir)
+	saveTestSessions(t, mgr, parent, child)
 
 	t.Run("add valid child", func(t *testing.T) {
 		err := mgr.AddChildSession(parentID, childID)
@@ -338,8 +401,7 @@ func TestManagerAddChildSession(t *testing.T) {
 			ProjectID: projectID,
 			Depth:     5, // Wrong depth
 		}
-		wrongDepthData, _ := json.Marshal(wrongDepth)
-		_ = os.WriteFile(filepath.Join(sessDir, wrongDepthID+".json"), wrongDepthData, 0o644)
+		saveTestSessions(t, mgr, wrongDepth)
 
 		err := mgr.AddChildSession(parentID, wrongDepthID)
 		if err == nil {
@@ -366,14 +428,10 @@ func TestManagerRecoverStaleSessions(t *testing.T) {
 		{SessionID: "gogol_20250101_130000_ddd44444", ProjectID: projectID, Status: StatusCompleted, UpdatedAt: staleTime},
 	}
 
-	for _, s := range sessions {
-		data, _ := json.Marshal(s)
-		_ = os.WriteFile(filepath.Join(sessDir, s.SessionID+".json"), data, 0o644)
-	}
-
-	mgr := NewManager(sessionsDir, nil, "http://localhost:8080/mcp")
+	mgr := newTestManager(t, sessionsDir)
+	saveTestSessions(t, mgr, sessions...)
 
-	t.Run("recover via filesystem scan", func(t *testing.T) {
+	t.Run("recover stale sessions", func(t *testing.T) {
 		recovered, err := mgr.RecoverStaleSessions(time.Hour)
 		if err != nil {
 			t.Fatalf("RecoverStaleSessions() error = %v", err)
@@ -401,22 +459,13 @@ func TestManagerRecoverStaleSessions(t *testing.T) {
 		}
 	})
 
-	t.Run("recover via index", func(t *testing.T) {
-		// Reset stale sessions to active
-		for _, id := range []string{"gogol_20250101_100000_aaa11111", "gogol_20250101_110000_bbb22222"} {
-			s, _ := mgr.Load(id)
-			s.Status = StatusActive
-			s.UpdatedAt = staleTime
-			_ = mgr.SaveSession(s)
-		}
-
-		// Index is now populated - recovery should use fast path
+	t.Run("recovered sessions are not recovered again", func(t *testing.T) {
 		recovered, err := mgr.RecoverStaleSessions(time.Hour)
 		if err != nil {
 			t.Fatalf("RecoverStaleSessions() error = %v", err)
 		}
-		if recovered != 2 {
-			t.Errorf("recovered = %d, want 2", recovered)
+		if recovered != 0 {
+			t.Errorf("recovered = %d, want 0", recovered)
 		}
 	})
 }
@@ -438,10 +487,8 @@ func TestManagerConcurrentSave(t *testing.T) {
 		UpdatedAt: time.Now(),
 		Turns:     []Turn{},
 	}
-	data, _ := json.Marshal(session)
-	_ = os.WriteFile(filepath.Join(sessDir, sessionID+".json"), data, 0o644)
-
-	mgr := NewManager(sessionsDir, nil, "http://localhost:8080/mcp")
+	mgr := newTestManager(t, sessionsDir)
+	saveTestSessions(t, mgr, session)
 
 	// Run concurrent saves
 	var wg sync.WaitGroup
@@ -457,13 +504,13 @@ func TestManagerConcurrentSave(t *testing.T) {
 	}
 	wg.Wait()
 
-	// Verify file is valid JSON
+	// Verify the session is still readable
 	loaded, err := mgr.Load(sessionID)
 	if err != nil {
 		t.Fatalf("Load after concurrent saves error = %v", err)
 	}
 	// We can't guarantee turn count due to race conditions in reads,
-	// but file should be valid and have at least some turns
+	// but the session should be intact and have at least some turns
 	if len(loaded.Turns) == 0 {
 		t.Error("expected at least some turns after concurrent saves")
 	}
@@ -501,7 +548,7 @@ func TestLoadSessionFromFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	sessionsDir := filepath.Join(tmpDir, "projects")
 
-	mgr := NewManager(sessionsDir, nil, "http://localhost:8080/mcp")
+	mgr := newTestManager(t, sessionsDir)
 
 	t.Run("valid file", func(t *testing.T) {
 		session := &Session{
@@ -540,67 +587,6 @@ func TestLoadSessionFromFile(t *testing.T) {
 	})
 }
 
-func TestIndexSessionAndLookup(t *testing.T) {
-	tmpDir := t.TempDir()
-	sessionsDir := filepath.Join(tmpDir, "projects")
-
-	mgr := NewManager(sessionsDir, nil, "http://localhost:8080/mcp")
-
-	session := &Session{
-		SessionID:   "gogol_20250101_120000_abc12345",
-		ProjectID:   "550e8400-e29b-41d4-a716-446655440001",
-		WorkspaceID: "660e8400-e29b-41d4-a716-446655440001",
-		Status:      StatusActive,
-	}
-
-	// Index the session
-	mgr.indexSession(session)
-
-	// Lookup should work
-	projectID, ok := mgr.lookupProject(session.SessionID)
-	if !ok {
-		t.Error("expected lookup to succeed")
-	}
-	if projectID != "550e8400-e29b-41d4-a716-446655440001" {
-		t.Errorf("projectID = %q, want %q", projectID, "550e8400-e29b-41d4-a716-446655440001")
-	}
-
-	// Non-existent lookup
-	_, ok = mgr.lookupProject("gogol_20250101_000000_00000000")
-	if ok {
-		t.Error("expected lookup to fail for non-existent session")
-	}
-}
-
-func TestUpdateIndexStatus(t *testing.T) {
-	tmpDir := t.TempDir()
-	sessionsDir := filepath.Join(tmpDir, "projects")
-
-	mgr := NewManager(sessionsDir, nil, "http://localhost:8080/mcp")
-
-	session := &Session{
-		SessionID: "gogol_20250101_120000_abc12345",
-		ProjectID: "550e8400-e29b-41d4-a716-446655440001",
-		Status:    StatusActive,
-	}
-
-	mgr.indexSession(session)
-
-	// Update status
-	mgr.updateIndexStatus(session.SessionID, StatusCompleted)
-
-	// Verify via GetByStatus
-	active := mgr.persistentIndex.GetByStatus(StatusActive)
-	if len(active) != 0 {
-		t.Errorf("expected 0 active sessions, got %d", len(active))
-	}
-
-	completed := mgr.persistentIndex.GetByStatus(StatusCompleted)
-	if len(completed) != 1 {
-		t.Errorf("expected 1 completed session, got %d", len(completed))
-	}
-}
-
 func TestCleanupOldSessions(t *testing.T) {
 	tmpDir := t.TempDir()
 	sessionsDir := filepath.Join(tmpDir, "projects")
@@ -621,19 +607,8 @@ func TestCleanupOldSessions(t *testing.T) {
 		{SessionID: "gogol_20250101_130000_recent_done", ProjectID: projectID, Status: StatusCompleted, UpdatedAt: recentTime},
 	}
 
-	for _, s := range sessions {
-		data, _ := json.Marshal(s)
-		filePath := filepath.Join(sessDir, s.SessionID+".json")
-		_ = os.WriteFile(filePath, data, 0o644)
-		// Set file modification time to match UpdatedAt
-		_ = os.Chtimes(filePath, s.UpdatedAt, s.UpdatedAt)
-	}
-
-	mgr := NewManager(sessionsDir, nil, "http://localhost:8080/mcp")
-	// Index all sessions
-	for _, s := range sessions {
-		mgr.indexSession(s)
-	}
+	mgr := newTestManager(t, sessionsDir)
+	saveTestSessions(t, mgr, sessions...)
 
 	t.Run("cleanup old completed sessions", func(t *testing.T) {
 		deleted, err := mgr.CleanupOldSessions(projectID, 24*time.Hour)
@@ -646,21 +621,21 @@ func TestCleanupOldSessions(t *testing.T) {
 		}
 
 		// Verify old completed sessions are gone
-		if _, err := os.Stat(filepath.Join(sessDir, "gogol_20250101_100000_old_done.json")); !errors.Is(err, fs.ErrNotExist) {
-			t.Error("expected old_done session file to be deleted")
+		if _, err := mgr.store.Get("gogol_20250101_100000_old_done"); !errors.Is(err, ErrSessionNotFound) {
+			t.Error("expected old_done session to be deleted")
 		}
-		if _, err := os.Stat(filepath.Join(sessDir, "gogol_20250101_110000_old_fail.json")); !errors.Is(err, fs.ErrNotExist) {
-			t.Error("expected old_fail session file to be deleted")
+		if _, err := mgr.store.Get("gogol_20250101_110000_old_fail"); !errors.Is(err, ErrSessionNotFound) {
+			t.Error("expected old_fail session to be deleted")
 		}
 
 		// Verify active session is preserved (even if old)
-		if _, err := os.Stat(filepath.Join(sessDir, "gogol_20250101_120000_old_active.json")); err != nil {
-			t.Error("expected old_active session file to be preserved")
+		if _, err := mgr.store.Get("gogol_20250101_120000_old_active"); err != nil {
+			t.Error("expected old_active session to be preserved")
 		}
 
 		// Verify recent session is preserved
-		if _, err := os.Stat(filepath.Join(sessDir, "gogol_20250101_130000_recent_done.json")); err != nil {
-			t.Error("expected recent_done session file to be preserved")
+		if _, err := mgr.store.Get("gogol_20250101_130000_recent_done"); err != nil {
+			t.Error("expected recent_done session to be preserved")
 		}
 	})
 
@@ -689,11 +664,6 @@ func TestCleanupAllOldSessions(t *testing.T) {
 	projectID1 := "550e8400-e29b-41d4-a716-446655440001"
 	projectID2 := "550e8400-e29b-41d4-a716-446655440002"
 
-	sessDir1 := filepath.Join(sessionsDir, projectID1, "sessions")
-	sessDir2 := filepath.Join(sessionsDir, projectID2, "sessions")
-	_ = os.MkdirAll(sessDir1, 0o755)
-	_ = os.MkdirAll(sessDir2, 0o755)
-
 	oldTime := time.Now().Add(-48 * time.Hour)
 
 	// Create old completed sessions in both projects
@@ -703,19 +673,8 @@ func TestCleanupAllOldSessions(t *testing.T) {
 		{SessionID: "gogol_20250101_120000_proj2_old2", ProjectID: projectID2, Status: StatusFailed, UpdatedAt: oldTime},
 	}
 
-	for _, s := range sessions {
-		data, _ := json.Marshal(s)
-		var filePath string
-		if s.ProjectID == projectID1 {
-			filePath = filepath.Join(sessDir1, s.SessionID+".json")
-		} else {
-			filePath = filepath.Join(sessDir2, s.SessionID+".json")
-		}
-		_ = os.WriteFile(filePath, data, 0o644)
-		_ = os.Chtimes(filePath, s.UpdatedAt, s.UpdatedAt)
-	}
-
-	mgr := NewManager(sessionsDir, nil, "http://localhost:8080/mcp")
+	mgr := newTestManager(t, sessionsDir)
+	saveTestSessions(t, mgr, sessions...)
 
 	results, err := mgr.CleanupAllOldSessions(24 * time.Hour)
 	if err != nil {
@@ -734,3 +693,29 @@ func TestCleanupAllOldSessions(t *testing.T) {
 		t.Errorf("results[project2] = %d, want 2", results[projectID2])
 	}
 }
+
+func TestManagerDeleteSession(t *testing.T) {
+	sessionsDir := filepath.Join(t.TempDir(), "projects")
+	projectID := "550e8400-e29b-41d4-a716-446655440001"
+	_ = os.MkdirAll(filepath.Join(sessionsDir, projectID, "sessions"), 0o755)
+
+	mgr := newTestManager(t, sessionsDir)
+	sess := &Session{SessionID: "gogol_20250101_100000_abc12345", ProjectID: projectID, Status: StatusActive}
+	saveTestSessions(t, mgr, sess)
+	if err := mgr.AppendEvent(projectID, sess.SessionID, &BufferedEvent{Event: &agent.StreamEvent{Type: agent.StreamEventMessage, Text: "hi"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mgr.DeleteSession(projectID, sess.SessionID); err != nil {
+		t.Fatalf("DeleteSession() error = %v", err)
+	}
+	if _, err := mgr.store.Get(sess.SessionID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("session still stored: %v", err)
+	}
+	if _, err := os.Stat(mgr.eventLogPath(projectID, sess.SessionID)); !os.IsNotExist(err) {
+		t.Errorf("event log still present: %v", err)
+	}
+	if err := mgr.DeleteSession(projectID, sess.SessionID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("second DeleteSession() error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,352 @@
+package session
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
+	"github.com/HyphaGroup/oubliette/internal/logger"
+	"github.com/HyphaGroup/oubliette/internal/validation"
+)
+
+// Search limits
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// Kinds of indexed text
+const (
+	SearchKindPrompt = "prompt" // A turn's prompt
+	SearchKindOutput = "output" // A turn's final assistant message
+	SearchKindTool   = "tool"   // A tool call's name and parameters
+)
+
+// SearchFilter selects search results. Query is required.
+type SearchFilter struct {
+	Query     string
+	ProjectID string
+	Since     time.Time // At or after
+	Until     time.Time // Before
+	Limit     int       // Defaults to DefaultSearchLimit, capped at MaxSearchLimit
+}
+
+// SearchHit is a piece of session text matching a search
+type SearchHit struct {
+	SessionID string    `json:"session_id"`
+	ProjectID string    `json:"project_id"`
+	Turn      int       `json:"turn"` // 1-based
+	Kind      string    `json:"kind"`
+	At        time.Time `json:"at"`
+	Snippet   string    `json:"snippet"` // Matched terms are wrapped in [ ]
+}
+
+// searchDoc is one row of the search index
+type searchDoc struct {
+	turn int
+	kind string
+	at   time.Time
+	text string
+}
+
+// Search returns the session text best matching filter.Query
+func (m *Manager) Search(filter SearchFilter) ([]*SearchHit, error) {
+	if filter.ProjectID != "" {
+		if err := validation.ValidateProjectID(filter.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+	return m.store.Search(filter)
+}
+
+// IndexSession rebuilds the search index of a session from its turns and
+// event log. A session that is not saved yet has nothing to index.
+func (m *Manager) IndexSession(sessionID string) error {
+	sess, err := m.store.Get(sessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	events, err := m.LoadEvents(sessionID)
+	if err != nil {
+		return err
+	}
+	return m.store.replaceSearchDocs(sess, searchDocs(sess, events))
+}
+
+// indexSessionLogged indexes a session, logging rather than returning failures
+// so search never fails the operation that triggered it
+func (m *Manager) indexSessionLogged(sessionID string) {
+	if err := m.IndexSession(sessionID); err != nil {
+		logger.Error("Failed to index session %s for search: %v", sessionID, err)
+	}
+}
+
+// IndexUnindexedSessions indexes sessions saved before search existed.
+// Returns the number of sessions indexed.
+func (m *Manager) IndexUnindexedSessions() (int, error) {
+	ids, err := m.store.unindexedIDs()
+	if err != nil {
+		return 0, err
+	}
+	indexed := 0
+	var failed []string
+	for _, id := range ids {
+		if err := m.IndexSession(id); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", id, err))
+			continue
+		}
+		indexed++
+	}
+	if len(failed) > 0 {
+		return indexed, fmt.Errorf("failed to index %d sessions: %s", len(failed), strings.Join(failed, "; "))
+	}
+	return indexed, nil
+}
+
+// searchDocs extracts the searchable text of a session: each turn's prompt and
+// final assistant message, and every tool call with its parameters
+func searchDocs(sess *Session, events []*BufferedEvent) []searchDoc {
+	var docs []searchDoc
+	for i, t := range sess.Turns {
+		if strings.TrimSpace(t.Prompt) != "" {
+			docs = append(docs, searchDoc{turn: i + 1, kind: SearchKindPrompt, at: t.StartedAt, text: t.Prompt})
+		}
+	}
+
+	outputs := make([]*searchDoc, len(sess.Turns))
+	hasEvents := make([]bool, len(sess.Turns))
+	tools := map[string]int{} // Tool call ID -> index in docs, for coalescing updates
+	for i, index := range TurnIndexes(sess.Turns, events) {
+		if index < 0 {
+			continue
+		}
+		hasEvents[index] = true
+		event, at := events[i].Event, events[i].Timestamp
+		switch event.Type {
+		case agent.StreamEventMessage:
+			if event.Text == "" || event.Role == "user" || isPromptEcho(event.Text, sess.Turns[index].Prompt) {
+				continue
+			}
+			// The last message of a turn is its final output
+			outputs[index] = &searchDoc{turn: index + 1, kind: SearchKindOutput, at: at, text: event.Text}
+		case agent.StreamEventCompletion:
+			if outputs[index] == nil && event.FinalText != "" {
+				outputs[index] = &searchDoc{turn: index + 1, kind: SearchKindOutput, at: at, text: event.FinalText}
+			}
+		case agent.StreamEventToolCall:
+			doc := searchDoc{turn: index + 1, kind: SearchKindTool, at: at, text: toolCallText(event)}
+			if j, ok := tools[event.ToolID]; ok && event.ToolID != "" {
+				docs[j] = doc
+				continue
+			}
+			tools[event.ToolID] = len(docs)
+			docs = append(docs, doc)
+		}
+	}
+
+	for i, t := range sess.Turns {
+		// Turns run without streaming record their reply on the turn
+		if !hasEvents[i] && !t.CompletedAt.IsZero() && t.Output.Text != "" {
+			outputs[i] = &searchDoc{turn: i + 1, kind: SearchKindOutput, at: t.CompletedAt, text: t.Output.Text}
+		}
+		if outputs[i] != nil {
+			docs = append(docs, *outputs[i])
+		}
+	}
+	return docs
+}
+
+// toolCallText renders a tool call as its name followed by its parameters, so
+// file paths and commands are searchable
+func toolCallText(event *agent.StreamEvent) string {
+	keys := make([]string, 0, len(event.Parameters))
+	for k := range event.Parameters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := []string{event.ToolName}
+	for _, k := range keys {
+		switch v := event.Parameters[k].(type) {
+		case string:
+			parts = append(parts, v)
+		case nil:
+		default:
+			parts = append(parts, fmt.Sprint(v))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// isPromptEcho reports whether a message is the runtime echoing the user's prompt
+func isPromptEcho(text, prompt string) bool {
+	prompt = strings.TrimSpace(prompt)
+	return prompt != "" && strings.HasPrefix(strings.TrimSpace(text), prompt)
+}
+
+// matchQuery turns a user query into an FTS5 query matching documents that
+// contain every term. "Quoted phrases" match as phrases and a trailing *
+// matches prefixes; other FTS5 syntax is treated as text.
+func matchQuery(query string) (string, error) {
+	var terms []string
+	add := func(term string, prefix bool) {
+		if strings.TrimSpace(term) == "" {
+			return
+		}
+		quoted := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			quoted += "*"
+		}
+		terms = append(terms, quoted)
+	}
+
+	rest := strings.TrimSpace(query)
+	for rest != "" {
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				add(rest[1:], false)
+				break
+			}
+			add(rest[1:end+1], false)
+			rest = strings.TrimSpace(rest[end+2:])
+			continue
+		}
+		term, remainder, _ := strings.Cut(rest, " ")
+		rest = strings.TrimSpace(remainder)
+		prefix := strings.HasSuffix(term, "*")
+		add(strings.Trim(term, `*"`), prefix)
+	}
+
+	if len(terms) == 0 {
+		return "", ErrEmptyQuery
+	}
+	return strings.Join(terms, " "), nil
+}
+
+// replaceSearchDocs replaces a session's rows in the search index
+func (s *Store) replaceSearchDocs(sess *Session, docs []searchDoc) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM session_search WHERE session_id = ?`, sess.SessionID); err != nil {
+		return fmt.Errorf("failed to clear search index: %w", err)
+	}
+	for _, doc := range docs {
+		_, err := tx.Exec(`INSERT INTO session_search (text, kind, session_id, project_id, turn, at) VALUES (?, ?, ?, ?, ?, ?)`,
+			doc.text, doc.kind, sess.SessionID, sess.ProjectID, doc.turn, formatTime(doc.at))
+		if err != nil {
+			return fmt.Errorf("failed to index session: %w", err)
+		}
+	}
+	_, err = tx.Exec(`INSERT INTO session_search_state (session_id, indexed_at) VALUES (?, ?)
+		ON CONFLICT(session_id) DO UPDATE SET indexed_at = excluded.indexed_at`,
+		sess.SessionID, formatTime(time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to record search index state: %w", err)
+	}
+	return tx.Commit()
+}
+
+// execer is implemented by *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// deleteSearchDocs removes the search rows of the sessions selected by where
+func deleteSearchDocs(tx execer, where string, args ...any) error {
+	if _, err := tx.Exec(`DELETE FROM session_search WHERE session_id IN (`+where+`)`, args...); err != nil {
+		return fmt.Errorf("failed to delete search index: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM session_search_state WHERE session_id IN (`+where+`)`, args...); err != nil {
+		return fmt.Errorf("failed to delete search index state: %w", err)
+	}
+	return nil
+}
+
+// unindexedIDs returns sessions that have never been indexed for search
+func (s *Store) unindexedIDs() ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT session_id FROM sessions
+		WHERE session_id NOT IN (SELECT session_id FROM session_search_state)
+		ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Search returns the indexed text best matching the filter, most relevant first
+func (s *Store) Search(f SearchFilter) ([]*SearchHit, error) {
+	match, err := matchQuery(f.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	where := []string{"session_search MATCH ?"}
+	args := []any{match}
+	if f.ProjectID != "" {
+		where = append(where, "project_id = ?")
+		args = append(args, f.ProjectID)
+	}
+	if !f.Since.IsZero() {
+		where = append(where, "at >= ?")
+		args = append(args, formatTime(f.Since))
+	}
+	if !f.Until.IsZero() {
+		where = append(where, "at < ?")
+		args = append(args, formatTime(f.Until))
+	}
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+	args = append(args, limit)
+
+	rows, err := s.db.Query(`
+		SELECT session_id, project_id, turn, kind, at, snippet(session_search, 0, '[', ']', '…', 16)
+		FROM session_search
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY rank, at DESC
+		LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search sessions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	hits := []*SearchHit{}
+	for rows.Next() {
+		var hit SearchHit
+		var at string
+		if err := rows.Scan(&hit.SessionID, &hit.ProjectID, &hit.Turn, &hit.Kind, &at, &hit.Snippet); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		hit.At = parseTime(at)
+		hits = append(hits, &hit)
+	}
+	return hits, rows.Err()
+}
@@ -0,0 +1,161 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
+)
+
+func TestMatchQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{"payments migration", `"payments" "migration"`, false},
+		{`"payments migration" db/migrate.go`, `"payments migration" "db/migrate.go"`, false},
+		{"migrat*", `"migrat"*`, false},
+		{`NOT OR (col:x)`, `"NOT" "OR" "(col:x)"`, false},
+		{`"unterminated phrase`, `"unterminated phrase"`, false},
+		{"  ", "", true},
+		{`"" *`, "", true},
+	}
+	for _, tt := range tests {
+		got, err := matchQuery(tt.query)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("matchQuery(%q) = %q, %v; want %q", tt.query, got, err, tt.want)
+		}
+	}
+}
+
+func TestSearchDocs(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+	sess := &Session{
+		SessionID: "gogol_20250101_120000_abc12345",
+		Turns: []Turn{
+			{Prompt: "Write the payments migration", StartedAt: at(0)},
+			{Prompt: "Run the tests", StartedAt: at(10)},
+			{Prompt: "Summarize", StartedAt: at(20), CompletedAt: at(21), Output: TurnOutput{Text: "All done"}},
+		},
+	}
+	events := []*BufferedEvent{
+		{Timestamp: at(1), Event: &agent.StreamEvent{Type: agent.StreamEventMessage, Text: "Write the payments migration"}},
+		{Timestamp: at(2), Event: &agent.StreamEvent{Type: agent.StreamEventToolCall, ToolID: "t1", ToolName: "write"}},
+		{Timestamp: at(3), Event: &agent.StreamEvent{Type: agent.StreamEventToolCall, ToolID: "t1", ToolName: "write", Parameters: map[string]interface{}{"filePath": "db/0042_payments.sql", "overwrite": true}}},
+		{Timestamp: at(4), Event: &agent.StreamEvent{Type: agent.StreamEventMessage, ID: "p1", Text: "Drafting"}},
+		{Timestamp: at(5), Event: &agent.StreamEvent{Type: agent.StreamEventMessage, ID: "p2", Text: "Migration written"}},
+		{Timestamp: at(6), Event: &agent.StreamEvent{Type: agent.StreamEventCompletion}},
+		{Timestamp: at(11), Event: &agent.StreamEvent{Type: agent.StreamEventToolCall, ToolID: "t2", ToolName: "bash", Parameters: map[string]interface{}{"command": "go test ./..."}}},
+		{Timestamp: at(12), Event: &agent.StreamEvent{Type: agent.StreamEventCompletion, FinalText: "Tests pass"}},
+	}
+
+	var got []string
+	for _, doc := range searchDocs(sess, events) {
+		got = append(got, fmt.Sprintf("%s:%d:%s", doc.kind, doc.turn, doc.text))
+	}
+	want := []string{
+		"prompt:1:Write the payments migration",
+		"prompt:2:Run the tests",
+		"prompt:3:Summarize",
+		"tool:1:write db/0042_payments.sql true",
+		"tool:2:bash go test ./...",
+		"output:1:Migration written",
+		"output:2:Tests pass",
+		"output:3:All done",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("searchDocs() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestManagerSearch(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionsDir := filepath.Join(tmpDir, "projects")
+	project1 := "550e8400-e29b-41d4-a716-446655440001"
+	project2 := "550e8400-e29b-41d4-a716-446655440002"
+	_ = os.MkdirAll(filepath.Join(sessionsDir, project1, "sessions"), 0o755)
+	mgr := newTestManager(t, sessionsDir)
+
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	recent := time.Now().Add(-time.Hour)
+	saveTestSessions(t, mgr,
+		&Session{SessionID: "gogol_20250101_120000_aaaa1111", ProjectID: project1, Status: StatusActive, CreatedAt: recent, UpdatedAt: recent,
+			Turns: []Turn{{TurnNumber: 1, Prompt: "Fix the payments migration", StartedAt: recent}}},
+		&Session{SessionID: "gogol_20250101_120000_bbbb2222", ProjectID: project2, Status: StatusCompleted, CreatedAt: old, UpdatedAt: old,
+			Turns: []Turn{{TurnNumber: 1, Prompt: "Review payments refunds", StartedAt: old}}},
+	)
+
+	t.Run("turn completion indexes the session", func(t *testing.T) {
+		if hits, _ := mgr.Search(SearchFilter{Query: "migration"}); len(hits) != 0 {
+			t.Fatalf("found %d hits before indexing", len(hits))
+		}
+		for _, e := range []*agent.StreamEvent{
+			{Type: agent.StreamEventToolCall, ToolID: "t1", ToolName: "bash", Parameters: map[string]interface{}{"command": "psql -f db/migrate_payments.sql"}},
+			{Type: agent.StreamEventCompletion},
+		} {
+			if err := mgr.AppendEvent(project1, "gogol_20250101_120000_aaaa1111", &BufferedEvent{Timestamp: recent.Add(time.Minute), Event: e}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		hits, err := mgr.Search(SearchFilter{Query: "migrate_payments.sql"})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(hits) != 1 || hits[0].SessionID != "gogol_20250101_120000_aaaa1111" || hits[0].Turn != 1 || hits[0].Kind != SearchKindTool {
+			t.Fatalf("hits = %+v", hits)
+		}
+		if !strings.Contains(hits[0].Snippet, "[migrate_payments.sql]") {
+			t.Errorf("Snippet = %q, want highlighted terms", hits[0].Snippet)
+		}
+	})
+
+	t.Run("backfill", func(t *testing.T) {
+		indexed, err := mgr.IndexUnindexedSessions()
+		if err != nil || indexed != 1 {
+			t.Fatalf("IndexUnindexedSessions() = %d, %v; want 1", indexed, err)
+		}
+		if indexed, _ := mgr.IndexUnindexedSessions(); indexed != 0 {
+			t.Errorf("second IndexUnindexedSessions() = %d, want 0", indexed)
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		for _, tt := range []struct {
+			name   string
+			filter SearchFilter
+			want   int
+		}{
+			{"all projects", SearchFilter{Query: "payments"}, 3},
+			{"project", SearchFilter{Query: "payments", ProjectID: project2}, 1},
+			{"since", SearchFilter{Query: "payments", Since: time.Now().Add(-24 * time.Hour)}, 2},
+			{"until", SearchFilter{Query: "payments", Until: time.Now().Add(-24 * time.Hour)}, 1},
+			{"all terms", SearchFilter{Query: "payments refunds"}, 1},
+			{"prefix", SearchFilter{Query: "refund*"}, 1},
+			{"limit", SearchFilter{Query: "payments", Limit: 1}, 1},
+		} {
+			hits, err := mgr.Search(tt.filter)
+			if err != nil {
+				t.Fatalf("%s: Search() error = %v", tt.name, err)
+			}
+			if len(hits) != tt.want {
+				t.Errorf("%s: %d hits, want %d", tt.name, len(hits), tt.want)
+			}
+		}
+	})
+
+	t.Run("deleted sessions leave the index", func(t *testing.T) {
+		if _, err := mgr.CleanupOldSessions(project2, 24*time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		if hits, _ := mgr.Search(SearchFilter{Query: "refunds"}); len(hits) != 0 {
+			t.Errorf("found %d hits for a deleted session", len(hits))
+		}
+	})
+}
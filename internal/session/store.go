@@ -0,0 +1,599 @@
+package session
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// List limits
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrEmptyQuery      = errors.New("query is required")
+)
+
+// ListFilter selects sessions for Store.List. Empty fields match everything.
+type ListFilter struct {
+	ProjectID       string
+	WorkspaceID     string
+	Status          Status
+	Model           string
+	ExplorationID   string
+	ParentSessionID string
+	Since           time.Time // Created at or after
+	Until           time.Time // Created before
+	Limit           int       // Defaults to DefaultListLimit, capped at MaxListLimit
+	Cursor          string    // NextCursor from the previous page
+}
+
+// ListPage is one page of sessions, newest first
+type ListPage struct {
+	Sessions   []*SessionSummary `json:"sessions"`
+	NextCursor string            `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// Store persists sessions and their turns in SQLite
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore opens (or creates) the session database in dataDir
+func NewStore(dataDir string) (*Store, error) {
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	dbPath := filepath.Join(dataDir, "sessions.db")
+	// Enable busy timeout for better concurrent access
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Enable WAL mode via PRAGMA (more reliable than DSN parameter)
+	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
+	}
+
+	store := &Store{db: db}
+	if err := store.migrate(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	return store, nil
+}
+
+func (s *Store) migrate() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS sessions (
+		session_id TEXT PRIMARY KEY,
+		project_id TEXT NOT NULL,
+		workspace_id TEXT NOT NULL DEFAULT '',
+		container_id TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		updated_at TEXT NOT NULL,
+		runtime_session_id TEXT NOT NULL DEFAULT '',
+		model TEXT NOT NULL DEFAULT '',
+		autonomy_level TEXT NOT NULL DEFAULT '',
+		reasoning_level TEXT NOT NULL DEFAULT '',
+		input_tokens INTEGER NOT NULL DEFAULT 0,
+		output_tokens INTEGER NOT NULL DEFAULT 0,
+		parent_session_id TEXT,
+		forked_from_turn INTEGER NOT NULL DEFAULT 0,
+		depth INTEGER NOT NULL DEFAULT 0,
+		exploration_id TEXT NOT NULL DEFAULT '',
+		child_sessions TEXT NOT NULL DEFAULT '',
+		task_context TEXT NOT NULL DEFAULT '',
+		tools_allowed TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_sessions_created ON sessions(created_at DESC, session_id DESC);
+	CREATE INDEX IF NOT EXISTS idx_sessions_project ON sessions(project_id, created_at DESC, session_id DESC);
+	CREATE INDEX IF NOT EXISTS idx_sessions_project_updated ON sessions(project_id, updated_at);
+	CREATE INDEX IF NOT EXISTS idx_sessions_workspace ON sessions(project_id, workspace_id, created_at DESC);
+	CREATE INDEX IF NOT EXISTS idx_sessions_status ON sessions(status, updated_at);
+	CREATE INDEX IF NOT EXISTS idx_sessions_model ON sessions(model, created_at DESC);
+	CREATE INDEX IF NOT EXISTS idx_sessions_exploration ON sessions(exploration_id);
+	CREATE INDEX IF NOT EXISTS idx_sessions_parent ON sessions(parent_session_id);
+
+	CREATE TABLE IF NOT EXISTS session_turns (
+		session_id TEXT NOT NULL,
+		seq INTEGER NOT NULL,
+		turn_number INTEGER NOT NULL,
+		prompt TEXT NOT NULL DEFAULT '',
+		started_at TEXT NOT NULL,
+		completed_at TEXT NOT NULL,
+		output_text TEXT NOT NULL DEFAULT '',
+		exit_code INTEGER NOT NULL DEFAULT 0,
+		error TEXT NOT NULL DEFAULT '',
+		streaming_file TEXT NOT NULL DEFAULT '',
+		input_tokens INTEGER NOT NULL DEFAULT 0,
+		output_tokens INTEGER NOT NULL DEFAULT 0,
+		model TEXT NOT NULL DEFAULT '',
+		attempts TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (session_id, seq)
+	);
+
+	CREATE VIRTUAL TABLE IF NOT EXISTS session_search USING fts5(
+		text,
+		kind UNINDEXED,
+		session_id UNINDEXED,
+		project_id UNINDEXED,
+		turn UNINDEXED,
+		at UNINDEXED
+	);
+	CREATE TABLE IF NOT EXISTS session_search_state (
+		session_id TEXT PRIMARY KEY,
+		indexed_at TEXT NOT NULL
+	);
+	`
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
+	}
+
+	// Columns added after the first release of the schema
+	return s.addColumns("session_turns", []string{
+		"model TEXT NOT NULL DEFAULT ''",
+		"attempts TEXT NOT NULL DEFAULT ''",
+	})
+}
+
+// addColumns adds the given column definitions to a table that lacks them
+func (s *Store) addColumns(table string, columns []string) error {
+	rows, err := s.db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return fmt.Errorf("failed to read %s columns: %w", table, err)
+	}
+	existing := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to read %s columns: %w", table, err)
+		}
+		existing[name] = true
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read %s columns: %w", table, err)
+	}
+
+	for _, column := range columns {
+		name, _, _ := strings.Cut(column, " ")
+		if existing[name] {
+			continue
+		}
+		if _, err := s.db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + column); err != nil {
+			return fmt.Errorf("failed to add %s.%s: %w", table, name, err)
+		}
+	}
+	return nil
+}
+
+// Close closes the database connection
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save inserts or replaces a session and its turns in one transaction, so a
+// crash leaves either the old or the new record
+func (s *Store) Save(sess *Session) error {
+	childSessions, err := marshalOptional(sess.ChildSessions, len(sess.ChildSessions) == 0)
+	if err != nil {
+		return err
+	}
+	taskContext, err := marshalOptional(sess.TaskContext, len(sess.TaskContext) == 0)
+	if err != nil {
+		return err
+	}
+	toolsAllowed, err := marshalOptional(sess.ToolsAllowed, len(sess.ToolsAllowed) == 0)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.Exec(`
+		INSERT INTO sessions (session_id, project_id, workspace_id, container_id, status, created_at, updated_at,
+		                      runtime_session_id, model, autonomy_level, reasoning_level, input_tokens, output_tokens,
+		                      parent_session_id, forked_from_turn, depth, exploration_id, child_sessions, task_context, tools_allowed)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(session_id) DO UPDATE SET
+			project_id = excluded.project_id, workspace_id = excluded.workspace_id, container_id = excluded.container_id,
+			status = excluded.status, created_at = excluded.created_at, updated_at = excluded.updated_at,
+			runtime_session_id = excluded.runtime_session_id, model = excluded.model,
+			autonomy_level = excluded.autonomy_level, reasoning_level = excluded.reasoning_level,
+			input_tokens = excluded.input_tokens, output_tokens = excluded.output_tokens,
+			parent_session_id = excluded.parent_session_id, forked_from_turn = excluded.forked_from_turn,
+			depth = excluded.depth, exploration_id = excluded.exploration_id, child_sessions = excluded.child_sessions,
+			task_context = excluded.task_context, tools_allowed = excluded.tools_allowed`,
+		sess.SessionID, sess.ProjectID, sess.WorkspaceID, sess.ContainerID, string(sess.Status),
+		formatTime(sess.CreatedAt), formatTime(sess.UpdatedAt),
+		sess.RuntimeSessionID, sess.Model, sess.AutonomyLevel, sess.ReasoningLevel,
+		sess.TotalCost.InputTokens, sess.TotalCost.OutputTokens,
+		sess.ParentSessionID, sess.ForkedFromTurn, sess.Depth, sess.ExplorationID,
+		childSessions, taskContext, toolsAllowed,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM session_turns WHERE session_id = ?`, sess.SessionID); err != nil {
+		return fmt.Errorf("failed to replace turns: %w", err)
+	}
+	for i, turn := range sess.Turns {
+		attempts, err := marshalOptional(turn.Attempts, len(turn.Attempts) == 0)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO session_turns (session_id, seq, turn_number, prompt, started_at, completed_at,
+			                           output_text, exit_code, error, streaming_file, input_tokens, output_tokens,
+			                           model, attempts)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			sess.SessionID, i, turn.TurnNumber, turn.Prompt, formatTime(turn.StartedAt), formatTime(turn.CompletedAt),
+			turn.Output.Text, turn.Output.ExitCode, turn.Output.Error, turn.Output.StreamingFile,
+			turn.Cost.InputTokens, turn.Cost.OutputTokens, turn.Model, attempts,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save turn %d: %w", turn.TurnNumber, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Get returns a session with its turns
+func (s *Store) Get(sessionID string) (*Session, error) {
+	var sess Session
+	var status, createdAt, updatedAt, childSessions, taskContext, toolsAllowed string
+	var parentSessionID sql.NullString
+
+	err := s.db.QueryRow(`
+		SELECT session_id, project_id, workspace_id, container_id, status, created_at, updated_at,
+		       runtime_session_id, model, autonomy_level, reasoning_level, input_tokens, output_tokens,
+		       parent_session_id, forked_from_turn, depth, exploration_id, child_sessions, task_context, tools_allowed
+		FROM sessions WHERE session_id = ?`, sessionID,
+	).Scan(
+		&sess.SessionID, &sess.ProjectID, &sess.WorkspaceID, &sess.ContainerID, &status, &createdAt, &updatedAt,
+		&sess.RuntimeSessionID, &sess.Model, &sess.AutonomyLevel, &sess.ReasoningLevel,
+		&sess.TotalCost.InputTokens, &sess.TotalCost.OutputTokens,
+		&parentSessionID, &sess.ForkedFromTurn, &sess.Depth, &sess.ExplorationID,
+		&childSessions, &taskContext, &toolsAllowed,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session: %w", err)
+	}
+
+	sess.Status = Status(status)
+	sess.CreatedAt = parseTime(createdAt)
+	sess.UpdatedAt = parseTime(updatedAt)
+	if parentSessionID.Valid {
+		sess.ParentSessionID = &parentSessionID.String
+	}
+	if err := unmarshalOptional(childSessions, &sess.ChildSessions); err != nil {
+		return nil, err
+	}
+	if err := unmarshalOptional(taskContext, &sess.TaskContext); err != nil {
+		return nil, err
+	}
+	if err := unmarshalOptional(toolsAllowed, &sess.ToolsAllowed); err != nil {
+		return nil, err
+	}
+
+	if sess.Turns, err = s.turns(sessionID); err != nil {
+		return nil, err
+	}
+	return &sess, nil
+}
+
+func (s *Store) turns(sessionID string) ([]Turn, error) {
+	rows, err := s.db.Query(`
+		SELECT turn_number, prompt, started_at, completed_at, output_text, exit_code, error, streaming_file,
+		       input_tokens, output_tokens, model, attempts
+		FROM session_turns WHERE session_id = ? ORDER BY seq`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query turns: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	turns := []Turn{}
+	for rows.Next() {
+		var turn Turn
+		var startedAt, completedAt, attempts string
+		if err := rows.Scan(
+			&turn.TurnNumber, &turn.Prompt, &startedAt, &completedAt,
+			&turn.Output.Text, &turn.Output.ExitCode, &turn.Output.Error, &turn.Output.StreamingFile,
+			&turn.Cost.InputTokens, &turn.Cost.OutputTokens, &turn.Model, &attempts,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan turn: %w", err)
+		}
+		if err := unmarshalOptional(attempts, &turn.Attempts); err != nil {
+			return nil, err
+		}
+		turn.StartedAt = parseTime(startedAt)
+		turn.CompletedAt = parseTime(completedAt)
+		turns = append(turns, turn)
+	}
+	return turns, rows.Err()
+}
+
+// List returns a page of sessions matching the filter, newest first. Pages
+// are keyed on (created_at, session_id), so sessions created while paging do
+// not shift later pages.
+func (s *Store) List(f ListFilter) (*ListPage, error) {
+	var where []string
+	var args []any
+	for _, c := range []struct {
+		column, value string
+	}{
+		{"project_id", f.ProjectID},
+		{"workspace_id", f.WorkspaceID},
+		{"status", string(f.Status)},
+		{"model", f.Model},
+		{"exploration_id", f.ExplorationID},
+		{"parent_session_id", f.ParentSessionID},
+	} {
+		if c.value != "" {
+			where = append(where, "s."+c.column+" = ?")
+			args = append(args, c.value)
+		}
+	}
+	if !f.Since.IsZero() {
+		where = append(where, "s.created_at >= ?")
+		args = append(args, formatTime(f.Since))
+	}
+	if !f.Until.IsZero() {
+		where = append(where, "s.created_at < ?")
+		args = append(args, formatTime(f.Until))
+	}
+	if f.Cursor != "" {
+		createdAt, sessionID, err := decodeCursor(f.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, "(s.created_at < ? OR (s.created_at = ? AND s.session_id < ?))")
+		args = append(args, createdAt, createdAt, sessionID)
+	}
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	if limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+
+	query := `
+		SELECT s.session_id, s.project_id, s.workspace_id, s.status, s.created_at, s.updated_at,
+		       s.model, s.parent_session_id, s.exploration_id,
+		       (SELECT COUNT(*) FROM session_turns t WHERE t.session_id = s.session_id),
+		       COALESCE((SELECT t.prompt FROM session_turns t WHERE t.session_id = s.session_id ORDER BY t.seq DESC LIMIT 1), '')
+		FROM sessions s`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	// Fetch one extra row to know whether another page follows
+	query += " ORDER BY s.created_at DESC, s.session_id DESC LIMIT ?"
+	args = append(args, limit+1)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	page := &ListPage{Sessions: []*SessionSummary{}}
+	var lastCreatedAt string
+	for rows.Next() {
+		if len(page.Sessions) == limit {
+			last := page.Sessions[limit-1]
+			page.NextCursor = encodeCursor(lastCreatedAt, last.SessionID)
+			break
+		}
+		var summary SessionSummary
+		var status, createdAt, updatedAt string
+		var parentSessionID sql.NullString
+		if err := rows.Scan(
+			&summary.SessionID, &summary.ProjectID, &summary.WorkspaceID, &status, &createdAt, &updatedAt,
+			&summary.Model, &parentSessionID, &summary.ExplorationID, &summary.TurnCount, &summary.LastPrompt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		summary.Status = Status(status)
+		summary.CreatedAt = parseTime(createdAt)
+		summary.UpdatedAt = parseTime(updatedAt)
+		summary.ParentSessionID = parentSessionID.String
+		lastCreatedAt = createdAt
+		page.Sessions = append(page.Sessions, &summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// LatestID returns the ID of the project's most recently updated session, or
+// "" if it has none
+func (s *Store) LatestID(projectID string) (string, error) {
+	var sessionID string
+	err := s.db.QueryRow(`
+		SELECT session_id FROM sessions WHERE project_id = ?
+		ORDER BY updated_at DESC, session_id DESC LIMIT 1`, projectID,
+	).Scan(&sessionID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query latest session: %w", err)
+	}
+	return sessionID, nil
+}
+
+// IDs returns the IDs of sessions in a status last updated before cutoff.
+// An empty projectID matches all projects; exclude inverts the status match.
+func (s *Store) IDs(projectID string, status Status, exclude bool, cutoff time.Time) ([]string, error) {
+	op := "="
+	if exclude {
+		op = "!="
+	}
+	query := `SELECT session_id FROM sessions WHERE status ` + op + ` ? AND updated_at < ?`
+	args := []any{string(status), formatTime(cutoff)}
+	if projectID != "" {
+		query += " AND project_id = ?"
+		args = append(args, projectID)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ProjectIDs returns the projects that have sessions
+func (s *Store) ProjectIDs() ([]string, error) {
+	rows, err := s.db.Query(`SELECT DISTINCT project_id FROM sessions ORDER BY project_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Delete removes a session and its turns
+func (s *Store) Delete(sessionID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM session_turns WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("failed to delete turns: %w", err)
+	}
+	if err := deleteSearchDocs(tx, `?`, sessionID); err != nil {
+		return err
+	}
+	result, err := tx.Exec(`DELETE FROM sessions WHERE session_id = ?`, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrSessionNotFound
+	}
+	return tx.Commit()
+}
+
+// DeleteProject removes all of a project's sessions and their turns
+func (s *Store) DeleteProject(projectID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM session_turns WHERE session_id IN (SELECT session_id FROM sessions WHERE project_id = ?)`, projectID); err != nil {
+		return fmt.Errorf("failed to delete turns: %w", err)
+	}
+	if err := deleteSearchDocs(tx, `SELECT session_id FROM sessions WHERE project_id = ?`, projectID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM sessions WHERE project_id = ?`, projectID); err != nil {
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	return tx.Commit()
+}
+
+// marshalOptional encodes v as JSON, or "" when empty
+func marshalOptional(v any, empty bool) (string, error) {
+	if empty {
+		return "", nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal session field: %w", err)
+	}
+	return string(data), nil
+}
+
+func unmarshalOptional(data string, v any) error {
+	if data == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return fmt.Errorf("failed to parse session field: %w", err)
+	}
+	return nil
+}
+
+// formatTime stores times as fixed-width UTC text so they sort correctly
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000000000Z07:00")
+}
+
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
+
+func encodeCursor(createdAt, sessionID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt + "|" + sessionID))
+}
+
+func decodeCursor(cursor string) (createdAt, sessionID string, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", "", ErrInvalidCursor
+	}
+	createdAt, sessionID, ok := strings.Cut(string(data), "|")
+	if !ok || sessionID == "" {
+		return "", "", ErrInvalidCursor
+	}
+	if _, err := time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return "", "", ErrInvalidCursor
+	}
+	return createdAt, sessionID, nil
+}
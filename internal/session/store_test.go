@@ -0,0 +1,248 @@
+package session
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestStoreSaveAndGet(t *testing.T) {
+	store := newTestStore(t)
+	parentID := "gogol_20250101_120000_aaaa0000"
+	created := time.Date(2025, 1, 1, 12, 0, 0, 123456789, time.UTC)
+	sess := &Session{
+		SessionID:       "gogol_20250101_120100_bbbb1111",
+		ProjectID:       "proj-1",
+		WorkspaceID:     "ws-1",
+		Status:          StatusActive,
+		CreatedAt:       created,
+		UpdatedAt:       created,
+		Model:           "claude-sonnet",
+		ParentSessionID: &parentID,
+		ForkedFromTurn:  2,
+		Depth:           1,
+		ToolsAllowed:    []string{"read"},
+		Turns: []Turn{
+			{TurnNumber: 1, Prompt: "one", StartedAt: created, Cost: Cost{InputTokens: 5}},
+			{TurnNumber: 2, Prompt: "two", StartedAt: created.Add(time.Second), Output: TurnOutput{Error: "boom", ExitCode: 1}},
+		},
+	}
+
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := store.Get(sess.SessionID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !got.CreatedAt.Equal(created) || got.Model != "claude-sonnet" || got.ForkedFromTurn != 2 || got.Depth != 1 {
+		t.Errorf("Get() = %+v", got)
+	}
+	if got.ParentSessionID == nil || *got.ParentSessionID != parentID || len(got.ToolsAllowed) != 1 {
+		t.Errorf("ParentSessionID = %v, ToolsAllowed = %v", got.ParentSessionID, got.ToolsAllowed)
+	}
+	if len(got.Turns) != 2 || got.Turns[0].Cost.InputTokens != 5 || got.Turns[1].Output.Error != "boom" {
+		t.Errorf("Turns = %+v", got.Turns)
+	}
+
+	t.Run("save replaces turns", func(t *testing.T) {
+		sess.Turns = sess.Turns[:1]
+		sess.Status = StatusCompleted
+		if err := store.Save(sess); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		got, _ := store.Get(sess.SessionID)
+		if len(got.Turns) != 1 || got.Status != StatusCompleted {
+			t.Errorf("after resave: status %q, %d turns", got.Status, len(got.Turns))
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := store.Delete(sess.SessionID); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := store.Get(sess.SessionID); !errors.Is(err, ErrSessionNotFound) {
+			t.Errorf("Get() after delete error = %v, want ErrSessionNotFound", err)
+		}
+		if turns, _ := store.turns(sess.SessionID); len(turns) != 0 {
+			t.Errorf("%d turns left after delete", len(turns))
+		}
+		if err := store.Delete(sess.SessionID); !errors.Is(err, ErrSessionNotFound) {
+			t.Errorf("second Delete() error = %v, want ErrSessionNotFound", err)
+		}
+	})
+}
+
+func TestStoreList(t *testing.T) {
+	store := newTestStore(t)
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	parentID := "gogol_20250101_000000_00000000"
+	for i, s := range []*Session{
+		{ProjectID: "proj-1", WorkspaceID: "ws-a", Status: StatusCompleted, Model: "sonnet"},
+		{ProjectID: "proj-1", WorkspaceID: "ws-a", Status: StatusActive, Model: "opus", ExplorationID: "exp-1"},
+		{ProjectID: "proj-1", WorkspaceID: "ws-b", Status: StatusFailed, Model: "sonnet", ExplorationID: "exp-1", ParentSessionID: &parentID},
+		{ProjectID: "proj-1", WorkspaceID: "ws-b", Status: StatusCompleted, Model: "sonnet"},
+		{ProjectID: "proj-2", WorkspaceID: "ws-c", Status: StatusCompleted, Model: "sonnet"},
+	} {
+		s.SessionID = "gogol_20250101_00000" + string(rune('1'+i)) + "_0000000" + string(rune('1'+i))
+		s.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		s.UpdatedAt = s.CreatedAt
+		s.Turns = []Turn{{TurnNumber: 1, Prompt: "first"}, {TurnNumber: 2, Prompt: "last"}}
+		if err := store.Save(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter ListFilter
+		want   int
+	}{
+		{"all", ListFilter{}, 5},
+		{"project", ListFilter{ProjectID: "proj-1"}, 4},
+		{"workspace", ListFilter{ProjectID: "proj-1", WorkspaceID: "ws-b"}, 2},
+		{"status", ListFilter{Status: StatusCompleted}, 3},
+		{"model", ListFilter{Model: "opus"}, 1},
+		{"exploration", ListFilter{ExplorationID: "exp-1"}, 2},
+		{"parent", ListFilter{ParentSessionID: parentID}, 1},
+		{"date range", ListFilter{Since: base.Add(time.Hour), Until: base.Add(3 * time.Hour)}, 2},
+		{"combined", ListFilter{ProjectID: "proj-1", Status: StatusCompleted, Model: "sonnet"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := store.List(tt.filter)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(page.Sessions) != tt.want {
+				t.Errorf("len(Sessions) = %d, want %d", len(page.Sessions), tt.want)
+			}
+			if page.NextCursor != "" {
+				t.Errorf("NextCursor = %q, want none", page.NextCursor)
+			}
+		})
+	}
+
+	t.Run("summaries", func(t *testing.T) {
+		page, _ := store.List(ListFilter{ParentSessionID: parentID})
+		got := page.Sessions[0]
+		if got.TurnCount != 2 || got.LastPrompt != "last" || got.Model != "sonnet" || got.ExplorationID != "exp-1" || got.ParentSessionID != parentID {
+			t.Errorf("summary = %+v", got)
+		}
+	})
+
+	t.Run("cursor pagination", func(t *testing.T) {
+		var ids []string
+		filter := ListFilter{Limit: 2}
+		for pages := 0; ; pages++ {
+			if pages > 3 {
+				t.Fatal("pagination did not terminate")
+			}
+			page, err := store.List(filter)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			for _, s := range page.Sessions {
+				ids = append(ids, s.SessionID)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			filter.Cursor = page.NextCursor
+		}
+		if len(ids) != 5 {
+			t.Fatalf("paged through %d sessions, want 5: %v", len(ids), ids)
+		}
+		for i := 1; i < len(ids); i++ {
+			if ids[i] >= ids[i-1] {
+				t.Errorf("sessions not newest first: %v", ids)
+			}
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		for _, cursor := range []string{"!!!", "bm90LWEtY3Vyc29y"} {
+			if _, err := store.List(ListFilter{Cursor: cursor}); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("List(cursor %q) error = %v, want ErrInvalidCursor", cursor, err)
+			}
+		}
+	})
+
+	t.Run("delete project", func(t *testing.T) {
+		if err := store.DeleteProject("proj-2"); err != nil {
+			t.Fatalf("DeleteProject() error = %v", err)
+		}
+		if page, _ := store.List(ListFilter{ProjectID: "proj-2"}); len(page.Sessions) != 0 {
+			t.Errorf("%d sessions left after DeleteProject", len(page.Sessions))
+		}
+		if page, _ := store.List(ListFilter{ProjectID: "proj-1"}); len(page.Sessions) != 4 {
+			t.Errorf("other project has %d sessions, want 4", len(page.Sessions))
+		}
+	})
+
+	t.Run("latest", func(t *testing.T) {
+		id, err := store.LatestID("proj-1")
+		if err != nil || id != "gogol_20250101_000004_00000004" {
+			t.Errorf("LatestID() = %q, %v", id, err)
+		}
+		if id, _ := store.LatestID("proj-none"); id != "" {
+			t.Errorf("LatestID() for project without sessions = %q", id)
+		}
+	})
+}
+
+func TestStoreAddsTurnColumns(t *testing.T) {
+	dir := t.TempDir()
+
+	// A database created before turns recorded provider failover
+	db, err := sql.Open("sqlite", filepath.Join(dir, "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`CREATE TABLE session_turns (
+		session_id TEXT NOT NULL, seq INTEGER NOT NULL, turn_number INTEGER NOT NULL,
+		prompt TEXT NOT NULL DEFAULT '', started_at TEXT NOT NULL, completed_at TEXT NOT NULL,
+		output_text TEXT NOT NULL DEFAULT '', exit_code INTEGER NOT NULL DEFAULT 0, error TEXT NOT NULL DEFAULT '',
+		streaming_file TEXT NOT NULL DEFAULT '', input_tokens INTEGER NOT NULL DEFAULT 0,
+		output_tokens INTEGER NOT NULL DEFAULT 0, PRIMARY KEY (session_id, seq))`)
+	_ = db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	at := time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)
+	sess := &Session{
+		SessionID: "gogol_20250101_030000_cccc3333", ProjectID: "proj-1", Status: StatusCompleted, CreatedAt: at, UpdatedAt: at,
+		Turns: []Turn{{TurnNumber: 1, Prompt: "nightly", Model: "openai/gpt-5.1",
+			Attempts: []TurnAttempt{{At: at, Error: "Overloaded", ErrorCode: 529, Outcome: AttemptFailover, NextModel: "openai/gpt-5.1"}}}},
+	}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := store.Get(sess.SessionID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	turn := got.Turns[0]
+	if turn.Model != "openai/gpt-5.1" || len(turn.Attempts) != 1 || !turn.Attempts[0].At.Equal(at) || turn.Attempts[0].Outcome != AttemptFailover {
+		t.Errorf("turn = %+v", turn)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/HyphaGroup/oubliette/internal/agent"
+	"github.com/HyphaGroup/oubliette/internal/validation"
 )
 
 /*
@@ -55,15 +56,7 @@ func (m *Manager) CreateBidirectionalSession(ctx context.Context, projectID, con
 		return nil, nil, fmt.Errorf("workspace_id is required - caller must resolve workspace before creating session")
 	}
 
-	// Working directory depends on workspace isolation setting
-	var workingDir string
-	if opts.WorkspaceIsolation {
-		// Isolated mode: /workspace is mounted to workspaces/, so workingDir is /workspace/<uuid>
-		workingDir = fmt.Sprintf("/workspace/%s", workspaceID)
-	} else {
-		// Non-isolated mode: /workspace is mounted to project root
-		workingDir = fmt.Sprintf("/workspace/workspaces/%s", workspaceID)
-	}
+	workingDir := containerWorkingDir(workspaceID, opts.WorkspaceIsolation)
 
 	session := &Session{
 		SessionID:      sessionID,
@@ -83,6 +76,7 @@ func (m *Manager) CreateBidirectionalSession(ctx context.Context, projectID, con
 	// Create agent request (new session, no -s flag)
 	req := &agent.ExecuteRequest{
 		Prompt:         prompt,
+		Files:          opts.Files,
 		ContainerID:    containerID,
 		WorkingDir:     workingDir,
 		ProjectID:      projectID,
@@ -96,13 +90,7 @@ func (m *Manager) CreateBidirectionalSession(ctx context.Context, projectID, con
 		StreamJSONRPC: true,
 	}
 
-	// Determine which runtime to use (override or manager's default)
-	runtime := m.agentRuntime
-	if opts.RuntimeOverride != nil {
-		if rt, ok := opts.RuntimeOverride.(agent.Runtime); ok {
-			runtime = rt
-		}
-	}
+	runtime := m.runtimeFor(opts)
 
 	// Start bidirectional streaming - use background context so executor survives request completion
 	// The executor has its own lifecycle managed by ActiveSessionManager
@@ -155,19 +143,12 @@ func (m *Manager) ResumeBidirectionalSession(ctx context.Context, existingSessio
 		return nil, nil, fmt.Errorf("workspace_id is required - caller must resolve workspace before resuming session")
 	}
 
-	// Working directory depends on workspace isolation setting
-	var workingDir string
-	if opts.WorkspaceIsolation {
-		// Isolated mode: /workspace is mounted to workspaces/, so workingDir is /workspace/<uuid>
-		workingDir = fmt.Sprintf("/workspace/%s", workspaceID)
-	} else {
-		// Non-isolated mode: /workspace is mounted to project root
-		workingDir = fmt.Sprintf("/workspace/workspaces/%s", workspaceID)
-	}
+	workingDir := containerWorkingDir(workspaceID, opts.WorkspaceIsolation)
 
 	// Create agent request with session ID for resumption
 	req := &agent.ExecuteRequest{
 		Prompt:         prompt,
+		Files:          opts.Files,
 		ContainerID:    containerID,
 		WorkingDir:     workingDir,
 		SessionID:      existingSession.RuntimeSessionID, // Resume this session
@@ -182,13 +163,7 @@ func (m *Manager) ResumeBidirectionalSession(ctx context.Context, existingSessio
 		StreamJSONRPC: true,
 	}
 
-	// Determine which runtime to use (override or manager's default)
-	runtime := m.agentRuntime
-	if opts.RuntimeOverride != nil {
-		if rt, ok := opts.RuntimeOverride.(agent.Runtime); ok {
-			runtime = rt
-		}
-	}
+	runtime := m.runtimeFor(opts)
 
 	// Start bidirectional streaming with session resumption - use background context
 	// so executor survives request completion
@@ -222,28 +197,37 @@ func (m *Manager) ResumeBidirectionalSession(ctx context.Context, existingSessio
 	return existingSession, executor, nil
 }
 
-// GetLatestSession returns the most recent session for a project, if any
-func (m *Manager) GetLatestSession(projectID string) (*Session, error) {
-	sessions, err := m.List(projectID, nil)
-	if err != nil {
-		return nil, err
+// containerWorkingDir returns the workspace's working directory inside the
+// container, which depends on the workspace isolation setting
+func containerWorkingDir(workspaceID string, isolation bool) string {
+	if isolation {
+		// Isolated mode: /workspace is mounted to workspaces/, so workingDir is /workspace/<uuid>
+		return fmt.Sprintf("/workspace/%s", workspaceID)
 	}
+	// Non-isolated mode: /workspace is mounted to project root
+	return fmt.Sprintf("/workspace/workspaces/%s", workspaceID)
+}
 
-	if len(sessions) == 0 {
-		return nil, nil
+// runtimeFor returns the runtime to use for a session (override or manager's default)
+func (m *Manager) runtimeFor(opts StartOptions) agent.Runtime {
+	if opts.RuntimeOverride != nil {
+		if rt, ok := opts.RuntimeOverride.(agent.Runtime); ok {
+			return rt
+		}
 	}
+	return m.agentRuntime
+}
 
-	// Find the most recent session
-	var latest *SessionSummary
-	for _, s := range sessions {
-		if latest == nil || s.UpdatedAt.After(latest.UpdatedAt) {
-			latest = s
-		}
+// GetLatestSession returns the most recently updated session for a project, if any
+func (m *Manager) GetLatestSession(projectID string) (*Session, error) {
+	if err := validation.ValidateProjectID(projectID); err != nil {
+		return nil, err
 	}
 
-	if latest == nil {
-		return nil, nil
+	sessionID, err := m.store.LatestID(projectID)
+	if err != nil || sessionID == "" {
+		return nil, err
 	}
 
-	return m.Load(latest.SessionID)
+	return m.Load(sessionID)
 }
@@ -2,6 +2,8 @@ package session
 
 import (
 	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
 )
 
 // Status represents the state of a session
@@ -30,6 +32,7 @@ type Session struct {
 	TotalCost        Cost      `json:"total_cost"`
 	// Recursion hierarchy fields
 	ParentSessionID *string                `json:"parent_session_id,omitempty"`
+	ForkedFromTurn  int                    `json:"forked_from_turn,omitempty"` // Set on forks; ParentSessionID is the source session
 	ChildSessions   []string               `json:"child_sessions,omitempty"`
 	Depth           int                    `json:"depth"`
 	ExplorationID   string                 `json:"exploration_id,omitempty"`
@@ -45,6 +48,29 @@ type Turn struct {
 	CompletedAt time.Time  `json:"completed_at"`
 	Output      TurnOutput `json:"output"`
 	Cost        Cost       `json:"cost"`
+
+	// Provider failover (see TurnAttempt)
+	Model    string        `json:"model,omitempty"`    // Fallback model that answered, when the turn failed over
+	Attempts []TurnAttempt `json:"attempts,omitempty"` // Retryable provider errors hit during the turn
+}
+
+// Outcomes of a retryable provider error
+const (
+	AttemptRetry    = "retry"    // Resent to the same model after a backoff
+	AttemptFailover = "failover" // Resent to the next fallback model
+	AttemptGaveUp   = "gave_up"  // Retries and fallbacks exhausted; the turn failed
+)
+
+// TurnAttempt records a retryable provider error during a turn and how the
+// turn recovered from it
+type TurnAttempt struct {
+	At        time.Time `json:"at"`
+	Model     string    `json:"model,omitempty"` // Model that failed (empty for the runtime's default)
+	Error     string    `json:"error"`
+	ErrorCode int       `json:"error_code,omitempty"` // Provider HTTP status, when known
+	Outcome   string    `json:"outcome"`
+	NextModel string    `json:"next_model,omitempty"` // Model the turn was resent to
+	Replaced  bool      `json:"replaced,omitempty"`   // The resend replaced the failed message instead of adding another
 }
 
 // TurnOutput contains the result of a turn
@@ -64,26 +90,34 @@ type Cost struct {
 
 // SessionSummary is a lightweight view of a session
 type SessionSummary struct {
-	SessionID   string    `json:"session_id"`
-	ProjectID   string    `json:"project_id"`
-	WorkspaceID string    `json:"workspace_id"`
-	Status      Status    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	TurnCount   int       `json:"turn_count"`
-	LastPrompt  string    `json:"last_prompt,omitempty"`
+	SessionID       string    `json:"session_id"`
+	ProjectID       string    `json:"project_id"`
+	WorkspaceID     string    `json:"workspace_id"`
+	Status          Status    `json:"status"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	TurnCount       int       `json:"turn_count"`
+	LastPrompt      string    `json:"last_prompt,omitempty"`
+	Model           string    `json:"model,omitempty"`
+	ParentSessionID string    `json:"parent_session_id,omitempty"`
+	ExplorationID   string    `json:"exploration_id,omitempty"`
 }
 
 // ToSummary converts a Session to SessionSummary
 func (s *Session) ToSummary() *SessionSummary {
 	summary := &SessionSummary{
-		SessionID:   s.SessionID,
-		ProjectID:   s.ProjectID,
-		WorkspaceID: s.WorkspaceID,
-		Status:      s.Status,
-		CreatedAt:   s.CreatedAt,
-		UpdatedAt:   s.UpdatedAt,
-		TurnCount:   len(s.Turns),
+		SessionID:     s.SessionID,
+		ProjectID:     s.ProjectID,
+		WorkspaceID:   s.WorkspaceID,
+		Status:        s.Status,
+		CreatedAt:     s.CreatedAt,
+		UpdatedAt:     s.UpdatedAt,
+		TurnCount:     len(s.Turns),
+		Model:         s.Model,
+		ExplorationID: s.ExplorationID,
+	}
+	if s.ParentSessionID != nil {
+		summary.ParentSessionID = *s.ParentSessionID
 	}
 	if len(s.Turns) > 0 {
 		summary.LastPrompt = s.Turns[len(s.Turns)-1].Prompt
@@ -103,4 +137,6 @@ type StartOptions struct {
 	WorkspaceIsolation bool     // When true, workingDir is /workspace/<uuid> instead of /workspace/workspaces/<uuid>
 
 	RuntimeOverride interface{} // agent.Runtime - use this runtime instead of manager's default (interface to avoid circular import)
+
+	Files []agent.FilePart // Files sent with the initial prompt (e.g., image attachments)
 }
@@ -0,0 +1,106 @@
+package terminal
+
+import (
+	"sync"
+)
+
+const (
+	// watcherBuffer is how many output chunks a watcher may fall behind by
+	// before it is dropped
+	watcherBuffer = 256
+	// replayBytes is how much recent output a new watcher is sent first, so it
+	// joins with something on screen
+	replayBytes = 16 * 1024
+)
+
+// Broadcaster fans a terminal's output out to read-only watchers. A watcher
+// that falls behind is dropped rather than slowing the terminal down.
+type Broadcaster struct {
+	mu       sync.Mutex
+	watchers map[*Watcher]struct{}
+	recent   []byte
+	closed   bool
+}
+
+// Watcher receives a terminal's output
+type Watcher struct {
+	// C delivers output chunks. It is closed when the terminal ends or the
+	// watcher falls too far behind.
+	C  <-chan []byte
+	ch chan []byte
+}
+
+// NewBroadcaster creates a broadcaster with no watchers
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{watchers: make(map[*Watcher]struct{})}
+}
+
+// Write sends output to every watcher
+func (b *Broadcaster) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return len(p), nil
+	}
+
+	b.recent = append(b.recent, p...)
+	if over := len(b.recent) - replayBytes; over > 0 {
+		b.recent = b.recent[over:]
+	}
+
+	chunk := append([]byte(nil), p...)
+	for w := range b.watchers {
+		select {
+		case w.ch <- chunk:
+		default:
+			delete(b.watchers, w)
+			close(w.ch)
+		}
+	}
+	return len(p), nil
+}
+
+// Watch adds a watcher, returning recent output to show before what arrives
+// on its channel. It returns nil once the broadcaster is closed.
+func (b *Broadcaster) Watch() (*Watcher, []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, nil
+	}
+	ch := make(chan []byte, watcherBuffer)
+	w := &Watcher{C: ch, ch: ch}
+	b.watchers[w] = struct{}{}
+	return w, append([]byte(nil), b.recent...)
+}
+
+// Unwatch removes a watcher
+func (b *Broadcaster) Unwatch(w *Watcher) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.watchers[w]; ok {
+		delete(b.watchers, w)
+		close(w.ch)
+	}
+}
+
+// Watchers returns the number of watchers
+func (b *Broadcaster) Watchers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.watchers)
+}
+
+// Close ends every watch
+func (b *Broadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for w := range b.watchers {
+		close(w.ch)
+	}
+	b.watchers = nil
+}
@@ -0,0 +1,159 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/websocket"
+)
+
+// Path is the server endpoint prefix; a project's terminal is at Path + project ID
+const Path = "/terminal/"
+
+const (
+	// DefaultShell is the shell started in the container
+	DefaultShell = "/bin/bash"
+	// DefaultTerm is the TERM the shell runs with
+	DefaultTerm = "xterm-256color"
+)
+
+// DialOptions describes the terminal to attach to
+type DialOptions struct {
+	ServerURL   string // http(s)://host:port of the Oubliette server
+	Token       string
+	ProjectID   string
+	WorkspaceID string // shell: workspace to start in (default: the project's default workspace)
+	Mode        string // ModeShell (default) or ModeWatch
+	TerminalID  string // watch: terminal to watch (default: the project's most recent)
+	Cols, Rows  uint
+	Record      bool // shell: record the session on the server
+}
+
+// URL returns the WebSocket URL for the options
+func (o DialOptions) URL() (string, error) {
+	u, err := url.Parse(o.ServerURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "ws":
+		u.Scheme = "ws"
+	case "https", "wss":
+		u.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("invalid server URL %q: scheme must be http or https", o.ServerURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + Path + url.PathEscape(o.ProjectID)
+
+	q := url.Values{}
+	if o.Mode != "" {
+		q.Set("mode", o.Mode)
+	}
+	if o.WorkspaceID != "" {
+		q.Set("workspace_id", o.WorkspaceID)
+	}
+	if o.TerminalID != "" {
+		q.Set("terminal_id", o.TerminalID)
+	}
+	if o.Cols > 0 && o.Rows > 0 {
+		q.Set("cols", strconv.FormatUint(uint64(o.Cols), 10))
+		q.Set("rows", strconv.FormatUint(uint64(o.Rows), 10))
+	}
+	if o.Record {
+		q.Set("record", "true")
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Dial connects to a terminal endpoint
+func Dial(opts DialOptions) (*websocket.Conn, error) {
+	location, err := opts.URL()
+	if err != nil {
+		return nil, err
+	}
+	origin := strings.Replace(strings.Replace(opts.ServerURL, "ws://", "http://", 1), "wss://", "https://", 1)
+	cfg, err := websocket.NewConfig(location, origin)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Header = http.Header{"Authorization": {"Bearer " + opts.Token}}
+
+	ws, err := websocket.DialConfig(cfg)
+	if err != nil {
+		var dialErr *websocket.DialError
+		if errors.As(err, &dialErr) && errors.Is(dialErr.Err, websocket.ErrBadStatus) {
+			return nil, fmt.Errorf("server refused the terminal (check the token's access to project %s)", opts.ProjectID)
+		}
+		return nil, err
+	}
+	return ws, nil
+}
+
+// Size is a terminal size
+type Size struct {
+	Cols, Rows uint
+}
+
+// ErrWatcherDropped is returned by Attach when the server stops sending a
+// watcher output because it fell too far behind
+var ErrWatcherDropped = errors.New("terminal output fell too far behind")
+
+// Attach connects a local terminal to an attached WebSocket until the remote
+// shell exits or the connection ends. The ready message is passed to onReady.
+// Input is sent only in shell mode; size changes from resize are forwarded.
+// It returns the shell's exit code.
+func Attach(ws *websocket.Conn, stdin io.Reader, stdout io.Writer, resize <-chan Size, onReady func(*Message)) (int, error) {
+	frame, err := Receive(ws)
+	if err != nil {
+		return -1, fmt.Errorf("failed to attach: %w", err)
+	}
+	if frame.Control == nil || frame.Control.Type != MessageReady {
+		if frame.Control != nil && frame.Control.Type == MessageError {
+			return -1, errors.New(frame.Control.Error)
+		}
+		return -1, errors.New("failed to attach: unexpected first message")
+	}
+	ready := frame.Control
+	if onReady != nil {
+		onReady(ready)
+	}
+
+	if ready.Mode == ModeShell {
+		go func() {
+			_, _ = io.Copy(Writer(ws), stdin)
+		}()
+		go func() {
+			for size := range resize {
+				_ = SendControl(ws, &Message{Type: MessageResize, Cols: size.Cols, Rows: size.Rows})
+			}
+		}()
+	}
+
+	for {
+		frame, err := Receive(ws)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return -1, errors.New("connection closed")
+			}
+			return -1, err
+		}
+		if frame.Control == nil {
+			if _, err := stdout.Write(frame.Data); err != nil {
+				return -1, err
+			}
+			continue
+		}
+		switch frame.Control.Type {
+		case MessageExit:
+			return frame.Control.ExitCode, nil
+		case MessageError:
+			return -1, errors.New(frame.Control.Error)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Package terminal carries interactive shells in project containers over
+// WebSocket: the wire protocol shared by the server and the attach client,
+// fan-out of a shell's output to read-only watchers, and asciicast
+// recordings of terminal sessions.
+//
+// Binary frames carry raw terminal bytes: keystrokes from the client, output
+// from the server. Text frames carry JSON control messages.
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/net/websocket"
+)
+
+// Modes a client attaches in
+const (
+	ModeShell = "shell" // interactive shell; input goes to the container
+	ModeWatch = "watch" // read-only view of another client's shell
+)
+
+// Control message types
+const (
+	MessageResize = "resize" // client → server: the client's terminal size changed
+	MessageReady  = "ready"  // server → client: the terminal is attached
+	MessageExit   = "exit"   // server → client: the shell exited
+	MessageError  = "error"  // server → client: the terminal failed
+)
+
+// Message is a control message sent as a text frame
+type Message struct {
+	Type string `json:"type"`
+
+	// resize
+	Cols uint `json:"cols,omitempty"`
+	Rows uint `json:"rows,omitempty"`
+
+	// ready
+	TerminalID string `json:"terminal_id,omitempty"`
+	Mode       string `json:"mode,omitempty"`
+	Recording  string `json:"recording,omitempty"` // recording file on the server, when recording
+
+	// exit
+	ExitCode int `json:"exit_code,omitempty"`
+
+	// error
+	Error string `json:"error,omitempty"`
+}
+
+// Frame is one WebSocket message: terminal bytes or a control message
+type Frame struct {
+	Data    []byte
+	Control *Message
+}
+
+// codec sends Data as binary frames and Control as JSON text frames
+var codec = websocket.Codec{
+	Marshal: func(v any) ([]byte, byte, error) {
+		frame, ok := v.(Frame)
+		if !ok {
+			return nil, 0, fmt.Errorf("terminal: cannot send %T", v)
+		}
+		if frame.Control != nil {
+			data, err := json.Marshal(frame.Control)
+			return data, websocket.TextFrame, err
+		}
+		return frame.Data, websocket.BinaryFrame, nil
+	},
+	Unmarshal: func(data []byte, payloadType byte, v any) error {
+		frame, ok := v.(*Frame)
+		if !ok {
+			return fmt.Errorf("terminal: cannot receive into %T", v)
+		}
+		*frame = Frame{}
+		if payloadType == websocket.TextFrame {
+			var msg Message
+			if err := json.Unmarshal(data, &msg); err != nil {
+				return fmt.Errorf("terminal: invalid control message: %w", err)
+			}
+			frame.Control = &msg
+			return nil
+		}
+		frame.Data = data
+		return nil
+	},
+}
+
+// SendData sends terminal bytes
+func SendData(ws *websocket.Conn, data []byte) error {
+	return codec.Send(ws, Frame{Data: data})
+}
+
+// SendControl sends a control message
+func SendControl(ws *websocket.Conn, msg *Message) error {
+	return codec.Send(ws, Frame{Control: msg})
+}
+
+// Receive reads the next frame
+func Receive(ws *websocket.Conn) (Frame, error) {
+	var frame Frame
+	err := codec.Receive(ws, &frame)
+	return frame, err
+}
+
+// Writer returns a writer that sends everything written as binary frames
+func Writer(ws *websocket.Conn) *FrameWriter {
+	return &FrameWriter{ws: ws}
+}
+
+// FrameWriter sends each write as a binary frame
+type FrameWriter struct {
+	ws *websocket.Conn
+}
+
+func (w *FrameWriter) Write(p []byte) (int, error) {
+	if err := SendData(w.ws, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
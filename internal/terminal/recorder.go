@@ -0,0 +1,131 @@
+package terminal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Recorder writes a terminal's output to a file in asciicast v2 format, which
+// `asciinema play` replays. Input is not recorded, so typed secrets stay out
+// of recordings unless the terminal echoes them.
+type Recorder struct {
+	mu      sync.Mutex
+	file    *os.File
+	w       *bufio.Writer
+	start   time.Time
+	partial []byte // incomplete UTF-8 sequence held back from the last write
+	err     error
+}
+
+// recordingHeader is the first line of an asciicast v2 file
+type recordingHeader struct {
+	Version   int               `json:"version"`
+	Width     uint              `json:"width"`
+	Height    uint              `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// NewRecorder creates a recording at path for a terminal of the given size
+func NewRecorder(path string, cols, rows uint, title string) (*Recorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recordings directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+
+	r := &Recorder{file: file, w: bufio.NewWriter(file), start: time.Now()}
+	r.writeLine(recordingHeader{
+		Version:   2,
+		Width:     cols,
+		Height:    rows,
+		Timestamp: r.start.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": DefaultTerm, "SHELL": DefaultShell},
+	})
+	if r.err != nil {
+		_ = file.Close()
+		return nil, r.err
+	}
+	return r, nil
+}
+
+// Write records terminal output
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Events are JSON strings, so a rune split across writes waits for its tail
+	data := append(r.partial, p...)
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	r.partial = append([]byte(nil), data[cut:]...)
+	if cut > 0 {
+		r.event("o", string(data[:cut]))
+	}
+	return len(p), r.err
+}
+
+// Resize records a terminal size change
+func (r *Recorder) Resize(cols, rows uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.event("r", fmt.Sprintf("%dx%d", cols, rows))
+}
+
+// Close flushes and closes the recording
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.partial) > 0 {
+		r.event("o", string(r.partial))
+		r.partial = nil
+	}
+	if err := r.w.Flush(); err != nil && r.err == nil {
+		r.err = err
+	}
+	if err := r.file.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+// event writes an [elapsed, type, data] line. Caller holds r.mu.
+func (r *Recorder) event(kind, data string) {
+	elapsed := time.Since(r.start).Seconds()
+	r.writeLine([]any{float64(int64(elapsed*1e6)) / 1e6, kind, data})
+	// Flush per event so a recording survives a server crash mid-session
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+}
+
+func (r *Recorder) writeLine(v any) {
+	if r.err != nil {
+		return
+	}
+	line, err := json.Marshal(v)
+	if err != nil {
+		r.err = err
+		return
+	}
+	if _, err := r.w.Write(append(line, '\n')); err != nil {
+		r.err = err
+	}
+}
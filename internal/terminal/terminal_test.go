@@ -0,0 +1,139 @@
+package terminal
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recordings", "term.cast")
+	rec, err := NewRecorder(path, 120, 40, "payments")
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+
+	// "é" split across writes must not be recorded as two invalid halves
+	_, _ = rec.Write([]byte("caf\xc3"))
+	_, _ = rec.Write([]byte("\xa9\r\n"))
+	rec.Resize(100, 30)
+	_, _ = rec.Write([]byte("done"))
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if _, err := NewRecorder(path, 80, 24, ""); err == nil {
+		t.Error("NewRecorder() over an existing recording succeeded")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	lines := bufio.NewScanner(f)
+
+	lines.Scan()
+	var header recordingHeader
+	if err := json.Unmarshal(lines.Bytes(), &header); err != nil {
+		t.Fatalf("invalid header %q: %v", lines.Text(), err)
+	}
+	if header.Version != 2 || header.Width != 120 || header.Height != 40 || header.Title != "payments" {
+		t.Errorf("header = %+v", header)
+	}
+
+	var events [][]any
+	for lines.Scan() {
+		var event []any
+		if err := json.Unmarshal(lines.Bytes(), &event); err != nil || len(event) != 3 {
+			t.Fatalf("invalid event %q: %v", lines.Text(), err)
+		}
+		events = append(events, event)
+	}
+	var kinds, data []string
+	for _, e := range events {
+		kinds = append(kinds, e[1].(string))
+		data = append(data, e[2].(string))
+	}
+	if got := strings.Join(kinds, ","); got != "o,o,r,o" {
+		t.Errorf("event types = %s, want o,o,r,o", got)
+	}
+	if got := strings.Join(data, "|"); got != "caf|é\r\n|100x30|done" {
+		t.Errorf("event data = %q", got)
+	}
+}
+
+func TestBroadcaster(t *testing.T) {
+	b := NewBroadcaster()
+	_, _ = b.Write([]byte("before "))
+
+	w, recent := b.Watch()
+	if string(recent) != "before " {
+		t.Errorf("Watch() recent = %q, want output so far", recent)
+	}
+	_, _ = b.Write([]byte("after"))
+	if got := string(<-w.C); got != "after" {
+		t.Errorf("watcher got %q, want %q", got, "after")
+	}
+
+	// A watcher that stops reading is dropped instead of blocking output
+	slow, _ := b.Watch()
+	for i := 0; i < watcherBuffer+1; i++ {
+		_, _ = b.Write([]byte("x"))
+	}
+	if b.Watchers() != 0 {
+		t.Errorf("Watchers() = %d after both fell behind, want 0", b.Watchers())
+	}
+	n := 0
+	for range slow.C {
+		n++
+	}
+	if n != watcherBuffer {
+		t.Errorf("slow watcher got %d chunks before being dropped, want %d", n, watcherBuffer)
+	}
+
+	b.Close()
+	if w, _ := b.Watch(); w != nil {
+		t.Error("Watch() after Close() returned a watcher")
+	}
+	if _, err := b.Write([]byte("late")); err != nil {
+		t.Errorf("Write() after Close() error = %v", err)
+	}
+}
+
+func TestDialOptionsURL(t *testing.T) {
+	tests := []struct {
+		opts DialOptions
+		want string
+	}{
+		{
+			DialOptions{ServerURL: "http://localhost:8080", ProjectID: "p1", Cols: 100, Rows: 30},
+			"ws://localhost:8080/terminal/p1?cols=100&rows=30",
+		},
+		{
+			DialOptions{ServerURL: "https://oubliette.example.com/", ProjectID: "p1", WorkspaceID: "w1", Record: true},
+			"wss://oubliette.example.com/terminal/p1?record=true&workspace_id=w1",
+		},
+		{
+			DialOptions{ServerURL: "http://localhost:8080", ProjectID: "p1", Mode: ModeWatch, TerminalID: "t1"},
+			"ws://localhost:8080/terminal/p1?mode=watch&terminal_id=t1",
+		},
+	}
+	for _, tt := range tests {
+		got, err := tt.opts.URL()
+		if err != nil {
+			t.Errorf("URL() error = %v", err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("URL() = %q, want %q", got, tt.want)
+		}
+	}
+
+	if _, err := (DialOptions{ServerURL: "localhost:8080"}).URL(); err == nil {
+		t.Error("URL() without a scheme succeeded")
+	}
+}
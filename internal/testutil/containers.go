@@ -23,6 +23,7 @@ type MockRuntime struct {
 	StartError      error
 	StopError       error
 	RemoveError     error
+	RenameError     error
 	ExecResponse    *container.ExecResult
 	ExecError       error
 	InspectResponse *container.ContainerInfo
@@ -36,15 +37,17 @@ type MockRuntime struct {
 	ImageExistsFunc func(imageName string) (bool, error)
 
 	// Call tracking
-	CreateCalls  []container.CreateConfig
-	StartCalls   []string
-	StopCalls    []string
-	RemoveCalls  []RemoveCall
-	ExecCalls    []ExecCall
-	InspectCalls []string
-	LogsCalls    []LogsCall
-	StatusCalls  []string
-	BuildCalls   []container.BuildConfig
+	CreateCalls      []container.CreateConfig
+	StartCalls       []string
+	StopCalls        []string
+	RemoveCalls      []RemoveCall
+	RenameCalls      []RenameCall
+	ExecCalls        []ExecCall
+	InspectCalls     []string
+	LogsCalls        []LogsCall
+	StatusCalls      []string
+	BuildCalls       []container.BuildConfig
+	RemoveImageCalls []string
 
 	// Container state (for stateful mocking)
 	Containers map[string]*container.ContainerInfo
@@ -56,6 +59,12 @@ type RemoveCall struct {
 	Force       bool
 }
 
+// RenameCall records a Rename call.
+type RenameCall struct {
+	ContainerID string
+	NewName     string
+}
+
 // ExecCall records an Exec call.
 type ExecCall struct {
 	ContainerID string
@@ -158,6 +167,22 @@ func (m *MockRuntime) Remove(ctx context.Context, containerID string, force bool
 	return nil
 }
 
+// Rename implements container.Runtime.
+func (m *MockRuntime) Rename(ctx context.Context, containerID, newName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.RenameCalls = append(m.RenameCalls, RenameCall{containerID, newName})
+	if m.RenameError != nil {
+		return m.RenameError
+	}
+
+	if info, ok := m.Containers[containerID]; ok {
+		info.Name = newName
+	}
+	return nil
+}
+
 // Exec implements container.Runtime.
 func (m *MockRuntime) Exec(ctx context.Context, containerID string, config container.ExecConfig) (*container.ExecResult, error) {
 	m.mu.Lock()
@@ -234,6 +259,21 @@ func (m *MockRuntime) Logs(ctx context.Context, containerID string, opts contain
 	return m.LogsResponse, nil
 }
 
+// FollowLogs implements container.Runtime. It writes LogsResponse and returns
+// as if the container had stopped.
+func (m *MockRuntime) FollowLogs(ctx context.Context, containerID string, opts container.LogsOptions, w io.Writer) error {
+	m.mu.Lock()
+	m.LogsCalls = append(m.LogsCalls, LogsCall{containerID, opts})
+	logs, err := m.LogsResponse, m.LogsError
+	m.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	_, _ = io.WriteString(w, logs)
+	return nil
+}
+
 // Status implements container.Runtime.
 func (m *MockRuntime) Status(ctx context.Context, containerID string) (container.ContainerStatus, error) {
 	m.mu.Lock()
@@ -276,6 +316,15 @@ func (m *MockRuntime) Pull(ctx context.Context, imageName string) error {
 	return nil
 }
 
+// RemoveImage implements container.Runtime.
+func (m *MockRuntime) RemoveImage(ctx context.Context, imageName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.RemoveImageCalls = append(m.RemoveImageCalls, imageName)
+	return nil
+}
+
 // Ping implements container.Runtime.
 func (m *MockRuntime) Ping(ctx context.Context) error {
 	return m.PingError
@@ -305,6 +354,7 @@ func (m *MockRuntime) Reset() {
 	m.StartCalls = nil
 	m.StopCalls = nil
 	m.RemoveCalls = nil
+	m.RenameCalls = nil
 	m.ExecCalls = nil
 	m.InspectCalls = nil
 	m.LogsCalls = nil
@@ -0,0 +1,791 @@
+package testutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/container"
+)
+
+// FakeRuntime is an in-memory container.Runtime that behaves like a real one:
+// containers have names, state, env, labels, mounts and logs, and commands run
+// by a small built-in shell. It passes the containertest conformance suite, so
+// code that drives containers can be tested without a daemon.
+//
+// The shell understands sh -c (or bash -c) scripts of ';'-separated simple
+// commands with $VAR expansion, the commands echo, cat, printenv, touch,
+// sleep, true, false, exit and exec, and a timeout wrapper around a command.
+// A shell started without -c reads its script from stdin line by line. Bind
+// mounts map to the host filesystem; everything else lives in memory per
+// container. Set ExecHandler to script other commands.
+type FakeRuntime struct {
+	// ExecHandler, if set, runs exec commands instead of the built-in shell.
+	// Returning handled=false falls back to the shell.
+	ExecHandler func(containerID string, cfg container.ExecConfig) (result *container.ExecResult, handled bool)
+
+	mu         sync.Mutex
+	containers map[string]*fakeContainer // by ID
+	images     map[string]bool
+}
+
+type fakeContainer struct {
+	info       container.ContainerInfo
+	cmd        []string
+	autoRemove bool
+	files      map[string][]byte // paths outside bind mounts
+	logs       fakeLog
+	ttySize    [2]uint // height, width of the last resized TTY exec
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewFakeRuntime creates an empty fake runtime
+func NewFakeRuntime() *FakeRuntime {
+	return &FakeRuntime{
+		containers: make(map[string]*fakeContainer),
+		images:     make(map[string]bool),
+	}
+}
+
+// AddImage makes an image available without pulling it
+func (f *FakeRuntime) AddImage(imageName string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.images[imageName] = true
+}
+
+// lookup finds a container by ID or name. Caller holds f.mu.
+func (f *FakeRuntime) lookup(ref string) (*fakeContainer, error) {
+	if c, ok := f.containers[ref]; ok {
+		return c, nil
+	}
+	for _, c := range f.containers {
+		if c.info.Name == ref {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("no such container: %s", ref)
+}
+
+// Create implements container.Runtime.
+func (f *FakeRuntime) Create(ctx context.Context, cfg container.CreateConfig) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if cfg.Name != "" {
+		if _, err := f.lookup(cfg.Name); err == nil {
+			return "", fmt.Errorf("failed to create container: name %q is already in use", cfg.Name)
+		}
+	}
+
+	idBytes := make([]byte, 32)
+	_, _ = rand.Read(idBytes)
+	id := hex.EncodeToString(idBytes)
+	name := cfg.Name
+	if name == "" {
+		name = id[:12]
+	}
+
+	mounts := slices.Clone(cfg.Mounts)
+	for _, ps := range cfg.PublishedSockets {
+		hostDir := filepath.Dir(ps.HostPath)
+		_ = os.MkdirAll(hostDir, 0o755)
+		mounts = append(mounts, container.Mount{
+			Type:   container.MountTypeBind,
+			Source: hostDir,
+			Target: path.Dir(ps.ContainerPath),
+		})
+	}
+
+	f.containers[id] = &fakeContainer{
+		info: container.ContainerInfo{
+			ID:        id,
+			Name:      name,
+			Image:     cfg.Image,
+			Status:    container.StatusCreated,
+			Mounts:    mounts,
+			Env:       slices.Clone(cfg.Env),
+			Labels:    maps.Clone(cfg.Labels),
+			CreatedAt: time.Now(),
+		},
+		cmd:        slices.Concat(cfg.Entrypoint, cfg.Cmd),
+		autoRemove: cfg.AutoRemove,
+		files:      make(map[string][]byte),
+	}
+	return id, nil
+}
+
+// Start implements container.Runtime. The container's command runs in the
+// background and its output goes to the container log.
+func (f *FakeRuntime) Start(ctx context.Context, containerID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.lookup(containerID)
+	if err != nil {
+		return fmt.Errorf("failed to start container: %w", err)
+	}
+	if c.info.Status == container.StatusRunning {
+		return nil
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	c.info.Status = container.StatusRunning
+	c.info.StartedAt = time.Now()
+
+	env := slices.Clone(c.info.Env)
+	go func(done chan struct{}) {
+		defer close(done)
+		f.run(runCtx, c, c.cmd, env, strings.NewReader(""), &c.logs, &c.logs)
+		cancel()
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		c.info.Status = container.StatusExited
+		if c.autoRemove {
+			delete(f.containers, c.info.ID)
+		}
+	}(c.done)
+	return nil
+}
+
+// Stop implements container.Runtime.
+func (f *FakeRuntime) Stop(ctx context.Context, containerID string) error {
+	f.mu.Lock()
+	c, err := f.lookup(containerID)
+	if err != nil {
+		f.mu.Unlock()
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+	cancel, done := c.cancel, c.done
+	f.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+	return nil
+}
+
+// Remove implements container.Runtime.
+func (f *FakeRuntime) Remove(ctx context.Context, containerID string, force bool) error {
+	f.mu.Lock()
+	c, err := f.lookup(containerID)
+	if err != nil {
+		f.mu.Unlock()
+		return fmt.Errorf("failed to remove container: %w", err)
+	}
+	running := c.info.Status == container.StatusRunning
+	f.mu.Unlock()
+
+	if running {
+		if !force {
+			return fmt.Errorf("failed to remove container: container %s is running", containerID)
+		}
+		_ = f.Stop(ctx, c.info.ID)
+	}
+
+	f.mu.Lock()
+	delete(f.containers, c.info.ID)
+	f.mu.Unlock()
+	return nil
+}
+
+// Rename implements container.Runtime.
+func (f *FakeRuntime) Rename(ctx context.Context, containerID, newName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.lookup(containerID)
+	if err != nil {
+		return fmt.Errorf("failed to rename container: %w", err)
+	}
+	if other, err := f.lookup(newName); err == nil && other != c {
+		return fmt.Errorf("failed to rename container: name %q is already in use", newName)
+	}
+	c.info.Name = newName
+	return nil
+}
+
+// runningContainer returns a container that can run exec commands
+func (f *FakeRuntime) runningContainer(containerID string) (*fakeContainer, []string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.lookup(containerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.info.Status != container.StatusRunning {
+		return nil, nil, fmt.Errorf("container %s is not running", containerID)
+	}
+	// Exec env is layered over the container's
+	return c, slices.Clone(c.info.Env), nil
+}
+
+// Exec implements container.Runtime.
+func (f *FakeRuntime) Exec(ctx context.Context, containerID string, cfg container.ExecConfig) (*container.ExecResult, error) {
+	c, env, err := f.runningContainer(containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exec in container: %w", err)
+	}
+	if f.ExecHandler != nil {
+		if result, handled := f.ExecHandler(c.info.ID, cfg); handled {
+			return result, nil
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := f.run(ctx, c, cfg.Cmd, append(env, cfg.Env...), strings.NewReader(""), &stdout, &stderr)
+	return &container.ExecResult{Stdout: stdout.String(), Stderr: stderr.String(), ExitCode: code}, nil
+}
+
+// ExecInteractive implements container.Runtime.
+func (f *FakeRuntime) ExecInteractive(ctx context.Context, containerID string, cfg container.ExecConfig) (*container.InteractiveExec, error) {
+	c, env, err := f.runningContainer(containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exec in container: %w", err)
+	}
+
+	stdinR, stdinW := io.Pipe()
+	stdoutR, stdoutW := io.Pipe()
+	stderrR, stderrW := io.Pipe()
+
+	// A terminal merges the streams
+	var errOut io.Writer = stderrW
+	if cfg.TTY {
+		errOut = stdoutW
+		_ = stderrW.Close()
+	}
+
+	done := make(chan struct{})
+	var code int
+	go func() {
+		defer close(done)
+		code = f.run(ctx, c, cfg.Cmd, append(env, cfg.Env...), stdinR, stdoutW, errOut)
+		// Unread input is discarded once the process exits
+		_ = stdinR.CloseWithError(errors.New("process exited"))
+		_ = stdoutW.Close()
+		_ = stderrW.Close()
+	}()
+
+	ie := container.NewInteractiveExec(stdinW, stdoutR, stderrR, func() (int, error) {
+		<-done
+		return code, nil
+	})
+	if cfg.TTY {
+		ie.SetResize(func(height, width uint) error {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			c.ttySize = [2]uint{height, width}
+			return nil
+		})
+	}
+	return ie, nil
+}
+
+// TerminalSize returns the size last set on a TTY exec in a container
+func (f *FakeRuntime) TerminalSize(containerID string) (height, width uint, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.lookup(containerID)
+	if err != nil {
+		return 0, 0, err
+	}
+	return c.ttySize[0], c.ttySize[1], nil
+}
+
+// Inspect implements container.Runtime.
+func (f *FakeRuntime) Inspect(ctx context.Context, containerID string) (*container.ContainerInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.lookup(containerID)
+	if err != nil {
+		return nil, err
+	}
+	info := c.info
+	info.Mounts = slices.Clone(info.Mounts)
+	info.Env = slices.Clone(info.Env)
+	info.Labels = maps.Clone(info.Labels)
+	return &info, nil
+}
+
+// Logs implements container.Runtime.
+func (f *FakeRuntime) Logs(ctx context.Context, containerID string, opts container.LogsOptions) (string, error) {
+	f.mu.Lock()
+	c, err := f.lookup(containerID)
+	f.mu.Unlock()
+	if err != nil {
+		return "", fmt.Errorf("failed to get container logs: %w", err)
+	}
+	return c.logs.tail(opts), nil
+}
+
+// FollowLogs implements container.Runtime.
+func (f *FakeRuntime) FollowLogs(ctx context.Context, containerID string, opts container.LogsOptions, w io.Writer) error {
+	f.mu.Lock()
+	c, err := f.lookup(containerID)
+	var done chan struct{}
+	if err == nil && c.info.Status == container.StatusRunning {
+		done = c.done
+	}
+	f.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to follow container logs: %w", err)
+	}
+
+	text, next, changed := c.logs.since(-1, opts)
+	_, _ = io.WriteString(w, text)
+	if done == nil {
+		return nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-done:
+			text, _, _ = c.logs.since(next, opts)
+			_, _ = io.WriteString(w, text)
+			return nil
+		case <-changed:
+			text, next, changed = c.logs.since(next, opts)
+			_, _ = io.WriteString(w, text)
+		}
+	}
+}
+
+// Status implements container.Runtime.
+func (f *FakeRuntime) Status(ctx context.Context, containerID string) (container.ContainerStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.lookup(containerID)
+	if err != nil {
+		return container.StatusUnknown, err
+	}
+	return c.info.Status, nil
+}
+
+// ListContainers implements container.Lister.
+func (f *FakeRuntime) ListContainers(ctx context.Context, prefix string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var names []string
+	for _, c := range f.containers {
+		if strings.HasPrefix(c.info.Name, prefix) {
+			names = append(names, c.info.Name)
+		}
+	}
+	return names, nil
+}
+
+// Build implements container.Runtime. It checks the Dockerfile exists and
+// records the image; nothing is executed.
+func (f *FakeRuntime) Build(ctx context.Context, cfg container.BuildConfig) error {
+	dockerfile := cfg.DockerfilePath
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	if !filepath.IsAbs(dockerfile) {
+		dockerfile = filepath.Join(cfg.ContextPath, dockerfile)
+	}
+	if _, err := os.Stat(dockerfile); err != nil {
+		return fmt.Errorf("failed to build image: %w", err)
+	}
+	if cfg.Output != nil {
+		_, _ = fmt.Fprintf(cfg.Output, "Successfully built %s\n", cfg.ImageName)
+	}
+
+	f.AddImage(cfg.ImageName)
+	return nil
+}
+
+// ImageExists implements container.Runtime.
+func (f *FakeRuntime) ImageExists(ctx context.Context, imageName string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.images[imageName], nil
+}
+
+// Pull implements container.Runtime. Every image name can be pulled.
+func (f *FakeRuntime) Pull(ctx context.Context, imageName string) error {
+	f.AddImage(imageName)
+	return nil
+}
+
+// RemoveImage implements container.Runtime.
+func (f *FakeRuntime) RemoveImage(ctx context.Context, imageName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.images, imageName)
+	return nil
+}
+
+// Ping implements container.Runtime.
+func (f *FakeRuntime) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close stops every running container.
+func (f *FakeRuntime) Close() error {
+	f.mu.Lock()
+	ids := slices.Collect(maps.Keys(f.containers))
+	f.mu.Unlock()
+
+	for _, id := range ids {
+		_ = f.Stop(context.Background(), id)
+	}
+	return nil
+}
+
+// Name implements container.Runtime.
+func (f *FakeRuntime) Name() string {
+	return "fake"
+}
+
+// IsAvailable implements container.Runtime.
+func (f *FakeRuntime) IsAvailable() bool {
+	return true
+}
+
+// run executes argv with the built-in shell and returns its exit code
+func (f *FakeRuntime) run(ctx context.Context, c *fakeContainer, argv []string, env []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(argv) == 0 {
+		return 0
+	}
+	lookupEnv := func(name string) (string, bool) {
+		// Later entries win, so exec env overrides container env
+		for i := len(env) - 1; i >= 0; i-- {
+			if k, v, ok := strings.Cut(env[i], "="); ok && k == name {
+				return v, true
+			}
+		}
+		return "", false
+	}
+
+	if argv[0] == "timeout" {
+		return f.timeout(ctx, c, argv[1:], env, stdin, stdout, stderr)
+	}
+	shell := path.Base(argv[0])
+	if shell != "sh" && shell != "bash" {
+		code, _ := f.command(ctx, c, argv, lookupEnv, stdin, stdout, stderr)
+		return code
+	}
+	if len(argv) < 3 || argv[1] != "-c" {
+		return f.interactiveShell(ctx, c, lookupEnv, stdin, stdout, stderr)
+	}
+	code, _ := f.script(ctx, c, argv[2], lookupEnv, stdin, stdout, stderr)
+	return code
+}
+
+// script runs a shell script, reporting whether it ended with exit or exec
+func (f *FakeRuntime) script(ctx context.Context, c *fakeContainer, script string, lookupEnv func(string) (string, bool), stdin io.Reader, stdout, stderr io.Writer) (int, bool) {
+	code := 0
+	for _, stmt := range strings.FieldsFunc(script, func(r rune) bool { return r == ';' || r == '\n' }) {
+		expanded := os.Expand(stmt, func(name string) string {
+			v, _ := lookupEnv(name)
+			return v
+		})
+		fields := strings.Fields(expanded)
+		if len(fields) == 0 {
+			continue
+		}
+		var exit bool
+		code, exit = f.command(ctx, c, fields, lookupEnv, stdin, stdout, stderr)
+		if exit || ctx.Err() != nil {
+			return code, exit
+		}
+	}
+	return code, false
+}
+
+// interactiveShell runs each line read from stdin until exit or end of input
+func (f *FakeRuntime) interactiveShell(ctx context.Context, c *fakeContainer, lookupEnv func(string) (string, bool), stdin io.Reader, stdout, stderr io.Writer) int {
+	lines := bufio.NewReader(stdin)
+	code := 0
+	for ctx.Err() == nil {
+		line, err := lines.ReadString('\n')
+		if line != "" {
+			var exit bool
+			// Commands that read stdin share the shell's buffered input
+			if code, exit = f.script(ctx, c, line, lookupEnv, lines, stdout, stderr); exit {
+				return code
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	return code
+}
+
+// timeout runs a command with a time limit like coreutils timeout, exiting 124
+// when the limit is reached
+func (f *FakeRuntime) timeout(ctx context.Context, c *fakeContainer, args []string, env []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		if args[0] == "-k" || args[0] == "-s" {
+			args = args[1:]
+		}
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		_, _ = fmt.Fprintln(stderr, "timeout: missing operand")
+		return 125
+	}
+	seconds, err := strconv.ParseFloat(strings.TrimSuffix(args[0], "s"), 64)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "timeout: invalid time interval '%s'\n", args[0])
+		return 125
+	}
+
+	limitCtx, cancel := context.WithTimeout(ctx, time.Duration(seconds*float64(time.Second)))
+	defer cancel()
+	code := f.run(limitCtx, c, args[1:], env, stdin, stdout, stderr)
+	if ctx.Err() == nil && limitCtx.Err() != nil {
+		return 124
+	}
+	return code
+}
+
+// command runs a single built-in command. exit reports whether the shell
+// should stop after it.
+func (f *FakeRuntime) command(ctx context.Context, c *fakeContainer, argv []string, lookupEnv func(string) (string, bool), stdin io.Reader, stdout, stderr io.Writer) (code int, exit bool) {
+	name, args := argv[0], argv[1:]
+	switch name {
+	case "exec":
+		// exec -a NAME only renames the process
+		if len(args) >= 2 && args[0] == "-a" {
+			args = args[2:]
+		}
+		if len(args) == 0 {
+			return 0, true
+		}
+		if shell := path.Base(args[0]); (shell == "sh" || shell == "bash") && (len(args) < 2 || args[1] != "-c") {
+			return f.interactiveShell(ctx, c, lookupEnv, stdin, stdout, stderr), true
+		}
+		code, _ := f.command(ctx, c, args, lookupEnv, stdin, stdout, stderr)
+		return code, true
+
+	case "exit":
+		n := 0
+		if len(args) > 0 {
+			n, _ = strconv.Atoi(args[0])
+		}
+		return n, true
+
+	case "true":
+		return 0, false
+
+	case "false":
+		return 1, false
+
+	case "echo":
+		_, _ = fmt.Fprintln(stdout, strings.Join(args, " "))
+		return 0, false
+
+	case "printenv":
+		if len(args) == 0 {
+			return 0, false
+		}
+		v, ok := lookupEnv(args[0])
+		if !ok {
+			return 1, false
+		}
+		_, _ = fmt.Fprintln(stdout, v)
+		return 0, false
+
+	case "cat":
+		if len(args) == 0 {
+			_, _ = io.Copy(stdout, stdin)
+			return 0, false
+		}
+		code := 0
+		for _, p := range args {
+			data, err := f.readFile(c, p)
+			if err != nil {
+				_, _ = fmt.Fprintf(stderr, "cat: can't open '%s': No such file or directory\n", p)
+				code = 1
+				continue
+			}
+			_, _ = stdout.Write(data)
+		}
+		return code, false
+
+	case "touch":
+		code := 0
+		for _, p := range args {
+			if err := f.touchFile(c, p); err != nil {
+				_, _ = fmt.Fprintf(stderr, "touch: %s: %v\n", p, err)
+				code = 1
+			}
+		}
+		return code, false
+
+	case "sleep":
+		seconds := 0.0
+		if len(args) > 0 {
+			seconds, _ = strconv.ParseFloat(args[0], 64)
+		}
+		select {
+		case <-ctx.Done():
+			return 143, true // terminated by SIGTERM
+		case <-time.After(time.Duration(seconds * float64(time.Second))):
+			return 0, false
+		}
+
+	default:
+		_, _ = fmt.Fprintf(stderr, "sh: %s: not found\n", name)
+		return 127, false
+	}
+}
+
+// resolve maps a container path to a host path through the container's bind
+// mounts, reporting whether it is mounted and read-only
+func (f *FakeRuntime) resolve(c *fakeContainer, p string) (hostPath string, mounted, readOnly bool) {
+	p = path.Clean(p)
+	best := -1
+	f.mu.Lock()
+	mounts := c.info.Mounts
+	f.mu.Unlock()
+	for _, m := range mounts {
+		if m.Type != container.MountTypeBind && m.Type != "" {
+			continue
+		}
+		target := path.Clean(m.Target)
+		if (p == target || strings.HasPrefix(p, target+"/")) && len(target) > best {
+			best = len(target)
+			hostPath = filepath.Join(m.Source, filepath.FromSlash(strings.TrimPrefix(p, target)))
+			readOnly = m.ReadOnly
+		}
+	}
+	return hostPath, best >= 0, readOnly
+}
+
+func (f *FakeRuntime) readFile(c *fakeContainer, p string) ([]byte, error) {
+	if hostPath, mounted, _ := f.resolve(c, p); mounted {
+		return os.ReadFile(hostPath)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := c.files[path.Clean(p)]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
+
+func (f *FakeRuntime) touchFile(c *fakeContainer, p string) error {
+	hostPath, mounted, readOnly := f.resolve(c, p)
+	if readOnly {
+		return errors.New("Read-only file system")
+	}
+	if mounted {
+		file, err := os.OpenFile(hostPath, os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		return file.Close()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := c.files[path.Clean(p)]; !ok {
+		c.files[path.Clean(p)] = nil
+	}
+	return nil
+}
+
+// fakeLog collects a container's output as timestamped lines
+type fakeLog struct {
+	mu      sync.Mutex
+	lines   []fakeLogLine
+	partial []byte
+	changed chan struct{} // closed when lines are added
+}
+
+type fakeLogLine struct {
+	at   time.Time
+	text string
+}
+
+func (l *fakeLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.partial = append(l.partial, p...)
+	for {
+		i := bytes.IndexByte(l.partial, '\n')
+		if i < 0 {
+			break
+		}
+		l.lines = append(l.lines, fakeLogLine{at: time.Now(), text: string(l.partial[:i+1])})
+		l.partial = l.partial[i+1:]
+		if l.changed != nil {
+			close(l.changed)
+			l.changed = nil
+		}
+	}
+	return len(p), nil
+}
+
+// tail renders the log the way the runtimes' logs commands do
+func (l *fakeLog) tail(opts container.LogsOptions) string {
+	text, _, _ := l.since(-1, opts)
+	return text
+}
+
+// since renders the lines from index from on (or the opts.Tail lines when
+// from is negative), and returns the index of the next line and a channel
+// closed when more lines arrive
+func (l *fakeLog) since(from int, opts container.LogsOptions) (string, int, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.changed == nil {
+		l.changed = make(chan struct{})
+	}
+
+	lines := l.lines
+	if from >= 0 {
+		lines = lines[min(from, len(lines)):]
+	} else if n, err := strconv.Atoi(opts.Tail); err == nil && n >= 0 && n < len(lines) {
+		lines = lines[len(lines)-n:]
+	}
+	text := l.render(lines, opts)
+	return text, len(l.lines), l.changed
+}
+
+func (l *fakeLog) render(lines []fakeLogLine, opts container.LogsOptions) string {
+	var b strings.Builder
+	for _, line := range lines {
+		if opts.Timestamps {
+			b.WriteString(line.at.UTC().Format(time.RFC3339Nano))
+			b.WriteByte(' ')
+		}
+		b.WriteString(line.text)
+	}
+	return b.String()
+}
+
+// Verify FakeRuntime implements Runtime interface
+var _ container.Runtime = (*FakeRuntime)(nil)
+var _ container.Lister = (*FakeRuntime)(nil)
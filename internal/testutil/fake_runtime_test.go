@@ -0,0 +1,14 @@
+package testutil_test
+
+import (
+	"testing"
+
+	"github.com/HyphaGroup/oubliette/internal/container/containertest"
+	"github.com/HyphaGroup/oubliette/internal/testutil"
+)
+
+func TestFakeRuntimeConformance(t *testing.T) {
+	rt := testutil.NewFakeRuntime()
+	defer func() { _ = rt.Close() }()
+	containertest.Run(t, rt, containertest.Config{})
+}
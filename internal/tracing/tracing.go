@@ -0,0 +1,121 @@
+// Package tracing provides OpenTelemetry tracing for Oubliette.
+//
+// Spans cover MCP tool calls, container starts, OpenCode requests, agent turns
+// and child session spawns. Trace context crosses the relay socket in the
+// OUBLIETTE-UPSTREAM handshake, so a recursive exploration is a single trace.
+//
+// When tracing is not configured the global no-op tracer is used and all
+// helpers are cheap.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Defaults
+const (
+	DefaultServiceName  = "oubliette"
+	instrumentationName = "github.com/HyphaGroup/oubliette"
+)
+
+// Config configures OTLP trace export
+type Config struct {
+	Endpoint    string  // OTLP/HTTP collector host:port (empty = OTEL_EXPORTER_OTLP_* env or localhost:4318)
+	Insecure    bool    // Use plain HTTP instead of HTTPS
+	ServiceName string  // Defaults to DefaultServiceName
+	SampleRatio float64 // Fraction of new traces sampled; 0 means 1.0 (sample everything)
+	Version     string  // Reported as service.version
+}
+
+var propagator = propagation.TraceContext{}
+
+// Setup installs an OTLP/HTTP exporter as the global tracer provider. The returned
+// function flushes pending spans and shuts the exporter down.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	var opts []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+	attrs := []attribute.KeyValue{attribute.String("service.name", serviceName)}
+	if cfg.Version != "" {
+		attrs = append(attrs, attribute.String("service.version", cfg.Version))
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span as a child of any span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on the span (if any) and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Detach returns a background context carrying only the span of ctx, for work
+// that outlives the request (e.g. relay connections) but belongs to its trace
+func Detach(ctx context.Context) context.Context {
+	return trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
+}
+
+// TraceParent encodes the span context of ctx as a W3C traceparent value.
+// Returns "" when ctx carries no valid span.
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// WithTraceParent returns ctx with the remote span context decoded from a
+// W3C traceparent value. Invalid values leave ctx unchanged.
+func WithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier{"traceparent": traceParent})
+}
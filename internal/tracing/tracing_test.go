@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prev)
+		_ = provider.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+func TestTraceParent_RoundTrip(t *testing.T) {
+	setupRecorder(t)
+
+	ctx, span := Start(context.Background(), "parent")
+	defer span.End()
+
+	tp := TraceParent(ctx)
+	if tp == "" {
+		t.Fatal("expected traceparent for sampled span")
+	}
+
+	remote := WithTraceParent(context.Background(), tp)
+	_, child := Start(remote, "child")
+	defer child.End()
+
+	if child.SpanContext().TraceID() != span.SpanContext().TraceID() {
+		t.Errorf("child trace ID = %s, want %s", child.SpanContext().TraceID(), span.SpanContext().TraceID())
+	}
+}
+
+func TestTraceParent_NoSpan(t *testing.T) {
+	if tp := TraceParent(context.Background()); tp != "" {
+		t.Errorf("TraceParent() = %q, want empty", tp)
+	}
+	if tp := TraceParent(WithTraceParent(context.Background(), "not-a-traceparent")); tp != "" {
+		t.Errorf("invalid traceparent produced span context %q", tp)
+	}
+}
+
+func TestDetach(t *testing.T) {
+	setupRecorder(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, span := Start(ctx, "request")
+	defer span.End()
+
+	detached := Detach(ctx)
+	cancel()
+
+	if detached.Err() != nil {
+		t.Error("detached context should not be cancelled with its parent")
+	}
+	if TraceParent(detached) != TraceParent(ctx) {
+		t.Error("detached context should carry the parent span")
+	}
+}
+
+func TestEnd_RecordsError(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	_, span := Start(context.Background(), "failing")
+	End(span, errors.New("boom"))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("ended spans = %d, want 1", len(spans))
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("status = %v, want Error", spans[0].Status().Code)
+	}
+}
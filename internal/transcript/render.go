@@ -0,0 +1,304 @@
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// Format is a transcript output format
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+	FormatJSON     Format = "json"
+)
+
+// ParseFormat parses a format name. An empty name is Markdown.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "", "markdown", "md":
+		return FormatMarkdown, nil
+	case "html":
+		return FormatHTML, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unknown format %q (valid: markdown, html, json)", name)
+	}
+}
+
+// Extension returns the file extension for the format
+func (f Format) Extension() string {
+	switch f {
+	case FormatHTML:
+		return ".html"
+	case FormatJSON:
+		return ".json"
+	default:
+		return ".md"
+	}
+}
+
+// Render renders the transcript in the given format
+func (t *Transcript) Render(format Format) ([]byte, error) {
+	switch format {
+	case FormatMarkdown:
+		return []byte(t.Markdown()), nil
+	case FormatHTML:
+		return t.HTML()
+	case FormatJSON:
+		return json.MarshalIndent(t, "", "  ")
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// Markdown renders the transcript as Markdown. Child sessions link to
+// <session_id>.md so exports of a session tree can sit side by side.
+func (t *Transcript) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Session %s\n\n", t.SessionID)
+	b.WriteString("| | |\n|---|---|\n")
+	for _, row := range t.summary() {
+		fmt.Fprintf(&b, "| %s | %s |\n", row[0], strings.ReplaceAll(row[1], "|", "\\|"))
+	}
+
+	for _, turn := range t.Turns {
+		fmt.Fprintf(&b, "\n## Turn %d\n\n", turn.Number)
+		if !turn.StartedAt.IsZero() {
+			fmt.Fprintf(&b, "_Started %s_\n\n", formatTime(turn.StartedAt))
+		}
+		b.WriteString("**Prompt**\n\n")
+		b.WriteString(quote(turn.Prompt))
+
+		for _, e := range turn.Entries {
+			b.WriteString("\n")
+			switch e.Kind {
+			case KindAssistant:
+				b.WriteString("**Assistant**\n\n")
+				b.WriteString(strings.TrimSpace(e.Text) + "\n")
+			case KindReasoning:
+				b.WriteString("<details><summary>Reasoning</summary>\n\n")
+				b.WriteString(strings.TrimSpace(e.Text) + "\n\n</details>\n")
+			case KindTool:
+				fmt.Fprintf(&b, "**Tool call: `%s`**", e.Tool)
+				if e.IsError {
+					b.WriteString(" (failed)")
+				}
+				b.WriteString("\n\n")
+				if params := e.formatParameters(); params != "" {
+					b.WriteString(codeBlock("json", params))
+				}
+				if e.Result != "" {
+					b.WriteString("\n<details><summary>" + e.resultLabel() + "</summary>\n\n")
+					b.WriteString(codeBlock("", e.Result))
+					b.WriteString("\n</details>\n")
+				}
+			case KindError:
+				b.WriteString("**Error**\n\n")
+				b.WriteString(quote(e.Text))
+			}
+		}
+
+		if turn.Tokens != (Tokens{}) {
+			fmt.Fprintf(&b, "\n_Tokens: %s_\n", turn.Tokens)
+		}
+	}
+
+	if len(t.Children) > 0 {
+		b.WriteString("\n## Child sessions\n\n")
+		for _, c := range t.Children {
+			fmt.Fprintf(&b, "- [`%s`](%s.md) — %s, %d turns, %s tokens", c.SessionID, c.SessionID, c.Status, c.Turns, c.Tokens)
+			if c.Prompt != "" {
+				fmt.Fprintf(&b, ": %s", firstLine(c.Prompt))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	fmt.Fprintf(&b, "\n---\n\n_Exported %s by oubliette_\n", formatTime(t.ExportedAt))
+	return b.String()
+}
+
+// HTML renders the transcript as a self-contained HTML page. Child sessions
+// link to <session_id>.html.
+func (t *Transcript) HTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, t); err != nil {
+		return nil, fmt.Errorf("failed to render transcript: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// summary returns the header rows shown above the turns
+func (t *Transcript) summary() [][2]string {
+	rows := [][2]string{
+		{"Project", t.ProjectID},
+	}
+	if t.WorkspaceID != "" {
+		rows = append(rows, [2]string{"Workspace", t.WorkspaceID})
+	}
+	switch {
+	case t.ForkedFromTurn > 0:
+		rows = append(rows, [2]string{"Forked from", fmt.Sprintf("%s at turn %d", t.ParentSessionID, t.ForkedFromTurn)})
+	case t.ParentSessionID != "":
+		rows = append(rows, [2]string{"Parent session", t.ParentSessionID})
+	}
+	rows = append(rows, [2]string{"Status", t.Status})
+	if t.Model != "" {
+		rows = append(rows, [2]string{"Model", t.Model})
+	}
+	rows = append(rows,
+		[2]string{"Created", formatTime(t.CreatedAt)},
+		[2]string{"Updated", formatTime(t.UpdatedAt)},
+		[2]string{"Turns", fmt.Sprint(len(t.Turns))},
+		[2]string{"Tokens", t.Tokens.String()},
+	)
+	return rows
+}
+
+// formatParameters returns the tool parameters as indented JSON, truncated
+func (e Entry) formatParameters() string {
+	if len(e.Parameters) == 0 {
+		return ""
+	}
+	data, err := json.MarshalIndent(e.Parameters, "", "  ")
+	if err != nil {
+		return ""
+	}
+	params, truncated := Truncate(string(data), MaxResultLength)
+	if truncated {
+		params += "\n… (truncated)"
+	}
+	return params
+}
+
+func (e Entry) resultLabel() string {
+	label := "Result"
+	if e.IsError {
+		label = "Error result"
+	}
+	if e.ResultTruncated {
+		label += fmt.Sprintf(" (truncated to %d characters)", MaxResultLength)
+	}
+	return label
+}
+
+// codeBlock fences s with more backticks than it contains in a row
+func codeBlock(lang, s string) string {
+	fence := "```"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + strings.TrimRight(s, "\n") + "\n" + fence + "\n"
+}
+
+// quote formats text as a Markdown block quote
+func quote(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "> _(empty)_\n"
+	}
+	return "> " + strings.ReplaceAll(s, "\n", "\n> ") + "\n"
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i] + " …"
+	}
+	return s
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+var htmlTemplate = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"time":        formatTime,
+	"firstLine":   firstLine,
+	"summary":     (*Transcript).summary,
+	"params":      Entry.formatParameters,
+	"resultLabel": Entry.resultLabel,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Session {{.SessionID}}</title>
+<style>
+body { font: 15px/1.5 -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; max-width: 960px; margin: 2em auto; padding: 0 1em; }
+h1 { font-size: 1.5em; word-break: break-all; }
+h2 { font-size: 1.2em; border-bottom: 1px solid #d0d7de; padding-bottom: .3em; margin-top: 2em; }
+table.summary td { padding: .15em 1em .15em 0; vertical-align: top; }
+table.summary td:first-child { color: #59636e; }
+.muted { color: #59636e; font-size: .9em; }
+.prompt { background: #f6f8fa; border-left: 4px solid #0969da; padding: .5em 1em; white-space: pre-wrap; }
+.entry { margin: 1em 0; }
+.label { font-weight: 600; }
+.text { white-space: pre-wrap; }
+.error { border-left: 4px solid #cf222e; padding: .5em 1em; background: #ffebe9; white-space: pre-wrap; }
+.failed { color: #cf222e; }
+pre { background: #f6f8fa; padding: .75em; overflow-x: auto; font-size: .85em; }
+code, pre { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }
+details summary { cursor: pointer; color: #59636e; }
+footer { margin-top: 3em; border-top: 1px solid #d0d7de; padding-top: .5em; }
+</style>
+</head>
+<body>
+<h1>Session {{.SessionID}}</h1>
+<table class="summary">
+{{- range summary .}}
+<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>
+{{- end}}
+</table>
+{{- range .Turns}}
+<h2>Turn {{.Number}}</h2>
+{{- if not .StartedAt.IsZero}}
+<p class="muted">Started {{time .StartedAt}}</p>
+{{- end}}
+<div class="prompt">{{.Prompt}}</div>
+{{- range .Entries}}
+<div class="entry">
+{{- if eq .Kind "assistant"}}
+<div class="label">Assistant</div>
+<div class="text">{{.Text}}</div>
+{{- else if eq .Kind "reasoning"}}
+<details><summary>Reasoning</summary><div class="text">{{.Text}}</div></details>
+{{- else if eq .Kind "tool"}}
+<div class="label">Tool call: <code>{{.Tool}}</code>{{if .IsError}} <span class="failed">(failed)</span>{{end}}</div>
+{{- with params .}}
+<pre>{{.}}</pre>
+{{- end}}
+{{- if .Result}}
+<details><summary>{{resultLabel .}}</summary><pre>{{.Result}}</pre></details>
+{{- end}}
+{{- else if eq .Kind "error"}}
+<div class="error">{{.Text}}</div>
+{{- end}}
+</div>
+{{- end}}
+{{- if or .Tokens.Input .Tokens.Output}}
+<p class="muted">Tokens: {{.Tokens}}</p>
+{{- end}}
+{{- end}}
+{{- if .Children}}
+<h2>Child sessions</h2>
+<ul>
+{{- range .Children}}
+<li><a href="{{.SessionID}}.html"><code>{{.SessionID}}</code></a> — {{.Status}}, {{.Turns}} turns, {{.Tokens}} tokens{{with .Prompt}}: {{firstLine .}}{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+<footer class="muted">Exported {{time .ExportedAt}} by oubliette</footer>
+</body>
+</html>
+`))
@@ -0,0 +1,259 @@
+// Package transcript builds a readable record of a session from its metadata
+// and persisted event log, and renders it as Markdown, self-contained HTML or
+// JSON for attaching to pull requests and incident reviews.
+package transcript
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
+	"github.com/HyphaGroup/oubliette/internal/session"
+)
+
+// MaxResultLength is the number of characters of a tool result or tool
+// parameters kept in a transcript
+const MaxResultLength = 2000
+
+// Entry kinds
+const (
+	KindAssistant = "assistant"
+	KindReasoning = "reasoning"
+	KindTool      = "tool"
+	KindError     = "error"
+)
+
+// Transcript is the full conversation of one session
+type Transcript struct {
+	SessionID       string    `json:"session_id"`
+	ProjectID       string    `json:"project_id"`
+	WorkspaceID     string    `json:"workspace_id,omitempty"`
+	ParentSessionID string    `json:"parent_session_id,omitempty"`
+	ForkedFromTurn  int       `json:"forked_from_turn,omitempty"`
+	Status          string    `json:"status"`
+	Model           string    `json:"model,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	ExportedAt      time.Time `json:"exported_at"`
+	Turns           []Turn    `json:"turns"`
+	Children        []Child   `json:"children,omitempty"`
+	Tokens          Tokens    `json:"tokens"`
+}
+
+// Turn is a prompt and everything the agent did in response
+type Turn struct {
+	Number      int       `json:"number"`
+	Prompt      string    `json:"prompt"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at,omitzero"`
+	Entries     []Entry   `json:"entries"`
+	Tokens      Tokens    `json:"tokens"`
+}
+
+// Entry is one piece of agent output: text, reasoning, a tool call with its
+// result, or an error
+type Entry struct {
+	Kind            string         `json:"kind"`
+	Time            time.Time      `json:"time"`
+	Text            string         `json:"text,omitempty"`
+	Tool            string         `json:"tool,omitempty"`
+	ToolID          string         `json:"tool_id,omitempty"`
+	Parameters      map[string]any `json:"parameters,omitempty"`
+	Result          string         `json:"result,omitempty"`
+	ResultTruncated bool           `json:"result_truncated,omitempty"`
+	IsError         bool           `json:"is_error,omitempty"`
+
+	id string // Runtime part ID, used to coalesce repeated updates
+}
+
+// Child is a session spawned by the transcript's session
+type Child struct {
+	SessionID string `json:"session_id"`
+	Status    string `json:"status"`
+	Prompt    string `json:"prompt,omitempty"`
+	Turns     int    `json:"turns"`
+	Tokens    Tokens `json:"tokens"`
+}
+
+// Tokens counts model token usage
+type Tokens struct {
+	Input  int `json:"input"`
+	Output int `json:"output"`
+}
+
+// Load builds the transcript of a session using the manager's session
+// metadata and event logs
+func Load(mgr *session.Manager, sessionID string) (*Transcript, error) {
+	sess, err := mgr.Load(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := mgr.LoadEvents(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[string]*session.Session, len(sess.ChildSessions))
+	for _, childID := range sess.ChildSessions {
+		if child, err := mgr.Load(childID); err == nil {
+			children[childID] = child
+		}
+	}
+	return Build(sess, events, children), nil
+}
+
+// Build assembles a transcript from a session, its persisted events in order,
+// and its child sessions by ID. Children missing from the map are listed as
+// unavailable.
+func Build(sess *session.Session, events []*session.BufferedEvent, children map[string]*session.Session) *Transcript {
+	t := &Transcript{
+		SessionID:   sess.SessionID,
+		ProjectID:   sess.ProjectID,
+		WorkspaceID: sess.WorkspaceID,
+		Status:      string(sess.Status),
+		Model:       sess.Model,
+		CreatedAt:   sess.CreatedAt,
+		UpdatedAt:   sess.UpdatedAt,
+		ExportedAt:  time.Now().UTC(),
+	}
+	if sess.ParentSessionID != nil {
+		t.ParentSessionID = *sess.ParentSessionID
+		t.ForkedFromTurn = sess.ForkedFromTurn
+	}
+
+	for _, turn := range sess.Turns {
+		t.Turns = append(t.Turns, Turn{
+			Number:      turn.TurnNumber,
+			Prompt:      turn.Prompt,
+			StartedAt:   turn.StartedAt,
+			CompletedAt: turn.CompletedAt,
+		})
+	}
+	if len(t.Turns) == 0 && len(events) > 0 {
+		t.Turns = []Turn{{Number: 1, StartedAt: events[0].Timestamp}}
+	}
+
+	tools := map[string]int{}
+	previous := -1
+	for i, current := range session.TurnIndexes(sess.Turns, events) {
+		if current < 0 {
+			// Events without saved turns go to the synthesized first turn
+			current = 0
+		}
+		if current != previous {
+			tools = map[string]int{}
+			previous = current
+		}
+		be := events[i]
+		turn := &t.Turns[current]
+		event := be.Event
+
+		turn.Tokens.Input += event.InputTokens
+		turn.Tokens.Output += event.OutputTokens
+
+		switch event.Type {
+		case agent.StreamEventMessage:
+			if isPromptEcho(event.Text, turn.Prompt) {
+				continue
+			}
+			turn.addText(KindAssistant, event, be.Timestamp)
+		case agent.StreamEventSystem:
+			if event.Subtype == "reasoning" && event.Text != "" {
+				turn.addText(KindReasoning, event, be.Timestamp)
+			}
+		case agent.StreamEventToolCall:
+			tools[event.ToolID] = len(turn.Entries)
+			turn.Entries = append(turn.Entries, Entry{
+				Kind:       KindTool,
+				Time:       be.Timestamp,
+				Tool:       event.ToolName,
+				ToolID:     event.ToolID,
+				Parameters: event.Parameters,
+			})
+		case agent.StreamEventToolResult:
+			j, ok := tools[event.ToolID]
+			if !ok || event.ToolID == "" {
+				j = len(turn.Entries)
+				turn.Entries = append(turn.Entries, Entry{Kind: KindTool, Time: be.Timestamp, Tool: event.ToolName, ToolID: event.ToolID})
+			}
+			turn.Entries[j].Result, turn.Entries[j].ResultTruncated = Truncate(event.Value, MaxResultLength)
+			turn.Entries[j].IsError = event.IsError
+		case agent.StreamEventError:
+			text := event.Text
+			if text == "" {
+				text = event.Value
+			}
+			turn.Entries = append(turn.Entries, Entry{Kind: KindError, Time: be.Timestamp, Text: text})
+		case agent.StreamEventCompletion:
+			if turn.CompletedAt.IsZero() {
+				turn.CompletedAt = be.Timestamp
+			}
+		}
+	}
+
+	for _, turn := range t.Turns {
+		t.Tokens.Input += turn.Tokens.Input
+		t.Tokens.Output += turn.Tokens.Output
+	}
+	if t.Tokens == (Tokens{}) {
+		// Sessions recorded before event logs only have their totals
+		t.Tokens = Tokens{Input: sess.TotalCost.InputTokens, Output: sess.TotalCost.OutputTokens}
+	}
+
+	for _, childID := range sess.ChildSessions {
+		child, ok := children[childID]
+		if !ok {
+			t.Children = append(t.Children, Child{SessionID: childID, Status: "unavailable"})
+			continue
+		}
+		c := Child{
+			SessionID: childID,
+			Status:    string(child.Status),
+			Turns:     len(child.Turns),
+			Tokens:    Tokens{Input: child.TotalCost.InputTokens, Output: child.TotalCost.OutputTokens},
+		}
+		if len(child.Turns) > 0 {
+			c.Prompt = child.Turns[0].Prompt
+		}
+		t.Children = append(t.Children, c)
+	}
+	return t
+}
+
+// addText adds assistant or reasoning text, replacing an earlier update of
+// the same runtime part
+func (turn *Turn) addText(kind string, event *agent.StreamEvent, at time.Time) {
+	if event.ID != "" {
+		for i := range turn.Entries {
+			if turn.Entries[i].Kind == kind && turn.Entries[i].id == event.ID {
+				turn.Entries[i].Text = event.Text
+				return
+			}
+		}
+	}
+	turn.Entries = append(turn.Entries, Entry{Kind: kind, Time: at, Text: event.Text, id: event.ID})
+}
+
+// isPromptEcho reports whether a message is the runtime echoing the user's
+// prompt back. Prompts can carry appended notes (e.g., attachment lists), so a
+// message that starts with the prompt counts.
+func isPromptEcho(text, prompt string) bool {
+	prompt = strings.TrimSpace(prompt)
+	return prompt != "" && strings.HasPrefix(strings.TrimSpace(text), prompt)
+}
+
+// Truncate shortens s to at most n characters, reporting whether it was cut
+func Truncate(s string, n int) (string, bool) {
+	if utf8.RuneCountInString(s) <= n {
+		return s, false
+	}
+	runes := []rune(s)
+	return string(runes[:n]), true
+}
+
+// String formats token counts for display
+func (t Tokens) String() string {
+	return fmt.Sprintf("%d in / %d out", t.Input, t.Output)
+}
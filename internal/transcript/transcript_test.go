@@ -0,0 +1,193 @@
+package transcript
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HyphaGroup/oubliette/internal/agent"
+	"github.com/HyphaGroup/oubliette/internal/session"
+)
+
+// testSession returns a two-turn session with a child, its event log and the child
+func testSession() (*session.Session, []*session.BufferedEvent, map[string]*session.Session) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+
+	sess := &session.Session{
+		SessionID:     "gogol_20260301_090000_abcd1234",
+		ProjectID:     "550e8400-e29b-41d4-a716-446655440000",
+		Status:        session.StatusCompleted,
+		Model:         "claude-sonnet",
+		CreatedAt:     start,
+		UpdatedAt:     at(60),
+		ChildSessions: []string{"gogol_20260301_090010_child001", "gogol_missing"},
+		Turns: []session.Turn{
+			{TurnNumber: 1, Prompt: "Fix the failing test", StartedAt: at(1)},
+			{TurnNumber: 2, Prompt: "Now update the changelog", StartedAt: at(30)},
+		},
+	}
+
+	events := []*session.BufferedEvent{
+		// Emitted before turn 1 was saved
+		{Timestamp: at(0), Event: &agent.StreamEvent{Type: agent.StreamEventMessage, Text: "Fix the failing test\n\nAttached files: ..."}},
+		{Timestamp: at(2), Event: &agent.StreamEvent{Type: agent.StreamEventSystem, Subtype: "reasoning", ID: "prt_r1", Text: "Run the tests first"}},
+		{Timestamp: at(3), Event: &agent.StreamEvent{Type: agent.StreamEventToolCall, ToolID: "call_1", ToolName: "bash", Parameters: map[string]any{"command": "go test ./..."}}},
+		{Timestamp: at(4), Event: &agent.StreamEvent{Type: agent.StreamEventToolResult, ToolID: "call_1", Value: strings.Repeat("x", MaxResultLength+10), IsError: true}},
+		{Timestamp: at(5), Event: &agent.StreamEvent{Type: agent.StreamEventMessage, ID: "prt_t1", Text: "Fixed"}},
+		{Timestamp: at(6), Event: &agent.StreamEvent{Type: agent.StreamEventMessage, ID: "prt_t1", Text: "Fixed the nil check in parser.go"}},
+		{Timestamp: at(7), Event: &agent.StreamEvent{Type: agent.StreamEventSystem, Subtype: "step-finish", InputTokens: 100, OutputTokens: 20}},
+		{Timestamp: at(8), Event: &agent.StreamEvent{Type: agent.StreamEventCompletion}},
+		// Turn 2 starts emitting before it is saved
+		{Timestamp: at(29), Event: &agent.StreamEvent{Type: agent.StreamEventMessage, Text: "Now update the changelog"}},
+		{Timestamp: at(29), Event: &agent.StreamEvent{Type: agent.StreamEventError, Text: "rate limited"}},
+		{Timestamp: at(31), Event: &agent.StreamEvent{Type: agent.StreamEventSystem, Subtype: "step-finish", InputTokens: 50, OutputTokens: 5}},
+	}
+
+	children := map[string]*session.Session{
+		"gogol_20260301_090010_child001": {
+			SessionID: "gogol_20260301_090010_child001",
+			Status:    session.StatusCompleted,
+			Turns:     []session.Turn{{TurnNumber: 1, Prompt: "Check the docs\nfor typos"}},
+			TotalCost: session.Cost{InputTokens: 7, OutputTokens: 3},
+		},
+	}
+	return sess, events, children
+}
+
+func TestBuild(t *testing.T) {
+	sess, events, children := testSession()
+	tr := Build(sess, events, children)
+
+	if len(tr.Turns) != 2 {
+		t.Fatalf("turns = %d, want 2", len(tr.Turns))
+	}
+
+	first := tr.Turns[0]
+	kinds := []string{}
+	for _, e := range first.Entries {
+		kinds = append(kinds, e.Kind)
+	}
+	if strings.Join(kinds, ",") != "reasoning,tool,assistant" {
+		t.Fatalf("turn 1 entries = %v, want reasoning,tool,assistant without the prompt echo", kinds)
+	}
+	tool := first.Entries[1]
+	if tool.Tool != "bash" || tool.Parameters["command"] != "go test ./..." || !tool.IsError {
+		t.Errorf("tool entry = %+v", tool)
+	}
+	if len(tool.Result) != MaxResultLength || !tool.ResultTruncated {
+		t.Errorf("tool result length = %d, truncated = %v", len(tool.Result), tool.ResultTruncated)
+	}
+	if first.Entries[2].Text != "Fixed the nil check in parser.go" {
+		t.Errorf("assistant text = %q, want the last update of the part", first.Entries[2].Text)
+	}
+	if first.CompletedAt.IsZero() || first.Tokens != (Tokens{Input: 100, Output: 20}) {
+		t.Errorf("turn 1 completed = %v, tokens = %v", first.CompletedAt, first.Tokens)
+	}
+
+	second := tr.Turns[1]
+	if len(second.Entries) != 1 || second.Entries[0].Kind != KindError || second.Tokens != (Tokens{Input: 50, Output: 5}) {
+		t.Errorf("turn 2 = %+v", second)
+	}
+	if tr.Tokens != (Tokens{Input: 150, Output: 25}) {
+		t.Errorf("total tokens = %v", tr.Tokens)
+	}
+
+	if len(tr.Children) != 2 || tr.Children[0].Prompt != "Check the docs\nfor typos" || tr.Children[1].Status != "unavailable" {
+		t.Errorf("children = %+v", tr.Children)
+	}
+}
+
+func TestBuildWithoutEvents(t *testing.T) {
+	sess, _, _ := testSession()
+	sess.ChildSessions = nil
+	sess.TotalCost = session.Cost{InputTokens: 12, OutputTokens: 4}
+
+	tr := Build(sess, nil, nil)
+	if len(tr.Turns) != 2 || len(tr.Turns[0].Entries) != 0 {
+		t.Errorf("turns = %+v, want the prompts only", tr.Turns)
+	}
+	if tr.Tokens != (Tokens{Input: 12, Output: 4}) {
+		t.Errorf("tokens = %v, want the session totals", tr.Tokens)
+	}
+
+	parent := "gogol_20260228_090000_parent01"
+	sess.ParentSessionID, sess.ForkedFromTurn = &parent, 1
+	if md := Build(sess, nil, nil).Markdown(); !strings.Contains(md, "| Forked from | "+parent+" at turn 1 |") {
+		t.Errorf("markdown missing fork row:\n%s", md)
+	}
+}
+
+func TestRender(t *testing.T) {
+	sess, events, children := testSession()
+	events = append(events, &session.BufferedEvent{Timestamp: time.Date(2026, 3, 1, 9, 0, 40, 0, time.UTC), Event: &agent.StreamEvent{
+		Type: agent.StreamEventMessage, Text: "<script>alert(1)</script> and ```fenced```",
+	}})
+	tr := Build(sess, events, children)
+
+	t.Run("markdown", func(t *testing.T) {
+		out, err := tr.Render(FormatMarkdown)
+		if err != nil {
+			t.Fatal(err)
+		}
+		md := string(out)
+		for _, want := range []string{
+			"# Session " + sess.SessionID,
+			"| Tokens | 150 in / 25 out |",
+			"> Fix the failing test",
+			"**Tool call: `bash`** (failed)",
+			`"command": "go test ./..."`,
+			"Error result (truncated to 2000 characters)",
+			"[`gogol_20260301_090010_child001`](gogol_20260301_090010_child001.md) — completed, 1 turns, 7 in / 3 out tokens: Check the docs …",
+		} {
+			if !strings.Contains(md, want) {
+				t.Errorf("markdown missing %q:\n%s", want, md)
+			}
+		}
+	})
+
+	t.Run("html", func(t *testing.T) {
+		out, err := tr.Render(FormatHTML)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page := string(out)
+		if strings.Contains(page, "<script>") || !strings.Contains(page, "&lt;script&gt;") {
+			t.Error("html does not escape agent output")
+		}
+		for _, want := range []string{"<style>", "Tool call: <code>bash</code>", `href="gogol_20260301_090010_child001.html"`, "Run the tests first"} {
+			if !strings.Contains(page, want) {
+				t.Errorf("html missing %q", want)
+			}
+		}
+		if strings.Contains(page, "src=") || strings.Contains(page, "<link") {
+			t.Error("html is not self-contained")
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out, err := tr.Render(FormatJSON)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded Transcript
+		if err := json.Unmarshal(out, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.SessionID != sess.SessionID || len(decoded.Turns) != 2 || decoded.Turns[0].Entries[1].ToolID != "call_1" {
+			t.Errorf("decoded = %+v", decoded)
+		}
+	})
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"": FormatMarkdown, "md": FormatMarkdown, "HTML": FormatHTML, "json": FormatJSON} {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v", name, got, err)
+		}
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("ParseFormat(pdf) should fail")
+	}
+}
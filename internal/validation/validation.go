@@ -1,11 +1,26 @@
 package validation
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
 )
 
+// ErrInvalid matches every validation error with errors.Is
+var ErrInvalid = errors.New("invalid input")
+
+// invalidError is a validation failure; its message is the failure alone
+type invalidError struct{ msg string }
+
+func (e *invalidError) Error() string        { return e.msg }
+func (e *invalidError) Is(target error) bool { return target == ErrInvalid }
+
+// invalidf returns a validation error with a formatted message
+func invalidf(format string, args ...any) error {
+	return &invalidError{msg: fmt.Sprintf(format, args...)}
+}
+
 var (
 	// UUIDRegex matches standard UUID format
 	uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
@@ -17,10 +32,10 @@ var (
 // ValidateUUID checks if the string is a valid UUID
 func ValidateUUID(id string) error {
 	if id == "" {
-		return fmt.Errorf("ID cannot be empty")
+		return invalidf("ID cannot be empty")
 	}
 	if !uuidRegex.MatchString(id) {
-		return fmt.Errorf("invalid UUID format: %s", id)
+		return invalidf("invalid UUID format: %s", id)
 	}
 	return nil
 }
@@ -36,14 +51,14 @@ var gogolSessionRegex = regexp.MustCompile(`^gogol_\d{8}_\d{6}_[0-9a-fA-F]+$`)
 // ValidateSessionID validates a session ID (can be UUID, child_*, or gogol_* format)
 func ValidateSessionID(id string) error {
 	if id == "" {
-		return fmt.Errorf("session ID cannot be empty")
+		return invalidf("session ID cannot be empty")
 	}
 
 	// Child session IDs have format child_<parent>_<counter>
 	if strings.HasPrefix(id, "child_") {
 		parts := strings.Split(id, "_")
 		if len(parts) < 3 {
-			return fmt.Errorf("invalid child session ID format: %s", id)
+			return invalidf("invalid child session ID format: %s", id)
 		}
 		return nil
 	}
@@ -51,7 +66,7 @@ func ValidateSessionID(id string) error {
 	// Gogol session IDs have format gogol_YYYYMMDD_HHMMSS_RANDOMHEX
 	if strings.HasPrefix(id, "gogol_") {
 		if !gogolSessionRegex.MatchString(id) {
-			return fmt.Errorf("invalid gogol session ID format: %s", id)
+			return invalidf("invalid gogol session ID format: %s", id)
 		}
 		return nil
 	}
@@ -68,17 +83,17 @@ func ValidateWorkspaceID(id string) error {
 // SanitizePath removes path traversal attempts and validates path components
 func SanitizePath(path string) (string, error) {
 	if path == "" {
-		return "", fmt.Errorf("path cannot be empty")
+		return "", invalidf("path cannot be empty")
 	}
 
 	// Reject obvious traversal attempts
 	if strings.Contains(path, "..") {
-		return "", fmt.Errorf("path traversal detected: %s", path)
+		return "", invalidf("path traversal detected: %s", path)
 	}
 
 	// Reject absolute paths when relative expected
 	if strings.HasPrefix(path, "/") {
-		return "", fmt.Errorf("absolute paths not allowed: %s", path)
+		return "", invalidf("absolute paths not allowed: %s", path)
 	}
 
 	// Split and validate each component
@@ -88,7 +103,7 @@ func SanitizePath(path string) (string, error) {
 			continue // Allow trailing/leading slashes
 		}
 		if !safePathRegex.MatchString(part) {
-			return "", fmt.Errorf("unsafe path component: %s", part)
+			return "", invalidf("unsafe path component: %s", part)
 		}
 	}
 
@@ -98,12 +113,12 @@ func SanitizePath(path string) (string, error) {
 // ValidateContainerID validates a container ID (hex string)
 func ValidateContainerID(id string) error {
 	if id == "" {
-		return fmt.Errorf("container ID cannot be empty")
+		return invalidf("container ID cannot be empty")
 	}
 
 	// Container IDs are hex strings, typically 64 chars but can be shorter for short IDs
 	if len(id) < 12 || len(id) > 64 {
-		return fmt.Errorf("invalid container ID length: %s", id)
+		return invalidf("invalid container ID length: %s", id)
 	}
 
 	for _, c := range id {
@@ -111,9 +126,23 @@ func ValidateContainerID(id string) error {
 		isLowerHex := c >= 'a' && c <= 'f'
 		isUpperHex := c >= 'A' && c <= 'F'
 		if !isDigit && !isLowerHex && !isUpperHex {
-			return fmt.Errorf("invalid container ID format: %s", id)
+			return invalidf("invalid container ID format: %s", id)
 		}
 	}
 
 	return nil
 }
+
+// templateNameRegex matches prompt template names (alphanumeric, dash, underscore)
+var templateNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// ValidateTemplateName validates a prompt template name
+func ValidateTemplateName(name string) error {
+	if name == "" {
+		return invalidf("template name cannot be empty")
+	}
+	if !templateNameRegex.MatchString(name) {
+		return invalidf("invalid template name: %s (must be 1-64 alphanumeric, dash or underscore characters)", name)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package validation
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -168,3 +169,27 @@ func TestValidateContainerID(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTemplateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"simple", "weekly-summary", false},
+		{"underscore", "pr_review", false},
+		{"empty", "", true},
+		{"path traversal", "../etc", true},
+		{"slash", "a/b", true},
+		{"too long", strings.Repeat("a", 65), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTemplateName(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTemplateName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}